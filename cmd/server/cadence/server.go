@@ -30,6 +30,7 @@ import (
 	"github.com/uber/cadence/common/archiver"
 	"github.com/uber/cadence/common/archiver/provider"
 	"github.com/uber/cadence/common/blobstore/filestore"
+	"github.com/uber/cadence/common/certificate"
 	"github.com/uber/cadence/common/cluster"
 	"github.com/uber/cadence/common/config"
 	"github.com/uber/cadence/common/dynamicconfig"
@@ -145,14 +146,18 @@ func (s *server) startService() common.Daemon {
 
 	params.MetricScope = svcCfg.Metrics.NewScope(params.Logger, params.Name)
 
-	rpcParams, err := rpc.NewParams(params.Name, s.cfg)
+	rpcParams, err := rpc.NewParams(params.Name, s.cfg, params.Logger)
 	if err != nil {
 		log.Fatalf("error creating rpc factory params: %v", err)
 	}
 	rpcParams.OutboundsBuilder = rpc.CombineOutbounds(
 		rpcParams.OutboundsBuilder,
-		rpc.NewCrossDCOutbounds(clusterGroupMetadata.ClusterGroup, rpc.NewDNSPeerChooserFactory(s.cfg.PublicClient.RefreshInterval, params.Logger)),
+		rpc.NewCrossDCOutbounds(clusterGroupMetadata.ClusterGroup, rpc.NewDNSPeerChooserFactory(s.cfg.PublicClient.RefreshInterval, params.Logger), params.Logger),
 	)
+	rpcParams.WatchCertificates(params.MetricScope, s.doneC)
+	if err := s.watchPersistenceCertificates(&params); err != nil {
+		log.Fatalf("error loading persistence certificates: %v", err)
+	}
 	rpcFactory := rpc.NewFactory(params.Logger, rpcParams)
 	params.RPCFactory = rpcFactory
 	params.MembershipFactory, err = s.cfg.Ringpop.NewFactory(
@@ -183,7 +188,7 @@ func (s *server) startService() common.Daemon {
 		common.GetDefaultAdvancedVisibilityWritingMode(params.PersistenceConfig.IsAdvancedVisibilityConfigExist()),
 	)()
 	isAdvancedVisEnabled := advancedVisMode != common.AdvancedVisibilityWritingModeOff
-	if isAdvancedVisEnabled {
+	if isAdvancedVisEnabled || s.cfg.Audit.HasSink(config.AuditSinkKafka) {
 		params.MessagingClient = kafka.NewKafkaClient(&s.cfg.Kafka, params.MetricsClient, params.Logger, params.MetricScope, isAdvancedVisEnabled)
 	} else {
 		params.MessagingClient = nil
@@ -226,7 +231,9 @@ func (s *server) startService() common.Daemon {
 	params.ArchiverProvider = provider.NewArchiverProvider(s.cfg.Archival.History.Provider, s.cfg.Archival.Visibility.Provider)
 	params.PersistenceConfig.TransactionSizeLimit = dc.GetIntProperty(dynamicconfig.TransactionSizeLimit, common.DefaultTransactionSizeLimit)
 	params.PersistenceConfig.ErrorInjectionRate = dc.GetFloat64Property(dynamicconfig.PersistenceErrorInjectionRate, 0)
+	params.PersistenceConfig.MigrationMode = dc.GetStringProperty(dynamicconfig.PersistenceMigrationMode, common.PersistenceMigrationModeOff)
 	params.AuthorizationConfig = s.cfg.Authorization
+	params.AuditConfig = s.cfg.Audit
 	params.BlobstoreClient, err = filestore.NewFilestoreClient(s.cfg.Blobstore.Filestore)
 	if err != nil {
 		log.Printf("failed to create file blobstore client, will continue startup without it: %v", err)
@@ -256,6 +263,33 @@ func (s *server) startService() common.Daemon {
 	return daemon
 }
 
+// watchPersistenceCertificates reports how long the TLS certificates of the datastores are still valid,
+// the persistence clients reload the files on their own
+func (s *server) watchPersistenceCertificates(params *resource.Params) error {
+	for _, ds := range s.cfg.Persistence.DataStores {
+		var tls *config.TLS
+		switch {
+		case ds.NoSQL != nil:
+			tls = ds.NoSQL.TLS
+		case ds.Cassandra != nil:
+			tls = ds.Cassandra.TLS
+		case ds.SQL != nil:
+			tls = ds.SQL.TLS
+		}
+		if tls == nil {
+			continue
+		}
+		reloader, err := certificate.NewReloader(*tls, params.Logger)
+		if err != nil {
+			return err
+		}
+		if reloader != nil {
+			reloader.Watch(params.MetricScope, s.doneC)
+		}
+	}
+	return nil
+}
+
 // execute runs the daemon in a separate go routine
 func execute(d common.Daemon, doneC chan struct{}) {
 	d.Start()
@@ -0,0 +1,321 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/uber/cadence/common/config"
+	"github.com/uber/cadence/common/log/loggerimpl"
+	"github.com/uber/cadence/common/messaging"
+	"github.com/uber/cadence/common/metrics"
+	"github.com/uber/cadence/common/metrics/mocks"
+	"github.com/uber/cadence/common/persistence"
+)
+
+type (
+	auditSuite struct {
+		suite.Suite
+		controller *gomock.Controller
+		tempDir    string
+		now        time.Time
+	}
+
+	testProducer struct {
+		messages []interface{}
+	}
+
+	blockingSink struct {
+		startedCh chan struct{}
+		emitCh    chan struct{}
+		events    []*Event
+		emitErr   error
+		closed    bool
+	}
+)
+
+func TestAuditSuite(t *testing.T) {
+	suite.Run(t, new(auditSuite))
+}
+
+func (s *auditSuite) SetupTest() {
+	s.controller = gomock.NewController(s.T())
+	var err error
+	s.tempDir, err = ioutil.TempDir("", "auditSuite")
+	s.NoError(err)
+	s.now = time.Unix(1630000000, 0).UTC()
+}
+
+func (s *auditSuite) TearDownTest() {
+	s.controller.Finish()
+	os.RemoveAll(s.tempDir)
+}
+
+func (s *auditSuite) newEvent(api string, workflowID string) *Event {
+	return &Event{
+		Timestamp:  s.now,
+		Actor:      "oncall",
+		API:        api,
+		Domain:     "test-domain",
+		WorkflowID: workflowID,
+		RunID:      "rid",
+		Reason:     "stuck",
+		Result:     ResultSuccess,
+	}
+}
+
+func (s *auditSuite) TestFileSink() {
+	path := filepath.Join(s.tempDir, "audit.log")
+	sink, err := NewSink(config.Audit{Sinks: []config.AuditSink{{Type: config.AuditSinkFile, Path: path}}}, nil, nil, loggerimpl.NewNopLogger())
+	s.NoError(err)
+
+	terminate := s.newEvent("TerminateWorkflowExecution", "wid1")
+	signal := s.newEvent("SignalWorkflowExecution", "wid2")
+	s.NoError(sink.Emit(context.Background(), terminate))
+	s.NoError(sink.Emit(context.Background(), signal))
+
+	events, err := ReadFile(path, nil)
+	s.NoError(err)
+	s.Equal([]*Event{terminate, signal}, events)
+
+	events, err = ReadFile(path, &Filter{WorkflowID: "wid2"})
+	s.NoError(err)
+	s.Equal([]*Event{signal}, events)
+}
+
+func (s *auditSuite) TestFileSinkRotation() {
+	path := filepath.Join(s.tempDir, "audit.log")
+	sink, err := NewFileSink(path, 1)
+	s.NoError(err)
+	sink.(*fileSink).maxSize = 600
+
+	var emitted []*Event
+	for i := 0; i < 6; i++ {
+		event := s.newEvent("TerminateWorkflowExecution", "wid")
+		emitted = append(emitted, event)
+		s.NoError(sink.Emit(context.Background(), event))
+	}
+	s.NoError(sink.Close())
+	s.Equal(errSinkClosed, sink.Emit(context.Background(), s.newEvent("TerminateWorkflowExecution", "wid")))
+
+	rotated, err := filepath.Glob(path + ".*")
+	s.NoError(err)
+	s.NotEmpty(rotated)
+	var events []*Event
+	for _, file := range append(rotated, path) {
+		info, err := os.Stat(file)
+		s.NoError(err)
+		s.True(info.Size() <= 600)
+		fileEvents, err := ReadFile(file, nil)
+		s.NoError(err)
+		events = append(events, fileEvents...)
+	}
+	s.Equal(emitted, events)
+}
+
+func (s *auditSuite) TestBufferedSink() {
+	blocking := &blockingSink{
+		startedCh: make(chan struct{}, 2),
+		emitCh:    make(chan struct{}),
+		emitErr:   errors.New("unavailable"),
+	}
+	metricsScope := &mocks.Scope{}
+	metricsScope.On("IncCounter", metrics.CadenceErrAuditFailedCounter).Times(2)
+	sink := NewBufferedSink(blocking, 1, time.Second, loggerimpl.NewNopLogger(), metricsScope)
+
+	first := s.newEvent("TerminateWorkflowExecution", "wid1")
+	second := s.newEvent("SignalWorkflowExecution", "wid2")
+	s.NoError(sink.Emit(context.Background(), first))
+	// the emit loop is blocked emitting the first event, the second one fills the buffer
+	<-blocking.startedCh
+	s.NoError(sink.Emit(context.Background(), second))
+	s.Equal(errBufferFull, sink.Emit(context.Background(), s.newEvent("SignalWorkflowExecution", "wid3")))
+
+	// close emits the buffered events before closing the sink
+	close(blocking.emitCh)
+	s.NoError(sink.Close())
+	s.Equal([]*Event{first, second}, blocking.events)
+	s.True(blocking.closed)
+	s.Equal(errSinkClosed, sink.Emit(context.Background(), first))
+	metricsScope.AssertExpectations(s.T())
+}
+
+func (s *auditSuite) TestKafkaSink() {
+	producer := &testProducer{}
+	event := s.newEvent("TerminateWorkflowExecution", "wid")
+	s.NoError(NewKafkaSink(producer).Emit(context.Background(), event))
+
+	s.Len(producer.messages, 1)
+	message := producer.messages[0].(*messaging.RawMessage)
+	s.Equal("test-domain", message.Key)
+	decoded := &Event{}
+	s.NoError(json.Unmarshal(message.Value, decoded))
+	s.Equal(event, decoded)
+}
+
+func (s *auditSuite) TestQueueSink() {
+	queue := persistence.NewMockQueueManager(s.controller)
+	event := s.newEvent("TerminateWorkflowExecution", "wid")
+	payload, err := json.Marshal(event)
+	s.NoError(err)
+
+	queue.EXPECT().EnqueueMessage(gomock.Any(), payload).Return(nil).Times(1)
+	s.NoError(NewQueueSink(queue, 0, loggerimpl.NewNopLogger()).Emit(context.Background(), event))
+
+	other, err := json.Marshal(s.newEvent("SignalWorkflowExecution", "other"))
+	s.NoError(err)
+	queue.EXPECT().ReadMessages(gomock.Any(), int64(-1), 10).Return([]*persistence.QueueMessage{
+		{ID: 0, Payload: payload},
+		{ID: 1, Payload: other},
+	}, nil).Times(1)
+	events, lastMessageID, err := ReadQueue(context.Background(), queue, -1, 10, &Filter{API: "TerminateWorkflowExecution"})
+	s.NoError(err)
+	s.Equal([]*Event{event}, events)
+	s.Equal(int64(1), lastMessageID)
+}
+
+func (s *auditSuite) TestReadQueueTail() {
+	// the head of the queue has been deleted by the retention
+	var messages []*persistence.QueueMessage
+	for id := int64(5); id < 40; id++ {
+		api := "SignalWorkflowExecution"
+		if id%2 == 0 {
+			api = "TerminateWorkflowExecution"
+		}
+		payload, err := json.Marshal(s.newEvent(api, fmt.Sprintf("wid%v", id)))
+		s.NoError(err)
+		messages = append(messages, &persistence.QueueMessage{ID: id, Payload: payload})
+	}
+	queue := s.newQueue(messages)
+
+	events, err := ReadQueueTail(context.Background(), queue, 3, 4, &Filter{API: "TerminateWorkflowExecution"})
+	s.NoError(err)
+	s.Len(events, 3)
+	s.Equal("wid34", events[0].WorkflowID)
+	s.Equal("wid36", events[1].WorkflowID)
+	s.Equal("wid38", events[2].WorkflowID)
+
+	events, err = ReadQueueTail(context.Background(), queue, 100, 4, nil)
+	s.NoError(err)
+	s.Len(events, 35)
+	s.Equal("wid5", events[0].WorkflowID)
+	s.Equal("wid39", events[34].WorkflowID)
+
+	events, err = ReadQueueTail(context.Background(), s.newQueue(nil), 3, 4, nil)
+	s.NoError(err)
+	s.Empty(events)
+}
+
+func (s *auditSuite) TestDeleteExpiredEvents() {
+	var messages []*persistence.QueueMessage
+	for id, age := range []time.Duration{3 * time.Hour, 2 * time.Hour, time.Minute} {
+		event := s.newEvent("TerminateWorkflowExecution", "wid")
+		event.Timestamp = time.Now().Add(-age)
+		payload, err := json.Marshal(event)
+		s.NoError(err)
+		messages = append(messages, &persistence.QueueMessage{ID: int64(id), Payload: payload})
+	}
+	queue := s.newQueue(messages)
+	queue.EXPECT().DeleteMessagesBefore(gomock.Any(), int64(2)).Return(nil).Times(1)
+	s.NoError(DeleteExpiredEvents(context.Background(), queue, time.Hour, 1))
+
+	// nothing to delete
+	s.NoError(DeleteExpiredEvents(context.Background(), queue, 4*time.Hour, 1))
+}
+
+// newQueue returns a queue reading the messages, which are sorted by ID
+func (s *auditSuite) newQueue(messages []*persistence.QueueMessage) *persistence.MockQueueManager {
+	queue := persistence.NewMockQueueManager(s.controller)
+	queue.EXPECT().ReadMessages(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, lastMessageID int64, maxCount int) ([]*persistence.QueueMessage, error) {
+			var result []*persistence.QueueMessage
+			for _, message := range messages {
+				if message.ID > lastMessageID && len(result) < maxCount {
+					result = append(result, message)
+				}
+			}
+			return result, nil
+		}).AnyTimes()
+	return queue
+}
+
+func (s *auditSuite) TestMultiSinkError() {
+	queue := persistence.NewMockQueueManager(s.controller)
+	queue.EXPECT().EnqueueMessage(gomock.Any(), gomock.Any()).Return(errors.New("unavailable")).Times(1)
+	path := filepath.Join(s.tempDir, "audit.log")
+	sink, err := NewSink(config.Audit{Sinks: []config.AuditSink{
+		{Type: config.AuditSinkPersistence},
+		{Type: config.AuditSinkFile, Path: path},
+	}}, nil, queue, loggerimpl.NewNopLogger())
+	s.NoError(err)
+
+	event := s.newEvent("TerminateWorkflowExecution", "wid")
+	s.Error(sink.Emit(context.Background(), event))
+	// the event is still written to the other sinks
+	events, err := ReadFile(path, nil)
+	s.NoError(err)
+	s.Equal([]*Event{event}, events)
+}
+
+func (s *auditSuite) TestMissingDependencies() {
+	_, err := NewSink(config.Audit{Sinks: []config.AuditSink{{Type: config.AuditSinkKafka}}}, nil, nil, loggerimpl.NewNopLogger())
+	s.Error(err)
+	_, err = NewSink(config.Audit{Sinks: []config.AuditSink{{Type: config.AuditSinkPersistence}}}, nil, nil, loggerimpl.NewNopLogger())
+	s.Error(err)
+}
+
+func (s *auditSuite) TestFilter() {
+	event := s.newEvent("TerminateWorkflowExecution", "wid")
+	s.True((*Filter)(nil).Match(event))
+	s.True((&Filter{Domain: "test-domain", Actor: "oncall", RunID: "rid"}).Match(event))
+	s.False((&Filter{Domain: "other-domain"}).Match(event))
+	s.True((&Filter{StartTime: s.now.Add(-time.Minute), EndTime: s.now.Add(time.Minute)}).Match(event))
+	s.False((&Filter{StartTime: s.now.Add(time.Minute)}).Match(event))
+	s.False((&Filter{EndTime: s.now.Add(-time.Minute)}).Match(event))
+}
+
+func (p *testProducer) Publish(ctx context.Context, message interface{}) error {
+	p.messages = append(p.messages, message)
+	return nil
+}
+
+func (s *blockingSink) Emit(ctx context.Context, event *Event) error {
+	s.startedCh <- struct{}{}
+	<-s.emitCh
+	s.events = append(s.events, event)
+	return s.emitErr
+}
+
+func (s *blockingSink) Close() error {
+	s.closed = true
+	return nil
+}
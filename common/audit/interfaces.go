@@ -0,0 +1,99 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package audit
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	// ResultSuccess means the API call succeeded
+	ResultSuccess Result = "success"
+	// ResultFailure means the API call returned an error
+	ResultFailure Result = "failure"
+	// ResultUnauthorized means the API call was denied by the authorizer
+	ResultUnauthorized Result = "unauthorized"
+)
+
+type (
+	// Event is the record of a mutating API call
+	Event struct {
+		Timestamp  time.Time `json:"timestamp"`
+		Actor      string    `json:"actor,omitempty"`
+		API        string    `json:"api"`
+		Domain     string    `json:"domain,omitempty"`
+		WorkflowID string    `json:"workflowID,omitempty"`
+		RunID      string    `json:"runID,omitempty"`
+		Reason     string    `json:"reason,omitempty"`
+		Result     Result    `json:"result"`
+		Error      string    `json:"error,omitempty"`
+	}
+
+	// Result is the outcome of the API call
+	Result string
+
+	// Sink is a destination of the audit events
+	Sink interface {
+		Emit(ctx context.Context, event *Event) error
+		Close() error
+	}
+
+	// Filter selects audit events, empty fields match every event
+	Filter struct {
+		Domain     string
+		WorkflowID string
+		RunID      string
+		Actor      string
+		API        string
+		StartTime  time.Time
+		EndTime    time.Time
+	}
+)
+
+// Match returns whether the event is selected by the filter
+func (f *Filter) Match(event *Event) bool {
+	if f == nil {
+		return true
+	}
+	if f.Domain != "" && f.Domain != event.Domain {
+		return false
+	}
+	if f.WorkflowID != "" && f.WorkflowID != event.WorkflowID {
+		return false
+	}
+	if f.RunID != "" && f.RunID != event.RunID {
+		return false
+	}
+	if f.Actor != "" && f.Actor != event.Actor {
+		return false
+	}
+	if f.API != "" && f.API != event.API {
+		return false
+	}
+	if !f.StartTime.IsZero() && event.Timestamp.Before(f.StartTime) {
+		return false
+	}
+	if !f.EndTime.IsZero() && event.Timestamp.After(f.EndTime) {
+		return false
+	}
+	return true
+}
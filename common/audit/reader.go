@@ -0,0 +1,215 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package audit
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/uber/cadence/common/persistence"
+)
+
+// ReadQueue reads the events after lastMessageID from the audit queue. It returns the events matching the filter
+// among the next pageSize messages, and the ID of the last message read to continue from.
+func ReadQueue(
+	ctx context.Context,
+	queue persistence.QueueManager,
+	lastMessageID int64,
+	pageSize int,
+	filter *Filter,
+) ([]*Event, int64, error) {
+	messages, err := queue.ReadMessages(ctx, lastMessageID, pageSize)
+	if err != nil {
+		return nil, lastMessageID, err
+	}
+	var events []*Event
+	for _, message := range messages {
+		lastMessageID = message.ID
+		event := &Event{}
+		if err := json.Unmarshal(message.Payload, event); err != nil {
+			return nil, lastMessageID, fmt.Errorf("invalid audit event %v: %v", message.ID, err)
+		}
+		if filter.Match(event) {
+			events = append(events, event)
+		}
+	}
+	return events, lastMessageID, nil
+}
+
+// ReadQueueTail reads the latest maxCount events matching the filter from the audit queue, oldest first. The queue
+// is read backwards from its last message by pages of pageSize messages, so the cost depends on how many
+// messages are read to find the events rather than on the size of the queue.
+func ReadQueueTail(
+	ctx context.Context,
+	queue persistence.QueueManager,
+	maxCount int,
+	pageSize int,
+	filter *Filter,
+) ([]*Event, error) {
+	first, err := readMessageAfter(ctx, queue, -1)
+	if err != nil || first == nil {
+		return nil, err
+	}
+	lastMessageID, err := findLastMessageID(ctx, queue, first.ID, pageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	var events []*Event
+	for end := lastMessageID; end >= first.ID && len(events) < maxCount; {
+		start := end - int64(pageSize)
+		if start < first.ID-1 {
+			start = first.ID - 1
+		}
+		// the page contains the messages in (start, end]
+		page, _, err := ReadQueue(ctx, queue, start, int(end-start), filter)
+		if err != nil {
+			return nil, err
+		}
+		events = append(page, events...)
+		end = start
+	}
+	if len(events) > maxCount {
+		events = events[len(events)-maxCount:]
+	}
+	return events, nil
+}
+
+// DeleteExpiredEvents deletes the events older than the retention from the head of the audit queue. It only
+// reads the queue up to the first event to retain, events are enqueued in the order of their time.
+func DeleteExpiredEvents(
+	ctx context.Context,
+	queue persistence.QueueManager,
+	retention time.Duration,
+	pageSize int,
+) error {
+	expiration := time.Now().Add(-retention)
+	lastMessageID := int64(-1)
+	deleteBefore := int64(-1)
+	for {
+		messages, err := queue.ReadMessages(ctx, lastMessageID, pageSize)
+		if err != nil {
+			return err
+		}
+		if len(messages) == 0 {
+			break
+		}
+		retained := false
+		for _, message := range messages {
+			event := &Event{}
+			if err := json.Unmarshal(message.Payload, event); err != nil {
+				return fmt.Errorf("invalid audit event %v: %v", message.ID, err)
+			}
+			if !event.Timestamp.Before(expiration) {
+				retained = true
+				break
+			}
+			deleteBefore = message.ID + 1
+		}
+		if retained {
+			break
+		}
+		lastMessageID = messages[len(messages)-1].ID
+	}
+	if deleteBefore < 0 {
+		return nil
+	}
+	return queue.DeleteMessagesBefore(ctx, deleteBefore)
+}
+
+// findLastMessageID finds the ID of the last message of the queue, starting from the ID of a message in the queue.
+// The IDs are searched exponentially and then by bisection, reading a single message per step.
+func findLastMessageID(
+	ctx context.Context,
+	queue persistence.QueueManager,
+	messageID int64,
+	step int,
+) (int64, error) {
+	// there's a message with ID lo and no message with ID hi or above once hi is found
+	lo, hi := messageID, int64(-1)
+	for probe := int64(step); hi < 0; probe *= 2 {
+		message, err := readMessageAfter(ctx, queue, lo+probe-1)
+		if err != nil {
+			return 0, err
+		}
+		if message == nil {
+			hi = lo + probe
+		} else {
+			lo = message.ID
+		}
+	}
+	for hi-lo > 1 {
+		mid := lo + (hi-lo)/2
+		message, err := readMessageAfter(ctx, queue, mid-1)
+		if err != nil {
+			return 0, err
+		}
+		if message == nil {
+			hi = mid
+		} else {
+			lo = message.ID
+		}
+	}
+	return lo, nil
+}
+
+// readMessageAfter returns the first message with an ID larger than messageID, or nil if there's none
+func readMessageAfter(
+	ctx context.Context,
+	queue persistence.QueueManager,
+	messageID int64,
+) (*persistence.QueueMessage, error) {
+	messages, err := queue.ReadMessages(ctx, messageID, 1)
+	if err != nil || len(messages) == 0 {
+		return nil, err
+	}
+	return messages[0], nil
+}
+
+// ReadFile reads the events matching the filter from a file written by the file sink
+func ReadFile(path string, filter *Filter) ([]*Event, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var events []*Event
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		event := &Event{}
+		if err := json.Unmarshal(scanner.Bytes(), event); err != nil {
+			return nil, fmt.Errorf("invalid audit event at line %v: %v", line, err)
+		}
+		if filter.Match(event) {
+			events = append(events, event)
+		}
+	}
+	return events, scanner.Err()
+}
@@ -0,0 +1,392 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"go.uber.org/multierr"
+
+	"github.com/uber/cadence/common"
+	"github.com/uber/cadence/common/config"
+	"github.com/uber/cadence/common/log"
+	"github.com/uber/cadence/common/log/tag"
+	"github.com/uber/cadence/common/messaging"
+	"github.com/uber/cadence/common/metrics"
+	"github.com/uber/cadence/common/persistence"
+)
+
+const (
+	bytesInMB = 1024 * 1024
+
+	rotatedFileTimeFormat = "20060102T150405.000000000"
+
+	retentionInterval = 10 * time.Minute
+	retentionTimeout  = time.Minute
+	retentionPageSize = 1000
+)
+
+var (
+	errSinkClosed = errors.New("audit sink is closed")
+	errBufferFull = errors.New("audit buffer is full")
+)
+
+type (
+	nopSink struct{}
+
+	multiSink struct {
+		sinks []Sink
+	}
+
+	fileSink struct {
+		sync.Mutex
+		path    string
+		maxSize int64
+		file    *os.File
+		size    int64
+	}
+
+	kafkaSink struct {
+		producer messaging.Producer
+	}
+
+	queueSink struct {
+		queue     persistence.QueueManager
+		retention time.Duration
+		logger    log.Logger
+		doneCh    chan struct{}
+		stoppedCh chan struct{}
+	}
+
+	bufferedSink struct {
+		sync.RWMutex
+		sink         Sink
+		emitTimeout  time.Duration
+		logger       log.Logger
+		metricsScope metrics.Scope
+		eventCh      chan *Event
+		closed       bool
+		doneCh       chan struct{}
+	}
+)
+
+var _ Sink = (*nopSink)(nil)
+var _ Sink = (*multiSink)(nil)
+var _ Sink = (*fileSink)(nil)
+var _ Sink = (*kafkaSink)(nil)
+var _ Sink = (*queueSink)(nil)
+var _ Sink = (*bufferedSink)(nil)
+
+// NewSink creates the sink emitting the events to every sink of the config.
+// messagingClient and queue are only required by the kafka and persistence sinks.
+func NewSink(
+	cfg config.Audit,
+	messagingClient messaging.Client,
+	queue persistence.QueueManager,
+	logger log.Logger,
+) (Sink, error) {
+	if len(cfg.Sinks) == 0 {
+		return NewNopSink(), nil
+	}
+	var sinks []Sink
+	for _, sinkCfg := range cfg.Sinks {
+		switch sinkCfg.Type {
+		case config.AuditSinkFile:
+			sink, err := NewFileSink(sinkCfg.Path, sinkCfg.MaxSizeInMB)
+			if err != nil {
+				return nil, err
+			}
+			sinks = append(sinks, sink)
+		case config.AuditSinkKafka:
+			if messagingClient == nil {
+				return nil, fmt.Errorf("kafka audit sink requires kafka to be configured")
+			}
+			producer, err := messagingClient.NewProducer(common.AuditAppName)
+			if err != nil {
+				return nil, err
+			}
+			sinks = append(sinks, NewKafkaSink(producer))
+		case config.AuditSinkPersistence:
+			if queue == nil {
+				return nil, fmt.Errorf("persistence audit sink requires the audit queue")
+			}
+			sinks = append(sinks, NewQueueSink(queue, sinkCfg.Retention, logger))
+		default:
+			return nil, fmt.Errorf("unknown audit sink type %v", sinkCfg.Type)
+		}
+	}
+	if len(sinks) == 1 {
+		return sinks[0], nil
+	}
+	return &multiSink{sinks: sinks}, nil
+}
+
+// NewNopSink creates a sink dropping the events
+func NewNopSink() Sink {
+	return &nopSink{}
+}
+
+// NewFileSink creates a sink appending the events as JSON lines to the file. The file is rotated when it
+// would grow beyond maxSizeInMB, the rotated file is renamed with the time of the rotation as suffix.
+// The file is never rotated when maxSizeInMB is 0.
+func NewFileSink(path string, maxSizeInMB int) (Sink, error) {
+	sink := &fileSink{
+		path:    path,
+		maxSize: int64(maxSizeInMB) * bytesInMB,
+	}
+	if err := sink.open(); err != nil {
+		return nil, err
+	}
+	return sink, nil
+}
+
+// NewKafkaSink creates a sink publishing the events as JSON, keyed by domain
+func NewKafkaSink(producer messaging.Producer) Sink {
+	return &kafkaSink{producer: producer}
+}
+
+// NewQueueSink creates a sink enqueuing the events as JSON to the audit queue. When retention is set, the events
+// older than the retention are periodically deleted from the queue until the sink is closed.
+func NewQueueSink(queue persistence.QueueManager, retention time.Duration, logger log.Logger) Sink {
+	s := &queueSink{
+		queue:     queue,
+		retention: retention,
+		logger:    logger,
+		doneCh:    make(chan struct{}),
+		stoppedCh: make(chan struct{}),
+	}
+	if retention > 0 {
+		go s.retentionLoop()
+	} else {
+		close(s.stoppedCh)
+	}
+	return s
+}
+
+// NewBufferedSink creates a sink emitting the events to the sink asynchronously, so that API calls are not
+// delayed by the sink. Emit fails without blocking when bufferSize events are waiting to be emitted, and the
+// events failing to be emitted to the sink are logged and counted. Close emits the events buffered before
+// closing the sink.
+func NewBufferedSink(
+	sink Sink,
+	bufferSize int,
+	emitTimeout time.Duration,
+	logger log.Logger,
+	metricsScope metrics.Scope,
+) Sink {
+	s := &bufferedSink{
+		sink:         sink,
+		emitTimeout:  emitTimeout,
+		logger:       logger,
+		metricsScope: metricsScope,
+		eventCh:      make(chan *Event, bufferSize),
+		doneCh:       make(chan struct{}),
+	}
+	go s.emitLoop()
+	return s
+}
+
+func (s *nopSink) Emit(ctx context.Context, event *Event) error {
+	return nil
+}
+
+func (s *nopSink) Close() error {
+	return nil
+}
+
+func (s *multiSink) Emit(ctx context.Context, event *Event) error {
+	var errs error
+	for _, sink := range s.sinks {
+		errs = multierr.Append(errs, sink.Emit(ctx, event))
+	}
+	return errs
+}
+
+func (s *multiSink) Close() error {
+	var errs error
+	for _, sink := range s.sinks {
+		errs = multierr.Append(errs, sink.Close())
+	}
+	return errs
+}
+
+func (s *fileSink) Emit(ctx context.Context, event *Event) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+	s.Lock()
+	defer s.Unlock()
+	if s.file == nil {
+		return errSinkClosed
+	}
+	if s.maxSize > 0 && s.size > 0 && s.size+int64(len(data)) > s.maxSize {
+		if err := s.rotate(); err != nil {
+			return err
+		}
+	}
+	n, err := s.file.Write(data)
+	s.size += int64(n)
+	return err
+}
+
+func (s *fileSink) Close() error {
+	s.Lock()
+	defer s.Unlock()
+	if s.file == nil {
+		return nil
+	}
+	err := s.file.Close()
+	s.file = nil
+	return err
+}
+
+func (s *fileSink) open() error {
+	file, err := os.OpenFile(s.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0640)
+	if err != nil {
+		return fmt.Errorf("failed to open audit file: %v", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("failed to open audit file: %v", err)
+	}
+	s.file = file
+	s.size = info.Size()
+	return nil
+}
+
+// rotate renames the file with the time of the rotation as suffix and opens a new file.
+// It's called with the lock held.
+func (s *fileSink) rotate() error {
+	if err := s.file.Close(); err != nil {
+		return fmt.Errorf("failed to rotate audit file: %v", err)
+	}
+	s.file = nil
+	rotatedPath := fmt.Sprintf("%v.%v", s.path, time.Now().UTC().Format(rotatedFileTimeFormat))
+	if err := os.Rename(s.path, rotatedPath); err != nil {
+		return fmt.Errorf("failed to rotate audit file: %v", err)
+	}
+	return s.open()
+}
+
+func (s *kafkaSink) Emit(ctx context.Context, event *Event) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	return s.producer.Publish(ctx, &messaging.RawMessage{Key: event.Domain, Value: data})
+}
+
+func (s *kafkaSink) Close() error {
+	if producer, ok := s.producer.(messaging.CloseableProducer); ok {
+		return producer.Close()
+	}
+	return nil
+}
+
+func (s *queueSink) Emit(ctx context.Context, event *Event) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	return s.queue.EnqueueMessage(ctx, data)
+}
+
+func (s *queueSink) Close() error {
+	close(s.doneCh)
+	<-s.stoppedCh
+	s.queue.Close()
+	return nil
+}
+
+func (s *queueSink) retentionLoop() {
+	defer close(s.stoppedCh)
+	ticker := time.NewTicker(retentionInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.doneCh:
+			return
+		case <-ticker.C:
+			// every frontend host runs the retention, deleting the expired events is idempotent
+			ctx, cancel := context.WithTimeout(context.Background(), retentionTimeout)
+			err := DeleteExpiredEvents(ctx, s.queue, s.retention, retentionPageSize)
+			cancel()
+			if err != nil {
+				s.logger.Warn("failed to delete expired audit events", tag.Error(err))
+			}
+		}
+	}
+}
+
+func (s *bufferedSink) Emit(ctx context.Context, event *Event) error {
+	s.RLock()
+	defer s.RUnlock()
+	if s.closed {
+		return errSinkClosed
+	}
+	select {
+	case s.eventCh <- event:
+		return nil
+	default:
+		return errBufferFull
+	}
+}
+
+func (s *bufferedSink) Close() error {
+	s.Lock()
+	if s.closed {
+		s.Unlock()
+		return nil
+	}
+	s.closed = true
+	close(s.eventCh)
+	s.Unlock()
+
+	<-s.doneCh
+	return s.sink.Close()
+}
+
+func (s *bufferedSink) emitLoop() {
+	defer close(s.doneCh)
+	for event := range s.eventCh {
+		ctx, cancel := context.WithTimeout(context.Background(), s.emitTimeout)
+		err := s.sink.Emit(ctx, event)
+		cancel()
+		if err != nil {
+			s.metricsScope.IncCounter(metrics.CadenceErrAuditFailedCounter)
+			s.logger.Error("failed to emit audit event",
+				tag.WorkflowDomainName(event.Domain),
+				tag.WorkflowID(event.WorkflowID),
+				tag.WorkflowRunID(event.RunID),
+				tag.Error(err))
+		}
+	}
+}
@@ -0,0 +1,128 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package authorization
+
+import (
+	"fmt"
+	"sort"
+)
+
+// AdminPermission is a fine-grained permission on a set of admin APIs
+type AdminPermission string
+
+const (
+	// AdminPermissionDLQ allows reading, merging and purging the DLQ messages
+	AdminPermissionDLQ AdminPermission = "dlq"
+	// AdminPermissionTask allows describing, resetting and refreshing the task queues and removing tasks
+	AdminPermissionTask AdminPermission = "task"
+	// AdminPermissionShard allows closing shards and describing the shard distribution
+	AdminPermissionShard AdminPermission = "shard"
+	// AdminPermissionDynamicConfig allows reading and updating the dynamic config
+	AdminPermissionDynamicConfig AdminPermission = "dynamicconfig"
+	// AdminPermissionFailover allows failing over domains
+	AdminPermissionFailover AdminPermission = "failover"
+	// AdminPermissionDomain allows registering, updating, deprecating and listing domains
+	AdminPermissionDomain AdminPermission = "domain"
+	// AdminPermissionSearchAttribute allows adding search attributes
+	AdminPermissionSearchAttribute AdminPermission = "searchattribute"
+	// AdminPermissionReplication allows reading the replication tasks and raw history and reapplying events
+	AdminPermissionReplication AdminPermission = "replication"
+	// AdminPermissionDescribe allows describing the cluster and the mutable state of workflows
+	AdminPermissionDescribe AdminPermission = "describe"
+)
+
+// FailoverDomainAPIName is the API name authorized for the UpdateDomain requests failing over a domain
+const FailoverDomainAPIName = "FailoverDomain"
+
+var adminAPIPermissions = map[string]AdminPermission{
+	"ReadDLQMessages":                  AdminPermissionDLQ,
+	"MergeDLQMessages":                 AdminPermissionDLQ,
+	"PurgeDLQMessages":                 AdminPermissionDLQ,
+	"RemoveTask":                       AdminPermissionTask,
+	"ResetQueue":                       AdminPermissionTask,
+	"DescribeQueue":                    AdminPermissionTask,
+	"RefreshWorkflowTasks":             AdminPermissionTask,
+	"CloseShard":                       AdminPermissionShard,
+	"DescribeShardDistribution":        AdminPermissionShard,
+	"DescribeHistoryHost":              AdminPermissionShard,
+	"GetDynamicConfig":                 AdminPermissionDynamicConfig,
+	"UpdateDynamicConfig":              AdminPermissionDynamicConfig,
+	"RestoreDynamicConfig":             AdminPermissionDynamicConfig,
+	"ListDynamicConfig":                AdminPermissionDynamicConfig,
+	FailoverDomainAPIName:              AdminPermissionFailover,
+	"RegisterDomain":                   AdminPermissionDomain,
+	"UpdateDomain":                     AdminPermissionDomain,
+	"DeprecateDomain":                  AdminPermissionDomain,
+	"ListDomains":                      AdminPermissionDomain,
+	"AddSearchAttribute":               AdminPermissionSearchAttribute,
+	"GetReplicationMessages":           AdminPermissionReplication,
+	"GetDomainReplicationMessages":     AdminPermissionReplication,
+	"GetDLQReplicationMessages":        AdminPermissionReplication,
+	"GetCrossClusterTasks":             AdminPermissionReplication,
+	"GetWorkflowExecutionRawHistoryV2": AdminPermissionReplication,
+	"ReapplyEvents":                    AdminPermissionReplication,
+	"ResendReplicationTasks":           AdminPermissionReplication,
+	"DescribeCluster":                  AdminPermissionDescribe,
+	"DescribeWorkflowExecution":        AdminPermissionDescribe,
+}
+
+// AdminPermissionOf returns the fine-grained permission required by the admin API,
+// the APIs without one are only allowed to admins
+func AdminPermissionOf(apiName string) (AdminPermission, bool) {
+	permission, ok := adminAPIPermissions[apiName]
+	return permission, ok
+}
+
+// adminPermissions maps the fine-grained admin permissions to the groups granted them by the server config
+type adminPermissions map[AdminPermission][]string
+
+func newAdminPermissions(cfg map[string][]string) (adminPermissions, error) {
+	if len(cfg) == 0 {
+		return nil, nil
+	}
+	known := map[AdminPermission]bool{}
+	for _, permission := range adminAPIPermissions {
+		known[permission] = true
+	}
+	permissions := adminPermissions{}
+	for name, groups := range cfg {
+		permission := AdminPermission(name)
+		if !known[permission] {
+			var names []string
+			for p := range known {
+				names = append(names, string(p))
+			}
+			sort.Strings(names)
+			return nil, fmt.Errorf("unknown admin permission %v, valid permissions are %v", name, names)
+		}
+		permissions[permission] = groups
+	}
+	return permissions, nil
+}
+
+// allows returns whether one of the groups is granted the admin permission required by the API
+func (p adminPermissions) allows(groups []string, attributes *Attributes) bool {
+	permission, ok := AdminPermissionOf(attributes.APIName)
+	if !ok {
+		return false
+	}
+	return containsAny(p[permission], groups)
+}
@@ -0,0 +1,67 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package authorization
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAdminPermissionOf(t *testing.T) {
+	permission, ok := AdminPermissionOf("PurgeDLQMessages")
+	assert.True(t, ok)
+	assert.Equal(t, AdminPermissionDLQ, permission)
+
+	permission, ok = AdminPermissionOf(FailoverDomainAPIName)
+	assert.True(t, ok)
+	assert.Equal(t, AdminPermissionFailover, permission)
+
+	_, ok = AdminPermissionOf("StartWorkflowExecution")
+	assert.False(t, ok)
+}
+
+func TestAdminPermissionsAllows(t *testing.T) {
+	permissions, err := newAdminPermissions(map[string][]string{
+		"task":     {"oncall"},
+		"failover": {"sre", "oncall"},
+	})
+	assert.NoError(t, err)
+
+	tests := []struct {
+		groups  []string
+		api     string
+		allowed bool
+	}{
+		{groups: []string{"oncall"}, api: "RemoveTask", allowed: true},
+		{groups: []string{"dev", "sre"}, api: FailoverDomainAPIName, allowed: true},
+		{groups: []string{"sre"}, api: "RemoveTask"},
+		{groups: []string{"oncall"}, api: "UpdateDomain"},
+		{groups: []string{"oncall"}, api: "UnknownAPI"},
+		{groups: nil, api: "RemoveTask"},
+	}
+	for _, test := range tests {
+		assert.Equal(t, test.allowed, permissions.allows(test.groups, &Attributes{APIName: test.api, Permission: PermissionAdmin}), "%+v", test)
+	}
+
+	_, err = newAdminPermissions(map[string][]string{"dlq": {"oncall"}, "everything": {"oncall"}})
+	assert.Error(t, err)
+}
@@ -0,0 +1,103 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package authorization
+
+import (
+	"context"
+	"fmt"
+
+	"go.uber.org/yarpc"
+
+	"github.com/uber/cadence/common"
+	"github.com/uber/cadence/common/cache"
+	"github.com/uber/cadence/common/log"
+	"github.com/uber/cadence/common/log/tag"
+)
+
+type apiKeyAuthority struct {
+	domainCache cache.DomainCache
+	log         log.Logger
+	fallback    Authorizer
+}
+
+var _ Authorizer = (*apiKeyAuthority)(nil)
+
+// NewAPIKeyAuthorizer creates an authorizer checking the API key of the request against the keys of the domain.
+// Requests without API key are authorized by the fallback authorizer, or denied if it's nil.
+func NewAPIKeyAuthorizer(
+	log log.Logger,
+	domainCache cache.DomainCache,
+	fallback Authorizer,
+) Authorizer {
+	return &apiKeyAuthority{
+		domainCache: domainCache,
+		log:         log,
+		fallback:    fallback,
+	}
+}
+
+// Authorize allows the request if its API key belongs to the domain and has the permission required by the API
+func (a *apiKeyAuthority) Authorize(
+	ctx context.Context,
+	attributes *Attributes,
+) (Result, error) {
+	call := yarpc.CallFromContext(ctx)
+	key := call.Header(common.APIKeyHeaderName)
+	if key == "" {
+		if a.fallback != nil {
+			return a.fallback.Authorize(ctx, attributes)
+		}
+		a.log.Debug("request is not authorized", tag.Error(fmt.Errorf("API key is not set in header")))
+		return Result{Decision: DecisionDeny}, nil
+	}
+	keyID, err := parseAPIKeyID(key)
+	if err != nil {
+		a.log.Debug("request is not authorized", tag.Error(err))
+		return Result{Decision: DecisionDeny}, nil
+	}
+	actor := "apikey:" + keyID
+	if attributes.DomainName == "" {
+		a.log.Debug("request is not authorized", tag.Error(fmt.Errorf("API keys are not valid for %v API", attributes.APIName)))
+		return Result{Decision: DecisionDeny, Actor: actor}, nil
+	}
+	domain, err := a.domainCache.GetDomain(attributes.DomainName)
+	if err != nil {
+		return Result{Decision: DecisionDeny, Actor: actor}, err
+	}
+	apiKeys, err := ParseAPIKeys(domain.GetInfo().Data)
+	if err != nil {
+		a.log.Warn("ignoring invalid API keys of domain", tag.WorkflowDomainName(attributes.DomainName), tag.Error(err))
+		return Result{Decision: DecisionDeny, Actor: actor}, nil
+	}
+	for i := range apiKeys {
+		if apiKeys[i].ID != keyID || !apiKeys[i].matches(key) {
+			continue
+		}
+		if !apiKeys[i].allows(attributes.Permission) {
+			a.log.Debug("request is not authorized", tag.Error(fmt.Errorf(
+				"API key %v with %v permission can't call %v API", keyID, apiKeys[i].Permission, attributes.APIName)))
+			return Result{Decision: DecisionDeny, Actor: actor}, nil
+		}
+		return Result{Decision: DecisionAllow, Actor: actor}, nil
+	}
+	a.log.Debug("request is not authorized", tag.Error(fmt.Errorf("API key %v doesn't exist in domain %v", keyID, attributes.DomainName)))
+	return Result{Decision: DecisionDeny, Actor: actor}, nil
+}
@@ -0,0 +1,144 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package authorization
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/yarpc/api/encoding"
+	"go.uber.org/yarpc/api/transport"
+
+	"github.com/uber/cadence/common"
+	"github.com/uber/cadence/common/cache"
+	"github.com/uber/cadence/common/log"
+	"github.com/uber/cadence/common/persistence"
+)
+
+type (
+	apiKeySuite struct {
+		suite.Suite
+		*require.Assertions
+
+		controller  *gomock.Controller
+		domainCache *cache.MockDomainCache
+		fallback    *MockAuthorizer
+		key         string
+		att         Attributes
+	}
+)
+
+func TestAPIKeySuite(t *testing.T) {
+	suite.Run(t, new(apiKeySuite))
+}
+
+func (s *apiKeySuite) SetupTest() {
+	s.Assertions = require.New(s.T())
+	s.controller = gomock.NewController(s.T())
+	s.domainCache = cache.NewMockDomainCache(s.controller)
+	s.fallback = NewMockAuthorizer(s.controller)
+
+	key, apiKey, err := NewAPIKey("write", "", time.Now())
+	s.NoError(err)
+	s.key = key
+	data, err := EncodeAPIKeys([]APIKey{*apiKey})
+	s.NoError(err)
+	domainEntry := cache.NewLocalDomainCacheEntryForTest(
+		&persistence.DomainInfo{ID: "test-domain-id", Name: "test-domain", Data: map[string]string{common.DomainDataKeyForAPIKeys: data}},
+		&persistence.DomainConfig{Retention: 1},
+		"",
+		nil,
+	)
+	s.domainCache.EXPECT().GetDomain("test-domain").Return(domainEntry, nil).AnyTimes()
+	s.domainCache.EXPECT().GetDomain("other-domain").Return(nil, errors.New("domain doesn't exist")).AnyTimes()
+	s.att = Attributes{APIName: "StartWorkflowExecution", DomainName: "test-domain", Permission: PermissionWrite}
+}
+
+func (s *apiKeySuite) TearDownTest() {
+	s.controller.Finish()
+}
+
+func (s *apiKeySuite) contextWithKey(key string) context.Context {
+	ctx, call := encoding.NewInboundCall(context.Background())
+	s.NoError(call.ReadFromRequest(&transport.Request{
+		Headers: transport.NewHeaders().With(common.APIKeyHeaderName, key),
+	}))
+	return ctx
+}
+
+func (s *apiKeySuite) TestValidKey() {
+	authorizer := NewAPIKeyAuthorizer(log.NewNoop(), s.domainCache, s.fallback)
+	id, err := parseAPIKeyID(s.key)
+	s.NoError(err)
+
+	result, err := authorizer.Authorize(s.contextWithKey(s.key), &s.att)
+	s.NoError(err)
+	s.Equal(DecisionAllow, result.Decision)
+	s.Equal("apikey:"+id, result.Actor)
+
+	s.att.Permission = PermissionAdmin
+	result, err = authorizer.Authorize(s.contextWithKey(s.key), &s.att)
+	s.NoError(err)
+	s.Equal(DecisionDeny, result.Decision)
+}
+
+func (s *apiKeySuite) TestInvalidKey() {
+	authorizer := NewAPIKeyAuthorizer(log.NewNoop(), s.domainCache, s.fallback)
+	otherKey, _, err := NewAPIKey("write", "", time.Now())
+	s.NoError(err)
+
+	for _, key := range []string{otherKey, s.key + "0", "malformed"} {
+		result, err := authorizer.Authorize(s.contextWithKey(key), &s.att)
+		s.NoError(err)
+		s.Equal(DecisionDeny, result.Decision, key)
+	}
+}
+
+func (s *apiKeySuite) TestKeyOfOtherDomain() {
+	authorizer := NewAPIKeyAuthorizer(log.NewNoop(), s.domainCache, s.fallback)
+
+	s.att.DomainName = "other-domain"
+	result, err := authorizer.Authorize(s.contextWithKey(s.key), &s.att)
+	s.Error(err)
+	s.Equal(DecisionDeny, result.Decision)
+
+	// admin APIs without domain
+	s.att = Attributes{APIName: "CloseShard", Permission: PermissionAdmin}
+	result, err = authorizer.Authorize(s.contextWithKey(s.key), &s.att)
+	s.NoError(err)
+	s.Equal(DecisionDeny, result.Decision)
+}
+
+func (s *apiKeySuite) TestNoKey() {
+	s.fallback.EXPECT().Authorize(gomock.Any(), &s.att).Return(Result{Decision: DecisionAllow}, nil).Times(1)
+	result, err := NewAPIKeyAuthorizer(log.NewNoop(), s.domainCache, s.fallback).Authorize(context.Background(), &s.att)
+	s.NoError(err)
+	s.Equal(DecisionAllow, result.Decision)
+
+	result, err = NewAPIKeyAuthorizer(log.NewNoop(), s.domainCache, nil).Authorize(context.Background(), &s.att)
+	s.NoError(err)
+	s.Equal(DecisionDeny, result.Decision)
+}
@@ -0,0 +1,213 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package authorization
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/uber/cadence/common"
+	"github.com/uber/cadence/common/types"
+)
+
+type (
+	// APIKey is an API key of a domain, only the hash of the key is stored in the domain data
+	APIKey struct {
+		ID          string    `json:"id"`
+		Hash        string    `json:"hash"`
+		Permission  string    `json:"permission"`
+		Description string    `json:"description,omitempty"`
+		CreatedTime time.Time `json:"createdTime"`
+	}
+)
+
+const (
+	apiKeyPrefix    = "cadence"
+	apiKeySeparator = "."
+	apiKeyIDBytes   = 8
+	apiKeySecret    = 32
+)
+
+// NewAPIKey generates an API key with the permission, read, write or admin. The key is returned only once,
+// the APIKey stored in the domain data keeps its hash.
+func NewAPIKey(permission string, description string, now time.Time) (string, *APIKey, error) {
+	if NewPermission(permission) < 0 {
+		return "", nil, fmt.Errorf("invalid API key permission %v, valid permissions are read, write and admin", permission)
+	}
+	id, err := randomHex(apiKeyIDBytes)
+	if err != nil {
+		return "", nil, err
+	}
+	secret, err := randomHex(apiKeySecret)
+	if err != nil {
+		return "", nil, err
+	}
+	key := strings.Join([]string{apiKeyPrefix, id, secret}, apiKeySeparator)
+	return key, &APIKey{
+		ID:          id,
+		Hash:        hashAPIKey(key),
+		Permission:  permission,
+		Description: description,
+		CreatedTime: now,
+	}, nil
+}
+
+// ParseAPIKeys parses the API keys stored in the domain data
+func ParseAPIKeys(domainData map[string]string) ([]APIKey, error) {
+	data, ok := domainData[common.DomainDataKeyForAPIKeys]
+	if !ok || data == "" {
+		return nil, nil
+	}
+	var apiKeys []APIKey
+	if err := json.Unmarshal([]byte(data), &apiKeys); err != nil {
+		return nil, fmt.Errorf("invalid API keys: %v", err)
+	}
+	for i, apiKey := range apiKeys {
+		if err := apiKey.validate(); err != nil {
+			return nil, fmt.Errorf("invalid API keys: key %v %v", i, err)
+		}
+	}
+	return apiKeys, nil
+}
+
+// ApplyAPIKeyOperations returns the data of an UpdateDomain request with its API key operations, the reserved
+// DomainDataKeyForCreateAPIKey and DomainDataKeyForRevokeAPIKey keys, replaced by the API keys resulting from
+// applying them to the current domain data. The domain handler calls it within the domain update, which is
+// conditional on the notification version of the domains, so that concurrent operations on the API keys of a
+// domain don't overwrite each other.
+func ApplyAPIKeyOperations(domainData map[string]string, requestData map[string]string) (map[string]string, error) {
+	created, create := requestData[common.DomainDataKeyForCreateAPIKey]
+	revokedID, revoke := requestData[common.DomainDataKeyForRevokeAPIKey]
+	if !create && !revoke {
+		return requestData, nil
+	}
+	if _, ok := requestData[common.DomainDataKeyForAPIKeys]; ok {
+		return nil, &types.BadRequestError{Message: "API keys can't be set along with API key operations."}
+	}
+	apiKeys, err := ParseAPIKeys(domainData)
+	if err != nil {
+		return nil, &types.BadRequestError{Message: err.Error()}
+	}
+	if revoke {
+		remaining := make([]APIKey, 0, len(apiKeys))
+		for _, apiKey := range apiKeys {
+			if apiKey.ID != revokedID {
+				remaining = append(remaining, apiKey)
+			}
+		}
+		if len(remaining) == len(apiKeys) {
+			return nil, &types.EntityNotExistsError{Message: fmt.Sprintf("API key %v does not exist.", revokedID)}
+		}
+		apiKeys = remaining
+	}
+	if create {
+		var apiKey APIKey
+		if err := json.Unmarshal([]byte(created), &apiKey); err != nil {
+			return nil, &types.BadRequestError{Message: fmt.Sprintf("Invalid API key: %v", err)}
+		}
+		if err := apiKey.validate(); err != nil {
+			return nil, &types.BadRequestError{Message: fmt.Sprintf("Invalid API key: key %v", err)}
+		}
+		for _, existing := range apiKeys {
+			if existing.ID == apiKey.ID {
+				return nil, &types.BadRequestError{Message: fmt.Sprintf("API key %v already exists.", apiKey.ID)}
+			}
+		}
+		apiKeys = append(apiKeys, apiKey)
+	}
+	data, err := EncodeAPIKeys(apiKeys)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]string, len(requestData))
+	for key, value := range requestData {
+		if key != common.DomainDataKeyForCreateAPIKey && key != common.DomainDataKeyForRevokeAPIKey {
+			result[key] = value
+		}
+	}
+	result[common.DomainDataKeyForAPIKeys] = data
+	return result, nil
+}
+
+// EncodeAPIKeys encodes the API keys to be stored in the domain data
+func EncodeAPIKeys(apiKeys []APIKey) (string, error) {
+	if apiKeys == nil {
+		apiKeys = []APIKey{}
+	}
+	data, err := json.Marshal(apiKeys)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// validate returns an error if the API key can't be used to authorize requests
+func (k *APIKey) validate() error {
+	if k.ID == "" || k.Hash == "" {
+		return fmt.Errorf("doesn't have an id or a hash")
+	}
+	if NewPermission(k.Permission) < 0 {
+		return fmt.Errorf("has invalid permission %v", k.Permission)
+	}
+	return nil
+}
+
+// matches returns whether the key is the one this API key was generated for
+func (k *APIKey) matches(key string) bool {
+	return subtle.ConstantTimeCompare([]byte(k.Hash), []byte(hashAPIKey(key))) == 1
+}
+
+// allows returns whether the permission of the API key covers the permission required by the API
+func (k *APIKey) allows(permission Permission) bool {
+	if permission == PermissionSensitiveRead {
+		return NewPermission(k.Permission) == PermissionAdmin
+	}
+	return permission > 0 && permission <= NewPermission(k.Permission)
+}
+
+// parseAPIKeyID returns the id of the API key, which identifies the key among the keys of the domain
+func parseAPIKeyID(key string) (string, error) {
+	parts := strings.Split(key, apiKeySeparator)
+	if len(parts) != 3 || parts[0] != apiKeyPrefix || parts[1] == "" || parts[2] == "" {
+		return "", fmt.Errorf("malformed API key")
+	}
+	return parts[1], nil
+}
+
+func hashAPIKey(key string) string {
+	hash := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(hash[:])
+}
+
+func randomHex(n int) (string, error) {
+	data := make([]byte, n)
+	if _, err := rand.Read(data); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(data), nil
+}
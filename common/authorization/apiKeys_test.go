@@ -0,0 +1,152 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package authorization
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/uber/cadence/common"
+	"github.com/uber/cadence/common/types"
+)
+
+func TestNewAPIKey(t *testing.T) {
+	now := time.Unix(1630000000, 0).UTC()
+	key, apiKey, err := NewAPIKey("write", "payments workers", now)
+	assert.NoError(t, err)
+	assert.NotContains(t, apiKey.Hash, key)
+	assert.Equal(t, "write", apiKey.Permission)
+	assert.Equal(t, "payments workers", apiKey.Description)
+	assert.Equal(t, now, apiKey.CreatedTime)
+	assert.True(t, apiKey.matches(key))
+	assert.False(t, apiKey.matches(key+"0"))
+
+	id, err := parseAPIKeyID(key)
+	assert.NoError(t, err)
+	assert.Equal(t, apiKey.ID, id)
+
+	otherKey, _, err := NewAPIKey("write", "", now)
+	assert.NoError(t, err)
+	assert.NotEqual(t, key, otherKey)
+
+	_, _, err = NewAPIKey("owner", "", now)
+	assert.Error(t, err)
+}
+
+func TestParseAPIKeyID(t *testing.T) {
+	for _, key := range []string{"", "token", "cadence.id", "other.id.secret", "cadence..secret", "cadence.id.secret.more"} {
+		_, err := parseAPIKeyID(key)
+		assert.Error(t, err, key)
+	}
+}
+
+func TestParseAPIKeys(t *testing.T) {
+	apiKeys, err := ParseAPIKeys(nil)
+	assert.NoError(t, err)
+	assert.Nil(t, apiKeys)
+
+	_, apiKey, err := NewAPIKey("read", "", time.Now())
+	assert.NoError(t, err)
+	data, err := EncodeAPIKeys([]APIKey{*apiKey})
+	assert.NoError(t, err)
+	apiKeys, err = ParseAPIKeys(map[string]string{common.DomainDataKeyForAPIKeys: data})
+	assert.NoError(t, err)
+	assert.Len(t, apiKeys, 1)
+	assert.Equal(t, apiKey.ID, apiKeys[0].ID)
+	assert.Equal(t, apiKey.Hash, apiKeys[0].Hash)
+
+	data, err = EncodeAPIKeys(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "[]", data)
+
+	for _, invalid := range []string{
+		`{"id": "a"}`,
+		`[{"id": "a", "permission": "read"}]`,
+		`[{"id": "a", "hash": "b", "permission": "owner"}]`,
+	} {
+		_, err := ParseAPIKeys(map[string]string{common.DomainDataKeyForAPIKeys: invalid})
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestAPIKeyAllows(t *testing.T) {
+	read := APIKey{Permission: "read"}
+	assert.True(t, read.allows(PermissionRead))
+	assert.False(t, read.allows(PermissionWrite))
+	assert.False(t, read.allows(PermissionAdmin))
+
+	write := APIKey{Permission: "write"}
+	assert.True(t, write.allows(PermissionRead))
+	assert.True(t, write.allows(PermissionWrite))
+	assert.False(t, write.allows(PermissionAdmin))
+	assert.False(t, write.allows(Permission(-1)))
+	assert.False(t, write.allows(PermissionSensitiveRead))
+
+	admin := APIKey{Permission: "admin"}
+	assert.True(t, admin.allows(PermissionSensitiveRead))
+}
+
+func TestApplyAPIKeyOperations(t *testing.T) {
+	_, existing, err := NewAPIKey("read", "", time.Now())
+	assert.NoError(t, err)
+	existingData, err := EncodeAPIKeys([]APIKey{*existing})
+	assert.NoError(t, err)
+	domainData := map[string]string{common.DomainDataKeyForAPIKeys: existingData}
+
+	requestData := map[string]string{"team": "payments"}
+	data, err := ApplyAPIKeyOperations(domainData, requestData)
+	assert.NoError(t, err)
+	assert.Equal(t, requestData, data)
+
+	_, created, err := NewAPIKey("write", "", time.Now())
+	assert.NoError(t, err)
+	encoded, err := json.Marshal(created)
+	assert.NoError(t, err)
+	data, err = ApplyAPIKeyOperations(domainData, map[string]string{
+		"team":                              "payments",
+		common.DomainDataKeyForCreateAPIKey: string(encoded),
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "payments", data["team"])
+	assert.NotContains(t, data, common.DomainDataKeyForCreateAPIKey)
+	apiKeys, err := ParseAPIKeys(data)
+	assert.NoError(t, err)
+	assert.Len(t, apiKeys, 2)
+	assert.Equal(t, existing.ID, apiKeys[0].ID)
+	assert.Equal(t, created.ID, apiKeys[1].ID)
+
+	data, err = ApplyAPIKeyOperations(domainData, map[string]string{common.DomainDataKeyForRevokeAPIKey: existing.ID})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{common.DomainDataKeyForAPIKeys: "[]"}, data)
+
+	_, err = ApplyAPIKeyOperations(domainData, map[string]string{common.DomainDataKeyForRevokeAPIKey: "unknown"})
+	assert.IsType(t, &types.EntityNotExistsError{}, err)
+	_, err = ApplyAPIKeyOperations(domainData, map[string]string{common.DomainDataKeyForCreateAPIKey: `{"id": "a"}`})
+	assert.IsType(t, &types.BadRequestError{}, err)
+	_, err = ApplyAPIKeyOperations(domainData, map[string]string{
+		common.DomainDataKeyForAPIKeys:      existingData,
+		common.DomainDataKeyForCreateAPIKey: string(encoded),
+	})
+	assert.IsType(t, &types.BadRequestError{}, err)
+}
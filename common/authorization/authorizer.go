@@ -46,6 +46,8 @@ const (
 	PermissionWrite
 	// PermissionAdmin means the user can read+write on the domain level APIs
 	PermissionAdmin
+	// PermissionSensitiveRead means the user can read the payloads the domain redacts for the other readers
+	PermissionSensitiveRead
 )
 
 type (
@@ -63,6 +65,8 @@ type (
 	// Result is result from authority.
 	Result struct {
 		Decision Decision
+		// Actor is the identity of the caller, if known by the authority
+		Actor string
 	}
 
 	// Decision is enum type for auth decision
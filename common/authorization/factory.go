@@ -27,9 +27,24 @@ import (
 )
 
 func NewAuthorizer(authorization config.Authorization, logger log.Logger, domainCache cache.DomainCache) (Authorizer, error) {
+	authorizer, err := newAuthorizer(authorization, logger, domainCache)
+	if err != nil || !authorization.APIKeyAuthorizer.Enable {
+		return authorizer, err
+	}
+	// the requests without API key are left to the other authorizer, if any
+	var fallback Authorizer
+	if authorization.OAuthAuthorizer.Enable || authorization.MTLSAuthorizer.Enable || authorization.NoopAuthorizer.Enable {
+		fallback = authorizer
+	}
+	return NewAPIKeyAuthorizer(logger, domainCache, fallback), nil
+}
+
+func newAuthorizer(authorization config.Authorization, logger log.Logger, domainCache cache.DomainCache) (Authorizer, error) {
 	switch true {
 	case authorization.OAuthAuthorizer.Enable:
-		return NewOAuthAuthorizer(authorization.OAuthAuthorizer, logger, domainCache)
+		return NewOAuthAuthorizer(authorization.OAuthAuthorizer, authorization.AdminPermissions, logger, domainCache)
+	case authorization.MTLSAuthorizer.Enable:
+		return NewMTLSAuthorizer(authorization.MTLSAuthorizer, authorization.AdminPermissions, logger, domainCache)
 	default:
 		return NewNopAuthorizer()
 	}
@@ -67,6 +67,8 @@ func cfgOAuth() config.Authorization {
 				PublicKey: "../../config/credentials/keytest.pub",
 			},
 			MaxJwtTTL: 12345,
+			// the token cache is not comparable
+			TokenCacheSize: -1,
 		},
 	}
 }
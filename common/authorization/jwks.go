@@ -0,0 +1,338 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package authorization
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cristalhq/jwt/v3"
+
+	"github.com/uber/cadence/common/config"
+	"github.com/uber/cadence/common/log"
+	"github.com/uber/cadence/common/log/tag"
+)
+
+const (
+	defaultJWKSRefreshInterval = time.Hour
+	// jwksMinRefreshInterval limits the refreshes triggered by tokens signed by unknown keys
+	jwksMinRefreshInterval = time.Minute
+	jwksFetchTimeout       = 10 * time.Second
+	jwksMaxDocumentSize    = 1 << 20
+
+	oidcConfigurationPath = "/.well-known/openid-configuration"
+)
+
+type (
+	// jwksKeySet caches the keys of the JWKS document of an identity provider,
+	// the document is refreshed periodically and when a token is signed by an unknown key
+	jwksKeySet struct {
+		provider        config.OAuthProvider
+		refreshInterval time.Duration
+		httpClient      *http.Client
+		log             log.Logger
+
+		refreshLock sync.Mutex
+		sync.RWMutex
+		jwksURL     string
+		keys        map[string]*publicJWK
+		refreshedAt time.Time
+		attemptedAt time.Time
+	}
+
+	publicJWK struct {
+		key crypto.PublicKey
+		// algorithm is the alg of the JWK, empty if the key can be used with any algorithm of its type
+		algorithm jwt.Algorithm
+	}
+
+	jsonWebKeySet struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+
+	jsonWebKey struct {
+		Kty string `json:"kty"`
+		Kid string `json:"kid"`
+		Use string `json:"use"`
+		Alg string `json:"alg"`
+		Crv string `json:"crv"`
+		N   string `json:"n"`
+		E   string `json:"e"`
+		X   string `json:"x"`
+		Y   string `json:"y"`
+	}
+
+	oidcConfiguration struct {
+		Issuer  string `json:"issuer"`
+		JWKSURI string `json:"jwks_uri"`
+	}
+
+	// jwtKeyError is returned when the key of a token can't be found or used
+	jwtKeyError struct {
+		err error
+	}
+)
+
+func newJWKSKeySet(provider config.OAuthProvider, log log.Logger) *jwksKeySet {
+	refreshInterval := provider.RefreshInterval
+	if refreshInterval <= 0 {
+		refreshInterval = defaultJWKSRefreshInterval
+	}
+	return &jwksKeySet{
+		provider:        provider,
+		refreshInterval: refreshInterval,
+		httpClient:      &http.Client{Timeout: jwksFetchTimeout},
+		log:             log,
+		jwksURL:         provider.JWKSURL,
+	}
+}
+
+// getKey returns the key of the kid, which can be empty if the document has a single key
+func (s *jwksKeySet) getKey(kid string) (*publicJWK, error) {
+	now := time.Now()
+	s.RLock()
+	key := s.lookup(kid)
+	refreshedAt := s.refreshedAt
+	canRefresh := now.Sub(s.attemptedAt) >= jwksMinRefreshInterval
+	stale := now.Sub(refreshedAt) >= s.refreshInterval
+	s.RUnlock()
+
+	if canRefresh && (key == nil || stale) {
+		if err := s.refresh(refreshedAt); err != nil {
+			s.log.Warn("failed to refresh JWKS", tag.Error(err))
+			s.RLock()
+			loaded := s.keys != nil
+			s.RUnlock()
+			if !loaded {
+				return nil, err
+			}
+		}
+		s.RLock()
+		key = s.lookup(kid)
+		s.RUnlock()
+	}
+	if key == nil {
+		return nil, &jwtKeyError{fmt.Errorf("JWKS doesn't have key %v", kid)}
+	}
+	return key, nil
+}
+
+func (s *jwksKeySet) lookup(kid string) *publicJWK {
+	if kid == "" && len(s.keys) == 1 {
+		for _, key := range s.keys {
+			return key
+		}
+	}
+	return s.keys[kid]
+}
+
+// refresh fetches the JWKS document, unless it was refreshed by another caller since refreshedAt
+func (s *jwksKeySet) refresh(refreshedAt time.Time) error {
+	s.refreshLock.Lock()
+	defer s.refreshLock.Unlock()
+
+	s.RLock()
+	done := !s.refreshedAt.Equal(refreshedAt)
+	jwksURL := s.jwksURL
+	s.RUnlock()
+	if done {
+		return nil
+	}
+
+	s.Lock()
+	s.attemptedAt = time.Now()
+	s.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), jwksFetchTimeout)
+	defer cancel()
+	if jwksURL == "" {
+		discovered, err := s.discoverJWKSURL(ctx)
+		if err != nil {
+			return err
+		}
+		jwksURL = discovered
+	}
+
+	document, err := s.fetch(ctx, jwksURL)
+	if err != nil {
+		return err
+	}
+	keys, err := s.parseKeySet(document)
+	if err != nil {
+		return err
+	}
+
+	s.Lock()
+	defer s.Unlock()
+	s.jwksURL = jwksURL
+	s.keys = keys
+	s.refreshedAt = time.Now()
+	return nil
+}
+
+func (s *jwksKeySet) discoverJWKSURL(ctx context.Context) (string, error) {
+	document, err := s.fetch(ctx, strings.TrimSuffix(s.provider.Issuer, "/")+oidcConfigurationPath)
+	if err != nil {
+		return "", err
+	}
+	var configuration oidcConfiguration
+	if err := json.Unmarshal(document, &configuration); err != nil {
+		return "", fmt.Errorf("invalid OpenID configuration: %v", err)
+	}
+	if configuration.Issuer != s.provider.Issuer {
+		return "", fmt.Errorf("issuer %v of OpenID configuration doesn't match %v", configuration.Issuer, s.provider.Issuer)
+	}
+	if configuration.JWKSURI == "" {
+		return "", fmt.Errorf("OpenID configuration of %v doesn't have jwks_uri", s.provider.Issuer)
+	}
+	return configuration.JWKSURI, nil
+}
+
+func (s *jwksKeySet) fetch(ctx context.Context, location string) ([]byte, error) {
+	locationURL, err := url.Parse(location)
+	if err != nil {
+		return nil, err
+	}
+	if locationURL.Scheme == "file" {
+		return ioutil.ReadFile(locationURL.Path)
+	}
+
+	request, err := http.NewRequest(http.MethodGet, location, nil)
+	if err != nil {
+		return nil, err
+	}
+	response, err := s.httpClient.Do(request.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetching %v failed with status %v", location, response.Status)
+	}
+	return ioutil.ReadAll(io.LimitReader(response.Body, jwksMaxDocumentSize))
+}
+
+func (s *jwksKeySet) parseKeySet(document []byte) (map[string]*publicJWK, error) {
+	var keySet jsonWebKeySet
+	if err := json.Unmarshal(document, &keySet); err != nil {
+		return nil, fmt.Errorf("invalid JWKS: %v", err)
+	}
+	keys := make(map[string]*publicJWK, len(keySet.Keys))
+	for _, jwk := range keySet.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		key, err := jwk.publicKey()
+		if err != nil {
+			// a single unsupported key must not invalidate the whole document
+			s.log.Warn("skipping JWKS key", tag.Value(jwk.Kid), tag.Error(err))
+			continue
+		}
+		keys[jwk.Kid] = &publicJWK{key: key, algorithm: jwt.Algorithm(jwk.Alg)}
+	}
+	return keys, nil
+}
+
+func (jwk *jsonWebKey) publicKey() (crypto.PublicKey, error) {
+	switch jwk.Kty {
+	case "RSA":
+		n, err := decodeJWKInt(jwk.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeJWKInt(jwk.E)
+		if err != nil {
+			return nil, err
+		}
+		if !e.IsInt64() || e.Int64() > int64(^uint32(0)>>1) {
+			return nil, fmt.Errorf("invalid RSA exponent")
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch jwk.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("curve %v is not supported", jwk.Crv)
+		}
+		x, err := decodeJWKInt(jwk.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeJWKInt(jwk.Y)
+		if err != nil {
+			return nil, err
+		}
+		if !curve.IsOnCurve(x, y) {
+			return nil, fmt.Errorf("point is not on curve %v", jwk.Crv)
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	case "OKP":
+		if jwk.Crv != "Ed25519" {
+			return nil, fmt.Errorf("curve %v is not supported", jwk.Crv)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(jwk.X)
+		if err != nil {
+			return nil, err
+		}
+		if len(x) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("invalid Ed25519 key size %v", len(x))
+		}
+		return ed25519.PublicKey(x), nil
+	default:
+		return nil, fmt.Errorf("key type %v is not supported", jwk.Kty)
+	}
+}
+
+func decodeJWKInt(value string) (*big.Int, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) == 0 {
+		return nil, fmt.Errorf("empty key parameter")
+	}
+	return new(big.Int).SetBytes(data), nil
+}
+
+func (e *jwtKeyError) Error() string {
+	return e.err.Error()
+}
@@ -0,0 +1,341 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package authorization
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/yarpc/api/encoding"
+	"go.uber.org/yarpc/api/transport"
+	"golang.org/x/net/context"
+
+	"github.com/uber/cadence/common"
+	"github.com/uber/cadence/common/cache"
+	"github.com/uber/cadence/common/config"
+	"github.com/uber/cadence/common/log"
+	"github.com/uber/cadence/common/log/loggerimpl"
+)
+
+type (
+	jwksSuite struct {
+		suite.Suite
+		logger      log.Logger
+		controller  *gomock.Controller
+		domainCache *cache.MockDomainCache
+		dir         string
+		jwksPath    string
+		ecKey       *ecdsa.PrivateKey
+		edKey       ed25519.PrivateKey
+	}
+)
+
+func TestJWKSSuite(t *testing.T) {
+	suite.Run(t, new(jwksSuite))
+}
+
+func (s *jwksSuite) SetupTest() {
+	s.logger = loggerimpl.NewLoggerForTest(s.Suite)
+	s.controller = gomock.NewController(s.T())
+	s.domainCache = cache.NewMockDomainCache(s.controller)
+
+	var err error
+	s.ecKey, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	s.NoError(err)
+	_, s.edKey, err = ed25519.GenerateKey(rand.Reader)
+	s.NoError(err)
+
+	s.dir, err = ioutil.TempDir("", "jwks")
+	s.NoError(err)
+	s.jwksPath = filepath.Join(s.dir, "jwks.json")
+	s.writeJWKS(s.jwksPath, s.ecJWK("ec-1"), s.edJWK("ed-1"))
+}
+
+func (s *jwksSuite) TearDownTest() {
+	s.controller.Finish()
+	os.RemoveAll(s.dir)
+}
+
+func (s *jwksSuite) TestAuthorize_JWKSFile() {
+	authorizer := s.newAuthorizer(config.OAuthProvider{
+		JWKSURL:  "file://" + s.jwksPath,
+		Issuer:   "https://idp.example.com",
+		Audience: "cadence",
+	})
+
+	for _, token := range []string{
+		s.signES256("ec-1", s.adminClaims()),
+		s.signEdDSA("ed-1", s.adminClaims()),
+	} {
+		result, err := authorizer.Authorize(s.callContext(token), &Attributes{DomainName: "test-domain"})
+		s.NoError(err)
+		s.Equal(DecisionAllow, result.Decision)
+	}
+}
+
+func (s *jwksSuite) TestAuthorize_OIDCDiscovery() {
+	var issuer string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case oidcConfigurationPath:
+			_ = json.NewEncoder(w).Encode(map[string]string{"issuer": issuer, "jwks_uri": issuer + "/keys"})
+		case "/keys":
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"keys": []map[string]string{s.edJWK("ed-1")}})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	issuer = server.URL
+
+	authorizer := s.newAuthorizer(config.OAuthProvider{Issuer: issuer})
+	claims := s.adminClaims()
+	claims["iss"] = issuer
+	result, err := authorizer.Authorize(s.callContext(s.signEdDSA("ed-1", claims)), &Attributes{DomainName: "test-domain"})
+	s.NoError(err)
+	s.Equal(DecisionAllow, result.Decision)
+}
+
+func (s *jwksSuite) TestAuthorize_KeyRotation() {
+	authorizer := s.newAuthorizer(config.OAuthProvider{JWKSURL: "file://" + s.jwksPath})
+	token := s.signES256("ec-2", s.adminClaims())
+
+	result, err := authorizer.Authorize(s.callContext(s.signES256("ec-1", s.adminClaims())), &Attributes{})
+	s.NoError(err)
+	s.Equal(DecisionAllow, result.Decision)
+
+	s.writeJWKS(s.jwksPath, s.ecJWK("ec-1"), s.ecJWK("ec-2"))
+	// unknown keys don't trigger a refresh more than once a minute
+	result, err = authorizer.Authorize(s.callContext(token), &Attributes{})
+	s.NoError(err)
+	s.Equal(DecisionDeny, result.Decision)
+
+	authorizer.(*oauthAuthority).keySet.attemptedAt = time.Now().Add(-jwksMinRefreshInterval)
+	result, err = authorizer.Authorize(s.callContext(token), &Attributes{})
+	s.NoError(err)
+	s.Equal(DecisionAllow, result.Decision)
+}
+
+func (s *jwksSuite) TestAuthorize_InvalidClaims() {
+	authorizer := s.newAuthorizer(config.OAuthProvider{
+		JWKSURL:  "file://" + s.jwksPath,
+		Issuer:   "https://idp.example.com",
+		Audience: "cadence",
+	})
+	now := time.Now().Unix()
+
+	tests := map[string]func(claims map[string]interface{}){
+		"expired":          func(claims map[string]interface{}) { claims["exp"] = now - 2*jwtLeeway },
+		"no expiration":    func(claims map[string]interface{}) { delete(claims, "exp") },
+		"not valid yet":    func(claims map[string]interface{}) { claims["nbf"] = now + 2*jwtLeeway },
+		"wrong issuer":     func(claims map[string]interface{}) { claims["iss"] = "https://other.example.com" },
+		"wrong audience":   func(claims map[string]interface{}) { claims["aud"] = []string{"other"} },
+		"missing audience": func(claims map[string]interface{}) { delete(claims, "aud") },
+	}
+	for name, update := range tests {
+		claims := s.adminClaims()
+		update(claims)
+		result, err := authorizer.Authorize(s.callContext(s.signES256("ec-1", claims)), &Attributes{})
+		s.NoError(err, name)
+		s.Equal(DecisionDeny, result.Decision, name)
+	}
+}
+
+func (s *jwksSuite) TestAuthorize_MaxJwtTTL() {
+	provider := config.OAuthProvider{
+		JWKSURL:  "file://" + s.jwksPath,
+		Issuer:   "https://idp.example.com",
+		Audience: "cadence",
+	}
+	authorizer, err := NewOAuthAuthorizer(config.OAuthAuthorizer{Enable: true, MaxJwtTTL: 300, Provider: &provider}, nil, s.logger, s.domainCache)
+	s.NoError(err)
+	now := time.Now().Unix()
+
+	tests := map[string]struct {
+		update   func(claims map[string]interface{})
+		decision Decision
+	}{
+		"ttl too large": {
+			update:   func(claims map[string]interface{}) {},
+			decision: DecisionDeny,
+		},
+		"ttl allowed": {
+			update:   func(claims map[string]interface{}) { claims["exp"] = now + 200 },
+			decision: DecisionAllow,
+		},
+		"no issued at, expiration too far": {
+			update:   func(claims map[string]interface{}) { delete(claims, "iat") },
+			decision: DecisionDeny,
+		},
+		"no issued at, expiration allowed": {
+			update: func(claims map[string]interface{}) {
+				delete(claims, "iat")
+				claims["exp"] = now + 200
+			},
+			decision: DecisionAllow,
+		},
+	}
+	for name, test := range tests {
+		claims := s.adminClaims()
+		test.update(claims)
+		result, err := authorizer.Authorize(s.callContext(s.signES256("ec-1", claims)), &Attributes{DomainName: "test-domain"})
+		s.NoError(err, name)
+		s.Equal(test.decision, result.Decision, name)
+	}
+}
+
+func (s *jwksSuite) TestAuthorize_InvalidToken() {
+	authorizer := s.newAuthorizer(config.OAuthProvider{JWKSURL: "file://" + s.jwksPath})
+	otherKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	s.NoError(err)
+	s.ecKey = otherKey
+
+	for _, token := range []string{
+		"test",
+		s.signES256("unknown", s.adminClaims()),
+		// signed by a key different from the one in JWKS
+		s.signES256("ec-1", s.adminClaims()),
+		// algorithm doesn't match the key
+		s.sign("ed-1", "ES256", s.adminClaims(), func(payload []byte) []byte { return ed25519.Sign(s.edKey, payload) }),
+	} {
+		result, err := authorizer.Authorize(s.callContext(token), &Attributes{})
+		s.NoError(err)
+		s.Equal(DecisionDeny, result.Decision)
+	}
+}
+
+func (s *jwksSuite) TestAuthorize_JWKSUnavailable() {
+	authorizer := s.newAuthorizer(config.OAuthProvider{JWKSURL: "file://" + filepath.Join(s.dir, "missing.json")})
+	result, err := authorizer.Authorize(s.callContext(s.signES256("ec-1", s.adminClaims())), &Attributes{})
+	s.Error(err)
+	s.Equal(DecisionDeny, result.Decision)
+}
+
+func (s *jwksSuite) TestParseKeySet_SkipsUnsupportedKeys() {
+	keySet := newJWKSKeySet(config.OAuthProvider{}, s.logger)
+	document, err := json.Marshal(map[string]interface{}{"keys": []map[string]string{
+		s.ecJWK("ec-1"),
+		{"kty": "oct", "kid": "hmac", "k": "c2VjcmV0"},
+		{"kty": "EC", "kid": "enc", "use": "enc", "crv": "P-256"},
+		{"kty": "EC", "kid": "bad", "crv": "P-256", "x": "AQ", "y": "AQ"},
+	}})
+	s.NoError(err)
+	keys, err := keySet.parseKeySet(document)
+	s.NoError(err)
+	s.Len(keys, 1)
+	s.Equal(&s.ecKey.PublicKey, keys["ec-1"].key)
+}
+
+func (s *jwksSuite) newAuthorizer(provider config.OAuthProvider) Authorizer {
+	authorizer, err := NewOAuthAuthorizer(config.OAuthAuthorizer{Enable: true, Provider: &provider}, nil, s.logger, s.domainCache)
+	s.NoError(err)
+	return authorizer
+}
+
+func (s *jwksSuite) callContext(token string) context.Context {
+	ctx, call := encoding.NewInboundCall(context.Background())
+	err := call.ReadFromRequest(&transport.Request{
+		Headers: transport.NewHeaders().With(common.AuthorizationTokenHeaderName, token),
+	})
+	s.NoError(err)
+	return ctx
+}
+
+func (s *jwksSuite) adminClaims() map[string]interface{} {
+	now := time.Now().Unix()
+	return map[string]interface{}{
+		"sub":   "1234567890",
+		"admin": true,
+		"iat":   now,
+		"exp":   now + 600,
+		"iss":   "https://idp.example.com",
+		"aud":   "cadence",
+	}
+}
+
+func (s *jwksSuite) ecJWK(kid string) map[string]string {
+	return map[string]string{
+		"kty": "EC",
+		"kid": kid,
+		"use": "sig",
+		"alg": "ES256",
+		"crv": "P-256",
+		"x":   base64.RawURLEncoding.EncodeToString(s.ecKey.X.Bytes()),
+		"y":   base64.RawURLEncoding.EncodeToString(s.ecKey.Y.Bytes()),
+	}
+}
+
+func (s *jwksSuite) edJWK(kid string) map[string]string {
+	return map[string]string{
+		"kty": "OKP",
+		"kid": kid,
+		"crv": "Ed25519",
+		"x":   base64.RawURLEncoding.EncodeToString(s.edKey.Public().(ed25519.PublicKey)),
+	}
+}
+
+func (s *jwksSuite) writeJWKS(path string, keys ...map[string]string) {
+	document, err := json.Marshal(map[string]interface{}{"keys": keys})
+	s.NoError(err)
+	s.NoError(ioutil.WriteFile(path, document, 0600))
+}
+
+func (s *jwksSuite) signES256(kid string, claims map[string]interface{}) string {
+	return s.sign(kid, "ES256", claims, func(payload []byte) []byte {
+		digest := sha256.Sum256(payload)
+		r, sig, err := ecdsa.Sign(rand.Reader, s.ecKey, digest[:])
+		s.NoError(err)
+		signature := make([]byte, 64)
+		r.FillBytes(signature[:32])
+		sig.FillBytes(signature[32:])
+		return signature
+	})
+}
+
+func (s *jwksSuite) signEdDSA(kid string, claims map[string]interface{}) string {
+	return s.sign(kid, "EdDSA", claims, func(payload []byte) []byte {
+		return ed25519.Sign(s.edKey, payload)
+	})
+}
+
+func (s *jwksSuite) sign(kid, algorithm string, claims map[string]interface{}, sign func([]byte) []byte) string {
+	header, err := json.Marshal(map[string]string{"alg": algorithm, "typ": "JWT", "kid": kid})
+	s.NoError(err)
+	payload, err := json.Marshal(claims)
+	s.NoError(err)
+	signed := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	return fmt.Sprintf("%v.%v", signed, base64.RawURLEncoding.EncodeToString(sign([]byte(signed))))
+}
@@ -0,0 +1,176 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package authorization
+
+import (
+	"context"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+	"gopkg.in/yaml.v2"
+
+	"github.com/uber/cadence/common/cache"
+	"github.com/uber/cadence/common/config"
+	"github.com/uber/cadence/common/log"
+	"github.com/uber/cadence/common/log/tag"
+)
+
+type (
+	mtlsAuthority struct {
+		domainCache cache.DomainCache
+		log         log.Logger
+		mapping     *mtlsGroupMapping
+		permissions adminPermissions
+	}
+
+	// mtlsGroupMapping is the content of the group mapping file of the mTLS authorizer.
+	// Identities of a client certificate are its URI, DNS and email SANs and its subject common name, e.g.
+	//   identities:
+	//     spiffe://example.com/payments-worker: [payments]
+	//     ops-tool.example.com: [payments-readers, billing-readers]
+	//   admins:
+	//     - spiffe://example.com/cadence-admin
+	mtlsGroupMapping struct {
+		// Identities maps the identities to the groups checked against the domain data
+		Identities map[string][]string `yaml:"identities"`
+		// Admins are the identities allowed to call every API, like the admin claim of the JWT
+		Admins []string `yaml:"admins"`
+	}
+)
+
+var _ Authorizer = (*mtlsAuthority)(nil)
+
+// NewMTLSAuthorizer creates an authorizer using the verified client certificate of the inbound call.
+// Only the gRPC inbound terminates TLS, requests received through TChannel are denied.
+func NewMTLSAuthorizer(
+	authorizationCfg config.MTLSAuthorizer,
+	adminPermissionsCfg map[string][]string,
+	log log.Logger,
+	domainCache cache.DomainCache,
+) (Authorizer, error) {
+	mapping, err := loadMTLSGroupMapping(authorizationCfg.GroupMappingFile)
+	if err != nil {
+		return nil, err
+	}
+	permissions, err := newAdminPermissions(adminPermissionsCfg)
+	if err != nil {
+		return nil, err
+	}
+	return &mtlsAuthority{
+		domainCache: domainCache,
+		log:         log,
+		mapping:     mapping,
+		permissions: permissions,
+	}, nil
+}
+
+// Authorize checks the groups mapped from the identities of the client certificate against the domain data
+func (a *mtlsAuthority) Authorize(
+	ctx context.Context,
+	attributes *Attributes,
+) (Result, error) {
+	cert := peerCertificate(ctx)
+	if cert == nil {
+		a.log.Debug("request is not authorized", tag.Error(fmt.Errorf("no verified client certificate")))
+		return Result{Decision: DecisionDeny}, nil
+	}
+	identities := certificateIdentities(cert)
+	actor := ""
+	if len(identities) > 0 {
+		actor = identities[0]
+	}
+	if a.mapping.isAdmin(identities) {
+		return Result{Decision: DecisionAllow, Actor: actor}, nil
+	}
+	groups := a.mapping.groups(identities)
+	if attributes.Permission == PermissionAdmin {
+		if !a.permissions.allows(groups, attributes) {
+			a.log.Debug("request is not authorized", tag.Error(fmt.Errorf(
+				"certificate doesn't have the admin permission for %v API, identities: %v, groups: %v", attributes.APIName, identities, groups)))
+			return Result{Decision: DecisionDeny, Actor: actor}, nil
+		}
+		return Result{Decision: DecisionAllow, Actor: actor}, nil
+	}
+	domain, err := a.domainCache.GetDomain(attributes.DomainName)
+	if err != nil {
+		return Result{Decision: DecisionDeny, Actor: actor}, err
+	}
+
+	if !hasGroupPermission(groups, attributes, domain.GetInfo().Data, a.log) {
+		a.log.Debug("request is not authorized", tag.Error(fmt.Errorf(
+			"certificate doesn't have the right permission, identities: %v, groups: %v", identities, groups)))
+		return Result{Decision: DecisionDeny, Actor: actor}, nil
+	}
+	return Result{Decision: DecisionAllow, Actor: actor}, nil
+}
+
+// peerCertificate returns the client certificate verified by the TLS handshake of the inbound call
+func peerCertificate(ctx context.Context) *x509.Certificate {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return nil
+	}
+	tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok || len(tlsInfo.State.VerifiedChains) == 0 || len(tlsInfo.State.VerifiedChains[0]) == 0 {
+		return nil
+	}
+	return tlsInfo.State.VerifiedChains[0][0]
+}
+
+func certificateIdentities(cert *x509.Certificate) []string {
+	var identities []string
+	for _, uri := range cert.URIs {
+		identities = append(identities, uri.String())
+	}
+	identities = append(identities, cert.DNSNames...)
+	identities = append(identities, cert.EmailAddresses...)
+	if cert.Subject.CommonName != "" {
+		identities = append(identities, cert.Subject.CommonName)
+	}
+	return identities
+}
+
+func loadMTLSGroupMapping(path string) (*mtlsGroupMapping, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read group mapping file: %v", err)
+	}
+	mapping := &mtlsGroupMapping{}
+	if err := yaml.Unmarshal(data, mapping); err != nil {
+		return nil, fmt.Errorf("invalid group mapping file: %v", err)
+	}
+	return mapping, nil
+}
+
+func (m *mtlsGroupMapping) isAdmin(identities []string) bool {
+	return containsAny(m.Admins, identities)
+}
+
+func (m *mtlsGroupMapping) groups(identities []string) []string {
+	var groups []string
+	for _, identity := range identities {
+		groups = append(groups, m.Identities[identity]...)
+	}
+	return groups
+}
@@ -0,0 +1,213 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package authorization
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/suite"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+
+	"github.com/uber/cadence/common"
+	"github.com/uber/cadence/common/cache"
+	"github.com/uber/cadence/common/config"
+	"github.com/uber/cadence/common/log"
+	"github.com/uber/cadence/common/log/loggerimpl"
+	"github.com/uber/cadence/common/persistence"
+)
+
+type (
+	mtlsSuite struct {
+		suite.Suite
+		logger      log.Logger
+		controller  *gomock.Controller
+		domainCache *cache.MockDomainCache
+		tempDir     string
+		cfg         config.MTLSAuthorizer
+		att         Attributes
+		domainEntry *cache.DomainCacheEntry
+	}
+)
+
+const testGroupMapping = `
+identities:
+  spiffe://example.com/payments-worker: [payments]
+  ops-tool.example.com: [readers]
+admins:
+  - cadence-admin
+`
+
+func TestMTLSSuite(t *testing.T) {
+	suite.Run(t, new(mtlsSuite))
+}
+
+func (s *mtlsSuite) SetupTest() {
+	s.logger = loggerimpl.NewLoggerForTest(s.Suite)
+	s.controller = gomock.NewController(s.T())
+	s.domainCache = cache.NewMockDomainCache(s.controller)
+
+	var err error
+	s.tempDir, err = ioutil.TempDir("", "mtlsSuite")
+	s.NoError(err)
+	s.cfg = config.MTLSAuthorizer{
+		Enable:           true,
+		GroupMappingFile: filepath.Join(s.tempDir, "groups.yaml"),
+	}
+	s.NoError(ioutil.WriteFile(s.cfg.GroupMappingFile, []byte(testGroupMapping), 0644))
+
+	s.att = Attributes{
+		APIName:    "SignalWorkflowExecution",
+		DomainName: "test-domain",
+		Permission: PermissionWrite,
+	}
+	s.domainEntry = cache.NewLocalDomainCacheEntryForTest(
+		&persistence.DomainInfo{
+			Name: "test-domain",
+			Data: map[string]string{
+				common.DomainDataKeyForReadGroups:  "readers",
+				common.DomainDataKeyForWriteGroups: "payments",
+			},
+		},
+		&persistence.DomainConfig{},
+		"",
+		nil,
+	)
+}
+
+func (s *mtlsSuite) TearDownTest() {
+	s.controller.Finish()
+	os.RemoveAll(s.tempDir)
+}
+
+func (s *mtlsSuite) contextWithCertificate(cert *x509.Certificate) context.Context {
+	return peer.NewContext(context.Background(), &peer.Peer{
+		AuthInfo: credentials.TLSInfo{
+			State: tls.ConnectionState{
+				PeerCertificates: []*x509.Certificate{cert},
+				VerifiedChains:   [][]*x509.Certificate{{cert}},
+			},
+		},
+	})
+}
+
+func (s *mtlsSuite) newAuthorizer() Authorizer {
+	authorizer, err := NewMTLSAuthorizer(s.cfg, nil, s.logger, s.domainCache)
+	s.NoError(err)
+	return authorizer
+}
+
+func (s *mtlsSuite) TestURIIdentity() {
+	uri, err := url.Parse("spiffe://example.com/payments-worker")
+	s.NoError(err)
+	ctx := s.contextWithCertificate(&x509.Certificate{URIs: []*url.URL{uri}})
+	s.domainCache.EXPECT().GetDomain(s.att.DomainName).Return(s.domainEntry, nil).Times(1)
+
+	result, err := s.newAuthorizer().Authorize(ctx, &s.att)
+	s.NoError(err)
+	s.Equal(DecisionAllow, result.Decision)
+	s.Equal("spiffe://example.com/payments-worker", result.Actor)
+}
+
+func (s *mtlsSuite) TestReadOnlyIdentity() {
+	ctx := s.contextWithCertificate(&x509.Certificate{DNSNames: []string{"ops-tool.example.com"}})
+	s.domainCache.EXPECT().GetDomain(s.att.DomainName).Return(s.domainEntry, nil).Times(2)
+	authorizer := s.newAuthorizer()
+
+	result, err := authorizer.Authorize(ctx, &s.att)
+	s.NoError(err)
+	s.Equal(DecisionDeny, result.Decision)
+
+	s.att.Permission = PermissionRead
+	result, err = authorizer.Authorize(ctx, &s.att)
+	s.NoError(err)
+	s.Equal(DecisionAllow, result.Decision)
+}
+
+func (s *mtlsSuite) TestAdminIdentity() {
+	ctx := s.contextWithCertificate(&x509.Certificate{Subject: pkix.Name{CommonName: "cadence-admin"}})
+	s.att.Permission = PermissionAdmin
+
+	result, err := s.newAuthorizer().Authorize(ctx, &s.att)
+	s.NoError(err)
+	s.Equal(DecisionAllow, result.Decision)
+}
+
+func (s *mtlsSuite) TestAdminPermission() {
+	ctx := s.contextWithCertificate(&x509.Certificate{DNSNames: []string{"ops-tool.example.com"}})
+	authorizer, err := NewMTLSAuthorizer(s.cfg, map[string][]string{"dynamicconfig": {"readers"}}, s.logger, s.domainCache)
+	s.NoError(err)
+
+	result, err := authorizer.Authorize(ctx, &Attributes{APIName: "ListDynamicConfig", Permission: PermissionAdmin})
+	s.NoError(err)
+	s.Equal(DecisionAllow, result.Decision)
+
+	result, err = authorizer.Authorize(ctx, &Attributes{APIName: "PurgeDLQMessages", Permission: PermissionAdmin})
+	s.NoError(err)
+	s.Equal(DecisionDeny, result.Decision)
+}
+
+func (s *mtlsSuite) TestUnknownIdentity() {
+	ctx := s.contextWithCertificate(&x509.Certificate{Subject: pkix.Name{CommonName: "unknown"}})
+	s.domainCache.EXPECT().GetDomain(s.att.DomainName).Return(s.domainEntry, nil).Times(1)
+
+	result, err := s.newAuthorizer().Authorize(ctx, &s.att)
+	s.NoError(err)
+	s.Equal(DecisionDeny, result.Decision)
+}
+
+func (s *mtlsSuite) TestNoCertificate() {
+	authorizer := s.newAuthorizer()
+
+	result, err := authorizer.Authorize(context.Background(), &s.att)
+	s.NoError(err)
+	s.Equal(DecisionDeny, result.Decision)
+
+	// certificate presented but not verified
+	ctx := peer.NewContext(context.Background(), &peer.Peer{
+		AuthInfo: credentials.TLSInfo{
+			State: tls.ConnectionState{
+				PeerCertificates: []*x509.Certificate{{Subject: pkix.Name{CommonName: "cadence-admin"}}},
+			},
+		},
+	})
+	result, err = authorizer.Authorize(ctx, &s.att)
+	s.NoError(err)
+	s.Equal(DecisionDeny, result.Decision)
+}
+
+func (s *mtlsSuite) TestInvalidGroupMapping() {
+	_, err := NewMTLSAuthorizer(config.MTLSAuthorizer{Enable: true, GroupMappingFile: filepath.Join(s.tempDir, "missing.yaml")}, nil, s.logger, s.domainCache)
+	s.Error(err)
+
+	s.NoError(ioutil.WriteFile(s.cfg.GroupMappingFile, []byte("identities: [invalid"), 0644))
+	_, err = NewMTLSAuthorizer(s.cfg, nil, s.logger, s.domainCache)
+	s.Error(err)
+}
@@ -22,7 +22,12 @@ package authorization
 
 import (
 	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
 	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"strings"
@@ -42,7 +47,11 @@ type oauthAuthority struct {
 	authorizationCfg config.OAuthAuthorizer
 	domainCache      cache.DomainCache
 	log              log.Logger
-	publicKey        *rsa.PublicKey
+	publicKey        crypto.PublicKey
+	keySet           *jwksKeySet
+	adminPermissions adminPermissions
+	// tokenCache maps the hash of the verified tokens to their claims, nil if disabled
+	tokenCache cache.Cache
 }
 
 type JWTClaims struct {
@@ -52,17 +61,53 @@ type JWTClaims struct {
 	Admin  bool
 	Iat    int64
 	TTL    int64
+	// registered claims, Iat and TTL are only used by tokens without Exp
+	Exp int64       `json:"exp,omitempty"`
+	Nbf int64       `json:"nbf,omitempty"`
+	Iss string      `json:"iss,omitempty"`
+	Aud JWTAudience `json:"aud,omitempty"`
 }
 
-const groupSeparator = " "
+// JWTAudience is the aud claim, which is either a single string or an array of strings
+type JWTAudience []string
 
-// NewOAuthAuthorizer creates a oauth authority
+type jwtHeader struct {
+	Algorithm jwt.Algorithm `json:"alg"`
+	KeyID     string        `json:"kid"`
+}
+
+const (
+	groupSeparator = " "
+	// jwtLeeway is the clock skew in seconds tolerated when validating exp and nbf
+	jwtLeeway = int64(60)
+
+	defaultTokenCacheSize = 10000
+	defaultTokenCacheTTL  = 5 * time.Minute
+)
+
+// NewOAuthAuthorizer creates a oauth authority, adminPermissionsCfg grants groups of the token
+// fine-grained permissions on the admin APIs
 func NewOAuthAuthorizer(
 	authorizationCfg config.OAuthAuthorizer,
+	adminPermissionsCfg map[string][]string,
 	log log.Logger,
 	domainCache cache.DomainCache,
 ) (Authorizer, error) {
-	publicKey, err := common.LoadRSAPublicKey(authorizationCfg.JwtCredentials.PublicKey)
+	permissions, err := newAdminPermissions(adminPermissionsCfg)
+	if err != nil {
+		return nil, err
+	}
+	if authorizationCfg.Provider != nil {
+		return &oauthAuthority{
+			authorizationCfg: authorizationCfg,
+			domainCache:      domainCache,
+			log:              log,
+			keySet:           newJWKSKeySet(*authorizationCfg.Provider, log),
+			adminPermissions: permissions,
+			tokenCache:       newTokenCache(authorizationCfg),
+		}, nil
+	}
+	publicKey, err := common.LoadPublicKey(authorizationCfg.JwtCredentials.PublicKey)
 	if err != nil {
 		return nil, err
 	}
@@ -71,60 +116,170 @@ func NewOAuthAuthorizer(
 		domainCache:      domainCache,
 		log:              log,
 		publicKey:        publicKey,
+		adminPermissions: permissions,
+		tokenCache:       newTokenCache(authorizationCfg),
 	}, nil
 }
 
+func newTokenCache(authorizationCfg config.OAuthAuthorizer) cache.Cache {
+	size := authorizationCfg.TokenCacheSize
+	if size == 0 {
+		size = defaultTokenCacheSize
+	}
+	if size < 0 {
+		return nil
+	}
+	ttl := authorizationCfg.TokenCacheTTL
+	if ttl <= 0 {
+		ttl = defaultTokenCacheTTL
+	}
+	return cache.New(&cache.Options{
+		MaxCount: size,
+		TTL:      ttl,
+	})
+}
+
 // Authorize defines the logic to verify get claims from token
 func (a *oauthAuthority) Authorize(
 	ctx context.Context,
 	attributes *Attributes,
 ) (Result, error) {
 	call := yarpc.CallFromContext(ctx)
-	verifier, err := a.getVerifier()
-	if err != nil {
-		return Result{Decision: DecisionDeny}, err
-	}
 	token := call.Header(common.AuthorizationTokenHeaderName)
 	if token == "" {
 		a.log.Debug("request is not authorized", tag.Error(fmt.Errorf("token is not set in header")))
 		return Result{Decision: DecisionDeny}, nil
 	}
-	claims, err := a.parseToken(token, verifier)
-	if err != nil {
-		a.log.Debug("request is not authorized", tag.Error(err))
-		return Result{Decision: DecisionDeny}, nil
+	tokenHash := sha256.Sum256([]byte(token))
+	claims := a.getCachedClaims(tokenHash)
+	isCached := claims != nil
+	if !isCached {
+		verifier, err := a.getVerifier(token)
+		if err != nil {
+			if _, ok := err.(*jwtKeyError); ok {
+				a.log.Debug("request is not authorized", tag.Error(err))
+				return Result{Decision: DecisionDeny}, nil
+			}
+			return Result{Decision: DecisionDeny}, err
+		}
+		claims, err = a.parseToken(token, verifier)
+		if err != nil {
+			a.log.Debug("request is not authorized", tag.Error(err))
+			return Result{Decision: DecisionDeny}, nil
+		}
 	}
-	err = a.validateTTL(claims)
+	// the claims of cached tokens are validated again, to reject them once expired
+	err := a.validateClaims(claims)
 	if err != nil {
 		a.log.Debug("request is not authorized", tag.Error(err))
 		return Result{Decision: DecisionDeny}, nil
 	}
+	if !isCached && a.tokenCache != nil {
+		a.tokenCache.Put(tokenHash, claims)
+	}
+	actor := claims.actor()
 	if claims.Admin {
-		return Result{Decision: DecisionAllow}, nil
+		return Result{Decision: DecisionAllow, Actor: actor}, nil
+	}
+	if attributes.Permission == PermissionAdmin {
+		jwtGroups := splitGroups(claims.Groups)
+		if !a.adminPermissions.allows(jwtGroups, attributes) {
+			a.log.Debug("request is not authorized", tag.Error(fmt.Errorf(
+				"token doesn't have the admin permission for %v API, jwt groups: %v", attributes.APIName, jwtGroups)))
+			return Result{Decision: DecisionDeny, Actor: actor}, nil
+		}
+		return Result{Decision: DecisionAllow, Actor: actor}, nil
 	}
 	domain, err := a.domainCache.GetDomain(attributes.DomainName)
 	if err != nil {
-		return Result{Decision: DecisionDeny}, err
+		return Result{Decision: DecisionDeny, Actor: actor}, err
 	}
 
 	err = a.validatePermission(claims, attributes, domain.GetInfo().Data)
 	if err != nil {
 		a.log.Debug("request is not authorized", tag.Error(err))
-		return Result{Decision: DecisionDeny}, nil
+		return Result{Decision: DecisionDeny, Actor: actor}, nil
+	}
+	return Result{Decision: DecisionAllow, Actor: actor}, nil
+}
+
+// getCachedClaims returns the claims of the token if its signature has already been verified, nil otherwise
+func (a *oauthAuthority) getCachedClaims(tokenHash [sha256.Size]byte) *JWTClaims {
+	if a.tokenCache == nil {
+		return nil
+	}
+	claims, _ := a.tokenCache.Get(tokenHash).(*JWTClaims)
+	return claims
+}
+
+// actor returns the identity of the caller, the subject of the token if set and its name otherwise
+func (c *JWTClaims) actor() string {
+	if c.Sub != "" {
+		return c.Sub
 	}
-	return Result{Decision: DecisionAllow}, nil
+	return c.Name
 }
 
-func (a *oauthAuthority) getVerifier() (jwt.Verifier, error) {
+// getVerifier returns the verifier of the token, errors caused by the token itself are *jwtKeyError
+func (a *oauthAuthority) getVerifier(token string) (jwt.Verifier, error) {
+	if a.keySet == nil {
+		algorithm := jwt.Algorithm(a.authorizationCfg.JwtCredentials.Algorithm)
+		return newJWTVerifier(algorithm, a.publicKey)
+	}
 
-	algorithm := jwt.Algorithm(a.authorizationCfg.JwtCredentials.Algorithm)
-	verifier, err := jwt.NewVerifierRS(algorithm, a.publicKey)
+	header, err := parseJWTHeader(token)
+	if err != nil {
+		return nil, &jwtKeyError{err}
+	}
+	key, err := a.keySet.getKey(header.KeyID)
 	if err != nil {
 		return nil, err
 	}
+	if key.algorithm != "" && key.algorithm != header.Algorithm {
+		return nil, &jwtKeyError{fmt.Errorf("algorithm %v doesn't match algorithm %v of key %v", header.Algorithm, key.algorithm, header.KeyID)}
+	}
+	verifier, err := newJWTVerifier(header.Algorithm, key.key)
+	if err != nil {
+		return nil, &jwtKeyError{err}
+	}
 	return verifier, nil
 }
 
+func newJWTVerifier(algorithm jwt.Algorithm, publicKey crypto.PublicKey) (jwt.Verifier, error) {
+	switch key := publicKey.(type) {
+	case *rsa.PublicKey:
+		if strings.HasPrefix(string(algorithm), "PS") {
+			return jwt.NewVerifierPS(algorithm, key)
+		}
+		return jwt.NewVerifierRS(algorithm, key)
+	case *ecdsa.PublicKey:
+		return jwt.NewVerifierES(algorithm, key)
+	case ed25519.PublicKey:
+		if algorithm != jwt.EdDSA {
+			return nil, jwt.ErrUnsupportedAlg
+		}
+		return jwt.NewVerifierEdDSA(key)
+	default:
+		return nil, fmt.Errorf("public key of type %T is not supported", publicKey)
+	}
+}
+
+func parseJWTHeader(tokenStr string) (*jwtHeader, error) {
+	parts := strings.Split(tokenStr, ".")
+	if len(parts) != 3 {
+		return nil, jwt.ErrInvalidFormat
+	}
+	rawHeader, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return nil, jwt.ErrInvalidFormat
+	}
+	var header jwtHeader
+	if err := json.Unmarshal(rawHeader, &header); err != nil {
+		return nil, jwt.ErrInvalidFormat
+	}
+	return &header, nil
+}
+
 func (a *oauthAuthority) parseToken(tokenStr string, verifier jwt.Verifier) (*JWTClaims, error) {
 	token, verifyErr := jwt.ParseAndVerifyString(tokenStr, verifier)
 	if verifyErr != nil {
@@ -135,6 +290,56 @@ func (a *oauthAuthority) parseToken(tokenStr string, verifier jwt.Verifier) (*JW
 	return &claims, nil
 }
 
+func (a *oauthAuthority) validateClaims(claims *JWTClaims) error {
+	if claims.Exp == 0 {
+		if a.keySet != nil {
+			return fmt.Errorf("JWT doesn't have an expiration time")
+		}
+		return a.validateTTL(claims)
+	}
+
+	now := time.Now().Unix()
+	if now >= claims.Exp+jwtLeeway {
+		return fmt.Errorf("JWT has expired")
+	}
+	if claims.Nbf != 0 && now+jwtLeeway < claims.Nbf {
+		return fmt.Errorf("JWT is not valid yet")
+	}
+	if err := a.validateMaxTTL(claims, now); err != nil {
+		return err
+	}
+	if provider := a.authorizationCfg.Provider; provider != nil {
+		if provider.Issuer != "" && claims.Iss != provider.Issuer {
+			return fmt.Errorf("JWT issuer %v is not allowed", claims.Iss)
+		}
+		if provider.Audience != "" && !claims.Aud.contains(provider.Audience) {
+			return fmt.Errorf("JWT audience %v doesn't include %v", []string(claims.Aud), provider.Audience)
+		}
+	}
+	return nil
+}
+
+// validateMaxTTL checks the lifetime of a JWT with an expiration time against MaxJwtTTL, which is mandatory
+// when the JWT is verified with the static key. Without issued at time, the JWT of the static key is rejected
+// and the remaining lifetime of the JWT of a provider is checked instead.
+func (a *oauthAuthority) validateMaxTTL(claims *JWTClaims, now int64) error {
+	maxTTL := a.authorizationCfg.MaxJwtTTL
+	if maxTTL <= 0 && a.keySet != nil {
+		return nil
+	}
+	switch {
+	case claims.Iat != 0:
+		if claims.Exp-claims.Iat > maxTTL {
+			return fmt.Errorf("TTL in token is larger than MaxTTL allowed")
+		}
+	case a.keySet == nil:
+		return fmt.Errorf("JWT doesn't have an issued at time")
+	case claims.Exp-now > maxTTL:
+		return fmt.Errorf("TTL in token is larger than MaxTTL allowed")
+	}
+	return nil
+}
+
 func (a *oauthAuthority) validateTTL(claims *JWTClaims) error {
 	if claims.TTL > a.authorizationCfg.MaxJwtTTL {
 		return fmt.Errorf("TTL in token is larger than MaxTTL allowed")
@@ -146,25 +351,79 @@ func (a *oauthAuthority) validateTTL(claims *JWTClaims) error {
 }
 
 func (a *oauthAuthority) validatePermission(claims *JWTClaims, attributes *Attributes, data map[string]string) error {
+	if attributes.Permission != PermissionRead && attributes.Permission != PermissionWrite && attributes.Permission != PermissionSensitiveRead {
+		return fmt.Errorf("token doesn't have permission for %v API", attributes.Permission)
+	}
+	jwtGroups := splitGroups(claims.Groups) // groups that the request has associated with
+	if hasGroupPermission(jwtGroups, attributes, data, a.log) {
+		return nil
+	}
+	return fmt.Errorf("token doesn't have the right permission, jwt groups: %v, allowed groups: %v", jwtGroups, domainGroups(attributes.Permission, data))
+}
+
+// domainGroups returns the groups allowed by domain configuration(in domainData) for the permission
+func domainGroups(permission Permission, data map[string]string) []string {
 	groups := ""
-	switch attributes.Permission {
+	switch permission {
 	case PermissionRead:
 		groups = data[common.DomainDataKeyForReadGroups] + groupSeparator + data[common.DomainDataKeyForWriteGroups]
 	case PermissionWrite:
 		groups = data[common.DomainDataKeyForWriteGroups]
-	default:
-		return fmt.Errorf("token doesn't have permission for %v API", attributes.Permission)
+	case PermissionSensitiveRead:
+		groups = data[common.DomainDataKeyForSensitiveReadGroups]
 	}
-	// groups are separated by space
-	allowedGroups := strings.Split(groups, groupSeparator)    // groups that allowed by domain configuration(in domainData)
-	jwtGroups := strings.Split(claims.Groups, groupSeparator) // groups that the request has associated with
+	return splitGroups(groups)
+}
+
+// splitGroups returns the groups separated by space, without the empty groups, which are not to match
+// the groups of a token without groups or the groups of a domain which doesn't set them
+func splitGroups(groups string) []string {
+	return strings.Fields(groups)
+}
 
-	for _, group1 := range allowedGroups {
-		for _, group2 := range jwtGroups {
+// hasGroupPermission returns whether one of the groups is granted the read, write or sensitive read permission by
+// the domain data, either through the domain groups or through the authorization rules. Rules don't grant the
+// sensitive read permission.
+func hasGroupPermission(groups []string, attributes *Attributes, data map[string]string, logger log.Logger) bool {
+	if attributes.Permission != PermissionRead && attributes.Permission != PermissionWrite && attributes.Permission != PermissionSensitiveRead {
+		return false
+	}
+	for _, group1 := range domainGroups(attributes.Permission, data) {
+		for _, group2 := range groups {
 			if group1 == group2 {
-				return nil
+				return true
 			}
 		}
 	}
-	return fmt.Errorf("token doesn't have the right permission, jwt groups: %v, allowed groups: %v", jwtGroups, allowedGroups)
+
+	rules, err := ParseRules(data)
+	if err != nil {
+		logger.Warn("ignoring invalid authorization rules of domain", tag.WorkflowDomainName(attributes.DomainName), tag.Error(err))
+		return false
+	}
+	return isAllowedByRules(rules, groups, attributes)
+}
+
+// UnmarshalJSON accepts both a single audience and an array of audiences
+func (aud *JWTAudience) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*aud = JWTAudience{single}
+		return nil
+	}
+	var multiple []string
+	if err := json.Unmarshal(data, &multiple); err != nil {
+		return err
+	}
+	*aud = multiple
+	return nil
+}
+
+func (aud JWTAudience) contains(audience string) bool {
+	for _, a := range aud {
+		if a == audience {
+			return true
+		}
+	}
+	return false
 }
@@ -39,6 +39,7 @@ import (
 	"github.com/uber/cadence/common/log"
 	"github.com/uber/cadence/common/log/tag"
 	"github.com/uber/cadence/common/persistence"
+	"github.com/uber/cadence/common/types"
 )
 
 type (
@@ -117,11 +118,34 @@ func (s *oauthSuite) TearDownTest() {
 
 func (s *oauthSuite) TestCorrectPayload() {
 	s.domainCache.EXPECT().GetDomain(s.att.DomainName).Return(s.domainEntry, nil).Times(1)
-	authorizer, err := NewOAuthAuthorizer(s.cfg, s.logger, s.domainCache)
+	authorizer, err := NewOAuthAuthorizer(s.cfg, nil, s.logger, s.domainCache)
 	s.NoError(err)
 	result, err := authorizer.Authorize(s.ctx, &s.att)
 	s.NoError(err)
 	s.Equal(result.Decision, DecisionAllow)
+	s.Equal("1234567890", result.Actor)
+}
+
+func (s *oauthSuite) TestTokenCache() {
+	s.domainCache.EXPECT().GetDomain(s.att.DomainName).Return(s.domainEntry, nil).Times(2)
+	authorizer, err := NewOAuthAuthorizer(s.cfg, nil, s.logger, s.domainCache)
+	s.NoError(err)
+	result, err := authorizer.Authorize(s.ctx, &s.att)
+	s.NoError(err)
+	s.Equal(DecisionAllow, result.Decision)
+
+	// the signature of the cached token is not verified again
+	authorizer.(*oauthAuthority).publicKey = nil
+	result, err = authorizer.Authorize(s.ctx, &s.att)
+	s.NoError(err)
+	s.Equal(DecisionAllow, result.Decision)
+}
+
+func (s *oauthSuite) TestTokenCacheDisabled() {
+	s.cfg.TokenCacheSize = -1
+	authorizer, err := NewOAuthAuthorizer(s.cfg, nil, s.logger, s.domainCache)
+	s.NoError(err)
+	s.Nil(authorizer.(*oauthAuthority).tokenCache)
 }
 
 func (s *oauthSuite) TestItIsAdmin() {
@@ -133,7 +157,7 @@ func (s *oauthSuite) TestItIsAdmin() {
 		Headers: transport.NewHeaders().With(common.AuthorizationTokenHeaderName, token),
 	})
 	s.NoError(err)
-	authorizer, err := NewOAuthAuthorizer(s.cfg, s.logger, s.domainCache)
+	authorizer, err := NewOAuthAuthorizer(s.cfg, nil, s.logger, s.domainCache)
 	s.NoError(err)
 	result, err := authorizer.Authorize(ctx, &s.att)
 	s.NoError(err)
@@ -147,7 +171,7 @@ func (s *oauthSuite) TestEmptyToken() {
 		Headers: transport.NewHeaders().With(common.AuthorizationTokenHeaderName, ""),
 	})
 	s.NoError(err)
-	authorizer, err := NewOAuthAuthorizer(s.cfg, s.logger, s.domainCache)
+	authorizer, err := NewOAuthAuthorizer(s.cfg, nil, s.logger, s.domainCache)
 	s.NoError(err)
 	s.logger.On("Debug", "request is not authorized", mock.MatchedBy(func(t []tag.Tag) bool {
 		return fmt.Sprintf("%v", t[0].Field().Interface) == "token is not set in header"
@@ -158,7 +182,7 @@ func (s *oauthSuite) TestEmptyToken() {
 
 func (s *oauthSuite) TestGetDomainError() {
 	s.domainCache.EXPECT().GetDomain(s.att.DomainName).Return(nil, fmt.Errorf("error")).Times(1)
-	authorizer, err := NewOAuthAuthorizer(s.cfg, s.logger, s.domainCache)
+	authorizer, err := NewOAuthAuthorizer(s.cfg, nil, s.logger, s.domainCache)
 	s.NoError(err)
 	result, err := authorizer.Authorize(s.ctx, &s.att)
 	s.Equal(result.Decision, DecisionDeny)
@@ -167,14 +191,14 @@ func (s *oauthSuite) TestGetDomainError() {
 
 func (s *oauthSuite) TestIncorrectPublicKey() {
 	s.cfg.JwtCredentials.PublicKey = "incorrectPublicKey"
-	authorizer, err := NewOAuthAuthorizer(s.cfg, s.logger, s.domainCache)
+	authorizer, err := NewOAuthAuthorizer(s.cfg, nil, s.logger, s.domainCache)
 	s.Equal(authorizer, nil)
 	s.EqualError(err, "invalid public key path incorrectPublicKey")
 }
 
 func (s *oauthSuite) TestIncorrectAlgorithm() {
 	s.cfg.JwtCredentials.Algorithm = "SHA256"
-	authorizer, err := NewOAuthAuthorizer(s.cfg, s.logger, s.domainCache)
+	authorizer, err := NewOAuthAuthorizer(s.cfg, nil, s.logger, s.domainCache)
 	s.NoError(err)
 	result, err := authorizer.Authorize(s.ctx, &s.att)
 	s.EqualError(err, "jwt: algorithm is not supported")
@@ -183,7 +207,7 @@ func (s *oauthSuite) TestIncorrectAlgorithm() {
 
 func (s *oauthSuite) TestMaxTTLLargerInToken() {
 	s.cfg.MaxJwtTTL = 1
-	authorizer, err := NewOAuthAuthorizer(s.cfg, s.logger, s.domainCache)
+	authorizer, err := NewOAuthAuthorizer(s.cfg, nil, s.logger, s.domainCache)
 	s.NoError(err)
 	s.logger.On("Debug", "request is not authorized", mock.MatchedBy(func(t []tag.Tag) bool {
 		return fmt.Sprintf("%v", t[0].Field().Interface) == "TTL in token is larger than MaxTTL allowed"
@@ -199,7 +223,7 @@ func (s *oauthSuite) TestIncorrectToken() {
 		Headers: transport.NewHeaders().With(common.AuthorizationTokenHeaderName, "test"),
 	})
 	s.NoError(err)
-	authorizer, err := NewOAuthAuthorizer(s.cfg, s.logger, s.domainCache)
+	authorizer, err := NewOAuthAuthorizer(s.cfg, nil, s.logger, s.domainCache)
 	s.NoError(err)
 	s.logger.On("Debug", "request is not authorized", mock.MatchedBy(func(t []tag.Tag) bool {
 		return fmt.Sprintf("%v", t[0].Field().Interface) == "jwt: token format is not valid"
@@ -217,7 +241,7 @@ func (s *oauthSuite) TestIatExpiredToken() {
 		Headers: transport.NewHeaders().With(common.AuthorizationTokenHeaderName, token),
 	})
 	s.NoError(err)
-	authorizer, err := NewOAuthAuthorizer(s.cfg, s.logger, s.domainCache)
+	authorizer, err := NewOAuthAuthorizer(s.cfg, nil, s.logger, s.domainCache)
 	s.NoError(err)
 	s.logger.On("Debug", "request is not authorized", mock.MatchedBy(func(t []tag.Tag) bool {
 		return fmt.Sprintf("%v", t[0].Field().Interface) == "JWT has expired"
@@ -230,7 +254,7 @@ func (s *oauthSuite) TestDifferentGroup() {
 	s.domainEntry.GetInfo().Data[common.DomainDataKeyForReadGroups] = "AdifferentGroup"
 	s.domainCache.EXPECT().GetDomain(s.att.DomainName).Return(s.domainEntry, nil).Times(1)
 	s.att.Permission = PermissionWrite
-	authorizer, err := NewOAuthAuthorizer(s.cfg, s.logger, s.domainCache)
+	authorizer, err := NewOAuthAuthorizer(s.cfg, nil, s.logger, s.domainCache)
 	s.NoError(err)
 	s.logger.On("Debug", "request is not authorized", mock.MatchedBy(func(t []tag.Tag) bool {
 		return fmt.Sprintf("%v", t[0].Field().Interface) == "token doesn't have the right permission, jwt groups: [a b c], allowed groups: []"
@@ -242,7 +266,7 @@ func (s *oauthSuite) TestDifferentGroup() {
 func (s *oauthSuite) TestIncorrectPermission() {
 	s.domainCache.EXPECT().GetDomain(s.att.DomainName).Return(s.domainEntry, nil).Times(1)
 	s.att.Permission = Permission(15)
-	authorizer, err := NewOAuthAuthorizer(s.cfg, s.logger, s.domainCache)
+	authorizer, err := NewOAuthAuthorizer(s.cfg, nil, s.logger, s.domainCache)
 	s.NoError(err)
 	s.logger.On("Debug", "request is not authorized", mock.MatchedBy(func(t []tag.Tag) bool {
 		return fmt.Sprintf("%v", t[0].Field().Interface) == "token doesn't have permission for 15 API"
@@ -251,3 +275,82 @@ func (s *oauthSuite) TestIncorrectPermission() {
 	s.NoError(err)
 	s.Equal(result.Decision, DecisionDeny)
 }
+
+func (s *oauthSuite) TestWorkflowTypeRule() {
+	s.domainEntry.GetInfo().Data[common.DomainDataKeyForReadGroups] = "AdifferentGroup"
+	s.domainEntry.GetInfo().Data[common.DomainDataKeyForAuthorizationRules] =
+		`[{"groups": ["b"], "apis": ["SignalWorkflowExecution"], "workflowTypes": ["PaymentWorkflow"]}]`
+	s.domainCache.EXPECT().GetDomain(s.att.DomainName).Return(s.domainEntry, nil).Times(2)
+	authorizer, err := NewOAuthAuthorizer(s.cfg, nil, s.logger, s.domainCache)
+	s.NoError(err)
+
+	s.att.Permission = PermissionWrite
+	s.att.APIName = "SignalWorkflowExecution"
+	s.att.WorkflowType = &types.WorkflowType{Name: "PaymentWorkflow"}
+	result, err := authorizer.Authorize(s.ctx, &s.att)
+	s.NoError(err)
+	s.Equal(DecisionAllow, result.Decision)
+
+	s.att.WorkflowType = &types.WorkflowType{Name: "OtherWorkflow"}
+	s.logger.On("Debug", "request is not authorized", mock.Anything).Once()
+	result, err = authorizer.Authorize(s.ctx, &s.att)
+	s.NoError(err)
+	s.Equal(DecisionDeny, result.Decision)
+}
+
+func (s *oauthSuite) TestInvalidRulesIgnored() {
+	s.domainEntry.GetInfo().Data[common.DomainDataKeyForAuthorizationRules] = `{"groups": "b"}`
+	s.domainCache.EXPECT().GetDomain(s.att.DomainName).Return(s.domainEntry, nil).Times(1)
+	authorizer, err := NewOAuthAuthorizer(s.cfg, nil, s.logger, s.domainCache)
+	s.NoError(err)
+
+	s.att.Permission = PermissionWrite
+	s.logger.On("Warn", "ignoring invalid authorization rules of domain", mock.Anything).Once()
+	s.logger.On("Debug", "request is not authorized", mock.Anything).Once()
+	result, err := authorizer.Authorize(s.ctx, &s.att)
+	s.NoError(err)
+	s.Equal(DecisionDeny, result.Decision)
+}
+
+func (s *oauthSuite) TestSensitiveReadPermission() {
+	s.domainEntry.GetInfo().Data[common.DomainDataKeyForAuthorizationRules] = `[{"groups": ["a"]}]`
+	s.domainCache.EXPECT().GetDomain(s.att.DomainName).Return(s.domainEntry, nil).Times(2)
+	authorizer, err := NewOAuthAuthorizer(s.cfg, nil, s.logger, s.domainCache)
+	s.NoError(err)
+
+	s.att.Permission = PermissionSensitiveRead
+	s.logger.On("Debug", "request is not authorized", mock.MatchedBy(func(t []tag.Tag) bool {
+		return fmt.Sprintf("%v", t[0].Field().Interface) == "token doesn't have the right permission, jwt groups: [a b c], allowed groups: []"
+	})).Once()
+	result, err := authorizer.Authorize(s.ctx, &s.att)
+	s.NoError(err)
+	s.Equal(DecisionDeny, result.Decision)
+
+	s.domainEntry.GetInfo().Data[common.DomainDataKeyForSensitiveReadGroups] = "b"
+	result, err = authorizer.Authorize(s.ctx, &s.att)
+	s.NoError(err)
+	s.Equal(DecisionAllow, result.Decision)
+}
+
+func (s *oauthSuite) TestAdminPermission() {
+	authorizer, err := NewOAuthAuthorizer(s.cfg, map[string][]string{"dlq": {"c"}}, s.logger, s.domainCache)
+	s.NoError(err)
+
+	s.att = Attributes{APIName: "MergeDLQMessages", Permission: PermissionAdmin}
+	result, err := authorizer.Authorize(s.ctx, &s.att)
+	s.NoError(err)
+	s.Equal(DecisionAllow, result.Decision)
+
+	s.att = Attributes{APIName: "CloseShard", Permission: PermissionAdmin}
+	s.logger.On("Debug", "request is not authorized", mock.MatchedBy(func(t []tag.Tag) bool {
+		return fmt.Sprintf("%v", t[0].Field().Interface) == "token doesn't have the admin permission for CloseShard API, jwt groups: [a b c]"
+	})).Once()
+	result, err = authorizer.Authorize(s.ctx, &s.att)
+	s.NoError(err)
+	s.Equal(DecisionDeny, result.Decision)
+}
+
+func (s *oauthSuite) TestUnknownAdminPermission() {
+	_, err := NewOAuthAuthorizer(s.cfg, map[string][]string{"superuser": {"c"}}, s.logger, s.domainCache)
+	s.Error(err)
+}
@@ -0,0 +1,116 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package authorization
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/uber/cadence/common"
+)
+
+type (
+	// Rule grants groups access to the read and write APIs of a domain, in addition to the domain wide
+	// READ_GROUPS and WRITE_GROUPS. The access can be restricted to some APIs, workflow types and task lists,
+	// an empty list doesn't restrict. Rules never grant access to the admin APIs.
+	// e.g. letting the oncall group signal and terminate PaymentWorkflow only:
+	//   [{"groups": ["oncall"], "apis": ["SignalWorkflowExecution", "TerminateWorkflowExecution"], "workflowTypes": ["PaymentWorkflow"]}]
+	Rule struct {
+		Groups        []string `json:"groups"`
+		APIs          []string `json:"apis,omitempty"`
+		WorkflowTypes []string `json:"workflowTypes,omitempty"`
+		TaskLists     []string `json:"taskLists,omitempty"`
+	}
+)
+
+// ParseRules parses the authorization rules stored in the domain data
+func ParseRules(domainData map[string]string) ([]Rule, error) {
+	data, ok := domainData[common.DomainDataKeyForAuthorizationRules]
+	if !ok || data == "" {
+		return nil, nil
+	}
+	var rules []Rule
+	if err := json.Unmarshal([]byte(data), &rules); err != nil {
+		return nil, fmt.Errorf("invalid authorization rules: %v", err)
+	}
+	for i, rule := range rules {
+		if len(rule.Groups) == 0 {
+			return nil, fmt.Errorf("invalid authorization rules: rule %v doesn't have any group", i)
+		}
+	}
+	return rules, nil
+}
+
+// HasWorkflowTypeRules returns whether the domain has authorization rules restricted to workflow types
+func HasWorkflowTypeRules(domainData map[string]string) bool {
+	rules, err := ParseRules(domainData)
+	if err != nil {
+		return false
+	}
+	for _, rule := range rules {
+		if len(rule.WorkflowTypes) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// isAllowedByRules returns whether any of the groups is granted access to the API call by the rules
+func isAllowedByRules(rules []Rule, groups []string, attributes *Attributes) bool {
+	if attributes.Permission != PermissionRead && attributes.Permission != PermissionWrite {
+		return false
+	}
+	for _, rule := range rules {
+		if rule.allows(groups, attributes) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Rule) allows(groups []string, attributes *Attributes) bool {
+	if !containsAny(r.Groups, groups) {
+		return false
+	}
+	if len(r.APIs) > 0 && !containsAny(r.APIs, []string{attributes.APIName}) {
+		return false
+	}
+	if len(r.WorkflowTypes) > 0 &&
+		(attributes.WorkflowType == nil || !containsAny(r.WorkflowTypes, []string{attributes.WorkflowType.GetName()})) {
+		return false
+	}
+	if len(r.TaskLists) > 0 &&
+		(attributes.TaskList == nil || !containsAny(r.TaskLists, []string{attributes.TaskList.GetName()})) {
+		return false
+	}
+	return true
+}
+
+func containsAny(values []string, candidates []string) bool {
+	for _, value := range values {
+		for _, candidate := range candidates {
+			if value == candidate {
+				return true
+			}
+		}
+	}
+	return false
+}
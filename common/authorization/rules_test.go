@@ -0,0 +1,139 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package authorization
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/uber/cadence/common"
+	"github.com/uber/cadence/common/log/loggerimpl"
+	"github.com/uber/cadence/common/types"
+)
+
+func TestParseRules(t *testing.T) {
+	tests := []struct {
+		data     map[string]string
+		expected []Rule
+		err      bool
+	}{
+		{data: nil},
+		{data: map[string]string{common.DomainDataKeyForAuthorizationRules: ""}},
+		{
+			data: map[string]string{
+				common.DomainDataKeyForAuthorizationRules: `[{"groups": ["oncall"], "workflowTypes": ["PaymentWorkflow"]}]`,
+			},
+			expected: []Rule{{Groups: []string{"oncall"}, WorkflowTypes: []string{"PaymentWorkflow"}}},
+		},
+		{data: map[string]string{common.DomainDataKeyForAuthorizationRules: `[{"apis": ["SignalWorkflowExecution"]}]`}, err: true},
+		{data: map[string]string{common.DomainDataKeyForAuthorizationRules: `not json`}, err: true},
+	}
+	for _, test := range tests {
+		rules, err := ParseRules(test.data)
+		if test.err {
+			assert.Error(t, err)
+			continue
+		}
+		assert.NoError(t, err)
+		assert.Equal(t, test.expected, rules)
+	}
+}
+
+func TestIsAllowedByRules(t *testing.T) {
+	rules := []Rule{
+		{
+			Groups:        []string{"oncall"},
+			APIs:          []string{"SignalWorkflowExecution", "TerminateWorkflowExecution"},
+			WorkflowTypes: []string{"PaymentWorkflow"},
+		},
+		{
+			Groups:    []string{"billing"},
+			TaskLists: []string{"billing-tl"},
+		},
+	}
+	paymentWorkflow := &types.WorkflowType{Name: "PaymentWorkflow"}
+	tests := []struct {
+		groups     []string
+		attributes Attributes
+		allowed    bool
+	}{
+		{
+			groups:     []string{"oncall"},
+			attributes: Attributes{APIName: "SignalWorkflowExecution", WorkflowType: paymentWorkflow, Permission: PermissionWrite},
+			allowed:    true,
+		},
+		{
+			groups:     []string{"oncall"},
+			attributes: Attributes{APIName: "ResetWorkflowExecution", WorkflowType: paymentWorkflow, Permission: PermissionWrite},
+		},
+		{
+			groups:     []string{"oncall"},
+			attributes: Attributes{APIName: "SignalWorkflowExecution", WorkflowType: &types.WorkflowType{Name: "Other"}, Permission: PermissionWrite},
+		},
+		{
+			groups:     []string{"oncall"},
+			attributes: Attributes{APIName: "SignalWorkflowExecution", Permission: PermissionWrite},
+		},
+		{
+			groups:     []string{"dev"},
+			attributes: Attributes{APIName: "SignalWorkflowExecution", WorkflowType: paymentWorkflow, Permission: PermissionWrite},
+		},
+		{
+			groups:     []string{"billing"},
+			attributes: Attributes{APIName: "PollForDecisionTask", TaskList: &types.TaskList{Name: "billing-tl"}, Permission: PermissionWrite},
+			allowed:    true,
+		},
+		{
+			groups:     []string{"billing"},
+			attributes: Attributes{APIName: "UpdateDomain", TaskList: &types.TaskList{Name: "billing-tl"}, Permission: PermissionAdmin},
+		},
+	}
+	for _, test := range tests {
+		assert.Equal(t, test.allowed, isAllowedByRules(rules, test.groups, &test.attributes), "%+v", test)
+	}
+}
+
+func TestHasWorkflowTypeRules(t *testing.T) {
+	assert.False(t, HasWorkflowTypeRules(nil))
+	assert.False(t, HasWorkflowTypeRules(map[string]string{
+		common.DomainDataKeyForAuthorizationRules: `[{"groups": ["billing"], "taskLists": ["billing-tl"]}]`,
+	}))
+	assert.True(t, HasWorkflowTypeRules(map[string]string{
+		common.DomainDataKeyForAuthorizationRules: `[{"groups": ["oncall"], "workflowTypes": ["PaymentWorkflow"]}]`,
+	}))
+}
+
+func TestHasGroupPermission_EmptyGroups(t *testing.T) {
+	logger := loggerimpl.NewNopLogger()
+	data := map[string]string{
+		common.DomainDataKeyForReadGroups: "reader  other",
+	}
+	sensitiveRead := &Attributes{DomainName: "test-domain", Permission: PermissionSensitiveRead}
+	read := &Attributes{DomainName: "test-domain", Permission: PermissionRead}
+
+	// a token without groups doesn't match the groups the domain doesn't set
+	assert.False(t, hasGroupPermission(splitGroups(""), sensitiveRead, data, logger))
+	assert.False(t, hasGroupPermission(splitGroups(""), read, data, logger))
+	assert.False(t, hasGroupPermission(splitGroups(" "), read, data, logger))
+	assert.True(t, hasGroupPermission(splitGroups("reader"), read, data, logger))
+	assert.False(t, hasGroupPermission(splitGroups("reader"), sensitiveRead, data, logger))
+}
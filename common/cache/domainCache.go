@@ -27,6 +27,7 @@ import (
 	"hash/fnv"
 	"sort"
 	"strconv"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -843,6 +844,28 @@ func (entry *DomainCacheEntry) GetReplicationPolicy() ReplicationPolicy {
 	return ReplicationPolicyOneCluster
 }
 
+// IsClusterAllowed return whether the data residency of the domain allows its workflow data in the cluster
+func (entry *DomainCacheEntry) IsClusterAllowed(clusterName string) bool {
+	if entry.info == nil {
+		return true
+	}
+	return IsClusterAllowedByDomainData(entry.info.Data, clusterName)
+}
+
+// IsClusterAllowedByDomainData return whether the domain data allows the workflow data of the domain in the cluster
+func IsClusterAllowedByDomainData(data map[string]string, clusterName string) bool {
+	allowedClusters := strings.Fields(data[common.DomainDataKeyForAllowedClusters])
+	if len(allowedClusters) == 0 {
+		return true
+	}
+	for _, allowedCluster := range allowedClusters {
+		if allowedCluster == clusterName {
+			return true
+		}
+	}
+	return false
+}
+
 // GetDomainNotActiveErr return err if domain is not active, nil otherwise
 func (entry *DomainCacheEntry) GetDomainNotActiveErr() error {
 	if entry.IsDomainActive() {
@@ -794,3 +794,22 @@ func Test_DomainCacheEntry_GetDomainNotActiveErr(t *testing.T) {
 	_, ok := err.(*types.DomainNotActiveError)
 	require.True(t, ok)
 }
+
+func Test_DomainCacheEntry_IsClusterAllowed(t *testing.T) {
+	domainEntry := NewLocalDomainCacheEntryForTest(
+		&persistence.DomainInfo{Name: "test-domain"},
+		nil,
+		cluster.TestCurrentClusterName,
+		nil,
+	)
+	require.True(t, domainEntry.IsClusterAllowed(cluster.TestCurrentClusterName))
+	require.True(t, domainEntry.IsClusterAllowed(cluster.TestAlternativeClusterName))
+
+	domainEntry.info.Data = map[string]string{common.DomainDataKeyForAllowedClusters: " "}
+	require.True(t, domainEntry.IsClusterAllowed(cluster.TestAlternativeClusterName))
+
+	domainEntry.info.Data = map[string]string{common.DomainDataKeyForAllowedClusters: cluster.TestCurrentClusterName + " other"}
+	require.True(t, domainEntry.IsClusterAllowed(cluster.TestCurrentClusterName))
+	require.True(t, domainEntry.IsClusterAllowed("other"))
+	require.False(t, domainEntry.IsClusterAllowed(cluster.TestAlternativeClusterName))
+}
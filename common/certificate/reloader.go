@@ -0,0 +1,316 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package certificate
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/uber-go/tally"
+
+	"github.com/uber/cadence/common/config"
+	"github.com/uber/cadence/common/log"
+	"github.com/uber/cadence/common/log/tag"
+)
+
+const (
+	defaultReloadInterval = time.Minute
+
+	expiryGauge       = "tls_certificate_expiry_seconds"
+	reloadErrorsCount = "tls_certificate_reload_errors"
+	certificateFile   = "certificate_file"
+)
+
+type (
+	// Reloader keeps the certificate, key and CA files of a TLS config loaded, and swaps them in when they change
+	// on disk, so that rotated certificates are used by the new connections without restarting.
+	Reloader struct {
+		cfg      config.TLS
+		interval time.Duration
+		logger   log.Logger
+
+		sync.RWMutex
+		tlsConfig *tls.Config
+		modTimes  map[string]time.Time
+		expiries  map[string]time.Time
+		lastCheck time.Time
+	}
+)
+
+var errNoClientCertificate = errors.New("no client certificate configured")
+
+// NewReloader loads the files of the TLS config, it returns nil if TLS is not enabled
+func NewReloader(cfg config.TLS, logger log.Logger) (*Reloader, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+	interval := cfg.ReloadInterval
+	if interval <= 0 {
+		interval = defaultReloadInterval
+	}
+	r := &Reloader{
+		cfg:      cfg,
+		interval: interval,
+		logger:   logger,
+	}
+	// errors of reading the files take precedence over the ones of stat
+	modTimes, statErr := r.stat()
+	if err := r.load(modTimes); err != nil {
+		return nil, err
+	}
+	if statErr != nil {
+		return nil, statErr
+	}
+	return r, nil
+}
+
+// TLSConfig returns the TLS config built from the current files, like config.TLS.ToTLSConfig does.
+// Files are checked for changes at most once per reload interval.
+func (r *Reloader) TLSConfig() *tls.Config {
+	r.maybeReload()
+
+	r.RLock()
+	defer r.RUnlock()
+	return r.tlsConfig.Clone()
+}
+
+// ClientTLSConfig returns a TLS config for the clients which build their connections from a single config,
+// like the persistence drivers. The client certificate and, when the server name is known, the CA certificates
+// verifying the server are looked up at each handshake.
+func (r *Reloader) ClientTLSConfig(serverName string) *tls.Config {
+	current := r.TLSConfig()
+	if serverName == "" {
+		serverName = current.ServerName
+	}
+	clientConfig := &tls.Config{
+		ServerName:         serverName,
+		InsecureSkipVerify: current.InsecureSkipVerify,
+		RootCAs:            current.RootCAs,
+	}
+	if len(current.Certificates) > 0 {
+		clientConfig.GetClientCertificate = r.getClientCertificate
+	}
+	if !current.InsecureSkipVerify && current.RootCAs != nil && serverName != "" {
+		// the server certificate is verified against the reloaded CA certificates instead
+		clientConfig.InsecureSkipVerify = true
+		clientConfig.VerifyPeerCertificate = func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+			return r.verifyServerCertificate(rawCerts, serverName)
+		}
+	}
+	return clientConfig
+}
+
+// Watch checks the files for changes every reload interval until doneCh is closed,
+// and reports how long the certificates are still valid
+func (r *Reloader) Watch(scope tally.Scope, doneCh chan struct{}) {
+	r.reportExpiries(scope)
+	go func() {
+		ticker := time.NewTicker(r.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if err := r.reload(); err != nil {
+					scope.Counter(reloadErrorsCount).Inc(1)
+				}
+				r.reportExpiries(scope)
+			case <-doneCh:
+				return
+			}
+		}
+	}()
+}
+
+func (r *Reloader) getClientCertificate(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+	certificates := r.TLSConfig().Certificates
+	if len(certificates) == 0 {
+		return nil, errNoClientCertificate
+	}
+	return &certificates[0], nil
+}
+
+func (r *Reloader) verifyServerCertificate(rawCerts [][]byte, serverName string) error {
+	if len(rawCerts) == 0 {
+		return errors.New("server didn't present any certificate")
+	}
+	certs := make([]*x509.Certificate, 0, len(rawCerts))
+	for _, rawCert := range rawCerts {
+		cert, err := x509.ParseCertificate(rawCert)
+		if err != nil {
+			return err
+		}
+		certs = append(certs, cert)
+	}
+	intermediates := x509.NewCertPool()
+	for _, cert := range certs[1:] {
+		intermediates.AddCert(cert)
+	}
+	_, err := certs[0].Verify(x509.VerifyOptions{
+		DNSName:       serverName,
+		Roots:         r.TLSConfig().RootCAs,
+		Intermediates: intermediates,
+	})
+	return err
+}
+
+func (r *Reloader) maybeReload() {
+	r.RLock()
+	due := time.Since(r.lastCheck) >= r.interval
+	r.RUnlock()
+	if due {
+		_ = r.reload()
+	}
+}
+
+// reload loads the files again if any of them changed, the previous files are kept in use on failure
+func (r *Reloader) reload() error {
+	r.Lock()
+	r.lastCheck = time.Now()
+	previous := r.modTimes
+	r.Unlock()
+
+	modTimes, err := r.stat()
+	if err == nil && !changed(previous, modTimes) {
+		return nil
+	}
+	if err == nil {
+		err = r.load(modTimes)
+	}
+	if err != nil {
+		r.logger.Error("failed to reload TLS certificates, keeping the previous ones", tag.Error(err))
+		return err
+	}
+	r.logger.Info("reloaded TLS certificates", tag.Value(r.files()))
+	return nil
+}
+
+func (r *Reloader) load(modTimes map[string]time.Time) error {
+	tlsConfig, err := r.cfg.ToTLSConfig()
+	if err != nil {
+		return err
+	}
+	expiries := map[string]time.Time{}
+	for _, file := range r.certificateFiles() {
+		expiry, err := earliestExpiry(file)
+		if err != nil {
+			return err
+		}
+		expiries[file] = expiry
+	}
+
+	r.Lock()
+	defer r.Unlock()
+	r.tlsConfig = tlsConfig
+	r.modTimes = modTimes
+	r.expiries = expiries
+	r.lastCheck = time.Now()
+	return nil
+}
+
+func (r *Reloader) reportExpiries(scope tally.Scope) {
+	r.RLock()
+	defer r.RUnlock()
+	for file, expiry := range r.expiries {
+		scope.Tagged(map[string]string{certificateFile: file}).Gauge(expiryGauge).Update(time.Until(expiry).Seconds())
+	}
+}
+
+func (r *Reloader) stat() (map[string]time.Time, error) {
+	modTimes := map[string]time.Time{}
+	for _, file := range r.files() {
+		info, err := os.Stat(file)
+		if err != nil {
+			return nil, err
+		}
+		modTimes[file] = info.ModTime()
+	}
+	return modTimes, nil
+}
+
+// files returns the certificate, key and CA files of the config
+func (r *Reloader) files() []string {
+	files := r.certificateFiles()
+	if r.cfg.CertFile != "" && r.cfg.KeyFile != "" {
+		files = append(files, r.cfg.KeyFile)
+	}
+	return files
+}
+
+// certificateFiles returns the files holding certificates which expire
+func (r *Reloader) certificateFiles() []string {
+	files := append([]string{}, r.cfg.CaFiles...)
+	if r.cfg.CaFile != "" {
+		files = append(files, r.cfg.CaFile)
+	}
+	if r.cfg.CertFile != "" && r.cfg.KeyFile != "" {
+		files = append(files, r.cfg.CertFile)
+	}
+	return files
+}
+
+func changed(previous, current map[string]time.Time) bool {
+	if len(previous) != len(current) {
+		return true
+	}
+	for file, modTime := range current {
+		if !previous[file].Equal(modTime) {
+			return true
+		}
+	}
+	return false
+}
+
+// earliestExpiry returns the earliest expiration time of the certificates of the PEM file
+func earliestExpiry(file string) (time.Time, error) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return time.Time{}, err
+	}
+	var expiry time.Time
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("failed to parse certificate of %v: %v", file, err)
+		}
+		if expiry.IsZero() || cert.NotAfter.Before(expiry) {
+			expiry = cert.NotAfter
+		}
+	}
+	if expiry.IsZero() {
+		return time.Time{}, fmt.Errorf("no certificate found in %v", file)
+	}
+	return expiry, nil
+}
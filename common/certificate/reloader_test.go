@@ -0,0 +1,188 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package certificate
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+	"github.com/uber-go/tally"
+
+	"github.com/uber/cadence/common/config"
+	"github.com/uber/cadence/common/log"
+)
+
+type (
+	reloaderSuite struct {
+		suite.Suite
+		*require.Assertions
+
+		tempDir string
+		cfg     config.TLS
+	}
+)
+
+func TestReloaderSuite(t *testing.T) {
+	suite.Run(t, new(reloaderSuite))
+}
+
+func (s *reloaderSuite) SetupTest() {
+	s.Assertions = require.New(s.T())
+	tempDir, err := ioutil.TempDir("", "reloader_test")
+	s.NoError(err)
+	s.tempDir = tempDir
+	s.cfg = config.TLS{
+		Enabled:                true,
+		CertFile:               filepath.Join(tempDir, "cert.pem"),
+		KeyFile:                filepath.Join(tempDir, "key.pem"),
+		CaFile:                 filepath.Join(tempDir, "ca.pem"),
+		EnableHostVerification: true,
+		ServerName:             "cadence.example.com",
+		ReloadInterval:         time.Millisecond,
+	}
+}
+
+func (s *reloaderSuite) TearDownTest() {
+	s.NoError(os.RemoveAll(s.tempDir))
+}
+
+// writeCertificate writes a self-signed certificate valid for the duration, and its key
+func (s *reloaderSuite) writeCertificate(commonName string, validity time.Duration, modTime time.Time) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	s.NoError(err)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: commonName},
+		DNSNames:              []string{"cadence.example.com"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(validity),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	s.NoError(err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	s.NoError(err)
+
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	s.NoError(ioutil.WriteFile(s.cfg.CertFile, certPEM, 0644))
+	s.NoError(ioutil.WriteFile(s.cfg.CaFile, certPEM, 0644))
+	s.NoError(ioutil.WriteFile(s.cfg.KeyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600))
+	for _, file := range []string{s.cfg.CertFile, s.cfg.CaFile, s.cfg.KeyFile} {
+		s.NoError(os.Chtimes(file, modTime, modTime))
+	}
+}
+
+func (s *reloaderSuite) commonName(tlsConfig *tls.Config) string {
+	s.Len(tlsConfig.Certificates, 1)
+	cert, err := x509.ParseCertificate(tlsConfig.Certificates[0].Certificate[0])
+	s.NoError(err)
+	return cert.Subject.CommonName
+}
+
+func (s *reloaderSuite) TestDisabled() {
+	reloader, err := NewReloader(config.TLS{}, log.NewNoop())
+	s.NoError(err)
+	s.Nil(reloader)
+}
+
+func (s *reloaderSuite) TestMissingFiles() {
+	_, err := NewReloader(s.cfg, log.NewNoop())
+	s.Error(err)
+}
+
+func (s *reloaderSuite) TestReload() {
+	s.writeCertificate("first", time.Hour, time.Now().Add(-time.Minute))
+	reloader, err := NewReloader(s.cfg, log.NewNoop())
+	s.NoError(err)
+	s.Equal("first", s.commonName(reloader.TLSConfig()))
+
+	s.writeCertificate("second", time.Hour, time.Now())
+	time.Sleep(2 * time.Millisecond)
+	s.Equal("second", s.commonName(reloader.TLSConfig()))
+}
+
+func (s *reloaderSuite) TestReloadFailureKeepsPrevious() {
+	s.writeCertificate("first", time.Hour, time.Now().Add(-time.Minute))
+	reloader, err := NewReloader(s.cfg, log.NewNoop())
+	s.NoError(err)
+
+	s.NoError(ioutil.WriteFile(s.cfg.CertFile, []byte("invalid"), 0644))
+	s.Error(reloader.reload())
+	s.Equal("first", s.commonName(reloader.TLSConfig()))
+}
+
+func (s *reloaderSuite) TestClientTLSConfig() {
+	s.writeCertificate("first", time.Hour, time.Now().Add(-time.Minute))
+	reloader, err := NewReloader(s.cfg, log.NewNoop())
+	s.NoError(err)
+	clientConfig := reloader.ClientTLSConfig("")
+	s.True(clientConfig.InsecureSkipVerify)
+	s.NotNil(clientConfig.VerifyPeerCertificate)
+
+	cert, err := clientConfig.GetClientCertificate(&tls.CertificateRequestInfo{})
+	s.NoError(err)
+	first := cert.Certificate[0]
+	s.NoError(clientConfig.VerifyPeerCertificate([][]byte{first}, nil))
+
+	// the server presenting the rotated certificate is verified with the rotated CA
+	s.writeCertificate("second", time.Hour, time.Now())
+	time.Sleep(2 * time.Millisecond)
+	cert, err = clientConfig.GetClientCertificate(&tls.CertificateRequestInfo{})
+	s.NoError(err)
+	s.NotEqual(first, cert.Certificate[0])
+	s.NoError(clientConfig.VerifyPeerCertificate([][]byte{cert.Certificate[0]}, nil))
+	s.Error(clientConfig.VerifyPeerCertificate([][]byte{first}, nil))
+}
+
+func (s *reloaderSuite) TestWatchReportsExpiry() {
+	s.writeCertificate("first", 24*time.Hour, time.Now().Add(-time.Minute))
+	s.cfg.ReloadInterval = time.Hour
+	reloader, err := NewReloader(s.cfg, log.NewNoop())
+	s.NoError(err)
+
+	scope := tally.NewTestScope("", nil)
+	doneCh := make(chan struct{})
+	defer close(doneCh)
+	reloader.Watch(scope, doneCh)
+
+	gauges := scope.Snapshot().Gauges()
+	s.Len(gauges, 2)
+	for _, gauge := range gauges {
+		s.Equal(expiryGauge, gauge.Name())
+		s.InDelta((24 * time.Hour).Seconds(), gauge.Value(), 60)
+	}
+}
@@ -0,0 +1,89 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	// AuditSinkFile appends the audit events as JSON lines to a local file
+	AuditSinkFile = "file"
+	// AuditSinkKafka publishes the audit events to the kafka topic of the audit application
+	AuditSinkKafka = "kafka"
+	// AuditSinkPersistence enqueues the audit events to the audit queue of the default store,
+	// which is the one queried by the admin audit CLI command
+	AuditSinkPersistence = "persistence"
+)
+
+type (
+	// Audit is the config for the audit log of mutating API calls, audit is disabled when there is no sink
+	Audit struct {
+		Sinks []AuditSink `yaml:"sinks"`
+	}
+
+	// AuditSink is a destination of the audit events
+	AuditSink struct {
+		// Type is one of file, kafka and persistence
+		Type string `yaml:"type"`
+		// Path is the file the audit events are appended to, only used by the file sink
+		Path string `yaml:"path"`
+		// MaxSizeInMB is the size beyond which the file of the file sink is rotated, the file is never rotated if 0
+		MaxSizeInMB int `yaml:"maxSizeInMB"`
+		// Retention is how long the events are kept in the audit queue, only used by the persistence sink.
+		// The events are kept forever if 0
+		Retention time.Duration `yaml:"retention"`
+	}
+)
+
+// Validate validates the audit config
+func (a *Audit) Validate() error {
+	for _, sink := range a.Sinks {
+		switch sink.Type {
+		case AuditSinkFile:
+			if sink.Path == "" {
+				return fmt.Errorf("[AuditConfig] Path of file sink can't be empty")
+			}
+			if sink.MaxSizeInMB < 0 {
+				return fmt.Errorf("[AuditConfig] MaxSizeInMB of file sink can't be negative")
+			}
+		case AuditSinkPersistence:
+			if sink.Retention < 0 {
+				return fmt.Errorf("[AuditConfig] Retention of persistence sink can't be negative")
+			}
+		case AuditSinkKafka:
+		default:
+			return fmt.Errorf("[AuditConfig] Unknown sink type %v", sink.Type)
+		}
+	}
+	return nil
+}
+
+// HasSink returns whether a sink of the type is configured
+func (a *Audit) HasSink(sinkType string) bool {
+	for _, sink := range a.Sinks {
+		if sink.Type == sinkType {
+			return true
+		}
+	}
+	return false
+}
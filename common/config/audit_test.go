@@ -0,0 +1,47 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuditValidation(t *testing.T) {
+	cfg := Audit{}
+	assert.NoError(t, cfg.Validate())
+
+	cfg.Sinks = []AuditSink{{Type: AuditSinkPersistence}, {Type: AuditSinkKafka}}
+	assert.NoError(t, cfg.Validate())
+	assert.True(t, cfg.HasSink(AuditSinkKafka))
+	assert.False(t, cfg.HasSink(AuditSinkFile))
+
+	cfg.Sinks = append(cfg.Sinks, AuditSink{Type: AuditSinkFile})
+	assert.EqualError(t, cfg.Validate(), "[AuditConfig] Path of file sink can't be empty")
+
+	cfg.Sinks = []AuditSink{{Type: AuditSinkPersistence, Retention: -time.Hour}}
+	assert.EqualError(t, cfg.Validate(), "[AuditConfig] Retention of persistence sink can't be negative")
+
+	cfg.Sinks = []AuditSink{{Type: "stdout"}}
+	assert.EqualError(t, cfg.Validate(), "[AuditConfig] Unknown sink type stdout")
+}
@@ -22,13 +22,20 @@ package config
 
 import (
 	"fmt"
+	"net/url"
 
 	"github.com/cristalhq/jwt/v3"
 )
 
 // Validate validates the persistence config
 func (a *Authorization) Validate() error {
-	if a.OAuthAuthorizer.Enable && a.NoopAuthorizer.Enable {
+	enabled := 0
+	for _, enable := range []bool{a.OAuthAuthorizer.Enable, a.NoopAuthorizer.Enable, a.MTLSAuthorizer.Enable} {
+		if enable {
+			enabled++
+		}
+	}
+	if enabled > 1 {
 		return fmt.Errorf("[AuthorizationConfig] More than one authorizer is enabled")
 	}
 
@@ -38,20 +45,70 @@ func (a *Authorization) Validate() error {
 		}
 	}
 
+	if a.MTLSAuthorizer.Enable && a.MTLSAuthorizer.GroupMappingFile == "" {
+		return fmt.Errorf("[MTLSConfig] GroupMappingFile can't be empty")
+	}
+
+	return nil
+}
+
+// validateInbound validates that the frontend RPC config gives the mTLS authorizer a verified client certificate,
+// which is only available on the gRPC inbound
+func (m *MTLSAuthorizer) validateInbound(rpc RPC) error {
+	if rpc.GRPCPort == 0 {
+		return fmt.Errorf("[MTLSConfig] frontend GRPCPort must be set, requests through TChannel can't be authorized")
+	}
+	if !rpc.TLS.Enabled || !rpc.TLS.RequireClientAuth {
+		return fmt.Errorf("[MTLSConfig] frontend TLS must be enabled with RequireClientAuth")
+	}
 	return nil
 }
 
 func (a *Authorization) validateOAuth() error {
 	oauthConfig := a.OAuthAuthorizer
 
+	if oauthConfig.Provider != nil {
+		return oauthConfig.Provider.validate()
+	}
 	if oauthConfig.MaxJwtTTL <= 0 {
 		return fmt.Errorf("[OAuthConfig] MaxTTL must be greater than 0")
 	}
 	if oauthConfig.JwtCredentials.PublicKey == "" {
 		return fmt.Errorf("[OAuthConfig] PublicKey can't be empty")
 	}
-	if oauthConfig.JwtCredentials.Algorithm != jwt.RS256.String() {
-		return fmt.Errorf("[OAuthConfig] The only supported Algorithm is RS256")
+	if !isSupportedJwtAlgorithm(oauthConfig.JwtCredentials.Algorithm) {
+		return fmt.Errorf("[OAuthConfig] Algorithm %v is not supported", oauthConfig.JwtCredentials.Algorithm)
 	}
 	return nil
 }
+
+func (p *OAuthProvider) validate() error {
+	if p.JWKSURL == "" && p.Issuer == "" {
+		return fmt.Errorf("[OAuthConfig] Provider must have either JWKSURL or Issuer")
+	}
+	if p.JWKSURL != "" {
+		jwksURL, err := url.Parse(p.JWKSURL)
+		if err != nil {
+			return fmt.Errorf("[OAuthConfig] invalid JWKSURL: %v", err)
+		}
+		if jwksURL.Scheme != "http" && jwksURL.Scheme != "https" && jwksURL.Scheme != "file" {
+			return fmt.Errorf("[OAuthConfig] JWKSURL must be a http, https or file URL")
+		}
+	}
+	if p.RefreshInterval < 0 {
+		return fmt.Errorf("[OAuthConfig] RefreshInterval can't be negative")
+	}
+	return nil
+}
+
+func isSupportedJwtAlgorithm(algorithm string) bool {
+	switch jwt.Algorithm(algorithm) {
+	case jwt.RS256, jwt.RS384, jwt.RS512,
+		jwt.PS256, jwt.PS384, jwt.PS512,
+		jwt.ES256, jwt.ES384, jwt.ES512,
+		jwt.EdDSA:
+		return true
+	default:
+		return false
+	}
+}
@@ -91,7 +91,7 @@ func TestAlgorithmIsInvalid(t *testing.T) {
 	}
 
 	err := cfg.Validate()
-	assert.EqualError(t, err, "[OAuthConfig] The only supported Algorithm is RS256")
+	assert.EqualError(t, err, "[OAuthConfig] Algorithm SHA256 is not supported")
 }
 
 func TestCorrectValidation(t *testing.T) {
@@ -112,3 +112,80 @@ func TestCorrectValidation(t *testing.T) {
 	err := cfg.Validate()
 	assert.NoError(t, err)
 }
+
+func TestProviderValidation(t *testing.T) {
+	tests := map[string]struct {
+		provider OAuthProvider
+		err      string
+	}{
+		"jwks url": {
+			provider: OAuthProvider{JWKSURL: "https://idp.example.com/.well-known/jwks.json"},
+		},
+		"jwks file": {
+			provider: OAuthProvider{JWKSURL: "file:///etc/cadence/jwks.json"},
+		},
+		"issuer discovery": {
+			provider: OAuthProvider{Issuer: "https://idp.example.com", Audience: "cadence"},
+		},
+		"no jwks url or issuer": {
+			provider: OAuthProvider{Audience: "cadence"},
+			err:      "[OAuthConfig] Provider must have either JWKSURL or Issuer",
+		},
+		"unsupported scheme": {
+			provider: OAuthProvider{JWKSURL: "ftp://idp.example.com/jwks.json"},
+			err:      "[OAuthConfig] JWKSURL must be a http, https or file URL",
+		},
+		"negative refresh interval": {
+			provider: OAuthProvider{JWKSURL: "https://idp.example.com/jwks.json", RefreshInterval: -1},
+			err:      "[OAuthConfig] RefreshInterval can't be negative",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			provider := test.provider
+			cfg := Authorization{
+				OAuthAuthorizer: OAuthAuthorizer{
+					Enable:   true,
+					Provider: &provider,
+				},
+			}
+			err := cfg.Validate()
+			if test.err == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, test.err)
+			}
+		})
+	}
+}
+
+func TestMTLSValidation(t *testing.T) {
+	cfg := Authorization{
+		MTLSAuthorizer: MTLSAuthorizer{
+			Enable: true,
+		},
+	}
+	assert.EqualError(t, cfg.Validate(), "[MTLSConfig] GroupMappingFile can't be empty")
+
+	cfg.MTLSAuthorizer.GroupMappingFile = "config/mtls_groups.yaml"
+	assert.NoError(t, cfg.Validate())
+
+	cfg.OAuthAuthorizer.Enable = true
+	assert.EqualError(t, cfg.Validate(), "[AuthorizationConfig] More than one authorizer is enabled")
+}
+
+func TestMTLSInboundValidation(t *testing.T) {
+	cfg := MTLSAuthorizer{Enable: true, GroupMappingFile: "config/mtls_groups.yaml"}
+	rpc := RPC{Port: 7933}
+	assert.EqualError(t, cfg.validateInbound(rpc), "[MTLSConfig] frontend GRPCPort must be set, requests through TChannel can't be authorized")
+
+	rpc.GRPCPort = 7833
+	assert.EqualError(t, cfg.validateInbound(rpc), "[MTLSConfig] frontend TLS must be enabled with RequireClientAuth")
+
+	rpc.TLS = TLS{Enabled: true}
+	assert.EqualError(t, cfg.validateInbound(rpc), "[MTLSConfig] frontend TLS must be enabled with RequireClientAuth")
+
+	rpc.TLS.RequireClientAuth = true
+	assert.NoError(t, cfg.validateInbound(rpc))
+}
@@ -73,11 +73,24 @@ type (
 		Blobstore Blobstore `yaml:"blobstore"`
 		// Authorization is the config for setting up authorization
 		Authorization Authorization `yaml:"authorization"`
+		// Audit is the config for the audit log of mutating API calls
+		Audit Audit `yaml:"audit"`
 	}
 
 	Authorization struct {
 		OAuthAuthorizer OAuthAuthorizer `yaml:"oauthAuthorizer"`
 		NoopAuthorizer  NoopAuthorizer  `yaml:"noopAuthorizer"`
+		MTLSAuthorizer  MTLSAuthorizer  `yaml:"mtlsAuthorizer"`
+		// APIKeyAuthorizer authorizes the requests carrying a domain API key, the other requests are
+		// authorized by the OAuth or mTLS authorizer if enabled
+		APIKeyAuthorizer APIKeyAuthorizer `yaml:"apiKeyAuthorizer"`
+		// AdminPermissions grants groups fine-grained permissions on the admin APIs without being admin,
+		// it maps the permissions (dlq, task, shard, dynamicconfig, failover, domain, searchattribute,
+		// replication and describe) to the groups having them
+		AdminPermissions map[string][]string `yaml:"adminPermissions"`
+		// ShadowMode allows the requests denied by the authorizer, logging them and emitting the
+		// cadence_authorization_shadow_denied metric instead, to roll out authorization without rejecting calls
+		ShadowMode bool `yaml:"shadowMode"`
 	}
 
 	DynamicConfig struct {
@@ -96,15 +109,51 @@ type (
 		JwtCredentials JwtCredentials `yaml:"jwtCredentials"`
 		// Max of TTL in the claim
 		MaxJwtTTL int64 `yaml:"maxJwtTTL"`
+		// Provider is the identity provider publishing the keys to verify the JWT, JwtCredentials is not used when it's set
+		Provider *OAuthProvider `yaml:"provider"`
+		// TokenCacheSize is the max number of verified JWTs cached not to verify their signature on every call,
+		// default is 10000, a negative size disables the cache
+		TokenCacheSize int `yaml:"tokenCacheSize"`
+		// TokenCacheTTL is how long a verified JWT is cached, default is 5m
+		// The expiration of cached JWTs is still checked on every call
+		TokenCacheTTL time.Duration `yaml:"tokenCacheTTL"`
+	}
+
+	// MTLSAuthorizer authorizes requests by the verified client certificate of the inbound call.
+	// Only the gRPC inbound of frontend terminates TLS, so it requires the gRPC port to be set up with TLS and
+	// RequireClientAuth. Requests received through the TChannel port have no certificate and are always denied
+	MTLSAuthorizer struct {
+		Enable bool `yaml:"enable"`
+		// GroupMappingFile is the path of the yaml file mapping the identities of client certificates to groups
+		GroupMappingFile string `yaml:"groupMappingFile"`
+	}
+
+	// APIKeyAuthorizer authorizes requests by the API keys created for the domains
+	APIKeyAuthorizer struct {
+		Enable bool `yaml:"enable"`
 	}
 
 	JwtCredentials struct {
-		// support: RS256 (RSA using SHA256)
+		// support: RS256/384/512, PS256/384/512, ES256/384/512 and EdDSA
 		Algorithm string `yaml:"algorithm"`
 		// Public Key Path for verifying JWT token passed in from external clients
 		PublicKey string `yaml:"publicKey"`
 	}
 
+	// OAuthProvider is an OIDC identity provider, whose keys are fetched from a JWKS document
+	OAuthProvider struct {
+		// JWKSURL is the location of the JWKS document, either a http(s):// URL or a file:// path
+		// It's discovered from the OpenID configuration of the Issuer when not set
+		JWKSURL string `yaml:"jwksURL"`
+		// Issuer is the value the iss claim of the JWT must have, not checked when empty
+		Issuer string `yaml:"issuer"`
+		// Audience is the value the aud claim of the JWT must contain, not checked when empty
+		Audience string `yaml:"audience"`
+		// RefreshInterval is the interval to refresh the JWKS document, default is 1h
+		// The document is also refreshed, at most once a minute, when a JWT is signed by an unknown key
+		RefreshInterval time.Duration `yaml:"refreshInterval"`
+	}
+
 	// Service contains the service specific config items
 	Service struct {
 		// TChannel is the tchannel configuration
@@ -179,6 +228,12 @@ type (
 		// AdvancedVisibilityStore is the name of the datastore to be used for visibility records
 		// Must provide one of VisibilityStore and AdvancedVisibilityStore
 		AdvancedVisibilityStore string `yaml:"advancedVisibilityStore"`
+		// MigrationTargetStore is the name of the datastore that the data of the default store is migrated to.
+		// Writes to the default store are mirrored to this datastore when PersistenceMigrationMode is shadow
+		MigrationTargetStore string `yaml:"migrationTargetStore"`
+		// PayloadEncryption is the config for encrypting workflow payloads at rest.
+		// Payloads are stored unencrypted if it is not specified
+		PayloadEncryption *PayloadEncryption `yaml:"payloadEncryption"`
 		// HistoryMaxConns is the desired number of conns to history store. Value specified
 		// here overrides the MaxConns config specified as part of datastore
 		HistoryMaxConns int `yaml:"historyMaxConns"`
@@ -194,6 +249,15 @@ type (
 		// TODO: move dynamic config out of static config
 		// ErrorInjectionRate is the the rate for injecting random error
 		ErrorInjectionRate dynamicconfig.FloatPropertyFn `yaml:"-" json:"-"`
+		// TODO: move dynamic config out of static config
+		// MigrationMode is the mode for writing to the MigrationTargetStore
+		MigrationMode dynamicconfig.StringPropertyFn `yaml:"-" json:"-"`
+	}
+
+	// PayloadEncryption is the config for encrypting workflow payloads at rest
+	PayloadEncryption struct {
+		// KeyFile is the path of the YAML file containing the encryption keys and the active key ID
+		KeyFile string `yaml:"keyFile"`
 	}
 
 	// DataStore is the configuration for a single datastore
@@ -215,7 +279,7 @@ type (
 
 	// NoSQL contains configuration to connect to NoSQL Database cluster
 	NoSQL struct {
-		// PluginName is the name of NoSQL plugin, default is "cassandra". Supported values: cassandra
+		// PluginName is the name of NoSQL plugin, default is "cassandra". Supported values: cassandra, dynamodb
 		PluginName string `yaml:"pluginName"`
 		// Hosts is a csv of cassandra endpoints
 		Hosts string `yaml:"hosts" validate:"nonzero"`
@@ -225,9 +289,9 @@ type (
 		User string `yaml:"user"`
 		// Password is the cassandra password used for authentication by gocql client
 		Password string `yaml:"password"`
-		// Keyspace is the cassandra keyspace
+		// Keyspace is the cassandra keyspace, or the table name prefix for dynamodb
 		Keyspace string `yaml:"keyspace"`
-		// Region is the region filter arg for cassandra
+		// Region is the region filter arg for cassandra, or the AWS region for dynamodb
 		Region string `yaml:"region"`
 		// Datacenter is the data center filter arg for cassandra
 		Datacenter string `yaml:"datacenter"`
@@ -291,6 +355,24 @@ type (
 		// Required when UseMultipleDatabases is true
 		// the length of the list should be exactly the same as NumShards
 		MultipleDatabasesConfig []MultipleDatabasesConfigEntry `yaml:"multipleDatabasesConfig"`
+		// Replicas is the list of read replicas of the database. Non-conditional reads which can tolerate
+		// replication lag (e.g. visibility list queries, reading history) are routed to the replicas.
+		// If useMultipleDatabases, must be empty and provide it via multipleDatabasesConfig instead
+		Replicas []ReplicaConfigEntry `yaml:"replicas"`
+		// MaxReplicationLag is the lag tolerance of the read replicas. A replica lagging behind the primary
+		// by more than this value is not read from until it catches up. Default is 5s
+		MaxReplicationLag time.Duration `yaml:"maxReplicationLag"`
+		// Partitioning must be set when the history_node and executions tables are created by the partitioned schema,
+		// where every partition of the tables covers a time bucket and is dropped as a whole once expired.
+		// Only supported by MySQL and Postgres
+		Partitioning *SQLPartitioning `yaml:"partitioning"`
+	}
+
+	// SQLPartitioning is the configuration of the partitioned schema of SQL datastores
+	SQLPartitioning struct {
+		// BucketSize is the time range covered by a partition, default is 24h
+		// It cannot be changed once the partitions are created
+		BucketSize time.Duration `yaml:"bucketSize"`
 	}
 
 	// MultipleDatabasesConfigEntry is an entry for MultipleDatabasesConfig to connect to a single SQL database
@@ -303,6 +385,18 @@ type (
 		DatabaseName string `yaml:"databaseName" validate:"nonzero"`
 		// ConnectAddr is the remote addr of the database
 		ConnectAddr string `yaml:"connectAddr" validate:"nonzero"`
+		// Replicas is the list of read replicas of this database
+		Replicas []ReplicaConfigEntry `yaml:"replicas"`
+	}
+
+	// ReplicaConfigEntry is the configuration for connecting to a read replica of a SQL database
+	ReplicaConfigEntry struct {
+		// User is the username to be used for the conn, default to the user of the primary database
+		User string `yaml:"user"`
+		// Password is the password corresponding to the user name, default to the password of the primary database
+		Password string `yaml:"password"`
+		// ConnectAddr is the remote addr of the replica
+		ConnectAddr string `yaml:"connectAddr" validate:"nonzero"`
 	}
 
 	// CustomDatastoreConfig is the configuration for connecting to a custom datastore that is not supported by cadence core
@@ -532,7 +626,15 @@ func (c *Config) validate() error {
 		return err
 	}
 
-	return c.Authorization.Validate()
+	if err := c.Authorization.Validate(); err != nil {
+		return err
+	}
+	if c.Authorization.MTLSAuthorizer.Enable {
+		if err := c.Authorization.MTLSAuthorizer.validateInbound(c.Services["frontend"].RPC); err != nil {
+			return err
+		}
+	}
+	return c.Audit.Validate()
 }
 
 func (c *Config) fillDefaults() {
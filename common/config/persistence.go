@@ -22,6 +22,7 @@ package config
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/uber/cadence/common"
 )
@@ -31,6 +32,8 @@ const (
 	StoreTypeSQL = "sql"
 	// StoreTypeCassandra refers to cassandra as persistence store
 	StoreTypeCassandra = "cassandra"
+
+	defaultSQLPartitionBucketSize = 24 * time.Hour
 )
 
 // DefaultStoreType returns the storeType for the default persistence store
@@ -64,6 +67,9 @@ func (c *Persistence) FillDefaults() {
 			if store.SQL.NumShards == 0 {
 				store.SQL.NumShards = 1
 			}
+			if store.SQL.Partitioning != nil && store.SQL.Partitioning.BucketSize == 0 {
+				store.SQL.Partitioning.BucketSize = defaultSQLPartitionBucketSize
+			}
 		}
 
 		// write changes back to DataStores, as ds is a value object
@@ -85,6 +91,17 @@ func (c *Persistence) Validate() error {
 		useAdvancedVisibilityOnly = true
 	}
 
+	if c.MigrationTargetStore != "" {
+		if c.MigrationTargetStore == c.DefaultStore {
+			return fmt.Errorf("persistence config: migrationTargetStore must be different from defaultStore")
+		}
+		dbStoreKeys = append(dbStoreKeys, c.MigrationTargetStore)
+	}
+
+	if c.PayloadEncryption != nil && c.PayloadEncryption.KeyFile == "" {
+		return fmt.Errorf("persistence config: payloadEncryption must provide a keyFile")
+	}
+
 	for _, st := range dbStoreKeys {
 		ds, ok := c.DataStores[st]
 		if !ok {
@@ -116,6 +133,9 @@ func (c *Persistence) Validate() error {
 				if ds.SQL.Password != "" {
 					return fmt.Errorf("sql persistence config: password can only be configured in multipleDatabasesConfig when UseMultipleDatabases is true")
 				}
+				if len(ds.SQL.Replicas) != 0 {
+					return fmt.Errorf("sql persistence config: replicas can only be configured in multipleDatabasesConfig when UseMultipleDatabases is true")
+				}
 				if ds.SQL.NumShards <= 1 || len(ds.SQL.MultipleDatabasesConfig) != ds.SQL.NumShards {
 					return fmt.Errorf("sql persistence config: nShards must be greater than one and equal to the length of multipleDatabasesConfig")
 				}
@@ -126,6 +146,9 @@ func (c *Persistence) Validate() error {
 					if entry.ConnectAddr == "" {
 						return fmt.Errorf("sql multipleDatabasesConfig persistence config: connectAddr can not be empty")
 					}
+					if err := validateSQLReplicas(entry.Replicas); err != nil {
+						return err
+					}
 				}
 			} else {
 				if ds.SQL.DatabaseName == "" {
@@ -134,6 +157,15 @@ func (c *Persistence) Validate() error {
 				if ds.SQL.ConnectAddr == "" {
 					return fmt.Errorf("sql persistence config: connectAddr can not be empty")
 				}
+				if err := validateSQLReplicas(ds.SQL.Replicas); err != nil {
+					return err
+				}
+			}
+			if ds.SQL.MaxReplicationLag < 0 {
+				return fmt.Errorf("sql persistence config: maxReplicationLag can not be negative")
+			}
+			if ds.SQL.Partitioning != nil && ds.SQL.Partitioning.BucketSize < time.Hour {
+				return fmt.Errorf("sql persistence config: partitioning bucketSize must be at least 1h")
 			}
 		}
 	}
@@ -141,6 +173,20 @@ func (c *Persistence) Validate() error {
 	return nil
 }
 
+func validateSQLReplicas(replicas []ReplicaConfigEntry) error {
+	for _, replica := range replicas {
+		if replica.ConnectAddr == "" {
+			return fmt.Errorf("sql replicas persistence config: connectAddr can not be empty")
+		}
+	}
+	return nil
+}
+
+// IsMigrationConfigExist returns whether user specified migrationTargetStore in config
+func (c *Persistence) IsMigrationConfigExist() bool {
+	return len(c.MigrationTargetStore) != 0
+}
+
 // IsAdvancedVisibilityConfigExist returns whether user specified advancedVisibilityStore in config
 func (c *Persistence) IsAdvancedVisibilityConfigExist() bool {
 	return len(c.AdvancedVisibilityStore) != 0
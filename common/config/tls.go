@@ -24,6 +24,7 @@ import (
 	"crypto/tls"
 	"crypto/x509"
 	"io/ioutil"
+	"time"
 )
 
 type (
@@ -55,6 +56,10 @@ type (
 		RequireClientAuth bool `yaml:"requireClientAuth"`
 
 		ServerName string `yaml:"serverName"`
+
+		// ReloadInterval is how often the certificate, key and CA files are checked for changes by the RPC
+		// transports and the persistence clients, which swap the new files in without restarting. Default is 1 minute.
+		ReloadInterval time.Duration `yaml:"reloadInterval"`
 	}
 )
 
@@ -66,6 +66,11 @@ const (
 	EncodingTypeUnknown  EncodingType = "unknow"
 	EncodingTypeEmpty    EncodingType = ""
 	EncodingTypeProto    EncodingType = "proto3"
+
+	// EncodingTypeThriftRWSnappy is thriftrw compressed with snappy
+	EncodingTypeThriftRWSnappy EncodingType = "thriftrw-snappy"
+	// EncodingTypeThriftRWZstd is thriftrw compressed with zstd
+	EncodingTypeThriftRWZstd EncodingType = "thriftrw-zstd"
 )
 
 type (
@@ -86,6 +91,8 @@ const (
 const (
 	// VisibilityAppName is used to find kafka topics and ES indexName for visibility
 	VisibilityAppName = "visibility"
+	// AuditAppName is used to find kafka topics for the audit events
+	AuditAppName = "audit"
 )
 
 // This was flagged by salus as potentially hardcoded credentials. This is a false positive by the scanner and should be
@@ -111,6 +118,10 @@ const (
 	ShadowerDomainID = "59c51119-1b41-4a28-986d-d6e377716f82"
 	// ShadowerLocalDomainName
 	ShadowerLocalDomainName = shadower.LocalDomainName
+	// SchedulerDomainID is domain id for scheduler local domain
+	SchedulerDomainID = "049f267d-67d6-4633-8311-b841ff245f67"
+	// SchedulerLocalDomainName is domain name for schedule workflows running in local cluster
+	SchedulerLocalDomainName = "cadence-scheduler"
 )
 
 const (
@@ -153,6 +164,14 @@ const (
 	AdvancedVisibilityWritingModeDual = "dual"
 )
 
+// enum for dynamic config PersistenceMigrationMode
+const (
+	// PersistenceMigrationModeOff means only write to the default persistence store
+	PersistenceMigrationModeOff = "off"
+	// PersistenceMigrationModeShadow means writes to the default persistence store are mirrored to the migration target store
+	PersistenceMigrationModeShadow = "shadow"
+)
+
 const (
 	// DomainDataKeyForManagedFailover is key of DomainData for managed failover
 	DomainDataKeyForManagedFailover = "IsManagedByCadence"
@@ -162,6 +181,42 @@ const (
 	DomainDataKeyForReadGroups = "READ_GROUPS"
 	// DomainDataKeyForWriteGroups stores which groups have write permission of the domain API
 	DomainDataKeyForWriteGroups = "WRITE_GROUPS"
+	// DomainDataKeyForAuthorizationRules stores the authorization rules granting groups access scoped to
+	// workflow types, task lists or APIs of the domain, as a JSON array of authorization.Rule
+	DomainDataKeyForAuthorizationRules = "AUTHZ_RULES"
+	// DomainDataKeyForAPIKeys stores the hashed API keys of the domain, as a JSON array of authorization.APIKey
+	DomainDataKeyForAPIKeys = "API_KEYS"
+	// DomainDataKeyForCreateAPIKey is the reserved key of an UpdateDomain request adding the JSON encoded
+	// authorization.APIKey to the API keys of the domain, it's never stored in the domain data
+	DomainDataKeyForCreateAPIKey = "__cadence_create_api_key"
+	// DomainDataKeyForRevokeAPIKey is the reserved key of an UpdateDomain request removing the API key with the
+	// given ID from the API keys of the domain, it's never stored in the domain data
+	DomainDataKeyForRevokeAPIKey = "__cadence_revoke_api_key"
+	// DomainDataKeyForSensitiveReadGroups stores which groups can read the redacted payloads of the domain
+	DomainDataKeyForSensitiveReadGroups = "SENSITIVE_READ_GROUPS"
+	// DomainDataKeyForRedactedPaths stores the JSON paths of the payloads redacted for the callers without
+	// the sensitive read permission, separated by space
+	DomainDataKeyForRedactedPaths = "REDACTED_PATHS"
+	// DomainDataKeyForRedactedSearchAttributes stores the search attribute keys redacted for the callers without
+	// the sensitive read permission, separated by space
+	DomainDataKeyForRedactedSearchAttributes = "REDACTED_SEARCH_ATTRIBUTES"
+	// DomainDataKeyForAllowedClusters stores the space separated clusters the workflow data of the domain is
+	// allowed to be stored in and replicated to, all clusters are allowed when it's not set or empty
+	DomainDataKeyForAllowedClusters = "ALLOWED_CLUSTERS"
+)
+
+const (
+	// PauseWorkflowSignalName is the reserved signal name requesting the pause of a workflow, the request is
+	// handled by the history service and not recorded as a signal in the history of the workflow
+	PauseWorkflowSignalName = "__cadence_pause"
+	// UnpauseWorkflowSignalName is the reserved signal name resuming a paused workflow
+	UnpauseWorkflowSignalName = "__cadence_unpause"
+	// ResetReasonPreservePendingActivityResultsPrefix prefixes the reason of a reset request on the wire when the
+	// reset preserves the results of the activities pending at the reset point
+	ResetReasonPreservePendingActivityResultsPrefix = "__cadence_preserve_pending_activity_results:"
+	// WorkflowIDConflictPolicyHeaderKey is the reserved header key carrying the workflow ID conflict policy
+	// of a start or signal with start request on the wire, it's removed from the header before the request is handled
+	WorkflowIDConflictPolicyHeaderKey = "__cadence_workflow_id_conflict_policy"
 )
 
 type (
@@ -36,6 +36,7 @@ const (
 	Encoding        = "Encoding"
 	KafkaKey        = "KafkaKey"
 	BinaryChecksums = "BinaryChecksums"
+	IsPaused        = "IsPaused"
 	TaskList        = "TaskList"
 	IsCron          = "IsCron"
 	NumClusters     = "NumClusters"
@@ -96,6 +97,12 @@ var systemIndexedKeys = map[string]interface{}{
 	TaskList:      shared.IndexedValueTypeKeyword,
 	IsCron:        shared.IndexedValueTypeBool,
 	NumClusters:   shared.IndexedValueTypeInt,
+	IsPaused:      shared.IndexedValueTypeBool,
+}
+
+// systemSearchAttributeKeys is Cadence created visibility keys that are kept in workflow search attributes
+var systemSearchAttributeKeys = map[string]struct{}{
+	IsPaused: {},
 }
 
 // IsSystemIndexedKey return true is key is system added
@@ -103,3 +110,9 @@ func IsSystemIndexedKey(key string) bool {
 	_, ok := systemIndexedKeys[key]
 	return ok
 }
+
+// IsSystemSearchAttributeKey return true if key is system added and stored as a search attribute
+func IsSystemSearchAttributeKey(key string) bool {
+	_, ok := systemSearchAttributeKeys[key]
+	return ok
+}
@@ -23,6 +23,7 @@ package domain
 import (
 	"fmt"
 
+	"github.com/uber/cadence/common/cache"
 	"github.com/uber/cadence/common/cluster"
 	"github.com/uber/cadence/common/persistence"
 	"github.com/uber/cadence/common/types"
@@ -149,6 +150,22 @@ func (d *AttrValidatorImpl) validateDomainReplicationConfigClustersDoesNotRemove
 	return nil
 }
 
+func (d *AttrValidatorImpl) validateDomainReplicationConfigAllowedClusters(
+	data map[string]string,
+	replicationConfig *persistence.DomainReplicationConfig,
+) error {
+
+	for _, clusterConfig := range replicationConfig.Clusters {
+		if !cache.IsClusterAllowedByDomainData(data, clusterConfig.ClusterName) {
+			return &types.BadRequestError{Message: fmt.Sprintf(
+				"Cluster %v is not allowed by the data residency of the domain",
+				clusterConfig.ClusterName,
+			)}
+		}
+	}
+	return nil
+}
+
 func (d *AttrValidatorImpl) validateClusterName(
 	clusterName string,
 ) error {
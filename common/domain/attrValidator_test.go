@@ -25,6 +25,7 @@ import (
 
 	"github.com/stretchr/testify/suite"
 
+	"github.com/uber/cadence/common"
 	"github.com/uber/cadence/common/cluster"
 	"github.com/uber/cadence/common/mocks"
 	"github.com/uber/cadence/common/persistence"
@@ -204,6 +205,33 @@ func (s *attrValidatorSuite) TestValidateDomainReplicationConfigForGlobalDomain(
 	s.NoError(err)
 }
 
+func (s *attrValidatorSuite) TestValidateDomainReplicationConfigAllowedClusters() {
+	replicationConfig := &persistence.DomainReplicationConfig{
+		ActiveClusterName: cluster.TestCurrentClusterName,
+		Clusters: []*persistence.ClusterReplicationConfig{
+			{ClusterName: cluster.TestCurrentClusterName},
+			{ClusterName: cluster.TestAlternativeClusterName},
+		},
+	}
+
+	err := s.validator.validateDomainReplicationConfigAllowedClusters(nil, replicationConfig)
+	s.NoError(err)
+
+	err = s.validator.validateDomainReplicationConfigAllowedClusters(
+		map[string]string{
+			common.DomainDataKeyForAllowedClusters: cluster.TestCurrentClusterName + " " + cluster.TestAlternativeClusterName,
+		},
+		replicationConfig,
+	)
+	s.NoError(err)
+
+	err = s.validator.validateDomainReplicationConfigAllowedClusters(
+		map[string]string{common.DomainDataKeyForAllowedClusters: cluster.TestCurrentClusterName},
+		replicationConfig,
+	)
+	s.IsType(&types.BadRequestError{}, err)
+}
+
 func (s *attrValidatorSuite) TestValidateDomainReplicationConfigClustersDoesNotRemove() {
 	err := s.validator.validateDomainReplicationConfigClustersDoesNotRemove(
 		[]*persistence.ClusterReplicationConfig{
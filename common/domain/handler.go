@@ -32,6 +32,7 @@ import (
 	"github.com/uber/cadence/common"
 	"github.com/uber/cadence/common/archiver"
 	"github.com/uber/cadence/common/archiver/provider"
+	"github.com/uber/cadence/common/authorization"
 	"github.com/uber/cadence/common/clock"
 	"github.com/uber/cadence/common/cluster"
 	"github.com/uber/cadence/common/dynamicconfig"
@@ -229,6 +230,12 @@ func (d *handlerImpl) RegisterDomain(
 	if err := d.domainAttrValidator.validateDomainConfig(config); err != nil {
 		return err
 	}
+	if err := d.domainAttrValidator.validateDomainReplicationConfigAllowedClusters(
+		registerRequest.Data,
+		replicationConfig,
+	); err != nil {
+		return err
+	}
 	if isGlobalDomain {
 		if err := d.domainAttrValidator.validateDomainReplicationConfigForGlobalDomain(
 			replicationConfig,
@@ -425,6 +432,17 @@ func (d *handlerImpl) UpdateDomain(
 		config.VisibilityArchivalURI = visibilityArchivalState.URI
 	}
 
+	// Resolve the API key operations against the domain data read under the notification version
+	if updateRequest.Data != nil {
+		data, err := authorization.ApplyAPIKeyOperations(info.Data, updateRequest.Data)
+		if err != nil {
+			return nil, err
+		}
+		request := *updateRequest
+		request.Data = data
+		updateRequest = &request
+	}
+
 	// Update domain info
 	info, domainInfoChanged := d.updateDomainInfo(
 		updateRequest,
@@ -485,6 +503,12 @@ func (d *handlerImpl) UpdateDomain(
 	if err := d.domainAttrValidator.validateDomainConfig(config); err != nil {
 		return nil, err
 	}
+	if err := d.domainAttrValidator.validateDomainReplicationConfigAllowedClusters(
+		info.Data,
+		replicationConfig,
+	); err != nil {
+		return nil, err
+	}
 	if isGlobalDomain {
 		if err := d.domainAttrValidator.validateDomainReplicationConfigForGlobalDomain(
 			replicationConfig,
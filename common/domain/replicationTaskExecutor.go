@@ -26,6 +26,7 @@ import (
 	"context"
 	"time"
 
+	"github.com/uber/cadence/common/cache"
 	"github.com/uber/cadence/common/clock"
 	"github.com/uber/cadence/common/log"
 	"github.com/uber/cadence/common/persistence"
@@ -49,6 +50,8 @@ var (
 	ErrInvalidDomainStatus = &types.BadRequestError{Message: "invalid domain status attribute"}
 	// ErrNameUUIDCollision is the error to indicate domain name / UUID collision
 	ErrNameUUIDCollision = &types.BadRequestError{Message: "domain replication encounter name / UUID collision"}
+	// ErrDataResidencyViolation is the error to indicate the domain is replicated to clusters not allowed by its data residency
+	ErrDataResidencyViolation = &types.BadRequestError{Message: "domain replication encounter clusters not allowed by the domain data residency"}
 )
 
 const (
@@ -284,6 +287,11 @@ func (h *domainReplicationTaskExecutorImpl) validateDomainReplicationTask(task *
 	} else if task.ReplicationConfig == nil {
 		return ErrInvalidDomainReplicationConfig
 	}
+	for _, cluster := range task.ReplicationConfig.Clusters {
+		if !cache.IsClusterAllowedByDomainData(task.Info.Data, cluster.GetClusterName()) {
+			return ErrDataResidencyViolation
+		}
+	}
 	return nil
 }
 
@@ -143,6 +143,40 @@ func (s *domainReplicationTaskExecutorSuite) TestExecute_RegisterDomainTask_Name
 	s.IsType(&types.BadRequestError{}, err)
 }
 
+func (s *domainReplicationTaskExecutorSuite) TestExecute_RegisterDomainTask_DataResidencyViolation() {
+	operation := types.DomainOperationCreate
+	status := types.DomainStatusRegistered
+	clusterActive := "some random active cluster name"
+	clusterStandby := "some random standby cluster name"
+
+	task := &types.DomainTaskAttributes{
+		DomainOperation: &operation,
+		ID:              uuid.New(),
+		Info: &types.DomainInfo{
+			Name:   "some random domain test name",
+			Status: &status,
+			Data:   map[string]string{common.DomainDataKeyForAllowedClusters: clusterActive},
+		},
+		Config: &types.DomainConfiguration{
+			WorkflowExecutionRetentionPeriodInDays: 10,
+		},
+		ReplicationConfig: &types.DomainReplicationConfiguration{
+			ActiveClusterName: clusterActive,
+			Clusters: []*types.ClusterReplicationConfiguration{
+				{ClusterName: clusterActive},
+				{ClusterName: clusterStandby},
+			},
+		},
+	}
+
+	err := s.domainReplicator.Execute(task)
+	s.Equal(ErrDataResidencyViolation, err)
+
+	resp, err := s.DomainManager.GetDomain(context.Background(), &persistence.GetDomainRequest{ID: task.ID})
+	s.Nil(resp)
+	s.IsType(&types.EntityNotExistsError{}, err)
+}
+
 func (s *domainReplicationTaskExecutorSuite) TestExecute_RegisterDomainTask() {
 	operation := types.DomainOperationCreate
 	id := uuid.New()
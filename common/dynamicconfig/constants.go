@@ -148,6 +148,12 @@ const (
 	// Default value: 0
 	// Allowed filters: N/A
 	PersistenceErrorInjectionRate
+	// PersistenceMigrationMode is key for how to write to the migrationTargetStore of the persistence config. "shadow" mirrors all the writes to the default store to the migration target store
+	// KeyName: system.persistenceMigrationMode
+	// Value type: String enum: "off" or "shadow"
+	// Default value: "off" (common.PersistenceMigrationModeOff)
+	// Allowed filters: N/A
+	PersistenceMigrationMode
 	// MaxRetentionDays is the maximum allowed retention days for domain
 	// KeyName: system.maxRetentionDays
 	// Value type: Int
@@ -1368,12 +1374,49 @@ const (
 	// Default value: 5m (5*time.Minute)
 	// Allowed filters: N/A
 	ShardSyncMinInterval
-	// DefaultEventEncoding is the encoding type for history events
+	// DefaultEventEncoding is the encoding type for history events. The compressed encodings reduce the size of history,
+	// and history written with any encoding can always be read back. Zstd is only available when built with cgo,
+	// other builds refuse it and use thriftrw.
 	// KeyName: history.defaultEventEncoding
-	// Value type: String
+	// Value type: String enum: "thriftrw", "json", "thriftrw-snappy" (compressed with snappy) or "thriftrw-zstd" (compressed with zstd)
 	// Default value: string(common.EncodingTypeThriftRW)
 	// Allowed filters: DomainName
 	DefaultEventEncoding
+	// EnableDomainStorageTracking is whether the history service tracks the storage used by each domain,
+	// by periodically scanning the workflow executions of its shards
+	// KeyName: history.enableDomainStorageTracking
+	// Value type: Bool
+	// Default value: false
+	// Allowed filters: N/A
+	EnableDomainStorageTracking
+	// DomainStorageScanInterval is the interval between two scans of the workflow executions of a shard
+	// to compute the storage used by each domain
+	// KeyName: history.domainStorageScanInterval
+	// Value type: Duration
+	// Default value: 6h (6*time.Hour)
+	// Allowed filters: N/A
+	DomainStorageScanInterval
+	// DomainStorageScanPageSize is the number of workflow executions read in one page by the domain storage scan
+	// KeyName: history.domainStorageScanPageSize
+	// Value type: Int
+	// Default value: 500
+	// Allowed filters: N/A
+	DomainStorageScanPageSize
+	// DomainStorageScanRPS is the maximum number of pages read per second by the domain storage scan of a shard
+	// KeyName: history.domainStorageScanRPS
+	// Value type: Int
+	// Default value: 1
+	// Allowed filters: N/A
+	DomainStorageScanRPS
+	// DomainStorageQuota is the maximum storage in bytes used by the history and mutable state of a domain.
+	// New workflow executions of a domain are rejected once the storage used by the domain in the shard of the
+	// workflow exceeds the share of the shard (quota / number of shards), 0 means no quota.
+	// It is only enforced when EnableDomainStorageTracking is true
+	// KeyName: history.domainStorageQuota
+	// Value type: Int
+	// Default value: 0
+	// Allowed filters: DomainName
+	DomainStorageQuota
 	// NumArchiveSystemWorkflows is key for number of archive system workflows running in total
 	// KeyName: history.numArchiveSystemWorkflows
 	// Value type: Int
@@ -1796,6 +1839,13 @@ const (
 	// Default value: true
 	// Allowed filters: N/A
 	HistoryScannerEnabled
+	// PartitionRetentionEnabled is indicates if the partition retention job should be started as part of worker.Scanner,
+	// it only runs with sql persistence using the partitioned schema
+	// KeyName: worker.partitionRetentionEnabled
+	// Value type: Bool
+	// Default value: true
+	// Allowed filters: N/A
+	PartitionRetentionEnabled
 	// ConcreteExecutionsScannerEnabled is indicates if executions scanner should be started as part of worker.Scanner
 	// KeyName: worker.executionsScannerEnabled
 	// Value type: Bool
@@ -1916,6 +1966,12 @@ const (
 	// Default value: true
 	// Allowed filters: N/A
 	EnableWorkflowShadower
+	// EnableScheduler decides whether to start the scheduler running schedule workflows in our worker
+	// KeyName: system.enableScheduler
+	// Value type: Bool
+	// Default value: false
+	// Allowed filters: N/A
+	EnableScheduler
 	// ConcreteExecutionFixerDomainAllow is which domains are allowed to be fixed by concrete fixer workflow
 	// KeyName: worker.concreteExecutionFixerDomainAllow
 	// Value type: Bool
@@ -2119,6 +2175,7 @@ var Keys = map[Key]string{
 	EnableGracefulFailover:              "system.enableGracefulFailover",
 	TransactionSizeLimit:                "system.transactionSizeLimit",
 	PersistenceErrorInjectionRate:       "system.persistenceErrorInjectionRate",
+	PersistenceMigrationMode:            "system.persistenceMigrationMode",
 	MaxRetentionDays:                    "system.maxRetentionDays",
 	MinRetentionDays:                    "system.minRetentionDays",
 	MaxDecisionStartToCloseSeconds:      "system.maxDecisionStartToCloseSeconds",
@@ -2128,6 +2185,7 @@ var Keys = map[Key]string{
 	EnableESAnalyzer:                    "system.enableESAnalyzer",
 	EnableFailoverManager:               "system.enableFailoverManager",
 	EnableWorkflowShadower:              "system.enableWorkflowShadower",
+	EnableScheduler:                     "system.enableScheduler",
 	EnableStickyQuery:                   "system.enableStickyQuery",
 	EnableDebugMode:                     "system.enableDebugMode",
 	RequiredDomainDataKeys:              "system.requiredDomainDataKeys",
@@ -2345,6 +2403,11 @@ var Keys = map[Key]string{
 	ShardUpdateMinInterval:                             "history.shardUpdateMinInterval",
 	ShardSyncMinInterval:                               "history.shardSyncMinInterval",
 	DefaultEventEncoding:                               "history.defaultEventEncoding",
+	EnableDomainStorageTracking:                        "history.enableDomainStorageTracking",
+	DomainStorageScanInterval:                          "history.domainStorageScanInterval",
+	DomainStorageScanPageSize:                          "history.domainStorageScanPageSize",
+	DomainStorageScanRPS:                               "history.domainStorageScanRPS",
+	DomainStorageQuota:                                 "history.domainStorageQuota",
 	EnableAdminProtection:                              "history.enableAdminProtection",
 	AdminOperationToken:                                "history.adminOperationToken",
 	EnableParentClosePolicy:                            "history.enableParentClosePolicy",
@@ -2415,6 +2478,7 @@ var Keys = map[Key]string{
 	ScannerMaxTasksProcessedPerTasklistJob:                   "worker.scannerMaxTasksProcessedPerTasklistJob",
 	TaskListScannerEnabled:                                   "worker.taskListScannerEnabled",
 	HistoryScannerEnabled:                                    "worker.historyScannerEnabled",
+	PartitionRetentionEnabled:                                "worker.partitionRetentionEnabled",
 	ConcreteExecutionsScannerEnabled:                         "worker.executionsScannerEnabled",
 	ConcreteExecutionsScannerBlobstoreFlushThreshold:         "worker.executionsScannerBlobstoreFlushThreshold",
 	ConcreteExecutionsScannerActivityBatchSize:               "worker.executionsScannerActivityBatchSize",
@@ -137,7 +137,7 @@ func (qv *VisibilityQueryValidator) validateComparisonExpr(expr sqlparser.Expr)
 	}
 	colNameStr := colName.Name.String()
 	if qv.isValidSearchAttributes(colNameStr) {
-		if !definition.IsSystemIndexedKey(colNameStr) { // add search attribute prefix
+		if !definition.IsSystemIndexedKey(colNameStr) || definition.IsSystemSearchAttributeKey(colNameStr) { // add search attribute prefix
 			comparisonExpr.Left = &sqlparser.ColName{
 				Metadata:  colName.Metadata,
 				Name:      sqlparser.NewColIdent(definition.Attr + "." + colNameStr),
@@ -157,7 +157,7 @@ func (qv *VisibilityQueryValidator) validateRangeExpr(expr sqlparser.Expr) error
 	}
 	colNameStr := colName.Name.String()
 	if qv.isValidSearchAttributes(colNameStr) {
-		if !definition.IsSystemIndexedKey(colNameStr) { // add search attribute prefix
+		if !definition.IsSystemIndexedKey(colNameStr) || definition.IsSystemSearchAttributeKey(colNameStr) { // add search attribute prefix
 			rangeCond.Left = &sqlparser.ColName{
 				Metadata:  colName.Metadata,
 				Name:      sqlparser.NewColIdent(definition.Attr + "." + colNameStr),
@@ -177,7 +177,7 @@ func (qv *VisibilityQueryValidator) validateOrderByExpr(orderBy sqlparser.OrderB
 		}
 		colNameStr := colName.Name.String()
 		if qv.isValidSearchAttributes(colNameStr) {
-			if !definition.IsSystemIndexedKey(colNameStr) { // add search attribute prefix
+			if !definition.IsSystemIndexedKey(colNameStr) || definition.IsSystemSearchAttributeKey(colNameStr) { // add search attribute prefix
 				orderByExpr.Expr = &sqlparser.ColName{
 					Metadata:  colName.Metadata,
 					Name:      sqlparser.NewColIdent(definition.Attr + "." + colNameStr),
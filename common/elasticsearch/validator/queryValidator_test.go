@@ -56,6 +56,11 @@ func TestValidateQuery(t *testing.T) {
 			query:     "WorkflowID = 'wid' and ((CustomStringField = 'custom') or CustomIntField between 1 and 10)",
 			validated: "WorkflowID = 'wid' and ((`Attr.CustomStringField` = 'custom') or `Attr.CustomIntField` between 1 and 10)",
 		},
+		{
+			msg:       "system search attribute",
+			query:     "WorkflowID = 'wid' and IsPaused = true",
+			validated: "WorkflowID = 'wid' and `Attr.IsPaused` = true",
+		},
 		{
 			msg:   "invalid SQL",
 			query: "Invalid SQL",
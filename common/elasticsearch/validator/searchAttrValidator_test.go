@@ -104,6 +104,13 @@ func (s *searchAttributesValidatorSuite) TestValidateSearchAttributes() {
 	err = validator.ValidateSearchAttributes(attr, domain)
 	s.Equal(`BadRequestError{Message: StartTime is read-only Cadence reservered attribute}`, err.Error())
 
+	fields = map[string][]byte{
+		"IsPaused": []byte(`true`),
+	}
+	attr.IndexedFields = fields
+	err = validator.ValidateSearchAttributes(attr, domain)
+	s.Equal(`BadRequestError{Message: IsPaused is read-only Cadence reservered attribute}`, err.Error())
+
 	fields = map[string][]byte{
 		"CustomKeywordField": []byte(`"123456"`),
 	}
@@ -49,6 +49,11 @@ func Timestamp(timestamp time.Time) Tag {
 	return newTimeTag("timestamp", timestamp)
 }
 
+// Actor returns tag for the identity of the caller
+func Actor(actor string) Tag {
+	return newStringTag("actor", actor)
+}
+
 ///////////////////  Workflow tags defined here: ( wf is short for workflow) ///////////////////
 
 // WorkflowAction returns tag for WorkflowAction
@@ -138,6 +143,11 @@ func WorkflowEndingRunID(endingRunID string) Tag {
 	return newStringTag("wf-ending-run-id", endingRunID)
 }
 
+// WorkflowPauseReason returns tag for WorkflowPauseReason
+func WorkflowPauseReason(reason string) Tag {
+	return newStringTag("wf-pause-reason", reason)
+}
+
 // WorkflowDecisionTimeoutSeconds returns tag for WorkflowDecisionTimeoutSeconds
 func WorkflowDecisionTimeoutSeconds(s int32) Tag {
 	return newInt32("wf-decision-timeout", s)
@@ -398,6 +408,16 @@ func StoreType(storeType string) Tag {
 	return newPredefinedStringTag("store-type", storeType)
 }
 
+// StoreTable returns tag for StoreTable
+func StoreTable(table string) Tag {
+	return newStringTag("store-table", table)
+}
+
+// StorePartitionBucket returns tag for StorePartitionBucket
+func StorePartitionBucket(bucket int64) Tag {
+	return newInt64("store-partition-bucket", bucket)
+}
+
 // StoreError returns tag for StoreError
 func StoreError(storeErr error) Tag {
 	return newErrorTag("store-error", storeErr)
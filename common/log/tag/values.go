@@ -122,6 +122,7 @@ var (
 	ComponentESVisibilityManager        = component("es-visibility-manager")
 	ComponentArchiver                   = component("archiver")
 	ComponentBatcher                    = component("batcher")
+	ComponentScheduler                  = component("scheduler")
 	ComponentWorker                     = component("worker")
 	ComponentServiceResolver            = component("service-resolver")
 	ComponentFailoverCoordinator        = component("failover-coordinator")
@@ -131,6 +132,9 @@ var (
 	ComponentCrossClusterTaskFetcher    = component("cross-cluster-task-fetcher")
 	ComponentShardScanner               = component("shardscanner-scanner")
 	ComponentShardFixer                 = component("shardscanner-fixer")
+	ComponentPersistenceMigrator        = component("persistence-migrator")
+	ComponentDomainStorageTracker       = component("domain-storage-tracker")
+	ComponentPartitionRetention         = component("partition-retention")
 )
 
 // Pre-defined values for TagSysLifecycle
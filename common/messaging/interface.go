@@ -60,6 +60,13 @@ type (
 		Publish(ctx context.Context, message interface{}) error
 	}
 
+	// RawMessage is a message already serialized by the producer's caller
+	RawMessage struct {
+		// Key is the partition key of the message
+		Key   string
+		Value []byte
+	}
+
 	// CloseableProducer is a Producer that can be closed
 	CloseableProducer interface {
 		Producer
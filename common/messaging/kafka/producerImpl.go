@@ -111,6 +111,13 @@ func (p *producerImpl) getProducerMessage(message interface{}) (*sarama.Producer
 			Value: sarama.ByteEncoder(message.Value),
 		}
 		return msg, nil
+	case *messaging.RawMessage:
+		msg := &sarama.ProducerMessage{
+			Topic: p.topic,
+			Key:   sarama.StringEncoder(message.Key),
+			Value: sarama.ByteEncoder(message.Value),
+		}
+		return msg, nil
 	default:
 		return nil, errors.New("unknown producer message type")
 	}
@@ -710,6 +710,8 @@ const (
 	DomainFailoverScope
 	// DomainReplicationQueueScope is used in domainreplication queue
 	DomainReplicationQueueScope
+	// PersistenceMigrationShadowScope is used by the persistence stores mirroring writes to the migration target store
+	PersistenceMigrationShadowScope
 
 	NumCommonScopes
 )
@@ -852,6 +854,10 @@ const (
 	FrontendResetWorkflowExecutionScope
 	// FrontendGetSearchAttributesScope is the metric scope for frontend.GetSearchAttributes
 	FrontendGetSearchAttributesScope
+	// FrontendAuditScope is the metric scope for the audit log of mutating API calls
+	FrontendAuditScope
+	// FrontendAuthorizationShadowScope is the metric scope for the requests allowed by the authorization shadow mode
+	FrontendAuthorizationShadowScope
 
 	NumFrontendScopes
 )
@@ -1114,6 +1120,8 @@ const (
 	HistoryReplicationV2TaskScope
 	// SyncActivityTaskScope is the scope used by sync activity information processing
 	SyncActivityTaskScope
+	// DomainStorageScope is the scope used by all metrics emitted related to domain storage tracking
+	DomainStorageScope
 
 	NumHistoryScopes
 )
@@ -1178,6 +1186,8 @@ const (
 	BatcherScope
 	// HistoryScavengerScope is scope used by all metrics emitted by worker.history.Scavenger module
 	HistoryScavengerScope
+	// PartitionRetentionScope is scope used by all metrics emitted by worker.partition.Manager module
+	PartitionRetentionScope
 	// ParentClosePolicyProcessorScope is scope used by all metrics emitted by worker.ParentClosePolicyProcessor
 	ParentClosePolicyProcessorScope
 	// ShardScannerScope is scope used by all metrics emitted by worker.shardscanner module
@@ -1485,6 +1495,8 @@ var ScopeDefs = map[ServiceIdx]map[int]scopeDefinition{
 
 		DomainFailoverScope:         {operation: "DomainFailover"},
 		DomainReplicationQueueScope: {operation: "DomainReplicationQueue"},
+
+		PersistenceMigrationShadowScope: {operation: "PersistenceMigrationShadow"},
 	},
 	// Frontend Scope Names
 	Frontend: {
@@ -1555,6 +1567,8 @@ var ScopeDefs = map[ServiceIdx]map[int]scopeDefinition{
 		FrontendDescribeTaskListScope:                   {operation: "DescribeTaskList"},
 		FrontendResetStickyTaskListScope:                {operation: "ResetStickyTaskList"},
 		FrontendGetSearchAttributesScope:                {operation: "GetSearchAttributes"},
+		FrontendAuditScope:                              {operation: "Audit"},
+		FrontendAuthorizationShadowScope:                {operation: "AuthorizationShadow"},
 	},
 	// History Scope Names
 	History: {
@@ -1686,6 +1700,7 @@ var ScopeDefs = map[ServiceIdx]map[int]scopeDefinition{
 		FailoverMarkerScope:                                             {operation: "FailoverMarker"},
 		HistoryReplicationV2TaskScope:                                   {operation: "HistoryReplicationV2Task"},
 		SyncActivityTaskScope:                                           {operation: "SyncActivityTask"},
+		DomainStorageScope:                                              {operation: "DomainStorage"},
 	},
 	// Matching Scope Names
 	Matching: {
@@ -1719,6 +1734,7 @@ var ScopeDefs = map[ServiceIdx]map[int]scopeDefinition{
 		CheckDataCorruptionWorkflowScope:       {operation: "CheckDataCorruptionWorkflow"},
 		ExecutionsFixerScope:                   {operation: "ExecutionsFixer"},
 		HistoryScavengerScope:                  {operation: "historyscavenger"},
+		PartitionRetentionScope:                {operation: "partitionretention"},
 		BatcherScope:                           {operation: "batcher"},
 		ParentClosePolicyProcessorScope:        {operation: "ParentClosePolicyProcessor"},
 		ESAnalyzerScope:                        {operation: "ESAnalyzer"},
@@ -1749,6 +1765,7 @@ const (
 	CadenceErrNonDeterministicCounter
 	CadenceErrUnauthorizedCounter
 	CadenceErrAuthorizeFailedCounter
+	CadenceErrAuditFailedCounter
 	CadenceErrRemoteSyncMatchFailedCounter
 	CadenceErrDomainNameExceededWarnLimit
 	CadenceErrIdentityExceededWarnLimit
@@ -1785,6 +1802,7 @@ const (
 	CadenceDcRedirectionClientLatency
 
 	CadenceAuthorizationLatency
+	CadenceAuthorizationShadowDeniedCounter
 
 	DomainCachePrepareCallbacksLatency
 	DomainCacheCallbacksLatency
@@ -1885,6 +1903,8 @@ const (
 	ParentClosePolicyProcessorSuccess
 	ParentClosePolicyProcessorFailures
 
+	PersistenceShadowWriteFailures
+
 	NumCommonMetrics // Needs to be last on this list for iota numbering
 )
 
@@ -2124,6 +2144,10 @@ const (
 	FailoverMarkerUpdateShardFailure
 	FailoverMarkerCallbackCount
 	HistoryFailoverCallbackCount
+	DomainStorageUsageGauge
+	DomainStorageScanFailure
+	DomainStorageQuotaExceededCounter
+	DataResidencyViolationCounter
 
 	NumHistoryMetrics
 )
@@ -2218,6 +2242,10 @@ const (
 	HistoryScavengerSuccessCount
 	HistoryScavengerErrorCount
 	HistoryScavengerSkipCount
+	PartitionRetentionCreatedCount
+	PartitionRetentionDroppedCount
+	PartitionRetentionSkipCount
+	PartitionRetentionErrorCount
 	DomainReplicationEnqueueDLQCount
 	ScannerExecutionsGauge
 	ScannerCorruptedGauge
@@ -2269,6 +2297,7 @@ var MetricDefs = map[ServiceIdx]map[int]metricDefinition{
 		CadenceErrNonDeterministicCounter:                   {metricName: "cadence_errors_nondeterministic", metricType: Counter},
 		CadenceErrUnauthorizedCounter:                       {metricName: "cadence_errors_unauthorized", metricType: Counter},
 		CadenceErrAuthorizeFailedCounter:                    {metricName: "cadence_errors_authorize_failed", metricType: Counter},
+		CadenceErrAuditFailedCounter:                        {metricName: "cadence_errors_audit_failed", metricType: Counter},
 		CadenceErrRemoteSyncMatchFailedCounter:              {metricName: "cadence_errors_remote_syncmatch_failed", metricType: Counter},
 		CadenceErrDomainNameExceededWarnLimit:               {metricName: "cadence_errors_domain_name_exceeded_warn_limit", metricType: Counter},
 		CadenceErrIdentityExceededWarnLimit:                 {metricName: "cadence_errors_identity_exceeded_warn_limit", metricType: Counter},
@@ -2302,6 +2331,7 @@ var MetricDefs = map[ServiceIdx]map[int]metricDefinition{
 		CadenceDcRedirectionClientFailures:                  {metricName: "cadence_client_errors_redirection", metricType: Counter},
 		CadenceDcRedirectionClientLatency:                   {metricName: "cadence_client_latency_redirection", metricType: Timer},
 		CadenceAuthorizationLatency:                         {metricName: "cadence_authorization_latency", metricType: Timer},
+		CadenceAuthorizationShadowDeniedCounter:             {metricName: "cadence_authorization_shadow_denied", metricType: Counter},
 		DomainCachePrepareCallbacksLatency:                  {metricName: "domain_cache_prepare_callbacks_latency", metricType: Timer},
 		DomainCacheCallbacksLatency:                         {metricName: "domain_cache_callbacks_latency", metricType: Timer},
 		DomainCacheCallbacksCount:                           {metricName: "domain_cache_callbacks_count", metricType: Counter},
@@ -2426,6 +2456,7 @@ var MetricDefs = map[ServiceIdx]map[int]metricDefinition{
 		DomainReplicationQueueSizeErrorCount: {metricName: "domain_replication_queue_failed", metricType: Counter},
 		ParentClosePolicyProcessorSuccess:    {metricName: "parent_close_policy_processor_requests", metricType: Counter},
 		ParentClosePolicyProcessorFailures:   {metricName: "parent_close_policy_processor_errors", metricType: Counter},
+		PersistenceShadowWriteFailures:       {metricName: "persistence_shadow_write_failures", metricType: Counter},
 	},
 	History: {
 		TaskRequests:             {metricName: "task_requests", metricType: Counter},
@@ -2654,6 +2685,10 @@ var MetricDefs = map[ServiceIdx]map[int]metricDefinition{
 		FailoverMarkerUpdateShardFailure:                  {metricName: "failover_marker_update_shard_failures", metricType: Counter},
 		FailoverMarkerCallbackCount:                       {metricName: "failover_marker_callback_count", metricType: Counter},
 		HistoryFailoverCallbackCount:                      {metricName: "failover_callback_handler_count", metricType: Counter},
+		DomainStorageUsageGauge:                           {metricName: "domain_storage_bytes", metricType: Gauge},
+		DomainStorageScanFailure:                          {metricName: "domain_storage_scan_failures", metricType: Counter},
+		DomainStorageQuotaExceededCounter:                 {metricName: "domain_storage_quota_exceeded", metricType: Counter},
+		DataResidencyViolationCounter:                     {metricName: "data_residency_violation", metricType: Counter},
 		TransferTasksCount:                                {metricName: "transfer_tasks_count", metricType: Timer},
 		TimerTasksCount:                                   {metricName: "timer_tasks_count", metricType: Timer},
 		CrossClusterTasksCount:                            {metricName: "cross_cluster_tasks_count", metricType: Timer},
@@ -2744,6 +2779,10 @@ var MetricDefs = map[ServiceIdx]map[int]metricDefinition{
 		HistoryScavengerSuccessCount:                  {metricName: "scavenger_success", metricType: Counter},
 		HistoryScavengerErrorCount:                    {metricName: "scavenger_errors", metricType: Counter},
 		HistoryScavengerSkipCount:                     {metricName: "scavenger_skips", metricType: Counter},
+		PartitionRetentionCreatedCount:                {metricName: "partition_retention_created", metricType: Counter},
+		PartitionRetentionDroppedCount:                {metricName: "partition_retention_dropped", metricType: Counter},
+		PartitionRetentionSkipCount:                   {metricName: "partition_retention_skips", metricType: Counter},
+		PartitionRetentionErrorCount:                  {metricName: "partition_retention_errors", metricType: Counter},
 		DomainReplicationEnqueueDLQCount:              {metricName: "domain_replication_dlq_enqueue_requests", metricType: Counter},
 		ScannerExecutionsGauge:                        {metricName: "scanner_executions", metricType: Gauge},
 		ScannerCorruptedGauge:                         {metricName: "scanner_corrupted", metricType: Gauge},
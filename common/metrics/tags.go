@@ -47,6 +47,7 @@ const (
 	transport              = "transport"
 	caller                 = "caller"
 	signalName             = "signalName"
+	apiName                = "apiName"
 
 	allValue     = "all"
 	unknownValue = "_unknown_"
@@ -87,6 +88,11 @@ func DomainUnknownTag() Tag {
 	return DomainTag("")
 }
 
+// APINameTag returns a new API name tag.
+func APINameTag(value string) Tag {
+	return metricWithUnknown(apiName, value)
+}
+
 // InstanceTag returns a new instance tag
 func InstanceTag(value string) Tag {
 	return simpleMetric{key: instance, value: value}
@@ -33,6 +33,7 @@ import (
 	"github.com/uber/cadence/common/metrics"
 	p "github.com/uber/cadence/common/persistence"
 	"github.com/uber/cadence/common/persistence/elasticsearch"
+	"github.com/uber/cadence/common/persistence/encryption"
 	"github.com/uber/cadence/common/persistence/nosql"
 	"github.com/uber/cadence/common/persistence/serialization"
 	"github.com/uber/cadence/common/persistence/sql"
@@ -61,6 +62,8 @@ type (
 		NewVisibilityManager(params *Params, serviceConfig *service.Config) (p.VisibilityManager, error)
 		// NewDomainReplicationQueueManager returns a new queue for domain replication
 		NewDomainReplicationQueueManager() (p.QueueManager, error)
+		// NewAuditQueueManager returns a new queue for audit events
+		NewAuditQueueManager() (p.QueueManager, error)
 		// NewConfigStoreManager returns a new config store manager
 		NewConfigStoreManager() (p.ConfigStoreManager, error)
 	}
@@ -82,7 +85,8 @@ type (
 		// NewVisibilityStore returns a new visibility store,
 		// TODO We temporarily using sortByCloseTime to determine whether or not ListClosedWorkflowExecutions should
 		// be ordering by CloseTime. This will be removed when implementing https://github.com/uber/cadence/issues/3621
-		NewVisibilityStore(sortByCloseTime bool) (p.VisibilityStore, error)
+		// validSearchAttributes declares the types of the custom search attributes used in visibility queries
+		NewVisibilityStore(sortByCloseTime bool, validSearchAttributes dynamicconfig.MapPropertyFn) (p.VisibilityStore, error)
 		NewQueue(queueType p.QueueType) (p.Queue, error)
 		// NewConfigStore returns a new config store
 		NewConfigStore() (p.ConfigStore, error)
@@ -92,6 +96,8 @@ type (
 	Datastore struct {
 		factory   DataStoreFactory
 		ratelimit quotas.Limiter
+		// shadow is the factory of the migration target store, it is nil if no migration is configured
+		shadow DataStoreFactory
 	}
 	factoryImpl struct {
 		sync.RWMutex
@@ -100,6 +106,8 @@ type (
 		logger        log.Logger
 		datastores    map[storeType]Datastore
 		clusterName   string
+		// encryptor encrypts workflow payloads at rest, it is nil if payload encryption is not configured
+		encryptor encryption.Encryptor
 	}
 
 	storeType int
@@ -159,6 +167,13 @@ func (f *factoryImpl) NewTaskManager() (p.TaskManager, error) {
 	if err != nil {
 		return nil, err
 	}
+	if ds.shadow != nil {
+		target, err := ds.shadow.NewTaskStore()
+		if err != nil {
+			return nil, err
+		}
+		store = p.NewTaskShadowStore(store, target, f.config.MigrationMode, f.metricsClient, f.logger)
+	}
 	result := p.NewTaskManager(store)
 	if errorRate := f.config.ErrorInjectionRate(); errorRate != 0 {
 		result = p.NewTaskPersistenceErrorInjectionClient(result, errorRate, f.logger)
@@ -179,6 +194,13 @@ func (f *factoryImpl) NewShardManager() (p.ShardManager, error) {
 	if err != nil {
 		return nil, err
 	}
+	if ds.shadow != nil {
+		target, err := ds.shadow.NewShardStore()
+		if err != nil {
+			return nil, err
+		}
+		store = p.NewShardShadowStore(store, target, f.config.MigrationMode, f.metricsClient, f.logger)
+	}
 	result := p.NewShardManager(store)
 	if errorRate := f.config.ErrorInjectionRate(); errorRate != 0 {
 		result = p.NewShardPersistenceErrorInjectionClient(result, errorRate, f.logger)
@@ -199,6 +221,16 @@ func (f *factoryImpl) NewHistoryManager() (p.HistoryManager, error) {
 	if err != nil {
 		return nil, err
 	}
+	if ds.shadow != nil {
+		target, err := ds.shadow.NewHistoryStore()
+		if err != nil {
+			return nil, err
+		}
+		store = p.NewHistoryShadowStore(store, target, f.config.MigrationMode, f.metricsClient, f.logger)
+	}
+	if f.encryptor != nil {
+		store = p.NewHistoryEncryptionStore(store, f.encryptor)
+	}
 	result := p.NewHistoryV2ManagerImpl(store, f.logger, f.config.TransactionSizeLimit)
 	if errorRate := f.config.ErrorInjectionRate(); errorRate != 0 {
 		result = p.NewHistoryPersistenceErrorInjectionClient(result, errorRate, f.logger)
@@ -221,6 +253,13 @@ func (f *factoryImpl) NewDomainManager() (p.DomainManager, error) {
 	if err != nil {
 		return nil, err
 	}
+	if ds.shadow != nil {
+		target, err := ds.shadow.NewDomainStore()
+		if err != nil {
+			return nil, err
+		}
+		store = p.NewDomainShadowStore(store, target, f.config.MigrationMode, f.metricsClient, f.logger)
+	}
 	result := p.NewDomainManagerImpl(store, f.logger)
 	if errorRate := f.config.ErrorInjectionRate(); errorRate != 0 {
 		result = p.NewDomainPersistenceErrorInjectionClient(result, errorRate, f.logger)
@@ -241,6 +280,16 @@ func (f *factoryImpl) NewExecutionManager(shardID int) (p.ExecutionManager, erro
 	if err != nil {
 		return nil, err
 	}
+	if ds.shadow != nil {
+		target, err := ds.shadow.NewExecutionStore(shardID)
+		if err != nil {
+			return nil, err
+		}
+		store = p.NewExecutionShadowStore(store, target, f.config.MigrationMode, f.metricsClient, f.logger)
+	}
+	if f.encryptor != nil {
+		store = p.NewExecutionEncryptionStore(store, f.encryptor)
+	}
 	result := p.NewExecutionManagerImpl(store, f.logger)
 	if errorRate := f.config.ErrorInjectionRate(); errorRate != 0 {
 		result = p.NewWorkflowExecutionPersistenceErrorInjectionClient(result, errorRate, f.logger)
@@ -278,7 +327,7 @@ func (f *factoryImpl) NewVisibilityManager(
 			f.logger.Fatal("Creating visibility producer failed", tag.Error(err))
 		}
 		visibilityFromES = newESVisibilityManager(
-			visibilityIndexName, params.ESClient, resourceConfig, visibilityProducer, params.MetricsClient, f.logger,
+			visibilityIndexName, params.ESClient, resourceConfig, visibilityProducer, f.encryptor, params.MetricsClient, f.logger,
 		)
 	}
 	return p.NewVisibilityDualManager(
@@ -298,11 +347,15 @@ func newESVisibilityManager(
 	esClient es.GenericClient,
 	visibilityConfig *service.Config,
 	producer messaging.Producer,
+	encryptor encryption.Encryptor,
 	metricsClient metrics.Client,
 	log log.Logger,
 ) p.VisibilityManager {
 
 	visibilityFromESStore := elasticsearch.NewElasticSearchVisibilityStore(esClient, indexName, producer, visibilityConfig, log)
+	if encryptor != nil {
+		visibilityFromESStore = p.NewVisibilityEncryptionStore(visibilityFromESStore, encryptor)
+	}
 	visibilityFromES := p.NewVisibilityManagerImpl(visibilityFromESStore, log)
 
 	// wrap with rate limiter
@@ -333,10 +386,13 @@ func (f *factoryImpl) newDBVisibilityManager(
 	}
 
 	ds := f.datastores[storeTypeVisibility]
-	store, err := ds.factory.NewVisibilityStore(enableReadFromClosedExecutionV2)
+	store, err := ds.factory.NewVisibilityStore(enableReadFromClosedExecutionV2, visibilityConfig.ValidSearchAttributes)
 	if err != nil {
 		return nil, err
 	}
+	if f.encryptor != nil {
+		store = p.NewVisibilityEncryptionStore(store, f.encryptor)
+	}
 	result := p.NewVisibilityManagerImpl(store, f.logger)
 	if errorRate := f.config.ErrorInjectionRate(); errorRate != 0 {
 		result = p.NewVisibilityPersistenceErrorInjectionClient(result, errorRate, f.logger)
@@ -359,8 +415,16 @@ func (f *factoryImpl) newDBVisibilityManager(
 }
 
 func (f *factoryImpl) NewDomainReplicationQueueManager() (p.QueueManager, error) {
+	return f.newQueueManager(p.DomainReplicationQueueType)
+}
+
+func (f *factoryImpl) NewAuditQueueManager() (p.QueueManager, error) {
+	return f.newQueueManager(p.AuditQueueType)
+}
+
+func (f *factoryImpl) newQueueManager(queueType p.QueueType) (p.QueueManager, error) {
 	ds := f.datastores[storeTypeQueue]
-	store, err := ds.factory.NewQueue(p.DomainReplicationQueueType)
+	store, err := ds.factory.NewQueue(queueType)
 	if err != nil {
 		return nil, err
 	}
@@ -402,6 +466,9 @@ func (f *factoryImpl) NewConfigStoreManager() (p.ConfigStoreManager, error) {
 func (f *factoryImpl) Close() {
 	ds := f.datastores[storeTypeExecution]
 	ds.factory.Close()
+	if ds.shadow != nil {
+		ds.shadow.Close()
+	}
 }
 
 func (f *factoryImpl) init(clusterName string, limiters map[string]quotas.Limiter) {
@@ -410,32 +477,28 @@ func (f *factoryImpl) init(clusterName string, limiters map[string]quotas.Limite
 	if defaultCfg.Cassandra != nil {
 		f.logger.Warn("Cassandra config is deprecated, please use NoSQL with pluginName of cassandra.")
 	}
-	defaultDataStore := Datastore{ratelimit: limiters[f.config.DefaultStore]}
-	switch {
-	case defaultCfg.NoSQL != nil:
-		defaultDataStore.factory = nosql.NewFactory(*defaultCfg.NoSQL, clusterName, f.logger)
-	case defaultCfg.SQL != nil:
-		if defaultCfg.SQL.EncodingType == "" {
-			defaultCfg.SQL.EncodingType = string(common.EncodingTypeThriftRW)
-		}
-		if len(defaultCfg.SQL.DecodingTypes) == 0 {
-			defaultCfg.SQL.DecodingTypes = []string{
-				string(common.EncodingTypeThriftRW),
-			}
-		}
-		var decodingTypes []common.EncodingType
-		for _, dt := range defaultCfg.SQL.DecodingTypes {
-			decodingTypes = append(decodingTypes, common.EncodingType(dt))
+	if f.config.PayloadEncryption != nil {
+		keyProvider, err := encryption.NewFileKeyProvider(f.config.PayloadEncryption.KeyFile)
+		if err != nil {
+			f.logger.Fatal("failed to load payload encryption keys", tag.Error(err))
 		}
-		defaultDataStore.factory = sql.NewFactory(
-			*defaultCfg.SQL,
-			clusterName,
-			f.logger,
-			getSQLParser(f.logger, common.EncodingType(defaultCfg.SQL.EncodingType), decodingTypes...))
-	default:
+		f.encryptor = encryption.NewEnvelopeEncryptor(keyProvider)
+	}
+
+	defaultDataStore := Datastore{ratelimit: limiters[f.config.DefaultStore]}
+	defaultDataStore.factory = newDataStoreFactory(defaultCfg, clusterName, f.logger)
+	if defaultDataStore.factory == nil {
 		f.logger.Fatal("invalid config: one of nosql or sql params must be specified for defaultDataStore")
 	}
 
+	if f.config.MigrationTargetStore != "" {
+		migrationTargetCfg := f.config.DataStores[f.config.MigrationTargetStore]
+		defaultDataStore.shadow = newDataStoreFactory(migrationTargetCfg, clusterName, f.logger)
+		if defaultDataStore.shadow == nil {
+			f.logger.Fatal("invalid config: one of nosql or sql params must be specified for migrationTargetStore")
+		}
+	}
+
 	for _, st := range storeTypes {
 		if st != storeTypeVisibility {
 			f.datastores[st] = defaultDataStore
@@ -453,24 +516,40 @@ func (f *factoryImpl) init(clusterName string, limiters map[string]quotas.Limite
 		f.logger.Warn("Cassandra config is deprecated, please use NoSQL with pluginName of cassandra.")
 	}
 	visibilityDataStore := Datastore{ratelimit: limiters[f.config.VisibilityStore]}
+	visibilityDataStore.factory = newDataStoreFactory(visibilityCfg, clusterName, f.logger)
+	if visibilityDataStore.factory == nil {
+		f.logger.Fatal("invalid config: one of nosql or sql params must be specified for visibilityStore")
+	}
+
+	f.datastores[storeTypeVisibility] = visibilityDataStore
+}
+
+// newDataStoreFactory returns the factory of a single datastore, or nil if neither nosql nor sql is configured
+func newDataStoreFactory(cfg config.DataStore, clusterName string, logger log.Logger) DataStoreFactory {
 	switch {
-	case visibilityCfg.NoSQL != nil:
-		visibilityDataStore.factory = nosql.NewFactory(*visibilityCfg.NoSQL, clusterName, f.logger)
-	case visibilityCfg.SQL != nil:
+	case cfg.NoSQL != nil:
+		return nosql.NewFactory(*cfg.NoSQL, clusterName, logger)
+	case cfg.SQL != nil:
+		if cfg.SQL.EncodingType == "" {
+			cfg.SQL.EncodingType = string(common.EncodingTypeThriftRW)
+		}
+		if len(cfg.SQL.DecodingTypes) == 0 {
+			cfg.SQL.DecodingTypes = []string{
+				string(common.EncodingTypeThriftRW),
+			}
+		}
 		var decodingTypes []common.EncodingType
-		for _, dt := range visibilityCfg.SQL.DecodingTypes {
+		for _, dt := range cfg.SQL.DecodingTypes {
 			decodingTypes = append(decodingTypes, common.EncodingType(dt))
 		}
-		visibilityDataStore.factory = sql.NewFactory(
-			*visibilityCfg.SQL,
+		return sql.NewFactory(
+			*cfg.SQL,
 			clusterName,
-			f.logger,
-			getSQLParser(f.logger, common.EncodingType(visibilityCfg.SQL.EncodingType), decodingTypes...))
+			logger,
+			getSQLParser(logger, common.EncodingType(cfg.SQL.EncodingType), decodingTypes...))
 	default:
-		f.logger.Fatal("invalid config: one of nosql or sql params must be specified for visibilityStore")
+		return nil
 	}
-
-	f.datastores[storeTypeVisibility] = visibilityDataStore
 }
 
 func getSQLParser(logger log.Logger, encodingType common.EncodingType, decodingTypes ...common.EncodingType) serialization.Parser {
@@ -64,6 +64,7 @@ type QueueType int
 // Negative numbers are reserved for DLQ
 const (
 	DomainReplicationQueueType QueueType = iota + 1
+	AuditQueueType
 )
 
 // Create Workflow Execution Mode
@@ -920,6 +921,10 @@ type (
 	ListConcreteExecutionsEntity struct {
 		ExecutionInfo    *WorkflowExecutionInfo
 		VersionHistories *VersionHistories
+		ExecutionStats   *ExecutionStats
+		// RecordSize is the size of the execution record, it does not include the size
+		// of the pending activities, timers, child executions and signals
+		RecordSize int
 	}
 
 	// GetCurrentExecutionResponse is the response to GetCurrentExecution
@@ -1511,6 +1516,8 @@ type (
 		Encoding common.EncodingType
 		// The shard to get history node data
 		ShardID *int
+		// optional: the domain of the workflow, used to encrypt the events with the key of the domain
+		DomainID string
 	}
 
 	// AppendHistoryNodesResponse is a response to AppendHistoryNodesRequest
@@ -2878,7 +2885,7 @@ var internalThriftEncoder = codec.NewThriftRWEncoder()
 
 // NewHistoryBranchToken return a new branch token
 func NewHistoryBranchToken(treeID string) ([]byte, error) {
-	branchID := uuid.New()
+	branchID := NewHistoryBranchID()
 	bi := &workflow.HistoryBranch{
 		TreeID:    &treeID,
 		BranchID:  &branchID,
@@ -2891,6 +2898,15 @@ func NewHistoryBranchToken(treeID string) ([]byte, error) {
 	return token, nil
 }
 
+// NewHistoryBranchID returns a new time based UUID for a history branch, the SQL partitioned schema
+// relies on the creation time encoded in it to place the history nodes of the branch
+func NewHistoryBranchID() string {
+	if branchID := uuid.NewUUID(); branchID != nil {
+		return branchID.String()
+	}
+	return uuid.New()
+}
+
 // NewHistoryBranchTokenByBranchID return a new branch token with treeID/branchID
 func NewHistoryBranchTokenByBranchID(treeID, branchID string) ([]byte, error) {
 	bi := &workflow.HistoryBranch{
@@ -514,6 +514,8 @@ type (
 		TransactionID int64
 		// Used in sharded data stores to identify which shard to use
 		ShardID int
+		// The domain of the workflow, empty if unknown
+		DomainID string
 	}
 
 	// InternalGetWorkflowExecutionRequest is used to retrieve the info of a workflow execution
@@ -890,7 +892,7 @@ func NewDataBlob(data []byte, encodingType common.EncodingType) *DataBlob {
 	if data == nil || len(data) == 0 {
 		return nil
 	}
-	if encodingType != "thriftrw" && data[0] == 'Y' {
+	if encodingType != "thriftrw" && !IsCompressedEncodingType(encodingType) && data[0] == 'Y' {
 		panic(fmt.Sprintf("Invalid incoding: \"%v\"", encodingType))
 	}
 	return &DataBlob{
@@ -930,6 +932,10 @@ func (d *DataBlob) GetEncoding() common.EncodingType {
 		return common.EncodingTypeJSON
 	case common.EncodingTypeThriftRW:
 		return common.EncodingTypeThriftRW
+	case common.EncodingTypeThriftRWSnappy:
+		return common.EncodingTypeThriftRWSnappy
+	case common.EncodingTypeThriftRWZstd:
+		return common.EncodingTypeThriftRWZstd
 	case common.EncodingTypeEmpty:
 		return common.EncodingTypeEmpty
 	default:
@@ -0,0 +1,190 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package encryption
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// The envelope of an encrypted payload is laid out as
+//
+//	magic | version | len(keyID) | keyID | len(wrappedDataKey) | wrappedDataKey | nonce | ciphertext
+//
+// Every payload is encrypted with a random data key, which is in turn encrypted (wrapped) with the key
+// encryption key identified by keyID. The header and the additional data given by the caller, which is not
+// part of the envelope, are authenticated together with the ciphertext.
+const (
+	envelopeVersion = 1
+	dataKeySize     = 32
+	maxKeyIDLength  = 255
+)
+
+var envelopeMagic = []byte{0xCA, 0xDE, 0xE5, 0xC1}
+
+var (
+	errInvalidEnvelope = errors.New("invalid encrypted payload envelope")
+)
+
+type envelopeEncryptor struct {
+	keyProvider KeyProvider
+}
+
+var _ Encryptor = (*envelopeEncryptor)(nil)
+
+// NewEnvelopeEncryptor returns an Encryptor doing envelope encryption with AES-GCM
+func NewEnvelopeEncryptor(keyProvider KeyProvider) Encryptor {
+	return &envelopeEncryptor{
+		keyProvider: keyProvider,
+	}
+}
+
+// IsEncrypted returns true if the payload has been encrypted by an envelope Encryptor
+func IsEncrypted(data []byte) bool {
+	return len(data) > len(envelopeMagic) &&
+		bytes.Equal(data[:len(envelopeMagic)], envelopeMagic) &&
+		data[len(envelopeMagic)] == envelopeVersion
+}
+
+func (e *envelopeEncryptor) Encrypt(domainID string, plaintext []byte, additionalData []byte) ([]byte, error) {
+	if len(plaintext) == 0 {
+		return plaintext, nil
+	}
+	keyID, err := e.keyProvider.GetActiveKeyID(domainID)
+	if err != nil {
+		return nil, err
+	}
+	if len(keyID) == 0 || len(keyID) > maxKeyIDLength {
+		return nil, fmt.Errorf("invalid encryption key ID %q", keyID)
+	}
+	key, err := e.keyProvider.GetKey(keyID)
+	if err != nil {
+		return nil, err
+	}
+
+	dataKey := make([]byte, dataKeySize)
+	if _, err := io.ReadFull(rand.Reader, dataKey); err != nil {
+		return nil, err
+	}
+	wrappedDataKey, err := seal(key, dataKey, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	header := make([]byte, 0, len(envelopeMagic)+3+len(keyID)+len(wrappedDataKey))
+	header = append(header, envelopeMagic...)
+	header = append(header, envelopeVersion, byte(len(keyID)))
+	header = append(header, keyID...)
+	header = append(header, byte(len(wrappedDataKey)))
+	header = append(header, wrappedDataKey...)
+
+	ciphertext, err := seal(dataKey, plaintext, authenticatedData(header, additionalData))
+	if err != nil {
+		return nil, err
+	}
+	return append(header, ciphertext...), nil
+}
+
+func (e *envelopeEncryptor) Decrypt(data []byte, additionalData []byte) ([]byte, error) {
+	if !IsEncrypted(data) {
+		return data, nil
+	}
+
+	offset := len(envelopeMagic) + 1
+	keyID, offset, err := readField(data, offset)
+	if err != nil {
+		return nil, err
+	}
+	wrappedDataKey, offset, err := readField(data, offset)
+	if err != nil {
+		return nil, err
+	}
+	key, err := e.keyProvider.GetKey(string(keyID))
+	if err != nil {
+		return nil, err
+	}
+	dataKey, err := open(key, wrappedDataKey, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt data key with key %q: %v", keyID, err)
+	}
+	plaintext, err := open(dataKey, data[offset:], authenticatedData(data[:offset], additionalData))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt payload: %v", err)
+	}
+	return plaintext, nil
+}
+
+func authenticatedData(header []byte, additionalData []byte) []byte {
+	result := make([]byte, 0, len(header)+len(additionalData))
+	result = append(result, header...)
+	return append(result, additionalData...)
+}
+
+// readField reads a field prefixed by its one byte length
+func readField(data []byte, offset int) ([]byte, int, error) {
+	if offset >= len(data) {
+		return nil, 0, errInvalidEnvelope
+	}
+	length := int(data[offset])
+	offset++
+	if offset+length > len(data) {
+		return nil, 0, errInvalidEnvelope
+	}
+	return data[offset : offset+length], offset + length, nil
+}
+
+// seal encrypts the plaintext with AES-GCM and prepends the random nonce
+func seal(key []byte, plaintext []byte, additionalData []byte) ([]byte, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(plaintext)+aead.Overhead())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+	return aead.Seal(nonce, nonce, plaintext, additionalData), nil
+}
+
+func open(key []byte, ciphertext []byte, additionalData []byte) ([]byte, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(ciphertext) < aead.NonceSize() {
+		return nil, errInvalidEnvelope
+	}
+	nonce := ciphertext[:aead.NonceSize()]
+	return aead.Open(nil, nonce, ciphertext[aead.NonceSize():], additionalData)
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
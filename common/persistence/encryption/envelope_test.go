@@ -0,0 +1,178 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package encryption
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type (
+	envelopeSuite struct {
+		suite.Suite
+	}
+)
+
+func TestEnvelopeSuite(t *testing.T) {
+	suite.Run(t, new(envelopeSuite))
+}
+
+func (s *envelopeSuite) newKey() []byte {
+	key := make([]byte, 32)
+	_, err := rand.Read(key)
+	s.NoError(err)
+	return key
+}
+
+func (s *envelopeSuite) TestEncryptDecrypt() {
+	provider, err := NewStaticKeyProvider(map[string][]byte{"key-1": s.newKey()}, "key-1", nil)
+	s.NoError(err)
+	encryptor := NewEnvelopeEncryptor(provider)
+
+	plaintext := []byte("some workflow payload")
+	ciphertext, err := encryptor.Encrypt("domain-id", plaintext, []byte("domain-id"))
+	s.NoError(err)
+	s.True(IsEncrypted(ciphertext))
+	s.False(bytes.Contains(ciphertext, plaintext))
+
+	decrypted, err := encryptor.Decrypt(ciphertext, []byte("domain-id"))
+	s.NoError(err)
+	s.Equal(plaintext, decrypted)
+
+	other, err := encryptor.Encrypt("domain-id", plaintext, []byte("domain-id"))
+	s.NoError(err)
+	s.NotEqual(ciphertext, other)
+}
+
+func (s *envelopeSuite) TestEmptyAndUnencryptedPayloads() {
+	provider, err := NewStaticKeyProvider(map[string][]byte{"key-1": s.newKey()}, "key-1", nil)
+	s.NoError(err)
+	encryptor := NewEnvelopeEncryptor(provider)
+
+	ciphertext, err := encryptor.Encrypt("domain-id", nil, []byte("domain-id"))
+	s.NoError(err)
+	s.Nil(ciphertext)
+
+	plaintext := []byte("written before encryption was enabled")
+	s.False(IsEncrypted(plaintext))
+	decrypted, err := encryptor.Decrypt(plaintext, []byte("domain-id"))
+	s.NoError(err)
+	s.Equal(plaintext, decrypted)
+}
+
+func (s *envelopeSuite) TestKeyRotation() {
+	keys := map[string][]byte{"key-1": s.newKey(), "key-2": s.newKey()}
+	oldProvider, err := NewStaticKeyProvider(keys, "key-1", nil)
+	s.NoError(err)
+	newProvider, err := NewStaticKeyProvider(keys, "key-2", nil)
+	s.NoError(err)
+
+	plaintext := []byte("some workflow payload")
+	ciphertext, err := NewEnvelopeEncryptor(oldProvider).Encrypt("domain-id", plaintext, []byte("domain-id"))
+	s.NoError(err)
+
+	decrypted, err := NewEnvelopeEncryptor(newProvider).Decrypt(ciphertext, []byte("domain-id"))
+	s.NoError(err)
+	s.Equal(plaintext, decrypted)
+
+	removedProvider, err := NewStaticKeyProvider(map[string][]byte{"key-2": keys["key-2"]}, "key-2", nil)
+	s.NoError(err)
+	_, err = NewEnvelopeEncryptor(removedProvider).Decrypt(ciphertext, []byte("domain-id"))
+	s.Error(err)
+}
+
+func (s *envelopeSuite) TestDomainKey() {
+	keys := map[string][]byte{"key-1": s.newKey(), "key-2": s.newKey()}
+	provider, err := NewStaticKeyProvider(keys, "key-1", map[string]string{"domain-2": "key-2"})
+	s.NoError(err)
+
+	keyID, err := provider.GetActiveKeyID("domain-1")
+	s.NoError(err)
+	s.Equal("key-1", keyID)
+	keyID, err = provider.GetActiveKeyID("domain-2")
+	s.NoError(err)
+	s.Equal("key-2", keyID)
+
+	ciphertext, err := NewEnvelopeEncryptor(provider).Encrypt("domain-2", []byte("payload"), []byte("domain-2"))
+	s.NoError(err)
+	s.True(bytes.Contains(ciphertext, []byte("key-2")))
+}
+
+func (s *envelopeSuite) TestTamperedPayload() {
+	provider, err := NewStaticKeyProvider(map[string][]byte{"key-1": s.newKey()}, "key-1", nil)
+	s.NoError(err)
+	encryptor := NewEnvelopeEncryptor(provider)
+
+	ciphertext, err := encryptor.Encrypt("domain-id", []byte("some workflow payload"), []byte("domain-id"))
+	s.NoError(err)
+
+	tampered := append([]byte{}, ciphertext...)
+	tampered[len(tampered)-1] ^= 0xFF
+	_, err = encryptor.Decrypt(tampered, []byte("domain-id"))
+	s.Error(err)
+
+	_, err = encryptor.Decrypt(ciphertext[:len(envelopeMagic)+3], []byte("domain-id"))
+	s.Error(err)
+
+	// payloads are bound to the additional data they were encrypted with
+	_, err = encryptor.Decrypt(ciphertext, []byte("other-domain-id"))
+	s.Error(err)
+}
+
+func (s *envelopeSuite) TestInvalidStaticKeys() {
+	_, err := NewStaticKeyProvider(map[string][]byte{"key-1": []byte("short")}, "key-1", nil)
+	s.Error(err)
+	_, err = NewStaticKeyProvider(map[string][]byte{"key-1": s.newKey()}, "key-2", nil)
+	s.Error(err)
+	_, err = NewStaticKeyProvider(map[string][]byte{"key-1": s.newKey()}, "key-1", map[string]string{"domain-id": "key-2"})
+	s.Error(err)
+}
+
+func (s *envelopeSuite) TestFileKeyProvider() {
+	dir, err := ioutil.TempDir("", "encryption")
+	s.NoError(err)
+	defer os.RemoveAll(dir)
+
+	key := s.newKey()
+	path := filepath.Join(dir, "keys.yaml")
+	content := fmt.Sprintf("activeKeyID: key-1\nkeys:\n  key-1: %v\n", base64.StdEncoding.EncodeToString(key))
+	s.NoError(ioutil.WriteFile(path, []byte(content), 0600))
+
+	provider, err := NewFileKeyProvider(path)
+	s.NoError(err)
+	keyID, err := provider.GetActiveKeyID("domain-id")
+	s.NoError(err)
+	s.Equal("key-1", keyID)
+	actual, err := provider.GetKey("key-1")
+	s.NoError(err)
+	s.Equal(key, actual)
+
+	_, err = NewFileKeyProvider(filepath.Join(dir, "missing.yaml"))
+	s.Error(err)
+}
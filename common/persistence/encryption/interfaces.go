@@ -0,0 +1,43 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package encryption
+
+type (
+	// KeyProvider provides the key encryption keys used for encrypting payloads at rest.
+	// Keys are identified by an ID which is stored with every encrypted payload, so that keys can be
+	// rotated by changing the active key while the previous keys stay available for decryption.
+	KeyProvider interface {
+		// GetActiveKeyID returns the ID of the key used to encrypt new payloads of the domain
+		GetActiveKeyID(domainID string) (string, error)
+		// GetKey returns the key with the given ID
+		GetKey(keyID string) ([]byte, error)
+	}
+
+	// Encryptor encrypts payloads before they are written to the database and decrypts them on read
+	Encryptor interface {
+		// Encrypt encrypts the payload with the active key of the domain. The additional data is authenticated
+		// but not stored, it binds the payload to its owner (e.g. the domain ID) so that it cannot be moved.
+		Encrypt(domainID string, plaintext []byte, additionalData []byte) ([]byte, error)
+		// Decrypt decrypts a payload returned by Encrypt with the same additional data,
+		// payloads which are not encrypted are returned as is
+		Decrypt(data []byte, additionalData []byte) ([]byte, error)
+	}
+)
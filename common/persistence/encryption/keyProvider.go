@@ -0,0 +1,128 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package encryption
+
+import (
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+
+	"gopkg.in/yaml.v2"
+)
+
+type (
+	staticKeyProvider struct {
+		keys         map[string][]byte
+		activeKeyID  string
+		domainKeyIDs map[string]string
+	}
+
+	// keyFile is the content of the file read by the file based KeyProvider
+	keyFile struct {
+		// ActiveKeyID is the ID of the key used to encrypt new payloads
+		ActiveKeyID string `yaml:"activeKeyID"`
+		// Keys maps key IDs to base64 encoded keys of 16, 24 or 32 bytes
+		Keys map[string]string `yaml:"keys"`
+		// Domains maps domain IDs to the ID of the key used to encrypt their new payloads,
+		// overriding the active key
+		Domains map[string]string `yaml:"domains"`
+	}
+)
+
+var _ KeyProvider = (*staticKeyProvider)(nil)
+
+// NewStaticKeyProvider returns a KeyProvider serving a fixed set of keys.
+// domainKeyIDs optionally overrides the active key per domain ID.
+func NewStaticKeyProvider(
+	keys map[string][]byte,
+	activeKeyID string,
+	domainKeyIDs map[string]string,
+) (KeyProvider, error) {
+	for keyID, key := range keys {
+		if len(keyID) == 0 || len(keyID) > maxKeyIDLength {
+			return nil, fmt.Errorf("invalid encryption key ID %q", keyID)
+		}
+		switch len(key) {
+		case 16, 24, 32:
+		default:
+			return nil, fmt.Errorf("encryption key %q must be 16, 24 or 32 bytes long", keyID)
+		}
+	}
+	if _, ok := keys[activeKeyID]; !ok {
+		return nil, fmt.Errorf("active encryption key %q does not exist", activeKeyID)
+	}
+	for domainID, keyID := range domainKeyIDs {
+		if _, ok := keys[keyID]; !ok {
+			return nil, fmt.Errorf("encryption key %q of domain %v does not exist", keyID, domainID)
+		}
+	}
+	return &staticKeyProvider{
+		keys:         keys,
+		activeKeyID:  activeKeyID,
+		domainKeyIDs: domainKeyIDs,
+	}, nil
+}
+
+// NewFileKeyProvider returns a KeyProvider serving the keys of a YAML file, e.g.
+//
+//	activeKeyID: key-2
+//	keys:
+//	  key-1: <base64 encoded key>
+//	  key-2: <base64 encoded key>
+//	domains:
+//	  <domain ID>: key-1
+//
+// Keys are rotated by adding a new key, making it active and restarting the services.
+// Old keys must be kept for as long as payloads encrypted with them are stored.
+func NewFileKeyProvider(path string) (KeyProvider, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read encryption key file: %v", err)
+	}
+	var file keyFile
+	if err := yaml.Unmarshal(content, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse encryption key file: %v", err)
+	}
+	keys := make(map[string][]byte, len(file.Keys))
+	for keyID, encoded := range file.Keys {
+		key, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode encryption key %q: %v", keyID, err)
+		}
+		keys[keyID] = key
+	}
+	return NewStaticKeyProvider(keys, file.ActiveKeyID, file.Domains)
+}
+
+func (p *staticKeyProvider) GetActiveKeyID(domainID string) (string, error) {
+	if keyID, ok := p.domainKeyIDs[domainID]; ok {
+		return keyID, nil
+	}
+	return p.activeKeyID, nil
+}
+
+func (p *staticKeyProvider) GetKey(keyID string) ([]byte, error) {
+	key, ok := p.keys[keyID]
+	if !ok {
+		return nil, fmt.Errorf("encryption key %q does not exist", keyID)
+	}
+	return key, nil
+}
@@ -24,8 +24,6 @@ import (
 	"context"
 	"fmt"
 
-	"github.com/pborman/uuid"
-
 	workflow "github.com/uber/cadence/.gen/go/shared"
 	"github.com/uber/cadence/common"
 	"github.com/uber/cadence/common/codec"
@@ -103,7 +101,7 @@ func (m *historyV2ManagerImpl) ForkHistoryBranch(
 	req := &InternalForkHistoryBranchRequest{
 		ForkBranchInfo: *thrift.ToHistoryBranch(&forkBranch),
 		ForkNodeID:     request.ForkNodeID,
-		NewBranchID:    uuid.New(),
+		NewBranchID:    NewHistoryBranchID(),
 		Info:           request.Info,
 		ShardID:        shardID,
 	}
@@ -247,6 +245,7 @@ func (m *historyV2ManagerImpl) AppendHistoryNodes(
 		Events:        blob,
 		TransactionID: request.TransactionID,
 		ShardID:       shardID,
+		DomainID:      request.DomainID,
 	}
 
 	err = m.persistence.AppendHistoryNodes(ctx, req)
@@ -300,6 +299,12 @@ func (m *historyV2ManagerImpl) ReadRawHistoryBranch(
 	if err != nil {
 		return nil, err
 	}
+	// raw history is sent to remote clusters and clients, which do not understand compressed encoding types
+	for i, blob := range dataBlobs {
+		if dataBlobs[i], err = DecompressDataBlob(blob); err != nil {
+			return nil, err
+		}
+	}
 
 	nextPageToken, err := m.serializeToken(token)
 	if err != nil {
@@ -51,7 +51,15 @@ func newNoSQLQueueStore(
 	if err != nil {
 		return nil, err
 	}
+	return NewNoSQLQueueStoreFromSession(db, logger, queueType)
+}
 
+// NewNoSQLQueueStoreFromSession returns a queue store of the queue type using an existing session
+func NewNoSQLQueueStoreFromSession(
+	db nosqlplugin.DB,
+	logger log.Logger,
+	queueType persistence.QueueType,
+) (persistence.Queue, error) {
 	queue := &nosqlQueueStore{
 		nosqlStore: nosqlStore{
 			db:     db,
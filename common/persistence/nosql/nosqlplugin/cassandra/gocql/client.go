@@ -21,11 +21,12 @@
 package gocql
 
 import (
-	"crypto/tls"
 	"strings"
 
 	"github.com/gocql/gocql"
 
+	"github.com/uber/cadence/common/certificate"
+	"github.com/uber/cadence/common/log"
 	"github.com/uber/cadence/environment"
 )
 
@@ -50,7 +51,7 @@ func RegisterClient(c Client) {
 	}
 }
 
-func newCassandraCluster(cfg ClusterConfig) *gocql.ClusterConfig {
+func newCassandraCluster(cfg ClusterConfig) (*gocql.ClusterConfig, error) {
 	hosts := parseHosts(cfg.Hosts)
 	cluster := gocql.NewCluster(hosts...)
 	if cfg.ProtoVersion == 0 {
@@ -77,15 +78,15 @@ func newCassandraCluster(cfg ClusterConfig) *gocql.ClusterConfig {
 	}
 
 	if cfg.TLS != nil && cfg.TLS.Enabled {
+		// the files are reloaded by the TLS config for the new connections instead of being loaded once by gocql
+		tlsReloader, err := certificate.NewReloader(*cfg.TLS, log.NewNoop())
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig := tlsReloader.ClientTLSConfig(cfg.TLS.ServerName)
 		cluster.SslOpts = &gocql.SslOptions{
-			CertPath:               cfg.TLS.CertFile,
-			KeyPath:                cfg.TLS.KeyFile,
-			CaPath:                 cfg.TLS.CaFile,
-			EnableHostVerification: cfg.TLS.EnableHostVerification,
-
-			Config: &tls.Config{
-				ServerName: cfg.TLS.ServerName,
-			},
+			Config:                 tlsConfig,
+			EnableHostVerification: !tlsConfig.InsecureSkipVerify,
 		}
 	}
 	if cfg.MaxConns > 0 {
@@ -94,7 +95,7 @@ func newCassandraCluster(cfg ClusterConfig) *gocql.ClusterConfig {
 
 	cluster.PoolConfig.HostSelectionPolicy = gocql.TokenAwareHostPolicy(gocql.RoundRobinHostPolicy())
 
-	return cluster
+	return cluster, nil
 }
 
 // regionHostFilter returns a gocql host filter for the given region name
@@ -67,7 +67,10 @@ func NewSession(
 func initSession(
 	config ClusterConfig,
 ) (*gocql.Session, error) {
-	cluster := newCassandraCluster(config)
+	cluster, err := newCassandraCluster(config)
+	if err != nil {
+		return nil, err
+	}
 	cluster.Consistency = mustConvertConsistency(config.Consistency)
 	cluster.SerialConsistency = mustConvertSerialConsistency(config.SerialConsistency)
 	cluster.Timeout = config.Timeout
@@ -22,15 +22,141 @@
 package dynamodb
 
 import (
+	"context"
+	"sort"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+
 	"github.com/uber/cadence/common/persistence/nosql/nosqlplugin"
 )
 
 var _ nosqlplugin.AdminDB = (*ddb)(nil)
 
+// SetupTestDatabase creates all the tables of the schema, prefixed by the keyspace.
+// The schema is defined in code as DynamoDB tables are schemaless beyond their keys, so schemaBaseDir is ignored.
 func (db *ddb) SetupTestDatabase(schemaBaseDir string) error {
-	panic("TODO")
+	ctx := context.Background()
+	if err := db.dropTables(ctx); err != nil {
+		return err
+	}
+	return db.createTables(ctx)
 }
 
+// TeardownTestDatabase deletes all the tables of the schema
 func (db *ddb) TeardownTestDatabase() error {
-	panic("TODO")
+	return db.dropTables(context.Background())
+}
+
+func (db *ddb) createTables(ctx context.Context) error {
+	for _, def := range tableDefinitions {
+		if err := db.createTable(ctx, def); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (db *ddb) createTable(ctx context.Context, def tableDefinition) error {
+	tableName := db.tableName(def.name)
+	attributes := []*dynamodb.AttributeDefinition{{
+		AttributeName: aws.String(def.hashKey.name),
+		AttributeType: aws.String(def.hashKey.attributeType),
+	}}
+	keySchema := []*dynamodb.KeySchemaElement{{
+		AttributeName: aws.String(def.hashKey.name),
+		KeyType:       aws.String(dynamodb.KeyTypeHash),
+	}}
+	if def.rangeKey != nil {
+		attributes = append(attributes, &dynamodb.AttributeDefinition{
+			AttributeName: aws.String(def.rangeKey.name),
+			AttributeType: aws.String(def.rangeKey.attributeType),
+		})
+		keySchema = append(keySchema, &dynamodb.KeySchemaElement{
+			AttributeName: aws.String(def.rangeKey.name),
+			KeyType:       aws.String(dynamodb.KeyTypeRange),
+		})
+	}
+
+	var indexes []*dynamodb.LocalSecondaryIndex
+	indexNames := make([]string, 0, len(def.localIndexes))
+	for name := range def.localIndexes {
+		indexNames = append(indexNames, name)
+	}
+	sort.Strings(indexNames)
+	for _, name := range indexNames {
+		key := def.localIndexes[name]
+		attributes = append(attributes, &dynamodb.AttributeDefinition{
+			AttributeName: aws.String(key.name),
+			AttributeType: aws.String(key.attributeType),
+		})
+		indexes = append(indexes, &dynamodb.LocalSecondaryIndex{
+			IndexName: aws.String(name),
+			KeySchema: []*dynamodb.KeySchemaElement{
+				{
+					AttributeName: aws.String(def.hashKey.name),
+					KeyType:       aws.String(dynamodb.KeyTypeHash),
+				},
+				{
+					AttributeName: aws.String(key.name),
+					KeyType:       aws.String(dynamodb.KeyTypeRange),
+				},
+			},
+			Projection: &dynamodb.Projection{
+				ProjectionType: aws.String(dynamodb.ProjectionTypeAll),
+			},
+		})
+	}
+
+	_, err := db.client.CreateTableWithContext(ctx, &dynamodb.CreateTableInput{
+		TableName:             aws.String(tableName),
+		AttributeDefinitions:  attributes,
+		KeySchema:             keySchema,
+		LocalSecondaryIndexes: indexes,
+		BillingMode:           aws.String(dynamodb.BillingModePayPerRequest),
+	})
+	if err != nil {
+		return err
+	}
+	if err := db.client.WaitUntilTableExistsWithContext(ctx, &dynamodb.DescribeTableInput{
+		TableName: aws.String(tableName),
+	}); err != nil {
+		return err
+	}
+
+	if def.ttlEnabled {
+		_, err = db.client.UpdateTimeToLiveWithContext(ctx, &dynamodb.UpdateTimeToLiveInput{
+			TableName: aws.String(tableName),
+			TimeToLiveSpecification: &dynamodb.TimeToLiveSpecification{
+				AttributeName: aws.String(ttlAttribute),
+				Enabled:       aws.Bool(true),
+			},
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (db *ddb) dropTables(ctx context.Context) error {
+	for _, def := range tableDefinitions {
+		tableName := db.tableName(def.name)
+		_, err := db.client.DeleteTableWithContext(ctx, &dynamodb.DeleteTableInput{
+			TableName: aws.String(tableName),
+		})
+		if err != nil {
+			if aerr, ok := err.(awserr.Error); ok && aerr.Code() == dynamodb.ErrCodeResourceNotFoundException {
+				continue
+			}
+			return err
+		}
+		if err := db.client.WaitUntilTableNotExistsWithContext(ctx, &dynamodb.DescribeTableInput{
+			TableName: aws.String(tableName),
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
 }
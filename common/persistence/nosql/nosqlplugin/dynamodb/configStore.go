@@ -22,15 +22,67 @@ package dynamodb
 
 import (
 	"context"
-	"errors"
+	"time"
 
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb/expression"
+
+	"github.com/uber/cadence/common"
 	"github.com/uber/cadence/common/persistence"
+	"github.com/uber/cadence/common/persistence/nosql/nosqlplugin"
 )
 
 func (db *ddb) InsertConfig(ctx context.Context, row *persistence.InternalConfigStoreEntry) error {
-	return errors.New("TODO")
+	it := item{
+		"row_type":  numberValue(int64(row.RowType)),
+		"version":   numberValue(row.Version),
+		"timestamp": numberValue(row.Timestamp.UnixNano()),
+		"values":    binaryValue(row.Values.Data),
+		"encoding":  stringValue(string(row.Values.Encoding)),
+	}
+	condition := expression.AttributeNotExists(expression.Name("version"))
+	err := db.putItem(ctx, db.tableName(tableConfigStore), it, &condition)
+	if db.IsConditionFailedError(err) {
+		return nosqlplugin.NewConditionFailure("InsertConfig operation failed because of version collision")
+	}
+	return err
 }
 
 func (db *ddb) SelectLatestConfig(ctx context.Context, rowType int) (*persistence.InternalConfigStoreEntry, error) {
-	return nil, errors.New("TODO")
+	input, err := db.newQuery(
+		db.tableName(tableConfigStore),
+		"",
+		expression.Key("row_type").Equal(expression.Value(rowType)),
+		nil,
+	)
+	if err != nil {
+		return nil, err
+	}
+	input.ScanIndexForward = aws.Bool(false)
+	items, _, err := db.queryPage(ctx, input, 1, nil)
+	if err != nil {
+		return nil, err
+	}
+	if len(items) == 0 {
+		return nil, nil
+	}
+
+	it := items[0]
+	version, err := getNumber(it, "version")
+	if err != nil {
+		return nil, err
+	}
+	timestamp, err := getNumber(it, "timestamp")
+	if err != nil {
+		return nil, err
+	}
+	return &persistence.InternalConfigStoreEntry{
+		RowType:   rowType,
+		Version:   version,
+		Timestamp: time.Unix(0, timestamp),
+		Values: &persistence.DataBlob{
+			Data:     getBinary(it, "values"),
+			Encoding: common.EncodingType(getString(it, "encoding")),
+		},
+	}, nil
 }
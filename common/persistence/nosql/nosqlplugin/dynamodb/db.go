@@ -21,8 +21,13 @@
 package dynamodb
 
 import (
+	"context"
 	"errors"
-	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
 
 	"github.com/uber/cadence/common/config"
 	"github.com/uber/cadence/common/log"
@@ -36,10 +41,13 @@ const (
 
 var (
 	errConditionFailed = errors.New("internal condition fail error")
+	errItemNotFound    = errors.New("item not found")
 )
 
 // ddb represents a logical connection to DynamoDB database
 type ddb struct {
+	cfg    *config.NoSQL
+	client dynamodbiface.DynamoDBAPI
 	logger log.Logger
 }
 
@@ -47,11 +55,27 @@ var _ nosqlplugin.DB = (*ddb)(nil)
 
 // NewDynamoDB return a new DB
 func NewDynamoDB(cfg config.NoSQL, logger log.Logger) (nosqlplugin.DB, error) {
-	return nil, fmt.Errorf("TODO")
+	return newDynamoDB(&cfg, logger)
+}
+
+func newDynamoDB(cfg *config.NoSQL, logger log.Logger) (*ddb, error) {
+	sess, err := newSession(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return newDynamoDBFromClient(cfg, dynamodb.New(sess), logger), nil
+}
+
+func newDynamoDBFromClient(cfg *config.NoSQL, client dynamodbiface.DynamoDBAPI, logger log.Logger) *ddb {
+	return &ddb{
+		cfg:    cfg,
+		client: client,
+		logger: logger,
+	}
 }
 
 func (db *ddb) Close() {
-	panic("TODO")
+	// the underlying http client doesn't hold any resource that needs to be released explicitly
 }
 
 func (db *ddb) PluginName() string {
@@ -59,20 +83,55 @@ func (db *ddb) PluginName() string {
 }
 
 func (db *ddb) IsNotFoundError(err error) bool {
-	panic("TODO")
+	return err == errItemNotFound
 }
 
 func (db *ddb) IsTimeoutError(err error) bool {
-	panic("TODO")
+	if err == context.DeadlineExceeded {
+		return true
+	}
+	if aerr, ok := err.(awserr.Error); ok {
+		switch aerr.Code() {
+		case request.CanceledErrorCode, "RequestTimeout", "RequestTimeoutException":
+			return true
+		}
+		return aerr.OrigErr() == context.DeadlineExceeded
+	}
+	return false
 }
 
 func (db *ddb) IsThrottlingError(err error) bool {
-	panic("TODO")
+	if txErr, ok := err.(*dynamodb.TransactionCanceledException); ok {
+		for _, reason := range txErr.CancellationReasons {
+			if reason == nil || reason.Code == nil {
+				continue
+			}
+			switch *reason.Code {
+			case "ThrottlingError", "ProvisionedThroughputExceeded", "TransactionConflict":
+				return true
+			}
+		}
+		return false
+	}
+	if aerr, ok := err.(awserr.Error); ok {
+		switch aerr.Code() {
+		case dynamodb.ErrCodeProvisionedThroughputExceededException,
+			dynamodb.ErrCodeRequestLimitExceeded,
+			dynamodb.ErrCodeTransactionInProgressException,
+			dynamodb.ErrCodeTransactionConflictException,
+			"ThrottlingException":
+			return true
+		}
+	}
+	return false
 }
 
 func (db *ddb) IsConditionFailedError(err error) bool {
 	if err == errConditionFailed {
 		return true
 	}
+	if aerr, ok := err.(awserr.Error); ok {
+		return aerr.Code() == dynamodb.ErrCodeConditionalCheckFailedException
+	}
 	return false
 }
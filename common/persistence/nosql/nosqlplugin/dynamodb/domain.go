@@ -22,9 +22,26 @@ package dynamodb
 
 import (
 	"context"
+	"fmt"
 
+	"github.com/aws/aws-sdk-go/service/dynamodb/expression"
+
+	"github.com/uber/cadence/common"
+	"github.com/uber/cadence/common/log/tag"
+	p "github.com/uber/cadence/common/persistence"
 	"github.com/uber/cadence/common/persistence/nosql/nosqlplugin"
-	"github.com/uber/cadence/common/persistence/nosql/nosqlplugin/cassandra/gocql"
+	"github.com/uber/cadence/common/types"
+)
+
+// All the domain items are stored within a single partition of the domain table:
+// "name#<domainName>" is the domain record, "id#<domainID>" maps a domain ID to the name,
+// and "metadata" holds the notification version of the domains.
+// Unlike Cassandra, the three of them are updated within the same transaction.
+const (
+	constDomainPartition     = 0
+	domainNameKeyPrefix      = "name#"
+	domainIDKeyPrefix        = "id#"
+	domainMetadataRecordName = "metadata"
 )
 
 // Insert a new record to domain, return error if failed or already exists
@@ -33,15 +50,70 @@ func (db *ddb) InsertDomain(
 	ctx context.Context,
 	row *nosqlplugin.DomainRow,
 ) error {
-	panic("TODO")
+	metadataNotificationVersion, err := db.SelectDomainMetadata(ctx)
+	if err != nil {
+		return err
+	}
+
+	newRow := *row
+	newRow.FailoverNotificationVersion = p.InitialFailoverNotificationVersion
+	newRow.PreviousFailoverVersion = common.InitialPreviousFailoverVersion
+	newRow.NotificationVersion = metadataNotificationVersion
+	nameItem, err := newDomainItem(&newRow)
+	if err != nil {
+		return err
+	}
+	idItem := domainKey(domainIDKeyPrefix + row.Info.ID)
+	idItem["name"] = stringValue(row.Info.Name)
+
+	txn := &transaction{}
+	table := db.tableName(tableDomain)
+	notExists := expression.AttributeNotExists(expression.Name("domain_key"))
+	if err := txn.put(conditionDomainID, table, idItem, &notExists); err != nil {
+		return err
+	}
+	if err := txn.put(conditionDomainName, table, nameItem, &notExists); err != nil {
+		return err
+	}
+	if err := db.updateMetadata(txn, metadataNotificationVersion); err != nil {
+		return err
+	}
+
+	failures, err := db.executeTransaction(ctx, txn)
+	if err != nil {
+		return err
+	}
+	if failures.has(conditionDomainID) {
+		return fmt.Errorf("CreateDomain operation failed because of uuid collision")
+	}
+	if failures.has(conditionDomainName) {
+		db.logger.Warn("Domain already exists", tag.WorkflowDomainName(row.Info.Name))
+		return &types.DomainAlreadyExistsError{
+			Message: fmt.Sprintf("Domain %v already exists", row.Info.Name),
+		}
+	}
+	if failures != nil {
+		db.logger.Warn("Create domain operation failed because of condition update failure on domain metadata record")
+		return nosqlplugin.NewConditionFailure("domain")
+	}
+	return nil
 }
 
-func (db *ddb) updateMetadataBatch(
-	ctx context.Context,
-	batch gocql.Batch,
+// updateMetadata adds the conditional update of the domain notification version into the transaction
+func (db *ddb) updateMetadata(
+	txn *transaction,
 	notificationVersion int64,
-) {
-	panic("TODO")
+) error {
+	var condition expression.ConditionBuilder
+	if notificationVersion == 0 {
+		// the metadata record doesn't exist before the first domain is created
+		condition = expression.AttributeNotExists(expression.Name("notification_version")).
+			Or(expression.Name("notification_version").Equal(expression.Value(notificationVersion)))
+	} else {
+		condition = expression.Name("notification_version").Equal(expression.Value(notificationVersion))
+	}
+	update := expression.Set(expression.Name("notification_version"), expression.Value(notificationVersion+1))
+	return txn.update(conditionDomainMetadata, db.tableName(tableDomain), domainKey(domainMetadataRecordName), update, &condition)
 }
 
 // Update domain
@@ -49,7 +121,28 @@ func (db *ddb) UpdateDomain(
 	ctx context.Context,
 	row *nosqlplugin.DomainRow,
 ) error {
-	panic("TODO")
+	nameItem, err := newDomainItem(row)
+	if err != nil {
+		return err
+	}
+
+	txn := &transaction{}
+	exists := expression.AttributeExists(expression.Name("domain_key"))
+	if err := txn.put(conditionDomainName, db.tableName(tableDomain), nameItem, &exists); err != nil {
+		return err
+	}
+	if err := db.updateMetadata(txn, row.NotificationVersion); err != nil {
+		return err
+	}
+
+	failures, err := db.executeTransaction(ctx, txn)
+	if err != nil {
+		return err
+	}
+	if failures != nil {
+		return nosqlplugin.NewConditionFailure("domain")
+	}
+	return nil
 }
 
 // Get one domain data, either by domainID or domainName
@@ -58,7 +151,25 @@ func (db *ddb) SelectDomain(
 	domainID *string,
 	domainName *string,
 ) (*nosqlplugin.DomainRow, error) {
-	panic("TODO")
+	if domainID != nil && domainName != nil {
+		return nil, fmt.Errorf("GetDomain operation failed.  Both ID and Name specified in request")
+	} else if domainID == nil && domainName == nil {
+		return nil, fmt.Errorf("GetDomain operation failed.  Both ID and Name are empty")
+	}
+
+	if domainID != nil {
+		name, err := db.selectDomainName(ctx, *domainID)
+		if err != nil {
+			return nil, err
+		}
+		domainName = &name
+	}
+
+	it, err := db.getItem(ctx, db.tableName(tableDomain), domainKey(domainNameKeyPrefix+*domainName))
+	if err != nil {
+		return nil, err
+	}
+	return parseDomainItem(it)
 }
 
 // Get all domain data
@@ -67,20 +178,116 @@ func (db *ddb) SelectAllDomains(
 	pageSize int,
 	pageToken []byte,
 ) ([]*nosqlplugin.DomainRow, []byte, error) {
-	panic("TODO")
+	keyCondition := expression.KeyAnd(
+		expression.Key("domains_partition").Equal(expression.Value(constDomainPartition)),
+		expression.Key("domain_key").BeginsWith(domainNameKeyPrefix),
+	)
+	input, err := db.newQuery(db.tableName(tableDomain), "", keyCondition, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	items, nextPageToken, err := db.queryPage(ctx, input, pageSize, pageToken)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rows := make([]*nosqlplugin.DomainRow, 0, len(items))
+	for _, it := range items {
+		row, err := parseDomainItem(it)
+		if err != nil {
+			return nil, nil, err
+		}
+		rows = append(rows, row)
+	}
+	return rows, nextPageToken, nil
 }
 
-//  Delete a domain, either by domainID or domainName
+// Delete a domain, either by domainID or domainName
 func (db *ddb) DeleteDomain(
 	ctx context.Context,
 	domainID *string,
 	domainName *string,
 ) error {
-	panic("TODO")
+	if domainName == nil && domainID == nil {
+		return fmt.Errorf("must provide either domainID or domainName")
+	}
+
+	if domainName == nil {
+		name, err := db.selectDomainName(ctx, *domainID)
+		if err != nil {
+			if db.IsNotFoundError(err) {
+				return nil
+			}
+			return err
+		}
+		domainName = common.StringPtr(name)
+	} else {
+		it, err := db.getItem(ctx, db.tableName(tableDomain), domainKey(domainNameKeyPrefix+*domainName))
+		if err != nil {
+			if db.IsNotFoundError(err) {
+				return nil
+			}
+			return err
+		}
+		domainID = common.StringPtr(getString(it, "id"))
+	}
+
+	txn := &transaction{}
+	table := db.tableName(tableDomain)
+	if err := txn.delete(conditionNone, table, domainKey(domainNameKeyPrefix+*domainName), nil); err != nil {
+		return err
+	}
+	if err := txn.delete(conditionNone, table, domainKey(domainIDKeyPrefix+*domainID), nil); err != nil {
+		return err
+	}
+	_, err := db.executeTransaction(ctx, txn)
+	return err
 }
 
 func (db *ddb) SelectDomainMetadata(
 	ctx context.Context,
 ) (int64, error) {
-	panic("TODO")
+	it, err := db.getItem(ctx, db.tableName(tableDomain), domainKey(domainMetadataRecordName))
+	if err != nil {
+		if db.IsNotFoundError(err) {
+			// the metadata record is created along with the first domain
+			return 0, nil
+		}
+		return -1, err
+	}
+	return getNumber(it, "notification_version")
+}
+
+func (db *ddb) selectDomainName(ctx context.Context, domainID string) (string, error) {
+	it, err := db.getItem(ctx, db.tableName(tableDomain), domainKey(domainIDKeyPrefix+domainID))
+	if err != nil {
+		return "", err
+	}
+	return getString(it, "name"), nil
+}
+
+func domainKey(domainKey string) item {
+	return item{
+		"domains_partition": numberValue(constDomainPartition),
+		"domain_key":        stringValue(domainKey),
+	}
+}
+
+func newDomainItem(row *nosqlplugin.DomainRow) (item, error) {
+	data, err := jsonValue(row)
+	if err != nil {
+		return nil, err
+	}
+	it := domainKey(domainNameKeyPrefix + row.Info.Name)
+	it["id"] = stringValue(row.Info.ID)
+	it["data"] = data
+	return it, nil
+}
+
+func parseDomainItem(it item) (*nosqlplugin.DomainRow, error) {
+	row := &nosqlplugin.DomainRow{}
+	if err := getJSON(it, "data", row); err != nil {
+		return nil, err
+	}
+	return row, nil
 }
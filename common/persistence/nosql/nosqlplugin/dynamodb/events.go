@@ -22,31 +22,260 @@ package dynamodb
 
 import (
 	"context"
+	"fmt"
+	"math"
+	"sort"
+	"time"
 
+	"github.com/aws/aws-sdk-go/service/dynamodb/expression"
+
+	"github.com/uber/cadence/common"
 	"github.com/uber/cadence/common/persistence/nosql/nosqlplugin"
+	"github.com/uber/cadence/common/types"
 )
 
 // InsertIntoHistoryTreeAndNode inserts one or two rows: tree row and node row(at least one of them)
 func (db *ddb) InsertIntoHistoryTreeAndNode(ctx context.Context, treeRow *nosqlplugin.HistoryTreeRow, nodeRow *nosqlplugin.HistoryNodeRow) error {
-	panic("TODO")
+	if treeRow == nil && nodeRow == nil {
+		return fmt.Errorf("require at least a tree row or a node row to insert")
+	}
+
+	txn := &transaction{}
+	if treeRow != nil {
+		it, err := newHistoryTreeItem(treeRow)
+		if err != nil {
+			return err
+		}
+		if err := txn.put(conditionNone, db.tableName(tableHistoryTree), it, nil); err != nil {
+			return err
+		}
+	}
+	if nodeRow != nil {
+		if err := txn.put(conditionNone, db.tableName(tableHistoryNode), newHistoryNodeItem(nodeRow), nil); err != nil {
+			return err
+		}
+	}
+
+	if len(txn.items) == 1 {
+		// a single write doesn't need a transaction
+		put := txn.items[0].Put
+		return db.putItem(ctx, *put.TableName, put.Item, nil)
+	}
+	_, err := db.executeTransaction(ctx, txn)
+	return err
 }
 
 // SelectFromHistoryNode read nodes based on a filter
 func (db *ddb) SelectFromHistoryNode(ctx context.Context, filter *nosqlplugin.HistoryNodeFilter) ([]*nosqlplugin.HistoryNodeRow, []byte, error) {
-	panic("TODO")
+	keyCondition := expression.KeyAnd(
+		expression.Key("tree_id").Equal(expression.Value(filter.TreeID)),
+		expression.Key("node_key").Between(
+			expression.Value(historyNodePrefix(filter.BranchID, filter.MinNodeID)),
+			expression.Value(historyNodePrefix(filter.BranchID, filter.MaxNodeID)),
+		),
+	)
+	input, err := db.newQuery(db.tableName(tableHistoryNode), "", keyCondition, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	items, nextPageToken, err := db.queryPage(ctx, input, filter.PageSize, filter.NextPageToken)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rows := make([]*nosqlplugin.HistoryNodeRow, 0, len(items))
+	for _, it := range items {
+		row, err := parseHistoryNodeItem(it)
+		if err != nil {
+			return nil, nil, err
+		}
+		rows = append(rows, row)
+	}
+	return rows, nextPageToken, nil
 }
 
 // DeleteFromHistoryTreeAndNode delete a branch record, and a list of ranges of nodes.
+// NOTE: unlike the cassandra batch, this is not atomic. Deleting the nodes first leaves
+// the branch record in place for retry when it fails in the middle.
 func (db *ddb) DeleteFromHistoryTreeAndNode(ctx context.Context, treeFilter *nosqlplugin.HistoryTreeFilter, nodeFilters []*nosqlplugin.HistoryNodeFilter) error {
-	panic("TODO")
+	for _, nodeFilter := range nodeFilters {
+		keyCondition := expression.KeyAnd(
+			expression.Key("tree_id").Equal(expression.Value(nodeFilter.TreeID)),
+			expression.Key("node_key").Between(
+				expression.Value(historyNodePrefix(nodeFilter.BranchID, nodeFilter.MinNodeID)),
+				expression.Value(nodeFilter.BranchID+"#~"),
+			),
+		)
+		input, err := db.newQuery(db.tableName(tableHistoryNode), "", keyCondition, nil)
+		if err != nil {
+			return err
+		}
+		if err := db.deleteByQuery(ctx, input, "tree_id", "node_key"); err != nil {
+			return err
+		}
+	}
+	return db.deleteItem(ctx, db.tableName(tableHistoryTree), historyTreeKey(treeFilter.TreeID, common.StringDefault(treeFilter.BranchID)))
 }
 
 // SelectAllHistoryTrees will return all tree branches with pagination
 func (db *ddb) SelectAllHistoryTrees(ctx context.Context, nextPageToken []byte, pageSize int) ([]*nosqlplugin.HistoryTreeRow, []byte, error) {
-	panic("TODO")
+	items, token, err := db.scanPage(ctx, db.tableName(tableHistoryTree), nil, pageSize, nextPageToken)
+	if err != nil {
+		return nil, nil, err
+	}
+	rows := make([]*nosqlplugin.HistoryTreeRow, 0, len(items))
+	for _, it := range items {
+		row, err := parseHistoryTreeItem(it)
+		if err != nil {
+			return nil, nil, err
+		}
+		rows = append(rows, row)
+	}
+	return rows, token, nil
 }
 
 // SelectFromHistoryTree read branch records for a tree
 func (db *ddb) SelectFromHistoryTree(ctx context.Context, filter *nosqlplugin.HistoryTreeFilter) ([]*nosqlplugin.HistoryTreeRow, error) {
-	panic("TODO")
+	input, err := db.newQuery(
+		db.tableName(tableHistoryTree),
+		"",
+		expression.Key("tree_id").Equal(expression.Value(filter.TreeID)),
+		nil,
+	)
+	if err != nil {
+		return nil, err
+	}
+	var rows []*nosqlplugin.HistoryTreeRow
+	err = db.queryAll(ctx, input, func(it item) error {
+		row, err := parseHistoryTreeItem(it)
+		if err != nil {
+			return err
+		}
+		rows = append(rows, row)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+// historyNodePrefix returns the prefix of node_key for a node, the node_key is ordered
+// by node_id ascending and then txn_id descending, the same as the cassandra clustering order
+func historyNodePrefix(branchID string, nodeID int64) string {
+	return fmt.Sprintf("%s#%020d", branchID, nodeID)
+}
+
+func historyNodeKeyValue(branchID string, nodeID, txnID int64) string {
+	return fmt.Sprintf("%s#%020d", historyNodePrefix(branchID, nodeID), math.MaxInt64-txnID)
+}
+
+func historyTreeKey(treeID, branchID string) item {
+	return item{
+		"tree_id":   stringValue(treeID),
+		"branch_id": stringValue(branchID),
+	}
+}
+
+func newHistoryNodeItem(row *nosqlplugin.HistoryNodeRow) item {
+	txnID := int64(0)
+	if row.TxnID != nil {
+		txnID = *row.TxnID
+	}
+	return item{
+		"tree_id":       stringValue(row.TreeID),
+		"node_key":      stringValue(historyNodeKeyValue(row.BranchID, row.NodeID, txnID)),
+		"branch_id":     stringValue(row.BranchID),
+		"node_id":       numberValue(row.NodeID),
+		"txn_id":        numberValue(txnID),
+		"data":          binaryValue(row.Data),
+		"data_encoding": stringValue(row.DataEncoding),
+	}
+}
+
+func parseHistoryNodeItem(it item) (*nosqlplugin.HistoryNodeRow, error) {
+	nodeID, err := getNumber(it, "node_id")
+	if err != nil {
+		return nil, err
+	}
+	txnID, err := getNumber(it, "txn_id")
+	if err != nil {
+		return nil, err
+	}
+	return &nosqlplugin.HistoryNodeRow{
+		TreeID:       getString(it, "tree_id"),
+		BranchID:     getString(it, "branch_id"),
+		NodeID:       nodeID,
+		TxnID:        common.Int64Ptr(txnID),
+		Data:         getBinary(it, "data"),
+		DataEncoding: getString(it, "data_encoding"),
+	}, nil
+}
+
+type historyBranchAncestor struct {
+	BranchID  string `json:"branch_id"`
+	EndNodeID int64  `json:"end_node_id"`
+}
+
+func newHistoryTreeItem(row *nosqlplugin.HistoryTreeRow) (item, error) {
+	ancestors := make([]historyBranchAncestor, 0, len(row.Ancestors))
+	for _, an := range row.Ancestors {
+		ancestors = append(ancestors, historyBranchAncestor{
+			BranchID:  an.GetBranchID(),
+			EndNodeID: an.GetEndNodeID(),
+		})
+	}
+	ancestorsValue, err := jsonValue(ancestors)
+	if err != nil {
+		return nil, err
+	}
+	it := historyTreeKey(row.TreeID, row.BranchID)
+	it["shard_id"] = numberValue(int64(row.ShardID))
+	it["ancestors"] = ancestorsValue
+	it["fork_time"] = numberValue(row.CreateTimestamp.UnixNano())
+	it["info"] = stringValue(row.Info)
+	return it, nil
+}
+
+func parseHistoryTreeItem(it item) (*nosqlplugin.HistoryTreeRow, error) {
+	var ancestors []historyBranchAncestor
+	if err := getJSON(it, "ancestors", &ancestors); err != nil {
+		return nil, err
+	}
+	shardID, err := getNumber(it, "shard_id")
+	if err != nil {
+		return nil, err
+	}
+	forkTime, err := getNumber(it, "fork_time")
+	if err != nil {
+		return nil, err
+	}
+	return &nosqlplugin.HistoryTreeRow{
+		ShardID:         int(shardID),
+		TreeID:          getString(it, "tree_id"),
+		BranchID:        getString(it, "branch_id"),
+		Ancestors:       parseBranchAncestors(ancestors),
+		CreateTimestamp: time.Unix(0, forkTime),
+		Info:            getString(it, "info"),
+	}, nil
+}
+
+func parseBranchAncestors(ancestors []historyBranchAncestor) []*types.HistoryBranchRange {
+	ans := make([]*types.HistoryBranchRange, 0, len(ancestors))
+	for _, e := range ancestors {
+		ans = append(ans, &types.HistoryBranchRange{
+			BranchID:  common.StringPtr(e.BranchID),
+			EndNodeID: common.Int64Ptr(e.EndNodeID),
+		})
+	}
+
+	if len(ans) > 0 {
+		// sort ans based on EndNodeID so that we can set BeginNodeID
+		sort.Slice(ans, func(i, j int) bool { return *ans[i].EndNodeID < *ans[j].EndNodeID })
+		ans[0].BeginNodeID = common.Int64Ptr(int64(1))
+		for i := 1; i < len(ans); i++ {
+			ans[i].BeginNodeID = ans[i-1].EndNodeID
+		}
+	}
+	return ans
 }
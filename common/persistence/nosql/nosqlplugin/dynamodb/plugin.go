@@ -0,0 +1,128 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dynamodb
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+
+	"github.com/uber/cadence/common/config"
+	"github.com/uber/cadence/common/log"
+	"github.com/uber/cadence/common/persistence/nosql"
+	"github.com/uber/cadence/common/persistence/nosql/nosqlplugin"
+	"github.com/uber/cadence/environment"
+)
+
+const (
+	// credentials used when connecting to DynamoDB Local, which accepts any non-empty credentials
+	localAccessKeyID     = "cadence"
+	localSecretAccessKey = "cadence"
+)
+
+type plugin struct{}
+
+var _ nosqlplugin.Plugin = (*plugin)(nil)
+
+func init() {
+	nosql.RegisterPlugin(PluginName, &plugin{})
+}
+
+// CreateDB initialize the db object
+func (p *plugin) CreateDB(cfg *config.NoSQL, logger log.Logger) (nosqlplugin.DB, error) {
+	return newDynamoDB(cfg, logger)
+}
+
+// CreateAdminDB initialize the AdminDB object
+func (p *plugin) CreateAdminDB(cfg *config.NoSQL, logger log.Logger) (nosqlplugin.AdminDB, error) {
+	return newDynamoDB(cfg, logger)
+}
+
+// newSession creates an AWS session from the NoSQL config.
+// When neither Hosts nor Region is configured, the session points to a DynamoDB Local
+// instance resolved from the environment, which is what the tests and local development use.
+// When only Region is configured, the default AWS endpoint and credential chain of that region are used.
+func newSession(cfg *config.NoSQL) (*session.Session, error) {
+	awsConfig := &aws.Config{}
+
+	region := cfg.Region
+	hosts := cfg.Hosts
+	port := cfg.Port
+	useLocal := hosts == "" && region == ""
+	if useLocal {
+		hosts = environment.GetDynamoDBAddress()
+		if port == 0 {
+			port = environment.GetDynamoDBPort()
+		}
+	}
+	if region == "" {
+		region = environment.GetDynamoDBRegion()
+	}
+	awsConfig.Region = aws.String(region)
+
+	if hosts != "" {
+		awsConfig.Endpoint = aws.String(toEndpoint(hosts, port, cfg.TLS != nil && cfg.TLS.Enabled))
+	}
+
+	if cfg.User != "" {
+		awsConfig.Credentials = credentials.NewStaticCredentials(cfg.User, cfg.Password, "")
+	} else if useLocal {
+		awsConfig.Credentials = credentials.NewStaticCredentials(localAccessKeyID, localSecretAccessKey, "")
+	}
+
+	transport := &http.Transport{
+		Proxy: http.ProxyFromEnvironment,
+	}
+	if cfg.MaxConns > 0 {
+		transport.MaxConnsPerHost = cfg.MaxConns
+		transport.MaxIdleConnsPerHost = cfg.MaxConns
+	}
+	if cfg.TLS != nil && cfg.TLS.Enabled {
+		tlsConfig, err := cfg.TLS.ToTLSConfig()
+		if err != nil {
+			return nil, err
+		}
+		transport.TLSClientConfig = tlsConfig
+	}
+	awsConfig.HTTPClient = &http.Client{Transport: transport}
+
+	return session.NewSession(awsConfig)
+}
+
+// toEndpoint converts the first host of the csv hosts into a DynamoDB endpoint URL
+func toEndpoint(hosts string, port int, tlsEnabled bool) string {
+	host := strings.TrimSpace(strings.Split(hosts, ",")[0])
+	if strings.Contains(host, "://") {
+		return host
+	}
+	scheme := "http"
+	if tlsEnabled {
+		scheme = "https"
+	}
+	if port == 0 {
+		return fmt.Sprintf("%v://%v", scheme, host)
+	}
+	return fmt.Sprintf("%v://%v:%v", scheme, host, port)
+}
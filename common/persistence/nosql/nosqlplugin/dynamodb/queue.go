@@ -22,18 +22,33 @@ package dynamodb
 
 import (
 	"context"
+	"math"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/expression"
 
 	"github.com/uber/cadence/common/persistence"
 	"github.com/uber/cadence/common/persistence/nosql/nosqlplugin"
 )
 
-//Insert message into queue, return error if failed or already exists
+// Insert message into queue, return error if failed or already exists
 // Return ConditionFailure if the condition doesn't meet
 func (db *ddb) InsertIntoQueue(
 	ctx context.Context,
 	row *nosqlplugin.QueueMessageRow,
 ) error {
-	panic("TODO")
+	it := item{
+		"queue_type":      numberValue(int64(row.QueueType)),
+		"message_id":      numberValue(row.ID),
+		"message_payload": binaryValue(row.Payload),
+	}
+	condition := expression.AttributeNotExists(expression.Name("message_id"))
+	err := db.putItem(ctx, db.tableName(tableQueueMessage), it, &condition)
+	if db.IsConditionFailedError(err) {
+		return nosqlplugin.NewConditionFailure("queue")
+	}
+	return err
 }
 
 // Get the ID of last message inserted into the queue
@@ -41,7 +56,19 @@ func (db *ddb) SelectLastEnqueuedMessageID(
 	ctx context.Context,
 	queueType persistence.QueueType,
 ) (int64, error) {
-	panic("TODO")
+	input, err := db.newQueueMessagesQuery(queueType, math.MinInt64, math.MaxInt64)
+	if err != nil {
+		return 0, err
+	}
+	input.ScanIndexForward = aws.Bool(false)
+	items, _, err := db.queryPage(ctx, input, 1, nil)
+	if err != nil {
+		return 0, err
+	}
+	if len(items) == 0 {
+		return 0, errItemNotFound
+	}
+	return getNumber(items[0], "message_id")
 }
 
 // Read queue messages starting from the exclusiveBeginMessageID
@@ -51,7 +78,27 @@ func (db *ddb) SelectMessagesFrom(
 	exclusiveBeginMessageID int64,
 	maxRows int,
 ) ([]*nosqlplugin.QueueMessageRow, error) {
-	panic("TODO")
+	if exclusiveBeginMessageID == math.MaxInt64 {
+		return nil, nil
+	}
+	input, err := db.newQueueMessagesQuery(queueType, exclusiveBeginMessageID+1, math.MaxInt64)
+	if err != nil {
+		return nil, err
+	}
+	items, _, err := db.queryPage(ctx, input, maxRows, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []*nosqlplugin.QueueMessageRow
+	for _, it := range items {
+		row, err := parseQueueMessageItem(it)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, &row)
+	}
+	return result, nil
 }
 
 // Read queue message starting from exclusiveBeginMessageID int64, inclusiveEndMessageID int64
@@ -59,7 +106,28 @@ func (db *ddb) SelectMessagesBetween(
 	ctx context.Context,
 	request nosqlplugin.SelectMessagesBetweenRequest,
 ) (*nosqlplugin.SelectMessagesBetweenResponse, error) {
-	panic("TODO")
+	response := &nosqlplugin.SelectMessagesBetweenResponse{}
+	if request.ExclusiveBeginMessageID >= request.InclusiveEndMessageID {
+		return response, nil
+	}
+	input, err := db.newQueueMessagesQuery(request.QueueType, request.ExclusiveBeginMessageID+1, request.InclusiveEndMessageID)
+	if err != nil {
+		return nil, err
+	}
+	items, nextPageToken, err := db.queryPage(ctx, input, request.PageSize, request.NextPageToken)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, it := range items {
+		row, err := parseQueueMessageItem(it)
+		if err != nil {
+			return nil, err
+		}
+		response.Rows = append(response.Rows, row)
+	}
+	response.NextPageToken = nextPageToken
+	return response, nil
 }
 
 // Delete all messages before exclusiveBeginMessageID
@@ -68,7 +136,14 @@ func (db *ddb) DeleteMessagesBefore(
 	queueType persistence.QueueType,
 	exclusiveBeginMessageID int64,
 ) error {
-	panic("TODO")
+	if exclusiveBeginMessageID == math.MinInt64 {
+		return nil
+	}
+	input, err := db.newQueueMessagesQuery(queueType, math.MinInt64, exclusiveBeginMessageID-1)
+	if err != nil {
+		return err
+	}
+	return db.deleteByQuery(ctx, input, "queue_type", "message_id")
 }
 
 // Delete all messages in a range between exclusiveBeginMessageID and inclusiveEndMessageID
@@ -78,7 +153,14 @@ func (db *ddb) DeleteMessagesInRange(
 	exclusiveBeginMessageID int64,
 	inclusiveEndMessageID int64,
 ) error {
-	panic("TODO")
+	if exclusiveBeginMessageID >= inclusiveEndMessageID {
+		return nil
+	}
+	input, err := db.newQueueMessagesQuery(queueType, exclusiveBeginMessageID+1, inclusiveEndMessageID)
+	if err != nil {
+		return err
+	}
+	return db.deleteByQuery(ctx, input, "queue_type", "message_id")
 }
 
 // Delete one message
@@ -87,7 +169,10 @@ func (db *ddb) DeleteMessage(
 	queueType persistence.QueueType,
 	messageID int64,
 ) error {
-	panic("TODO")
+	return db.deleteItem(ctx, db.tableName(tableQueueMessage), item{
+		"queue_type": numberValue(int64(queueType)),
+		"message_id": numberValue(messageID),
+	})
 }
 
 // Insert an empty metadata row, starting from a version
@@ -96,17 +181,40 @@ func (db *ddb) InsertQueueMetadata(
 	queueType persistence.QueueType,
 	version int64,
 ) error {
-	panic("TODO")
+	it, err := newQueueMetadataItem(nosqlplugin.QueueMetadataRow{
+		QueueType:        queueType,
+		ClusterAckLevels: map[string]int64{},
+		Version:          version,
+	})
+	if err != nil {
+		return err
+	}
+	condition := expression.AttributeNotExists(expression.Name("queue_type"))
+	err = db.putItem(ctx, db.tableName(tableQueueMetadata), it, &condition)
+	if db.IsConditionFailedError(err) {
+		// it's ok if the query is not applied, which means that the record exists already.
+		return nil
+	}
+	return err
 }
 
 // **Conditionally** update a queue metadata row, if current version is matched(meaning current == row.Version - 1),
 // then the current version will increase by one when updating the metadata row
-// Return ConditionFailure if the condition doesn't meet
+// it should return ConditionFailure if the condition is not met
 func (db *ddb) UpdateQueueMetadataCas(
 	ctx context.Context,
 	row nosqlplugin.QueueMetadataRow,
 ) error {
-	panic("TODO")
+	it, err := newQueueMetadataItem(row)
+	if err != nil {
+		return err
+	}
+	condition := expression.Name("version").Equal(expression.Value(row.Version - 1))
+	err = db.putItem(ctx, db.tableName(tableQueueMetadata), it, &condition)
+	if db.IsConditionFailedError(err) {
+		return nosqlplugin.NewConditionFailure("queue")
+	}
+	return err
 }
 
 // Read a QueueMetadata
@@ -114,12 +222,74 @@ func (db *ddb) SelectQueueMetadata(
 	ctx context.Context,
 	queueType persistence.QueueType,
 ) (*nosqlplugin.QueueMetadataRow, error) {
-	panic("TODO")
+	it, err := db.getItem(ctx, db.tableName(tableQueueMetadata), item{
+		"queue_type": numberValue(int64(queueType)),
+	})
+	if err != nil {
+		return nil, err
+	}
+	version, err := getNumber(it, "version")
+	if err != nil {
+		return nil, err
+	}
+	var ackLevels map[string]int64
+	if err := getJSON(it, "cluster_ack_level", &ackLevels); err != nil {
+		return nil, err
+	}
+	// if record exist but ackLevels is empty, we initialize the map
+	if ackLevels == nil {
+		ackLevels = make(map[string]int64)
+	}
+	return &nosqlplugin.QueueMetadataRow{
+		QueueType:        queueType,
+		ClusterAckLevels: ackLevels,
+		Version:          version,
+	}, nil
 }
 
 func (db *ddb) GetQueueSize(
 	ctx context.Context,
 	queueType persistence.QueueType,
 ) (int64, error) {
-	panic("TODO")
+	input, err := db.newQueueMessagesQuery(queueType, math.MinInt64, math.MaxInt64)
+	if err != nil {
+		return 0, err
+	}
+	return db.count(ctx, input)
+}
+
+// newQueueMessagesQuery returns a query of the messages within [inclusiveBeginMessageID, inclusiveEndMessageID]
+func (db *ddb) newQueueMessagesQuery(
+	queueType persistence.QueueType,
+	inclusiveBeginMessageID int64,
+	inclusiveEndMessageID int64,
+) (*dynamodb.QueryInput, error) {
+	keyCondition := expression.KeyAnd(
+		expression.Key("queue_type").Equal(expression.Value(queueType)),
+		expression.Key("message_id").Between(expression.Value(inclusiveBeginMessageID), expression.Value(inclusiveEndMessageID)),
+	)
+	return db.newQuery(db.tableName(tableQueueMessage), "", keyCondition, nil)
+}
+
+func newQueueMetadataItem(row nosqlplugin.QueueMetadataRow) (item, error) {
+	ackLevels, err := jsonValue(row.ClusterAckLevels)
+	if err != nil {
+		return nil, err
+	}
+	return item{
+		"queue_type":        numberValue(int64(row.QueueType)),
+		"cluster_ack_level": ackLevels,
+		"version":           numberValue(row.Version),
+	}, nil
+}
+
+func parseQueueMessageItem(it item) (nosqlplugin.QueueMessageRow, error) {
+	id, err := getNumber(it, "message_id")
+	if err != nil {
+		return nosqlplugin.QueueMessageRow{}, err
+	}
+	return nosqlplugin.QueueMessageRow{
+		ID:      id,
+		Payload: getBinary(it, "message_payload"),
+	}, nil
 }
@@ -0,0 +1,171 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dynamodb
+
+import (
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+// Tables of the DynamoDB schema. Each table name is prefixed by the keyspace of the NoSQL config, if any.
+const (
+	tableShard              = "shard"
+	tableCurrentWorkflow    = "current_workflow"
+	tableWorkflowExecution  = "workflow_execution"
+	tableTransferTask       = "transfer_task"
+	tableCrossClusterTask   = "cross_cluster_task"
+	tableReplicationTask    = "replication_task"
+	tableReplicationDLQTask = "replication_dlq_task"
+	tableTimerTask          = "timer_task"
+	tableHistoryTree        = "history_tree"
+	tableHistoryNode        = "history_node"
+	tableQueueMessage       = "queue_message"
+	tableQueueMetadata      = "queue_metadata"
+	tableDomain             = "domain"
+	tableTaskList           = "task_list"
+	tableTask               = "task"
+	tableVisibility         = "visibility"
+	tableConfigStore        = "config_store"
+)
+
+// Local secondary indexes of the visibility table
+const (
+	indexVisibilityStartTime = "start_time_index"
+	indexVisibilityCloseTime = "close_time_index"
+)
+
+// ttlAttribute is the attribute holding the expiration time(epoch seconds) of the tables that support TTL
+const ttlAttribute = "ttl"
+
+type (
+	keyDefinition struct {
+		name          string
+		attributeType string
+	}
+
+	tableDefinition struct {
+		name         string
+		hashKey      keyDefinition
+		rangeKey     *keyDefinition
+		localIndexes map[string]keyDefinition
+		ttlEnabled   bool
+	}
+)
+
+var tableDefinitions = []tableDefinition{
+	{
+		name:    tableShard,
+		hashKey: keyDefinition{"shard_id", dynamodb.ScalarAttributeTypeN},
+	},
+	{
+		name:     tableCurrentWorkflow,
+		hashKey:  keyDefinition{"shard_id", dynamodb.ScalarAttributeTypeN},
+		rangeKey: &keyDefinition{"workflow_key", dynamodb.ScalarAttributeTypeS},
+	},
+	{
+		name:     tableWorkflowExecution,
+		hashKey:  keyDefinition{"shard_id", dynamodb.ScalarAttributeTypeN},
+		rangeKey: &keyDefinition{"execution_key", dynamodb.ScalarAttributeTypeS},
+	},
+	{
+		name:     tableTransferTask,
+		hashKey:  keyDefinition{"shard_id", dynamodb.ScalarAttributeTypeN},
+		rangeKey: &keyDefinition{"task_id", dynamodb.ScalarAttributeTypeN},
+	},
+	{
+		name:     tableCrossClusterTask,
+		hashKey:  keyDefinition{"shard_cluster", dynamodb.ScalarAttributeTypeS},
+		rangeKey: &keyDefinition{"task_id", dynamodb.ScalarAttributeTypeN},
+	},
+	{
+		name:     tableReplicationTask,
+		hashKey:  keyDefinition{"shard_id", dynamodb.ScalarAttributeTypeN},
+		rangeKey: &keyDefinition{"task_id", dynamodb.ScalarAttributeTypeN},
+	},
+	{
+		name:     tableReplicationDLQTask,
+		hashKey:  keyDefinition{"shard_cluster", dynamodb.ScalarAttributeTypeS},
+		rangeKey: &keyDefinition{"task_id", dynamodb.ScalarAttributeTypeN},
+	},
+	{
+		name:     tableTimerTask,
+		hashKey:  keyDefinition{"shard_id", dynamodb.ScalarAttributeTypeN},
+		rangeKey: &keyDefinition{"timer_key", dynamodb.ScalarAttributeTypeS},
+	},
+	{
+		name:     tableHistoryTree,
+		hashKey:  keyDefinition{"tree_id", dynamodb.ScalarAttributeTypeS},
+		rangeKey: &keyDefinition{"branch_id", dynamodb.ScalarAttributeTypeS},
+	},
+	{
+		name:     tableHistoryNode,
+		hashKey:  keyDefinition{"tree_id", dynamodb.ScalarAttributeTypeS},
+		rangeKey: &keyDefinition{"node_key", dynamodb.ScalarAttributeTypeS},
+	},
+	{
+		name:     tableQueueMessage,
+		hashKey:  keyDefinition{"queue_type", dynamodb.ScalarAttributeTypeN},
+		rangeKey: &keyDefinition{"message_id", dynamodb.ScalarAttributeTypeN},
+	},
+	{
+		name:    tableQueueMetadata,
+		hashKey: keyDefinition{"queue_type", dynamodb.ScalarAttributeTypeN},
+	},
+	{
+		name:     tableDomain,
+		hashKey:  keyDefinition{"domains_partition", dynamodb.ScalarAttributeTypeN},
+		rangeKey: &keyDefinition{"domain_key", dynamodb.ScalarAttributeTypeS},
+	},
+	{
+		name:       tableTaskList,
+		hashKey:    keyDefinition{"task_list_key", dynamodb.ScalarAttributeTypeS},
+		ttlEnabled: true,
+	},
+	{
+		name:       tableTask,
+		hashKey:    keyDefinition{"task_list_key", dynamodb.ScalarAttributeTypeS},
+		rangeKey:   &keyDefinition{"task_id", dynamodb.ScalarAttributeTypeN},
+		ttlEnabled: true,
+	},
+	{
+		// the local indexes share the partition of the domain, therefore the visibility records
+		// of a single domain are bound to the 10GB limit of a DynamoDB item collection
+		name:     tableVisibility,
+		hashKey:  keyDefinition{"domain_id", dynamodb.ScalarAttributeTypeS},
+		rangeKey: &keyDefinition{"run_key", dynamodb.ScalarAttributeTypeS},
+		localIndexes: map[string]keyDefinition{
+			indexVisibilityStartTime: {"start_time", dynamodb.ScalarAttributeTypeN},
+			indexVisibilityCloseTime: {"close_time", dynamodb.ScalarAttributeTypeN},
+		},
+		ttlEnabled: true,
+	},
+	{
+		name:     tableConfigStore,
+		hashKey:  keyDefinition{"row_type", dynamodb.ScalarAttributeTypeN},
+		rangeKey: &keyDefinition{"version", dynamodb.ScalarAttributeTypeN},
+	},
+}
+
+func (db *ddb) tableName(name string) string {
+	if db.cfg.Keyspace == "" {
+		return name
+	}
+	return db.cfg.Keyspace + "_" + name
+}
@@ -22,29 +22,130 @@ package dynamodb
 
 import (
 	"context"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go/service/dynamodb/expression"
 
 	"github.com/uber/cadence/common/persistence/nosql/nosqlplugin"
 )
 
+var _ nosqlplugin.ShardCRUD = (*ddb)(nil)
+
 // InsertShard creates a new shard, return error is there is any.
 // Return ShardOperationConditionFailure if the condition doesn't meet
 func (db *ddb) InsertShard(ctx context.Context, row *nosqlplugin.ShardRow) error {
-	panic("TODO")
+	it, err := newShardItem(row, row.RangeID)
+	if err != nil {
+		return err
+	}
+	condition := expression.AttributeNotExists(expression.Name("shard_id"))
+	err = db.putItem(ctx, db.tableName(tableShard), it, &condition)
+	if db.IsConditionFailedError(err) {
+		return db.newShardConditionFailure(ctx, row.ShardID)
+	}
+	return err
 }
 
 // SelectShard gets a shard
 func (db *ddb) SelectShard(ctx context.Context, shardID int, currentClusterName string) (int64, *nosqlplugin.ShardRow, error) {
-	panic("TODO")
+	it, err := db.getItem(ctx, db.tableName(tableShard), shardKey(shardID))
+	if err != nil {
+		return 0, nil, err
+	}
+	rangeID, err := getNumber(it, "range_id")
+	if err != nil {
+		return 0, nil, err
+	}
+	shard := &nosqlplugin.ShardRow{}
+	if err := getJSON(it, "data", shard); err != nil {
+		return 0, nil, err
+	}
+
+	if shard.ClusterTransferAckLevel == nil {
+		shard.ClusterTransferAckLevel = map[string]int64{
+			currentClusterName: shard.TransferAckLevel,
+		}
+	}
+	if shard.ClusterTimerAckLevel == nil {
+		shard.ClusterTimerAckLevel = map[string]time.Time{
+			currentClusterName: shard.TimerAckLevel,
+		}
+	}
+	if shard.ClusterReplicationLevel == nil {
+		shard.ClusterReplicationLevel = make(map[string]int64)
+	}
+	if shard.ReplicationDLQAckLevel == nil {
+		shard.ReplicationDLQAckLevel = make(map[string]int64)
+	}
+	return rangeID, shard, nil
 }
 
 // UpdateRangeID updates the rangeID, return error is there is any
 // Return ShardOperationConditionFailure if the condition doesn't meet
 func (db *ddb) UpdateRangeID(ctx context.Context, shardID int, rangeID int64, previousRangeID int64) error {
-	panic("TODO")
+	update := expression.Set(expression.Name("range_id"), expression.Value(rangeID))
+	condition := expression.Name("range_id").Equal(expression.Value(previousRangeID))
+	err := db.updateItem(ctx, db.tableName(tableShard), shardKey(shardID), update, &condition)
+	if db.IsConditionFailedError(err) {
+		return db.newShardConditionFailure(ctx, shardID)
+	}
+	return err
 }
 
 // UpdateShard updates a shard, return error is there is any.
 // Return ShardOperationConditionFailure if the condition doesn't meet
 func (db *ddb) UpdateShard(ctx context.Context, row *nosqlplugin.ShardRow, previousRangeID int64) error {
-	panic("TODO")
+	it, err := newShardItem(row, row.RangeID)
+	if err != nil {
+		return err
+	}
+	condition := expression.Name("range_id").Equal(expression.Value(previousRangeID))
+	err = db.putItem(ctx, db.tableName(tableShard), it, &condition)
+	if db.IsConditionFailedError(err) {
+		return db.newShardConditionFailure(ctx, row.ShardID)
+	}
+	return err
+}
+
+// newShardConditionFailure reads the current shard to report the actual rangeID,
+// as DynamoDB doesn't return the existing item when a conditional write fails
+func (db *ddb) newShardConditionFailure(ctx context.Context, shardID int) error {
+	rangeID := int64(-1)
+	details := "shard not found"
+	it, err := db.getItem(ctx, db.tableName(tableShard), shardKey(shardID))
+	if err != nil && !db.IsNotFoundError(err) {
+		return err
+	}
+	if err == nil {
+		rangeID, err = getNumber(it, "range_id")
+		if err != nil {
+			return err
+		}
+		details = fmt.Sprintf("shard_id=%v,range_id=%v", shardID, rangeID)
+	}
+	return &nosqlplugin.ShardOperationConditionFailure{
+		RangeID: rangeID,
+		Details: details,
+	}
+}
+
+func newShardItem(row *nosqlplugin.ShardRow, rangeID int64) (item, error) {
+	shard := *row
+	shard.UpdatedAt = time.Now()
+	data, err := jsonValue(&shard)
+	if err != nil {
+		return nil, err
+	}
+	return item{
+		"shard_id": numberValue(int64(row.ShardID)),
+		"range_id": numberValue(rangeID),
+		"data":     data,
+	}, nil
+}
+
+func shardKey(shardID int) item {
+	return item{
+		"shard_id": numberValue(int64(shardID)),
+	}
 }
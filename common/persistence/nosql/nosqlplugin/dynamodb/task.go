@@ -22,20 +22,42 @@ package dynamodb
 
 import (
 	"context"
+	"fmt"
+	"time"
 
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/expression"
+
+	p "github.com/uber/cadence/common/persistence"
 	"github.com/uber/cadence/common/persistence/nosql/nosqlplugin"
 )
 
+const (
+	initialRangeID = 1 // Id of the first range of a new task list
+)
+
 // SelectTaskList returns a single tasklist row.
 // Return IsNotFoundError if the row doesn't exist
 func (db *ddb) SelectTaskList(ctx context.Context, filter *nosqlplugin.TaskListFilter) (*nosqlplugin.TaskListRow, error) {
-	panic("TODO")
+	it, err := db.getItem(ctx, db.tableName(tableTaskList), taskListKey(filter))
+	if err != nil {
+		return nil, err
+	}
+	return parseTaskListItem(it)
 }
 
 // InsertTaskList insert a single tasklist row
-// Return IsConditionFailedError if the row already exists, and also the existing row
+// Return TaskOperationConditionFailure if the row already exists
 func (db *ddb) InsertTaskList(ctx context.Context, row *nosqlplugin.TaskListRow) error {
-	panic("TODO")
+	newRow := *row
+	newRow.RangeID = initialRangeID
+	newRow.AckLevel = 0
+	it := newTaskListItem(&newRow, 0)
+
+	condition := expression.AttributeNotExists(expression.Name("task_list_key"))
+	err := db.putItem(ctx, db.tableName(tableTaskList), it, &condition)
+	return db.handleTaskListConditionError(ctx, row.DomainID, row.TaskListName, row.TaskListType, err)
 }
 
 // UpdateTaskList updates a single tasklist row
@@ -45,53 +67,258 @@ func (db *ddb) UpdateTaskList(
 	row *nosqlplugin.TaskListRow,
 	previousRangeID int64,
 ) error {
-	panic("TODO")
+	return db.updateTaskList(ctx, 0, row, previousRangeID)
 }
 
-// UpdateTaskList updates a single tasklist row, and set an TTL on the record
+// UpdateTaskListWithTTL updates a single tasklist row, and set an TTL on the record
 // Return TaskOperationConditionFailure if the condition doesn't meet
-// Ignore TTL if it's not supported, which becomes exactly the same as UpdateTaskList, but ListTaskList must be
-// implemented for TaskListScavenger
 func (db *ddb) UpdateTaskListWithTTL(
 	ctx context.Context,
 	ttlSeconds int64,
 	row *nosqlplugin.TaskListRow,
 	previousRangeID int64,
 ) error {
-	panic("TODO")
+	newRow := *row
+	newRow.LastUpdatedTime = time.Now()
+	return db.updateTaskList(ctx, ttlSeconds, &newRow, previousRangeID)
+}
+
+func (db *ddb) updateTaskList(
+	ctx context.Context,
+	ttlSeconds int64,
+	row *nosqlplugin.TaskListRow,
+	previousRangeID int64,
+) error {
+	condition := expression.Name("range_id").Equal(expression.Value(previousRangeID))
+	err := db.putItem(ctx, db.tableName(tableTaskList), newTaskListItem(row, ttlSeconds), &condition)
+	return db.handleTaskListConditionError(ctx, row.DomainID, row.TaskListName, row.TaskListType, err)
 }
 
 // ListTaskList returns all tasklists.
-// Noop if TTL is already implemented in other methods
+// The items expire by DynamoDB TTL, this is only used by the TaskListScavenger
 func (db *ddb) ListTaskList(ctx context.Context, pageSize int, nextPageToken []byte) (*nosqlplugin.ListTaskListResult, error) {
-	panic("TODO")
+	items, token, err := db.scanPage(ctx, db.tableName(tableTaskList), nil, pageSize, nextPageToken)
+	if err != nil {
+		return nil, err
+	}
+	result := &nosqlplugin.ListTaskListResult{
+		TaskLists:     make([]*nosqlplugin.TaskListRow, 0, len(items)),
+		NextPageToken: token,
+	}
+	for _, it := range items {
+		row, err := parseTaskListItem(it)
+		if err != nil {
+			return nil, err
+		}
+		result.TaskLists = append(result.TaskLists, row)
+	}
+	return result, nil
 }
 
 // DeleteTaskList deletes a single tasklist row
 // Return TaskOperationConditionFailure if the condition doesn't meet
 func (db *ddb) DeleteTaskList(ctx context.Context, filter *nosqlplugin.TaskListFilter, previousRangeID int64) error {
-	panic("TODO")
+	condition := expression.Name("range_id").Equal(expression.Value(previousRangeID))
+	expr, err := expression.NewBuilder().WithCondition(condition).Build()
+	if err != nil {
+		return err
+	}
+	_, err = db.client.DeleteItemWithContext(ctx, &dynamodb.DeleteItemInput{
+		TableName:                 aws.String(db.tableName(tableTaskList)),
+		Key:                       taskListKey(filter),
+		ConditionExpression:       expr.Condition(),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+	})
+	return db.handleTaskListConditionError(ctx, filter.DomainID, filter.TaskListName, filter.TaskListType, err)
 }
 
 // InsertTasks inserts a batch of tasks
 // Return TaskOperationConditionFailure if the condition doesn't meet
+// NOTE: a transaction is limited to maxTransactionItems items, so a large batch is split into multiple transactions,
+// each of them is conditioned on the range_id of the tasklist. This is safe because matching tolerates duplicated tasks.
 func (db *ddb) InsertTasks(
 	ctx context.Context,
 	tasksToInsert []*nosqlplugin.TaskRowForInsert,
 	tasklistCondition *nosqlplugin.TaskListRow,
 ) error {
-	panic("TODO")
+	filter := &nosqlplugin.TaskListFilter{
+		DomainID:     tasklistCondition.DomainID,
+		TaskListName: tasklistCondition.TaskListName,
+		TaskListType: tasklistCondition.TaskListType,
+	}
+	partition := taskListKeyValue(filter)
+	rangeCondition := expression.Name("range_id").Equal(expression.Value(tasklistCondition.RangeID))
+
+	// the range_id is still checked when there is no task to insert
+	for start := 0; start == 0 || start < len(tasksToInsert); start += maxTransactionItems - 1 {
+		end := start + maxTransactionItems - 1
+		if end > len(tasksToInsert) {
+			end = len(tasksToInsert)
+		}
+
+		txn := &transaction{}
+		for _, task := range tasksToInsert[start:end] {
+			it, err := newTaskItem(item{
+				"task_list_key": stringValue(partition),
+				"task_id":       numberValue(task.TaskID),
+			}, &task.TaskRow)
+			if err != nil {
+				return err
+			}
+			if task.TTLSeconds > 0 {
+				it[ttlAttribute] = ttlValue(int64(task.TTLSeconds))
+			}
+			if err := txn.put(conditionNone, db.tableName(tableTask), it, nil); err != nil {
+				return err
+			}
+		}
+		if err := txn.conditionCheck(conditionTaskList, db.tableName(tableTaskList), taskListKey(filter), rangeCondition); err != nil {
+			return err
+		}
+
+		failures, err := db.executeTransaction(ctx, txn)
+		if err != nil {
+			return err
+		}
+		if failures != nil {
+			return newTaskListConditionFailure(failures[conditionTaskList])
+		}
+	}
+	return nil
 }
 
 // SelectTasks return tasks that associated to a tasklist
 func (db *ddb) SelectTasks(ctx context.Context, filter *nosqlplugin.TasksFilter) ([]*nosqlplugin.TaskRow, error) {
-	panic("TODO")
+	input, ok, err := db.newTaskIDRangeQuery(
+		db.tableName(tableTask),
+		"task_list_key",
+		taskListKeyValue(&filter.TaskListFilter),
+		filter.MinTaskID,
+		filter.MaxTaskID,
+	)
+	if err != nil || !ok {
+		return nil, err
+	}
+	items, _, err := db.queryPage(ctx, input, filter.BatchSize, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	tasks := make([]*nosqlplugin.TaskRow, 0, len(items))
+	for _, it := range items {
+		task := &nosqlplugin.TaskRow{}
+		if err := getJSON(it, "data", task); err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, task)
+	}
+	return tasks, nil
 }
 
-// DeleteTask delete a batch tasks that taskIDs less than the row
-// If TTL is not implemented, then should also return the number of rows deleted, otherwise persistence.UnknownNumRowsAffected
-// NOTE: This API ignores the `BatchSize` request parameter i.e. either all tasks leq the task_id will be deleted or an error will
-// be returned to the caller, because rowsDeleted is not supported by Cassandra
+// RangeDeleteTasks delete a batch tasks that taskIDs within the range
+// NOTE: This API ignores the `BatchSize` request parameter i.e. either all tasks within the range will be deleted or an error will
+// be returned to the caller
 func (db *ddb) RangeDeleteTasks(ctx context.Context, filter *nosqlplugin.TasksFilter) (rowsDeleted int, err error) {
-	panic("TODO")
+	err = db.rangeDeleteTasksByTaskID(
+		ctx,
+		db.tableName(tableTask),
+		"task_list_key",
+		taskListKeyValue(&filter.TaskListFilter),
+		filter.MinTaskID,
+		filter.MaxTaskID,
+	)
+	return p.UnknownNumRowsAffected, err
+}
+
+// handleTaskListConditionError converts a failed condition of a single tasklist write into TaskOperationConditionFailure
+func (db *ddb) handleTaskListConditionError(ctx context.Context, domainID, taskListName string, taskListType int, err error) error {
+	if err == nil || !db.IsConditionFailedError(err) {
+		return err
+	}
+	it, readErr := db.getItem(ctx, db.tableName(tableTaskList), taskListKey(&nosqlplugin.TaskListFilter{
+		DomainID:     domainID,
+		TaskListName: taskListName,
+		TaskListType: taskListType,
+	}))
+	if readErr != nil && !db.IsNotFoundError(readErr) {
+		return readErr
+	}
+	return newTaskListConditionFailure(it)
+}
+
+func newTaskListConditionFailure(it item) error {
+	rangeID, err := getNumber(it, "range_id")
+	if err != nil {
+		return &nosqlplugin.TaskOperationConditionFailure{
+			RangeID: -1,
+			Details: "tasklist not found",
+		}
+	}
+	return &nosqlplugin.TaskOperationConditionFailure{
+		RangeID: rangeID,
+		Details: fmt.Sprintf("range_id=%v", rangeID),
+	}
+}
+
+func taskListKeyValue(filter *nosqlplugin.TaskListFilter) string {
+	return fmt.Sprintf("%s#%d#%s", filter.DomainID, filter.TaskListType, filter.TaskListName)
+}
+
+func taskListKey(filter *nosqlplugin.TaskListFilter) item {
+	return item{
+		"task_list_key": stringValue(taskListKeyValue(filter)),
+	}
+}
+
+func newTaskListItem(row *nosqlplugin.TaskListRow, ttlSeconds int64) item {
+	it := taskListKey(&nosqlplugin.TaskListFilter{
+		DomainID:     row.DomainID,
+		TaskListName: row.TaskListName,
+		TaskListType: row.TaskListType,
+	})
+	it["domain_id"] = stringValue(row.DomainID)
+	it["name"] = stringValue(row.TaskListName)
+	it["type"] = numberValue(int64(row.TaskListType))
+	it["range_id"] = numberValue(row.RangeID)
+	it["kind"] = numberValue(int64(row.TaskListKind))
+	it["ack_level"] = numberValue(row.AckLevel)
+	it["last_updated"] = numberValue(row.LastUpdatedTime.UnixNano())
+	if ttlSeconds > 0 {
+		it[ttlAttribute] = ttlValue(ttlSeconds)
+	}
+	return it
+}
+
+func parseTaskListItem(it item) (*nosqlplugin.TaskListRow, error) {
+	row := &nosqlplugin.TaskListRow{
+		DomainID:     getString(it, "domain_id"),
+		TaskListName: getString(it, "name"),
+	}
+	for name, target := range map[string]*int64{
+		"range_id":  &row.RangeID,
+		"ack_level": &row.AckLevel,
+	} {
+		v, err := getNumber(it, name)
+		if err != nil {
+			return nil, err
+		}
+		*target = v
+	}
+	taskListType, err := getNumber(it, "type")
+	if err != nil {
+		return nil, err
+	}
+	row.TaskListType = int(taskListType)
+	kind, err := getNumber(it, "kind")
+	if err != nil {
+		return nil, err
+	}
+	row.TaskListKind = int(kind)
+	lastUpdated, err := getNumber(it, "last_updated")
+	if err != nil {
+		return nil, err
+	}
+	row.LastUpdatedTime = time.Unix(0, lastUpdated)
+	return row, nil
 }
@@ -23,40 +23,71 @@ package tests
 import (
 	"testing"
 
+	"github.com/stretchr/testify/suite"
+
 	"github.com/uber/cadence/common/config"
 	"github.com/uber/cadence/common/persistence/nosql/nosqlplugin/dynamodb"
+	"github.com/uber/cadence/common/persistence/nosql/nosqlplugin/dynamodb/public"
+	persistencetests "github.com/uber/cadence/common/persistence/persistence-tests"
 )
 
 // This is to make sure adding new noop method when adding new nosql interfaces
-// Remove it when any other tests are implemented.
 func TestNoopStruct(t *testing.T) {
 	_, _ = dynamodb.NewDynamoDB(config.NoSQL{}, nil)
 }
 
 func TestDynamoDBHistoryPersistence(t *testing.T) {
-	//s := new(persistencetests.HistoryV2PersistenceSuite)
-	//s.TestBase = public.NewTestBaseWithDynamoDB(&persistencetests.TestBaseOptions{})
-	//s.TestBase.Setup()
-	//suite.Run(t, s)
+	s := new(persistencetests.HistoryV2PersistenceSuite)
+	s.TestBase = public.NewTestBaseWithDynamoDB(&persistencetests.TestBaseOptions{})
+	s.TestBase.Setup()
+	suite.Run(t, s)
 }
 
 func TestDynamoDBMatchingPersistence(t *testing.T) {
-	//s := new(persistencetests.MatchingPersistenceSuite)
-	//s.TestBase = public.NewTestBaseWithDynamoDB(&persistencetests.TestBaseOptions{})
-	//s.TestBase.Setup()
-	//suite.Run(t, s)
+	s := new(persistencetests.MatchingPersistenceSuite)
+	s.TestBase = public.NewTestBaseWithDynamoDB(&persistencetests.TestBaseOptions{})
+	s.TestBase.Setup()
+	suite.Run(t, s)
 }
 
 func TestDynamoDBDomainPersistence(t *testing.T) {
-	//s := new(persistencetests.MetadataPersistenceSuiteV2)
-	//s.TestBase = public.NewTestBaseWithDynamoDB(&persistencetests.TestBaseOptions{})
-	//s.TestBase.Setup()
-	//suite.Run(t, s)
+	s := new(persistencetests.MetadataPersistenceSuiteV2)
+	s.TestBase = public.NewTestBaseWithDynamoDB(&persistencetests.TestBaseOptions{})
+	s.TestBase.Setup()
+	suite.Run(t, s)
+}
+
+func TestDynamoDBQueuePersistence(t *testing.T) {
+	s := new(persistencetests.QueuePersistenceSuite)
+	s.TestBase = public.NewTestBaseWithDynamoDB(&persistencetests.TestBaseOptions{})
+	s.TestBase.Setup()
+	suite.Run(t, s)
+}
+
+func TestDynamoDBShardPersistence(t *testing.T) {
+	s := new(persistencetests.ShardPersistenceSuite)
+	s.TestBase = public.NewTestBaseWithDynamoDB(&persistencetests.TestBaseOptions{})
+	s.TestBase.Setup()
+	suite.Run(t, s)
+}
+
+func TestDynamoDBVisibilityPersistence(t *testing.T) {
+	s := new(persistencetests.DBVisibilityPersistenceSuite)
+	s.TestBase = public.NewTestBaseWithDynamoDB(&persistencetests.TestBaseOptions{})
+	s.TestBase.Setup()
+	suite.Run(t, s)
+}
+
+func TestDynamoDBExecutionManager(t *testing.T) {
+	s := new(persistencetests.ExecutionManagerSuite)
+	s.TestBase = public.NewTestBaseWithDynamoDB(&persistencetests.TestBaseOptions{})
+	s.TestBase.Setup()
+	suite.Run(t, s)
 }
 
-func TestQueuePersistence(t *testing.T) {
-	//s := new(persistencetests.QueuePersistenceSuite)
-	//s.TestBase = public.NewTestBaseWithDynamoDB(&persistencetests.TestBaseOptions{})
-	//s.TestBase.Setup()
-	//suite.Run(t, s)
+func TestDynamoDBConfigStorePersistence(t *testing.T) {
+	s := new(persistencetests.ConfigStorePersistenceSuite)
+	s.TestBase = public.NewTestBaseWithDynamoDB(&persistencetests.TestBaseOptions{})
+	s.TestBase.Setup()
+	suite.Run(t, s)
 }
@@ -0,0 +1,611 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dynamodb
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/expression"
+
+	p "github.com/uber/cadence/common/persistence"
+)
+
+const (
+	// maxTransactionItems is the max number of items DynamoDB accepts in a single TransactWriteItems call
+	maxTransactionItems = 100
+	// maxBatchWriteItems is the max number of items DynamoDB accepts in a single BatchWriteItem call
+	maxBatchWriteItems = 25
+	// unprocessedItemsRetryInterval is the interval between retries of unprocessed items of a batch write
+	unprocessedItemsRetryInterval = 50 * time.Millisecond
+	// maxItemSize is the max size in bytes DynamoDB accepts for a single item, including the attribute names
+	maxItemSize = 400 * 1024
+
+	cancellationReasonConditionalCheckFailed = "ConditionalCheckFailed"
+	cancellationReasonValidationError        = "ValidationError"
+)
+
+type (
+	item = map[string]*dynamodb.AttributeValue
+
+	// conditionKind identifies a conditional write within a transaction, so that a cancelled
+	// transaction can be mapped back to the condition(s) that failed
+	conditionKind int
+
+	// transaction accumulates the items of a TransactWriteItems call
+	transaction struct {
+		items []*dynamodb.TransactWriteItem
+		kinds []conditionKind
+	}
+
+	// taskWrite is a task item to write along with a workflow execution, with its key
+	taskWrite struct {
+		table string
+		key   item
+		item  item
+	}
+
+	// conditionFailures contains the conditions that failed in a cancelled transaction,
+	// with the existing item at the time of the failure, which can be nil if the item doesn't exist
+	conditionFailures map[conditionKind]item
+)
+
+const (
+	conditionNone conditionKind = iota
+	conditionShard
+	conditionCurrentWorkflow
+	conditionWorkflowExecution
+	conditionTaskList
+	conditionDomainID
+	conditionDomainName
+	conditionDomainMetadata
+)
+
+func (t *transaction) put(kind conditionKind, table string, it item, condition *expression.ConditionBuilder) error {
+	put := &dynamodb.Put{
+		TableName: aws.String(table),
+		Item:      it,
+	}
+	if condition != nil {
+		expr, err := expression.NewBuilder().WithCondition(*condition).Build()
+		if err != nil {
+			return err
+		}
+		put.ConditionExpression = expr.Condition()
+		put.ExpressionAttributeNames = expr.Names()
+		put.ExpressionAttributeValues = expr.Values()
+		put.ReturnValuesOnConditionCheckFailure = aws.String(dynamodb.ReturnValuesOnConditionCheckFailureAllOld)
+	}
+	t.add(kind, &dynamodb.TransactWriteItem{Put: put})
+	return nil
+}
+
+func (t *transaction) update(kind conditionKind, table string, key item, update expression.UpdateBuilder, condition *expression.ConditionBuilder) error {
+	builder := expression.NewBuilder().WithUpdate(update)
+	if condition != nil {
+		builder = builder.WithCondition(*condition)
+	}
+	expr, err := builder.Build()
+	if err != nil {
+		return err
+	}
+	u := &dynamodb.Update{
+		TableName:                 aws.String(table),
+		Key:                       key,
+		UpdateExpression:          expr.Update(),
+		ConditionExpression:       expr.Condition(),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+	}
+	if condition != nil {
+		u.ReturnValuesOnConditionCheckFailure = aws.String(dynamodb.ReturnValuesOnConditionCheckFailureAllOld)
+	}
+	t.add(kind, &dynamodb.TransactWriteItem{Update: u})
+	return nil
+}
+
+func (t *transaction) conditionCheck(kind conditionKind, table string, key item, condition expression.ConditionBuilder) error {
+	expr, err := expression.NewBuilder().WithCondition(condition).Build()
+	if err != nil {
+		return err
+	}
+	t.add(kind, &dynamodb.TransactWriteItem{
+		ConditionCheck: &dynamodb.ConditionCheck{
+			TableName:                           aws.String(table),
+			Key:                                 key,
+			ConditionExpression:                 expr.Condition(),
+			ExpressionAttributeNames:            expr.Names(),
+			ExpressionAttributeValues:           expr.Values(),
+			ReturnValuesOnConditionCheckFailure: aws.String(dynamodb.ReturnValuesOnConditionCheckFailureAllOld),
+		},
+	})
+	return nil
+}
+
+func (t *transaction) delete(kind conditionKind, table string, key item, condition *expression.ConditionBuilder) error {
+	d := &dynamodb.Delete{
+		TableName: aws.String(table),
+		Key:       key,
+	}
+	if condition != nil {
+		expr, err := expression.NewBuilder().WithCondition(*condition).Build()
+		if err != nil {
+			return err
+		}
+		d.ConditionExpression = expr.Condition()
+		d.ExpressionAttributeNames = expr.Names()
+		d.ExpressionAttributeValues = expr.Values()
+		d.ReturnValuesOnConditionCheckFailure = aws.String(dynamodb.ReturnValuesOnConditionCheckFailureAllOld)
+	}
+	t.add(kind, &dynamodb.TransactWriteItem{Delete: d})
+	return nil
+}
+
+func (t *transaction) add(kind conditionKind, writeItem *dynamodb.TransactWriteItem) {
+	t.items = append(t.items, writeItem)
+	t.kinds = append(t.kinds, kind)
+}
+
+// executeTransaction writes all the items of the transaction atomically.
+// If the transaction is cancelled because of condition check failures, the failed conditions are returned
+// with a nil error, otherwise the error is returned as is.
+func (db *ddb) executeTransaction(ctx context.Context, t *transaction) (conditionFailures, error) {
+	if len(t.items) == 0 {
+		return nil, nil
+	}
+	if len(t.items) > maxTransactionItems {
+		return nil, fmt.Errorf("transaction contains %v items, which exceeds the DynamoDB limit of %v items", len(t.items), maxTransactionItems)
+	}
+
+	_, err := db.client.TransactWriteItemsWithContext(ctx, &dynamodb.TransactWriteItemsInput{
+		TransactItems: t.items,
+	})
+	if err == nil {
+		return nil, nil
+	}
+
+	txErr, ok := err.(*dynamodb.TransactionCanceledException)
+	if !ok {
+		if isItemSizeError(err) {
+			return nil, newItemSizeLimitError(err.Error())
+		}
+		return nil, err
+	}
+	for _, reason := range txErr.CancellationReasons {
+		if reason != nil && aws.StringValue(reason.Code) == cancellationReasonValidationError &&
+			strings.Contains(aws.StringValue(reason.Message), "size") {
+			// an update growing an item over the DynamoDB limit would fail on every retry
+			return nil, newItemSizeLimitError(aws.StringValue(reason.Message))
+		}
+	}
+	failures := conditionFailures{}
+	for i, reason := range txErr.CancellationReasons {
+		if reason == nil || aws.StringValue(reason.Code) != cancellationReasonConditionalCheckFailed || i >= len(t.kinds) {
+			continue
+		}
+		failures[t.kinds[i]] = reason.Item
+	}
+	if len(failures) == 0 {
+		return nil, err
+	}
+	return failures, nil
+}
+
+func (f conditionFailures) has(kind conditionKind) bool {
+	_, ok := f[kind]
+	return ok
+}
+
+// queryAll runs the query and calls fn for every item across all the pages
+func (db *ddb) queryAll(ctx context.Context, input *dynamodb.QueryInput, fn func(it item) error) error {
+	for {
+		out, err := db.client.QueryWithContext(ctx, input)
+		if err != nil {
+			return err
+		}
+		for _, it := range out.Items {
+			if err := fn(it); err != nil {
+				return err
+			}
+		}
+		if len(out.LastEvaluatedKey) == 0 {
+			return nil
+		}
+		input.ExclusiveStartKey = out.LastEvaluatedKey
+	}
+}
+
+// count returns the number of items matching the query across all the pages
+func (db *ddb) count(ctx context.Context, input *dynamodb.QueryInput) (int64, error) {
+	input.Select = aws.String(dynamodb.SelectCount)
+	var total int64
+	for {
+		out, err := db.client.QueryWithContext(ctx, input)
+		if err != nil {
+			return 0, err
+		}
+		total += aws.Int64Value(out.Count)
+		if len(out.LastEvaluatedKey) == 0 {
+			return total, nil
+		}
+		input.ExclusiveStartKey = out.LastEvaluatedKey
+	}
+}
+
+// deleteByQuery deletes all the items returned by the query
+func (db *ddb) deleteByQuery(ctx context.Context, input *dynamodb.QueryInput, keyAttributes ...string) error {
+	var keys []item
+	err := db.queryAll(ctx, input, func(it item) error {
+		keys = append(keys, keyOf(it, keyAttributes...))
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return db.batchDelete(ctx, aws.StringValue(input.TableName), keys)
+}
+
+// batchDelete deletes the items of the keys, it is not atomic
+func (db *ddb) batchDelete(ctx context.Context, table string, keys []item) error {
+	requests := make([]*dynamodb.WriteRequest, 0, len(keys))
+	for _, key := range keys {
+		requests = append(requests, &dynamodb.WriteRequest{
+			DeleteRequest: &dynamodb.DeleteRequest{Key: key},
+		})
+	}
+	return db.batchWrite(ctx, table, requests)
+}
+
+// batchPut writes the items, it is not atomic
+func (db *ddb) batchPut(ctx context.Context, table string, items []item) error {
+	requests := make([]*dynamodb.WriteRequest, 0, len(items))
+	for _, it := range items {
+		requests = append(requests, &dynamodb.WriteRequest{
+			PutRequest: &dynamodb.PutRequest{Item: it},
+		})
+	}
+	return db.batchWrite(ctx, table, requests)
+}
+
+// batchWrite sends the write requests in batches of maxBatchWriteItems and retries the unprocessed ones
+func (db *ddb) batchWrite(ctx context.Context, table string, allRequests []*dynamodb.WriteRequest) error {
+	for start := 0; start < len(allRequests); start += maxBatchWriteItems {
+		end := start + maxBatchWriteItems
+		if end > len(allRequests) {
+			end = len(allRequests)
+		}
+		requests := allRequests[start:end]
+		for len(requests) > 0 {
+			out, err := db.client.BatchWriteItemWithContext(ctx, &dynamodb.BatchWriteItemInput{
+				RequestItems: map[string][]*dynamodb.WriteRequest{table: requests},
+			})
+			if err != nil {
+				return err
+			}
+			requests = out.UnprocessedItems[table]
+			if len(requests) > 0 {
+				select {
+				case <-ctx.Done():
+					return ctx.Err()
+				case <-time.After(unprocessedItemsRetryInterval):
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// getItem reads an item with strong consistency, and returns errItemNotFound if the item doesn't exist
+func (db *ddb) getItem(ctx context.Context, table string, key item) (item, error) {
+	out, err := db.client.GetItemWithContext(ctx, &dynamodb.GetItemInput{
+		TableName:      aws.String(table),
+		Key:            key,
+		ConsistentRead: aws.Bool(true),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(out.Item) == 0 {
+		return nil, errItemNotFound
+	}
+	return out.Item, nil
+}
+
+func (db *ddb) deleteItem(ctx context.Context, table string, key item) error {
+	_, err := db.client.DeleteItemWithContext(ctx, &dynamodb.DeleteItemInput{
+		TableName: aws.String(table),
+		Key:       key,
+	})
+	return err
+}
+
+func (db *ddb) putItem(ctx context.Context, table string, it item, condition *expression.ConditionBuilder) error {
+	input := &dynamodb.PutItemInput{
+		TableName: aws.String(table),
+		Item:      it,
+	}
+	if condition != nil {
+		expr, err := expression.NewBuilder().WithCondition(*condition).Build()
+		if err != nil {
+			return err
+		}
+		input.ConditionExpression = expr.Condition()
+		input.ExpressionAttributeNames = expr.Names()
+		input.ExpressionAttributeValues = expr.Values()
+	}
+	_, err := db.client.PutItemWithContext(ctx, input)
+	return err
+}
+
+func (db *ddb) updateItem(ctx context.Context, table string, key item, update expression.UpdateBuilder, condition *expression.ConditionBuilder) error {
+	builder := expression.NewBuilder().WithUpdate(update)
+	if condition != nil {
+		builder = builder.WithCondition(*condition)
+	}
+	expr, err := builder.Build()
+	if err != nil {
+		return err
+	}
+	_, err = db.client.UpdateItemWithContext(ctx, &dynamodb.UpdateItemInput{
+		TableName:                 aws.String(table),
+		Key:                       key,
+		UpdateExpression:          expr.Update(),
+		ConditionExpression:       expr.Condition(),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+	})
+	return err
+}
+
+// newQuery builds a strongly consistent query input
+func (db *ddb) newQuery(
+	table string,
+	index string,
+	keyCondition expression.KeyConditionBuilder,
+	filter *expression.ConditionBuilder,
+) (*dynamodb.QueryInput, error) {
+	builder := expression.NewBuilder().WithKeyCondition(keyCondition)
+	if filter != nil {
+		builder = builder.WithFilter(*filter)
+	}
+	expr, err := builder.Build()
+	if err != nil {
+		return nil, err
+	}
+	input := &dynamodb.QueryInput{
+		TableName:                 aws.String(table),
+		KeyConditionExpression:    expr.KeyCondition(),
+		FilterExpression:          expr.Filter(),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+		ConsistentRead:            aws.Bool(true),
+	}
+	if index != "" {
+		input.IndexName = aws.String(index)
+	}
+	return input, nil
+}
+
+// queryPage runs the query for a single page of results
+func (db *ddb) queryPage(
+	ctx context.Context,
+	input *dynamodb.QueryInput,
+	pageSize int,
+	pageToken []byte,
+) ([]item, []byte, error) {
+	startKey, err := deserializePageToken(pageToken)
+	if err != nil {
+		return nil, nil, err
+	}
+	input.ExclusiveStartKey = startKey
+	if pageSize > 0 {
+		input.Limit = aws.Int64(int64(pageSize))
+	}
+	out, err := db.client.QueryWithContext(ctx, input)
+	if err != nil {
+		return nil, nil, err
+	}
+	nextPageToken, err := serializePageToken(out.LastEvaluatedKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	return out.Items, nextPageToken, nil
+}
+
+// scanPage scans the table for a single page of results
+func (db *ddb) scanPage(
+	ctx context.Context,
+	table string,
+	filter *expression.ConditionBuilder,
+	pageSize int,
+	pageToken []byte,
+) ([]item, []byte, error) {
+	startKey, err := deserializePageToken(pageToken)
+	if err != nil {
+		return nil, nil, err
+	}
+	input := &dynamodb.ScanInput{
+		TableName:         aws.String(table),
+		ExclusiveStartKey: startKey,
+		ConsistentRead:    aws.Bool(true),
+	}
+	if pageSize > 0 {
+		input.Limit = aws.Int64(int64(pageSize))
+	}
+	if filter != nil {
+		expr, err := expression.NewBuilder().WithFilter(*filter).Build()
+		if err != nil {
+			return nil, nil, err
+		}
+		input.FilterExpression = expr.Filter()
+		input.ExpressionAttributeNames = expr.Names()
+		input.ExpressionAttributeValues = expr.Values()
+	}
+	out, err := db.client.ScanWithContext(ctx, input)
+	if err != nil {
+		return nil, nil, err
+	}
+	nextPageToken, err := serializePageToken(out.LastEvaluatedKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	return out.Items, nextPageToken, nil
+}
+
+func serializePageToken(lastEvaluatedKey item) ([]byte, error) {
+	if len(lastEvaluatedKey) == 0 {
+		return nil, nil
+	}
+	return json.Marshal(lastEvaluatedKey)
+}
+
+func deserializePageToken(pageToken []byte) (item, error) {
+	if len(pageToken) == 0 {
+		return nil, nil
+	}
+	var key item
+	if err := json.Unmarshal(pageToken, &key); err != nil {
+		return nil, fmt.Errorf("invalid page token: %v", err)
+	}
+	return key, nil
+}
+
+func keyOf(it item, keyAttributes ...string) item {
+	key := make(item, len(keyAttributes))
+	for _, name := range keyAttributes {
+		key[name] = it[name]
+	}
+	return key
+}
+
+func numberValue(v int64) *dynamodb.AttributeValue {
+	return &dynamodb.AttributeValue{N: aws.String(strconv.FormatInt(v, 10))}
+}
+
+func stringValue(v string) *dynamodb.AttributeValue {
+	return &dynamodb.AttributeValue{S: aws.String(v)}
+}
+
+func binaryValue(v []byte) *dynamodb.AttributeValue {
+	return &dynamodb.AttributeValue{B: v}
+}
+
+func jsonValue(v interface{}) (*dynamodb.AttributeValue, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return binaryValue(data), nil
+}
+
+func getNumber(it item, name string) (int64, error) {
+	v, ok := it[name]
+	if !ok || v == nil || v.N == nil {
+		return 0, fmt.Errorf("attribute %v is missing or not a number", name)
+	}
+	return strconv.ParseInt(*v.N, 10, 64)
+}
+
+func getString(it item, name string) string {
+	if v, ok := it[name]; ok && v != nil {
+		return aws.StringValue(v.S)
+	}
+	return ""
+}
+
+func getBinary(it item, name string) []byte {
+	if v, ok := it[name]; ok && v != nil {
+		return v.B
+	}
+	return nil
+}
+
+func getJSON(it item, name string, v interface{}) error {
+	data := getBinary(it, name)
+	if len(data) == 0 {
+		return fmt.Errorf("attribute %v is missing", name)
+	}
+	return json.Unmarshal(data, v)
+}
+
+// ttlValue returns the epoch seconds after which DynamoDB will expire the item
+func ttlValue(ttlSeconds int64) *dynamodb.AttributeValue {
+	return numberValue(time.Now().Unix() + ttlSeconds)
+}
+
+// isItemSizeError returns whether the write was rejected because an item exceeds maxItemSize
+func isItemSizeError(err error) bool {
+	aerr, ok := err.(awserr.Error)
+	return ok && aerr.Code() == "ValidationException" && strings.Contains(aerr.Message(), "Item size")
+}
+
+// newItemSizeLimitError returns the error of a write exceeding maxItemSize, the history service fails the workflow
+// on it instead of retrying a write that can never succeed
+func newItemSizeLimitError(msg string) error {
+	return &p.TransactionSizeLimitError{
+		Msg: fmt.Sprintf("item exceeds the DynamoDB limit of %v bytes: %v", maxItemSize, msg),
+	}
+}
+
+// itemSize returns the size of the item as computed by DynamoDB against maxItemSize
+func itemSize(it item) int {
+	size := 0
+	for name, value := range it {
+		size += len(name) + attributeValueSize(value)
+	}
+	return size
+}
+
+func attributeValueSize(v *dynamodb.AttributeValue) int {
+	switch {
+	case v == nil:
+		return 0
+	case v.S != nil:
+		return len(*v.S)
+	case v.N != nil:
+		return len(*v.N)/2 + 1
+	case v.B != nil:
+		return len(v.B)
+	case v.BOOL != nil, v.NULL != nil:
+		return 1
+	case v.M != nil:
+		// maps and lists have 3 bytes of overhead plus 1 byte per element
+		size := 3
+		for name, value := range v.M {
+			size += len(name) + attributeValueSize(value) + 1
+		}
+		return size
+	case v.L != nil:
+		size := 3
+		for _, value := range v.L {
+			size += attributeValueSize(value) + 1
+		}
+		return size
+	}
+	return 0
+}
@@ -22,16 +22,30 @@ package dynamodb
 
 import (
 	"context"
+	"fmt"
 
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb/expression"
+
+	"github.com/uber/cadence/common/persistence"
 	"github.com/uber/cadence/common/persistence/nosql/nosqlplugin"
 )
 
+// Each workflow run has a single visibility item, keyed by (domain_id, run_key).
+// The open and closed records are told apart by the existence of close_time,
+// so the close_time_index only contains the closed records.
+
+// InsertVisibility creates a new visibility record, return error is there is any.
 func (db *ddb) InsertVisibility(
 	ctx context.Context,
 	ttlSeconds int64,
 	row *nosqlplugin.VisibilityRowForInsert,
 ) error {
-	panic("TODO")
+	it, err := newVisibilityItem(row.DomainID, &row.VisibilityRow, false, ttlSeconds)
+	if err != nil {
+		return err
+	}
+	return db.putItem(ctx, db.tableName(tableVisibility), it, nil)
 }
 
 func (db *ddb) UpdateVisibility(
@@ -39,26 +53,161 @@ func (db *ddb) UpdateVisibility(
 	ttlSeconds int64,
 	row *nosqlplugin.VisibilityRowForUpdate,
 ) error {
-	panic("TODO")
+	if row.UpdateCloseToOpen {
+		// TODO implement it when where is a need
+		panic("not supported operation")
+	}
+
+	it, err := newVisibilityItem(row.DomainID, &row.VisibilityRow, true, ttlSeconds)
+	if err != nil {
+		return err
+	}
+	return db.putItem(ctx, db.tableName(tableVisibility), it, nil)
 }
 
 func (db *ddb) SelectVisibility(
 	ctx context.Context,
 	filter *nosqlplugin.VisibilityFilter,
 ) (*nosqlplugin.SelectVisibilityResponse, error) {
-	panic("TODO")
+	request := &filter.ListRequest
+
+	var isOpen bool
+	var extraFilter *expression.ConditionBuilder
+	switch filter.FilterType {
+	case nosqlplugin.AllOpen:
+		isOpen = true
+	case nosqlplugin.AllClosed:
+	case nosqlplugin.OpenByWorkflowType:
+		isOpen = true
+		extraFilter = conditionPtr(expression.Name("workflow_type_name").Equal(expression.Value(filter.WorkflowType)))
+	case nosqlplugin.ClosedByWorkflowType:
+		extraFilter = conditionPtr(expression.Name("workflow_type_name").Equal(expression.Value(filter.WorkflowType)))
+	case nosqlplugin.OpenByWorkflowID:
+		isOpen = true
+		extraFilter = conditionPtr(expression.Name("workflow_id").Equal(expression.Value(filter.WorkflowID)))
+	case nosqlplugin.ClosedByWorkflowID:
+		extraFilter = conditionPtr(expression.Name("workflow_id").Equal(expression.Value(filter.WorkflowID)))
+	case nosqlplugin.ClosedByClosedStatus:
+		extraFilter = conditionPtr(expression.Name("close_status").Equal(expression.Value(filter.CloseStatus)))
+	default:
+		panic("no supported filter type")
+	}
+
+	index := indexVisibilityStartTime
+	timeAttribute := "start_time"
+	var statusFilter expression.ConditionBuilder
+	if isOpen {
+		statusFilter = expression.AttributeNotExists(expression.Name("close_time"))
+	} else {
+		switch filter.SortType {
+		case nosqlplugin.SortByStartTime:
+			statusFilter = expression.AttributeExists(expression.Name("close_time"))
+		case nosqlplugin.SortByClosedTime:
+			// the close_time_index only contains the closed records
+			index = indexVisibilityCloseTime
+			timeAttribute = "close_time"
+			statusFilter = expression.AttributeExists(expression.Name("close_time"))
+		default:
+			panic("not supported sorting type")
+		}
+	}
+	if extraFilter != nil {
+		statusFilter = statusFilter.And(*extraFilter)
+	}
+
+	keyCondition := expression.KeyAnd(
+		expression.Key("domain_id").Equal(expression.Value(request.DomainUUID)),
+		expression.Key(timeAttribute).Between(
+			expression.Value(request.EarliestTime.UnixNano()),
+			expression.Value(request.LatestTime.UnixNano()),
+		),
+	)
+	input, err := db.newQuery(db.tableName(tableVisibility), index, keyCondition, &statusFilter)
+	if err != nil {
+		return nil, err
+	}
+	input.ScanIndexForward = aws.Bool(false)
+
+	items, nextPageToken, err := db.queryPage(ctx, input, request.PageSize, request.NextPageToken)
+	if err != nil {
+		return nil, err
+	}
+	response := &nosqlplugin.SelectVisibilityResponse{
+		Executions:    make([]*persistence.InternalVisibilityWorkflowExecutionInfo, 0, len(items)),
+		NextPageToken: nextPageToken,
+	}
+	for _, it := range items {
+		row, err := parseVisibilityItem(it)
+		if err != nil {
+			return nil, err
+		}
+		response.Executions = append(response.Executions, row)
+	}
+	return response, nil
 }
 
 func (db *ddb) DeleteVisibility(
 	ctx context.Context,
 	domainID, workflowID, runID string,
 ) error {
-	panic("TODO")
+	return db.deleteItem(ctx, db.tableName(tableVisibility), visibilityKey(domainID, workflowID, runID))
 }
 
 func (db *ddb) SelectOneClosedWorkflow(
 	ctx context.Context,
 	domainID, workflowID, runID string,
 ) (*nosqlplugin.VisibilityRow, error) {
-	panic("TODO")
+	it, err := db.getItem(ctx, db.tableName(tableVisibility), visibilityKey(domainID, workflowID, runID))
+	if err != nil {
+		if db.IsNotFoundError(err) {
+			// Special case: return nil,nil if not found(since we will deprecate it, it's not worth refactor to be consistent)
+			return nil, nil
+		}
+		return nil, err
+	}
+	if _, ok := it["close_time"]; !ok {
+		return nil, nil
+	}
+	return parseVisibilityItem(it)
+}
+
+func conditionPtr(condition expression.ConditionBuilder) *expression.ConditionBuilder {
+	return &condition
+}
+
+func visibilityKey(domainID, workflowID, runID string) item {
+	return item{
+		"domain_id": stringValue(domainID),
+		"run_key":   stringValue(fmt.Sprintf("%s#%s", workflowID, runID)),
+	}
+}
+
+func newVisibilityItem(domainID string, row *nosqlplugin.VisibilityRow, closed bool, ttlSeconds int64) (item, error) {
+	data, err := jsonValue(row)
+	if err != nil {
+		return nil, err
+	}
+	it := visibilityKey(domainID, row.WorkflowID, row.RunID)
+	it["workflow_id"] = stringValue(row.WorkflowID)
+	it["workflow_type_name"] = stringValue(row.TypeName)
+	it["start_time"] = numberValue(row.StartTime.UnixNano())
+	it["data"] = data
+	if closed {
+		it["close_time"] = numberValue(row.CloseTime.UnixNano())
+		if row.Status != nil {
+			it["close_status"] = numberValue(int64(*row.Status))
+		}
+	}
+	if ttlSeconds > 0 {
+		it[ttlAttribute] = ttlValue(ttlSeconds)
+	}
+	return it, nil
+}
+
+func parseVisibilityItem(it item) (*nosqlplugin.VisibilityRow, error) {
+	row := &nosqlplugin.VisibilityRow{}
+	if err := getJSON(it, "data", row); err != nil {
+		return nil, err
+	}
+	return row, nil
 }
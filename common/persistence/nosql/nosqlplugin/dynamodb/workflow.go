@@ -18,13 +18,19 @@
 // LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
 // OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
 // THE SOFTWARE.
-
 package dynamodb
 
 import (
 	"context"
+	"fmt"
+	"math"
 	"time"
 
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/expression"
+
+	"github.com/uber/cadence/common/log/tag"
 	"github.com/uber/cadence/common/persistence"
 	"github.com/uber/cadence/common/persistence/nosql/nosqlplugin"
 )
@@ -41,7 +47,36 @@ func (db *ddb) InsertWorkflowExecutionWithTasks(
 	timerTasks []*nosqlplugin.TimerTask,
 	shardCondition *nosqlplugin.ShardCondition,
 ) error {
-	panic("TODO")
+	shardID := shardCondition.ShardID
+	tasks, err := db.newTaskWrites(shardID, transferTasks, crossClusterTasks, replicationTasks, timerTasks)
+	if err != nil {
+		return err
+	}
+
+	txn := &transaction{}
+	err = db.createOrUpdateCurrentWorkflow(txn, shardID, execution.DomainID, execution.WorkflowID, currentWorkflowRequest)
+	if err != nil {
+		return err
+	}
+
+	err = db.createWorkflowExecution(txn, conditionWorkflowExecution, shardID, execution)
+	if err != nil {
+		return err
+	}
+
+	err = db.assertShardRangeID(txn, shardID, shardCondition.RangeID)
+	if err != nil {
+		return err
+	}
+
+	failures, err := db.executeTransactionWithTasks(ctx, txn, shardCondition, tasks)
+	if err != nil {
+		return err
+	}
+	if failures != nil {
+		return db.convertCreateWorkflowConditionFailures(failures, currentWorkflowRequest, execution, shardCondition)
+	}
+	return nil
 }
 
 func (db *ddb) UpdateWorkflowExecutionWithTasks(
@@ -56,105 +91,510 @@ func (db *ddb) UpdateWorkflowExecutionWithTasks(
 	timerTasks []*nosqlplugin.TimerTask,
 	shardCondition *nosqlplugin.ShardCondition,
 ) error {
-	panic("TODO")
+	shardID := shardCondition.ShardID
+	var domainID, workflowID string
+	var previousNextEventIDCondition int64
+	if mutatedExecution != nil {
+		domainID = mutatedExecution.DomainID
+		workflowID = mutatedExecution.WorkflowID
+		previousNextEventIDCondition = *mutatedExecution.PreviousNextEventIDCondition
+	} else if resetExecution != nil {
+		domainID = resetExecution.DomainID
+		workflowID = resetExecution.WorkflowID
+		previousNextEventIDCondition = *resetExecution.PreviousNextEventIDCondition
+	} else {
+		return fmt.Errorf("at least one of mutatedExecution and resetExecution should be provided")
+	}
+
+	tasks, err := db.newTaskWrites(shardID, transferTasks, crossClusterTasks, replicationTasks, timerTasks)
+	if err != nil {
+		return err
+	}
+
+	txn := &transaction{}
+	err = db.createOrUpdateCurrentWorkflow(txn, shardID, domainID, workflowID, currentWorkflowRequest)
+	if err != nil {
+		return err
+	}
+
+	if mutatedExecution != nil {
+		err = db.updateWorkflowExecution(txn, shardID, mutatedExecution)
+		if err != nil {
+			return err
+		}
+	}
+
+	if insertedExecution != nil {
+		err = db.createWorkflowExecution(txn, conditionNone, shardID, insertedExecution)
+		if err != nil {
+			return err
+		}
+	}
+
+	if resetExecution != nil {
+		err = db.resetWorkflowExecution(txn, shardID, resetExecution)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = db.assertShardRangeID(txn, shardID, shardCondition.RangeID)
+	if err != nil {
+		return err
+	}
+
+	failures, err := db.executeTransactionWithTasks(ctx, txn, shardCondition, tasks)
+	if err != nil {
+		return err
+	}
+	if failures != nil {
+		return db.convertUpdateWorkflowConditionFailures(failures, currentWorkflowRequest, previousNextEventIDCondition, shardCondition)
+	}
+	return nil
+}
+
+// executeTransactionWithTasks writes the tasks within the transaction of the execution, which checks the shard range ID.
+// A transaction is limited to maxTransactionItems items and a single decision can generate more tasks than that. The
+// tasks that don't fit are written first, in transactions that check the shard range ID as well, so a stale shard
+// owner never writes any task. If a condition of the transaction of the execution fails, those tasks are deleted, so
+// no task is left behind for a state that was never committed.
+func (db *ddb) executeTransactionWithTasks(
+	ctx context.Context,
+	txn *transaction,
+	shardCondition *nosqlplugin.ShardCondition,
+	tasks []*taskWrite,
+) (conditionFailures, error) {
+	inTransaction := maxTransactionItems - len(txn.items)
+	if inTransaction > len(tasks) {
+		inTransaction = len(tasks)
+	}
+	for _, task := range tasks[:inTransaction] {
+		if err := txn.put(conditionNone, task.table, task.item, nil); err != nil {
+			return nil, err
+		}
+	}
+
+	overflow := tasks[inTransaction:]
+	for start := 0; start < len(overflow); start += maxTransactionItems - 1 {
+		end := start + maxTransactionItems - 1
+		if end > len(overflow) {
+			end = len(overflow)
+		}
+		overflowTxn := &transaction{}
+		for _, task := range overflow[start:end] {
+			if err := overflowTxn.put(conditionNone, task.table, task.item, nil); err != nil {
+				return nil, err
+			}
+		}
+		if err := db.assertShardRangeID(overflowTxn, shardCondition.ShardID, shardCondition.RangeID); err != nil {
+			return nil, err
+		}
+		failures, err := db.executeTransaction(ctx, overflowTxn)
+		if failures != nil {
+			db.deleteTasks(ctx, overflow[:start])
+		}
+		if err != nil || failures != nil {
+			return failures, err
+		}
+	}
+
+	failures, err := db.executeTransaction(ctx, txn)
+	if failures != nil {
+		db.deleteTasks(ctx, overflow)
+	}
+	return failures, err
+}
+
+// deleteTasks deletes the tasks written ahead of a transaction that failed its conditions.
+// Only a failed condition is certain to leave nothing committed, any other error can come from a committed transaction.
+func (db *ddb) deleteTasks(ctx context.Context, tasks []*taskWrite) {
+	keysByTable := make(map[string][]item)
+	for _, task := range tasks {
+		keysByTable[task.table] = append(keysByTable[task.table], task.key)
+	}
+	for table, keys := range keysByTable {
+		if err := db.batchDelete(ctx, table, keys); err != nil {
+			db.logger.Warn("Failed to delete the tasks of a failed workflow transaction.", tag.Error(err))
+		}
+	}
 }
 
 func (db *ddb) SelectCurrentWorkflow(ctx context.Context, shardID int, domainID, workflowID string) (*nosqlplugin.CurrentWorkflowRow, error) {
-	panic("TODO")
+	it, err := db.getItem(ctx, db.tableName(tableCurrentWorkflow), currentWorkflowKey(shardID, domainID, workflowID))
+	if err != nil {
+		return nil, err
+	}
+	return parseCurrentWorkflowItem(shardID, it)
 }
 
 func (db *ddb) SelectWorkflowExecution(ctx context.Context, shardID int, domainID, workflowID, runID string) (*nosqlplugin.WorkflowExecution, error) {
-	panic("TODO")
+	it, err := db.getItem(ctx, db.tableName(tableWorkflowExecution), workflowExecutionKey(shardID, domainID, workflowID, runID))
+	if err != nil {
+		return nil, err
+	}
+	return parseWorkflowExecutionItem(it)
 }
 
 func (db *ddb) DeleteCurrentWorkflow(ctx context.Context, shardID int, domainID, workflowID, currentRunIDCondition string) error {
-	panic("TODO")
+	condition := expression.Name("run_id").Equal(expression.Value(currentRunIDCondition))
+	expr, err := expression.NewBuilder().WithCondition(condition).Build()
+	if err != nil {
+		return err
+	}
+	_, err = db.client.DeleteItemWithContext(ctx, &dynamodb.DeleteItemInput{
+		TableName:                 aws.String(db.tableName(tableCurrentWorkflow)),
+		Key:                       currentWorkflowKey(shardID, domainID, workflowID),
+		ConditionExpression:       expr.Condition(),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+	})
+	if db.IsConditionFailedError(err) {
+		// the current workflow has moved on to another run, which must not be deleted
+		return nil
+	}
+	return err
 }
 
 func (db *ddb) DeleteWorkflowExecution(ctx context.Context, shardID int, domainID, workflowID, runID string) error {
-	panic("TODO")
+	return db.deleteItem(ctx, db.tableName(tableWorkflowExecution), workflowExecutionKey(shardID, domainID, workflowID, runID))
 }
 
 func (db *ddb) SelectAllCurrentWorkflows(ctx context.Context, shardID int, pageToken []byte, pageSize int) ([]*persistence.CurrentWorkflowExecution, []byte, error) {
-	panic("TODO")
+	input, err := db.newQuery(
+		db.tableName(tableCurrentWorkflow),
+		"",
+		expression.Key("shard_id").Equal(expression.Value(shardID)),
+		nil,
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+	items, nextPageToken, err := db.queryPage(ctx, input, pageSize, pageToken)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	executions := make([]*persistence.CurrentWorkflowExecution, 0, len(items))
+	for _, it := range items {
+		row, err := parseCurrentWorkflowItem(shardID, it)
+		if err != nil {
+			return nil, nil, err
+		}
+		executions = append(executions, &persistence.CurrentWorkflowExecution{
+			DomainID:     row.DomainID,
+			WorkflowID:   row.WorkflowID,
+			RunID:        permanentRunID,
+			State:        row.State,
+			CurrentRunID: row.RunID,
+		})
+	}
+	return executions, nextPageToken, nil
 }
 
 func (db *ddb) SelectAllWorkflowExecutions(ctx context.Context, shardID int, pageToken []byte, pageSize int) ([]*persistence.InternalListConcreteExecutionsEntity, []byte, error) {
-	panic("TODO")
+	input, err := db.newQuery(
+		db.tableName(tableWorkflowExecution),
+		"",
+		expression.Key("shard_id").Equal(expression.Value(shardID)),
+		nil,
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+	items, nextPageToken, err := db.queryPage(ctx, input, pageSize, pageToken)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	executions := make([]*persistence.InternalListConcreteExecutionsEntity, 0, len(items))
+	for _, it := range items {
+		entity := &persistence.InternalListConcreteExecutionsEntity{
+			ExecutionInfo: &persistence.InternalWorkflowExecutionInfo{},
+		}
+		if err := getJSON(it, "execution", entity.ExecutionInfo); err != nil {
+			return nil, nil, err
+		}
+		if err := getJSON(it, "version_histories", &entity.VersionHistories); err != nil {
+			return nil, nil, err
+		}
+		executions = append(executions, entity)
+	}
+	return executions, nextPageToken, nil
 }
 
 func (db *ddb) IsWorkflowExecutionExists(ctx context.Context, shardID int, domainID, workflowID, runID string) (bool, error) {
-	panic("TODO")
+	_, err := db.getItem(ctx, db.tableName(tableWorkflowExecution), workflowExecutionKey(shardID, domainID, workflowID, runID))
+	if err != nil {
+		if db.IsNotFoundError(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
 }
 
 func (db *ddb) SelectTransferTasksOrderByTaskID(ctx context.Context, shardID, pageSize int, pageToken []byte, exclusiveMinTaskID, inclusiveMaxTaskID int64) ([]*nosqlplugin.TransferTask, []byte, error) {
-	panic("TODO")
+	items, nextPageToken, err := db.selectTasksByTaskID(ctx, db.tableName(tableTransferTask), "shard_id", shardID, pageSize, pageToken, exclusiveMinTaskID, inclusiveMaxTaskID)
+	if err != nil {
+		return nil, nil, err
+	}
+	tasks := make([]*nosqlplugin.TransferTask, 0, len(items))
+	for _, it := range items {
+		task := &nosqlplugin.TransferTask{}
+		if err := getJSON(it, "data", task); err != nil {
+			return nil, nil, err
+		}
+		tasks = append(tasks, task)
+	}
+	return tasks, nextPageToken, nil
 }
 
 func (db *ddb) DeleteTransferTask(ctx context.Context, shardID int, taskID int64) error {
-	panic("TODO")
+	return db.deleteItem(ctx, db.tableName(tableTransferTask), shardIDKey(shardID, taskID))
 }
 
 func (db *ddb) RangeDeleteTransferTasks(ctx context.Context, shardID int, exclusiveBeginTaskID, inclusiveEndTaskID int64) error {
-	panic("TODO")
+	return db.rangeDeleteTasksByTaskID(ctx, db.tableName(tableTransferTask), "shard_id", shardID, exclusiveBeginTaskID, inclusiveEndTaskID)
 }
 
 func (db *ddb) SelectTimerTasksOrderByVisibilityTime(ctx context.Context, shardID, pageSize int, pageToken []byte, inclusiveMinTime, exclusiveMaxTime time.Time) ([]*nosqlplugin.TimerTask, []byte, error) {
-	panic("TODO")
+	input, ok, err := db.newTimerTasksQuery(shardID, inclusiveMinTime, exclusiveMaxTime)
+	if err != nil || !ok {
+		return nil, nil, err
+	}
+	items, nextPageToken, err := db.queryPage(ctx, input, pageSize, pageToken)
+	if err != nil {
+		return nil, nil, err
+	}
+	tasks := make([]*nosqlplugin.TimerTask, 0, len(items))
+	for _, it := range items {
+		task := &nosqlplugin.TimerTask{}
+		if err := getJSON(it, "data", task); err != nil {
+			return nil, nil, err
+		}
+		tasks = append(tasks, task)
+	}
+	return tasks, nextPageToken, nil
 }
 
 func (db *ddb) DeleteTimerTask(ctx context.Context, shardID int, taskID int64, visibilityTimestamp time.Time) error {
-	panic("TODO")
+	return db.deleteItem(ctx, db.tableName(tableTimerTask), timerTaskKey(shardID, visibilityTimestamp, taskID))
 }
 
 func (db *ddb) RangeDeleteTimerTasks(ctx context.Context, shardID int, inclusiveMinTime, exclusiveMaxTime time.Time) error {
-	panic("TODO")
+	input, ok, err := db.newTimerTasksQuery(shardID, inclusiveMinTime, exclusiveMaxTime)
+	if err != nil || !ok {
+		return err
+	}
+	return db.deleteByQuery(ctx, input, "shard_id", "timer_key")
+}
+
+// newTimerTasksQuery returns a query of the timers within [inclusiveMinTime, exclusiveMaxTime),
+// or false if the range is empty
+func (db *ddb) newTimerTasksQuery(shardID int, inclusiveMinTime, exclusiveMaxTime time.Time) (*dynamodb.QueryInput, bool, error) {
+	minTimestamp := toUnixNano(inclusiveMinTime)
+	maxTimestamp := toUnixNano(exclusiveMaxTime)
+	if maxTimestamp <= minTimestamp {
+		return nil, false, nil
+	}
+	keyCondition := expression.KeyAnd(
+		expression.Key("shard_id").Equal(expression.Value(shardID)),
+		expression.Key("timer_key").Between(
+			expression.Value(timerKeyValue(time.Unix(0, minTimestamp), 0)),
+			expression.Value(timerKeyValue(time.Unix(0, maxTimestamp-1), math.MaxInt64)),
+		),
+	)
+	input, err := db.newQuery(db.tableName(tableTimerTask), "", keyCondition, nil)
+	if err != nil {
+		return nil, false, err
+	}
+	return input, true, nil
 }
 
 func (db *ddb) SelectReplicationTasksOrderByTaskID(ctx context.Context, shardID, pageSize int, pageToken []byte, exclusiveMinTaskID, inclusiveMaxTaskID int64) ([]*nosqlplugin.ReplicationTask, []byte, error) {
-	panic("TODO")
+	items, nextPageToken, err := db.selectTasksByTaskID(ctx, db.tableName(tableReplicationTask), "shard_id", shardID, pageSize, pageToken, exclusiveMinTaskID, inclusiveMaxTaskID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return parseReplicationTaskItems(items, nextPageToken)
 }
 
 func (db *ddb) DeleteReplicationTask(ctx context.Context, shardID int, taskID int64) error {
-	panic("TODO")
+	return db.deleteItem(ctx, db.tableName(tableReplicationTask), shardIDKey(shardID, taskID))
 }
 
 func (db *ddb) RangeDeleteReplicationTasks(ctx context.Context, shardID int, inclusiveEndTaskID int64) error {
-	panic("TODO")
+	return db.rangeDeleteTasksByTaskID(ctx, db.tableName(tableReplicationTask), "shard_id", shardID, math.MinInt64, inclusiveEndTaskID)
 }
 
-func (db *ddb) InsertReplicationTask(ctx context.Context, tasks []*nosqlplugin.ReplicationTask, condition nosqlplugin.ShardCondition) error {
-	panic("TODO")
+func (db *ddb) InsertReplicationTask(ctx context.Context, tasks []*nosqlplugin.ReplicationTask, shardCondition nosqlplugin.ShardCondition) error {
+	if len(tasks) == 0 {
+		return nil
+	}
+
+	shardID := shardCondition.ShardID
+	txn := &transaction{}
+	for _, task := range tasks {
+		it, err := newTaskItem(shardIDKey(shardID, task.TaskID), task)
+		if err != nil {
+			return err
+		}
+		if err := txn.put(conditionNone, db.tableName(tableReplicationTask), it, nil); err != nil {
+			return err
+		}
+	}
+	if err := db.assertShardRangeID(txn, shardID, shardCondition.RangeID); err != nil {
+		return err
+	}
+
+	failures, err := db.executeTransaction(ctx, txn)
+	if err != nil {
+		return err
+	}
+	if failures != nil {
+		actualRangeID := int64(-1)
+		if failures.has(conditionShard) {
+			actualRangeID = rangeIDOf(failures[conditionShard])
+		}
+		return &nosqlplugin.ShardOperationConditionFailure{
+			RangeID: actualRangeID,
+			Details: fmt.Sprintf("Failed to insert replication tasks. ShardID: %v, request_range_id: %v, actual_range_id: %v",
+				shardID, shardCondition.RangeID, actualRangeID),
+		}
+	}
+	return nil
 }
 
 func (db *ddb) SelectCrossClusterTasksOrderByTaskID(ctx context.Context, shardID, pageSize int, pageToken []byte, targetCluster string, exclusiveMinTaskID, inclusiveMaxTaskID int64) ([]*nosqlplugin.CrossClusterTask, []byte, error) {
-	panic("TODO")
+	items, nextPageToken, err := db.selectTasksByTaskID(ctx, db.tableName(tableCrossClusterTask), "shard_cluster", shardClusterValue(shardID, targetCluster), pageSize, pageToken, exclusiveMinTaskID, inclusiveMaxTaskID)
+	if err != nil {
+		return nil, nil, err
+	}
+	tasks := make([]*nosqlplugin.CrossClusterTask, 0, len(items))
+	for _, it := range items {
+		task := &nosqlplugin.CrossClusterTask{
+			TargetCluster: targetCluster,
+		}
+		if err := getJSON(it, "data", &task.TransferTask); err != nil {
+			return nil, nil, err
+		}
+		tasks = append(tasks, task)
+	}
+	return tasks, nextPageToken, nil
 }
 
 func (db *ddb) DeleteCrossClusterTask(ctx context.Context, shardID int, targetCluster string, taskID int64) error {
-	panic("TODO")
+	return db.deleteItem(ctx, db.tableName(tableCrossClusterTask), shardClusterKey(shardID, targetCluster, taskID))
 }
 
 func (db *ddb) RangeDeleteCrossClusterTasks(ctx context.Context, shardID int, targetCluster string, exclusiveBeginTaskID, inclusiveEndTaskID int64) error {
-	panic("TODO")
+	return db.rangeDeleteTasksByTaskID(ctx, db.tableName(tableCrossClusterTask), "shard_cluster", shardClusterValue(shardID, targetCluster), exclusiveBeginTaskID, inclusiveEndTaskID)
 }
 
 func (db *ddb) InsertReplicationDLQTask(ctx context.Context, shardID int, sourceCluster string, task nosqlplugin.ReplicationTask) error {
-	panic("TODO")
+	it, err := newTaskItem(shardClusterKey(shardID, sourceCluster, task.TaskID), &task)
+	if err != nil {
+		return err
+	}
+	return db.putItem(ctx, db.tableName(tableReplicationDLQTask), it, nil)
 }
 
 func (db *ddb) SelectReplicationDLQTasksOrderByTaskID(ctx context.Context, shardID int, sourceCluster string, pageSize int, pageToken []byte, exclusiveMinTaskID, inclusiveMaxTaskID int64) ([]*nosqlplugin.ReplicationTask, []byte, error) {
-	panic("TODO")
+	items, nextPageToken, err := db.selectTasksByTaskID(ctx, db.tableName(tableReplicationDLQTask), "shard_cluster", shardClusterValue(shardID, sourceCluster), pageSize, pageToken, exclusiveMinTaskID, inclusiveMaxTaskID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return parseReplicationTaskItems(items, nextPageToken)
 }
 
 func (db *ddb) SelectReplicationDLQTasksCount(ctx context.Context, shardID int, sourceCluster string) (int64, error) {
-	panic("TODO")
+	input, err := db.newQuery(
+		db.tableName(tableReplicationDLQTask),
+		"",
+		expression.Key("shard_cluster").Equal(expression.Value(shardClusterValue(shardID, sourceCluster))),
+		nil,
+	)
+	if err != nil {
+		return -1, err
+	}
+	count, err := db.count(ctx, input)
+	if err != nil {
+		return -1, err
+	}
+	return count, nil
 }
 
 func (db *ddb) DeleteReplicationDLQTask(ctx context.Context, shardID int, sourceCluster string, taskID int64) error {
-	panic("TODO")
+	return db.deleteItem(ctx, db.tableName(tableReplicationDLQTask), shardClusterKey(shardID, sourceCluster, taskID))
 }
 
 func (db *ddb) RangeDeleteReplicationDLQTasks(ctx context.Context, shardID int, sourceCluster string, exclusiveBeginTaskID, inclusiveEndTaskID int64) error {
-	panic("TODO")
+	return db.rangeDeleteTasksByTaskID(ctx, db.tableName(tableReplicationDLQTask), "shard_cluster", shardClusterValue(shardID, sourceCluster), exclusiveBeginTaskID, inclusiveEndTaskID)
+}
+
+// newTaskIDRangeQuery returns a query of the tasks of a partition within (exclusiveMinTaskID, inclusiveMaxTaskID],
+// or false if the range is empty
+func (db *ddb) newTaskIDRangeQuery(
+	table string,
+	partitionKey string,
+	partition interface{},
+	exclusiveMinTaskID int64,
+	inclusiveMaxTaskID int64,
+) (*dynamodb.QueryInput, bool, error) {
+	if exclusiveMinTaskID >= inclusiveMaxTaskID {
+		return nil, false, nil
+	}
+	keyCondition := expression.KeyAnd(
+		expression.Key(partitionKey).Equal(expression.Value(partition)),
+		expression.Key("task_id").Between(expression.Value(exclusiveMinTaskID+1), expression.Value(inclusiveMaxTaskID)),
+	)
+	input, err := db.newQuery(table, "", keyCondition, nil)
+	if err != nil {
+		return nil, false, err
+	}
+	return input, true, nil
+}
+
+func (db *ddb) selectTasksByTaskID(
+	ctx context.Context,
+	table string,
+	partitionKey string,
+	partition interface{},
+	pageSize int,
+	pageToken []byte,
+	exclusiveMinTaskID int64,
+	inclusiveMaxTaskID int64,
+) ([]item, []byte, error) {
+	input, ok, err := db.newTaskIDRangeQuery(table, partitionKey, partition, exclusiveMinTaskID, inclusiveMaxTaskID)
+	if err != nil || !ok {
+		return nil, nil, err
+	}
+	return db.queryPage(ctx, input, pageSize, pageToken)
+}
+
+func (db *ddb) rangeDeleteTasksByTaskID(
+	ctx context.Context,
+	table string,
+	partitionKey string,
+	partition interface{},
+	exclusiveMinTaskID int64,
+	inclusiveMaxTaskID int64,
+) error {
+	input, ok, err := db.newTaskIDRangeQuery(table, partitionKey, partition, exclusiveMinTaskID, inclusiveMaxTaskID)
+	if err != nil || !ok {
+		return err
+	}
+	return db.deleteByQuery(ctx, input, partitionKey, "task_id")
+}
+
+func parseReplicationTaskItems(items []item, nextPageToken []byte) ([]*nosqlplugin.ReplicationTask, []byte, error) {
+	tasks := make([]*nosqlplugin.ReplicationTask, 0, len(items))
+	for _, it := range items {
+		task := &nosqlplugin.ReplicationTask{}
+		if err := getJSON(it, "data", task); err != nil {
+			return nil, nil, err
+		}
+		tasks = append(tasks, task)
+	}
+	return tasks, nextPageToken, nil
 }
@@ -0,0 +1,785 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dynamodb
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/aws/aws-sdk-go/service/dynamodb/expression"
+
+	"github.com/uber/cadence/common"
+	"github.com/uber/cadence/common/checksum"
+	p "github.com/uber/cadence/common/persistence"
+	"github.com/uber/cadence/common/persistence/nosql/nosqlplugin"
+)
+
+// The maps of a workflow execution are stored as DynamoDB map attributes of the execution item,
+// so that a single entry can be upserted or removed without reading the item.
+// The whole mutable state is therefore bound to maxItemSize, a write over it returns a TransactionSizeLimitError
+// so that the history service terminates the workflow instead of retrying the write forever.
+const (
+	activityMapAttribute        = "activity_map"
+	timerMapAttribute           = "timer_map"
+	childExecutionMapAttribute  = "child_execution_map"
+	requestCancelMapAttribute   = "request_cancel_map"
+	signalMapAttribute          = "signal_map"
+	signalRequestedMapAttribute = "signal_requested_map"
+	bufferedEventsAttribute     = "buffered_events"
+)
+
+// permanentRunID is the run ID reported for the current workflow records, same as the cassandra plugin
+const permanentRunID = "30000000-0000-f000-f000-000000000001"
+
+var maxUnixNanoTime = time.Unix(0, math.MaxInt64)
+
+// rawValue makes an AttributeValue usable in expressions without being marshaled again,
+// since the default marshaler turns empty lists and maps into NULL
+type rawValue struct {
+	av *dynamodb.AttributeValue
+}
+
+var _ dynamodbattribute.Marshaler = rawValue{}
+
+func (v rawValue) MarshalDynamoDBAttributeValue(av *dynamodb.AttributeValue) error {
+	*av = *v.av
+	return nil
+}
+
+func currentWorkflowKey(shardID int, domainID, workflowID string) item {
+	return item{
+		"shard_id":     numberValue(int64(shardID)),
+		"workflow_key": stringValue(domainID + "#" + workflowID),
+	}
+}
+
+func workflowExecutionKey(shardID int, domainID, workflowID, runID string) item {
+	return item{
+		"shard_id":      numberValue(int64(shardID)),
+		"execution_key": stringValue(domainID + "#" + workflowID + "#" + runID),
+	}
+}
+
+func shardIDKey(shardID int, taskID int64) item {
+	return item{
+		"shard_id": numberValue(int64(shardID)),
+		"task_id":  numberValue(taskID),
+	}
+}
+
+func shardClusterKey(shardID int, cluster string, taskID int64) item {
+	return item{
+		"shard_cluster": stringValue(shardClusterValue(shardID, cluster)),
+		"task_id":       numberValue(taskID),
+	}
+}
+
+func shardClusterValue(shardID int, cluster string) string {
+	return strconv.Itoa(shardID) + "#" + cluster
+}
+
+func timerTaskKey(shardID int, visibilityTimestamp time.Time, taskID int64) item {
+	return item{
+		"shard_id":  numberValue(int64(shardID)),
+		"timer_key": stringValue(timerKeyValue(visibilityTimestamp, taskID)),
+	}
+}
+
+// timerKeyValue returns a range key that sorts timers by visibility timestamp and then by task ID
+func timerKeyValue(visibilityTimestamp time.Time, taskID int64) string {
+	return fmt.Sprintf("%020d#%020d", toUnixNano(visibilityTimestamp), taskID)
+}
+
+// toUnixNano converts a time to unix nanoseconds, clamped to the range representable by int64
+func toUnixNano(t time.Time) int64 {
+	if t.Before(time.Unix(0, 0)) {
+		return 0
+	}
+	if t.After(maxUnixNanoTime) {
+		return math.MaxInt64
+	}
+	return t.UnixNano()
+}
+
+func int64MapKey(key int64) string {
+	return strconv.FormatInt(key, 10)
+}
+
+func parseInt64MapKey(key string) (int64, error) {
+	return strconv.ParseInt(key, 10, 64)
+}
+
+// stringMapKey encodes user provided keys(e.g. timerID), which can contain characters
+// that have special meanings in DynamoDB document paths
+func stringMapKey(key string) string {
+	return "s" + hex.EncodeToString([]byte(key))
+}
+
+func parseStringMapKey(key string) (string, error) {
+	decoded, err := hex.DecodeString(strings.TrimPrefix(key, "s"))
+	if err != nil {
+		return "", err
+	}
+	return string(decoded), nil
+}
+
+func mapEntryName(mapAttribute, key string) expression.NameBuilder {
+	return expression.Name(mapAttribute + "." + key)
+}
+
+func newCurrentWorkflowItem(shardID int, domainID, workflowID string, row *nosqlplugin.CurrentWorkflowRow) item {
+	it := currentWorkflowKey(shardID, domainID, workflowID)
+	it["domain_id"] = stringValue(domainID)
+	it["workflow_id"] = stringValue(workflowID)
+	it["run_id"] = stringValue(row.RunID)
+	it["create_request_id"] = stringValue(row.CreateRequestID)
+	it["state"] = numberValue(int64(row.State))
+	it["close_status"] = numberValue(int64(row.CloseStatus))
+	it["last_write_version"] = numberValue(row.LastWriteVersion)
+	return it
+}
+
+func parseCurrentWorkflowItem(shardID int, it item) (*nosqlplugin.CurrentWorkflowRow, error) {
+	state, err := getNumber(it, "state")
+	if err != nil {
+		return nil, err
+	}
+	closeStatus, err := getNumber(it, "close_status")
+	if err != nil {
+		return nil, err
+	}
+	lastWriteVersion, err := getNumber(it, "last_write_version")
+	if err != nil {
+		lastWriteVersion = common.EmptyVersion
+	}
+	return &nosqlplugin.CurrentWorkflowRow{
+		ShardID:          shardID,
+		DomainID:         getString(it, "domain_id"),
+		WorkflowID:       getString(it, "workflow_id"),
+		RunID:            getString(it, "run_id"),
+		CreateRequestID:  getString(it, "create_request_id"),
+		State:            int(state),
+		CloseStatus:      int(closeStatus),
+		LastWriteVersion: lastWriteVersion,
+	}, nil
+}
+
+func (db *ddb) createOrUpdateCurrentWorkflow(
+	txn *transaction,
+	shardID int,
+	domainID string,
+	workflowID string,
+	request *nosqlplugin.CurrentWorkflowWriteRequest,
+) error {
+	table := db.tableName(tableCurrentWorkflow)
+	switch request.WriteMode {
+	case nosqlplugin.CurrentWorkflowWriteModeNoop:
+		return nil
+	case nosqlplugin.CurrentWorkflowWriteModeInsert:
+		condition := expression.AttributeNotExists(expression.Name("workflow_key"))
+		return txn.put(conditionCurrentWorkflow, table, newCurrentWorkflowItem(shardID, domainID, workflowID, &request.Row), &condition)
+	case nosqlplugin.CurrentWorkflowWriteModeUpdate:
+		if request.Condition == nil || request.Condition.GetCurrentRunID() == "" {
+			return fmt.Errorf("CurrentWorkflowWriteModeUpdate require Condition.CurrentRunID")
+		}
+		condition := expression.Name("run_id").Equal(expression.Value(*request.Condition.CurrentRunID))
+		if request.Condition.LastWriteVersion != nil && request.Condition.State != nil {
+			condition = condition.And(
+				expression.Name("last_write_version").Equal(expression.Value(*request.Condition.LastWriteVersion)),
+				expression.Name("state").Equal(expression.Value(*request.Condition.State)),
+			)
+		}
+		return txn.put(conditionCurrentWorkflow, table, newCurrentWorkflowItem(shardID, domainID, workflowID, &request.Row), &condition)
+	default:
+		return fmt.Errorf("unknown mode %v", request.WriteMode)
+	}
+}
+
+func (db *ddb) assertShardRangeID(txn *transaction, shardID int, rangeID int64) error {
+	condition := expression.Name("range_id").Equal(expression.Value(rangeID))
+	return txn.conditionCheck(conditionShard, db.tableName(tableShard), shardKey(shardID), condition)
+}
+
+// createWorkflowExecution inserts a new execution with all its maps, the execution must not exist
+func (db *ddb) createWorkflowExecution(
+	txn *transaction,
+	kind conditionKind,
+	shardID int,
+	execution *nosqlplugin.WorkflowExecutionRequest,
+) error {
+	if execution.MapsWriteMode != nosqlplugin.WorkflowExecutionMapsWriteModeCreate {
+		return fmt.Errorf("should only support WorkflowExecutionMapsWriteModeCreate")
+	}
+	if execution.EventBufferWriteMode != nosqlplugin.EventBufferWriteModeNone {
+		return fmt.Errorf("should only support EventBufferWriteModeNone")
+	}
+	it, err := newWorkflowExecutionItem(shardID, execution)
+	if err != nil {
+		return err
+	}
+	if size := itemSize(it); size > maxItemSize {
+		return newItemSizeLimitError(fmt.Sprintf("execution item of %v bytes", size))
+	}
+	condition := expression.AttributeNotExists(expression.Name("execution_key"))
+	return txn.put(kind, db.tableName(tableWorkflowExecution), it, &condition)
+}
+
+// resetWorkflowExecution replaces the execution with all its maps and clears the buffered events
+func (db *ddb) resetWorkflowExecution(
+	txn *transaction,
+	shardID int,
+	execution *nosqlplugin.WorkflowExecutionRequest,
+) error {
+	if execution.MapsWriteMode != nosqlplugin.WorkflowExecutionMapsWriteModeReset {
+		return fmt.Errorf("should only support WorkflowExecutionMapsWriteModeReset")
+	}
+	if execution.EventBufferWriteMode != nosqlplugin.EventBufferWriteModeClear {
+		return fmt.Errorf("should only support EventBufferWriteModeClear")
+	}
+	it, err := newWorkflowExecutionItem(shardID, execution)
+	if err != nil {
+		return err
+	}
+	if size := itemSize(it); size > maxItemSize {
+		return newItemSizeLimitError(fmt.Sprintf("execution item of %v bytes", size))
+	}
+	condition := expression.Name("next_event_id").Equal(expression.Value(*execution.PreviousNextEventIDCondition))
+	return txn.put(conditionWorkflowExecution, db.tableName(tableWorkflowExecution), it, &condition)
+}
+
+// updateWorkflowExecution updates the execution info, merges/deletes the map entries and updates the buffered events
+func (db *ddb) updateWorkflowExecution(
+	txn *transaction,
+	shardID int,
+	execution *nosqlplugin.WorkflowExecutionRequest,
+) error {
+	if execution.MapsWriteMode != nosqlplugin.WorkflowExecutionMapsWriteModeUpdate {
+		return fmt.Errorf("should only support WorkflowExecutionMapsWriteModeUpdate")
+	}
+
+	info, err := jsonValue(&execution.InternalWorkflowExecutionInfo)
+	if err != nil {
+		return err
+	}
+	versionHistories, err := jsonValue(execution.VersionHistories)
+	if err != nil {
+		return err
+	}
+	checksums, err := jsonValue(execution.Checksums)
+	if err != nil {
+		return err
+	}
+	update := expression.
+		Set(expression.Name("execution"), expression.Value(rawValue{info})).
+		Set(expression.Name("next_event_id"), expression.Value(execution.NextEventID)).
+		Set(expression.Name("version_histories"), expression.Value(rawValue{versionHistories})).
+		Set(expression.Name("checksum"), expression.Value(rawValue{checksums})).
+		Set(expression.Name("last_write_version"), expression.Value(execution.LastWriteVersion))
+
+	for key, value := range execution.ActivityInfos {
+		if update, err = setMapEntry(update, activityMapAttribute, int64MapKey(key), value); err != nil {
+			return err
+		}
+	}
+	for _, key := range execution.ActivityInfoKeysToDelete {
+		update = update.Remove(mapEntryName(activityMapAttribute, int64MapKey(key)))
+	}
+	for key, value := range execution.TimerInfos {
+		if update, err = setMapEntry(update, timerMapAttribute, stringMapKey(key), value); err != nil {
+			return err
+		}
+	}
+	for _, key := range execution.TimerInfoKeysToDelete {
+		update = update.Remove(mapEntryName(timerMapAttribute, stringMapKey(key)))
+	}
+	for key, value := range execution.ChildWorkflowInfos {
+		if update, err = setMapEntry(update, childExecutionMapAttribute, int64MapKey(key), value); err != nil {
+			return err
+		}
+	}
+	for _, key := range execution.ChildWorkflowInfoKeysToDelete {
+		update = update.Remove(mapEntryName(childExecutionMapAttribute, int64MapKey(key)))
+	}
+	for key, value := range execution.RequestCancelInfos {
+		if update, err = setMapEntry(update, requestCancelMapAttribute, int64MapKey(key), value); err != nil {
+			return err
+		}
+	}
+	for _, key := range execution.RequestCancelInfoKeysToDelete {
+		update = update.Remove(mapEntryName(requestCancelMapAttribute, int64MapKey(key)))
+	}
+	for key, value := range execution.SignalInfos {
+		if update, err = setMapEntry(update, signalMapAttribute, int64MapKey(key), value); err != nil {
+			return err
+		}
+	}
+	for _, key := range execution.SignalInfoKeysToDelete {
+		update = update.Remove(mapEntryName(signalMapAttribute, int64MapKey(key)))
+	}
+	for _, key := range execution.SignalRequestedIDs {
+		update = update.Set(
+			mapEntryName(signalRequestedMapAttribute, stringMapKey(key)),
+			expression.Value(rawValue{&dynamodb.AttributeValue{BOOL: aws.Bool(true)}}),
+		)
+	}
+	for _, key := range execution.SignalRequestedIDsKeysToDelete {
+		update = update.Remove(mapEntryName(signalRequestedMapAttribute, stringMapKey(key)))
+	}
+
+	switch execution.EventBufferWriteMode {
+	case nosqlplugin.EventBufferWriteModeNone:
+	case nosqlplugin.EventBufferWriteModeAppend:
+		batch, err := newBufferedEventsValue(execution.NewBufferedEventBatch)
+		if err != nil {
+			return err
+		}
+		update = update.Set(
+			expression.Name(bufferedEventsAttribute),
+			expression.ListAppend(expression.Name(bufferedEventsAttribute), expression.Value(rawValue{batch})),
+		)
+	case nosqlplugin.EventBufferWriteModeClear:
+		update = update.Set(
+			expression.Name(bufferedEventsAttribute),
+			expression.Value(rawValue{&dynamodb.AttributeValue{L: []*dynamodb.AttributeValue{}}}),
+		)
+	default:
+		return fmt.Errorf("unknown EventBufferWriteType %v", execution.EventBufferWriteMode)
+	}
+
+	condition := expression.Name("next_event_id").Equal(expression.Value(*execution.PreviousNextEventIDCondition))
+	key := workflowExecutionKey(shardID, execution.DomainID, execution.WorkflowID, execution.RunID)
+	return txn.update(conditionWorkflowExecution, db.tableName(tableWorkflowExecution), key, update, &condition)
+}
+
+func setMapEntry(update expression.UpdateBuilder, mapAttribute, key string, value interface{}) (expression.UpdateBuilder, error) {
+	v, err := jsonValue(value)
+	if err != nil {
+		return update, err
+	}
+	return update.Set(mapEntryName(mapAttribute, key), expression.Value(rawValue{v})), nil
+}
+
+func newBufferedEventsValue(batches ...*p.DataBlob) (*dynamodb.AttributeValue, error) {
+	list := make([]*dynamodb.AttributeValue, 0, len(batches))
+	for _, batch := range batches {
+		if batch == nil {
+			return nil, fmt.Errorf("buffered event batch must not be nil")
+		}
+		list = append(list, &dynamodb.AttributeValue{M: item{
+			"encoding": stringValue(string(batch.Encoding)),
+			"data":     binaryValue(batch.Data),
+		}})
+	}
+	return &dynamodb.AttributeValue{L: list}, nil
+}
+
+func newWorkflowExecutionItem(shardID int, execution *nosqlplugin.WorkflowExecutionRequest) (item, error) {
+	it := workflowExecutionKey(shardID, execution.DomainID, execution.WorkflowID, execution.RunID)
+	it["domain_id"] = stringValue(execution.DomainID)
+	it["workflow_id"] = stringValue(execution.WorkflowID)
+	it["run_id"] = stringValue(execution.RunID)
+	it["next_event_id"] = numberValue(execution.NextEventID)
+	it["last_write_version"] = numberValue(execution.LastWriteVersion)
+
+	var err error
+	if it["execution"], err = jsonValue(&execution.InternalWorkflowExecutionInfo); err != nil {
+		return nil, err
+	}
+	if it["version_histories"], err = jsonValue(execution.VersionHistories); err != nil {
+		return nil, err
+	}
+	if it["checksum"], err = jsonValue(execution.Checksums); err != nil {
+		return nil, err
+	}
+
+	activityMap := make(item, len(execution.ActivityInfos))
+	for key, value := range execution.ActivityInfos {
+		if activityMap[int64MapKey(key)], err = jsonValue(value); err != nil {
+			return nil, err
+		}
+	}
+	timerMap := make(item, len(execution.TimerInfos))
+	for key, value := range execution.TimerInfos {
+		if timerMap[stringMapKey(key)], err = jsonValue(value); err != nil {
+			return nil, err
+		}
+	}
+	childExecutionMap := make(item, len(execution.ChildWorkflowInfos))
+	for key, value := range execution.ChildWorkflowInfos {
+		if childExecutionMap[int64MapKey(key)], err = jsonValue(value); err != nil {
+			return nil, err
+		}
+	}
+	requestCancelMap := make(item, len(execution.RequestCancelInfos))
+	for key, value := range execution.RequestCancelInfos {
+		if requestCancelMap[int64MapKey(key)], err = jsonValue(value); err != nil {
+			return nil, err
+		}
+	}
+	signalMap := make(item, len(execution.SignalInfos))
+	for key, value := range execution.SignalInfos {
+		if signalMap[int64MapKey(key)], err = jsonValue(value); err != nil {
+			return nil, err
+		}
+	}
+	signalRequestedMap := make(item, len(execution.SignalRequestedIDs))
+	for _, key := range execution.SignalRequestedIDs {
+		signalRequestedMap[stringMapKey(key)] = &dynamodb.AttributeValue{BOOL: aws.Bool(true)}
+	}
+
+	it[activityMapAttribute] = &dynamodb.AttributeValue{M: activityMap}
+	it[timerMapAttribute] = &dynamodb.AttributeValue{M: timerMap}
+	it[childExecutionMapAttribute] = &dynamodb.AttributeValue{M: childExecutionMap}
+	it[requestCancelMapAttribute] = &dynamodb.AttributeValue{M: requestCancelMap}
+	it[signalMapAttribute] = &dynamodb.AttributeValue{M: signalMap}
+	it[signalRequestedMapAttribute] = &dynamodb.AttributeValue{M: signalRequestedMap}
+	it[bufferedEventsAttribute] = &dynamodb.AttributeValue{L: []*dynamodb.AttributeValue{}}
+	return it, nil
+}
+
+func parseWorkflowExecutionItem(it item) (*nosqlplugin.WorkflowExecution, error) {
+	state := &nosqlplugin.WorkflowExecution{
+		ExecutionInfo:       &p.InternalWorkflowExecutionInfo{},
+		ActivityInfos:       make(map[int64]*p.InternalActivityInfo),
+		TimerInfos:          make(map[string]*p.TimerInfo),
+		ChildExecutionInfos: make(map[int64]*p.InternalChildExecutionInfo),
+		RequestCancelInfos:  make(map[int64]*p.RequestCancelInfo),
+		SignalInfos:         make(map[int64]*p.SignalInfo),
+		SignalRequestedIDs:  make(map[string]struct{}),
+	}
+	if err := getJSON(it, "execution", state.ExecutionInfo); err != nil {
+		return nil, err
+	}
+	if err := getJSON(it, "version_histories", &state.VersionHistories); err != nil {
+		return nil, err
+	}
+	var checksums *checksum.Checksum
+	if err := getJSON(it, "checksum", &checksums); err != nil {
+		return nil, err
+	}
+	if checksums != nil {
+		state.Checksum = *checksums
+	}
+
+	for key, value := range getMap(it, activityMapAttribute) {
+		id, err := parseInt64MapKey(key)
+		if err != nil {
+			return nil, err
+		}
+		info := &p.InternalActivityInfo{}
+		if err := unmarshalJSONValue(value, info); err != nil {
+			return nil, err
+		}
+		state.ActivityInfos[id] = info
+	}
+	for key, value := range getMap(it, timerMapAttribute) {
+		id, err := parseStringMapKey(key)
+		if err != nil {
+			return nil, err
+		}
+		info := &p.TimerInfo{}
+		if err := unmarshalJSONValue(value, info); err != nil {
+			return nil, err
+		}
+		state.TimerInfos[id] = info
+	}
+	for key, value := range getMap(it, childExecutionMapAttribute) {
+		id, err := parseInt64MapKey(key)
+		if err != nil {
+			return nil, err
+		}
+		info := &p.InternalChildExecutionInfo{}
+		if err := unmarshalJSONValue(value, info); err != nil {
+			return nil, err
+		}
+		state.ChildExecutionInfos[id] = info
+	}
+	for key, value := range getMap(it, requestCancelMapAttribute) {
+		id, err := parseInt64MapKey(key)
+		if err != nil {
+			return nil, err
+		}
+		info := &p.RequestCancelInfo{}
+		if err := unmarshalJSONValue(value, info); err != nil {
+			return nil, err
+		}
+		state.RequestCancelInfos[id] = info
+	}
+	for key, value := range getMap(it, signalMapAttribute) {
+		id, err := parseInt64MapKey(key)
+		if err != nil {
+			return nil, err
+		}
+		info := &p.SignalInfo{}
+		if err := unmarshalJSONValue(value, info); err != nil {
+			return nil, err
+		}
+		state.SignalInfos[id] = info
+	}
+	for key := range getMap(it, signalRequestedMapAttribute) {
+		id, err := parseStringMapKey(key)
+		if err != nil {
+			return nil, err
+		}
+		state.SignalRequestedIDs[id] = struct{}{}
+	}
+
+	var bufferedEvents []*dynamodb.AttributeValue
+	if v, ok := it[bufferedEventsAttribute]; ok && v != nil {
+		bufferedEvents = v.L
+	}
+	state.BufferedEvents = make([]*p.DataBlob, 0, len(bufferedEvents))
+	for _, v := range bufferedEvents {
+		if v == nil {
+			continue
+		}
+		state.BufferedEvents = append(state.BufferedEvents, p.NewDataBlob(
+			getBinary(v.M, "data"),
+			common.EncodingType(getString(v.M, "encoding")),
+		))
+	}
+	return state, nil
+}
+
+func getMap(it item, name string) item {
+	if v, ok := it[name]; ok && v != nil {
+		return v.M
+	}
+	return nil
+}
+
+func unmarshalJSONValue(v *dynamodb.AttributeValue, out interface{}) error {
+	if v == nil || len(v.B) == 0 {
+		return fmt.Errorf("expect a json binary attribute")
+	}
+	return json.Unmarshal(v.B, out)
+}
+
+// newTaskWrites returns the writes of all the tasks. The replication tasks come first, so that they are the last ones
+// to overflow the transaction of the execution.
+func (db *ddb) newTaskWrites(
+	shardID int,
+	transferTasks []*nosqlplugin.TransferTask,
+	crossClusterTasks []*nosqlplugin.CrossClusterTask,
+	replicationTasks []*nosqlplugin.ReplicationTask,
+	timerTasks []*nosqlplugin.TimerTask,
+) ([]*taskWrite, error) {
+	writes := make([]*taskWrite, 0, len(transferTasks)+len(crossClusterTasks)+len(replicationTasks)+len(timerTasks))
+	add := func(table string, key item, task interface{}) error {
+		// the key is kept apart from the item, to delete the task if the transaction of the execution fails
+		keyCopy := make(item, len(key)+1)
+		for name, value := range key {
+			keyCopy[name] = value
+		}
+		it, err := newTaskItem(keyCopy, task)
+		if err != nil {
+			return err
+		}
+		writes = append(writes, &taskWrite{table: db.tableName(table), key: key, item: it})
+		return nil
+	}
+	for _, task := range replicationTasks {
+		if err := add(tableReplicationTask, shardIDKey(shardID, task.TaskID), task); err != nil {
+			return nil, err
+		}
+	}
+	for _, task := range crossClusterTasks {
+		if err := add(tableCrossClusterTask, shardClusterKey(shardID, task.TargetCluster, task.TaskID), &task.TransferTask); err != nil {
+			return nil, err
+		}
+	}
+	for _, task := range transferTasks {
+		if err := add(tableTransferTask, shardIDKey(shardID, task.TaskID), task); err != nil {
+			return nil, err
+		}
+	}
+	for _, task := range timerTasks {
+		if err := add(tableTimerTask, timerTaskKey(shardID, task.VisibilityTimestamp, task.TaskID), task); err != nil {
+			return nil, err
+		}
+	}
+	return writes, nil
+}
+
+// newTaskItem returns a task item with the task serialized into the data attribute
+func newTaskItem(key item, task interface{}) (item, error) {
+	data, err := jsonValue(task)
+	if err != nil {
+		return nil, err
+	}
+	key["data"] = data
+	return key, nil
+}
+
+func (db *ddb) convertCreateWorkflowConditionFailures(
+	failures conditionFailures,
+	currentWorkflowRequest *nosqlplugin.CurrentWorkflowWriteRequest,
+	execution *nosqlplugin.WorkflowExecutionRequest,
+	shardCondition *nosqlplugin.ShardCondition,
+) error {
+	if failures.has(conditionShard) {
+		return &nosqlplugin.WorkflowOperationConditionFailure{
+			ShardRangeIDNotMatch: common.Int64Ptr(rangeIDOf(failures[conditionShard])),
+		}
+	}
+
+	if failures.has(conditionCurrentWorkflow) {
+		previous := failures[conditionCurrentWorkflow]
+		if len(previous) == 0 {
+			msg := fmt.Sprintf("Workflow execution creation condition failed by missing current workflow. WorkflowId: %v, Expected Current RunID: %v",
+				execution.WorkflowID, currentWorkflowRequest.Condition.GetCurrentRunID())
+			return &nosqlplugin.WorkflowOperationConditionFailure{
+				CurrentWorkflowConditionFailInfo: &msg,
+			}
+		}
+		current, err := parseCurrentWorkflowItem(shardCondition.ShardID, previous)
+		if err != nil {
+			return err
+		}
+		if currentWorkflowRequest.WriteMode == nosqlplugin.CurrentWorkflowWriteModeInsert {
+			msg := fmt.Sprintf("Workflow execution already running. WorkflowId: %v, RunId: %v, rangeID: %v",
+				current.WorkflowID, current.RunID, shardCondition.RangeID)
+			return &nosqlplugin.WorkflowOperationConditionFailure{
+				WorkflowExecutionAlreadyExists: &nosqlplugin.WorkflowExecutionAlreadyExists{
+					OtherInfo:        msg,
+					CreateRequestID:  current.CreateRequestID,
+					RunID:            current.RunID,
+					State:            current.State,
+					CloseStatus:      current.CloseStatus,
+					LastWriteVersion: current.LastWriteVersion,
+				},
+			}
+		}
+		if current.RunID != currentWorkflowRequest.Condition.GetCurrentRunID() {
+			// currentRunID on previous run has been changed, return to caller to handle
+			msg := fmt.Sprintf("Workflow execution creation condition failed by mismatch runID. WorkflowId: %v, Expected Current RunID: %v, Actual Current RunID: %v",
+				execution.WorkflowID, currentWorkflowRequest.Condition.GetCurrentRunID(), current.RunID)
+			return &nosqlplugin.WorkflowOperationConditionFailure{
+				CurrentWorkflowConditionFailInfo: &msg,
+			}
+		}
+		msg := fmt.Sprintf("Workflow execution creation condition failed. WorkflowId: %v, CurrentRunID: %v, LastWriteVersion: %v, State: %v",
+			execution.WorkflowID, current.RunID, current.LastWriteVersion, current.State)
+		return &nosqlplugin.WorkflowOperationConditionFailure{
+			CurrentWorkflowConditionFailInfo: &msg,
+		}
+	}
+
+	if failures.has(conditionWorkflowExecution) {
+		lastWriteVersion := common.EmptyVersion
+		if v, err := getNumber(failures[conditionWorkflowExecution], "last_write_version"); err == nil {
+			lastWriteVersion = v
+		}
+		msg := fmt.Sprintf("Workflow execution already running. WorkflowId: %v, RunId: %v, rangeID: %v",
+			execution.WorkflowID, execution.RunID, shardCondition.RangeID)
+		return &nosqlplugin.WorkflowOperationConditionFailure{
+			WorkflowExecutionAlreadyExists: &nosqlplugin.WorkflowExecutionAlreadyExists{
+				OtherInfo:        msg,
+				CreateRequestID:  execution.CreateRequestID,
+				RunID:            execution.RunID,
+				State:            execution.State,
+				CloseStatus:      execution.CloseStatus,
+				LastWriteVersion: lastWriteVersion,
+			},
+		}
+	}
+
+	return newUnknownConditionFailureReason(shardCondition.RangeID, failures)
+}
+
+func (db *ddb) convertUpdateWorkflowConditionFailures(
+	failures conditionFailures,
+	currentWorkflowRequest *nosqlplugin.CurrentWorkflowWriteRequest,
+	previousNextEventIDCondition int64,
+	shardCondition *nosqlplugin.ShardCondition,
+) error {
+	if failures.has(conditionShard) {
+		return &nosqlplugin.WorkflowOperationConditionFailure{
+			ShardRangeIDNotMatch: common.Int64Ptr(rangeIDOf(failures[conditionShard])),
+		}
+	}
+
+	requestConditionalRunID := currentWorkflowRequest.Condition.GetCurrentRunID()
+	actualNextEventID := int64(0)
+	if v, err := getNumber(failures[conditionWorkflowExecution], "next_event_id"); err == nil {
+		actualNextEventID = v
+	}
+
+	if failures.has(conditionCurrentWorkflow) {
+		actualCurrRunID := getString(failures[conditionCurrentWorkflow], "run_id")
+		msg := fmt.Sprintf("Failed to update mutable state.  Request Condition: %v, Actual Value: %v, Request Current RunID: %v, Actual Value: %v",
+			previousNextEventIDCondition, actualNextEventID, requestConditionalRunID, actualCurrRunID)
+		return &nosqlplugin.WorkflowOperationConditionFailure{
+			CurrentWorkflowConditionFailInfo: &msg,
+		}
+	}
+
+	if failures.has(conditionWorkflowExecution) {
+		msg := fmt.Sprintf("Failed to update mutable state.  Request Condition: %v, Actual Value: %v, Request Current RunID: %v",
+			previousNextEventIDCondition, actualNextEventID, requestConditionalRunID)
+		return &nosqlplugin.WorkflowOperationConditionFailure{
+			UnknownConditionFailureDetails: &msg,
+		}
+	}
+
+	return newUnknownConditionFailureReason(shardCondition.RangeID, failures)
+}
+
+func newUnknownConditionFailureReason(
+	rangeID int64,
+	failures conditionFailures,
+) *nosqlplugin.WorkflowOperationConditionFailure {
+	// At this point we only know that the write was not applied.
+	// It's much safer to return ShardOwnershipLostError as the default to force the application to reload
+	// shard to recover from such errors
+	var columns []string
+	for _, previous := range failures {
+		for k, v := range previous {
+			columns = append(columns, fmt.Sprintf("%s=%v", k, v))
+		}
+	}
+	sort.Strings(columns)
+
+	msg := fmt.Sprintf("Failed to operate on workflow execution.  Request RangeID: %v, columns: (%v)",
+		rangeID, strings.Join(columns, ","))
+
+	return &nosqlplugin.WorkflowOperationConditionFailure{
+		UnknownConditionFailureDetails: &msg,
+	}
+}
+
+// rangeIDOf returns the range_id of the shard item, or -1 if the shard doesn't exist
+func rangeIDOf(shard item) int64 {
+	rangeID, err := getNumber(shard, "range_id")
+	if err != nil {
+		return -1
+	}
+	return rangeID
+}
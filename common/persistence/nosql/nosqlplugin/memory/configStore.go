@@ -0,0 +1,67 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package memory
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/uber/cadence/common/persistence"
+	"github.com/uber/cadence/common/persistence/nosql/nosqlplugin"
+)
+
+const tableConfigStore = "cluster_config"
+
+var _ nosqlplugin.ConfigStoreCRUD = (*mdb)(nil)
+
+func (db *mdb) InsertConfig(ctx context.Context, row *persistence.InternalConfigStoreEntry) error {
+	s, err := db.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer s.Unlock()
+
+	configs := s.table(tableConfigStore)
+	key := rowKey(strconv.Itoa(row.RowType), int64Key(row.Version))
+	if configs.exists(key) {
+		return nosqlplugin.NewConditionFailure("InsertConfig operation failed because of version collision")
+	}
+	return configs.put(key, row)
+}
+
+func (db *mdb) SelectLatestConfig(ctx context.Context, rowType int) (*persistence.InternalConfigStoreEntry, error) {
+	s, err := db.begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer s.Unlock()
+
+	configs := s.table(tableConfigStore)
+	keys := configs.scanPrefix(strconv.Itoa(rowType))
+	if len(keys) == 0 {
+		return nil, nil
+	}
+	row := &persistence.InternalConfigStoreEntry{}
+	if _, err := configs.get(keys[len(keys)-1], row); err != nil {
+		return nil, err
+	}
+	return row, nil
+}
@@ -0,0 +1,87 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package memory
+
+import (
+	"context"
+	"errors"
+
+	"github.com/uber/cadence/common/config"
+	"github.com/uber/cadence/common/log"
+	"github.com/uber/cadence/common/persistence/nosql/nosqlplugin"
+)
+
+const (
+	// PluginName is the name of the plugin
+	PluginName = "memory"
+)
+
+var errRowNotFound = errors.New("row not found")
+
+// mdb represents a logical connection to the in-memory store of a keyspace
+type mdb struct {
+	cfg    *config.NoSQL
+	logger log.Logger
+}
+
+var _ nosqlplugin.DB = (*mdb)(nil)
+
+// NewMemoryDB return a new DB
+func NewMemoryDB(cfg config.NoSQL, logger log.Logger) nosqlplugin.DB {
+	return newMemoryDB(&cfg, logger)
+}
+
+func newMemoryDB(cfg *config.NoSQL, logger log.Logger) *mdb {
+	return &mdb{
+		cfg:    cfg,
+		logger: logger,
+	}
+}
+
+func (db *mdb) Close() {
+	// the data is kept in the store until the keyspace is torn down, so that it survives restarts of the services
+}
+
+func (db *mdb) PluginName() string {
+	return PluginName
+}
+
+func (db *mdb) IsNotFoundError(err error) bool {
+	return err == errRowNotFound
+}
+
+func (db *mdb) IsTimeoutError(err error) bool {
+	return err == context.DeadlineExceeded
+}
+
+func (db *mdb) IsThrottlingError(err error) bool {
+	return false
+}
+
+// begin locks the store of the keyspace for a single operation, the caller must unlock it when done
+func (db *mdb) begin(ctx context.Context) (*store, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	s := getStore(db.cfg.Keyspace)
+	s.Lock()
+	return s, nil
+}
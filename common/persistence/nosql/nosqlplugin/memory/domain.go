@@ -0,0 +1,246 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package memory
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/uber/cadence/common"
+	"github.com/uber/cadence/common/log/tag"
+	p "github.com/uber/cadence/common/persistence"
+	"github.com/uber/cadence/common/persistence/nosql/nosqlplugin"
+	"github.com/uber/cadence/common/types"
+)
+
+// The domain table holds the domain rows keyed by name, the mapping from domain ID to name,
+// and the metadata row with the notification version of the domains.
+const (
+	tableDomain              = "domain"
+	domainNameKeyPrefix      = "name"
+	domainIDKeyPrefix        = "id"
+	domainMetadataRecordName = "metadata"
+)
+
+var _ nosqlplugin.DomainCRUD = (*mdb)(nil)
+
+// Insert a new record to domain, return error if failed or already exists
+// Return ConditionFailure if the condition doesn't meet
+func (db *mdb) InsertDomain(
+	ctx context.Context,
+	row *nosqlplugin.DomainRow,
+) error {
+	s, err := db.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer s.Unlock()
+
+	domains := s.table(tableDomain)
+	if domains.exists(rowKey(domainIDKeyPrefix, row.Info.ID)) {
+		return fmt.Errorf("CreateDomain operation failed because of uuid collision")
+	}
+	if domains.exists(rowKey(domainNameKeyPrefix, row.Info.Name)) {
+		db.logger.Warn("Domain already exists", tag.WorkflowDomainName(row.Info.Name))
+		return &types.DomainAlreadyExistsError{
+			Message: fmt.Sprintf("Domain %v already exists", row.Info.Name),
+		}
+	}
+	metadataNotificationVersion, err := selectDomainMetadata(s)
+	if err != nil {
+		return err
+	}
+
+	newRow := *row
+	newRow.FailoverNotificationVersion = p.InitialFailoverNotificationVersion
+	newRow.PreviousFailoverVersion = common.InitialPreviousFailoverVersion
+	newRow.NotificationVersion = metadataNotificationVersion
+
+	b := &batch{}
+	if err := b.put(domains, rowKey(domainNameKeyPrefix, row.Info.Name), &newRow); err != nil {
+		return err
+	}
+	if err := b.put(domains, rowKey(domainIDKeyPrefix, row.Info.ID), row.Info.Name); err != nil {
+		return err
+	}
+	if err := b.put(domains, domainMetadataRecordName, metadataNotificationVersion+1); err != nil {
+		return err
+	}
+	b.apply()
+	return nil
+}
+
+// Update domain
+func (db *mdb) UpdateDomain(
+	ctx context.Context,
+	row *nosqlplugin.DomainRow,
+) error {
+	s, err := db.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer s.Unlock()
+
+	domains := s.table(tableDomain)
+	metadataNotificationVersion, err := selectDomainMetadata(s)
+	if err != nil {
+		return err
+	}
+	if !domains.exists(rowKey(domainNameKeyPrefix, row.Info.Name)) || metadataNotificationVersion != row.NotificationVersion {
+		return nosqlplugin.NewConditionFailure("domain")
+	}
+
+	b := &batch{}
+	if err := b.put(domains, rowKey(domainNameKeyPrefix, row.Info.Name), row); err != nil {
+		return err
+	}
+	if err := b.put(domains, domainMetadataRecordName, metadataNotificationVersion+1); err != nil {
+		return err
+	}
+	b.apply()
+	return nil
+}
+
+// Get one domain data, either by domainID or domainName
+func (db *mdb) SelectDomain(
+	ctx context.Context,
+	domainID *string,
+	domainName *string,
+) (*nosqlplugin.DomainRow, error) {
+	if domainID != nil && domainName != nil {
+		return nil, fmt.Errorf("GetDomain operation failed.  Both ID and Name specified in request")
+	} else if domainID == nil && domainName == nil {
+		return nil, fmt.Errorf("GetDomain operation failed.  Both ID and Name are empty")
+	}
+
+	s, err := db.begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer s.Unlock()
+
+	domains := s.table(tableDomain)
+	if domainID != nil {
+		var name string
+		found, err := domains.get(rowKey(domainIDKeyPrefix, *domainID), &name)
+		if err != nil {
+			return nil, err
+		}
+		if !found {
+			return nil, errRowNotFound
+		}
+		domainName = &name
+	}
+
+	row := &nosqlplugin.DomainRow{}
+	found, err := domains.get(rowKey(domainNameKeyPrefix, *domainName), row)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, errRowNotFound
+	}
+	return row, nil
+}
+
+// Get all domain data
+func (db *mdb) SelectAllDomains(
+	ctx context.Context,
+	pageSize int,
+	pageToken []byte,
+) ([]*nosqlplugin.DomainRow, []byte, error) {
+	s, err := db.begin(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer s.Unlock()
+
+	domains := s.table(tableDomain)
+	keys, nextPageToken := page(domains.scanPrefix(domainNameKeyPrefix), pageSize, pageToken)
+	rows := make([]*nosqlplugin.DomainRow, 0, len(keys))
+	for _, key := range keys {
+		row := &nosqlplugin.DomainRow{}
+		if _, err := domains.get(key, row); err != nil {
+			return nil, nil, err
+		}
+		rows = append(rows, row)
+	}
+	return rows, nextPageToken, nil
+}
+
+// Delete a domain, either by domainID or domainName
+func (db *mdb) DeleteDomain(
+	ctx context.Context,
+	domainID *string,
+	domainName *string,
+) error {
+	if domainName == nil && domainID == nil {
+		return fmt.Errorf("must provide either domainID or domainName")
+	}
+
+	s, err := db.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer s.Unlock()
+
+	domains := s.table(tableDomain)
+	if domainName == nil {
+		var name string
+		found, err := domains.get(rowKey(domainIDKeyPrefix, *domainID), &name)
+		if err != nil || !found {
+			return err
+		}
+		domainName = common.StringPtr(name)
+	} else {
+		row := &nosqlplugin.DomainRow{}
+		found, err := domains.get(rowKey(domainNameKeyPrefix, *domainName), row)
+		if err != nil || !found {
+			return err
+		}
+		domainID = common.StringPtr(row.Info.ID)
+	}
+
+	domains.delete(rowKey(domainNameKeyPrefix, *domainName))
+	domains.delete(rowKey(domainIDKeyPrefix, *domainID))
+	return nil
+}
+
+func (db *mdb) SelectDomainMetadata(
+	ctx context.Context,
+) (int64, error) {
+	s, err := db.begin(ctx)
+	if err != nil {
+		return -1, err
+	}
+	defer s.Unlock()
+
+	return selectDomainMetadata(s)
+}
+
+// selectDomainMetadata returns the notification version, the metadata row is created along with the first domain
+func selectDomainMetadata(s *store) (int64, error) {
+	var notificationVersion int64
+	if _, err := s.table(tableDomain).get(domainMetadataRecordName, &notificationVersion); err != nil {
+		return -1, err
+	}
+	return notificationVersion, nil
+}
@@ -0,0 +1,224 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package memory
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/uber/cadence/common"
+	"github.com/uber/cadence/common/persistence/nosql/nosqlplugin"
+	"github.com/uber/cadence/common/types"
+)
+
+const (
+	tableHistoryTree = "history_tree"
+	tableHistoryNode = "history_node"
+)
+
+var _ nosqlplugin.HistoryEventsCRUD = (*mdb)(nil)
+
+type (
+	// historyTreeRecord only keeps the end node of the ancestors, the begin nodes are derived when reading,
+	// the same as the ancestors column of the other plugins
+	historyTreeRecord struct {
+		ShardID         int
+		TreeID          string
+		BranchID        string
+		Ancestors       []historyBranchAncestor
+		CreateTimestamp time.Time
+		Info            string
+	}
+
+	historyBranchAncestor struct {
+		BranchID  string
+		EndNodeID int64
+	}
+)
+
+// InsertIntoHistoryTreeAndNode inserts one or two rows: tree row and node row(at least one of them)
+func (db *mdb) InsertIntoHistoryTreeAndNode(ctx context.Context, treeRow *nosqlplugin.HistoryTreeRow, nodeRow *nosqlplugin.HistoryNodeRow) error {
+	if treeRow == nil && nodeRow == nil {
+		return fmt.Errorf("require at least a tree row or a node row to insert")
+	}
+
+	s, err := db.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer s.Unlock()
+
+	b := &batch{}
+	if treeRow != nil {
+		if err := b.put(s.table(tableHistoryTree), historyTreeKey(treeRow.TreeID, treeRow.BranchID), newHistoryTreeRecord(treeRow)); err != nil {
+			return err
+		}
+	}
+	if nodeRow != nil {
+		row := *nodeRow
+		if row.TxnID == nil {
+			row.TxnID = common.Int64Ptr(0)
+		}
+		if err := b.put(s.table(tableHistoryNode), historyNodeKey(row.TreeID, row.BranchID, row.NodeID, *row.TxnID), &row); err != nil {
+			return err
+		}
+	}
+	b.apply()
+	return nil
+}
+
+// SelectFromHistoryNode read nodes based on a filter
+func (db *mdb) SelectFromHistoryNode(ctx context.Context, filter *nosqlplugin.HistoryNodeFilter) ([]*nosqlplugin.HistoryNodeRow, []byte, error) {
+	s, err := db.begin(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer s.Unlock()
+
+	nodes := s.table(tableHistoryNode)
+	begin, end := int64Range(filter.MinNodeID, filter.MaxNodeID-1, filter.TreeID, filter.BranchID)
+	keys, nextPageToken := page(nodes.scan(begin, end), filter.PageSize, filter.NextPageToken)
+	rows := make([]*nosqlplugin.HistoryNodeRow, 0, len(keys))
+	for _, key := range keys {
+		row := &nosqlplugin.HistoryNodeRow{}
+		if _, err := nodes.get(key, row); err != nil {
+			return nil, nil, err
+		}
+		rows = append(rows, row)
+	}
+	return rows, nextPageToken, nil
+}
+
+// DeleteFromHistoryTreeAndNode delete a branch record, and a list of ranges of nodes.
+// for each range, it will delete all nodes starting from MinNodeID(inclusive)
+func (db *mdb) DeleteFromHistoryTreeAndNode(ctx context.Context, treeFilter *nosqlplugin.HistoryTreeFilter, nodeFilters []*nosqlplugin.HistoryNodeFilter) error {
+	s, err := db.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer s.Unlock()
+
+	nodes := s.table(tableHistoryNode)
+	for _, nodeFilter := range nodeFilters {
+		begin, end := int64Range(nodeFilter.MinNodeID, math.MaxInt64, nodeFilter.TreeID, nodeFilter.BranchID)
+		for _, key := range nodes.scan(begin, end) {
+			nodes.delete(key)
+		}
+	}
+	s.table(tableHistoryTree).delete(historyTreeKey(treeFilter.TreeID, common.StringDefault(treeFilter.BranchID)))
+	return nil
+}
+
+// SelectAllHistoryTrees will return all tree branches with pagination
+func (db *mdb) SelectAllHistoryTrees(ctx context.Context, nextPageToken []byte, pageSize int) ([]*nosqlplugin.HistoryTreeRow, []byte, error) {
+	s, err := db.begin(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer s.Unlock()
+
+	trees := s.table(tableHistoryTree)
+	keys, token := page(trees.scanAll(), pageSize, nextPageToken)
+	return selectHistoryTrees(trees, keys, token)
+}
+
+// SelectFromHistoryTree read branch records for a tree
+func (db *mdb) SelectFromHistoryTree(ctx context.Context, filter *nosqlplugin.HistoryTreeFilter) ([]*nosqlplugin.HistoryTreeRow, error) {
+	s, err := db.begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer s.Unlock()
+
+	trees := s.table(tableHistoryTree)
+	rows, _, err := selectHistoryTrees(trees, trees.scanPrefix(filter.TreeID), nil)
+	return rows, err
+}
+
+func selectHistoryTrees(trees *table, keys []string, nextPageToken []byte) ([]*nosqlplugin.HistoryTreeRow, []byte, error) {
+	rows := make([]*nosqlplugin.HistoryTreeRow, 0, len(keys))
+	for _, key := range keys {
+		record := &historyTreeRecord{}
+		if _, err := trees.get(key, record); err != nil {
+			return nil, nil, err
+		}
+		rows = append(rows, &nosqlplugin.HistoryTreeRow{
+			ShardID:         record.ShardID,
+			TreeID:          record.TreeID,
+			BranchID:        record.BranchID,
+			Ancestors:       parseBranchAncestors(record.Ancestors),
+			CreateTimestamp: record.CreateTimestamp,
+			Info:            record.Info,
+		})
+	}
+	return rows, nextPageToken, nil
+}
+
+func historyTreeKey(treeID, branchID string) string {
+	return rowKey(treeID, branchID)
+}
+
+// historyNodeKey orders the nodes of a branch by node_id ascending and then txn_id descending,
+// the same as the cassandra clustering order
+func historyNodeKey(treeID, branchID string, nodeID, txnID int64) string {
+	return rowKey(treeID, branchID, int64Key(nodeID), descInt64Key(txnID))
+}
+
+func newHistoryTreeRecord(row *nosqlplugin.HistoryTreeRow) *historyTreeRecord {
+	ancestors := make([]historyBranchAncestor, 0, len(row.Ancestors))
+	for _, an := range row.Ancestors {
+		ancestors = append(ancestors, historyBranchAncestor{
+			BranchID:  an.GetBranchID(),
+			EndNodeID: an.GetEndNodeID(),
+		})
+	}
+	return &historyTreeRecord{
+		ShardID:         row.ShardID,
+		TreeID:          row.TreeID,
+		BranchID:        row.BranchID,
+		Ancestors:       ancestors,
+		CreateTimestamp: row.CreateTimestamp,
+		Info:            row.Info,
+	}
+}
+
+func parseBranchAncestors(ancestors []historyBranchAncestor) []*types.HistoryBranchRange {
+	ans := make([]*types.HistoryBranchRange, 0, len(ancestors))
+	for _, e := range ancestors {
+		ans = append(ans, &types.HistoryBranchRange{
+			BranchID:  common.StringPtr(e.BranchID),
+			EndNodeID: common.Int64Ptr(e.EndNodeID),
+		})
+	}
+
+	if len(ans) > 0 {
+		// sort ans based on EndNodeID so that we can set BeginNodeID
+		sort.Slice(ans, func(i, j int) bool { return *ans[i].EndNodeID < *ans[j].EndNodeID })
+		ans[0].BeginNodeID = common.Int64Ptr(int64(1))
+		for i := 1; i < len(ans); i++ {
+			ans[i].BeginNodeID = ans[i-1].EndNodeID
+		}
+	}
+	return ans
+}
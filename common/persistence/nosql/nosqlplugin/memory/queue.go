@@ -0,0 +1,316 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package memory
+
+import (
+	"context"
+	"math"
+	"strconv"
+
+	"github.com/uber/cadence/common/persistence"
+	"github.com/uber/cadence/common/persistence/nosql/nosqlplugin"
+)
+
+const (
+	tableQueueMessage  = "queue"
+	tableQueueMetadata = "queue_metadata"
+)
+
+var _ nosqlplugin.MessageQueueCRUD = (*mdb)(nil)
+
+// Insert message into queue, return error if failed or already exists
+// Return ConditionFailure if the condition doesn't meet
+func (db *mdb) InsertIntoQueue(
+	ctx context.Context,
+	row *nosqlplugin.QueueMessageRow,
+) error {
+	s, err := db.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer s.Unlock()
+
+	messages := s.table(tableQueueMessage)
+	key := queueMessageKey(row.QueueType, row.ID)
+	if messages.exists(key) {
+		return nosqlplugin.NewConditionFailure("queue")
+	}
+	return messages.put(key, row)
+}
+
+// Get the ID of last message inserted into the queue
+func (db *mdb) SelectLastEnqueuedMessageID(
+	ctx context.Context,
+	queueType persistence.QueueType,
+) (int64, error) {
+	s, err := db.begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer s.Unlock()
+
+	messages := s.table(tableQueueMessage)
+	keys := messages.scanPrefix(queueKey(queueType))
+	if len(keys) == 0 {
+		return 0, errRowNotFound
+	}
+	row := &nosqlplugin.QueueMessageRow{}
+	if _, err := messages.get(keys[len(keys)-1], row); err != nil {
+		return 0, err
+	}
+	return row.ID, nil
+}
+
+// Read queue messages starting from the exclusiveBeginMessageID
+func (db *mdb) SelectMessagesFrom(
+	ctx context.Context,
+	queueType persistence.QueueType,
+	exclusiveBeginMessageID int64,
+	maxRows int,
+) ([]*nosqlplugin.QueueMessageRow, error) {
+	if exclusiveBeginMessageID == math.MaxInt64 {
+		return nil, nil
+	}
+
+	s, err := db.begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer s.Unlock()
+
+	rows, err := selectQueueMessages(s, queueType, exclusiveBeginMessageID+1, math.MaxInt64)
+	if err != nil {
+		return nil, err
+	}
+	if maxRows > 0 && len(rows) > maxRows {
+		rows = rows[:maxRows]
+	}
+	return rows, nil
+}
+
+// Read queue message starting from exclusiveBeginMessageID int64, inclusiveEndMessageID int64
+func (db *mdb) SelectMessagesBetween(
+	ctx context.Context,
+	request nosqlplugin.SelectMessagesBetweenRequest,
+) (*nosqlplugin.SelectMessagesBetweenResponse, error) {
+	response := &nosqlplugin.SelectMessagesBetweenResponse{}
+	if request.ExclusiveBeginMessageID >= request.InclusiveEndMessageID {
+		return response, nil
+	}
+
+	s, err := db.begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer s.Unlock()
+
+	messages := s.table(tableQueueMessage)
+	begin, end := int64Range(request.ExclusiveBeginMessageID+1, request.InclusiveEndMessageID, queueKey(request.QueueType))
+	keys, nextPageToken := page(messages.scan(begin, end), request.PageSize, request.NextPageToken)
+	for _, key := range keys {
+		row := nosqlplugin.QueueMessageRow{}
+		if _, err := messages.get(key, &row); err != nil {
+			return nil, err
+		}
+		response.Rows = append(response.Rows, row)
+	}
+	response.NextPageToken = nextPageToken
+	return response, nil
+}
+
+// Delete all messages before exclusiveBeginMessageID
+func (db *mdb) DeleteMessagesBefore(
+	ctx context.Context,
+	queueType persistence.QueueType,
+	exclusiveBeginMessageID int64,
+) error {
+	if exclusiveBeginMessageID == math.MinInt64 {
+		return nil
+	}
+	return db.deleteQueueMessages(ctx, queueType, math.MinInt64, exclusiveBeginMessageID-1)
+}
+
+// Delete all messages in a range between exclusiveBeginMessageID and inclusiveEndMessageID
+func (db *mdb) DeleteMessagesInRange(
+	ctx context.Context,
+	queueType persistence.QueueType,
+	exclusiveBeginMessageID int64,
+	inclusiveEndMessageID int64,
+) error {
+	if exclusiveBeginMessageID >= inclusiveEndMessageID {
+		return nil
+	}
+	return db.deleteQueueMessages(ctx, queueType, exclusiveBeginMessageID+1, inclusiveEndMessageID)
+}
+
+// Delete one message
+func (db *mdb) DeleteMessage(
+	ctx context.Context,
+	queueType persistence.QueueType,
+	messageID int64,
+) error {
+	s, err := db.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer s.Unlock()
+
+	s.table(tableQueueMessage).delete(queueMessageKey(queueType, messageID))
+	return nil
+}
+
+// Insert an empty metadata row, starting from a version
+func (db *mdb) InsertQueueMetadata(
+	ctx context.Context,
+	queueType persistence.QueueType,
+	version int64,
+) error {
+	s, err := db.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer s.Unlock()
+
+	metadata := s.table(tableQueueMetadata)
+	key := queueKey(queueType)
+	if metadata.exists(key) {
+		// it's ok if the insert is not applied, which means that the record exists already.
+		return nil
+	}
+	return metadata.put(key, &nosqlplugin.QueueMetadataRow{
+		QueueType:        queueType,
+		ClusterAckLevels: map[string]int64{},
+		Version:          version,
+	})
+}
+
+// **Conditionally** update a queue metadata row, if current version is matched(meaning current == row.Version - 1),
+// then the current version will increase by one when updating the metadata row
+// it should return ConditionFailure if the condition is not met
+func (db *mdb) UpdateQueueMetadataCas(
+	ctx context.Context,
+	row nosqlplugin.QueueMetadataRow,
+) error {
+	s, err := db.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer s.Unlock()
+
+	metadata := s.table(tableQueueMetadata)
+	key := queueKey(row.QueueType)
+	current := &nosqlplugin.QueueMetadataRow{}
+	found, err := metadata.get(key, current)
+	if err != nil {
+		return err
+	}
+	if !found || current.Version != row.Version-1 {
+		return nosqlplugin.NewConditionFailure("queue")
+	}
+	return metadata.put(key, &row)
+}
+
+// Read a QueueMetadata
+func (db *mdb) SelectQueueMetadata(
+	ctx context.Context,
+	queueType persistence.QueueType,
+) (*nosqlplugin.QueueMetadataRow, error) {
+	s, err := db.begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer s.Unlock()
+
+	row := &nosqlplugin.QueueMetadataRow{}
+	found, err := s.table(tableQueueMetadata).get(queueKey(queueType), row)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, errRowNotFound
+	}
+	// if record exist but ackLevels is empty, we initialize the map
+	if row.ClusterAckLevels == nil {
+		row.ClusterAckLevels = make(map[string]int64)
+	}
+	return row, nil
+}
+
+func (db *mdb) GetQueueSize(
+	ctx context.Context,
+	queueType persistence.QueueType,
+) (int64, error) {
+	s, err := db.begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer s.Unlock()
+
+	return int64(len(s.table(tableQueueMessage).scanPrefix(queueKey(queueType)))), nil
+}
+
+// deleteQueueMessages deletes the messages within [inclusiveBeginMessageID, inclusiveEndMessageID]
+func (db *mdb) deleteQueueMessages(
+	ctx context.Context,
+	queueType persistence.QueueType,
+	inclusiveBeginMessageID int64,
+	inclusiveEndMessageID int64,
+) error {
+	s, err := db.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer s.Unlock()
+
+	messages := s.table(tableQueueMessage)
+	for _, key := range messages.scan(int64Range(inclusiveBeginMessageID, inclusiveEndMessageID, queueKey(queueType))) {
+		messages.delete(key)
+	}
+	return nil
+}
+
+// selectQueueMessages returns the messages within [inclusiveBeginMessageID, inclusiveEndMessageID] in ascending order
+func selectQueueMessages(
+	s *store,
+	queueType persistence.QueueType,
+	inclusiveBeginMessageID int64,
+	inclusiveEndMessageID int64,
+) ([]*nosqlplugin.QueueMessageRow, error) {
+	messages := s.table(tableQueueMessage)
+	keys := messages.scan(int64Range(inclusiveBeginMessageID, inclusiveEndMessageID, queueKey(queueType)))
+	rows := make([]*nosqlplugin.QueueMessageRow, 0, len(keys))
+	for _, key := range keys {
+		row := &nosqlplugin.QueueMessageRow{}
+		if _, err := messages.get(key, row); err != nil {
+			return nil, err
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+func queueKey(queueType persistence.QueueType) string {
+	return strconv.Itoa(int(queueType))
+}
+
+func queueMessageKey(queueType persistence.QueueType, messageID int64) string {
+	return rowKey(queueKey(queueType), int64Key(messageID))
+}
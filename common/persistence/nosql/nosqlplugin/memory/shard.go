@@ -0,0 +1,184 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package memory
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/uber/cadence/common/persistence/nosql/nosqlplugin"
+)
+
+const tableShard = "shard"
+
+var _ nosqlplugin.ShardCRUD = (*mdb)(nil)
+
+// shardRecord keeps the range_id apart from the shard data, like the range_id column of the other plugins
+type shardRecord struct {
+	RangeID int64
+	Data    *nosqlplugin.ShardRow
+}
+
+// InsertShard creates a new shard, return error is there is any.
+// Return ShardOperationConditionFailure if the condition doesn't meet
+func (db *mdb) InsertShard(ctx context.Context, row *nosqlplugin.ShardRow) error {
+	s, err := db.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer s.Unlock()
+
+	shards := s.table(tableShard)
+	key := shardKey(row.ShardID)
+	if shards.exists(key) {
+		return newShardConditionFailure(s, row.ShardID)
+	}
+	return shards.put(key, newShardRecord(row))
+}
+
+// SelectShard gets a shard
+func (db *mdb) SelectShard(ctx context.Context, shardID int, currentClusterName string) (int64, *nosqlplugin.ShardRow, error) {
+	s, err := db.begin(ctx)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer s.Unlock()
+
+	record := &shardRecord{}
+	found, err := s.table(tableShard).get(shardKey(shardID), record)
+	if err != nil {
+		return 0, nil, err
+	}
+	if !found {
+		return 0, nil, errRowNotFound
+	}
+
+	shard := record.Data
+	if shard.ClusterTransferAckLevel == nil {
+		shard.ClusterTransferAckLevel = map[string]int64{
+			currentClusterName: shard.TransferAckLevel,
+		}
+	}
+	if shard.ClusterTimerAckLevel == nil {
+		shard.ClusterTimerAckLevel = map[string]time.Time{
+			currentClusterName: shard.TimerAckLevel,
+		}
+	}
+	if shard.ClusterReplicationLevel == nil {
+		shard.ClusterReplicationLevel = make(map[string]int64)
+	}
+	if shard.ReplicationDLQAckLevel == nil {
+		shard.ReplicationDLQAckLevel = make(map[string]int64)
+	}
+	return record.RangeID, shard, nil
+}
+
+// UpdateRangeID updates the rangeID, return error is there is any
+// Return ShardOperationConditionFailure if the condition doesn't meet
+func (db *mdb) UpdateRangeID(ctx context.Context, shardID int, rangeID int64, previousRangeID int64) error {
+	s, err := db.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer s.Unlock()
+
+	shards := s.table(tableShard)
+	record := &shardRecord{}
+	found, err := shards.get(shardKey(shardID), record)
+	if err != nil {
+		return err
+	}
+	if !found || record.RangeID != previousRangeID {
+		return newShardConditionFailure(s, shardID)
+	}
+	record.RangeID = rangeID
+	return shards.put(shardKey(shardID), record)
+}
+
+// UpdateShard updates a shard, return error is there is any.
+// Return ShardOperationConditionFailure if the condition doesn't meet
+func (db *mdb) UpdateShard(ctx context.Context, row *nosqlplugin.ShardRow, previousRangeID int64) error {
+	s, err := db.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer s.Unlock()
+
+	if err := checkShardRangeID(s, row.ShardID, previousRangeID); err != nil {
+		return err
+	}
+	return s.table(tableShard).put(shardKey(row.ShardID), newShardRecord(row))
+}
+
+// checkShardRangeID returns ShardOperationConditionFailure if the rangeID of the shard doesn't match
+func checkShardRangeID(s *store, shardID int, rangeID int64) error {
+	actualRangeID, err := shardRangeID(s, shardID)
+	if err != nil {
+		return err
+	}
+	if actualRangeID != rangeID {
+		return newShardConditionFailure(s, shardID)
+	}
+	return nil
+}
+
+// shardRangeID returns the rangeID of the shard, or -1 if the shard doesn't exist
+func shardRangeID(s *store, shardID int) (int64, error) {
+	record := &shardRecord{}
+	found, err := s.table(tableShard).get(shardKey(shardID), record)
+	if err != nil {
+		return 0, err
+	}
+	if !found {
+		return -1, nil
+	}
+	return record.RangeID, nil
+}
+
+func newShardConditionFailure(s *store, shardID int) error {
+	rangeID, err := shardRangeID(s, shardID)
+	if err != nil {
+		return err
+	}
+	details := "shard not found"
+	if rangeID != -1 {
+		details = fmt.Sprintf("shard_id=%v,range_id=%v", shardID, rangeID)
+	}
+	return &nosqlplugin.ShardOperationConditionFailure{
+		RangeID: rangeID,
+		Details: details,
+	}
+}
+
+func newShardRecord(row *nosqlplugin.ShardRow) *shardRecord {
+	shard := *row
+	shard.UpdatedAt = time.Now()
+	return &shardRecord{
+		RangeID: row.RangeID,
+		Data:    &shard,
+	}
+}
+
+func shardKey(shardID int) string {
+	return strconv.Itoa(shardID)
+}
@@ -0,0 +1,239 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package memory
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// All the tables of a keyspace live in a process wide store, so that every DB created
+// for the same keyspace (e.g. by the different services of a onebox) shares the same data.
+// A store is guarded by a single lock, which makes every operation, including the
+// conditional batches, atomic and serializable.
+var (
+	storesLock sync.Mutex
+	stores     = map[string]*store{}
+)
+
+const (
+	// keySeparator separates the components of a row key, it sorts before any printable character
+	// so that all the rows of a partition are next to each other
+	keySeparator = "\x00"
+	// keyRangeEnd is the first key after all the keys that start with a prefix and keySeparator
+	keyRangeEnd = "\x01"
+)
+
+type (
+	store struct {
+		sync.Mutex
+		tables map[string]*table
+	}
+
+	// table is an ordered key value table, the rows are stored JSON encoded so that
+	// readers and writers never share any memory with the store
+	table struct {
+		rows map[string][]byte
+	}
+
+	// batch collects the writes of an operation, the rows are encoded when they are added,
+	// so applying the batch after all the conditions are checked cannot fail halfway
+	batch struct {
+		writes []write
+	}
+
+	write struct {
+		table  *table
+		key    string
+		value  []byte
+		delete bool
+	}
+)
+
+func getStore(keyspace string) *store {
+	storesLock.Lock()
+	defer storesLock.Unlock()
+
+	s, ok := stores[keyspace]
+	if !ok {
+		s = newStore()
+		stores[keyspace] = s
+	}
+	return s
+}
+
+func resetStore(keyspace string) {
+	storesLock.Lock()
+	defer storesLock.Unlock()
+
+	stores[keyspace] = newStore()
+}
+
+func dropStore(keyspace string) {
+	storesLock.Lock()
+	defer storesLock.Unlock()
+
+	delete(stores, keyspace)
+}
+
+func newStore() *store {
+	return &store{
+		tables: make(map[string]*table),
+	}
+}
+
+func (s *store) table(name string) *table {
+	t, ok := s.tables[name]
+	if !ok {
+		t = &table{
+			rows: make(map[string][]byte),
+		}
+		s.tables[name] = t
+	}
+	return t
+}
+
+// get decodes the row of the key into out, returns false if the row doesn't exist
+func (t *table) get(key string, out interface{}) (bool, error) {
+	value, ok := t.rows[key]
+	if !ok {
+		return false, nil
+	}
+	if err := json.Unmarshal(value, out); err != nil {
+		return false, fmt.Errorf("corrupted row %q: %v", key, err)
+	}
+	return true, nil
+}
+
+func (t *table) exists(key string) bool {
+	_, ok := t.rows[key]
+	return ok
+}
+
+func (t *table) put(key string, row interface{}) error {
+	value, err := json.Marshal(row)
+	if err != nil {
+		return err
+	}
+	t.rows[key] = value
+	return nil
+}
+
+func (t *table) delete(key string) {
+	delete(t.rows, key)
+}
+
+// scan returns the sorted keys within [inclusiveBegin, exclusiveEnd)
+func (t *table) scan(inclusiveBegin, exclusiveEnd string) []string {
+	var keys []string
+	for key := range t.rows {
+		if key >= inclusiveBegin && key < exclusiveEnd {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// scanPrefix returns the sorted keys of all the rows under the key prefix
+func (t *table) scanPrefix(prefix ...string) []string {
+	p := rowKey(prefix...)
+	return t.scan(p+keySeparator, p+keyRangeEnd)
+}
+
+// scanAll returns the sorted keys of all the rows
+func (t *table) scanAll() []string {
+	keys := make([]string, 0, len(t.rows))
+	for key := range t.rows {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func (b *batch) put(t *table, key string, row interface{}) error {
+	value, err := json.Marshal(row)
+	if err != nil {
+		return err
+	}
+	b.writes = append(b.writes, write{table: t, key: key, value: value})
+	return nil
+}
+
+func (b *batch) delete(t *table, key string) {
+	b.writes = append(b.writes, write{table: t, key: key, delete: true})
+}
+
+func (b *batch) apply() {
+	for _, w := range b.writes {
+		if w.delete {
+			delete(w.table.rows, w.key)
+		} else {
+			w.table.rows[w.key] = w.value
+		}
+	}
+}
+
+// rowKey joins the components of a key
+func rowKey(components ...string) string {
+	return strings.Join(components, keySeparator)
+}
+
+// int64Key encodes an integer as a fixed width string which sorts in the same order as the integer
+func int64Key(v int64) string {
+	return fmt.Sprintf("%020d", uint64(v)^(1<<63))
+}
+
+// descInt64Key encodes an integer as a fixed width string which sorts in the reverse order of the integer
+func descInt64Key(v int64) string {
+	return int64Key(^v)
+}
+
+// int64Range returns the key range of the integer range component within [inclusiveMin, inclusiveMax] under the prefix
+func int64Range(inclusiveMin, inclusiveMax int64, prefix ...string) (string, string) {
+	p := rowKey(prefix...)
+	if inclusiveMax == math.MaxInt64 {
+		return p + keySeparator + int64Key(inclusiveMin), p + keyRangeEnd
+	}
+	return p + keySeparator + int64Key(inclusiveMin), p + keySeparator + int64Key(inclusiveMax+1)
+}
+
+// page returns up to pageSize keys of the sorted keys after the pageToken, and the token
+// of the next page if there are more keys. The token is the last key of the page,
+// so that the pagination is not affected by rows inserted or deleted in between.
+func page(keys []string, pageSize int, pageToken []byte) ([]string, []byte) {
+	if len(pageToken) > 0 {
+		start := sort.SearchStrings(keys, string(pageToken))
+		if start < len(keys) && keys[start] == string(pageToken) {
+			start++
+		}
+		keys = keys[start:]
+	}
+	if pageSize <= 0 || len(keys) <= pageSize {
+		return keys, nil
+	}
+	keys = keys[:pageSize]
+	return keys, []byte(keys[len(keys)-1])
+}
@@ -0,0 +1,86 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package memory
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInt64KeyOrder(t *testing.T) {
+	values := []int64{math.MinInt64, -100, -1, 0, 1, 100, math.MaxInt64}
+	for i := 1; i < len(values); i++ {
+		assert.True(t, int64Key(values[i-1]) < int64Key(values[i]))
+		assert.True(t, descInt64Key(values[i-1]) > descInt64Key(values[i]))
+	}
+}
+
+func TestInt64Range(t *testing.T) {
+	tbl := &table{rows: make(map[string][]byte)}
+	for _, v := range []int64{math.MinInt64, 1, 2, 3, math.MaxInt64} {
+		assert.NoError(t, tbl.put(rowKey("p", int64Key(v)), v))
+		assert.NoError(t, tbl.put(rowKey("q", int64Key(v)), v))
+	}
+
+	assert.Equal(t, []string{rowKey("p", int64Key(2)), rowKey("p", int64Key(3))}, tbl.scan(int64Range(2, 3, "p")))
+	assert.Len(t, tbl.scan(int64Range(math.MinInt64, math.MaxInt64, "p")), 5)
+	assert.Len(t, tbl.scanPrefix("q"), 5)
+}
+
+func TestPage(t *testing.T) {
+	keys := []string{"a", "b", "c", "d", "e"}
+	result, token := page(keys, 2, nil)
+	assert.Equal(t, []string{"a", "b"}, result)
+	assert.Equal(t, []byte("b"), token)
+
+	result, token = page(keys, 2, token)
+	assert.Equal(t, []string{"c", "d"}, result)
+
+	result, token = page(keys, 2, token)
+	assert.Equal(t, []string{"e"}, result)
+	assert.Nil(t, token)
+
+	result, token = page(keys, 0, nil)
+	assert.Equal(t, keys, result)
+	assert.Nil(t, token)
+}
+
+func TestBatchApply(t *testing.T) {
+	s := newStore()
+	tbl := s.table("t")
+	assert.NoError(t, tbl.put("a", 1))
+
+	b := &batch{}
+	assert.NoError(t, b.put(tbl, "b", 2))
+	b.delete(tbl, "a")
+	assert.True(t, tbl.exists("a"))
+	assert.False(t, tbl.exists("b"))
+
+	b.apply()
+	assert.False(t, tbl.exists("a"))
+	var v int
+	found, err := tbl.get("b", &v)
+	assert.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, 2, v)
+}
@@ -0,0 +1,291 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package memory
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/uber/cadence/common/persistence/nosql/nosqlplugin"
+)
+
+const (
+	tableTaskList = "tasklist"
+	tableTask     = "task"
+
+	initialRangeID = 1 // Id of the first range of a new task list
+)
+
+var _ nosqlplugin.TaskCRUD = (*mdb)(nil)
+
+// SelectTaskList returns a single tasklist row.
+// Return IsNotFoundError if the row doesn't exist
+func (db *mdb) SelectTaskList(ctx context.Context, filter *nosqlplugin.TaskListFilter) (*nosqlplugin.TaskListRow, error) {
+	s, err := db.begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer s.Unlock()
+
+	row, err := selectTaskList(s, filter)
+	if err != nil {
+		return nil, err
+	}
+	if row == nil {
+		return nil, errRowNotFound
+	}
+	return row, nil
+}
+
+// InsertTaskList insert a single tasklist row
+// Return TaskOperationConditionFailure if the row already exists
+func (db *mdb) InsertTaskList(ctx context.Context, row *nosqlplugin.TaskListRow) error {
+	s, err := db.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer s.Unlock()
+
+	filter := taskListFilter(row)
+	current, err := selectTaskList(s, filter)
+	if err != nil {
+		return err
+	}
+	if current != nil {
+		return newTaskListConditionFailure(current)
+	}
+
+	newRow := *row
+	newRow.RangeID = initialRangeID
+	newRow.AckLevel = 0
+	return s.table(tableTaskList).put(taskListKey(filter), &newRow)
+}
+
+// UpdateTaskList updates a single tasklist row
+// Return TaskOperationConditionFailure if the condition doesn't meet
+func (db *mdb) UpdateTaskList(
+	ctx context.Context,
+	row *nosqlplugin.TaskListRow,
+	previousRangeID int64,
+) error {
+	return db.updateTaskList(ctx, row, previousRangeID)
+}
+
+// UpdateTaskListWithTTL updates a single tasklist row
+// Return TaskOperationConditionFailure if the condition doesn't meet
+// NOTE: the in-memory store doesn't expire rows, so the TTL is ignored and ListTaskList is implemented for TaskListScavenger
+func (db *mdb) UpdateTaskListWithTTL(
+	ctx context.Context,
+	ttlSeconds int64,
+	row *nosqlplugin.TaskListRow,
+	previousRangeID int64,
+) error {
+	newRow := *row
+	newRow.LastUpdatedTime = time.Now()
+	return db.updateTaskList(ctx, &newRow, previousRangeID)
+}
+
+func (db *mdb) updateTaskList(
+	ctx context.Context,
+	row *nosqlplugin.TaskListRow,
+	previousRangeID int64,
+) error {
+	s, err := db.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer s.Unlock()
+
+	filter := taskListFilter(row)
+	if err := checkTaskListRangeID(s, filter, previousRangeID); err != nil {
+		return err
+	}
+	return s.table(tableTaskList).put(taskListKey(filter), row)
+}
+
+// ListTaskList returns all tasklists.
+func (db *mdb) ListTaskList(ctx context.Context, pageSize int, nextPageToken []byte) (*nosqlplugin.ListTaskListResult, error) {
+	s, err := db.begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer s.Unlock()
+
+	taskLists := s.table(tableTaskList)
+	keys, token := page(taskLists.scanAll(), pageSize, nextPageToken)
+	result := &nosqlplugin.ListTaskListResult{
+		TaskLists:     make([]*nosqlplugin.TaskListRow, 0, len(keys)),
+		NextPageToken: token,
+	}
+	for _, key := range keys {
+		row := &nosqlplugin.TaskListRow{}
+		if _, err := taskLists.get(key, row); err != nil {
+			return nil, err
+		}
+		result.TaskLists = append(result.TaskLists, row)
+	}
+	return result, nil
+}
+
+// DeleteTaskList deletes a single tasklist row
+// Return TaskOperationConditionFailure if the condition doesn't meet
+func (db *mdb) DeleteTaskList(ctx context.Context, filter *nosqlplugin.TaskListFilter, previousRangeID int64) error {
+	s, err := db.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer s.Unlock()
+
+	if err := checkTaskListRangeID(s, filter, previousRangeID); err != nil {
+		return err
+	}
+	s.table(tableTaskList).delete(taskListKey(filter))
+	return nil
+}
+
+// InsertTasks inserts a batch of tasks
+// Return TaskOperationConditionFailure if the condition doesn't meet
+func (db *mdb) InsertTasks(
+	ctx context.Context,
+	tasksToInsert []*nosqlplugin.TaskRowForInsert,
+	tasklistCondition *nosqlplugin.TaskListRow,
+) error {
+	s, err := db.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer s.Unlock()
+
+	filter := taskListFilter(tasklistCondition)
+	if err := checkTaskListRangeID(s, filter, tasklistCondition.RangeID); err != nil {
+		return err
+	}
+
+	b := &batch{}
+	tasks := s.table(tableTask)
+	for _, task := range tasksToInsert {
+		if err := b.put(tasks, rowKey(taskListKey(filter), int64Key(task.TaskID)), &task.TaskRow); err != nil {
+			return err
+		}
+	}
+	b.apply()
+	return nil
+}
+
+// SelectTasks return tasks that associated to a tasklist
+func (db *mdb) SelectTasks(ctx context.Context, filter *nosqlplugin.TasksFilter) ([]*nosqlplugin.TaskRow, error) {
+	if filter.MinTaskID >= filter.MaxTaskID {
+		return nil, nil
+	}
+
+	s, err := db.begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer s.Unlock()
+
+	tasks := s.table(tableTask)
+	keys := tasks.scan(int64Range(filter.MinTaskID+1, filter.MaxTaskID, taskListKey(&filter.TaskListFilter)))
+	if filter.BatchSize > 0 && len(keys) > filter.BatchSize {
+		keys = keys[:filter.BatchSize]
+	}
+	rows := make([]*nosqlplugin.TaskRow, 0, len(keys))
+	for _, key := range keys {
+		row := &nosqlplugin.TaskRow{}
+		if _, err := tasks.get(key, row); err != nil {
+			return nil, err
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// RangeDeleteTasks delete a batch tasks that taskIDs within the range, up to BatchSize of the
+// tasks with the lowest taskIDs are deleted
+func (db *mdb) RangeDeleteTasks(ctx context.Context, filter *nosqlplugin.TasksFilter) (rowsDeleted int, err error) {
+	if filter.MinTaskID >= filter.MaxTaskID {
+		return 0, nil
+	}
+
+	s, err := db.begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer s.Unlock()
+
+	tasks := s.table(tableTask)
+	keys := tasks.scan(int64Range(filter.MinTaskID+1, filter.MaxTaskID, taskListKey(&filter.TaskListFilter)))
+	if filter.BatchSize > 0 && len(keys) > filter.BatchSize {
+		keys = keys[:filter.BatchSize]
+	}
+	for _, key := range keys {
+		tasks.delete(key)
+	}
+	return len(keys), nil
+}
+
+func selectTaskList(s *store, filter *nosqlplugin.TaskListFilter) (*nosqlplugin.TaskListRow, error) {
+	row := &nosqlplugin.TaskListRow{}
+	found, err := s.table(tableTaskList).get(taskListKey(filter), row)
+	if err != nil || !found {
+		return nil, err
+	}
+	return row, nil
+}
+
+// checkTaskListRangeID returns TaskOperationConditionFailure if the range_id of the tasklist is not the expected one
+func checkTaskListRangeID(s *store, filter *nosqlplugin.TaskListFilter, rangeID int64) error {
+	row, err := selectTaskList(s, filter)
+	if err != nil {
+		return err
+	}
+	if row == nil || row.RangeID != rangeID {
+		return newTaskListConditionFailure(row)
+	}
+	return nil
+}
+
+func newTaskListConditionFailure(row *nosqlplugin.TaskListRow) error {
+	if row == nil {
+		return &nosqlplugin.TaskOperationConditionFailure{
+			RangeID: -1,
+			Details: "tasklist not found",
+		}
+	}
+	return &nosqlplugin.TaskOperationConditionFailure{
+		RangeID: row.RangeID,
+		Details: fmt.Sprintf("range_id=%v", row.RangeID),
+	}
+}
+
+func taskListFilter(row *nosqlplugin.TaskListRow) *nosqlplugin.TaskListFilter {
+	return &nosqlplugin.TaskListFilter{
+		DomainID:     row.DomainID,
+		TaskListName: row.TaskListName,
+		TaskListType: row.TaskListType,
+	}
+}
+
+func taskListKey(filter *nosqlplugin.TaskListFilter) string {
+	return rowKey(filter.DomainID, strconv.Itoa(filter.TaskListType), filter.TaskListName)
+}
@@ -0,0 +1,200 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package memory
+
+import (
+	"context"
+	"sort"
+
+	"github.com/uber/cadence/common/persistence"
+	"github.com/uber/cadence/common/persistence/nosql/nosqlplugin"
+)
+
+const tableVisibility = "visibility"
+
+var _ nosqlplugin.VisibilityCRUD = (*mdb)(nil)
+
+// visibilityRecord is the single visibility record of a workflow run, the open and closed
+// records are told apart by the Closed flag
+type visibilityRecord struct {
+	Row    *nosqlplugin.VisibilityRow
+	Closed bool
+}
+
+// InsertVisibility creates a new visibility record, return error is there is any.
+// NOTE: the in-memory store doesn't expire rows, so the TTL is ignored
+func (db *mdb) InsertVisibility(
+	ctx context.Context,
+	ttlSeconds int64,
+	row *nosqlplugin.VisibilityRowForInsert,
+) error {
+	return db.putVisibility(ctx, row.DomainID, &row.VisibilityRow, false)
+}
+
+func (db *mdb) UpdateVisibility(
+	ctx context.Context,
+	ttlSeconds int64,
+	row *nosqlplugin.VisibilityRowForUpdate,
+) error {
+	if row.UpdateCloseToOpen {
+		// TODO implement it when where is a need
+		panic("not supported operation")
+	}
+	return db.putVisibility(ctx, row.DomainID, &row.VisibilityRow, true)
+}
+
+func (db *mdb) SelectVisibility(
+	ctx context.Context,
+	filter *nosqlplugin.VisibilityFilter,
+) (*nosqlplugin.SelectVisibilityResponse, error) {
+	request := &filter.ListRequest
+
+	var isOpen bool
+	var extraFilter func(row *nosqlplugin.VisibilityRow) bool
+	switch filter.FilterType {
+	case nosqlplugin.AllOpen:
+		isOpen = true
+	case nosqlplugin.AllClosed:
+	case nosqlplugin.OpenByWorkflowType:
+		isOpen = true
+		extraFilter = func(row *nosqlplugin.VisibilityRow) bool { return row.TypeName == filter.WorkflowType }
+	case nosqlplugin.ClosedByWorkflowType:
+		extraFilter = func(row *nosqlplugin.VisibilityRow) bool { return row.TypeName == filter.WorkflowType }
+	case nosqlplugin.OpenByWorkflowID:
+		isOpen = true
+		extraFilter = func(row *nosqlplugin.VisibilityRow) bool { return row.WorkflowID == filter.WorkflowID }
+	case nosqlplugin.ClosedByWorkflowID:
+		extraFilter = func(row *nosqlplugin.VisibilityRow) bool { return row.WorkflowID == filter.WorkflowID }
+	case nosqlplugin.ClosedByClosedStatus:
+		extraFilter = func(row *nosqlplugin.VisibilityRow) bool {
+			return row.Status != nil && int32(*row.Status) == filter.CloseStatus
+		}
+	default:
+		panic("no supported filter type")
+	}
+
+	sortByCloseTime := false
+	if !isOpen {
+		switch filter.SortType {
+		case nosqlplugin.SortByStartTime:
+		case nosqlplugin.SortByClosedTime:
+			sortByCloseTime = true
+		default:
+			panic("not supported sorting type")
+		}
+	}
+
+	s, err := db.begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer s.Unlock()
+
+	// the matched records are ordered by the time descending, the sort keys are used as the page tokens
+	visibility := s.table(tableVisibility)
+	rows := make(map[string]*nosqlplugin.VisibilityRow)
+	var sortKeys []string
+	for _, key := range visibility.scanPrefix(request.DomainUUID) {
+		record := &visibilityRecord{}
+		if _, err := visibility.get(key, record); err != nil {
+			return nil, err
+		}
+		row := record.Row
+		if record.Closed == isOpen || (extraFilter != nil && !extraFilter(row)) {
+			continue
+		}
+		timestamp := row.StartTime
+		if sortByCloseTime {
+			timestamp = row.CloseTime
+		}
+		if timestamp.Before(request.EarliestTime) || timestamp.After(request.LatestTime) {
+			continue
+		}
+		sortKey := rowKey(descInt64Key(timestamp.UnixNano()), row.WorkflowID, row.RunID)
+		rows[sortKey] = row
+		sortKeys = append(sortKeys, sortKey)
+	}
+	sort.Strings(sortKeys)
+
+	keys, nextPageToken := page(sortKeys, request.PageSize, request.NextPageToken)
+	response := &nosqlplugin.SelectVisibilityResponse{
+		Executions:    make([]*persistence.InternalVisibilityWorkflowExecutionInfo, 0, len(keys)),
+		NextPageToken: nextPageToken,
+	}
+	for _, key := range keys {
+		response.Executions = append(response.Executions, rows[key])
+	}
+	return response, nil
+}
+
+func (db *mdb) DeleteVisibility(
+	ctx context.Context,
+	domainID, workflowID, runID string,
+) error {
+	s, err := db.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer s.Unlock()
+
+	s.table(tableVisibility).delete(rowKey(domainID, workflowID, runID))
+	return nil
+}
+
+func (db *mdb) SelectOneClosedWorkflow(
+	ctx context.Context,
+	domainID, workflowID, runID string,
+) (*nosqlplugin.VisibilityRow, error) {
+	s, err := db.begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer s.Unlock()
+
+	record := &visibilityRecord{}
+	found, err := s.table(tableVisibility).get(rowKey(domainID, workflowID, runID), record)
+	if err != nil {
+		return nil, err
+	}
+	if !found || !record.Closed {
+		// Special case: return nil,nil if not found(since we will deprecate it, it's not worth refactor to be consistent)
+		return nil, nil
+	}
+	return record.Row, nil
+}
+
+func (db *mdb) putVisibility(
+	ctx context.Context,
+	domainID string,
+	row *nosqlplugin.VisibilityRow,
+	closed bool,
+) error {
+	s, err := db.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer s.Unlock()
+
+	return s.table(tableVisibility).put(rowKey(domainID, row.WorkflowID, row.RunID), &visibilityRecord{
+		Row:    row,
+		Closed: closed,
+	})
+}
@@ -0,0 +1,579 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package memory
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/uber/cadence/common/persistence"
+	"github.com/uber/cadence/common/persistence/nosql/nosqlplugin"
+)
+
+var _ nosqlplugin.WorkflowCRUD = (*mdb)(nil)
+
+func (db *mdb) InsertWorkflowExecutionWithTasks(
+	ctx context.Context,
+	currentWorkflowRequest *nosqlplugin.CurrentWorkflowWriteRequest,
+	execution *nosqlplugin.WorkflowExecutionRequest,
+	transferTasks []*nosqlplugin.TransferTask,
+	crossClusterTasks []*nosqlplugin.CrossClusterTask,
+	replicationTasks []*nosqlplugin.ReplicationTask,
+	timerTasks []*nosqlplugin.TimerTask,
+	shardCondition *nosqlplugin.ShardCondition,
+) error {
+	if execution.MapsWriteMode != nosqlplugin.WorkflowExecutionMapsWriteModeCreate {
+		return fmt.Errorf("should only support WorkflowExecutionMapsWriteModeCreate")
+	}
+	if execution.EventBufferWriteMode != nosqlplugin.EventBufferWriteModeNone {
+		return fmt.Errorf("should only support EventBufferWriteModeNone")
+	}
+
+	s, err := db.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer s.Unlock()
+
+	shardID := shardCondition.ShardID
+	if err := checkWorkflowShardRangeID(s, shardID, shardCondition.RangeID); err != nil {
+		return err
+	}
+
+	current, ok, err := checkCurrentWorkflow(s, shardID, execution.DomainID, execution.WorkflowID, currentWorkflowRequest)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return newCreateWorkflowCurrentConditionFailure(current, currentWorkflowRequest, execution, shardCondition)
+	}
+
+	existing, err := selectWorkflowExecution(s, shardID, execution.DomainID, execution.WorkflowID, execution.RunID)
+	if err != nil {
+		return err
+	}
+	if existing != nil {
+		return newWorkflowExecutionAlreadyExistsFailure(existing, execution, shardCondition)
+	}
+
+	b := &batch{}
+	if err := putCurrentWorkflow(s, b, shardID, execution.DomainID, execution.WorkflowID, currentWorkflowRequest); err != nil {
+		return err
+	}
+	key := workflowExecutionKey(shardID, execution.DomainID, execution.WorkflowID, execution.RunID)
+	if err := b.put(s.table(tableWorkflowExecution), key, newWorkflowExecutionRecord(execution)); err != nil {
+		return err
+	}
+	if err := putAllTasks(s, b, shardID, transferTasks, crossClusterTasks, replicationTasks, timerTasks); err != nil {
+		return err
+	}
+	b.apply()
+	return nil
+}
+
+func (db *mdb) UpdateWorkflowExecutionWithTasks(
+	ctx context.Context,
+	currentWorkflowRequest *nosqlplugin.CurrentWorkflowWriteRequest,
+	mutatedExecution *nosqlplugin.WorkflowExecutionRequest,
+	insertedExecution *nosqlplugin.WorkflowExecutionRequest,
+	resetExecution *nosqlplugin.WorkflowExecutionRequest,
+	transferTasks []*nosqlplugin.TransferTask,
+	crossClusterTasks []*nosqlplugin.CrossClusterTask,
+	replicationTasks []*nosqlplugin.ReplicationTask,
+	timerTasks []*nosqlplugin.TimerTask,
+	shardCondition *nosqlplugin.ShardCondition,
+) error {
+	shardID := shardCondition.ShardID
+	var conditionExecution *nosqlplugin.WorkflowExecutionRequest
+	if mutatedExecution != nil {
+		if mutatedExecution.MapsWriteMode != nosqlplugin.WorkflowExecutionMapsWriteModeUpdate {
+			return fmt.Errorf("should only support WorkflowExecutionMapsWriteModeUpdate")
+		}
+		conditionExecution = mutatedExecution
+	} else if resetExecution != nil {
+		conditionExecution = resetExecution
+	} else {
+		return fmt.Errorf("at least one of mutatedExecution and resetExecution should be provided")
+	}
+	if insertedExecution != nil {
+		if insertedExecution.MapsWriteMode != nosqlplugin.WorkflowExecutionMapsWriteModeCreate {
+			return fmt.Errorf("should only support WorkflowExecutionMapsWriteModeCreate")
+		}
+		if insertedExecution.EventBufferWriteMode != nosqlplugin.EventBufferWriteModeNone {
+			return fmt.Errorf("should only support EventBufferWriteModeNone")
+		}
+	}
+	if resetExecution != nil {
+		if resetExecution.MapsWriteMode != nosqlplugin.WorkflowExecutionMapsWriteModeReset {
+			return fmt.Errorf("should only support WorkflowExecutionMapsWriteModeReset")
+		}
+		if resetExecution.EventBufferWriteMode != nosqlplugin.EventBufferWriteModeClear {
+			return fmt.Errorf("should only support EventBufferWriteModeClear")
+		}
+	}
+	domainID := conditionExecution.DomainID
+	workflowID := conditionExecution.WorkflowID
+	previousNextEventIDCondition := *conditionExecution.PreviousNextEventIDCondition
+
+	s, err := db.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer s.Unlock()
+
+	if err := checkWorkflowShardRangeID(s, shardID, shardCondition.RangeID); err != nil {
+		return err
+	}
+
+	requestConditionalRunID := currentWorkflowRequest.Condition.GetCurrentRunID()
+	conditionRecord, err := selectWorkflowExecution(s, shardID, domainID, workflowID, conditionExecution.RunID)
+	if err != nil {
+		return err
+	}
+	actualNextEventID := int64(0)
+	if conditionRecord != nil {
+		actualNextEventID = conditionRecord.State.ExecutionInfo.NextEventID
+	}
+
+	current, ok, err := checkCurrentWorkflow(s, shardID, domainID, workflowID, currentWorkflowRequest)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		actualCurrRunID := ""
+		if current != nil {
+			actualCurrRunID = current.RunID
+		}
+		msg := fmt.Sprintf("Failed to update mutable state.  Request Condition: %v, Actual Value: %v, Request Current RunID: %v, Actual Value: %v",
+			previousNextEventIDCondition, actualNextEventID, requestConditionalRunID, actualCurrRunID)
+		return &nosqlplugin.WorkflowOperationConditionFailure{
+			CurrentWorkflowConditionFailInfo: &msg,
+		}
+	}
+
+	b := &batch{}
+	executions := s.table(tableWorkflowExecution)
+	for _, execution := range []*nosqlplugin.WorkflowExecutionRequest{mutatedExecution, resetExecution} {
+		if execution == nil {
+			continue
+		}
+		record, err := selectWorkflowExecution(s, shardID, execution.DomainID, execution.WorkflowID, execution.RunID)
+		if err != nil {
+			return err
+		}
+		if record == nil || record.State.ExecutionInfo.NextEventID != *execution.PreviousNextEventIDCondition {
+			msg := fmt.Sprintf("Failed to update mutable state.  Request Condition: %v, Actual Value: %v, Request Current RunID: %v",
+				previousNextEventIDCondition, actualNextEventID, requestConditionalRunID)
+			return &nosqlplugin.WorkflowOperationConditionFailure{
+				UnknownConditionFailureDetails: &msg,
+			}
+		}
+		if execution == mutatedExecution {
+			if err := updateWorkflowExecutionRecord(record, execution); err != nil {
+				return err
+			}
+		} else {
+			record = newWorkflowExecutionRecord(execution)
+		}
+		key := workflowExecutionKey(shardID, execution.DomainID, execution.WorkflowID, execution.RunID)
+		if err := b.put(executions, key, record); err != nil {
+			return err
+		}
+	}
+
+	if err := putCurrentWorkflow(s, b, shardID, domainID, workflowID, currentWorkflowRequest); err != nil {
+		return err
+	}
+	if insertedExecution != nil {
+		key := workflowExecutionKey(shardID, insertedExecution.DomainID, insertedExecution.WorkflowID, insertedExecution.RunID)
+		if err := b.put(executions, key, newWorkflowExecutionRecord(insertedExecution)); err != nil {
+			return err
+		}
+	}
+	if err := putAllTasks(s, b, shardID, transferTasks, crossClusterTasks, replicationTasks, timerTasks); err != nil {
+		return err
+	}
+	b.apply()
+	return nil
+}
+
+func (db *mdb) SelectCurrentWorkflow(ctx context.Context, shardID int, domainID, workflowID string) (*nosqlplugin.CurrentWorkflowRow, error) {
+	s, err := db.begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer s.Unlock()
+
+	row, err := selectCurrentWorkflow(s, shardID, domainID, workflowID)
+	if err != nil {
+		return nil, err
+	}
+	if row == nil {
+		return nil, errRowNotFound
+	}
+	return row, nil
+}
+
+func (db *mdb) SelectWorkflowExecution(ctx context.Context, shardID int, domainID, workflowID, runID string) (*nosqlplugin.WorkflowExecution, error) {
+	s, err := db.begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer s.Unlock()
+
+	record, err := selectWorkflowExecution(s, shardID, domainID, workflowID, runID)
+	if err != nil {
+		return nil, err
+	}
+	if record == nil {
+		return nil, errRowNotFound
+	}
+	return record.State, nil
+}
+
+func (db *mdb) DeleteCurrentWorkflow(ctx context.Context, shardID int, domainID, workflowID, currentRunIDCondition string) error {
+	s, err := db.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer s.Unlock()
+
+	current, err := selectCurrentWorkflow(s, shardID, domainID, workflowID)
+	if err != nil {
+		return err
+	}
+	// the current workflow may have moved on to another run, which must not be deleted
+	if current != nil && current.RunID == currentRunIDCondition {
+		s.table(tableCurrentWorkflow).delete(currentWorkflowKey(shardID, domainID, workflowID))
+	}
+	return nil
+}
+
+func (db *mdb) DeleteWorkflowExecution(ctx context.Context, shardID int, domainID, workflowID, runID string) error {
+	return db.deleteRow(ctx, tableWorkflowExecution, workflowExecutionKey(shardID, domainID, workflowID, runID))
+}
+
+func (db *mdb) SelectAllCurrentWorkflows(ctx context.Context, shardID int, pageToken []byte, pageSize int) ([]*persistence.CurrentWorkflowExecution, []byte, error) {
+	s, err := db.begin(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer s.Unlock()
+
+	currentWorkflows := s.table(tableCurrentWorkflow)
+	keys, nextPageToken := page(currentWorkflows.scanPrefix(shardKey(shardID)), pageSize, pageToken)
+	executions := make([]*persistence.CurrentWorkflowExecution, 0, len(keys))
+	for _, key := range keys {
+		row := &nosqlplugin.CurrentWorkflowRow{}
+		if _, err := currentWorkflows.get(key, row); err != nil {
+			return nil, nil, err
+		}
+		executions = append(executions, &persistence.CurrentWorkflowExecution{
+			DomainID:     row.DomainID,
+			WorkflowID:   row.WorkflowID,
+			RunID:        permanentRunID,
+			State:        row.State,
+			CurrentRunID: row.RunID,
+		})
+	}
+	return executions, nextPageToken, nil
+}
+
+func (db *mdb) SelectAllWorkflowExecutions(ctx context.Context, shardID int, pageToken []byte, pageSize int) ([]*persistence.InternalListConcreteExecutionsEntity, []byte, error) {
+	s, err := db.begin(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer s.Unlock()
+
+	executions := s.table(tableWorkflowExecution)
+	keys, nextPageToken := page(executions.scanPrefix(shardKey(shardID)), pageSize, pageToken)
+	entities := make([]*persistence.InternalListConcreteExecutionsEntity, 0, len(keys))
+	for _, key := range keys {
+		record := &workflowExecutionRecord{}
+		if _, err := executions.get(key, record); err != nil {
+			return nil, nil, err
+		}
+		entities = append(entities, &persistence.InternalListConcreteExecutionsEntity{
+			ExecutionInfo:    record.State.ExecutionInfo,
+			VersionHistories: record.State.VersionHistories,
+		})
+	}
+	return entities, nextPageToken, nil
+}
+
+func (db *mdb) IsWorkflowExecutionExists(ctx context.Context, shardID int, domainID, workflowID, runID string) (bool, error) {
+	s, err := db.begin(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer s.Unlock()
+
+	return s.table(tableWorkflowExecution).exists(workflowExecutionKey(shardID, domainID, workflowID, runID)), nil
+}
+
+func (db *mdb) SelectTransferTasksOrderByTaskID(ctx context.Context, shardID, pageSize int, pageToken []byte, exclusiveMinTaskID, inclusiveMaxTaskID int64) ([]*nosqlplugin.TransferTask, []byte, error) {
+	var tasks []*nosqlplugin.TransferTask
+	nextPageToken, err := db.selectTasksByTaskID(ctx, tableTransferTask, pageSize, pageToken, exclusiveMinTaskID, inclusiveMaxTaskID, func(t *table, key string) error {
+		task := &nosqlplugin.TransferTask{}
+		if _, err := t.get(key, task); err != nil {
+			return err
+		}
+		tasks = append(tasks, task)
+		return nil
+	}, shardKey(shardID))
+	if err != nil {
+		return nil, nil, err
+	}
+	return tasks, nextPageToken, nil
+}
+
+func (db *mdb) DeleteTransferTask(ctx context.Context, shardID int, taskID int64) error {
+	return db.deleteRow(ctx, tableTransferTask, shardTaskKey(shardID, taskID))
+}
+
+func (db *mdb) RangeDeleteTransferTasks(ctx context.Context, shardID int, exclusiveBeginTaskID, inclusiveEndTaskID int64) error {
+	return db.rangeDeleteTasksByTaskID(ctx, tableTransferTask, exclusiveBeginTaskID, inclusiveEndTaskID, shardKey(shardID))
+}
+
+func (db *mdb) SelectTimerTasksOrderByVisibilityTime(ctx context.Context, shardID, pageSize int, pageToken []byte, inclusiveMinTime, exclusiveMaxTime time.Time) ([]*nosqlplugin.TimerTask, []byte, error) {
+	s, err := db.begin(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer s.Unlock()
+
+	timers := s.table(tableTimerTask)
+	keys, nextPageToken := page(timerRange(timers, shardID, inclusiveMinTime, exclusiveMaxTime), pageSize, pageToken)
+	tasks := make([]*nosqlplugin.TimerTask, 0, len(keys))
+	for _, key := range keys {
+		task := &nosqlplugin.TimerTask{}
+		if _, err := timers.get(key, task); err != nil {
+			return nil, nil, err
+		}
+		tasks = append(tasks, task)
+	}
+	return tasks, nextPageToken, nil
+}
+
+func (db *mdb) DeleteTimerTask(ctx context.Context, shardID int, taskID int64, visibilityTimestamp time.Time) error {
+	return db.deleteRow(ctx, tableTimerTask, timerTaskKey(shardID, visibilityTimestamp, taskID))
+}
+
+func (db *mdb) RangeDeleteTimerTasks(ctx context.Context, shardID int, inclusiveMinTime, exclusiveMaxTime time.Time) error {
+	s, err := db.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer s.Unlock()
+
+	timers := s.table(tableTimerTask)
+	for _, key := range timerRange(timers, shardID, inclusiveMinTime, exclusiveMaxTime) {
+		timers.delete(key)
+	}
+	return nil
+}
+
+func (db *mdb) SelectReplicationTasksOrderByTaskID(ctx context.Context, shardID, pageSize int, pageToken []byte, exclusiveMinTaskID, inclusiveMaxTaskID int64) ([]*nosqlplugin.ReplicationTask, []byte, error) {
+	return db.selectReplicationTasks(ctx, tableReplicationTask, pageSize, pageToken, exclusiveMinTaskID, inclusiveMaxTaskID, shardKey(shardID))
+}
+
+func (db *mdb) DeleteReplicationTask(ctx context.Context, shardID int, taskID int64) error {
+	return db.deleteRow(ctx, tableReplicationTask, shardTaskKey(shardID, taskID))
+}
+
+func (db *mdb) RangeDeleteReplicationTasks(ctx context.Context, shardID int, inclusiveEndTaskID int64) error {
+	return db.rangeDeleteTasksByTaskID(ctx, tableReplicationTask, math.MinInt64, inclusiveEndTaskID, shardKey(shardID))
+}
+
+func (db *mdb) InsertReplicationTask(ctx context.Context, tasks []*nosqlplugin.ReplicationTask, shardCondition nosqlplugin.ShardCondition) error {
+	if len(tasks) == 0 {
+		return nil
+	}
+
+	s, err := db.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer s.Unlock()
+
+	shardID := shardCondition.ShardID
+	actualRangeID, err := shardRangeID(s, shardID)
+	if err != nil {
+		return err
+	}
+	if actualRangeID != shardCondition.RangeID {
+		return &nosqlplugin.ShardOperationConditionFailure{
+			RangeID: actualRangeID,
+			Details: fmt.Sprintf("Failed to insert replication tasks. ShardID: %v, request_range_id: %v, actual_range_id: %v",
+				shardID, shardCondition.RangeID, actualRangeID),
+		}
+	}
+
+	b := &batch{}
+	if err := putReplicationTasks(s, b, shardID, tasks); err != nil {
+		return err
+	}
+	b.apply()
+	return nil
+}
+
+func (db *mdb) SelectCrossClusterTasksOrderByTaskID(ctx context.Context, shardID, pageSize int, pageToken []byte, targetCluster string, exclusiveMinTaskID, inclusiveMaxTaskID int64) ([]*nosqlplugin.CrossClusterTask, []byte, error) {
+	var tasks []*nosqlplugin.CrossClusterTask
+	nextPageToken, err := db.selectTasksByTaskID(ctx, tableCrossClusterTask, pageSize, pageToken, exclusiveMinTaskID, inclusiveMaxTaskID, func(t *table, key string) error {
+		task := &nosqlplugin.CrossClusterTask{
+			TargetCluster: targetCluster,
+		}
+		if _, err := t.get(key, &task.TransferTask); err != nil {
+			return err
+		}
+		tasks = append(tasks, task)
+		return nil
+	}, shardKey(shardID), targetCluster)
+	if err != nil {
+		return nil, nil, err
+	}
+	return tasks, nextPageToken, nil
+}
+
+func (db *mdb) DeleteCrossClusterTask(ctx context.Context, shardID int, targetCluster string, taskID int64) error {
+	return db.deleteRow(ctx, tableCrossClusterTask, shardClusterTaskKey(shardID, targetCluster, taskID))
+}
+
+func (db *mdb) RangeDeleteCrossClusterTasks(ctx context.Context, shardID int, targetCluster string, exclusiveBeginTaskID, inclusiveEndTaskID int64) error {
+	return db.rangeDeleteTasksByTaskID(ctx, tableCrossClusterTask, exclusiveBeginTaskID, inclusiveEndTaskID, shardKey(shardID), targetCluster)
+}
+
+func (db *mdb) InsertReplicationDLQTask(ctx context.Context, shardID int, sourceCluster string, task nosqlplugin.ReplicationTask) error {
+	s, err := db.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer s.Unlock()
+
+	return s.table(tableReplicationDLQTask).put(shardClusterTaskKey(shardID, sourceCluster, task.TaskID), &task)
+}
+
+func (db *mdb) SelectReplicationDLQTasksOrderByTaskID(ctx context.Context, shardID int, sourceCluster string, pageSize int, pageToken []byte, exclusiveMinTaskID, inclusiveMaxTaskID int64) ([]*nosqlplugin.ReplicationTask, []byte, error) {
+	return db.selectReplicationTasks(ctx, tableReplicationDLQTask, pageSize, pageToken, exclusiveMinTaskID, inclusiveMaxTaskID, shardKey(shardID), sourceCluster)
+}
+
+func (db *mdb) SelectReplicationDLQTasksCount(ctx context.Context, shardID int, sourceCluster string) (int64, error) {
+	s, err := db.begin(ctx)
+	if err != nil {
+		return -1, err
+	}
+	defer s.Unlock()
+
+	return int64(len(s.table(tableReplicationDLQTask).scanPrefix(shardKey(shardID), sourceCluster))), nil
+}
+
+func (db *mdb) DeleteReplicationDLQTask(ctx context.Context, shardID int, sourceCluster string, taskID int64) error {
+	return db.deleteRow(ctx, tableReplicationDLQTask, shardClusterTaskKey(shardID, sourceCluster, taskID))
+}
+
+func (db *mdb) RangeDeleteReplicationDLQTasks(ctx context.Context, shardID int, sourceCluster string, exclusiveBeginTaskID, inclusiveEndTaskID int64) error {
+	return db.rangeDeleteTasksByTaskID(ctx, tableReplicationDLQTask, exclusiveBeginTaskID, inclusiveEndTaskID, shardKey(shardID), sourceCluster)
+}
+
+func (db *mdb) selectReplicationTasks(
+	ctx context.Context,
+	tableName string,
+	pageSize int,
+	pageToken []byte,
+	exclusiveMinTaskID int64,
+	inclusiveMaxTaskID int64,
+	partition ...string,
+) ([]*nosqlplugin.ReplicationTask, []byte, error) {
+	var tasks []*nosqlplugin.ReplicationTask
+	nextPageToken, err := db.selectTasksByTaskID(ctx, tableName, pageSize, pageToken, exclusiveMinTaskID, inclusiveMaxTaskID, func(t *table, key string) error {
+		task := &nosqlplugin.ReplicationTask{}
+		if _, err := t.get(key, task); err != nil {
+			return err
+		}
+		tasks = append(tasks, task)
+		return nil
+	}, partition...)
+	if err != nil {
+		return nil, nil, err
+	}
+	return tasks, nextPageToken, nil
+}
+
+// selectTasksByTaskID reads a page of the tasks of a partition within (exclusiveMinTaskID, inclusiveMaxTaskID]
+func (db *mdb) selectTasksByTaskID(
+	ctx context.Context,
+	tableName string,
+	pageSize int,
+	pageToken []byte,
+	exclusiveMinTaskID int64,
+	inclusiveMaxTaskID int64,
+	read func(t *table, key string) error,
+	partition ...string,
+) ([]byte, error) {
+	s, err := db.begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer s.Unlock()
+
+	t := s.table(tableName)
+	keys, nextPageToken := page(taskIDRange(t, exclusiveMinTaskID, inclusiveMaxTaskID, partition...), pageSize, pageToken)
+	for _, key := range keys {
+		if err := read(t, key); err != nil {
+			return nil, err
+		}
+	}
+	return nextPageToken, nil
+}
+
+func (db *mdb) rangeDeleteTasksByTaskID(
+	ctx context.Context,
+	tableName string,
+	exclusiveMinTaskID int64,
+	inclusiveMaxTaskID int64,
+	partition ...string,
+) error {
+	s, err := db.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer s.Unlock()
+
+	t := s.table(tableName)
+	for _, key := range taskIDRange(t, exclusiveMinTaskID, inclusiveMaxTaskID, partition...) {
+		t.delete(key)
+	}
+	return nil
+}
+
+func (db *mdb) deleteRow(ctx context.Context, tableName string, key string) error {
+	s, err := db.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer s.Unlock()
+
+	s.table(tableName).delete(key)
+	return nil
+}
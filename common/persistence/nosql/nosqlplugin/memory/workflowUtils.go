@@ -0,0 +1,397 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package memory
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/uber/cadence/common"
+	p "github.com/uber/cadence/common/persistence"
+	"github.com/uber/cadence/common/persistence/nosql/nosqlplugin"
+)
+
+const (
+	tableCurrentWorkflow    = "current_workflow"
+	tableWorkflowExecution  = "workflow_execution"
+	tableTransferTask       = "transfer_task"
+	tableCrossClusterTask   = "cross_cluster_task"
+	tableReplicationTask    = "replication_task"
+	tableReplicationDLQTask = "replication_dlq_task"
+	tableTimerTask          = "timer_task"
+)
+
+// permanentRunID is the run ID reported for the current workflow records, same as the cassandra plugin
+const permanentRunID = "30000000-0000-f000-f000-000000000001"
+
+var maxUnixNanoTime = time.Unix(0, math.MaxInt64)
+
+// workflowExecutionRecord is a workflow_execution row, the mutable state including all the maps
+// and the buffered events is kept in a single record
+type workflowExecutionRecord struct {
+	State            *nosqlplugin.WorkflowExecution
+	LastWriteVersion int64
+}
+
+func currentWorkflowKey(shardID int, domainID, workflowID string) string {
+	return rowKey(shardKey(shardID), domainID, workflowID)
+}
+
+func workflowExecutionKey(shardID int, domainID, workflowID, runID string) string {
+	return rowKey(shardKey(shardID), domainID, workflowID, runID)
+}
+
+func shardTaskKey(shardID int, taskID int64) string {
+	return rowKey(shardKey(shardID), int64Key(taskID))
+}
+
+func shardClusterTaskKey(shardID int, cluster string, taskID int64) string {
+	return rowKey(shardKey(shardID), cluster, int64Key(taskID))
+}
+
+// timerTaskKey orders the timers of a shard by visibility timestamp and then by task ID
+func timerTaskKey(shardID int, visibilityTimestamp time.Time, taskID int64) string {
+	return rowKey(timerTimeKey(shardID, visibilityTimestamp), int64Key(taskID))
+}
+
+func timerTimeKey(shardID int, visibilityTimestamp time.Time) string {
+	return rowKey(shardKey(shardID), int64Key(toUnixNano(visibilityTimestamp)))
+}
+
+// toUnixNano converts a time to unix nanoseconds, clamped to the range representable by int64
+func toUnixNano(t time.Time) int64 {
+	if t.Before(time.Unix(0, 0)) {
+		return 0
+	}
+	if t.After(maxUnixNanoTime) {
+		return math.MaxInt64
+	}
+	return t.UnixNano()
+}
+
+func selectCurrentWorkflow(s *store, shardID int, domainID, workflowID string) (*nosqlplugin.CurrentWorkflowRow, error) {
+	row := &nosqlplugin.CurrentWorkflowRow{}
+	found, err := s.table(tableCurrentWorkflow).get(currentWorkflowKey(shardID, domainID, workflowID), row)
+	if err != nil || !found {
+		return nil, err
+	}
+	return row, nil
+}
+
+func selectWorkflowExecution(s *store, shardID int, domainID, workflowID, runID string) (*workflowExecutionRecord, error) {
+	record := &workflowExecutionRecord{}
+	found, err := s.table(tableWorkflowExecution).get(workflowExecutionKey(shardID, domainID, workflowID, runID), record)
+	if err != nil || !found {
+		return nil, err
+	}
+	return record, nil
+}
+
+// checkWorkflowShardRangeID returns WorkflowOperationConditionFailure if the range_id of the shard is not the expected one
+func checkWorkflowShardRangeID(s *store, shardID int, rangeID int64) error {
+	actualRangeID, err := shardRangeID(s, shardID)
+	if err != nil {
+		return err
+	}
+	if actualRangeID != rangeID {
+		return &nosqlplugin.WorkflowOperationConditionFailure{
+			ShardRangeIDNotMatch: common.Int64Ptr(actualRangeID),
+		}
+	}
+	return nil
+}
+
+// checkCurrentWorkflow returns the current_workflow row, and whether the condition of the write request is met
+func checkCurrentWorkflow(
+	s *store,
+	shardID int,
+	domainID string,
+	workflowID string,
+	request *nosqlplugin.CurrentWorkflowWriteRequest,
+) (*nosqlplugin.CurrentWorkflowRow, bool, error) {
+	switch request.WriteMode {
+	case nosqlplugin.CurrentWorkflowWriteModeNoop:
+		return nil, true, nil
+	case nosqlplugin.CurrentWorkflowWriteModeInsert:
+		current, err := selectCurrentWorkflow(s, shardID, domainID, workflowID)
+		if err != nil {
+			return nil, false, err
+		}
+		return current, current == nil, nil
+	case nosqlplugin.CurrentWorkflowWriteModeUpdate:
+		if request.Condition == nil || request.Condition.GetCurrentRunID() == "" {
+			return nil, false, fmt.Errorf("CurrentWorkflowWriteModeUpdate require Condition.CurrentRunID")
+		}
+		current, err := selectCurrentWorkflow(s, shardID, domainID, workflowID)
+		if err != nil {
+			return nil, false, err
+		}
+		if current == nil || current.RunID != *request.Condition.CurrentRunID {
+			return current, false, nil
+		}
+		if request.Condition.LastWriteVersion != nil && request.Condition.State != nil &&
+			(current.LastWriteVersion != *request.Condition.LastWriteVersion || current.State != *request.Condition.State) {
+			return current, false, nil
+		}
+		return current, true, nil
+	default:
+		return nil, false, fmt.Errorf("unknown mode %v", request.WriteMode)
+	}
+}
+
+func putCurrentWorkflow(
+	s *store,
+	b *batch,
+	shardID int,
+	domainID string,
+	workflowID string,
+	request *nosqlplugin.CurrentWorkflowWriteRequest,
+) error {
+	if request.WriteMode == nosqlplugin.CurrentWorkflowWriteModeNoop {
+		return nil
+	}
+	row := request.Row
+	row.ShardID = shardID
+	row.DomainID = domainID
+	row.WorkflowID = workflowID
+	return b.put(s.table(tableCurrentWorkflow), currentWorkflowKey(shardID, domainID, workflowID), &row)
+}
+
+// newWorkflowExecutionRecord returns the record of a new execution with all its maps, for both create and reset
+func newWorkflowExecutionRecord(execution *nosqlplugin.WorkflowExecutionRequest) *workflowExecutionRecord {
+	state := &nosqlplugin.WorkflowExecution{
+		ExecutionInfo:       &execution.InternalWorkflowExecutionInfo,
+		VersionHistories:    execution.VersionHistories,
+		ActivityInfos:       make(map[int64]*p.InternalActivityInfo),
+		TimerInfos:          make(map[string]*p.TimerInfo),
+		ChildExecutionInfos: make(map[int64]*p.InternalChildExecutionInfo),
+		RequestCancelInfos:  make(map[int64]*p.RequestCancelInfo),
+		SignalInfos:         make(map[int64]*p.SignalInfo),
+		SignalRequestedIDs:  make(map[string]struct{}),
+		BufferedEvents:      make([]*p.DataBlob, 0),
+	}
+	if execution.Checksums != nil {
+		state.Checksum = *execution.Checksums
+	}
+	mergeWorkflowExecutionMaps(state, execution)
+	return &workflowExecutionRecord{
+		State:            state,
+		LastWriteVersion: execution.LastWriteVersion,
+	}
+}
+
+// updateWorkflowExecutionRecord updates the execution info, merges/deletes the map entries and updates the buffered events
+func updateWorkflowExecutionRecord(record *workflowExecutionRecord, execution *nosqlplugin.WorkflowExecutionRequest) error {
+	state := record.State
+	state.ExecutionInfo = &execution.InternalWorkflowExecutionInfo
+	state.VersionHistories = execution.VersionHistories
+	if execution.Checksums != nil {
+		state.Checksum = *execution.Checksums
+	}
+	record.LastWriteVersion = execution.LastWriteVersion
+
+	if state.ActivityInfos == nil {
+		state.ActivityInfos = make(map[int64]*p.InternalActivityInfo)
+	}
+	if state.TimerInfos == nil {
+		state.TimerInfos = make(map[string]*p.TimerInfo)
+	}
+	if state.ChildExecutionInfos == nil {
+		state.ChildExecutionInfos = make(map[int64]*p.InternalChildExecutionInfo)
+	}
+	if state.RequestCancelInfos == nil {
+		state.RequestCancelInfos = make(map[int64]*p.RequestCancelInfo)
+	}
+	if state.SignalInfos == nil {
+		state.SignalInfos = make(map[int64]*p.SignalInfo)
+	}
+	if state.SignalRequestedIDs == nil {
+		state.SignalRequestedIDs = make(map[string]struct{})
+	}
+	mergeWorkflowExecutionMaps(state, execution)
+	for _, key := range execution.ActivityInfoKeysToDelete {
+		delete(state.ActivityInfos, key)
+	}
+	for _, key := range execution.TimerInfoKeysToDelete {
+		delete(state.TimerInfos, key)
+	}
+	for _, key := range execution.ChildWorkflowInfoKeysToDelete {
+		delete(state.ChildExecutionInfos, key)
+	}
+	for _, key := range execution.RequestCancelInfoKeysToDelete {
+		delete(state.RequestCancelInfos, key)
+	}
+	for _, key := range execution.SignalInfoKeysToDelete {
+		delete(state.SignalInfos, key)
+	}
+	for _, key := range execution.SignalRequestedIDsKeysToDelete {
+		delete(state.SignalRequestedIDs, key)
+	}
+
+	switch execution.EventBufferWriteMode {
+	case nosqlplugin.EventBufferWriteModeNone:
+	case nosqlplugin.EventBufferWriteModeAppend:
+		if execution.NewBufferedEventBatch == nil {
+			return fmt.Errorf("buffered event batch must not be nil")
+		}
+		state.BufferedEvents = append(state.BufferedEvents, execution.NewBufferedEventBatch)
+	case nosqlplugin.EventBufferWriteModeClear:
+		state.BufferedEvents = make([]*p.DataBlob, 0)
+	default:
+		return fmt.Errorf("unknown EventBufferWriteType %v", execution.EventBufferWriteMode)
+	}
+	return nil
+}
+
+func mergeWorkflowExecutionMaps(state *nosqlplugin.WorkflowExecution, execution *nosqlplugin.WorkflowExecutionRequest) {
+	for key, value := range execution.ActivityInfos {
+		state.ActivityInfos[key] = value
+	}
+	for key, value := range execution.TimerInfos {
+		state.TimerInfos[key] = value
+	}
+	for key, value := range execution.ChildWorkflowInfos {
+		state.ChildExecutionInfos[key] = value
+	}
+	for key, value := range execution.RequestCancelInfos {
+		state.RequestCancelInfos[key] = value
+	}
+	for key, value := range execution.SignalInfos {
+		state.SignalInfos[key] = value
+	}
+	for _, key := range execution.SignalRequestedIDs {
+		state.SignalRequestedIDs[key] = struct{}{}
+	}
+}
+
+func putAllTasks(
+	s *store,
+	b *batch,
+	shardID int,
+	transferTasks []*nosqlplugin.TransferTask,
+	crossClusterTasks []*nosqlplugin.CrossClusterTask,
+	replicationTasks []*nosqlplugin.ReplicationTask,
+	timerTasks []*nosqlplugin.TimerTask,
+) error {
+	for _, task := range transferTasks {
+		if err := b.put(s.table(tableTransferTask), shardTaskKey(shardID, task.TaskID), task); err != nil {
+			return err
+		}
+	}
+	for _, task := range crossClusterTasks {
+		if err := b.put(s.table(tableCrossClusterTask), shardClusterTaskKey(shardID, task.TargetCluster, task.TaskID), &task.TransferTask); err != nil {
+			return err
+		}
+	}
+	if err := putReplicationTasks(s, b, shardID, replicationTasks); err != nil {
+		return err
+	}
+	for _, task := range timerTasks {
+		if err := b.put(s.table(tableTimerTask), timerTaskKey(shardID, task.VisibilityTimestamp, task.TaskID), task); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func putReplicationTasks(s *store, b *batch, shardID int, replicationTasks []*nosqlplugin.ReplicationTask) error {
+	for _, task := range replicationTasks {
+		if err := b.put(s.table(tableReplicationTask), shardTaskKey(shardID, task.TaskID), task); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// taskIDRange returns the keys of the tasks of a partition within (exclusiveMinTaskID, inclusiveMaxTaskID]
+func taskIDRange(t *table, exclusiveMinTaskID, inclusiveMaxTaskID int64, partition ...string) []string {
+	if exclusiveMinTaskID >= inclusiveMaxTaskID {
+		return nil
+	}
+	return t.scan(int64Range(exclusiveMinTaskID+1, inclusiveMaxTaskID, partition...))
+}
+
+// timerRange returns the keys of the timers of a shard within [inclusiveMinTime, exclusiveMaxTime)
+func timerRange(t *table, shardID int, inclusiveMinTime, exclusiveMaxTime time.Time) []string {
+	return t.scan(timerTimeKey(shardID, inclusiveMinTime), timerTimeKey(shardID, exclusiveMaxTime))
+}
+
+func newCreateWorkflowCurrentConditionFailure(
+	current *nosqlplugin.CurrentWorkflowRow,
+	currentWorkflowRequest *nosqlplugin.CurrentWorkflowWriteRequest,
+	execution *nosqlplugin.WorkflowExecutionRequest,
+	shardCondition *nosqlplugin.ShardCondition,
+) error {
+	if current == nil {
+		msg := fmt.Sprintf("Workflow execution creation condition failed by missing current workflow. WorkflowId: %v, Expected Current RunID: %v",
+			execution.WorkflowID, currentWorkflowRequest.Condition.GetCurrentRunID())
+		return &nosqlplugin.WorkflowOperationConditionFailure{
+			CurrentWorkflowConditionFailInfo: &msg,
+		}
+	}
+	if currentWorkflowRequest.WriteMode == nosqlplugin.CurrentWorkflowWriteModeInsert {
+		msg := fmt.Sprintf("Workflow execution already running. WorkflowId: %v, RunId: %v, rangeID: %v",
+			current.WorkflowID, current.RunID, shardCondition.RangeID)
+		return &nosqlplugin.WorkflowOperationConditionFailure{
+			WorkflowExecutionAlreadyExists: &nosqlplugin.WorkflowExecutionAlreadyExists{
+				OtherInfo:        msg,
+				CreateRequestID:  current.CreateRequestID,
+				RunID:            current.RunID,
+				State:            current.State,
+				CloseStatus:      current.CloseStatus,
+				LastWriteVersion: current.LastWriteVersion,
+			},
+		}
+	}
+	if current.RunID != currentWorkflowRequest.Condition.GetCurrentRunID() {
+		// currentRunID on previous run has been changed, return to caller to handle
+		msg := fmt.Sprintf("Workflow execution creation condition failed by mismatch runID. WorkflowId: %v, Expected Current RunID: %v, Actual Current RunID: %v",
+			execution.WorkflowID, currentWorkflowRequest.Condition.GetCurrentRunID(), current.RunID)
+		return &nosqlplugin.WorkflowOperationConditionFailure{
+			CurrentWorkflowConditionFailInfo: &msg,
+		}
+	}
+	msg := fmt.Sprintf("Workflow execution creation condition failed. WorkflowId: %v, CurrentRunID: %v, LastWriteVersion: %v, State: %v",
+		execution.WorkflowID, current.RunID, current.LastWriteVersion, current.State)
+	return &nosqlplugin.WorkflowOperationConditionFailure{
+		CurrentWorkflowConditionFailInfo: &msg,
+	}
+}
+
+func newWorkflowExecutionAlreadyExistsFailure(
+	existing *workflowExecutionRecord,
+	execution *nosqlplugin.WorkflowExecutionRequest,
+	shardCondition *nosqlplugin.ShardCondition,
+) error {
+	msg := fmt.Sprintf("Workflow execution already running. WorkflowId: %v, RunId: %v, rangeID: %v",
+		execution.WorkflowID, execution.RunID, shardCondition.RangeID)
+	return &nosqlplugin.WorkflowOperationConditionFailure{
+		WorkflowExecutionAlreadyExists: &nosqlplugin.WorkflowExecutionAlreadyExists{
+			OtherInfo:        msg,
+			CreateRequestID:  execution.CreateRequestID,
+			RunID:            execution.RunID,
+			State:            execution.State,
+			CloseStatus:      execution.CloseStatus,
+			LastWriteVersion: existing.LastWriteVersion,
+		},
+	}
+}
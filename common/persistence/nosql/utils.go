@@ -33,6 +33,11 @@ func convertCommonErrors(
 	operation string,
 	err error,
 ) error {
+	if sizeErr, ok := err.(*p.TransactionSizeLimitError); ok {
+		// returned by plugins whose item size limit is lower than the transaction size limit
+		return sizeErr
+	}
+
 	if errChecker.IsNotFoundError(err) {
 		return &types.EntityNotExistsError{
 			Message: fmt.Sprintf("%v failed. Error: %v ", operation, err),
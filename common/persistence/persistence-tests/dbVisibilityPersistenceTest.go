@@ -800,6 +800,13 @@ func (s *DBVisibilityPersistenceSuite) TestUpsertWorkflowExecution() {
 	ctx, cancel := context.WithTimeout(context.Background(), testContextTimeout)
 	defer cancel()
 
+	// search attributes can only be upserted into SQL visibility stores
+	var expectedUpsertErr error = p.NewOperationNotSupportErrorForVis()
+	cfg := s.VisibilityTestCluster.Config()
+	if cfg.DataStores[cfg.VisibilityStore].SQL != nil {
+		expectedUpsertErr = nil
+	}
+
 	tests := []struct {
 		request  *p.UpsertWorkflowExecutionRequest
 		expected error
@@ -834,7 +841,7 @@ func (s *DBVisibilityPersistenceSuite) TestUpsertWorkflowExecution() {
 				Memo:               nil,
 				SearchAttributes:   nil,
 			},
-			expected: p.NewOperationNotSupportErrorForVis(),
+			expected: expectedUpsertErr,
 		},
 	}
 
@@ -0,0 +1,575 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package persistence
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/uber/cadence/common/persistence/encryption"
+	"github.com/uber/cadence/common/types"
+)
+
+type (
+	// payloadEncryptor encrypts the workflow payloads (events, activity details, memos) written by the stores
+	// with the key of their domain, and decrypts them on read. Only the payloads are encrypted, the encoding
+	// of the blobs is left untouched and blobs written before encryption was enabled are read as is.
+	// Payloads are bound to the ID of their domain, or to their history tree as the domain of history
+	// nodes is not known on read, so that a payload copied to another owner fails to decrypt.
+	payloadEncryptor struct {
+		encryptor encryption.Encryptor
+	}
+
+	executionEncryptionStore struct {
+		payloadEncryptor
+		ExecutionStore
+	}
+
+	historyEncryptionStore struct {
+		payloadEncryptor
+		HistoryStore
+	}
+
+	visibilityEncryptionStore struct {
+		payloadEncryptor
+		VisibilityStore
+	}
+)
+
+var _ ExecutionStore = (*executionEncryptionStore)(nil)
+var _ HistoryStore = (*historyEncryptionStore)(nil)
+var _ VisibilityStore = (*visibilityEncryptionStore)(nil)
+
+// NewExecutionEncryptionStore returns an execution store encrypting workflow payloads at rest
+func NewExecutionEncryptionStore(store ExecutionStore, encryptor encryption.Encryptor) ExecutionStore {
+	return &executionEncryptionStore{
+		payloadEncryptor: payloadEncryptor{encryptor: encryptor},
+		ExecutionStore:   store,
+	}
+}
+
+// NewHistoryEncryptionStore returns a history store encrypting history events at rest
+func NewHistoryEncryptionStore(store HistoryStore, encryptor encryption.Encryptor) HistoryStore {
+	return &historyEncryptionStore{
+		payloadEncryptor: payloadEncryptor{encryptor: encryptor},
+		HistoryStore:     store,
+	}
+}
+
+// NewVisibilityEncryptionStore returns a visibility store encrypting memos at rest
+func NewVisibilityEncryptionStore(store VisibilityStore, encryptor encryption.Encryptor) VisibilityStore {
+	return &visibilityEncryptionStore{
+		payloadEncryptor: payloadEncryptor{encryptor: encryptor},
+		VisibilityStore:  store,
+	}
+}
+
+func (s *executionEncryptionStore) GetWorkflowExecution(
+	ctx context.Context,
+	request *InternalGetWorkflowExecutionRequest,
+) (*InternalGetWorkflowExecutionResponse, error) {
+	response, err := s.ExecutionStore.GetWorkflowExecution(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.decryptMutableState(response.State); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (s *executionEncryptionStore) CreateWorkflowExecution(
+	ctx context.Context,
+	request *InternalCreateWorkflowExecutionRequest,
+) (*CreateWorkflowExecutionResponse, error) {
+	snapshot, err := s.encryptSnapshot(&request.NewWorkflowSnapshot)
+	if err != nil {
+		return nil, err
+	}
+	encrypted := *request
+	encrypted.NewWorkflowSnapshot = *snapshot
+	return s.ExecutionStore.CreateWorkflowExecution(ctx, &encrypted)
+}
+
+func (s *executionEncryptionStore) UpdateWorkflowExecution(
+	ctx context.Context,
+	request *InternalUpdateWorkflowExecutionRequest,
+) error {
+	var err error
+	encrypted := *request
+	mutation, err := s.encryptMutation(&request.UpdateWorkflowMutation)
+	if err != nil {
+		return err
+	}
+	encrypted.UpdateWorkflowMutation = *mutation
+	if encrypted.NewWorkflowSnapshot, err = s.encryptSnapshot(request.NewWorkflowSnapshot); err != nil {
+		return err
+	}
+	return s.ExecutionStore.UpdateWorkflowExecution(ctx, &encrypted)
+}
+
+func (s *executionEncryptionStore) ConflictResolveWorkflowExecution(
+	ctx context.Context,
+	request *InternalConflictResolveWorkflowExecutionRequest,
+) error {
+	var err error
+	encrypted := *request
+	snapshot, err := s.encryptSnapshot(&request.ResetWorkflowSnapshot)
+	if err != nil {
+		return err
+	}
+	encrypted.ResetWorkflowSnapshot = *snapshot
+	if encrypted.NewWorkflowSnapshot, err = s.encryptSnapshot(request.NewWorkflowSnapshot); err != nil {
+		return err
+	}
+	if encrypted.CurrentWorkflowMutation, err = s.encryptMutation(request.CurrentWorkflowMutation); err != nil {
+		return err
+	}
+	return s.ExecutionStore.ConflictResolveWorkflowExecution(ctx, &encrypted)
+}
+
+func (s *executionEncryptionStore) ListConcreteExecutions(
+	ctx context.Context,
+	request *ListConcreteExecutionsRequest,
+) (*InternalListConcreteExecutionsResponse, error) {
+	response, err := s.ExecutionStore.ListConcreteExecutions(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	for _, execution := range response.Executions {
+		if err := s.decryptExecutionInfo(execution.ExecutionInfo); err != nil {
+			return nil, err
+		}
+	}
+	return response, nil
+}
+
+func (s *historyEncryptionStore) AppendHistoryNodes(
+	ctx context.Context,
+	request *InternalAppendHistoryNodesRequest,
+) error {
+	domainID := request.DomainID
+	if domainID == "" {
+		// requests of the callers not setting the domain ID are encrypted with the key of the domain
+		// found in the clean up info, or with the default key
+		domainID, _, _, _ = SplitHistoryGarbageCleanupInfo(request.Info)
+	}
+	events, err := s.encryptBlob(domainID, request.BranchInfo.GetTreeID(), request.Events)
+	if err != nil {
+		return err
+	}
+	encrypted := *request
+	encrypted.Events = events
+	return s.HistoryStore.AppendHistoryNodes(ctx, &encrypted)
+}
+
+func (s *historyEncryptionStore) ReadHistoryBranch(
+	ctx context.Context,
+	request *InternalReadHistoryBranchRequest,
+) (*InternalReadHistoryBranchResponse, error) {
+	response, err := s.HistoryStore.ReadHistoryBranch(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	for i, blob := range response.History {
+		if response.History[i], err = s.decryptBlob(request.TreeID, blob); err != nil {
+			return nil, err
+		}
+	}
+	return response, nil
+}
+
+func (s *visibilityEncryptionStore) RecordWorkflowExecutionStarted(
+	ctx context.Context,
+	request *InternalRecordWorkflowExecutionStartedRequest,
+) error {
+	memo, err := s.encryptBlob(request.DomainUUID, request.DomainUUID, request.Memo)
+	if err != nil {
+		return err
+	}
+	encrypted := *request
+	encrypted.Memo = memo
+	return s.VisibilityStore.RecordWorkflowExecutionStarted(ctx, &encrypted)
+}
+
+func (s *visibilityEncryptionStore) RecordWorkflowExecutionClosed(
+	ctx context.Context,
+	request *InternalRecordWorkflowExecutionClosedRequest,
+) error {
+	memo, err := s.encryptBlob(request.DomainUUID, request.DomainUUID, request.Memo)
+	if err != nil {
+		return err
+	}
+	encrypted := *request
+	encrypted.Memo = memo
+	return s.VisibilityStore.RecordWorkflowExecutionClosed(ctx, &encrypted)
+}
+
+func (s *visibilityEncryptionStore) UpsertWorkflowExecution(
+	ctx context.Context,
+	request *InternalUpsertWorkflowExecutionRequest,
+) error {
+	memo, err := s.encryptBlob(request.DomainUUID, request.DomainUUID, request.Memo)
+	if err != nil {
+		return err
+	}
+	encrypted := *request
+	encrypted.Memo = memo
+	return s.VisibilityStore.UpsertWorkflowExecution(ctx, &encrypted)
+}
+
+func (s *visibilityEncryptionStore) ListOpenWorkflowExecutions(
+	ctx context.Context,
+	request *InternalListWorkflowExecutionsRequest,
+) (*InternalListWorkflowExecutionsResponse, error) {
+	response, err := s.VisibilityStore.ListOpenWorkflowExecutions(ctx, request)
+	return s.decryptListResponse(request.DomainUUID, response, err)
+}
+
+func (s *visibilityEncryptionStore) ListClosedWorkflowExecutions(
+	ctx context.Context,
+	request *InternalListWorkflowExecutionsRequest,
+) (*InternalListWorkflowExecutionsResponse, error) {
+	response, err := s.VisibilityStore.ListClosedWorkflowExecutions(ctx, request)
+	return s.decryptListResponse(request.DomainUUID, response, err)
+}
+
+func (s *visibilityEncryptionStore) ListOpenWorkflowExecutionsByType(
+	ctx context.Context,
+	request *InternalListWorkflowExecutionsByTypeRequest,
+) (*InternalListWorkflowExecutionsResponse, error) {
+	response, err := s.VisibilityStore.ListOpenWorkflowExecutionsByType(ctx, request)
+	return s.decryptListResponse(request.DomainUUID, response, err)
+}
+
+func (s *visibilityEncryptionStore) ListClosedWorkflowExecutionsByType(
+	ctx context.Context,
+	request *InternalListWorkflowExecutionsByTypeRequest,
+) (*InternalListWorkflowExecutionsResponse, error) {
+	response, err := s.VisibilityStore.ListClosedWorkflowExecutionsByType(ctx, request)
+	return s.decryptListResponse(request.DomainUUID, response, err)
+}
+
+func (s *visibilityEncryptionStore) ListOpenWorkflowExecutionsByWorkflowID(
+	ctx context.Context,
+	request *InternalListWorkflowExecutionsByWorkflowIDRequest,
+) (*InternalListWorkflowExecutionsResponse, error) {
+	response, err := s.VisibilityStore.ListOpenWorkflowExecutionsByWorkflowID(ctx, request)
+	return s.decryptListResponse(request.DomainUUID, response, err)
+}
+
+func (s *visibilityEncryptionStore) ListClosedWorkflowExecutionsByWorkflowID(
+	ctx context.Context,
+	request *InternalListWorkflowExecutionsByWorkflowIDRequest,
+) (*InternalListWorkflowExecutionsResponse, error) {
+	response, err := s.VisibilityStore.ListClosedWorkflowExecutionsByWorkflowID(ctx, request)
+	return s.decryptListResponse(request.DomainUUID, response, err)
+}
+
+func (s *visibilityEncryptionStore) ListClosedWorkflowExecutionsByStatus(
+	ctx context.Context,
+	request *InternalListClosedWorkflowExecutionsByStatusRequest,
+) (*InternalListWorkflowExecutionsResponse, error) {
+	response, err := s.VisibilityStore.ListClosedWorkflowExecutionsByStatus(ctx, request)
+	return s.decryptListResponse(request.DomainUUID, response, err)
+}
+
+func (s *visibilityEncryptionStore) GetClosedWorkflowExecution(
+	ctx context.Context,
+	request *InternalGetClosedWorkflowExecutionRequest,
+) (*InternalGetClosedWorkflowExecutionResponse, error) {
+	response, err := s.VisibilityStore.GetClosedWorkflowExecution(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	if response.Execution != nil {
+		if response.Execution.Memo, err = s.decryptBlob(request.DomainUUID, response.Execution.Memo); err != nil {
+			return nil, err
+		}
+	}
+	return response, nil
+}
+
+func (s *visibilityEncryptionStore) ListWorkflowExecutions(
+	ctx context.Context,
+	request *ListWorkflowExecutionsByQueryRequest,
+) (*InternalListWorkflowExecutionsResponse, error) {
+	response, err := s.VisibilityStore.ListWorkflowExecutions(ctx, request)
+	return s.decryptListResponse(request.DomainUUID, response, err)
+}
+
+func (s *visibilityEncryptionStore) ScanWorkflowExecutions(
+	ctx context.Context,
+	request *ListWorkflowExecutionsByQueryRequest,
+) (*InternalListWorkflowExecutionsResponse, error) {
+	response, err := s.VisibilityStore.ScanWorkflowExecutions(ctx, request)
+	return s.decryptListResponse(request.DomainUUID, response, err)
+}
+
+func (s *visibilityEncryptionStore) decryptListResponse(
+	domainID string,
+	response *InternalListWorkflowExecutionsResponse,
+	err error,
+) (*InternalListWorkflowExecutionsResponse, error) {
+	if err != nil {
+		return nil, err
+	}
+	for _, execution := range response.Executions {
+		if execution.Memo, err = s.decryptBlob(domainID, execution.Memo); err != nil {
+			return nil, err
+		}
+	}
+	return response, nil
+}
+
+func (e *payloadEncryptor) encryptSnapshot(snapshot *InternalWorkflowSnapshot) (*InternalWorkflowSnapshot, error) {
+	if snapshot == nil || snapshot.ExecutionInfo == nil {
+		return snapshot, nil
+	}
+	var err error
+	domainID := snapshot.ExecutionInfo.DomainID
+	encrypted := *snapshot
+	if encrypted.ExecutionInfo, err = e.encryptExecutionInfo(snapshot.ExecutionInfo); err != nil {
+		return nil, err
+	}
+	if encrypted.ActivityInfos, err = e.encryptActivityInfos(domainID, snapshot.ActivityInfos); err != nil {
+		return nil, err
+	}
+	if encrypted.ChildExecutionInfos, err = e.encryptChildExecutionInfos(domainID, snapshot.ChildExecutionInfos); err != nil {
+		return nil, err
+	}
+	return &encrypted, nil
+}
+
+func (e *payloadEncryptor) encryptMutation(mutation *InternalWorkflowMutation) (*InternalWorkflowMutation, error) {
+	if mutation == nil || mutation.ExecutionInfo == nil {
+		return mutation, nil
+	}
+	var err error
+	domainID := mutation.ExecutionInfo.DomainID
+	encrypted := *mutation
+	if encrypted.ExecutionInfo, err = e.encryptExecutionInfo(mutation.ExecutionInfo); err != nil {
+		return nil, err
+	}
+	if encrypted.UpsertActivityInfos, err = e.encryptActivityInfos(domainID, mutation.UpsertActivityInfos); err != nil {
+		return nil, err
+	}
+	if encrypted.UpsertChildExecutionInfos, err = e.encryptChildExecutionInfos(domainID, mutation.UpsertChildExecutionInfos); err != nil {
+		return nil, err
+	}
+	if encrypted.NewBufferedEvents, err = e.encryptBlob(domainID, domainID, mutation.NewBufferedEvents); err != nil {
+		return nil, err
+	}
+	return &encrypted, nil
+}
+
+func (e *payloadEncryptor) encryptExecutionInfo(info *InternalWorkflowExecutionInfo) (*InternalWorkflowExecutionInfo, error) {
+	var err error
+	domainID := info.DomainID
+	encrypted := *info
+	if encrypted.CompletionEvent, err = e.encryptBlob(domainID, domainID, info.CompletionEvent); err != nil {
+		return nil, err
+	}
+	if encrypted.ExecutionContext, err = e.encrypt(domainID, domainID, info.ExecutionContext); err != nil {
+		return nil, err
+	}
+	if encrypted.Memo, err = e.encryptMap(domainID, info.Memo); err != nil {
+		return nil, err
+	}
+	if encrypted.SearchAttributes, err = e.encryptMap(domainID, info.SearchAttributes); err != nil {
+		return nil, err
+	}
+	return &encrypted, nil
+}
+
+func (e *payloadEncryptor) encryptMap(domainID string, values map[string][]byte) (map[string][]byte, error) {
+	if len(values) == 0 {
+		return values, nil
+	}
+	var err error
+	result := make(map[string][]byte, len(values))
+	for key, value := range values {
+		if result[key], err = e.encrypt(domainID, domainID, value); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+func (e *payloadEncryptor) encryptActivityInfos(domainID string, infos []*InternalActivityInfo) ([]*InternalActivityInfo, error) {
+	if len(infos) == 0 {
+		return infos, nil
+	}
+	var err error
+	result := make([]*InternalActivityInfo, 0, len(infos))
+	for _, info := range infos {
+		encrypted := *info
+		if encrypted.ScheduledEvent, err = e.encryptBlob(domainID, domainID, info.ScheduledEvent); err != nil {
+			return nil, err
+		}
+		if encrypted.StartedEvent, err = e.encryptBlob(domainID, domainID, info.StartedEvent); err != nil {
+			return nil, err
+		}
+		if encrypted.Details, err = e.encrypt(domainID, domainID, info.Details); err != nil {
+			return nil, err
+		}
+		if encrypted.LastFailureDetails, err = e.encrypt(domainID, domainID, info.LastFailureDetails); err != nil {
+			return nil, err
+		}
+		result = append(result, &encrypted)
+	}
+	return result, nil
+}
+
+func (e *payloadEncryptor) encryptChildExecutionInfos(domainID string, infos []*InternalChildExecutionInfo) ([]*InternalChildExecutionInfo, error) {
+	if len(infos) == 0 {
+		return infos, nil
+	}
+	var err error
+	result := make([]*InternalChildExecutionInfo, 0, len(infos))
+	for _, info := range infos {
+		encrypted := *info
+		if encrypted.InitiatedEvent, err = e.encryptBlob(domainID, domainID, info.InitiatedEvent); err != nil {
+			return nil, err
+		}
+		if encrypted.StartedEvent, err = e.encryptBlob(domainID, domainID, info.StartedEvent); err != nil {
+			return nil, err
+		}
+		result = append(result, &encrypted)
+	}
+	return result, nil
+}
+
+func (e *payloadEncryptor) decryptMutableState(state *InternalWorkflowMutableState) error {
+	if state == nil || state.ExecutionInfo == nil {
+		return nil
+	}
+	var err error
+	domainID := state.ExecutionInfo.DomainID
+	if err := e.decryptExecutionInfo(state.ExecutionInfo); err != nil {
+		return err
+	}
+	for _, info := range state.ActivityInfos {
+		if info.ScheduledEvent, err = e.decryptBlob(domainID, info.ScheduledEvent); err != nil {
+			return err
+		}
+		if info.StartedEvent, err = e.decryptBlob(domainID, info.StartedEvent); err != nil {
+			return err
+		}
+		if info.Details, err = e.decrypt(domainID, info.Details); err != nil {
+			return err
+		}
+		if info.LastFailureDetails, err = e.decrypt(domainID, info.LastFailureDetails); err != nil {
+			return err
+		}
+	}
+	for _, info := range state.ChildExecutionInfos {
+		if info.InitiatedEvent, err = e.decryptBlob(domainID, info.InitiatedEvent); err != nil {
+			return err
+		}
+		if info.StartedEvent, err = e.decryptBlob(domainID, info.StartedEvent); err != nil {
+			return err
+		}
+	}
+	for i, blob := range state.BufferedEvents {
+		if state.BufferedEvents[i], err = e.decryptBlob(domainID, blob); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *payloadEncryptor) decryptExecutionInfo(info *InternalWorkflowExecutionInfo) error {
+	if info == nil {
+		return nil
+	}
+	var err error
+	domainID := info.DomainID
+	if info.CompletionEvent, err = e.decryptBlob(domainID, info.CompletionEvent); err != nil {
+		return err
+	}
+	if info.ExecutionContext, err = e.decrypt(domainID, info.ExecutionContext); err != nil {
+		return err
+	}
+	if info.Memo, err = e.decryptMap(domainID, info.Memo); err != nil {
+		return err
+	}
+	info.SearchAttributes, err = e.decryptMap(domainID, info.SearchAttributes)
+	return err
+}
+
+func (e *payloadEncryptor) decryptMap(domainID string, values map[string][]byte) (map[string][]byte, error) {
+	if len(values) == 0 {
+		return values, nil
+	}
+	var err error
+	result := make(map[string][]byte, len(values))
+	for key, value := range values {
+		if result[key], err = e.decrypt(domainID, value); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+func (e *payloadEncryptor) encryptBlob(domainID string, owner string, blob *DataBlob) (*DataBlob, error) {
+	if blob == nil || len(blob.Data) == 0 {
+		return blob, nil
+	}
+	data, err := e.encrypt(domainID, owner, blob.Data)
+	if err != nil {
+		return nil, err
+	}
+	return &DataBlob{Encoding: blob.Encoding, Data: data}, nil
+}
+
+func (e *payloadEncryptor) decryptBlob(owner string, blob *DataBlob) (*DataBlob, error) {
+	if blob == nil || !encryption.IsEncrypted(blob.Data) {
+		return blob, nil
+	}
+	data, err := e.decrypt(owner, blob.Data)
+	if err != nil {
+		return nil, err
+	}
+	return &DataBlob{Encoding: blob.Encoding, Data: data}, nil
+}
+
+// encrypt encrypts the payload with the key of the domain and binds it to its owner,
+// the domain ID or the history tree ID
+func (e *payloadEncryptor) encrypt(domainID string, owner string, data []byte) ([]byte, error) {
+	encrypted, err := e.encryptor.Encrypt(domainID, data, []byte(owner))
+	if err != nil {
+		return nil, &types.InternalServiceError{
+			Message: fmt.Sprintf("Failed to encrypt payload: %v", err),
+		}
+	}
+	return encrypted, nil
+}
+
+func (e *payloadEncryptor) decrypt(owner string, data []byte) ([]byte, error) {
+	decrypted, err := e.encryptor.Decrypt(data, []byte(owner))
+	if err != nil {
+		return nil, &types.InternalServiceError{
+			Message: fmt.Sprintf("Failed to decrypt payload: %v", err),
+		}
+	}
+	return decrypted, nil
+}
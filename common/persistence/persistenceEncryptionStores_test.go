@@ -0,0 +1,175 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package persistence
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/uber/cadence/common"
+	"github.com/uber/cadence/common/persistence/encryption"
+	"github.com/uber/cadence/common/types"
+)
+
+type (
+	encryptionStoresSuite struct {
+		suite.Suite
+		encryptor encryption.Encryptor
+	}
+
+	fakeHistoryStore struct {
+		HistoryStore
+		nodes []*DataBlob
+	}
+
+	fakeExecutionStore struct {
+		ExecutionStore
+		snapshot *InternalWorkflowSnapshot
+	}
+)
+
+func TestEncryptionStoresSuite(t *testing.T) {
+	suite.Run(t, new(encryptionStoresSuite))
+}
+
+func (s *encryptionStoresSuite) SetupTest() {
+	keyProvider, err := encryption.NewStaticKeyProvider(
+		map[string][]byte{"key-1": []byte("0123456789abcdef0123456789abcdef")},
+		"key-1",
+		nil,
+	)
+	s.NoError(err)
+	s.encryptor = encryption.NewEnvelopeEncryptor(keyProvider)
+}
+
+func (s *encryptionStoresSuite) TestHistoryStore() {
+	fake := &fakeHistoryStore{}
+	store := NewHistoryEncryptionStore(fake, s.encryptor)
+	events := NewDataBlob([]byte("history events"), common.EncodingTypeThriftRW)
+
+	err := store.AppendHistoryNodes(context.Background(), &InternalAppendHistoryNodesRequest{
+		DomainID:   "domain-id",
+		BranchInfo: types.HistoryBranch{TreeID: common.StringPtr("tree-id")},
+		Events:     events,
+	})
+	s.NoError(err)
+	s.Len(fake.nodes, 1)
+	s.True(encryption.IsEncrypted(fake.nodes[0].Data))
+	s.Equal(events.Encoding, fake.nodes[0].Encoding)
+	s.Equal([]byte("history events"), events.Data)
+
+	// nodes written before encryption was enabled are read as is
+	fake.nodes = append(fake.nodes, NewDataBlob([]byte("plain events"), common.EncodingTypeThriftRW))
+	response, err := store.ReadHistoryBranch(context.Background(), &InternalReadHistoryBranchRequest{TreeID: "tree-id"})
+	s.NoError(err)
+	s.Equal([]byte("history events"), response.History[0].Data)
+	s.Equal([]byte("plain events"), response.History[1].Data)
+
+	// nodes are bound to their history tree
+	_, err = store.ReadHistoryBranch(context.Background(), &InternalReadHistoryBranchRequest{TreeID: "other-tree-id"})
+	s.Error(err)
+}
+
+func (s *encryptionStoresSuite) TestExecutionStore() {
+	fake := &fakeExecutionStore{}
+	store := NewExecutionEncryptionStore(fake, s.encryptor)
+	completionEvent := NewDataBlob([]byte("completion event"), common.EncodingTypeThriftRW)
+
+	_, err := store.CreateWorkflowExecution(context.Background(), &InternalCreateWorkflowExecutionRequest{
+		NewWorkflowSnapshot: InternalWorkflowSnapshot{
+			ExecutionInfo: &InternalWorkflowExecutionInfo{
+				DomainID:         "domain-id",
+				CompletionEvent:  completionEvent,
+				ExecutionContext: []byte("execution context"),
+				Memo:             map[string][]byte{"memo": []byte("memo value")},
+				SearchAttributes: map[string][]byte{"attr": []byte("attr value")},
+			},
+			ActivityInfos: []*InternalActivityInfo{{
+				ScheduledEvent: NewDataBlob([]byte("scheduled event"), common.EncodingTypeThriftRW),
+				Details:        []byte("heartbeat details"),
+			}},
+		},
+	})
+	s.NoError(err)
+	s.True(encryption.IsEncrypted(fake.snapshot.ExecutionInfo.CompletionEvent.Data))
+	s.True(encryption.IsEncrypted(fake.snapshot.ExecutionInfo.ExecutionContext))
+	s.True(encryption.IsEncrypted(fake.snapshot.ExecutionInfo.Memo["memo"]))
+	s.True(encryption.IsEncrypted(fake.snapshot.ExecutionInfo.SearchAttributes["attr"]))
+	s.True(encryption.IsEncrypted(fake.snapshot.ActivityInfos[0].ScheduledEvent.Data))
+	s.True(encryption.IsEncrypted(fake.snapshot.ActivityInfos[0].Details))
+	s.Nil(fake.snapshot.ActivityInfos[0].StartedEvent)
+	s.Equal([]byte("completion event"), completionEvent.Data)
+
+	response, err := store.GetWorkflowExecution(context.Background(), &InternalGetWorkflowExecutionRequest{})
+	s.NoError(err)
+	s.Equal([]byte("completion event"), response.State.ExecutionInfo.CompletionEvent.Data)
+	s.Equal([]byte("execution context"), response.State.ExecutionInfo.ExecutionContext)
+	s.Equal(map[string][]byte{"memo": []byte("memo value")}, response.State.ExecutionInfo.Memo)
+	s.Equal(map[string][]byte{"attr": []byte("attr value")}, response.State.ExecutionInfo.SearchAttributes)
+	s.Equal([]byte("scheduled event"), response.State.ActivityInfos[0].ScheduledEvent.Data)
+	s.Equal([]byte("heartbeat details"), response.State.ActivityInfos[0].Details)
+
+	// payloads are bound to their domain
+	fake.snapshot.ExecutionInfo.DomainID = "other-domain-id"
+	_, err = store.GetWorkflowExecution(context.Background(), &InternalGetWorkflowExecutionRequest{})
+	s.Error(err)
+}
+
+func (s *fakeHistoryStore) AppendHistoryNodes(
+	_ context.Context,
+	request *InternalAppendHistoryNodesRequest,
+) error {
+	s.nodes = append(s.nodes, request.Events)
+	return nil
+}
+
+func (s *fakeHistoryStore) ReadHistoryBranch(
+	_ context.Context,
+	_ *InternalReadHistoryBranchRequest,
+) (*InternalReadHistoryBranchResponse, error) {
+	return &InternalReadHistoryBranchResponse{
+		History: append([]*DataBlob{}, s.nodes...),
+	}, nil
+}
+
+func (s *fakeExecutionStore) CreateWorkflowExecution(
+	_ context.Context,
+	request *InternalCreateWorkflowExecutionRequest,
+) (*CreateWorkflowExecutionResponse, error) {
+	s.snapshot = &request.NewWorkflowSnapshot
+	return &CreateWorkflowExecutionResponse{}, nil
+}
+
+func (s *fakeExecutionStore) GetWorkflowExecution(
+	_ context.Context,
+	_ *InternalGetWorkflowExecutionRequest,
+) (*InternalGetWorkflowExecutionResponse, error) {
+	activityInfo := *s.snapshot.ActivityInfos[0]
+	executionInfo := *s.snapshot.ExecutionInfo
+	return &InternalGetWorkflowExecutionResponse{
+		State: &InternalWorkflowMutableState{
+			ExecutionInfo: &executionInfo,
+			ActivityInfos: map[int64]*InternalActivityInfo{0: &activityInfo},
+		},
+	}, nil
+}
@@ -0,0 +1,931 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package persistence
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/uber/cadence/common"
+	"github.com/uber/cadence/common/dynamicconfig"
+	"github.com/uber/cadence/common/log"
+	"github.com/uber/cadence/common/log/tag"
+	"github.com/uber/cadence/common/metrics"
+	"github.com/uber/cadence/common/types"
+)
+
+const (
+	msgShadowWriteFailed = "Failed to mirror persistence write to migration target store"
+
+	// maxTaskListLeaseCatchUp is the maximum number of leases taken on the target task list
+	// during one LeaseTaskList call to catch up with the range ID of the source task list
+	maxTaskListLeaseCatchUp = 1000
+)
+
+type (
+	// shadowWriter mirrors the writes that succeeded on the source store to the migration target store.
+	// The source store stays the source of truth: reads are only served by the source store, and
+	// failed writes on the target store are logged and counted by the persistence_shadow_write_failures metric.
+	// The target store diverges from the source store on a failed write until the migration backfill runs again,
+	// so the cutover must not happen unless the metric stayed at zero since the start of the last backfill.
+	shadowWriter struct {
+		mode          dynamicconfig.StringPropertyFn
+		metricsClient metrics.Client
+		logger        log.Logger
+	}
+
+	shardShadowStore struct {
+		shadowWriter
+		source ShardStore
+		target ShardStore
+	}
+
+	executionShadowStore struct {
+		shadowWriter
+		source ExecutionStore
+		target ExecutionStore
+	}
+
+	taskShadowStore struct {
+		shadowWriter
+		source TaskStore
+		target TaskStore
+
+		sync.RWMutex
+		// range IDs of the target task lists which could not catch up with the source task lists
+		targetRangeIDs map[taskListKey]int64
+	}
+
+	historyShadowStore struct {
+		shadowWriter
+		source HistoryStore
+		target HistoryStore
+	}
+
+	domainShadowStore struct {
+		shadowWriter
+		source DomainStore
+		target DomainStore
+	}
+
+	taskListKey struct {
+		domainID     string
+		taskListName string
+		taskType     int
+	}
+)
+
+var _ ShardStore = (*shardShadowStore)(nil)
+var _ ExecutionStore = (*executionShadowStore)(nil)
+var _ TaskStore = (*taskShadowStore)(nil)
+var _ HistoryStore = (*historyShadowStore)(nil)
+var _ DomainStore = (*domainShadowStore)(nil)
+
+// NewShardShadowStore creates a shard store which mirrors writes to the migration target store
+func NewShardShadowStore(
+	source ShardStore,
+	target ShardStore,
+	mode dynamicconfig.StringPropertyFn,
+	metricsClient metrics.Client,
+	logger log.Logger,
+) ShardStore {
+	return &shardShadowStore{
+		shadowWriter: shadowWriter{mode: mode, metricsClient: metricsClient, logger: logger},
+		source:       source,
+		target:       target,
+	}
+}
+
+// NewExecutionShadowStore creates an execution store which mirrors writes to the migration target store
+func NewExecutionShadowStore(
+	source ExecutionStore,
+	target ExecutionStore,
+	mode dynamicconfig.StringPropertyFn,
+	metricsClient metrics.Client,
+	logger log.Logger,
+) ExecutionStore {
+	return &executionShadowStore{
+		shadowWriter: shadowWriter{mode: mode, metricsClient: metricsClient, logger: logger.WithTags(tag.ShardID(source.GetShardID()))},
+		source:       source,
+		target:       target,
+	}
+}
+
+// NewTaskShadowStore creates a task store which mirrors writes to the migration target store
+func NewTaskShadowStore(
+	source TaskStore,
+	target TaskStore,
+	mode dynamicconfig.StringPropertyFn,
+	metricsClient metrics.Client,
+	logger log.Logger,
+) TaskStore {
+	return &taskShadowStore{
+		shadowWriter:   shadowWriter{mode: mode, metricsClient: metricsClient, logger: logger},
+		source:         source,
+		target:         target,
+		targetRangeIDs: make(map[taskListKey]int64),
+	}
+}
+
+// NewHistoryShadowStore creates a history store which mirrors writes to the migration target store
+func NewHistoryShadowStore(
+	source HistoryStore,
+	target HistoryStore,
+	mode dynamicconfig.StringPropertyFn,
+	metricsClient metrics.Client,
+	logger log.Logger,
+) HistoryStore {
+	return &historyShadowStore{
+		shadowWriter: shadowWriter{mode: mode, metricsClient: metricsClient, logger: logger},
+		source:       source,
+		target:       target,
+	}
+}
+
+// NewDomainShadowStore creates a domain store which mirrors writes to the migration target store
+func NewDomainShadowStore(
+	source DomainStore,
+	target DomainStore,
+	mode dynamicconfig.StringPropertyFn,
+	metricsClient metrics.Client,
+	logger log.Logger,
+) DomainStore {
+	return &domainShadowStore{
+		shadowWriter: shadowWriter{mode: mode, metricsClient: metricsClient, logger: logger},
+		source:       source,
+		target:       target,
+	}
+}
+
+func (w *shadowWriter) enabled() bool {
+	return w.mode != nil && w.mode() == common.PersistenceMigrationModeShadow
+}
+
+func (w *shadowWriter) mirror(
+	operation tag.Tag,
+	write func() error,
+) {
+	if !w.enabled() {
+		return
+	}
+	if err := write(); err != nil {
+		w.logger.Warn(msgShadowWriteFailed, operation, tag.StoreError(err))
+		if w.metricsClient != nil {
+			w.metricsClient.IncCounter(metrics.PersistenceMigrationShadowScope, metrics.PersistenceShadowWriteFailures)
+		}
+	}
+}
+
+func (s *shardShadowStore) GetName() string {
+	return s.source.GetName()
+}
+
+func (s *shardShadowStore) Close() {
+	s.source.Close()
+	s.target.Close()
+}
+
+func (s *shardShadowStore) CreateShard(
+	ctx context.Context,
+	request *InternalCreateShardRequest,
+) error {
+	if err := s.source.CreateShard(ctx, request); err != nil {
+		return err
+	}
+	s.mirror(tag.StoreOperationCreateShard, func() error {
+		return s.target.CreateShard(ctx, request)
+	})
+	return nil
+}
+
+func (s *shardShadowStore) GetShard(
+	ctx context.Context,
+	request *InternalGetShardRequest,
+) (*InternalGetShardResponse, error) {
+	return s.source.GetShard(ctx, request)
+}
+
+func (s *shardShadowStore) UpdateShard(
+	ctx context.Context,
+	request *InternalUpdateShardRequest,
+) error {
+	if err := s.source.UpdateShard(ctx, request); err != nil {
+		return err
+	}
+	s.mirror(tag.StoreOperationUpdateShard, func() error {
+		if err := s.target.UpdateShard(ctx, request); err == nil {
+			return nil
+		}
+		// the target shard does not exist yet or is behind the source shard,
+		// overwrite it so that the execution writes pass the range ID check
+		resp, err := s.target.GetShard(ctx, &InternalGetShardRequest{ShardID: request.ShardInfo.ShardID})
+		switch err.(type) {
+		case nil:
+			return s.target.UpdateShard(ctx, &InternalUpdateShardRequest{
+				ShardInfo:       request.ShardInfo,
+				PreviousRangeID: resp.ShardInfo.RangeID,
+			})
+		case *types.EntityNotExistsError:
+			return s.target.CreateShard(ctx, &InternalCreateShardRequest{ShardInfo: request.ShardInfo})
+		default:
+			return err
+		}
+	})
+	return nil
+}
+
+func (s *executionShadowStore) GetName() string {
+	return s.source.GetName()
+}
+
+func (s *executionShadowStore) GetShardID() int {
+	return s.source.GetShardID()
+}
+
+func (s *executionShadowStore) Close() {
+	s.source.Close()
+	s.target.Close()
+}
+
+func (s *executionShadowStore) GetWorkflowExecution(
+	ctx context.Context,
+	request *InternalGetWorkflowExecutionRequest,
+) (*InternalGetWorkflowExecutionResponse, error) {
+	return s.source.GetWorkflowExecution(ctx, request)
+}
+
+func (s *executionShadowStore) UpdateWorkflowExecution(
+	ctx context.Context,
+	request *InternalUpdateWorkflowExecutionRequest,
+) error {
+	if err := s.source.UpdateWorkflowExecution(ctx, request); err != nil {
+		return err
+	}
+	s.mirror(tag.StoreOperationUpdateWorkflowExecution, func() error {
+		return s.target.UpdateWorkflowExecution(ctx, request)
+	})
+	return nil
+}
+
+func (s *executionShadowStore) ConflictResolveWorkflowExecution(
+	ctx context.Context,
+	request *InternalConflictResolveWorkflowExecutionRequest,
+) error {
+	if err := s.source.ConflictResolveWorkflowExecution(ctx, request); err != nil {
+		return err
+	}
+	s.mirror(tag.StoreOperationConflictResolveWorkflowExecution, func() error {
+		return s.target.ConflictResolveWorkflowExecution(ctx, request)
+	})
+	return nil
+}
+
+func (s *executionShadowStore) CreateWorkflowExecution(
+	ctx context.Context,
+	request *InternalCreateWorkflowExecutionRequest,
+) (*CreateWorkflowExecutionResponse, error) {
+	resp, err := s.source.CreateWorkflowExecution(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	s.mirror(tag.StoreOperationCreateWorkflowExecution, func() error {
+		_, err := s.target.CreateWorkflowExecution(ctx, request)
+		return err
+	})
+	return resp, nil
+}
+
+func (s *executionShadowStore) DeleteWorkflowExecution(
+	ctx context.Context,
+	request *DeleteWorkflowExecutionRequest,
+) error {
+	if err := s.source.DeleteWorkflowExecution(ctx, request); err != nil {
+		return err
+	}
+	s.mirror(tag.StoreOperationDeleteWorkflowExecution, func() error {
+		return s.target.DeleteWorkflowExecution(ctx, request)
+	})
+	return nil
+}
+
+func (s *executionShadowStore) DeleteCurrentWorkflowExecution(
+	ctx context.Context,
+	request *DeleteCurrentWorkflowExecutionRequest,
+) error {
+	if err := s.source.DeleteCurrentWorkflowExecution(ctx, request); err != nil {
+		return err
+	}
+	s.mirror(tag.StoreOperationDeleteCurrentWorkflowExecution, func() error {
+		return s.target.DeleteCurrentWorkflowExecution(ctx, request)
+	})
+	return nil
+}
+
+func (s *executionShadowStore) GetCurrentExecution(
+	ctx context.Context,
+	request *GetCurrentExecutionRequest,
+) (*GetCurrentExecutionResponse, error) {
+	return s.source.GetCurrentExecution(ctx, request)
+}
+
+func (s *executionShadowStore) IsWorkflowExecutionExists(
+	ctx context.Context,
+	request *IsWorkflowExecutionExistsRequest,
+) (*IsWorkflowExecutionExistsResponse, error) {
+	return s.source.IsWorkflowExecutionExists(ctx, request)
+}
+
+func (s *executionShadowStore) GetTransferTasks(
+	ctx context.Context,
+	request *GetTransferTasksRequest,
+) (*GetTransferTasksResponse, error) {
+	return s.source.GetTransferTasks(ctx, request)
+}
+
+func (s *executionShadowStore) CompleteTransferTask(
+	ctx context.Context,
+	request *CompleteTransferTaskRequest,
+) error {
+	if err := s.source.CompleteTransferTask(ctx, request); err != nil {
+		return err
+	}
+	s.mirror(tag.StoreOperationCompleteTransferTask, func() error {
+		return s.target.CompleteTransferTask(ctx, request)
+	})
+	return nil
+}
+
+func (s *executionShadowStore) RangeCompleteTransferTask(
+	ctx context.Context,
+	request *RangeCompleteTransferTaskRequest,
+) (*RangeCompleteTransferTaskResponse, error) {
+	resp, err := s.source.RangeCompleteTransferTask(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	s.mirror(tag.StoreOperationRangeCompleteTransferTask, func() error {
+		_, err := s.target.RangeCompleteTransferTask(ctx, request)
+		return err
+	})
+	return resp, nil
+}
+
+func (s *executionShadowStore) GetCrossClusterTasks(
+	ctx context.Context,
+	request *GetCrossClusterTasksRequest,
+) (*GetCrossClusterTasksResponse, error) {
+	return s.source.GetCrossClusterTasks(ctx, request)
+}
+
+func (s *executionShadowStore) CompleteCrossClusterTask(
+	ctx context.Context,
+	request *CompleteCrossClusterTaskRequest,
+) error {
+	if err := s.source.CompleteCrossClusterTask(ctx, request); err != nil {
+		return err
+	}
+	s.mirror(tag.StoreOperationCompleteCrossClusterTask, func() error {
+		return s.target.CompleteCrossClusterTask(ctx, request)
+	})
+	return nil
+}
+
+func (s *executionShadowStore) RangeCompleteCrossClusterTask(
+	ctx context.Context,
+	request *RangeCompleteCrossClusterTaskRequest,
+) (*RangeCompleteCrossClusterTaskResponse, error) {
+	resp, err := s.source.RangeCompleteCrossClusterTask(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	s.mirror(tag.StoreOperationRangeCompleteCrossClusterTask, func() error {
+		_, err := s.target.RangeCompleteCrossClusterTask(ctx, request)
+		return err
+	})
+	return resp, nil
+}
+
+func (s *executionShadowStore) GetReplicationTasks(
+	ctx context.Context,
+	request *GetReplicationTasksRequest,
+) (*InternalGetReplicationTasksResponse, error) {
+	return s.source.GetReplicationTasks(ctx, request)
+}
+
+func (s *executionShadowStore) CompleteReplicationTask(
+	ctx context.Context,
+	request *CompleteReplicationTaskRequest,
+) error {
+	if err := s.source.CompleteReplicationTask(ctx, request); err != nil {
+		return err
+	}
+	s.mirror(tag.StoreOperationCompleteReplicationTask, func() error {
+		return s.target.CompleteReplicationTask(ctx, request)
+	})
+	return nil
+}
+
+func (s *executionShadowStore) RangeCompleteReplicationTask(
+	ctx context.Context,
+	request *RangeCompleteReplicationTaskRequest,
+) (*RangeCompleteReplicationTaskResponse, error) {
+	resp, err := s.source.RangeCompleteReplicationTask(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	s.mirror(tag.StoreOperationRangeCompleteReplicationTask, func() error {
+		_, err := s.target.RangeCompleteReplicationTask(ctx, request)
+		return err
+	})
+	return resp, nil
+}
+
+func (s *executionShadowStore) PutReplicationTaskToDLQ(
+	ctx context.Context,
+	request *InternalPutReplicationTaskToDLQRequest,
+) error {
+	if err := s.source.PutReplicationTaskToDLQ(ctx, request); err != nil {
+		return err
+	}
+	s.mirror(tag.StoreOperationPutReplicationTaskToDLQ, func() error {
+		return s.target.PutReplicationTaskToDLQ(ctx, request)
+	})
+	return nil
+}
+
+func (s *executionShadowStore) GetReplicationTasksFromDLQ(
+	ctx context.Context,
+	request *GetReplicationTasksFromDLQRequest,
+) (*InternalGetReplicationTasksFromDLQResponse, error) {
+	return s.source.GetReplicationTasksFromDLQ(ctx, request)
+}
+
+func (s *executionShadowStore) GetReplicationDLQSize(
+	ctx context.Context,
+	request *GetReplicationDLQSizeRequest,
+) (*GetReplicationDLQSizeResponse, error) {
+	return s.source.GetReplicationDLQSize(ctx, request)
+}
+
+func (s *executionShadowStore) DeleteReplicationTaskFromDLQ(
+	ctx context.Context,
+	request *DeleteReplicationTaskFromDLQRequest,
+) error {
+	if err := s.source.DeleteReplicationTaskFromDLQ(ctx, request); err != nil {
+		return err
+	}
+	s.mirror(tag.StoreOperationDeleteReplicationTaskFromDLQ, func() error {
+		return s.target.DeleteReplicationTaskFromDLQ(ctx, request)
+	})
+	return nil
+}
+
+func (s *executionShadowStore) RangeDeleteReplicationTaskFromDLQ(
+	ctx context.Context,
+	request *RangeDeleteReplicationTaskFromDLQRequest,
+) (*RangeDeleteReplicationTaskFromDLQResponse, error) {
+	resp, err := s.source.RangeDeleteReplicationTaskFromDLQ(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	s.mirror(tag.StoreOperationRangeDeleteReplicationTaskFromDLQ, func() error {
+		_, err := s.target.RangeDeleteReplicationTaskFromDLQ(ctx, request)
+		return err
+	})
+	return resp, nil
+}
+
+func (s *executionShadowStore) CreateFailoverMarkerTasks(
+	ctx context.Context,
+	request *CreateFailoverMarkersRequest,
+) error {
+	if err := s.source.CreateFailoverMarkerTasks(ctx, request); err != nil {
+		return err
+	}
+	s.mirror(tag.StoreOperationCreateFailoverMarkerTasks, func() error {
+		return s.target.CreateFailoverMarkerTasks(ctx, request)
+	})
+	return nil
+}
+
+func (s *executionShadowStore) GetTimerIndexTasks(
+	ctx context.Context,
+	request *GetTimerIndexTasksRequest,
+) (*GetTimerIndexTasksResponse, error) {
+	return s.source.GetTimerIndexTasks(ctx, request)
+}
+
+func (s *executionShadowStore) CompleteTimerTask(
+	ctx context.Context,
+	request *CompleteTimerTaskRequest,
+) error {
+	if err := s.source.CompleteTimerTask(ctx, request); err != nil {
+		return err
+	}
+	s.mirror(tag.StoreOperationCompleteTimerTask, func() error {
+		return s.target.CompleteTimerTask(ctx, request)
+	})
+	return nil
+}
+
+func (s *executionShadowStore) RangeCompleteTimerTask(
+	ctx context.Context,
+	request *RangeCompleteTimerTaskRequest,
+) (*RangeCompleteTimerTaskResponse, error) {
+	resp, err := s.source.RangeCompleteTimerTask(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	s.mirror(tag.StoreOperationRangeCompleteTimerTask, func() error {
+		_, err := s.target.RangeCompleteTimerTask(ctx, request)
+		return err
+	})
+	return resp, nil
+}
+
+func (s *executionShadowStore) ListConcreteExecutions(
+	ctx context.Context,
+	request *ListConcreteExecutionsRequest,
+) (*InternalListConcreteExecutionsResponse, error) {
+	return s.source.ListConcreteExecutions(ctx, request)
+}
+
+func (s *executionShadowStore) ListCurrentExecutions(
+	ctx context.Context,
+	request *ListCurrentExecutionsRequest,
+) (*ListCurrentExecutionsResponse, error) {
+	return s.source.ListCurrentExecutions(ctx, request)
+}
+
+func (s *taskShadowStore) GetName() string {
+	return s.source.GetName()
+}
+
+func (s *taskShadowStore) Close() {
+	s.source.Close()
+	s.target.Close()
+}
+
+func (s *taskShadowStore) LeaseTaskList(
+	ctx context.Context,
+	request *LeaseTaskListRequest,
+) (*LeaseTaskListResponse, error) {
+	resp, err := s.source.LeaseTaskList(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	s.mirror(tag.StoreOperationLeaseTaskList, func() error {
+		// task IDs are allocated from the range ID, so the target task list has to be leased
+		// until it reaches the range ID of the source task list, otherwise the tasks created
+		// after the migration would be below the ack level of the task list
+		targetRequest := *request
+		targetRequest.RangeID = 0
+		key := newTaskListKey(request.DomainID, request.TaskList, request.TaskType)
+		for i := 0; i < maxTaskListLeaseCatchUp; i++ {
+			targetResp, err := s.target.LeaseTaskList(ctx, &targetRequest)
+			if err != nil {
+				return err
+			}
+			targetRangeID := targetResp.TaskListInfo.RangeID
+			if targetRangeID >= resp.TaskListInfo.RangeID {
+				s.setTargetRangeID(key, resp.TaskListInfo.RangeID, targetRangeID)
+				return nil
+			}
+		}
+		return fmt.Errorf("target task list did not catch up with range ID %v", resp.TaskListInfo.RangeID)
+	})
+	return resp, nil
+}
+
+func (s *taskShadowStore) UpdateTaskList(
+	ctx context.Context,
+	request *UpdateTaskListRequest,
+) (*UpdateTaskListResponse, error) {
+	resp, err := s.source.UpdateTaskList(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	s.mirror(tag.StoreOperationUpdateTaskList, func() error {
+		_, err := s.target.UpdateTaskList(ctx, &UpdateTaskListRequest{
+			TaskListInfo: s.targetTaskListInfo(request.TaskListInfo),
+		})
+		return err
+	})
+	return resp, nil
+}
+
+func (s *taskShadowStore) ListTaskList(
+	ctx context.Context,
+	request *ListTaskListRequest,
+) (*ListTaskListResponse, error) {
+	return s.source.ListTaskList(ctx, request)
+}
+
+func (s *taskShadowStore) DeleteTaskList(
+	ctx context.Context,
+	request *DeleteTaskListRequest,
+) error {
+	if err := s.source.DeleteTaskList(ctx, request); err != nil {
+		return err
+	}
+	s.mirror(tag.StoreOperationDeleteTaskList, func() error {
+		key := newTaskListKey(request.DomainID, request.TaskListName, request.TaskListType)
+		targetRequest := *request
+		targetRequest.RangeID = s.targetRangeID(key, request.RangeID)
+		if err := s.target.DeleteTaskList(ctx, &targetRequest); err != nil {
+			return err
+		}
+		s.Lock()
+		delete(s.targetRangeIDs, key)
+		s.Unlock()
+		return nil
+	})
+	return nil
+}
+
+func (s *taskShadowStore) CreateTasks(
+	ctx context.Context,
+	request *InternalCreateTasksRequest,
+) (*CreateTasksResponse, error) {
+	resp, err := s.source.CreateTasks(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	s.mirror(tag.StoreOperationCreateTasks, func() error {
+		_, err := s.target.CreateTasks(ctx, &InternalCreateTasksRequest{
+			TaskListInfo: s.targetTaskListInfo(request.TaskListInfo),
+			Tasks:        request.Tasks,
+		})
+		return err
+	})
+	return resp, nil
+}
+
+func (s *taskShadowStore) GetTasks(
+	ctx context.Context,
+	request *GetTasksRequest,
+) (*InternalGetTasksResponse, error) {
+	return s.source.GetTasks(ctx, request)
+}
+
+func (s *taskShadowStore) CompleteTask(
+	ctx context.Context,
+	request *CompleteTaskRequest,
+) error {
+	if err := s.source.CompleteTask(ctx, request); err != nil {
+		return err
+	}
+	s.mirror(tag.StoreOperationCompleteTask, func() error {
+		return s.target.CompleteTask(ctx, request)
+	})
+	return nil
+}
+
+func (s *taskShadowStore) CompleteTasksLessThan(
+	ctx context.Context,
+	request *CompleteTasksLessThanRequest,
+) (*CompleteTasksLessThanResponse, error) {
+	resp, err := s.source.CompleteTasksLessThan(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	s.mirror(tag.StoreOperationCompleteTasksLessThan, func() error {
+		_, err := s.target.CompleteTasksLessThan(ctx, request)
+		return err
+	})
+	return resp, nil
+}
+
+func (s *taskShadowStore) GetOrphanTasks(
+	ctx context.Context,
+	request *GetOrphanTasksRequest,
+) (*GetOrphanTasksResponse, error) {
+	return s.source.GetOrphanTasks(ctx, request)
+}
+
+func (s *taskShadowStore) setTargetRangeID(
+	key taskListKey,
+	sourceRangeID int64,
+	targetRangeID int64,
+) {
+	s.Lock()
+	defer s.Unlock()
+	if sourceRangeID == targetRangeID {
+		delete(s.targetRangeIDs, key)
+		return
+	}
+	s.targetRangeIDs[key] = targetRangeID
+}
+
+func (s *taskShadowStore) targetRangeID(
+	key taskListKey,
+	sourceRangeID int64,
+) int64 {
+	s.RLock()
+	defer s.RUnlock()
+	if rangeID, ok := s.targetRangeIDs[key]; ok {
+		return rangeID
+	}
+	return sourceRangeID
+}
+
+func (s *taskShadowStore) targetTaskListInfo(
+	info *TaskListInfo,
+) *TaskListInfo {
+	targetInfo := *info
+	targetInfo.RangeID = s.targetRangeID(newTaskListKey(info.DomainID, info.Name, info.TaskType), info.RangeID)
+	return &targetInfo
+}
+
+func newTaskListKey(
+	domainID string,
+	taskListName string,
+	taskType int,
+) taskListKey {
+	return taskListKey{
+		domainID:     domainID,
+		taskListName: taskListName,
+		taskType:     taskType,
+	}
+}
+
+func (s *historyShadowStore) GetName() string {
+	return s.source.GetName()
+}
+
+func (s *historyShadowStore) Close() {
+	s.source.Close()
+	s.target.Close()
+}
+
+func (s *historyShadowStore) AppendHistoryNodes(
+	ctx context.Context,
+	request *InternalAppendHistoryNodesRequest,
+) error {
+	if err := s.source.AppendHistoryNodes(ctx, request); err != nil {
+		return err
+	}
+	s.mirror(tag.StoreOperationAppendHistoryNodes, func() error {
+		return s.target.AppendHistoryNodes(ctx, request)
+	})
+	return nil
+}
+
+func (s *historyShadowStore) ReadHistoryBranch(
+	ctx context.Context,
+	request *InternalReadHistoryBranchRequest,
+) (*InternalReadHistoryBranchResponse, error) {
+	return s.source.ReadHistoryBranch(ctx, request)
+}
+
+func (s *historyShadowStore) ForkHistoryBranch(
+	ctx context.Context,
+	request *InternalForkHistoryBranchRequest,
+) (*InternalForkHistoryBranchResponse, error) {
+	resp, err := s.source.ForkHistoryBranch(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	// the new branch ID is part of the request, so the forked branch has the same ID in both stores
+	s.mirror(tag.StoreOperationForkHistoryBranch, func() error {
+		_, err := s.target.ForkHistoryBranch(ctx, request)
+		return err
+	})
+	return resp, nil
+}
+
+func (s *historyShadowStore) DeleteHistoryBranch(
+	ctx context.Context,
+	request *InternalDeleteHistoryBranchRequest,
+) error {
+	if err := s.source.DeleteHistoryBranch(ctx, request); err != nil {
+		return err
+	}
+	s.mirror(tag.StoreOperationDeleteHistoryBranch, func() error {
+		return s.target.DeleteHistoryBranch(ctx, request)
+	})
+	return nil
+}
+
+func (s *historyShadowStore) GetHistoryTree(
+	ctx context.Context,
+	request *InternalGetHistoryTreeRequest,
+) (*InternalGetHistoryTreeResponse, error) {
+	return s.source.GetHistoryTree(ctx, request)
+}
+
+func (s *historyShadowStore) GetAllHistoryTreeBranches(
+	ctx context.Context,
+	request *GetAllHistoryTreeBranchesRequest,
+) (*GetAllHistoryTreeBranchesResponse, error) {
+	return s.source.GetAllHistoryTreeBranches(ctx, request)
+}
+
+func (s *domainShadowStore) GetName() string {
+	return s.source.GetName()
+}
+
+func (s *domainShadowStore) Close() {
+	s.source.Close()
+	s.target.Close()
+}
+
+func (s *domainShadowStore) CreateDomain(
+	ctx context.Context,
+	request *InternalCreateDomainRequest,
+) (*CreateDomainResponse, error) {
+	resp, err := s.source.CreateDomain(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	s.mirror(tag.StoreOperationCreateDomain, func() error {
+		_, err := s.target.CreateDomain(ctx, request)
+		return err
+	})
+	return resp, nil
+}
+
+func (s *domainShadowStore) GetDomain(
+	ctx context.Context,
+	request *GetDomainRequest,
+) (*InternalGetDomainResponse, error) {
+	return s.source.GetDomain(ctx, request)
+}
+
+func (s *domainShadowStore) UpdateDomain(
+	ctx context.Context,
+	request *InternalUpdateDomainRequest,
+) error {
+	if err := s.source.UpdateDomain(ctx, request); err != nil {
+		return err
+	}
+	s.mirror(tag.StoreOperationUpdateDomain, func() error {
+		// the notification version is tracked by each store separately
+		metadata, err := s.target.GetMetadata(ctx)
+		if err != nil {
+			return err
+		}
+		targetRequest := *request
+		targetRequest.NotificationVersion = metadata.NotificationVersion
+		return s.target.UpdateDomain(ctx, &targetRequest)
+	})
+	return nil
+}
+
+func (s *domainShadowStore) DeleteDomain(
+	ctx context.Context,
+	request *DeleteDomainRequest,
+) error {
+	if err := s.source.DeleteDomain(ctx, request); err != nil {
+		return err
+	}
+	s.mirror(tag.StoreOperationDeleteDomain, func() error {
+		return s.target.DeleteDomain(ctx, request)
+	})
+	return nil
+}
+
+func (s *domainShadowStore) DeleteDomainByName(
+	ctx context.Context,
+	request *DeleteDomainByNameRequest,
+) error {
+	if err := s.source.DeleteDomainByName(ctx, request); err != nil {
+		return err
+	}
+	s.mirror(tag.StoreOperationDeleteDomainByName, func() error {
+		return s.target.DeleteDomainByName(ctx, request)
+	})
+	return nil
+}
+
+func (s *domainShadowStore) ListDomains(
+	ctx context.Context,
+	request *ListDomainsRequest,
+) (*InternalListDomainsResponse, error) {
+	return s.source.ListDomains(ctx, request)
+}
+
+func (s *domainShadowStore) GetMetadata(
+	ctx context.Context,
+) (*GetMetadataResponse, error) {
+	return s.source.GetMetadata(ctx)
+}
@@ -271,6 +271,11 @@ func (t *serializerImpl) serialize(input interface{}, encodingType common.Encodi
 	switch encodingType {
 	case common.EncodingTypeThriftRW:
 		data, err = t.thriftrwEncode(input)
+	case common.EncodingTypeThriftRWSnappy, common.EncodingTypeThriftRWZstd:
+		data, err = t.thriftrwEncode(input)
+		if err == nil {
+			data, err = compress(data, encodingType)
+		}
 	case common.EncodingTypeJSON, common.EncodingTypeUnknown, common.EncodingTypeEmpty: // For backward-compatibility
 		encodingType = common.EncodingTypeJSON
 		data, err = json.Marshal(input)
@@ -321,6 +326,12 @@ func (t *serializerImpl) deserialize(data *DataBlob, target interface{}) error {
 	switch data.GetEncoding() {
 	case common.EncodingTypeThriftRW:
 		err = t.thriftrwDecode(data.Data, target)
+	case common.EncodingTypeThriftRWSnappy, common.EncodingTypeThriftRWZstd:
+		var decompressed []byte
+		decompressed, err = decompress(data.Data, data.GetEncoding())
+		if err == nil {
+			err = t.thriftrwDecode(decompressed, target)
+		}
 	case common.EncodingTypeJSON, common.EncodingTypeUnknown, common.EncodingTypeEmpty: // For backward-compatibility
 		err = json.Unmarshal(data.Data, target)
 	default:
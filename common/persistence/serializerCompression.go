@@ -0,0 +1,102 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package persistence
+
+import (
+	"fmt"
+
+	"github.com/golang/snappy"
+
+	"github.com/uber/cadence/common"
+)
+
+type (
+	// compressor compresses the serialized payload of a compressed encoding type
+	compressor interface {
+		compress(data []byte) ([]byte, error)
+		decompress(data []byte) ([]byte, error)
+	}
+
+	snappyCompressor struct{}
+)
+
+// compressors contains the compressors available in this build, keyed by the compressed encoding type
+var compressors = map[common.EncodingType]compressor{
+	common.EncodingTypeThriftRWSnappy: snappyCompressor{},
+}
+
+// IsCompressedEncodingType returns true if the encoding type compresses the serialized payload
+func IsCompressedEncodingType(encodingType common.EncodingType) bool {
+	switch encodingType {
+	case common.EncodingTypeThriftRWSnappy, common.EncodingTypeThriftRWZstd:
+		return true
+	default:
+		return false
+	}
+}
+
+// IsEncodingTypeAvailable returns false if the encoding type compresses the serialized payload
+// but its compressor is not available in this build, e.g. zstd without cgo
+func IsEncodingTypeAvailable(encodingType common.EncodingType) bool {
+	if !IsCompressedEncodingType(encodingType) {
+		return true
+	}
+	_, ok := compressors[encodingType]
+	return ok
+}
+
+// DecompressDataBlob returns the blob with its payload decompressed and its encoding type set to the
+// uncompressed encoding type, so that it can be handed out to components which are not aware of compression.
+// Uncompressed blobs are returned as is.
+func DecompressDataBlob(blob *DataBlob) (*DataBlob, error) {
+	if blob == nil || !IsCompressedEncodingType(blob.Encoding) {
+		return blob, nil
+	}
+	data, err := decompress(blob.Data, blob.Encoding)
+	if err != nil {
+		return nil, NewCadenceDeserializationError(fmt.Sprintf("DecompressDataBlob encoding: \"%v\", error: %v", blob.Encoding, err.Error()))
+	}
+	return NewDataBlob(data, common.EncodingTypeThriftRW), nil
+}
+
+func compress(data []byte, encodingType common.EncodingType) ([]byte, error) {
+	c, ok := compressors[encodingType]
+	if !ok {
+		return nil, NewUnknownEncodingTypeError(encodingType)
+	}
+	return c.compress(data)
+}
+
+func decompress(data []byte, encodingType common.EncodingType) ([]byte, error) {
+	c, ok := compressors[encodingType]
+	if !ok {
+		return nil, NewUnknownEncodingTypeError(encodingType)
+	}
+	return c.decompress(data)
+}
+
+func (snappyCompressor) compress(data []byte) ([]byte, error) {
+	return snappy.Encode(nil, data), nil
+}
+
+func (snappyCompressor) decompress(data []byte) ([]byte, error) {
+	return snappy.Decode(nil, data)
+}
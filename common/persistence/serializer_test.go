@@ -22,6 +22,7 @@ package persistence
 
 import (
 	"encoding/json"
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -528,3 +529,45 @@ func (s *cadenceSerializerSuite) TestSerializer() {
 	succ := common.AwaitWaitGroup(&doneWG, 10*time.Second)
 	s.True(succ, "test timed out")
 }
+
+func (s *cadenceSerializerSuite) TestSerializer_Compression() {
+	serializer := NewPayloadSerializer()
+	events := []*types.HistoryEvent{
+		{
+			EventID:   1,
+			EventType: types.EventTypeWorkflowExecutionStarted.Ptr(),
+			WorkflowExecutionStartedEventAttributes: &types.WorkflowExecutionStartedEventAttributes{
+				Input: []byte(`{"payload": "` + strings.Repeat("compressible ", 100) + `"}`),
+			},
+		},
+	}
+
+	plain, err := serializer.SerializeBatchEvents(events, common.EncodingTypeThriftRW)
+	s.NoError(err)
+	compressed, err := serializer.SerializeBatchEvents(events, common.EncodingTypeThriftRWSnappy)
+	s.NoError(err)
+	s.Equal(common.EncodingTypeThriftRWSnappy, compressed.Encoding)
+	s.True(len(compressed.Data) < len(plain.Data))
+
+	deserialized, err := serializer.DeserializeBatchEvents(compressed)
+	s.NoError(err)
+	s.Equal(events, deserialized)
+
+	decompressed, err := DecompressDataBlob(compressed)
+	s.NoError(err)
+	s.Equal(plain, decompressed)
+	decompressed, err = DecompressDataBlob(plain)
+	s.NoError(err)
+	s.Equal(plain, decompressed)
+
+	_, err = serializer.DeserializeBatchEvents(NewDataBlob([]byte("not snappy"), common.EncodingTypeThriftRWSnappy))
+	s.Error(err)
+}
+
+func (s *cadenceSerializerSuite) TestIsEncodingTypeAvailable() {
+	s.True(IsEncodingTypeAvailable(common.EncodingTypeThriftRW))
+	s.True(IsEncodingTypeAvailable(common.EncodingTypeJSON))
+	s.True(IsEncodingTypeAvailable(common.EncodingTypeThriftRWSnappy))
+	_, zstdRegistered := compressors[common.EncodingTypeThriftRWZstd]
+	s.Equal(zstdRegistered, IsEncodingTypeAvailable(common.EncodingTypeThriftRWZstd))
+}
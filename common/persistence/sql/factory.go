@@ -28,6 +28,7 @@ import (
 	"github.com/uber/cadence/common/persistence/serialization"
 
 	"github.com/uber/cadence/common/config"
+	"github.com/uber/cadence/common/dynamicconfig"
 	"github.com/uber/cadence/common/log"
 	p "github.com/uber/cadence/common/persistence"
 	"github.com/uber/cadence/common/persistence/sql/sqlplugin"
@@ -118,8 +119,8 @@ func (f *Factory) NewExecutionStore(shardID int) (p.ExecutionStore, error) {
 
 // NewVisibilityStore returns a visibility store
 // TODO sortByCloseTime will be removed and implemented for https://github.com/uber/cadence/issues/3621
-func (f *Factory) NewVisibilityStore(sortByCloseTime bool) (p.VisibilityStore, error) {
-	return NewSQLVisibilityStore(f.cfg, f.logger)
+func (f *Factory) NewVisibilityStore(sortByCloseTime bool, validSearchAttributes dynamicconfig.MapPropertyFn) (p.VisibilityStore, error) {
+	return NewSQLVisibilityStore(f.cfg, validSearchAttributes, f.logger)
 }
 
 // NewQueue returns a new queue backed by sql
@@ -129,7 +130,7 @@ func (f *Factory) NewQueue(queueType p.QueueType) (p.Queue, error) {
 		return nil, err
 	}
 
-	return newQueueStore(conn, f.logger, queueType)
+	return NewSQLQueueStore(conn, f.logger, queueType)
 }
 
 //NewConfigStore returns a new config store backed by sql. Not Yet Implemented.
@@ -1450,6 +1450,7 @@ func buildExecutionRow(
 		LastWriteVersion: lastWriteVersion,
 		Data:             blob.Data,
 		DataEncoding:     string(blob.Encoding),
+		StartTime:        executionInfo.StartTimestamp,
 	}, nil
 }
 
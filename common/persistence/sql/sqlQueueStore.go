@@ -43,7 +43,8 @@ type (
 	}
 )
 
-func newQueueStore(
+// NewSQLQueueStore returns a queue store of the queue type backed by sql
+func NewSQLQueueStore(
 	db sqlplugin.DB,
 	logger log.Logger,
 	queueType persistence.QueueType,
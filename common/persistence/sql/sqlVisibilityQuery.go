@@ -0,0 +1,419 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package sql
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/xwb1989/sqlparser"
+
+	workflow "github.com/uber/cadence/.gen/go/shared"
+	"github.com/uber/cadence/common"
+	"github.com/uber/cadence/common/definition"
+	"github.com/uber/cadence/common/types"
+	"github.com/uber/cadence/common/types/mapper/thrift"
+)
+
+type (
+	// visibilityQuery is a visibility query converted to a condition and an ordering
+	// over executions_visibility table
+	visibilityQuery struct {
+		condition string
+		args      []interface{}
+		orderBy   string
+	}
+
+	// visibilityField is a field of a visibility query mapped to the column it's stored in
+	visibilityField struct {
+		name      string
+		column    string
+		valueType workflow.IndexedValueType
+		custom    bool
+	}
+
+	visibilityQueryConverter struct {
+		validSearchAttributes map[string]interface{}
+		args                  []interface{}
+	}
+)
+
+const (
+	visibilityDefaultOrderBy = "start_time DESC, run_id DESC"
+	visibilityMissingValue   = "missing"
+
+	// custom search attributes are matched through their rows in executions_visibility_search_attributes table
+	templateSearchAttributeCondition = `EXISTS (SELECT 1 FROM executions_visibility_search_attributes sa ` +
+		`WHERE sa.domain_id = executions_visibility.domain_id AND sa.run_id = executions_visibility.run_id AND sa.name = ?%s)`
+)
+
+var visibilitySystemFields = map[string]visibilityField{
+	definition.DomainID:      {column: "domain_id", valueType: workflow.IndexedValueTypeKeyword},
+	definition.WorkflowID:    {column: "workflow_id", valueType: workflow.IndexedValueTypeKeyword},
+	definition.RunID:         {column: "run_id", valueType: workflow.IndexedValueTypeKeyword},
+	definition.WorkflowType:  {column: "workflow_type_name", valueType: workflow.IndexedValueTypeKeyword},
+	definition.StartTime:     {column: "start_time", valueType: workflow.IndexedValueTypeDatetime},
+	definition.ExecutionTime: {column: "execution_time", valueType: workflow.IndexedValueTypeDatetime},
+	definition.CloseTime:     {column: "close_time", valueType: workflow.IndexedValueTypeDatetime},
+	definition.CloseStatus:   {column: "close_status", valueType: workflow.IndexedValueTypeInt},
+	definition.HistoryLength: {column: "history_length", valueType: workflow.IndexedValueTypeInt},
+	definition.TaskList:      {column: "task_list", valueType: workflow.IndexedValueTypeKeyword},
+	definition.IsCron:        {column: "is_cron", valueType: workflow.IndexedValueTypeBool},
+	definition.NumClusters:   {column: "num_clusters", valueType: workflow.IndexedValueTypeInt},
+}
+
+// negatedOperators maps operators that are applied to custom search attributes
+// as the negation of their positive counterpart
+var negatedOperators = map[string]string{
+	sqlparser.NotEqualStr:   sqlparser.EqualStr,
+	sqlparser.NotInStr:      sqlparser.InStr,
+	sqlparser.NotLikeStr:    sqlparser.LikeStr,
+	sqlparser.NotBetweenStr: sqlparser.BetweenStr,
+}
+
+// convertVisibilityQuery converts a visibility query that has been validated by frontend into
+// a SQL condition over executions_visibility table. Values follow the same rules as ElasticSearch
+// visibility: times can be given in unix nanoseconds or RFC3339, close status by value or by name,
+// and "CloseTime = missing" matches open workflows. Custom search attributes are typed after
+// validSearchAttributes, the ValidSearchAttributes dynamic config.
+func convertVisibilityQuery(query string, validSearchAttributes map[string]interface{}) (*visibilityQuery, error) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return &visibilityQuery{orderBy: visibilityDefaultOrderBy}, nil
+	}
+
+	var placeholderQuery string
+	if common.IsJustOrderByClause(query) {
+		placeholderQuery = fmt.Sprintf("SELECT * FROM dummy %s", query)
+	} else {
+		placeholderQuery = fmt.Sprintf("SELECT * FROM dummy WHERE %s", query)
+	}
+	stmt, err := sqlparser.Parse(placeholderQuery)
+	if err != nil {
+		return nil, err
+	}
+	sel, ok := stmt.(*sqlparser.Select)
+	if !ok {
+		return nil, errors.New("invalid select query")
+	}
+
+	result := &visibilityQuery{}
+	c := &visibilityQueryConverter{validSearchAttributes: validSearchAttributes}
+	if sel.Where != nil {
+		if result.condition, err = c.convertExpr(sel.Where.Expr); err != nil {
+			return nil, err
+		}
+		result.args = c.args
+	}
+	if result.orderBy, err = c.convertOrderBy(sel.OrderBy); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (c *visibilityQueryConverter) convertOrderBy(orderBy sqlparser.OrderBy) (string, error) {
+	switch len(orderBy) {
+	case 0:
+		return visibilityDefaultOrderBy, nil
+	case 1:
+	default:
+		return "", errors.New("only one field can be used to sort")
+	}
+	field, err := c.resolveField(orderBy[0].Expr)
+	if err != nil {
+		return "", err
+	}
+	if field.custom {
+		return "", fmt.Errorf("not able to sort by custom search attribute %v", field.name)
+	}
+	direction := "ASC"
+	if orderBy[0].Direction == sqlparser.DescScr {
+		direction = "DESC"
+	}
+	if field.column == "run_id" {
+		return "run_id " + direction, nil
+	}
+	// add RunID as tie-breaker
+	return fmt.Sprintf("%v %v, run_id %v", field.column, direction, direction), nil
+}
+
+func (c *visibilityQueryConverter) convertExpr(expr sqlparser.Expr) (string, error) {
+	switch expr := expr.(type) {
+	case *sqlparser.AndExpr:
+		return c.convertBinaryExpr(expr.Left, "AND", expr.Right)
+	case *sqlparser.OrExpr:
+		return c.convertBinaryExpr(expr.Left, "OR", expr.Right)
+	case *sqlparser.ParenExpr:
+		inner, err := c.convertExpr(expr.Expr)
+		if err != nil {
+			return "", err
+		}
+		return "(" + inner + ")", nil
+	case *sqlparser.ComparisonExpr:
+		return c.convertComparisonExpr(expr)
+	case *sqlparser.RangeCond:
+		return c.convertRangeCond(expr)
+	default:
+		return "", errors.New("invalid where clause")
+	}
+}
+
+func (c *visibilityQueryConverter) convertBinaryExpr(left sqlparser.Expr, operator string, right sqlparser.Expr) (string, error) {
+	leftCondition, err := c.convertExpr(left)
+	if err != nil {
+		return "", err
+	}
+	rightCondition, err := c.convertExpr(right)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("(%v %v %v)", leftCondition, operator, rightCondition), nil
+}
+
+func (c *visibilityQueryConverter) convertComparisonExpr(expr *sqlparser.ComparisonExpr) (string, error) {
+	field, err := c.resolveField(expr.Left)
+	if err != nil {
+		return "", err
+	}
+	if isMissingValue(expr.Right) {
+		return c.convertMissing(field, expr.Operator)
+	}
+
+	var values []sqlparser.Expr
+	switch expr.Operator {
+	case sqlparser.EqualStr, sqlparser.NotEqualStr,
+		sqlparser.LessThanStr, sqlparser.LessEqualStr,
+		sqlparser.GreaterThanStr, sqlparser.GreaterEqualStr,
+		sqlparser.LikeStr, sqlparser.NotLikeStr:
+		values = []sqlparser.Expr{expr.Right}
+	case sqlparser.InStr, sqlparser.NotInStr:
+		tuple, ok := expr.Right.(sqlparser.ValTuple)
+		if !ok || len(tuple) == 0 {
+			return "", fmt.Errorf("invalid values of operator %v", expr.Operator)
+		}
+		values = tuple
+	default:
+		return "", fmt.Errorf("operator %v is not supported", expr.Operator)
+	}
+
+	placeholders := make([]string, len(values))
+	args := make([]interface{}, len(values))
+	for i, value := range values {
+		if args[i], err = convertVisibilityValue(field, value); err != nil {
+			return "", err
+		}
+		placeholders[i] = "?"
+	}
+	operand := placeholders[0]
+	if expr.Operator == sqlparser.InStr || expr.Operator == sqlparser.NotInStr {
+		operand = "(" + strings.Join(placeholders, ", ") + ")"
+	}
+	return c.render(field, expr.Operator, operand, args), nil
+}
+
+func (c *visibilityQueryConverter) convertRangeCond(expr *sqlparser.RangeCond) (string, error) {
+	field, err := c.resolveField(expr.Left)
+	if err != nil {
+		return "", err
+	}
+	if expr.Operator != sqlparser.BetweenStr && expr.Operator != sqlparser.NotBetweenStr {
+		return "", fmt.Errorf("operator %v is not supported", expr.Operator)
+	}
+	from, err := convertVisibilityValue(field, expr.From)
+	if err != nil {
+		return "", err
+	}
+	to, err := convertVisibilityValue(field, expr.To)
+	if err != nil {
+		return "", err
+	}
+	return c.render(field, expr.Operator, "? AND ?", []interface{}{from, to}), nil
+}
+
+func (c *visibilityQueryConverter) convertMissing(field visibilityField, operator string) (string, error) {
+	var negated bool
+	switch operator {
+	case sqlparser.EqualStr:
+		negated = true
+	case sqlparser.NotEqualStr:
+	default:
+		return "", fmt.Errorf("operator %v is not supported for missing value", operator)
+	}
+	if !field.custom {
+		if negated {
+			return field.column + " IS NULL", nil
+		}
+		return field.column + " IS NOT NULL", nil
+	}
+	c.args = append(c.args, field.name)
+	condition := fmt.Sprintf(templateSearchAttributeCondition, "")
+	if negated {
+		return "NOT " + condition, nil
+	}
+	return condition, nil
+}
+
+// render returns the condition of applying the operator to the field and adds its arguments. Custom search
+// attributes are matched if any of their values satisfies the condition, so a negated operator on them
+// matches executions without any such value.
+func (c *visibilityQueryConverter) render(field visibilityField, operator string, operand string, args []interface{}) string {
+	if !field.custom {
+		c.args = append(c.args, args...)
+		return fmt.Sprintf("%v %v %v", field.column, strings.ToUpper(operator), operand)
+	}
+	c.args = append(c.args, field.name)
+	c.args = append(c.args, args...)
+	positive, negated := negatedOperators[operator]
+	if !negated {
+		positive = operator
+	}
+	condition := fmt.Sprintf(templateSearchAttributeCondition,
+		fmt.Sprintf(" AND sa.%v %v %v", field.column, strings.ToUpper(positive), operand))
+	if negated {
+		return "NOT " + condition
+	}
+	return condition
+}
+
+// resolveField returns the field the expression refers to. Custom search attributes are
+// resolved to the column of the type they are declared with
+func (c *visibilityQueryConverter) resolveField(expr sqlparser.Expr) (visibilityField, error) {
+	colName, ok := expr.(*sqlparser.ColName)
+	if !ok {
+		return visibilityField{}, errors.New("invalid search attribute expression")
+	}
+	name := colName.Name.String()
+	if strings.HasPrefix(name, definition.Attr+".") {
+		name = name[len(definition.Attr)+1:]
+		valueType, ok := toIndexedValueType(c.validSearchAttributes[name])
+		if !ok {
+			return visibilityField{}, fmt.Errorf("invalid search attribute %v", name)
+		}
+		return visibilityField{name: name, custom: true}.withType(valueType), nil
+	}
+	field, ok := visibilitySystemFields[name]
+	if !ok {
+		return visibilityField{}, fmt.Errorf("invalid search attribute %v", name)
+	}
+	field.name = name
+	return field, nil
+}
+
+// toIndexedValueType converts the type of a search attribute in ValidSearchAttributes dynamic config
+func toIndexedValueType(valueType interface{}) (workflow.IndexedValueType, bool) {
+	switch t := valueType.(type) {
+	case float64:
+		return workflow.IndexedValueType(t), true
+	case int:
+		return workflow.IndexedValueType(t), true
+	case workflow.IndexedValueType:
+		return t, true
+	default:
+		return 0, false
+	}
+}
+
+func (f visibilityField) withType(valueType workflow.IndexedValueType) visibilityField {
+	f.valueType = valueType
+	switch valueType {
+	case workflow.IndexedValueTypeInt:
+		f.column = "int_value"
+	case workflow.IndexedValueTypeDouble:
+		f.column = "double_value"
+	case workflow.IndexedValueTypeBool:
+		f.column = "bool_value"
+	case workflow.IndexedValueTypeDatetime:
+		f.column = "datetime_value"
+	default:
+		f.column = "string_value"
+	}
+	return f
+}
+
+func isMissingValue(expr sqlparser.Expr) bool {
+	colName, ok := expr.(*sqlparser.ColName)
+	return ok && colName.Name.EqualString(visibilityMissingValue)
+}
+
+func convertVisibilityValue(field visibilityField, expr sqlparser.Expr) (interface{}, error) {
+	var literal string
+	switch expr := expr.(type) {
+	case sqlparser.BoolVal:
+		literal = strconv.FormatBool(bool(expr))
+	case *sqlparser.SQLVal:
+		switch expr.Type {
+		case sqlparser.StrVal, sqlparser.IntVal, sqlparser.FloatVal:
+			literal = string(expr.Val)
+		default:
+			return nil, fmt.Errorf("invalid value of %v", field.name)
+		}
+	default:
+		return nil, fmt.Errorf("invalid value of %v", field.name)
+	}
+
+	if !field.custom && field.name == definition.CloseStatus {
+		var status types.WorkflowExecutionCloseStatus
+		if err := status.UnmarshalText([]byte(literal)); err != nil {
+			return nil, err
+		}
+		return int32(*thrift.FromWorkflowExecutionCloseStatus(&status)), nil
+	}
+
+	switch field.valueType {
+	case workflow.IndexedValueTypeInt:
+		value, err := strconv.ParseInt(literal, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value of %v: %v", field.name, err)
+		}
+		return value, nil
+	case workflow.IndexedValueTypeDouble:
+		value, err := strconv.ParseFloat(literal, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value of %v: %v", field.name, err)
+		}
+		return value, nil
+	case workflow.IndexedValueTypeBool:
+		value, err := strconv.ParseBool(literal)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value of %v: %v", field.name, err)
+		}
+		return value, nil
+	case workflow.IndexedValueTypeDatetime:
+		return parseVisibilityTime(field.name, literal)
+	default:
+		return literal, nil
+	}
+}
+
+// parseVisibilityTime accepts a time in unix nanoseconds or in RFC3339 format
+func parseVisibilityTime(name string, literal string) (time.Time, error) {
+	if unixNano, err := strconv.ParseInt(literal, 10, 64); err == nil {
+		return time.Unix(0, unixNano).UTC(), nil
+	}
+	value, err := time.Parse(time.RFC3339, literal)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid value of %v: %v", name, err)
+	}
+	return value.UTC(), nil
+}
@@ -0,0 +1,153 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package sql
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	workflow "github.com/uber/cadence/.gen/go/shared"
+	"github.com/uber/cadence/common/definition"
+)
+
+const testSearchAttributeCondition = `EXISTS (SELECT 1 FROM executions_visibility_search_attributes sa ` +
+	`WHERE sa.domain_id = executions_visibility.domain_id AND sa.run_id = executions_visibility.run_id AND sa.name = ?`
+
+func TestConvertVisibilityQuery(t *testing.T) {
+	startTime := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := map[string]struct {
+		query             string
+		expectedCondition string
+		expectedArgs      []interface{}
+		expectedOrderBy   string
+	}{
+		"empty query": {
+			query:           "",
+			expectedOrderBy: "start_time DESC, run_id DESC",
+		},
+		"open workflows of a type": {
+			query:             "WorkflowType = 'test-type' and CloseTime = missing",
+			expectedCondition: "(workflow_type_name = ? AND close_time IS NULL)",
+			expectedArgs:      []interface{}{"test-type"},
+			expectedOrderBy:   "start_time DESC, run_id DESC",
+		},
+		"time range in RFC3339 and unix nanos": {
+			query:             "StartTime between '2021-01-01T00:00:00Z' and 1609459200000000000 order by CloseTime asc",
+			expectedCondition: "start_time BETWEEN ? AND ?",
+			expectedArgs:      []interface{}{startTime, startTime},
+			expectedOrderBy:   "close_time ASC, run_id ASC",
+		},
+		"close status by name and value": {
+			query:             "CloseStatus in ('completed', 1)",
+			expectedCondition: "close_status IN (?, ?)",
+			expectedArgs:      []interface{}{int32(0), int32(1)},
+			expectedOrderBy:   "start_time DESC, run_id DESC",
+		},
+		"just order by": {
+			query:           "order by WorkflowID desc",
+			expectedOrderBy: "workflow_id DESC, run_id DESC",
+		},
+		"custom search attributes with declared type": {
+			query:             "`Attr.CustomKeywordField` = 'keyword' or `Attr.CustomIntField` >= 3",
+			expectedCondition: "(" + testSearchAttributeCondition + " AND sa.string_value = ?) OR " + testSearchAttributeCondition + " AND sa.int_value >= ?))",
+			expectedArgs:      []interface{}{"CustomKeywordField", "keyword", "CustomIntField", int64(3)},
+			expectedOrderBy:   "start_time DESC, run_id DESC",
+		},
+		"custom search attribute typed by dynamic config": {
+			query:             "`Attr.CustomAmount` != 2",
+			expectedCondition: "NOT " + testSearchAttributeCondition + " AND sa.double_value = ?)",
+			expectedArgs:      []interface{}{"CustomAmount", float64(2)},
+			expectedOrderBy:   "start_time DESC, run_id DESC",
+		},
+		"keyword search attribute with datetime value": {
+			query:             "`Attr.CustomKeywordField` = '2021-01-01T00:00:00Z'",
+			expectedCondition: testSearchAttributeCondition + " AND sa.string_value = ?)",
+			expectedArgs:      []interface{}{"CustomKeywordField", "2021-01-01T00:00:00Z"},
+			expectedOrderBy:   "start_time DESC, run_id DESC",
+		},
+		"custom search attribute not in": {
+			query:             "`Attr.CustomKeywordField` not in ('a', 'b')",
+			expectedCondition: "NOT " + testSearchAttributeCondition + " AND sa.string_value IN (?, ?))",
+			expectedArgs:      []interface{}{"CustomKeywordField", "a", "b"},
+			expectedOrderBy:   "start_time DESC, run_id DESC",
+		},
+		"missing custom search attribute": {
+			query:             "`Attr.CustomDatetimeField` = missing",
+			expectedCondition: "NOT " + testSearchAttributeCondition + ")",
+			expectedArgs:      []interface{}{"CustomDatetimeField"},
+			expectedOrderBy:   "start_time DESC, run_id DESC",
+		},
+		"custom datetime search attribute": {
+			query:             "`Attr.CustomDatetimeField` < '2021-01-01T00:00:00Z'",
+			expectedCondition: testSearchAttributeCondition + " AND sa.datetime_value < ?)",
+			expectedArgs:      []interface{}{"CustomDatetimeField", startTime},
+			expectedOrderBy:   "start_time DESC, run_id DESC",
+		},
+		"bool values": {
+			query:             "IsCron = true",
+			expectedCondition: "is_cron = ?",
+			expectedArgs:      []interface{}{true},
+			expectedOrderBy:   "start_time DESC, run_id DESC",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			query, err := convertVisibilityQuery(test.query, testValidSearchAttributes())
+			require.NoError(t, err)
+			assert.Equal(t, test.expectedCondition, query.condition)
+			assert.Equal(t, test.expectedArgs, query.args)
+			assert.Equal(t, test.expectedOrderBy, query.orderBy)
+		})
+	}
+}
+
+func TestConvertVisibilityQuery_Invalid(t *testing.T) {
+	for _, query := range []string{
+		"WorkflowType = 'test-type' order by StartTime, CloseTime",
+		"`Attr.CustomKeywordField` = 'keyword' order by `Attr.CustomKeywordField`",
+		"CloseStatus = 'unknown'",
+		"StartTime > 'yesterday'",
+		"UnknownField = 1",
+		"WorkflowID regexp 'abc'",
+		"CloseTime > missing",
+		"`Attr.UnknownField` = 1",
+		"`Attr.CustomAmount` = 'abc'",
+	} {
+		_, err := convertVisibilityQuery(query, testValidSearchAttributes())
+		assert.Error(t, err, query)
+	}
+}
+
+// testValidSearchAttributes returns the default indexed keys and an attribute added through
+// dynamic config, where types are numbers
+func testValidSearchAttributes() map[string]interface{} {
+	validSearchAttributes := map[string]interface{}{}
+	for name, valueType := range definition.GetDefaultIndexedKeys() {
+		validSearchAttributes[name] = valueType
+	}
+	validSearchAttributes["CustomAmount"] = float64(workflow.IndexedValueTypeDouble)
+	return validSearchAttributes
+}
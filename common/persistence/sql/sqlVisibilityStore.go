@@ -21,16 +21,21 @@
 package sql
 
 import (
+	"bytes"
 	"context"
 	"database/sql"
 	"encoding/json"
 	"fmt"
+	"sort"
 	"time"
 
 	workflow "github.com/uber/cadence/.gen/go/shared"
 	"github.com/uber/cadence/common"
 	"github.com/uber/cadence/common/config"
+	"github.com/uber/cadence/common/definition"
+	"github.com/uber/cadence/common/dynamicconfig"
 	"github.com/uber/cadence/common/log"
+	"github.com/uber/cadence/common/log/tag"
 	p "github.com/uber/cadence/common/persistence"
 	"github.com/uber/cadence/common/persistence/sql/sqlplugin"
 	"github.com/uber/cadence/common/types"
@@ -40,25 +45,44 @@ import (
 type (
 	sqlVisibilityStore struct {
 		sqlStore
+		validSearchAttributes dynamicconfig.MapPropertyFn
 	}
 
 	visibilityPageToken struct {
 		Time  time.Time
 		RunID string
 	}
+
+	// visibilityQueryPageToken is the page token of ListWorkflowExecutions and ScanWorkflowExecutions.
+	// List pages through its ordering by offset, while scan pages through run IDs
+	visibilityQueryPageToken struct {
+		Offset    int    `json:",omitempty"`
+		LastRunID string `json:",omitempty"`
+	}
 )
 
-// NewSQLVisibilityStore creates an instance of ExecutionStore
-func NewSQLVisibilityStore(cfg config.SQL, logger log.Logger) (p.VisibilityStore, error) {
+const visibilityQueryDefaultPageSize = 1000
+
+// NewSQLVisibilityStore creates an instance of ExecutionStore. Custom search attributes in visibility
+// queries are typed after validSearchAttributes, which defaults to the default indexed keys when nil
+func NewSQLVisibilityStore(
+	cfg config.SQL,
+	validSearchAttributes dynamicconfig.MapPropertyFn,
+	logger log.Logger,
+) (p.VisibilityStore, error) {
 	db, err := NewSQLDB(&cfg)
 	if err != nil {
 		return nil, err
 	}
+	if validSearchAttributes == nil {
+		validSearchAttributes = dynamicconfig.GetMapPropertyFn(definition.GetDefaultIndexedKeys())
+	}
 	return &sqlVisibilityStore{
 		sqlStore: sqlStore{
 			db:     db,
 			logger: logger,
 		},
+		validSearchAttributes: validSearchAttributes,
 	}, nil
 }
 
@@ -66,23 +90,25 @@ func (s *sqlVisibilityStore) RecordWorkflowExecutionStarted(
 	ctx context.Context,
 	request *p.InternalRecordWorkflowExecutionStartedRequest,
 ) error {
-	_, err := s.db.InsertIntoVisibility(ctx, &sqlplugin.VisibilityRow{
-		DomainID:         request.DomainUUID,
-		WorkflowID:       request.WorkflowID,
-		RunID:            request.RunID,
-		StartTime:        request.StartTimestamp,
-		ExecutionTime:    request.ExecutionTimestamp,
-		WorkflowTypeName: request.WorkflowTypeName,
-		Memo:             request.Memo.Data,
-		Encoding:         string(request.Memo.GetEncoding()),
-		IsCron:           request.IsCron,
-		NumClusters:      request.NumClusters,
+	return s.txExecute(ctx, s.dbShardID(request.DomainUUID), "RecordWorkflowExecutionStarted", func(tx sqlplugin.Tx) error {
+		_, err := tx.InsertIntoVisibility(ctx, &sqlplugin.VisibilityRow{
+			DomainID:         request.DomainUUID,
+			WorkflowID:       request.WorkflowID,
+			RunID:            request.RunID,
+			StartTime:        request.StartTimestamp,
+			ExecutionTime:    request.ExecutionTimestamp,
+			WorkflowTypeName: request.WorkflowTypeName,
+			Memo:             request.Memo.Data,
+			Encoding:         string(request.Memo.GetEncoding()),
+			TaskList:         request.TaskList,
+			IsCron:           request.IsCron,
+			NumClusters:      request.NumClusters,
+		})
+		if err != nil {
+			return err
+		}
+		return s.replaceSearchAttributes(ctx, tx, request.DomainUUID, request.RunID, request.SearchAttributes)
 	})
-
-	if err != nil {
-		return convertCommonErrors(s.db, "RecordWorkflowExecutionStarted", "", err)
-	}
-	return nil
 }
 
 func (s *sqlVisibilityStore) RecordWorkflowExecutionClosed(
@@ -90,46 +116,48 @@ func (s *sqlVisibilityStore) RecordWorkflowExecutionClosed(
 	request *p.InternalRecordWorkflowExecutionClosedRequest,
 ) error {
 	closeTime := request.CloseTimestamp
-	result, err := s.db.ReplaceIntoVisibility(ctx, &sqlplugin.VisibilityRow{
-		DomainID:         request.DomainUUID,
-		WorkflowID:       request.WorkflowID,
-		RunID:            request.RunID,
-		StartTime:        request.StartTimestamp,
-		ExecutionTime:    request.ExecutionTimestamp,
-		WorkflowTypeName: request.WorkflowTypeName,
-		CloseTime:        &closeTime,
-		CloseStatus:      common.Int32Ptr(int32(*thrift.FromWorkflowExecutionCloseStatus(&request.Status))),
-		HistoryLength:    &request.HistoryLength,
-		Memo:             request.Memo.Data,
-		Encoding:         string(request.Memo.GetEncoding()),
-		IsCron:           request.IsCron,
-		NumClusters:      request.NumClusters,
-	})
-	if err != nil {
-		return convertCommonErrors(s.db, "RecordWorkflowExecutionClosed", "", err)
-	}
-	noRowsAffected, err := result.RowsAffected()
-	if err != nil {
-		return &types.InternalServiceError{
-			Message: fmt.Sprintf("RecordWorkflowExecutionClosed rowsAffected error: %v", err),
+	return s.txExecute(ctx, s.dbShardID(request.DomainUUID), "RecordWorkflowExecutionClosed", func(tx sqlplugin.Tx) error {
+		result, err := tx.ReplaceIntoVisibility(ctx, &sqlplugin.VisibilityRow{
+			DomainID:         request.DomainUUID,
+			WorkflowID:       request.WorkflowID,
+			RunID:            request.RunID,
+			StartTime:        request.StartTimestamp,
+			ExecutionTime:    request.ExecutionTimestamp,
+			WorkflowTypeName: request.WorkflowTypeName,
+			CloseTime:        &closeTime,
+			CloseStatus:      common.Int32Ptr(int32(*thrift.FromWorkflowExecutionCloseStatus(&request.Status))),
+			HistoryLength:    &request.HistoryLength,
+			Memo:             request.Memo.Data,
+			Encoding:         string(request.Memo.GetEncoding()),
+			TaskList:         request.TaskList,
+			IsCron:           request.IsCron,
+			NumClusters:      request.NumClusters,
+		})
+		if err != nil {
+			return err
 		}
-	}
-	if noRowsAffected > 2 { // either adds a new row or deletes old row and adds new row
-		return &types.InternalServiceError{
-			Message: fmt.Sprintf("RecordWorkflowExecutionClosed unexpected numRows (%v) updated", noRowsAffected),
+		noRowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return &types.InternalServiceError{
+				Message: fmt.Sprintf("RecordWorkflowExecutionClosed rowsAffected error: %v", err),
+			}
 		}
-	}
-	return nil
+		if noRowsAffected > 2 { // either adds a new row or deletes old row and adds new row
+			return &types.InternalServiceError{
+				Message: fmt.Sprintf("RecordWorkflowExecutionClosed unexpected numRows (%v) updated", noRowsAffected),
+			}
+		}
+		return s.replaceSearchAttributes(ctx, tx, request.DomainUUID, request.RunID, request.SearchAttributes)
+	})
 }
 
 func (s *sqlVisibilityStore) UpsertWorkflowExecution(
 	ctx context.Context,
 	request *p.InternalUpsertWorkflowExecutionRequest,
 ) error {
-	if p.IsNopUpsertWorkflowRequest(request) {
-		return nil
-	}
-	return p.NewOperationNotSupportErrorForVis()
+	return s.txExecute(ctx, s.dbShardID(request.DomainUUID), "UpsertWorkflowExecution", func(tx sqlplugin.Tx) error {
+		return s.replaceSearchAttributes(ctx, tx, request.DomainUUID, request.RunID, request.SearchAttributes)
+	})
 }
 
 func (s *sqlVisibilityStore) ListOpenWorkflowExecutions(
@@ -282,35 +310,244 @@ func (s *sqlVisibilityStore) DeleteWorkflowExecution(
 	ctx context.Context,
 	request *p.VisibilityDeleteWorkflowExecutionRequest,
 ) error {
-	_, err := s.db.DeleteFromVisibility(ctx, &sqlplugin.VisibilityFilter{
-		DomainID: request.DomainID,
-		RunID:    &request.RunID,
+	return s.txExecute(ctx, s.dbShardID(request.DomainID), "DeleteWorkflowExecution", func(tx sqlplugin.Tx) error {
+		_, err := tx.DeleteFromVisibility(ctx, &sqlplugin.VisibilityFilter{
+			DomainID: request.DomainID,
+			RunID:    &request.RunID,
+		})
+		if err != nil {
+			return err
+		}
+		_, err = tx.DeleteFromVisibilitySearchAttributes(ctx, &sqlplugin.VisibilitySearchAttributesFilter{
+			DomainID: request.DomainID,
+			RunID:    request.RunID,
+		})
+		return err
 	})
-	if err != nil {
-		return convertCommonErrors(s.db, "DeleteWorkflowExecution", "", err)
-	}
-	return nil
 }
 
 func (s *sqlVisibilityStore) ListWorkflowExecutions(
 	ctx context.Context,
 	request *p.ListWorkflowExecutionsByQueryRequest,
 ) (*p.InternalListWorkflowExecutionsResponse, error) {
-	return nil, p.NewOperationNotSupportErrorForVis()
+	query, err := convertVisibilityQuery(request.Query, s.validSearchAttributes())
+	if err != nil {
+		return nil, &types.BadRequestError{Message: fmt.Sprintf("Error when parse query: %v", err)}
+	}
+	token, err := s.deserializeQueryPageToken(request.NextPageToken)
+	if err != nil {
+		return nil, err
+	}
+	filter := &sqlplugin.VisibilityQueryFilter{
+		DomainID:  request.DomainUUID,
+		Condition: query.condition,
+		Args:      query.args,
+		OrderBy:   query.orderBy,
+		Offset:    token.Offset,
+		PageSize:  getQueryPageSize(request.PageSize),
+	}
+	return s.listWorkflowExecutionsByQuery(ctx, "ListWorkflowExecutions", filter, func(rows []sqlplugin.VisibilityRow) *visibilityQueryPageToken {
+		return &visibilityQueryPageToken{Offset: token.Offset + len(rows)}
+	})
 }
 
 func (s *sqlVisibilityStore) ScanWorkflowExecutions(
 	ctx context.Context,
 	request *p.ListWorkflowExecutionsByQueryRequest,
 ) (*p.InternalListWorkflowExecutionsResponse, error) {
-	return nil, p.NewOperationNotSupportErrorForVis()
+	query, err := convertVisibilityQuery(request.Query, s.validSearchAttributes())
+	if err != nil {
+		return nil, &types.BadRequestError{Message: fmt.Sprintf("Error when parse query: %v", err)}
+	}
+	token, err := s.deserializeQueryPageToken(request.NextPageToken)
+	if err != nil {
+		return nil, err
+	}
+	// scan ignores the ordering of the query and pages through run IDs instead
+	filter := &sqlplugin.VisibilityQueryFilter{
+		DomainID:  request.DomainUUID,
+		Condition: "run_id > ?",
+		Args:      []interface{}{token.LastRunID},
+		OrderBy:   "run_id",
+		PageSize:  getQueryPageSize(request.PageSize),
+	}
+	if query.condition != "" {
+		filter.Condition = "(" + query.condition + ") AND " + filter.Condition
+		filter.Args = append(query.args, filter.Args...)
+	}
+	return s.listWorkflowExecutionsByQuery(ctx, "ScanWorkflowExecutions", filter, func(rows []sqlplugin.VisibilityRow) *visibilityQueryPageToken {
+		return &visibilityQueryPageToken{LastRunID: rows[len(rows)-1].RunID}
+	})
 }
 
 func (s *sqlVisibilityStore) CountWorkflowExecutions(
 	ctx context.Context,
 	request *p.CountWorkflowExecutionsRequest,
 ) (*p.CountWorkflowExecutionsResponse, error) {
-	return nil, p.NewOperationNotSupportErrorForVis()
+	query, err := convertVisibilityQuery(request.Query, s.validSearchAttributes())
+	if err != nil {
+		return nil, &types.BadRequestError{Message: fmt.Sprintf("Error when parse query: %v", err)}
+	}
+	count, err := s.db.CountFromVisibilityByQuery(ctx, &sqlplugin.VisibilityQueryFilter{
+		DomainID:  request.DomainUUID,
+		Condition: query.condition,
+		Args:      query.args,
+	})
+	if err != nil {
+		return nil, convertCommonErrors(s.db, "CountWorkflowExecutions", "", err)
+	}
+	return &p.CountWorkflowExecutionsResponse{Count: count}, nil
+}
+
+func (s *sqlVisibilityStore) listWorkflowExecutionsByQuery(
+	ctx context.Context,
+	opName string,
+	filter *sqlplugin.VisibilityQueryFilter,
+	nextPage func(rows []sqlplugin.VisibilityRow) *visibilityQueryPageToken,
+) (*p.InternalListWorkflowExecutionsResponse, error) {
+	rows, err := s.db.SelectFromVisibilityByQuery(ctx, filter)
+	if err != nil {
+		return nil, convertCommonErrors(s.db, opName, "", err)
+	}
+	infos := make([]*p.InternalVisibilityWorkflowExecutionInfo, len(rows))
+	for i := range rows {
+		rows[i].DomainID = filter.DomainID
+		infos[i] = s.rowToInfo(&rows[i])
+	}
+	var nextPageToken []byte
+	if len(rows) == filter.PageSize {
+		nextPageToken, err = json.Marshal(nextPage(rows))
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &p.InternalListWorkflowExecutionsResponse{
+		Executions:    infos,
+		NextPageToken: nextPageToken,
+	}, nil
+}
+
+// replaceSearchAttributes replaces all the search attributes recorded for a run with the given ones
+// within the transaction that records the run
+func (s *sqlVisibilityStore) replaceSearchAttributes(
+	ctx context.Context,
+	tx sqlplugin.Tx,
+	domainID string,
+	runID string,
+	searchAttributes map[string][]byte,
+) error {
+	_, err := tx.DeleteFromVisibilitySearchAttributes(ctx, &sqlplugin.VisibilitySearchAttributesFilter{
+		DomainID: domainID,
+		RunID:    runID,
+	})
+	if err != nil {
+		return err
+	}
+	rows := s.searchAttributeRows(domainID, runID, searchAttributes)
+	if len(rows) == 0 {
+		return nil
+	}
+	_, err = tx.InsertIntoVisibilitySearchAttributes(ctx, rows)
+	return err
+}
+
+func (s *sqlVisibilityStore) dbShardID(domainID string) int {
+	return sqlplugin.GetDBShardIDFromDomainID(domainID, s.db.GetTotalNumDBShards())
+}
+
+// searchAttributeRows converts search attributes to the rows of executions_visibility_search_attributes table.
+// Values that can't be decoded are skipped so that they don't block recording the rest of the execution
+func (s *sqlVisibilityStore) searchAttributeRows(
+	domainID string,
+	runID string,
+	searchAttributes map[string][]byte,
+) []sqlplugin.VisibilitySearchAttributeRow {
+	names := make([]string, 0, len(searchAttributes))
+	for name := range searchAttributes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var rows []sqlplugin.VisibilitySearchAttributeRow
+	for _, name := range names {
+		decoder := json.NewDecoder(bytes.NewReader(searchAttributes[name]))
+		decoder.UseNumber()
+		var value interface{}
+		if err := decoder.Decode(&value); err != nil {
+			s.logger.Warn("Unable to decode search attribute", tag.ESField(name), tag.Error(err))
+			continue
+		}
+		values, isArray := value.([]interface{})
+		if !isArray {
+			values = []interface{}{value}
+		}
+		for i, v := range values {
+			row, ok := newSearchAttributeRow(domainID, runID, name, int32(i), v)
+			if !ok {
+				s.logger.Warn("Unsupported search attribute value", tag.ESField(name), tag.Value(v))
+				continue
+			}
+			rows = append(rows, row)
+		}
+	}
+	return rows
+}
+
+// newSearchAttributeRow stores the value in all the typed columns it converts to, so that
+// queries can match it whatever type the attribute is declared with
+func newSearchAttributeRow(
+	domainID string,
+	runID string,
+	name string,
+	index int32,
+	value interface{},
+) (sqlplugin.VisibilitySearchAttributeRow, bool) {
+	row := sqlplugin.VisibilitySearchAttributeRow{
+		DomainID:   domainID,
+		RunID:      runID,
+		Name:       name,
+		ValueIndex: index,
+	}
+	switch v := value.(type) {
+	case string:
+		row.StringValue = common.StringPtr(v)
+		if datetime, err := time.Parse(time.RFC3339Nano, v); err == nil {
+			datetime = datetime.UTC()
+			row.DatetimeValue = &datetime
+		}
+	case json.Number:
+		if intValue, err := v.Int64(); err == nil {
+			row.IntValue = common.Int64Ptr(intValue)
+			datetime := time.Unix(0, intValue).UTC()
+			row.DatetimeValue = &datetime
+		}
+		if doubleValue, err := v.Float64(); err == nil {
+			row.DoubleValue = common.Float64Ptr(doubleValue)
+		}
+	case bool:
+		row.BoolValue = common.BoolPtr(v)
+	default:
+		return row, false
+	}
+	return row, true
+}
+
+func (s *sqlVisibilityStore) deserializeQueryPageToken(data []byte) (*visibilityQueryPageToken, error) {
+	var token visibilityQueryPageToken
+	if len(data) == 0 {
+		return &token, nil
+	}
+	if err := json.Unmarshal(data, &token); err != nil {
+		return nil, &types.BadRequestError{Message: fmt.Sprintf("Invalid next page token: %v", err)}
+	}
+	return &token, nil
+}
+
+func getQueryPageSize(pageSize int) int {
+	if pageSize <= 0 {
+		return visibilityQueryDefaultPageSize
+	}
+	return pageSize
 }
 
 func (s *sqlVisibilityStore) rowToInfo(row *sqlplugin.VisibilityRow) *p.InternalVisibilityWorkflowExecutionInfo {
@@ -325,6 +562,7 @@ func (s *sqlVisibilityStore) rowToInfo(row *sqlplugin.VisibilityRow) *p.Internal
 		ExecutionTime: row.ExecutionTime,
 		IsCron:        row.IsCron,
 		NumClusters:   row.NumClusters,
+		TaskList:      row.TaskList,
 		Memo:          p.NewDataBlob(row.Memo, common.EncodingType(row.Encoding)),
 	}
 	if row.CloseStatus != nil {
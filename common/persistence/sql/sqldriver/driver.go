@@ -27,15 +27,16 @@ import (
 )
 
 // NewDriver returns a driver to SQL, either using singleton Driver or sharded Driver
-func NewDriver(xdbs []*sqlx.DB, tx *sqlx.Tx, dbShardID int) (Driver, error) {
+// replicas is optional, it's the read replicas of the databases
+func NewDriver(xdbs []*sqlx.DB, replicas *Replicas, tx *sqlx.Tx, dbShardID int) (Driver, error) {
 
 	if len(xdbs) == 1 {
-		return newSingletonSQLDriver(xdbs[0], tx, dbShardID), nil
+		return newSingletonSQLDriver(xdbs[0], replicas, tx, dbShardID), nil
 	}
 
 	if len(xdbs) <= 1 {
 		return nil, fmt.Errorf("invalid number of connection for sharded SQL driver")
 	}
 	// this is the case of multiple database with sharding
-	return newShardedSQLDriver(xdbs, tx, dbShardID), nil
+	return newShardedSQLDriver(xdbs, replicas, tx, dbShardID), nil
 }
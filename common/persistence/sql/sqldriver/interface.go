@@ -45,6 +45,13 @@ type (
 		// Close closes this driver(and underlying connections)
 		Close() error
 
+		// GetReplicaContext executes a get query(returning single row) on a read replica of the shard when there is one
+		// within the lag tolerance, otherwise on the primary database. It must only be used for non-conditional reads
+		// which can tolerate replication lag. If a transaction is started, the query is executed in the transaction.
+		GetReplicaContext(ctx context.Context, dbShardID int, dest interface{}, query string, args ...interface{}) error
+		// SelectReplicaContext is the same as GetReplicaContext but for a select query(returning multiple rows).
+		SelectReplicaContext(ctx context.Context, dbShardID int, dest interface{}, query string, args ...interface{}) error
+
 		// ExecDDL executes a DDL query
 		ExecDDL(ctx context.Context, dbShardID int, query string, args ...interface{}) (sql.Result, error)
 		// SelectForSchemaQuery executes a select query for schema(returning multiple rows).
@@ -0,0 +1,250 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package sqldriver
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/multierr"
+
+	"github.com/uber/cadence/common/config"
+)
+
+const (
+	defaultMaxReplicationLag    = 5 * time.Second
+	replicationLagCheckInterval = 5 * time.Second
+	replicationLagCheckTimeout  = 2 * time.Second
+)
+
+type (
+	// ReplicationLagFunc returns how much a read replica is lagging behind its primary database
+	ReplicationLagFunc func(ctx context.Context, db *sqlx.DB) (time.Duration, error)
+
+	// Replicas is the set of read replicas of every DB shard. The replication lag of every replica is
+	// checked periodically and reads are only routed to the replicas within the lag tolerance.
+	Replicas struct {
+		shards   [][]*replica // indexed by dbShardID
+		lagFunc  ReplicationLagFunc
+		maxLag   time.Duration
+		interval time.Duration
+		next     uint32 // for picking the replicas in a round robin way
+
+		shutdownCh chan struct{}
+		shutdownWG sync.WaitGroup
+	}
+
+	replica struct {
+		db      *sqlx.DB
+		healthy int32
+	}
+)
+
+// CreateReplicaConnections returns connections to the read replicas of every DB shard.
+// It returns nil when there is no replica configured.
+func CreateReplicaConnections(
+	cfg *config.SQL,
+	createConnFunc CreateSingleDBConn,
+	lagFunc ReplicationLagFunc,
+) (*Replicas, error) {
+	var entries [][]config.ReplicaConfigEntry
+	var primaries []config.MultipleDatabasesConfigEntry
+	if cfg.UseMultipleDatabases {
+		for _, entry := range cfg.MultipleDatabasesConfig {
+			entries = append(entries, entry.Replicas)
+			primaries = append(primaries, entry)
+		}
+	} else {
+		entries = append(entries, cfg.Replicas)
+		primaries = append(primaries, config.MultipleDatabasesConfigEntry{
+			User:         cfg.User,
+			Password:     cfg.Password,
+			DatabaseName: cfg.DatabaseName,
+		})
+	}
+
+	numReplicas := 0
+	for _, shardEntries := range entries {
+		numReplicas += len(shardEntries)
+	}
+	if numReplicas == 0 {
+		return nil, nil
+	}
+	if lagFunc == nil {
+		return nil, fmt.Errorf("sql plugin %v doesn't support read replicas", cfg.PluginName)
+	}
+
+	dbs := make([][]*sqlx.DB, len(entries))
+	for idx, shardEntries := range entries {
+		for _, entry := range shardEntries {
+			replicaCfg := *cfg
+			replicaCfg.User = primaries[idx].User
+			replicaCfg.Password = primaries[idx].Password
+			replicaCfg.DatabaseName = primaries[idx].DatabaseName
+			replicaCfg.ConnectAddr = entry.ConnectAddr
+			if entry.User != "" {
+				replicaCfg.User = entry.User
+				replicaCfg.Password = entry.Password
+			}
+			xdb, err := createConnFunc(&replicaCfg)
+			if err != nil {
+				closeReplicaConnections(dbs)
+				return nil, fmt.Errorf("got error of %v to connect to replica %v of %v database", err, entry.ConnectAddr, idx)
+			}
+			dbs[idx] = append(dbs[idx], xdb)
+		}
+	}
+	replicas := newReplicas(dbs, lagFunc, cfg.MaxReplicationLag, replicationLagCheckInterval)
+	replicas.start()
+	return replicas, nil
+}
+
+func newReplicas(
+	dbs [][]*sqlx.DB,
+	lagFunc ReplicationLagFunc,
+	maxLag time.Duration,
+	interval time.Duration,
+) *Replicas {
+	if maxLag <= 0 {
+		maxLag = defaultMaxReplicationLag
+	}
+	shards := make([][]*replica, len(dbs))
+	for idx, shardDBs := range dbs {
+		for _, db := range shardDBs {
+			shards[idx] = append(shards[idx], &replica{db: db})
+		}
+	}
+	return &Replicas{
+		shards:     shards,
+		lagFunc:    lagFunc,
+		maxLag:     maxLag,
+		interval:   interval,
+		shutdownCh: make(chan struct{}),
+	}
+}
+
+func (r *Replicas) start() {
+	// check synchronously once so that reads can be routed to replicas right away
+	r.checkReplicationLag()
+	r.shutdownWG.Add(1)
+	go r.checkReplicationLagLoop()
+}
+
+// Close stops checking the replication lag and closes the connections to the replicas
+func (r *Replicas) Close() error {
+	if r == nil {
+		return nil
+	}
+	close(r.shutdownCh)
+	r.shutdownWG.Wait()
+
+	var errs []error
+	for _, shard := range r.shards {
+		for _, replica := range shard {
+			if err := replica.db.Close(); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return multierr.Combine(errs...)
+}
+
+func (r *Replicas) checkReplicationLagLoop() {
+	defer r.shutdownWG.Done()
+
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-r.shutdownCh:
+			return
+		case <-ticker.C:
+			r.checkReplicationLag()
+		}
+	}
+}
+
+func (r *Replicas) checkReplicationLag() {
+	for _, shard := range r.shards {
+		for _, replica := range shard {
+			ctx, cancel := context.WithTimeout(context.Background(), replicationLagCheckTimeout)
+			lag, err := r.lagFunc(ctx, replica.db)
+			cancel()
+			if err == nil && lag <= r.maxLag {
+				atomic.StoreInt32(&replica.healthy, 1)
+			} else {
+				atomic.StoreInt32(&replica.healthy, 0)
+			}
+		}
+	}
+}
+
+// pick returns a replica of the shard within the lag tolerance, or nil if there is none
+func (r *Replicas) pick(dbShardID int) *sqlx.DB {
+	if r == nil || dbShardID < 0 || dbShardID >= len(r.shards) {
+		return nil
+	}
+	shard := r.shards[dbShardID]
+	if len(shard) == 0 {
+		return nil
+	}
+	start := atomic.AddUint32(&r.next, 1)
+	for i := 0; i < len(shard); i++ {
+		replica := shard[(int(start)+i)%len(shard)]
+		if atomic.LoadInt32(&replica.healthy) == 1 {
+			return replica.db
+		}
+	}
+	return nil
+}
+
+// read executes the read on a replica of the shard within the lag tolerance. It returns false when the read
+// should be executed on the primary database instead, which happens when there is no available replica or the
+// replica fails to serve the read.
+func (r *Replicas) read(ctx context.Context, dbShardID int, dest interface{}, readFn func(db *sqlx.DB) error) (bool, error) {
+	db := r.pick(dbShardID)
+	if db == nil {
+		return false, nil
+	}
+	err := readFn(db)
+	if err == nil || err == sql.ErrNoRows || ctx.Err() != nil {
+		return true, err
+	}
+	// the dest may have been partially filled by the failed read
+	if v := reflect.ValueOf(dest); v.Kind() == reflect.Ptr && !v.IsNil() {
+		v.Elem().Set(reflect.Zero(v.Elem().Type()))
+	}
+	return false, nil
+}
+
+func closeReplicaConnections(dbs [][]*sqlx.DB) {
+	for _, shardDBs := range dbs {
+		for _, db := range shardDBs {
+			db.Close()
+		}
+	}
+}
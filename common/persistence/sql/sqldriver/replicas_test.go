@@ -0,0 +1,146 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package sqldriver
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/suite"
+)
+
+type (
+	replicasSuite struct {
+		suite.Suite
+
+		replica1 *sqlx.DB
+		replica2 *sqlx.DB
+		lags     map[*sqlx.DB]time.Duration
+		replicas *Replicas
+	}
+)
+
+func TestReplicasSuite(t *testing.T) {
+	suite.Run(t, new(replicasSuite))
+}
+
+func (s *replicasSuite) SetupTest() {
+	s.replica1 = &sqlx.DB{}
+	s.replica2 = &sqlx.DB{}
+	s.lags = map[*sqlx.DB]time.Duration{}
+	s.replicas = newReplicas(
+		[][]*sqlx.DB{{s.replica1, s.replica2}, {}},
+		func(_ context.Context, db *sqlx.DB) (time.Duration, error) {
+			lag, ok := s.lags[db]
+			if !ok {
+				return 0, errors.New("replication is not running")
+			}
+			return lag, nil
+		},
+		time.Second,
+		time.Minute,
+	)
+}
+
+func (s *replicasSuite) TestPick_WithinLagTolerance() {
+	s.lags[s.replica1] = time.Millisecond
+	s.lags[s.replica2] = time.Second
+	s.replicas.checkReplicationLag()
+
+	picked := map[*sqlx.DB]int{}
+	for i := 0; i < 10; i++ {
+		picked[s.replicas.pick(0)]++
+	}
+	s.Equal(map[*sqlx.DB]int{s.replica1: 5, s.replica2: 5}, picked)
+}
+
+func (s *replicasSuite) TestPick_SkipLaggingReplica() {
+	s.lags[s.replica1] = time.Minute
+	s.lags[s.replica2] = time.Millisecond
+	s.replicas.checkReplicationLag()
+
+	for i := 0; i < 10; i++ {
+		s.Equal(s.replica2, s.replicas.pick(0))
+	}
+
+	// replica1 catches up and replica2 stops replicating
+	s.lags[s.replica1] = 0
+	delete(s.lags, s.replica2)
+	s.replicas.checkReplicationLag()
+
+	for i := 0; i < 10; i++ {
+		s.Equal(s.replica1, s.replicas.pick(0))
+	}
+}
+
+func (s *replicasSuite) TestPick_NoAvailableReplica() {
+	s.lags[s.replica1] = time.Minute
+	s.replicas.checkReplicationLag()
+
+	s.Nil(s.replicas.pick(0))
+	s.Nil(s.replicas.pick(1))
+	s.Nil(s.replicas.pick(2))
+
+	var nilReplicas *Replicas
+	s.Nil(nilReplicas.pick(0))
+}
+
+func (s *replicasSuite) TestRead() {
+	s.lags[s.replica1] = 0
+	s.lags[s.replica2] = 0
+	s.replicas.checkReplicationLag()
+	ctx := context.Background()
+
+	rows := []int{1}
+	done, err := s.replicas.read(ctx, 0, &rows, func(db *sqlx.DB) error {
+		rows = append(rows, 2)
+		return nil
+	})
+	s.True(done)
+	s.NoError(err)
+	s.Equal([]int{1, 2}, rows)
+
+	done, err = s.replicas.read(ctx, 0, &rows, func(db *sqlx.DB) error {
+		return sql.ErrNoRows
+	})
+	s.True(done)
+	s.Equal(sql.ErrNoRows, err)
+
+	// falls back to the primary and resets the partially filled dest
+	done, err = s.replicas.read(ctx, 0, &rows, func(db *sqlx.DB) error {
+		rows = append(rows, 3)
+		return errors.New("connection refused")
+	})
+	s.False(done)
+	s.NoError(err)
+	s.Nil(rows)
+
+	done, err = s.replicas.read(ctx, 1, &rows, func(db *sqlx.DB) error {
+		s.Fail("shard without replica shouldn't be read")
+		return nil
+	})
+	s.False(done)
+	s.NoError(err)
+}
@@ -35,6 +35,7 @@ type (
 	// sharded is the driver querying a group of SQL databases as sharded solution
 	sharded struct {
 		dbs           []*sqlx.DB // this is for starting a transaction, or executing any non transaction query
+		replicas      *Replicas  // this is for executing non-conditional reads, can be nil
 		tx            *sqlx.Tx   // this is a reference of a started transaction
 		useTx         bool       // if tx is not nil, the methods from commonOfDbAndTx should use tx
 		currTxShardID int        // which shard is current tx started from
@@ -46,10 +47,11 @@ type (
 // newShardedSQLDriver returns a driver querying a group of SQL databases as sharded solution.
 // xdbs is the list of connections to the sql instances. The length of the list of the list is the totalNumShards
 // dbShardID is needed when tx is not nil. It means a started transaction in the shard.
-func newShardedSQLDriver(xdbs []*sqlx.DB, xtx *sqlx.Tx, dbShardID int) Driver {
+func newShardedSQLDriver(xdbs []*sqlx.DB, replicas *Replicas, xtx *sqlx.Tx, dbShardID int) Driver {
 	driver := &sharded{
-		dbs: xdbs,
-		tx:  xtx,
+		dbs:      xdbs,
+		replicas: replicas,
+		tx:       xtx,
 	}
 	if xtx != nil {
 		driver.useTx = true
@@ -113,6 +115,34 @@ func (s *sharded) SelectContext(ctx context.Context, dbShardID int, dest interfa
 
 }
 
+func (s *sharded) GetReplicaContext(ctx context.Context, dbShardID int, dest interface{}, query string, args ...interface{}) error {
+	if dbShardID == sqlplugin.DbShardUndefined || dbShardID == sqlplugin.DbAllShards {
+		return fmt.Errorf("invalid dbShardID %v shouldn't be used to GetReplicaContext, there must be a bug", dbShardID)
+	}
+	if !s.useTx {
+		if done, err := s.replicas.read(ctx, dbShardID, dest, func(db *sqlx.DB) error {
+			return db.GetContext(ctx, dest, query, args...)
+		}); done {
+			return err
+		}
+	}
+	return s.GetContext(ctx, dbShardID, dest, query, args...)
+}
+
+func (s *sharded) SelectReplicaContext(ctx context.Context, dbShardID int, dest interface{}, query string, args ...interface{}) error {
+	if dbShardID == sqlplugin.DbShardUndefined || dbShardID == sqlplugin.DbAllShards {
+		return fmt.Errorf("invalid dbShardID %v shouldn't be used to SelectReplicaContext, there must be a bug", dbShardID)
+	}
+	if !s.useTx {
+		if done, err := s.replicas.read(ctx, dbShardID, dest, func(db *sqlx.DB) error {
+			return db.SelectContext(ctx, dest, query, args...)
+		}); done {
+			return err
+		}
+	}
+	return s.SelectContext(ctx, dbShardID, dest, query, args...)
+}
+
 // below are non-transactional methods only
 
 func (s *sharded) ExecDDL(ctx context.Context, dbShardID int, query string, args ...interface{}) (sql.Result, error) {
@@ -148,6 +178,9 @@ func (s *sharded) Close() error {
 			errs = append(errs, err)
 		}
 	}
+	if err := s.replicas.Close(); err != nil {
+		errs = append(errs, err)
+	}
 	if len(errs) > 0 {
 		return multierr.Combine(errs...)
 	}
@@ -25,24 +25,27 @@ import (
 	"database/sql"
 
 	"github.com/jmoiron/sqlx"
+	"go.uber.org/multierr"
 )
 
 type (
 	// singleton is the driver querying a single SQL database, which is the default driver
 	singleton struct {
-		db    *sqlx.DB // this is for starting a transaction, or executing any non transaction query
-		tx    *sqlx.Tx // this is a reference of a started transaction
-		useTx bool     // if tx is not nil, the methods from commonOfDbAndTx should use tx
+		db       *sqlx.DB  // this is for starting a transaction, or executing any non transaction query
+		replicas *Replicas // this is for executing non-conditional reads, can be nil
+		tx       *sqlx.Tx  // this is a reference of a started transaction
+		useTx    bool      // if tx is not nil, the methods from commonOfDbAndTx should use tx
 	}
 )
 
 // newSingletonSQLDriver returns a driver querying a single SQL database, which is the default driver
 // typically dbShardID is needed when tx is not nil, because it means a started transaction in a shard.
 // But this singleton doesn't have sharding so omitting it.
-func newSingletonSQLDriver(xdb *sqlx.DB, xtx *sqlx.Tx, _ int) Driver {
+func newSingletonSQLDriver(xdb *sqlx.DB, replicas *Replicas, xtx *sqlx.Tx, _ int) Driver {
 	driver := &singleton{
-		db: xdb,
-		tx: xtx,
+		db:       xdb,
+		replicas: replicas,
+		tx:       xtx,
 	}
 	if xtx != nil {
 		driver.useTx = true
@@ -80,6 +83,28 @@ func (s *singleton) SelectContext(ctx context.Context, _ int, dest interface{},
 	return s.db.SelectContext(ctx, dest, query, args...)
 }
 
+func (s *singleton) GetReplicaContext(ctx context.Context, _ int, dest interface{}, query string, args ...interface{}) error {
+	if !s.useTx {
+		if done, err := s.replicas.read(ctx, 0, dest, func(db *sqlx.DB) error {
+			return db.GetContext(ctx, dest, query, args...)
+		}); done {
+			return err
+		}
+	}
+	return s.GetContext(ctx, 0, dest, query, args...)
+}
+
+func (s *singleton) SelectReplicaContext(ctx context.Context, _ int, dest interface{}, query string, args ...interface{}) error {
+	if !s.useTx {
+		if done, err := s.replicas.read(ctx, 0, dest, func(db *sqlx.DB) error {
+			return db.SelectContext(ctx, dest, query, args...)
+		}); done {
+			return err
+		}
+	}
+	return s.SelectContext(ctx, 0, dest, query, args...)
+}
+
 // below are non-transactional methods only
 
 func (s *singleton) ExecDDL(ctx context.Context, _ int, query string, args ...interface{}) (sql.Result, error) {
@@ -99,7 +124,7 @@ func (s *singleton) BeginTxx(ctx context.Context, _ int, opts *sql.TxOptions) (*
 }
 
 func (s *singleton) Close() error {
-	return s.db.Close()
+	return multierr.Combine(s.db.Close(), s.replicas.Close())
 }
 
 // below are transactional methods only
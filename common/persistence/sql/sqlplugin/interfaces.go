@@ -133,6 +133,10 @@ type (
 		DataEncoding             string
 		VersionHistories         []byte
 		VersionHistoriesEncoding string
+		// StartTime is the start time of the workflow, only used for inserting into the partitioned schema
+		StartTime time.Time
+		// Bucket is the partition bucket of the row, only used by the partitioned schema
+		Bucket int64
 	}
 
 	// ExecutionsFilter contains the column names within executions table that
@@ -364,6 +368,8 @@ type (
 		TxnID        *int64
 		Data         []byte
 		DataEncoding string
+		// Bucket is the partition bucket of the row, only used by the partitioned schema
+		Bucket int64
 	}
 
 	// HistoryNodeFilter contains the column names within history_node table that
@@ -536,10 +542,45 @@ type (
 		HistoryLength    *int64
 		Memo             []byte
 		Encoding         string
+		TaskList         string
 		IsCron           bool
 		NumClusters      int16
 	}
 
+	// VisibilitySearchAttributeRow represents a row in executions_visibility_search_attributes table.
+	// Every value of a search attribute is stored in its own row, in all the typed columns it converts to
+	VisibilitySearchAttributeRow struct {
+		DomainID      string
+		RunID         string
+		Name          string
+		ValueIndex    int32
+		StringValue   *string
+		IntValue      *int64
+		DoubleValue   *float64
+		BoolValue     *bool
+		DatetimeValue *time.Time
+	}
+
+	// VisibilitySearchAttributesFilter contains the column names within executions_visibility_search_attributes
+	// table that can be used to filter results through a WHERE clause
+	VisibilitySearchAttributesFilter struct {
+		DomainID string
+		RunID    string
+	}
+
+	// VisibilityQueryFilter contains a condition rendered from a visibility query that
+	// is applied to the executions_visibility table of a domain
+	VisibilityQueryFilter struct {
+		DomainID string
+		// Condition is a boolean SQL expression using ? placeholders, empty means no condition
+		Condition string
+		Args      []interface{}
+		// OrderBy is the SQL ordering of the result, e.g. "start_time DESC, run_id DESC"
+		OrderBy  string
+		Offset   int
+		PageSize int
+	}
+
 	// VisibilityFilter contains the column names within executions_visibility table that
 	// can be used to filter results through a WHERE clause
 	VisibilityFilter struct {
@@ -785,6 +826,17 @@ type (
 		//     - workflowID, workflowTypeName, closeStatus (along with closed=true)
 		SelectFromVisibility(ctx context.Context, filter *VisibilityFilter) ([]VisibilityRow, error)
 		DeleteFromVisibility(ctx context.Context, filter *VisibilityFilter) (sql.Result, error)
+		// SelectFromVisibilityByQuery returns the rows of visibility table that match the query condition
+		// Required filter params - {domainID, orderBy, pageSize}
+		SelectFromVisibilityByQuery(ctx context.Context, filter *VisibilityQueryFilter) ([]VisibilityRow, error)
+		// CountFromVisibilityByQuery returns the number of rows of visibility table that match the query condition
+		// Required filter params - {domainID}
+		CountFromVisibilityByQuery(ctx context.Context, filter *VisibilityQueryFilter) (int64, error)
+
+		InsertIntoVisibilitySearchAttributes(ctx context.Context, rows []VisibilitySearchAttributeRow) (sql.Result, error)
+		// DeleteFromVisibilitySearchAttributes deletes all the search attribute rows of a run
+		// Required filter params - {domainID, runID}
+		DeleteFromVisibilitySearchAttributes(ctx context.Context, filter *VisibilitySearchAttributesFilter) (sql.Result, error)
 
 		InsertIntoQueue(ctx context.Context, row *QueueRow) (sql.Result, error)
 		GetLastEnqueuedMessageIDForUpdate(ctx context.Context, queueType persistence.QueueType) (int64, error)
@@ -838,6 +890,21 @@ type (
 		Close() error
 	}
 
+	// PartitionAdmin manages the partitions of the history_node and executions tables created by the
+	// partitioned schema, where every partition covers a time bucket. It's implemented by the DB of the
+	// plugins supporting the partitioned schema
+	PartitionAdmin interface {
+		// SelectPartitionBuckets returns the buckets of the partitions of the table in ascending order
+		SelectPartitionBuckets(ctx context.Context, dbShardID int, table string) ([]int64, error)
+		// CreatePartition creates the partition of the bucket, which must be larger than the bucket of any existing partition
+		CreatePartition(ctx context.Context, dbShardID int, table string, bucket int64) error
+		// IsPartitionInUse returns whether the partition still has rows in use, i.e. any execution,
+		// or any history node of a history tree which is not deleted
+		IsPartitionInUse(ctx context.Context, dbShardID int, table string, bucket int64) (bool, error)
+		// DropPartition drops the partition of the bucket with all its rows
+		DropPartition(ctx context.Context, dbShardID int, table string, bucket int64) error
+	}
+
 	// AdminDB defines the API for admin SQL operations for CLI and testing suites
 	AdminDB interface {
 		adminCRUD
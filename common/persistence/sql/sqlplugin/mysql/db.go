@@ -38,7 +38,10 @@ type (
 		converter   DataConverter
 		driver      sqldriver.Driver
 		originalDBs []*sqlx.DB
+		replicas    *sqldriver.Replicas
 		numDBShards int
+		// partitionBucketSize is the bucket size of the partitioned schema, zero if the schema is not partitioned
+		partitionBucketSize time.Duration
 	}
 )
 
@@ -99,8 +102,15 @@ func (mdb *db) IsThrottlingError(err error) bool {
 // newDB returns an instance of DB, which is a logical
 // connection to the underlying mysql database
 // dbShardID is needed when tx is not nil
-func newDB(xdbs []*sqlx.DB, tx *sqlx.Tx, dbShardID int, numDBShards int) (*db, error) {
-	driver, err := sqldriver.NewDriver(xdbs, tx, dbShardID)
+func newDB(
+	xdbs []*sqlx.DB,
+	replicas *sqldriver.Replicas,
+	tx *sqlx.Tx,
+	dbShardID int,
+	numDBShards int,
+	partitionBucketSize time.Duration,
+) (*db, error) {
+	driver, err := sqldriver.NewDriver(xdbs, replicas, tx, dbShardID)
 	if err != nil {
 		return nil, err
 	}
@@ -108,8 +118,11 @@ func newDB(xdbs []*sqlx.DB, tx *sqlx.Tx, dbShardID int, numDBShards int) (*db, e
 	db := &db{
 		converter:   &converter{},
 		originalDBs: xdbs, // this is kept because newDB will be called again when starting a transaction
+		replicas:    replicas,
 		driver:      driver,
 		numDBShards: numDBShards,
+
+		partitionBucketSize: partitionBucketSize,
 	}
 
 	return db, nil
@@ -121,7 +134,7 @@ func (mdb *db) BeginTx(dbShardID int, ctx context.Context) (sqlplugin.Tx, error)
 	if err != nil {
 		return nil, err
 	}
-	return newDB(mdb.originalDBs, xtx, dbShardID, mdb.numDBShards)
+	return newDB(mdb.originalDBs, mdb.replicas, xtx, dbShardID, mdb.numDBShards, mdb.partitionBucketSize)
 }
 
 // Commit commits a previously started transaction
@@ -33,11 +33,22 @@ const (
 		`shard_id, tree_id, branch_id, node_id, txn_id, data, data_encoding) ` +
 		`VALUES (:shard_id, :tree_id, :branch_id, :node_id, :txn_id, :data, :data_encoding) `
 
+	addPartitionedHistoryNodesQuery = `INSERT INTO history_node (` +
+		`shard_id, tree_id, branch_id, node_id, txn_id, data, data_encoding, bucket) ` +
+		`VALUES (:shard_id, :tree_id, :branch_id, :node_id, :txn_id, :data, :data_encoding, :bucket) `
+
 	getHistoryNodesQuery = `SELECT node_id, txn_id, data, data_encoding FROM history_node ` +
 		`WHERE shard_id = ? AND tree_id = ? AND branch_id = ? AND node_id >= ? and node_id < ? ORDER BY shard_id, tree_id, branch_id, node_id, txn_id LIMIT ? `
 
+	getLatestHistoryNodeQuery = `SELECT node_id, txn_id FROM history_node ` +
+		`WHERE shard_id = ? AND tree_id = ? AND branch_id = ? AND node_id >= ? and node_id < ? ORDER BY shard_id, tree_id, branch_id, node_id DESC, txn_id LIMIT 1 `
+
 	deleteHistoryNodesQuery = `DELETE FROM history_node WHERE shard_id = ? AND tree_id = ? AND branch_id = ? AND node_id >= ? ORDER BY shard_id, tree_id, branch_id, node_id, txn_id LIMIT ? `
 
+	// with the partitioned schema only the legacy partition is cleaned up row by row,
+	// the time bucketed partitions are dropped as a whole by the partition retention job
+	deletePartitionedHistoryNodesQuery = `DELETE FROM history_node PARTITION (p_legacy) WHERE shard_id = ? AND tree_id = ? AND branch_id = ? AND node_id >= ? ORDER BY shard_id, tree_id, branch_id, node_id, txn_id LIMIT ? `
+
 	// below are templates for history_tree table
 	addHistoryTreeQuery = `INSERT INTO history_tree (` +
 		`shard_id, tree_id, branch_id, data, data_encoding) ` +
@@ -57,6 +68,10 @@ func (mdb *db) InsertIntoHistoryNode(ctx context.Context, row *sqlplugin.History
 	// NOTE: Query 5.6 doesn't support clustering order, to workaround, we let txn_id multiple by -1
 	*row.TxnID *= -1
 	dbShardID := sqlplugin.GetDBShardIDFromTreeID(row.TreeID, mdb.GetTotalNumDBShards())
+	if mdb.partitionBucketSize > 0 {
+		row.Bucket = sqlplugin.GetHistoryBranchPartitionBucket(row.BranchID, mdb.partitionBucketSize)
+		return mdb.driver.NamedExecContext(ctx, dbShardID, addPartitionedHistoryNodesQuery, row)
+	}
 	return mdb.driver.NamedExecContext(ctx, dbShardID, addHistoryNodesQuery, row)
 }
 
@@ -64,8 +79,20 @@ func (mdb *db) InsertIntoHistoryNode(ctx context.Context, row *sqlplugin.History
 func (mdb *db) SelectFromHistoryNode(ctx context.Context, filter *sqlplugin.HistoryNodeFilter) ([]sqlplugin.HistoryNodeRow, error) {
 	var rows []sqlplugin.HistoryNodeRow
 	dbShardID := sqlplugin.GetDBShardIDFromTreeID(filter.TreeID, mdb.GetTotalNumDBShards())
-	err := mdb.driver.SelectContext(ctx, dbShardID, &rows, getHistoryNodesQuery,
+	err := mdb.driver.SelectReplicaContext(ctx, dbShardID, &rows, getHistoryNodesQuery,
 		filter.ShardID, filter.TreeID, filter.BranchID, *filter.MinNodeID, *filter.MaxNodeID, filter.PageSize)
+	if err == nil && mdb.replicas != nil && len(rows) < filter.PageSize {
+		// the last page may be read from a replica which doesn't have the latest write to the branch yet,
+		// either new nodes or a newer transaction of the last node, if so the page is read again from the primary
+		var latest []sqlplugin.HistoryNodeRow
+		err = mdb.driver.SelectContext(ctx, dbShardID, &latest, getLatestHistoryNodeQuery,
+			filter.ShardID, filter.TreeID, filter.BranchID, *filter.MinNodeID, *filter.MaxNodeID)
+		if err == nil && len(latest) > 0 && !containsHistoryNode(rows, latest[0].NodeID, *latest[0].TxnID) {
+			rows = nil
+			err = mdb.driver.SelectContext(ctx, dbShardID, &rows, getHistoryNodesQuery,
+				filter.ShardID, filter.TreeID, filter.BranchID, *filter.MinNodeID, *filter.MaxNodeID, filter.PageSize)
+		}
+	}
 	// NOTE: since we let txn_id multiple by -1 when inserting, we have to revert it back here
 	for _, row := range rows {
 		*row.TxnID *= -1
@@ -73,10 +100,25 @@ func (mdb *db) SelectFromHistoryNode(ctx context.Context, filter *sqlplugin.Hist
 	return rows, err
 }
 
+// containsHistoryNode returns true if the rows contain the transaction of the node,
+// the transaction ID is compared as stored in the database
+func containsHistoryNode(rows []sqlplugin.HistoryNodeRow, nodeID int64, txnID int64) bool {
+	for _, row := range rows {
+		if row.NodeID == nodeID && *row.TxnID == txnID {
+			return true
+		}
+	}
+	return false
+}
+
 // DeleteFromHistoryNode deletes one or more rows from history_node table
 func (mdb *db) DeleteFromHistoryNode(ctx context.Context, filter *sqlplugin.HistoryNodeFilter) (sql.Result, error) {
 	dbShardID := sqlplugin.GetDBShardIDFromTreeID(filter.TreeID, mdb.GetTotalNumDBShards())
-	return mdb.driver.ExecContext(ctx, dbShardID, deleteHistoryNodesQuery, filter.ShardID, filter.TreeID, filter.BranchID, *filter.MinNodeID, filter.PageSize)
+	query := deleteHistoryNodesQuery
+	if mdb.partitionBucketSize > 0 {
+		query = deletePartitionedHistoryNodesQuery
+	}
+	return mdb.driver.ExecContext(ctx, dbShardID, query, filter.ShardID, filter.TreeID, filter.BranchID, *filter.MinNodeID, filter.PageSize)
 }
 
 // For history_tree table:
@@ -33,6 +33,9 @@ const (
 	createExecutionQuery = `INSERT INTO executions(` + executionsColumns + `)
  VALUES(:shard_id, :domain_id, :workflow_id, :run_id, :next_event_id, :last_write_version, :data, :data_encoding)`
 
+	createPartitionedExecutionQuery = `INSERT INTO executions(` + executionsColumns + `, bucket)
+ VALUES(:shard_id, :domain_id, :workflow_id, :run_id, :next_event_id, :last_write_version, :data, :data_encoding, :bucket)`
+
 	updateExecutionQuery = `UPDATE executions SET
  next_event_id = :next_event_id, last_write_version = :last_write_version, data = :data, data_encoding = :data_encoding
  WHERE shard_id = :shard_id AND domain_id = :domain_id AND workflow_id = :workflow_id AND run_id = :run_id`
@@ -179,6 +182,10 @@ VALUES     (:source_cluster_name,
 // InsertIntoExecutions inserts a row into executions table
 func (mdb *db) InsertIntoExecutions(ctx context.Context, row *sqlplugin.ExecutionsRow) (sql.Result, error) {
 	dbShardID := sqlplugin.GetDBShardIDFromHistoryShardID(row.ShardID, mdb.GetTotalNumDBShards())
+	if mdb.partitionBucketSize > 0 {
+		row.Bucket = sqlplugin.GetPartitionBucket(row.StartTime, mdb.partitionBucketSize)
+		return mdb.driver.NamedExecContext(ctx, dbShardID, createPartitionedExecutionQuery, row)
+	}
 	return mdb.driver.NamedExecContext(ctx, dbShardID, createExecutionQuery, row)
 }
 
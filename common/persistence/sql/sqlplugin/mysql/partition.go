@@ -0,0 +1,120 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/uber/cadence/common/persistence/sql/sqlplugin"
+)
+
+const (
+	// partitions of the partitioned schema are named b<bucket>, except for p_legacy and p_max,
+	// see schema/mysql/v57/cadence/partitioned
+	partitionNamePrefix = "b"
+
+	selectPartitionNamesQuery = `SELECT partition_name FROM information_schema.partitions ` +
+		`WHERE table_schema = DATABASE() AND table_name = ? AND partition_name LIKE 'b%'`
+
+	// NOTE table and partition names can't be bind parameters, they are validated before formatted into the queries
+	createPartitionQuery = `ALTER TABLE %v REORGANIZE PARTITION p_max INTO ` +
+		`(PARTITION %v VALUES LESS THAN (%v), PARTITION p_max VALUES LESS THAN MAXVALUE)`
+
+	dropPartitionQuery = `ALTER TABLE %v DROP PARTITION %v`
+
+	historyNodePartitionInUseQuery = `SELECT 1 FROM history_node PARTITION (%v) n WHERE EXISTS ` +
+		`(SELECT 1 FROM history_tree t WHERE t.shard_id = n.shard_id AND t.tree_id = n.tree_id) LIMIT 1`
+
+	executionsPartitionInUseQuery = `SELECT 1 FROM executions PARTITION (%v) LIMIT 1`
+)
+
+var _ sqlplugin.PartitionAdmin = (*db)(nil)
+
+func partitionName(bucket int64) string {
+	return partitionNamePrefix + strconv.FormatInt(bucket, 10)
+}
+
+// SelectPartitionBuckets returns the buckets of the partitions of the table in ascending order
+func (mdb *db) SelectPartitionBuckets(ctx context.Context, dbShardID int, table string) ([]int64, error) {
+	if err := sqlplugin.ValidatePartitionedTable(table); err != nil {
+		return nil, err
+	}
+	var names []string
+	if err := mdb.driver.SelectContext(ctx, dbShardID, &names, selectPartitionNamesQuery, table); err != nil {
+		return nil, err
+	}
+	var buckets []int64
+	for _, name := range names {
+		bucket, err := strconv.ParseInt(strings.TrimPrefix(name, partitionNamePrefix), 10, 64)
+		if err != nil {
+			continue
+		}
+		buckets = append(buckets, bucket)
+	}
+	sort.Slice(buckets, func(i, j int) bool { return buckets[i] < buckets[j] })
+	return buckets, nil
+}
+
+// CreatePartition creates the partition of the bucket by splitting it from the p_max partition
+func (mdb *db) CreatePartition(ctx context.Context, dbShardID int, table string, bucket int64) error {
+	if err := sqlplugin.ValidatePartitionedTable(table); err != nil {
+		return err
+	}
+	_, err := mdb.driver.ExecContext(ctx, dbShardID, fmt.Sprintf(createPartitionQuery, table, partitionName(bucket), bucket+1))
+	return err
+}
+
+// IsPartitionInUse returns whether the partition still has rows in use
+func (mdb *db) IsPartitionInUse(ctx context.Context, dbShardID int, table string, bucket int64) (bool, error) {
+	if err := sqlplugin.ValidatePartitionedTable(table); err != nil {
+		return false, err
+	}
+	query := executionsPartitionInUseQuery
+	if table == sqlplugin.PartitionedTableHistoryNode {
+		query = historyNodePartitionInUseQuery
+	}
+	var inUse int
+	err := mdb.driver.GetContext(ctx, dbShardID, &inUse, fmt.Sprintf(query, partitionName(bucket)))
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// DropPartition drops the partition of the bucket with all its rows
+func (mdb *db) DropPartition(ctx context.Context, dbShardID int, table string, bucket int64) error {
+	if err := sqlplugin.ValidatePartitionedTable(table); err != nil {
+		return err
+	}
+	if bucket == sqlplugin.LegacyPartitionBucket {
+		return fmt.Errorf("partition of the legacy bucket of table %v can't be dropped", table)
+	}
+	_, err := mdb.driver.ExecContext(ctx, dbShardID, fmt.Sprintf(dropPartitionQuery, table, partitionName(bucket)))
+	return err
+}
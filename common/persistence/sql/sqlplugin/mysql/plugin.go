@@ -22,19 +22,21 @@ package mysql
 
 import (
 	"bytes"
-	"crypto/tls"
-	"crypto/x509"
+	"context"
 	"fmt"
-	"io/ioutil"
 	"net"
 	"net/url"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/go-sql-driver/mysql"
 	"github.com/iancoleman/strcase"
 	"github.com/jmoiron/sqlx"
 
+	"github.com/uber/cadence/common/certificate"
 	"github.com/uber/cadence/common/config"
+	"github.com/uber/cadence/common/log"
 	pt "github.com/uber/cadence/common/persistence/persistence-tests"
 	"github.com/uber/cadence/common/persistence/sql"
 	"github.com/uber/cadence/common/persistence/sql/sqldriver"
@@ -75,7 +77,20 @@ func (p *plugin) CreateDB(cfg *config.SQL) (sqlplugin.DB, error) {
 	if err != nil {
 		return nil, err
 	}
-	return newDB(conns, nil, sqlplugin.DbShardUndefined, cfg.NumShards)
+	replicas, err := sqldriver.CreateReplicaConnections(cfg, func(cfg *config.SQL) (*sqlx.DB, error) {
+		return p.createSingleDBConn(cfg)
+	}, p.replicationLag)
+	if err != nil {
+		for _, conn := range conns {
+			conn.Close()
+		}
+		return nil, err
+	}
+	var partitionBucketSize time.Duration
+	if cfg.Partitioning != nil {
+		partitionBucketSize = cfg.Partitioning.BucketSize
+	}
+	return newDB(conns, replicas, nil, sqlplugin.DbShardUndefined, cfg.NumShards, partitionBucketSize)
 }
 
 // CreateAdminDB initialize the adminDb object
@@ -86,7 +101,7 @@ func (p *plugin) CreateAdminDB(cfg *config.SQL) (sqlplugin.AdminDB, error) {
 	if err != nil {
 		return nil, err
 	}
-	return newDB(conns, nil, sqlplugin.DbShardUndefined, cfg.NumShards)
+	return newDB(conns, nil, nil, sqlplugin.DbShardUndefined, cfg.NumShards, 0)
 }
 
 func (p *plugin) createSingleDBConn(cfg *config.SQL) (*sqlx.DB, error) {
@@ -125,35 +140,12 @@ func registerTLSConfig(cfg *config.SQL) error {
 	}
 
 	// TODO: create a way to set MinVersion and CipherSuites via cfg.
-	tlsConfig := &tls.Config{
-		ServerName:         host,
-		InsecureSkipVerify: !cfg.TLS.EnableHostVerification,
-	}
-
-	if cfg.TLS.CaFile != "" {
-		rootCertPool := x509.NewCertPool()
-		pem, err := ioutil.ReadFile(cfg.TLS.CaFile)
-		if err != nil {
-			return fmt.Errorf("failed to load CA files: %v", err)
-		}
-		if ok := rootCertPool.AppendCertsFromPEM(pem); !ok {
-			return fmt.Errorf("failed to append CA file")
-		}
-		tlsConfig.RootCAs = rootCertPool
-	}
-
-	if cfg.TLS.CertFile != "" && cfg.TLS.KeyFile != "" {
-		clientCert := make([]tls.Certificate, 0, 1)
-		certs, err := tls.LoadX509KeyPair(
-			cfg.TLS.CertFile,
-			cfg.TLS.KeyFile,
-		)
-		if err != nil {
-			return fmt.Errorf("failed to load tls x509 key pair: %v", err)
-		}
-		clientCert = append(clientCert, certs)
-		tlsConfig.Certificates = clientCert
+	// the certificate, key and CA files are reloaded for the new connections when they change
+	tlsReloader, err := certificate.NewReloader(*cfg.TLS, log.NewNoop())
+	if err != nil {
+		return fmt.Errorf("failed to load TLS files: %v", err)
 	}
+	tlsConfig := tlsReloader.ClientTLSConfig(host)
 
 	// In order to use the TLS configuration you need to register it. Once registered you use it by specifying
 	// `tls` in the connect attributes.
@@ -179,6 +171,24 @@ func registerTLSConfig(cfg *config.SQL) error {
 	return nil
 }
 
+// replicationLag returns how much a mysql replica is lagging behind the source
+func (p *plugin) replicationLag(ctx context.Context, db *sqlx.DB) (time.Duration, error) {
+	status := make(map[string]interface{})
+	if err := db.QueryRowxContext(ctx, "SHOW SLAVE STATUS").MapScan(status); err != nil {
+		return 0, err
+	}
+	secondsBehind, ok := status["Seconds_Behind_Master"].([]byte)
+	if !ok {
+		// it's NULL when the replication is not running
+		return 0, fmt.Errorf("replication is not running")
+	}
+	lagSeconds, err := strconv.ParseInt(string(secondsBehind), 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(lagSeconds) * time.Second, nil
+}
+
 func buildDSN(cfg *config.SQL) string {
 	attrs := buildDSNAttrs(cfg)
 	dsn := fmt.Sprintf(dsnFmt, cfg.User, cfg.Password, cfg.ConnectProtocol, cfg.ConnectAddr, cfg.DatabaseName)
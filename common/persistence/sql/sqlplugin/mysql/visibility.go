@@ -25,18 +25,19 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/uber/cadence/common/persistence/sql/sqlplugin"
 )
 
 const (
 	templateCreateWorkflowExecutionStarted = `INSERT IGNORE INTO executions_visibility (` +
-		`domain_id, workflow_id, run_id, start_time, execution_time, workflow_type_name, memo, encoding, is_cron, num_clusters) ` +
-		`VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+		`domain_id, workflow_id, run_id, start_time, execution_time, workflow_type_name, memo, encoding, task_list, is_cron, num_clusters) ` +
+		`VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
 
 	templateCreateWorkflowExecutionClosed = `REPLACE INTO executions_visibility (` +
-		`domain_id, workflow_id, run_id, start_time, execution_time, workflow_type_name, close_time, close_status, history_length, memo, encoding, is_cron, num_clusters) ` +
-		`VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+		`domain_id, workflow_id, run_id, start_time, execution_time, workflow_type_name, close_time, close_status, history_length, memo, encoding, task_list, is_cron, num_clusters) ` +
+		`VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
 
 	// RunID condition is needed for correct pagination
 	templateConditions = ` AND domain_id = ?
@@ -72,6 +73,18 @@ const (
 		 AND run_id = ?`
 
 	templateDeleteWorkflowExecution = "DELETE FROM executions_visibility WHERE domain_id=? AND run_id=?"
+
+	templateQueryFieldNames = templateOpenFieldNames + `, close_time, close_status, history_length, task_list, COALESCE(num_clusters, 0) AS num_clusters`
+
+	templateGetWorkflowExecutionsByQuery = `SELECT ` + templateQueryFieldNames + ` FROM executions_visibility WHERE domain_id = ?`
+
+	templateCountWorkflowExecutionsByQuery = `SELECT COUNT(*) FROM executions_visibility WHERE domain_id = ?`
+
+	templateCreateSearchAttributes = `INSERT INTO executions_visibility_search_attributes (` +
+		`domain_id, run_id, name, value_index, string_value, int_value, double_value, bool_value, datetime_value) ` +
+		`VALUES (:domain_id, :run_id, :name, :value_index, :string_value, :int_value, :double_value, :bool_value, :datetime_value)`
+
+	templateDeleteSearchAttributes = "DELETE FROM executions_visibility_search_attributes WHERE domain_id=? AND run_id=?"
 )
 
 var errCloseParams = errors.New("missing one of {closeStatus, closeTime, historyLength} params")
@@ -92,6 +105,7 @@ func (mdb *db) InsertIntoVisibility(ctx context.Context, row *sqlplugin.Visibili
 		row.WorkflowTypeName,
 		row.Memo,
 		row.Encoding,
+		row.TaskList,
 		row.IsCron,
 		row.NumClusters)
 }
@@ -117,6 +131,7 @@ func (mdb *db) ReplaceIntoVisibility(ctx context.Context, row *sqlplugin.Visibil
 			*row.HistoryLength,
 			row.Memo,
 			row.Encoding,
+			row.TaskList,
 			row.IsCron,
 			row.NumClusters)
 	default:
@@ -144,7 +159,7 @@ func (mdb *db) SelectFromVisibility(ctx context.Context, filter *sqlplugin.Visib
 	switch {
 	case filter.MinStartTime == nil && filter.RunID != nil && filter.Closed:
 		var row sqlplugin.VisibilityRow
-		err = mdb.driver.GetContext(ctx, dbShardID, &row, templateGetClosedWorkflowExecution, filter.DomainID, *filter.RunID)
+		err = mdb.driver.GetReplicaContext(ctx, dbShardID, &row, templateGetClosedWorkflowExecution, filter.DomainID, *filter.RunID)
 		if err == nil {
 			rows = append(rows, row)
 		}
@@ -153,7 +168,7 @@ func (mdb *db) SelectFromVisibility(ctx context.Context, filter *sqlplugin.Visib
 		if filter.Closed {
 			qry = templateGetClosedWorkflowExecutionsByID
 		}
-		err = mdb.driver.SelectContext(ctx,
+		err = mdb.driver.SelectReplicaContext(ctx,
 			dbShardID,
 			&rows,
 			qry,
@@ -169,7 +184,7 @@ func (mdb *db) SelectFromVisibility(ctx context.Context, filter *sqlplugin.Visib
 		if filter.Closed {
 			qry = templateGetClosedWorkflowExecutionsByType
 		}
-		err = mdb.driver.SelectContext(ctx,
+		err = mdb.driver.SelectReplicaContext(ctx,
 			dbShardID,
 			&rows,
 			qry,
@@ -181,7 +196,7 @@ func (mdb *db) SelectFromVisibility(ctx context.Context, filter *sqlplugin.Visib
 			*filter.MaxStartTime,
 			*filter.PageSize)
 	case filter.MinStartTime != nil && filter.CloseStatus != nil:
-		err = mdb.driver.SelectContext(ctx,
+		err = mdb.driver.SelectReplicaContext(ctx,
 			dbShardID,
 			&rows,
 			templateGetClosedWorkflowExecutionsByStatus,
@@ -197,7 +212,7 @@ func (mdb *db) SelectFromVisibility(ctx context.Context, filter *sqlplugin.Visib
 		if filter.Closed {
 			qry = templateGetClosedWorkflowExecutions
 		}
-		err = mdb.driver.SelectContext(ctx,
+		err = mdb.driver.SelectReplicaContext(ctx,
 			dbShardID,
 			&rows,
 			qry,
@@ -223,3 +238,70 @@ func (mdb *db) SelectFromVisibility(ctx context.Context, filter *sqlplugin.Visib
 	}
 	return rows, err
 }
+
+// SelectFromVisibilityByQuery reads the rows of visibility table that match the query condition
+func (mdb *db) SelectFromVisibilityByQuery(ctx context.Context, filter *sqlplugin.VisibilityQueryFilter) ([]sqlplugin.VisibilityRow, error) {
+	dbShardID := sqlplugin.GetDBShardIDFromDomainID(filter.DomainID, mdb.GetTotalNumDBShards())
+	query, args := mdb.makeVisibilityQuery(templateGetWorkflowExecutionsByQuery, filter)
+	query += ` ORDER BY ` + filter.OrderBy + ` LIMIT ? OFFSET ?`
+	args = append(args, filter.PageSize, filter.Offset)
+	var rows []sqlplugin.VisibilityRow
+	if err := mdb.driver.SelectReplicaContext(ctx, dbShardID, &rows, query, args...); err != nil {
+		return nil, err
+	}
+	for i := range rows {
+		rows[i].StartTime = mdb.converter.FromMySQLDateTime(rows[i].StartTime)
+		rows[i].ExecutionTime = mdb.converter.FromMySQLDateTime(rows[i].ExecutionTime)
+		if rows[i].CloseTime != nil {
+			closeTime := mdb.converter.FromMySQLDateTime(*rows[i].CloseTime)
+			rows[i].CloseTime = &closeTime
+		}
+	}
+	return rows, nil
+}
+
+// CountFromVisibilityByQuery counts the rows of visibility table that match the query condition
+func (mdb *db) CountFromVisibilityByQuery(ctx context.Context, filter *sqlplugin.VisibilityQueryFilter) (int64, error) {
+	dbShardID := sqlplugin.GetDBShardIDFromDomainID(filter.DomainID, mdb.GetTotalNumDBShards())
+	query, args := mdb.makeVisibilityQuery(templateCountWorkflowExecutionsByQuery, filter)
+	var count int64
+	err := mdb.driver.GetReplicaContext(ctx, dbShardID, &count, query, args...)
+	return count, err
+}
+
+// InsertIntoVisibilitySearchAttributes inserts one or more rows into executions_visibility_search_attributes table
+func (mdb *db) InsertIntoVisibilitySearchAttributes(ctx context.Context, rows []sqlplugin.VisibilitySearchAttributeRow) (sql.Result, error) {
+	if len(rows) == 0 {
+		return nil, nil
+	}
+	dbShardID := sqlplugin.GetDBShardIDFromDomainID(rows[0].DomainID, mdb.GetTotalNumDBShards())
+	for i := range rows {
+		if rows[i].DatetimeValue != nil {
+			datetime := mdb.converter.ToMySQLDateTime(*rows[i].DatetimeValue)
+			rows[i].DatetimeValue = &datetime
+		}
+	}
+	return mdb.driver.NamedExecContext(ctx, dbShardID, templateCreateSearchAttributes, rows)
+}
+
+// DeleteFromVisibilitySearchAttributes deletes all the search attribute rows of a run
+func (mdb *db) DeleteFromVisibilitySearchAttributes(ctx context.Context, filter *sqlplugin.VisibilitySearchAttributesFilter) (sql.Result, error) {
+	dbShardID := sqlplugin.GetDBShardIDFromDomainID(filter.DomainID, mdb.GetTotalNumDBShards())
+	return mdb.driver.ExecContext(ctx, dbShardID, templateDeleteSearchAttributes, filter.DomainID, filter.RunID)
+}
+
+// makeVisibilityQuery appends the query condition to the template and converts the condition arguments
+func (mdb *db) makeVisibilityQuery(template string, filter *sqlplugin.VisibilityQueryFilter) (string, []interface{}) {
+	query := template
+	if filter.Condition != "" {
+		query += ` AND (` + filter.Condition + `)`
+	}
+	args := []interface{}{filter.DomainID}
+	for _, arg := range filter.Args {
+		if t, ok := arg.(time.Time); ok {
+			arg = mdb.converter.ToMySQLDateTime(t)
+		}
+		args = append(args, arg)
+	}
+	return query, args
+}
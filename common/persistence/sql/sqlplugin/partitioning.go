@@ -0,0 +1,80 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package sqlplugin
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/pborman/uuid"
+
+	"github.com/uber/cadence/common/persistence/serialization"
+)
+
+const (
+	// PartitionedTableHistoryNode is the history_node table in the partitioned schema, partitioned by the creation time of history branches
+	PartitionedTableHistoryNode = "history_node"
+	// PartitionedTableExecutions is the executions table in the partitioned schema, partitioned by the start time of workflows
+	PartitionedTableExecutions = "executions"
+
+	// LegacyPartitionBucket is the bucket of the history nodes of branches whose creation time is unknown
+	// The partition of this bucket is never dropped, its rows are deleted one by one instead
+	LegacyPartitionBucket = int64(0)
+)
+
+// PartitionedTables is the list of the tables partitioned by the partitioned schema
+var PartitionedTables = []string{PartitionedTableHistoryNode, PartitionedTableExecutions}
+
+// GetPartitionBucket returns the bucket of the partition covering the time
+func GetPartitionBucket(t time.Time, bucketSize time.Duration) int64 {
+	return t.Unix() / int64(bucketSize/time.Second)
+}
+
+// GetPartitionBucketStartTime returns the start time of the range covered by the partition of the bucket
+func GetPartitionBucketStartTime(bucket int64, bucketSize time.Duration) time.Time {
+	return time.Unix(bucket*int64(bucketSize/time.Second), 0)
+}
+
+// GetHistoryBranchPartitionBucket returns the bucket of the partition of the history nodes of a branch.
+// It's the bucket of the creation time of the branch, which is encoded in its time based(version 1) UUID.
+// LegacyPartitionBucket is returned for branches with a random UUID.
+func GetHistoryBranchPartitionBucket(branchID serialization.UUID, bucketSize time.Duration) int64 {
+	id := uuid.UUID(branchID)
+	if version, ok := id.Version(); !ok || version != 1 {
+		return LegacyPartitionBucket
+	}
+	uuidTime, ok := id.Time()
+	if !ok {
+		return LegacyPartitionBucket
+	}
+	sec, nsec := uuidTime.UnixTime()
+	return GetPartitionBucket(time.Unix(sec, nsec), bucketSize)
+}
+
+// ValidatePartitionedTable returns an error if the table is not partitioned by the partitioned schema
+func ValidatePartitionedTable(table string) error {
+	for _, partitionedTable := range PartitionedTables {
+		if table == partitionedTable {
+			return nil
+		}
+	}
+	return fmt.Errorf("table %v is not partitioned", table)
+}
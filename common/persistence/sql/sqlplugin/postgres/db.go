@@ -37,7 +37,10 @@ type (
 		converter   DataConverter
 		driver      sqldriver.Driver
 		originalDBs []*sqlx.DB
+		replicas    *sqldriver.Replicas
 		numDBShards int
+		// partitionBucketSize is the bucket size of the partitioned schema, zero if the schema is not partitioned
+		partitionBucketSize time.Duration
 	}
 )
 
@@ -88,8 +91,15 @@ func (pdb *db) IsThrottlingError(err error) bool {
 // newDB returns an instance of DB, which is a logical
 // connection to the underlying postgres database
 // dbShardID is needed when tx is not nil
-func newDB(xdbs []*sqlx.DB, tx *sqlx.Tx, dbShardID int, numDBShards int) (*db, error) {
-	driver, err := sqldriver.NewDriver(xdbs, tx, dbShardID)
+func newDB(
+	xdbs []*sqlx.DB,
+	replicas *sqldriver.Replicas,
+	tx *sqlx.Tx,
+	dbShardID int,
+	numDBShards int,
+	partitionBucketSize time.Duration,
+) (*db, error) {
+	driver, err := sqldriver.NewDriver(xdbs, replicas, tx, dbShardID)
 	if err != nil {
 		return nil, err
 	}
@@ -97,8 +107,11 @@ func newDB(xdbs []*sqlx.DB, tx *sqlx.Tx, dbShardID int, numDBShards int) (*db, e
 	db := &db{
 		converter:   &converter{},
 		originalDBs: xdbs, // this is kept because newDB will be called again when starting a transaction
+		replicas:    replicas,
 		driver:      driver,
 		numDBShards: numDBShards,
+
+		partitionBucketSize: partitionBucketSize,
 	}
 	return db, nil
 }
@@ -109,7 +122,7 @@ func (pdb *db) BeginTx(dbShardID int, ctx context.Context) (sqlplugin.Tx, error)
 	if err != nil {
 		return nil, err
 	}
-	return newDB(pdb.originalDBs, xtx, dbShardID, pdb.numDBShards)
+	return newDB(pdb.originalDBs, pdb.replicas, xtx, dbShardID, pdb.numDBShards, pdb.partitionBucketSize)
 }
 
 // Commit commits a previously started transaction
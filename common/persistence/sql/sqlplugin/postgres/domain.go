@@ -101,9 +101,9 @@ func (pdb *db) selectAllFromDomain(ctx context.Context, filter *sqlplugin.Domain
 	var rows []sqlplugin.DomainRow
 	switch {
 	case filter.GreaterThanID != nil:
-		err = pdb.driver.SelectContext(ctx, sqlplugin.DbDefaultShard, &rows, listDomainsRangeQuery, shardID, *filter.GreaterThanID, *filter.PageSize)
+		err = pdb.driver.SelectReplicaContext(ctx, sqlplugin.DbDefaultShard, &rows, listDomainsRangeQuery, shardID, *filter.GreaterThanID, *filter.PageSize)
 	default:
-		err = pdb.driver.SelectContext(ctx, sqlplugin.DbDefaultShard, &rows, listDomainsQuery, shardID, filter.PageSize)
+		err = pdb.driver.SelectReplicaContext(ctx, sqlplugin.DbDefaultShard, &rows, listDomainsQuery, shardID, filter.PageSize)
 	}
 	return rows, err
 }
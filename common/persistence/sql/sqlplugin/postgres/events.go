@@ -33,12 +33,24 @@ const (
 		`shard_id, tree_id, branch_id, node_id, txn_id, data, data_encoding) ` +
 		`VALUES (:shard_id, :tree_id, :branch_id, :node_id, :txn_id, :data, :data_encoding) `
 
+	addPartitionedHistoryNodesQuery = `INSERT INTO history_node (` +
+		`shard_id, tree_id, branch_id, node_id, txn_id, data, data_encoding, bucket) ` +
+		`VALUES (:shard_id, :tree_id, :branch_id, :node_id, :txn_id, :data, :data_encoding, :bucket) `
+
 	getHistoryNodesQuery = `SELECT node_id, txn_id, data, data_encoding FROM history_node ` +
 		`WHERE shard_id = $1 AND tree_id = $2 AND branch_id = $3 AND node_id >= $4 and node_id < $5 ORDER BY shard_id, tree_id, branch_id, node_id, txn_id LIMIT $6 `
 
+	getLatestHistoryNodeQuery = `SELECT node_id, txn_id FROM history_node ` +
+		`WHERE shard_id = $1 AND tree_id = $2 AND branch_id = $3 AND node_id >= $4 and node_id < $5 ORDER BY shard_id, tree_id, branch_id, node_id DESC, txn_id LIMIT 1 `
+
 	deleteHistoryNodesQuery = `DELETE FROM history_node WHERE shard_id = $1 AND tree_id = $2 AND branch_id = $3 AND (node_id,txn_id) IN (SELECT node_id,txn_id FROM
 		history_node WHERE shard_id = $1 AND tree_id = $2 AND branch_id = $3 AND node_id >= $4 LIMIT $5)`
 
+	// with the partitioned schema only the default partition is cleaned up row by row,
+	// the time bucketed partitions are dropped as a whole by the partition retention job
+	deletePartitionedHistoryNodesQuery = `DELETE FROM history_node_default WHERE shard_id = $1 AND tree_id = $2 AND branch_id = $3 AND (node_id,txn_id) IN (SELECT node_id,txn_id FROM
+		history_node_default WHERE shard_id = $1 AND tree_id = $2 AND branch_id = $3 AND node_id >= $4 LIMIT $5)`
+
 	// below are templates for history_tree table
 	addHistoryTreeQuery = `INSERT INTO history_tree (` +
 		`shard_id, tree_id, branch_id, data, data_encoding) ` +
@@ -58,6 +70,10 @@ func (pdb *db) InsertIntoHistoryNode(ctx context.Context, row *sqlplugin.History
 	dbShardID := sqlplugin.GetDBShardIDFromTreeID(row.TreeID, pdb.GetTotalNumDBShards())
 	// NOTE: Query 5.6 doesn't support clustering order, to workaround, we let txn_id multiple by -1
 	*row.TxnID *= -1
+	if pdb.partitionBucketSize > 0 {
+		row.Bucket = sqlplugin.GetHistoryBranchPartitionBucket(row.BranchID, pdb.partitionBucketSize)
+		return pdb.driver.NamedExecContext(ctx, dbShardID, addPartitionedHistoryNodesQuery, row)
+	}
 	return pdb.driver.NamedExecContext(ctx, dbShardID, addHistoryNodesQuery, row)
 }
 
@@ -65,8 +81,20 @@ func (pdb *db) InsertIntoHistoryNode(ctx context.Context, row *sqlplugin.History
 func (pdb *db) SelectFromHistoryNode(ctx context.Context, filter *sqlplugin.HistoryNodeFilter) ([]sqlplugin.HistoryNodeRow, error) {
 	dbShardID := sqlplugin.GetDBShardIDFromTreeID(filter.TreeID, pdb.GetTotalNumDBShards())
 	var rows []sqlplugin.HistoryNodeRow
-	err := pdb.driver.SelectContext(ctx, dbShardID, &rows, getHistoryNodesQuery,
+	err := pdb.driver.SelectReplicaContext(ctx, dbShardID, &rows, getHistoryNodesQuery,
 		filter.ShardID, filter.TreeID, filter.BranchID, *filter.MinNodeID, *filter.MaxNodeID, filter.PageSize)
+	if err == nil && pdb.replicas != nil && len(rows) < filter.PageSize {
+		// the last page may be read from a replica which doesn't have the latest write to the branch yet,
+		// either new nodes or a newer transaction of the last node, if so the page is read again from the primary
+		var latest []sqlplugin.HistoryNodeRow
+		err = pdb.driver.SelectContext(ctx, dbShardID, &latest, getLatestHistoryNodeQuery,
+			filter.ShardID, filter.TreeID, filter.BranchID, *filter.MinNodeID, *filter.MaxNodeID)
+		if err == nil && len(latest) > 0 && !containsHistoryNode(rows, latest[0].NodeID, *latest[0].TxnID) {
+			rows = nil
+			err = pdb.driver.SelectContext(ctx, dbShardID, &rows, getHistoryNodesQuery,
+				filter.ShardID, filter.TreeID, filter.BranchID, *filter.MinNodeID, *filter.MaxNodeID, filter.PageSize)
+		}
+	}
 	// NOTE: since we let txn_id multiple by -1 when inserting, we have to revert it back here
 	for _, row := range rows {
 		*row.TxnID *= -1
@@ -74,10 +102,25 @@ func (pdb *db) SelectFromHistoryNode(ctx context.Context, filter *sqlplugin.Hist
 	return rows, err
 }
 
+// containsHistoryNode returns true if the rows contain the transaction of the node,
+// the transaction ID is compared as stored in the database
+func containsHistoryNode(rows []sqlplugin.HistoryNodeRow, nodeID int64, txnID int64) bool {
+	for _, row := range rows {
+		if row.NodeID == nodeID && *row.TxnID == txnID {
+			return true
+		}
+	}
+	return false
+}
+
 // DeleteFromHistoryNode deletes one or more rows from history_node table
 func (pdb *db) DeleteFromHistoryNode(ctx context.Context, filter *sqlplugin.HistoryNodeFilter) (sql.Result, error) {
 	dbShardID := sqlplugin.GetDBShardIDFromTreeID(filter.TreeID, pdb.GetTotalNumDBShards())
-	return pdb.driver.ExecContext(ctx, dbShardID, deleteHistoryNodesQuery, filter.ShardID, filter.TreeID, filter.BranchID, *filter.MinNodeID, filter.PageSize)
+	query := deleteHistoryNodesQuery
+	if pdb.partitionBucketSize > 0 {
+		query = deletePartitionedHistoryNodesQuery
+	}
+	return pdb.driver.ExecContext(ctx, dbShardID, query, filter.ShardID, filter.TreeID, filter.BranchID, *filter.MinNodeID, filter.PageSize)
 }
 
 // For history_tree table:
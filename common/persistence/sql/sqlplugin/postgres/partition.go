@@ -0,0 +1,122 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/uber/cadence/common/persistence/sql/sqlplugin"
+)
+
+const (
+	// partitions of the partitioned schema are named <table>_b<bucket>, see schema/postgres/cadence/partitioned
+	partitionNameInfix = "_b"
+
+	selectPartitionNamesQuery = `SELECT c.relname FROM pg_inherits i ` +
+		`JOIN pg_class c ON c.oid = i.inhrelid JOIN pg_class p ON p.oid = i.inhparent WHERE p.relname = $1`
+
+	// NOTE table and partition names can't be bind parameters, they are validated before formatted into the queries
+	createPartitionQuery = `CREATE TABLE %v PARTITION OF %v FOR VALUES FROM (%v) TO (%v)`
+
+	dropPartitionQuery = `DROP TABLE %v`
+
+	historyNodePartitionInUseQuery = `SELECT 1 FROM %v n WHERE EXISTS ` +
+		`(SELECT 1 FROM history_tree t WHERE t.shard_id = n.shard_id AND t.tree_id = n.tree_id) LIMIT 1`
+
+	executionsPartitionInUseQuery = `SELECT 1 FROM %v LIMIT 1`
+)
+
+var _ sqlplugin.PartitionAdmin = (*db)(nil)
+
+func partitionName(table string, bucket int64) string {
+	return table + partitionNameInfix + strconv.FormatInt(bucket, 10)
+}
+
+// SelectPartitionBuckets returns the buckets of the partitions of the table in ascending order
+func (pdb *db) SelectPartitionBuckets(ctx context.Context, dbShardID int, table string) ([]int64, error) {
+	if err := sqlplugin.ValidatePartitionedTable(table); err != nil {
+		return nil, err
+	}
+	var names []string
+	if err := pdb.driver.SelectContext(ctx, dbShardID, &names, selectPartitionNamesQuery, table); err != nil {
+		return nil, err
+	}
+	var buckets []int64
+	for _, name := range names {
+		// the default partition and anything not created by CreatePartition are skipped
+		if !strings.HasPrefix(name, table+partitionNameInfix) {
+			continue
+		}
+		bucket, err := strconv.ParseInt(strings.TrimPrefix(name, table+partitionNameInfix), 10, 64)
+		if err != nil {
+			continue
+		}
+		buckets = append(buckets, bucket)
+	}
+	sort.Slice(buckets, func(i, j int) bool { return buckets[i] < buckets[j] })
+	return buckets, nil
+}
+
+// CreatePartition creates the partition of the bucket
+func (pdb *db) CreatePartition(ctx context.Context, dbShardID int, table string, bucket int64) error {
+	if err := sqlplugin.ValidatePartitionedTable(table); err != nil {
+		return err
+	}
+	_, err := pdb.driver.ExecContext(ctx, dbShardID, fmt.Sprintf(createPartitionQuery, partitionName(table, bucket), table, bucket, bucket+1))
+	return err
+}
+
+// IsPartitionInUse returns whether the partition still has rows in use
+func (pdb *db) IsPartitionInUse(ctx context.Context, dbShardID int, table string, bucket int64) (bool, error) {
+	if err := sqlplugin.ValidatePartitionedTable(table); err != nil {
+		return false, err
+	}
+	query := executionsPartitionInUseQuery
+	if table == sqlplugin.PartitionedTableHistoryNode {
+		query = historyNodePartitionInUseQuery
+	}
+	var inUse int
+	err := pdb.driver.GetContext(ctx, dbShardID, &inUse, fmt.Sprintf(query, partitionName(table, bucket)))
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// DropPartition drops the partition of the bucket with all its rows
+func (pdb *db) DropPartition(ctx context.Context, dbShardID int, table string, bucket int64) error {
+	if err := sqlplugin.ValidatePartitionedTable(table); err != nil {
+		return err
+	}
+	if bucket == sqlplugin.LegacyPartitionBucket {
+		return fmt.Errorf("partition of the legacy bucket of table %v can't be dropped", table)
+	}
+	_, err := pdb.driver.ExecContext(ctx, dbShardID, fmt.Sprintf(dropPartitionQuery, partitionName(table, bucket)))
+	return err
+}
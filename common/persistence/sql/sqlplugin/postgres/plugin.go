@@ -21,11 +21,13 @@
 package postgres
 
 import (
+	"context"
 	"fmt"
 	"net"
 	"net/url"
 	"os"
 	"runtime"
+	"time"
 
 	"github.com/uber/cadence/common/config"
 	pt "github.com/uber/cadence/common/persistence/persistence-tests"
@@ -42,6 +44,11 @@ const (
 	// PluginName is the name of the plugin
 	PluginName = "postgres"
 	dsnFmt     = "postgres://%s@%s:%s/%s"
+
+	// a standby server which has replayed all the received WAL is considered as not lagging, as the
+	// last replayed transaction can be old when there is no write on the primary
+	replicationLagQuery = `SELECT CASE WHEN pg_last_wal_receive_lsn() = pg_last_wal_replay_lsn() THEN 0 ` +
+		`ELSE COALESCE(EXTRACT(EPOCH FROM now() - pg_last_xact_replay_timestamp()), 0) END`
 )
 
 type plugin struct{}
@@ -60,7 +67,20 @@ func (d *plugin) CreateDB(cfg *config.SQL) (sqlplugin.DB, error) {
 	if err != nil {
 		return nil, err
 	}
-	return newDB(conns, nil, sqlplugin.DbShardUndefined, cfg.NumShards)
+	replicas, err := sqldriver.CreateReplicaConnections(cfg, func(cfg *config.SQL) (*sqlx.DB, error) {
+		return d.createSingleDBConn(cfg)
+	}, d.replicationLag)
+	if err != nil {
+		for _, conn := range conns {
+			conn.Close()
+		}
+		return nil, err
+	}
+	var partitionBucketSize time.Duration
+	if cfg.Partitioning != nil {
+		partitionBucketSize = cfg.Partitioning.BucketSize
+	}
+	return newDB(conns, replicas, nil, sqlplugin.DbShardUndefined, cfg.NumShards, partitionBucketSize)
 }
 
 // CreateAdminDB initialize the adminDB object
@@ -71,7 +91,7 @@ func (d *plugin) CreateAdminDB(cfg *config.SQL) (sqlplugin.AdminDB, error) {
 	if err != nil {
 		return nil, err
 	}
-	return newDB(conns, nil, sqlplugin.DbShardUndefined, cfg.NumShards)
+	return newDB(conns, nil, nil, sqlplugin.DbShardUndefined, cfg.NumShards, 0)
 }
 
 // CreateDBConnection creates a returns a reference to a logical connection to the
@@ -108,6 +128,15 @@ func (d *plugin) createSingleDBConn(cfg *config.SQL) (*sqlx.DB, error) {
 	return db, nil
 }
 
+// replicationLag returns how much a postgres standby server is lagging behind the primary
+func (d *plugin) replicationLag(ctx context.Context, db *sqlx.DB) (time.Duration, error) {
+	var lagSeconds float64
+	if err := db.GetContext(ctx, &lagSeconds, replicationLagQuery); err != nil {
+		return 0, err
+	}
+	return time.Duration(lagSeconds * float64(time.Second)), nil
+}
+
 func buildDSN(cfg *config.SQL, host string, port string, sslParams url.Values) string {
 	dbName := cfg.DatabaseName
 	//NOTE: postgres doesn't allow to connect with empty dbName, the admin dbName is "postgres"
@@ -26,19 +26,22 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
 
 	"github.com/uber/cadence/common/persistence/sql/sqlplugin"
 )
 
 const (
 	templateCreateWorkflowExecutionStarted = `INSERT INTO executions_visibility (` +
-		`domain_id, workflow_id, run_id, start_time, execution_time, workflow_type_name, memo, encoding, is_cron, num_clusters) ` +
-		`VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		`domain_id, workflow_id, run_id, start_time, execution_time, workflow_type_name, memo, encoding, task_list, is_cron, num_clusters) ` +
+		`VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
          ON CONFLICT (domain_id, run_id) DO NOTHING`
 
 	templateCreateWorkflowExecutionClosed = `INSERT INTO executions_visibility (` +
-		`domain_id, workflow_id, run_id, start_time, execution_time, workflow_type_name, close_time, close_status, history_length, memo, encoding, is_cron, num_clusters) ` +
-		`VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+		`domain_id, workflow_id, run_id, start_time, execution_time, workflow_type_name, close_time, close_status, history_length, memo, encoding, task_list, is_cron, num_clusters) ` +
+		`VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
 		ON CONFLICT (domain_id, run_id) DO UPDATE
 		  SET workflow_id = excluded.workflow_id,
 		      start_time = excluded.start_time,
@@ -49,6 +52,7 @@ const (
 			  history_length = excluded.history_length,
 			  memo = excluded.memo,
 			  encoding = excluded.encoding,
+			  task_list = excluded.task_list,
 				is_cron = excluded.is_cron,
 				num_clusters = excluded.num_clusters`
 
@@ -93,6 +97,19 @@ const (
 		 AND run_id = $2`
 
 	templateDeleteWorkflowExecution = "DELETE FROM executions_visibility WHERE domain_id=$1 AND run_id=$2"
+
+	templateQueryFieldNames = templateOpenFieldNames + `, close_time, close_status, history_length, task_list, COALESCE(num_clusters, 0) AS num_clusters`
+
+	// query conditions are rendered with ? placeholders and rebound before execution
+	templateGetWorkflowExecutionsByQuery = `SELECT ` + templateQueryFieldNames + ` FROM executions_visibility WHERE domain_id = ?`
+
+	templateCountWorkflowExecutionsByQuery = `SELECT COUNT(*) FROM executions_visibility WHERE domain_id = ?`
+
+	templateCreateSearchAttributes = `INSERT INTO executions_visibility_search_attributes (` +
+		`domain_id, run_id, name, value_index, string_value, int_value, double_value, bool_value, datetime_value) ` +
+		`VALUES (:domain_id, :run_id, :name, :value_index, :string_value, :int_value, :double_value, :bool_value, :datetime_value)`
+
+	templateDeleteSearchAttributes = "DELETE FROM executions_visibility_search_attributes WHERE domain_id=$1 AND run_id=$2"
 )
 
 var errCloseParams = errors.New("missing one of {closeStatus, closeTime, historyLength} params")
@@ -111,6 +128,7 @@ func (pdb *db) InsertIntoVisibility(ctx context.Context, row *sqlplugin.Visibili
 		row.WorkflowTypeName,
 		row.Memo,
 		row.Encoding,
+		row.TaskList,
 		row.IsCron,
 		row.NumClusters)
 }
@@ -134,6 +152,7 @@ func (pdb *db) ReplaceIntoVisibility(ctx context.Context, row *sqlplugin.Visibil
 			*row.HistoryLength,
 			row.Memo,
 			row.Encoding,
+			row.TaskList,
 			row.IsCron,
 			row.NumClusters)
 	default:
@@ -161,7 +180,7 @@ func (pdb *db) SelectFromVisibility(ctx context.Context, filter *sqlplugin.Visib
 	switch {
 	case filter.MinStartTime == nil && filter.RunID != nil && filter.Closed:
 		var row sqlplugin.VisibilityRow
-		err = pdb.driver.GetContext(ctx, dbShardID, &row, templateGetClosedWorkflowExecution, filter.DomainID, *filter.RunID)
+		err = pdb.driver.GetReplicaContext(ctx, dbShardID, &row, templateGetClosedWorkflowExecution, filter.DomainID, *filter.RunID)
 		if err == nil {
 			rows = append(rows, row)
 		}
@@ -170,7 +189,7 @@ func (pdb *db) SelectFromVisibility(ctx context.Context, filter *sqlplugin.Visib
 		if filter.Closed {
 			qry = templateGetClosedWorkflowExecutionsByID
 		}
-		err = pdb.driver.SelectContext(ctx, dbShardID, &rows,
+		err = pdb.driver.SelectReplicaContext(ctx, dbShardID, &rows,
 			qry,
 			*filter.WorkflowID,
 			filter.DomainID,
@@ -184,7 +203,7 @@ func (pdb *db) SelectFromVisibility(ctx context.Context, filter *sqlplugin.Visib
 		if filter.Closed {
 			qry = templateGetClosedWorkflowExecutionsByType
 		}
-		err = pdb.driver.SelectContext(ctx, dbShardID, &rows,
+		err = pdb.driver.SelectReplicaContext(ctx, dbShardID, &rows,
 			qry,
 			*filter.WorkflowTypeName,
 			filter.DomainID,
@@ -194,7 +213,7 @@ func (pdb *db) SelectFromVisibility(ctx context.Context, filter *sqlplugin.Visib
 			*filter.MaxStartTime,
 			*filter.PageSize)
 	case filter.MinStartTime != nil && filter.CloseStatus != nil:
-		err = pdb.driver.SelectContext(ctx, dbShardID, &rows,
+		err = pdb.driver.SelectReplicaContext(ctx, dbShardID, &rows,
 			templateGetClosedWorkflowExecutionsByStatus,
 			*filter.CloseStatus,
 			filter.DomainID,
@@ -210,7 +229,7 @@ func (pdb *db) SelectFromVisibility(ctx context.Context, filter *sqlplugin.Visib
 		}
 		minSt := pdb.converter.ToPostgresDateTime(*filter.MinStartTime)
 		maxSt := pdb.converter.ToPostgresDateTime(*filter.MaxStartTime)
-		err = pdb.driver.SelectContext(ctx, dbShardID, &rows,
+		err = pdb.driver.SelectReplicaContext(ctx, dbShardID, &rows,
 			qry,
 			filter.DomainID,
 			minSt,
@@ -236,3 +255,72 @@ func (pdb *db) SelectFromVisibility(ctx context.Context, filter *sqlplugin.Visib
 	}
 	return rows, err
 }
+
+// SelectFromVisibilityByQuery reads the rows of visibility table that match the query condition
+func (pdb *db) SelectFromVisibilityByQuery(ctx context.Context, filter *sqlplugin.VisibilityQueryFilter) ([]sqlplugin.VisibilityRow, error) {
+	dbShardID := sqlplugin.GetDBShardIDFromDomainID(filter.DomainID, pdb.GetTotalNumDBShards())
+	query, args := pdb.makeVisibilityQuery(templateGetWorkflowExecutionsByQuery, filter)
+	query += ` ORDER BY ` + filter.OrderBy + ` LIMIT ? OFFSET ?`
+	args = append(args, filter.PageSize, filter.Offset)
+	var rows []sqlplugin.VisibilityRow
+	if err := pdb.driver.SelectReplicaContext(ctx, dbShardID, &rows, sqlx.Rebind(sqlx.DOLLAR, query), args...); err != nil {
+		return nil, err
+	}
+	for i := range rows {
+		rows[i].StartTime = pdb.converter.FromPostgresDateTime(rows[i].StartTime)
+		rows[i].ExecutionTime = pdb.converter.FromPostgresDateTime(rows[i].ExecutionTime)
+		if rows[i].CloseTime != nil {
+			closeTime := pdb.converter.FromPostgresDateTime(*rows[i].CloseTime)
+			rows[i].CloseTime = &closeTime
+		}
+		rows[i].RunID = strings.TrimSpace(rows[i].RunID)
+		rows[i].WorkflowID = strings.TrimSpace(rows[i].WorkflowID)
+	}
+	return rows, nil
+}
+
+// CountFromVisibilityByQuery counts the rows of visibility table that match the query condition
+func (pdb *db) CountFromVisibilityByQuery(ctx context.Context, filter *sqlplugin.VisibilityQueryFilter) (int64, error) {
+	dbShardID := sqlplugin.GetDBShardIDFromDomainID(filter.DomainID, pdb.GetTotalNumDBShards())
+	query, args := pdb.makeVisibilityQuery(templateCountWorkflowExecutionsByQuery, filter)
+	var count int64
+	err := pdb.driver.GetReplicaContext(ctx, dbShardID, &count, sqlx.Rebind(sqlx.DOLLAR, query), args...)
+	return count, err
+}
+
+// InsertIntoVisibilitySearchAttributes inserts one or more rows into executions_visibility_search_attributes table
+func (pdb *db) InsertIntoVisibilitySearchAttributes(ctx context.Context, rows []sqlplugin.VisibilitySearchAttributeRow) (sql.Result, error) {
+	if len(rows) == 0 {
+		return nil, nil
+	}
+	dbShardID := sqlplugin.GetDBShardIDFromDomainID(rows[0].DomainID, pdb.GetTotalNumDBShards())
+	for i := range rows {
+		if rows[i].DatetimeValue != nil {
+			datetime := pdb.converter.ToPostgresDateTime(*rows[i].DatetimeValue)
+			rows[i].DatetimeValue = &datetime
+		}
+	}
+	return pdb.driver.NamedExecContext(ctx, dbShardID, templateCreateSearchAttributes, rows)
+}
+
+// DeleteFromVisibilitySearchAttributes deletes all the search attribute rows of a run
+func (pdb *db) DeleteFromVisibilitySearchAttributes(ctx context.Context, filter *sqlplugin.VisibilitySearchAttributesFilter) (sql.Result, error) {
+	dbShardID := sqlplugin.GetDBShardIDFromDomainID(filter.DomainID, pdb.GetTotalNumDBShards())
+	return pdb.driver.ExecContext(ctx, dbShardID, templateDeleteSearchAttributes, filter.DomainID, filter.RunID)
+}
+
+// makeVisibilityQuery appends the query condition to the template and converts the condition arguments
+func (pdb *db) makeVisibilityQuery(template string, filter *sqlplugin.VisibilityQueryFilter) (string, []interface{}) {
+	query := template
+	if filter.Condition != "" {
+		query += ` AND (` + filter.Condition + `)`
+	}
+	args := []interface{}{filter.DomainID}
+	for _, arg := range filter.Args {
+		if t, ok := arg.(time.Time); ok {
+			arg = pdb.converter.ToPostgresDateTime(t)
+		}
+		args = append(args, arg)
+	}
+	return query, args
+}
@@ -0,0 +1,157 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/mattn/go-sqlite3"
+
+	"github.com/uber/cadence/common/persistence/sql/sqldriver"
+	"github.com/uber/cadence/common/persistence/sql/sqlplugin"
+)
+
+type (
+	db struct {
+		converter   DataConverter
+		driver      sqldriver.Driver
+		originalDBs []*sqlx.DB
+		replicas    *sqldriver.Replicas
+		numDBShards int
+		// partitionBucketSize is the bucket size of the partitioned schema, zero if the schema is not partitioned
+		partitionBucketSize time.Duration
+		// memoryDatabaseName is the name of the in-memory database, whose connection is shared by all its stores
+		memoryDatabaseName string
+	}
+)
+
+func (sdb *db) GetTotalNumDBShards() int {
+	return sdb.numDBShards
+}
+
+var _ sqlplugin.DB = (*db)(nil)
+var _ sqlplugin.Tx = (*db)(nil)
+
+func (sdb *db) IsDupEntryError(err error) bool {
+	sqlErr, ok := err.(sqlite3.Error)
+	// the row already exists when either the primary key or a unique constraint is violated
+	return ok && (sqlErr.ExtendedCode == sqlite3.ErrConstraintPrimaryKey ||
+		sqlErr.ExtendedCode == sqlite3.ErrConstraintUnique)
+}
+
+func (sdb *db) IsNotFoundError(err error) bool {
+	if err == sql.ErrNoRows {
+		return true
+	}
+	return false
+}
+
+func (sdb *db) IsTimeoutError(err error) bool {
+	if err == context.DeadlineExceeded {
+		return true
+	}
+	return false
+}
+
+func (sdb *db) IsThrottlingError(err error) bool {
+	sqlErr, ok := err.(sqlite3.Error)
+	if ok {
+		// the database is locked by another connection for longer than the busy timeout
+		if sqlErr.Code == sqlite3.ErrBusy ||
+			sqlErr.Code == sqlite3.ErrLocked {
+			return true
+		}
+	}
+	return false
+}
+
+// newDB returns an instance of DB, which is a logical
+// connection to the underlying sqlite database
+// dbShardID is needed when tx is not nil
+func newDB(
+	xdbs []*sqlx.DB,
+	replicas *sqldriver.Replicas,
+	tx *sqlx.Tx,
+	dbShardID int,
+	numDBShards int,
+	partitionBucketSize time.Duration,
+) (*db, error) {
+	driver, err := sqldriver.NewDriver(xdbs, replicas, tx, dbShardID)
+	if err != nil {
+		return nil, err
+	}
+
+	db := &db{
+		converter:   &converter{},
+		originalDBs: xdbs, // this is kept because newDB will be called again when starting a transaction
+		replicas:    replicas,
+		driver:      driver,
+		numDBShards: numDBShards,
+
+		partitionBucketSize: partitionBucketSize,
+	}
+	return db, nil
+}
+
+// BeginTx starts a new transaction and returns a reference to the Tx object
+func (sdb *db) BeginTx(dbShardID int, ctx context.Context) (sqlplugin.Tx, error) {
+	xtx, err := sdb.driver.BeginTxx(ctx, dbShardID, nil)
+	if err != nil {
+		return nil, err
+	}
+	return newDB(sdb.originalDBs, sdb.replicas, xtx, dbShardID, sdb.numDBShards, sdb.partitionBucketSize)
+}
+
+// Commit commits a previously started transaction
+func (sdb *db) Commit() error {
+	return sdb.driver.Commit()
+}
+
+// Rollback triggers rollback of a previously started transaction
+func (sdb *db) Rollback() error {
+	return sdb.driver.Rollback()
+}
+
+// Close closes the connection to the sqlite db
+func (sdb *db) Close() error {
+	if sdb.memoryDatabaseName != "" {
+		return releaseMemoryDatabase(sdb.memoryDatabaseName)
+	}
+	return sdb.driver.Close()
+}
+
+// PluginName returns the name of the sqlite plugin
+func (sdb *db) PluginName() string {
+	return PluginName
+}
+
+// SupportsTTL returns weather SQLite supports TTL
+func (sdb *db) SupportsTTL() bool {
+	return false
+}
+
+// MaxAllowedTTL returns the max allowed ttl SQLite supports
+func (sdb *db) MaxAllowedTTL() (*time.Duration, error) {
+	return nil, sqlplugin.ErrTTLNotSupported
+}
@@ -0,0 +1,132 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package sqlite
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sync"
+
+	"github.com/jmoiron/sqlx"
+
+	"github.com/uber/cadence/common/config"
+	"github.com/uber/cadence/common/persistence/sql/sqlplugin"
+)
+
+const (
+	// schemaDirAttribute is the connect attribute of the directory of the sqlite schema, which is
+	// applied when an in-memory database is opened
+	schemaDirAttribute = "schema_dir"
+	defaultSchemaDir   = "schema/sqlite"
+)
+
+type memoryDatabase struct {
+	conn *sqlx.DB
+	refs int
+}
+
+// memoryDatabases are the in-memory databases opened by the process.
+// A shared-cache in-memory database is deleted with its last connection, and its tables are locked by
+// connection without waiting for the busy timeout, so all the stores of a database share a single connection,
+// which is closed with the last store.
+var memoryDatabases = struct {
+	sync.Mutex
+	dbs map[string]*memoryDatabase
+}{dbs: map[string]*memoryDatabase{}}
+
+func (d *plugin) createMemoryDB(cfg *config.SQL) (*db, error) {
+	if cfg.UseMultipleDatabases || len(cfg.Replicas) > 0 {
+		return nil, fmt.Errorf("sqlite in-memory database doesn't support multiple databases or replicas")
+	}
+	conn, err := d.acquireMemoryDatabase(cfg)
+	if err != nil {
+		return nil, err
+	}
+	sdb, err := newDB([]*sqlx.DB{conn}, nil, nil, sqlplugin.DbShardUndefined, cfg.NumShards, 0)
+	if err != nil {
+		releaseMemoryDatabase(cfg.DatabaseName)
+		return nil, err
+	}
+	sdb.memoryDatabaseName = cfg.DatabaseName
+	return sdb, nil
+}
+
+func (d *plugin) acquireMemoryDatabase(cfg *config.SQL) (*sqlx.DB, error) {
+	memoryDatabases.Lock()
+	defer memoryDatabases.Unlock()
+
+	if mdb, ok := memoryDatabases.dbs[cfg.DatabaseName]; ok {
+		mdb.refs++
+		return mdb.conn, nil
+	}
+
+	conn, err := d.createSingleDBConn(cfg)
+	if err != nil {
+		return nil, err
+	}
+	conn.SetMaxOpenConns(1)
+	conn.SetMaxIdleConns(1)
+	conn.SetConnMaxLifetime(0)
+
+	schemaDir := cfg.ConnectAttributes[schemaDirAttribute]
+	if schemaDir == "" {
+		schemaDir = defaultSchemaDir
+	}
+	if err := applySchema(conn, schemaDir); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	memoryDatabases.dbs[cfg.DatabaseName] = &memoryDatabase{conn: conn, refs: 1}
+	return conn, nil
+}
+
+func releaseMemoryDatabase(name string) error {
+	memoryDatabases.Lock()
+	defer memoryDatabases.Unlock()
+
+	mdb, ok := memoryDatabases.dbs[name]
+	if !ok {
+		return nil
+	}
+	mdb.refs--
+	if mdb.refs > 0 {
+		return nil
+	}
+	delete(memoryDatabases.dbs, name)
+	return mdb.conn.Close()
+}
+
+// applySchema creates the tables of both the default and the visibility store, since an in-memory
+// database can't be set up by the schema tool
+func applySchema(conn *sqlx.DB, schemaDir string) error {
+	for _, dir := range []string{"cadence", "visibility"} {
+		path := filepath.Join(schemaDir, dir, "schema.sql")
+		content, err := ioutil.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("unable to read sqlite schema %v: %v", path, err)
+		}
+		if _, err := conn.Exec(string(content)); err != nil {
+			return fmt.Errorf("unable to apply sqlite schema %v: %v", path, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,173 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package sqlite
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"path/filepath"
+	"strings"
+
+	"github.com/uber/cadence/common/config"
+	pt "github.com/uber/cadence/common/persistence/persistence-tests"
+	"github.com/uber/cadence/common/persistence/sql"
+	"github.com/uber/cadence/common/persistence/sql/sqldriver"
+	"github.com/uber/cadence/common/persistence/sql/sqlplugin"
+
+	"github.com/iancoleman/strcase"
+	"github.com/jmoiron/sqlx"
+)
+
+const (
+	// PluginName is the name of the plugin
+	PluginName = "sqlite"
+	// driverName is the name of the database/sql driver registered by go-sqlite3
+	driverName = "sqlite3"
+	// memoryDatabasePrefix is the prefix of the database name of a named in-memory database,
+	// e.g. ":memory:cadence" is the in-memory database named cadence
+	memoryDatabasePrefix = ":memory:"
+	dsnFmt               = "file:%s"
+	memoryDSNFmt         = "file:%s?mode=memory&cache=shared"
+	defaultBusyTimeout   = "10000"
+)
+
+type plugin struct{}
+
+var _ sqlplugin.Plugin = (*plugin)(nil)
+
+func init() {
+	sql.RegisterPlugin(PluginName, &plugin{})
+}
+
+// CreateDB initialize the db object
+func (d *plugin) CreateDB(cfg *config.SQL) (sqlplugin.DB, error) {
+	if cfg.Partitioning != nil {
+		return nil, fmt.Errorf("sqlite plugin doesn't support the partitioned schema")
+	}
+	if isMemoryDatabase(cfg.DatabaseName) {
+		return d.createMemoryDB(cfg)
+	}
+	conns, err := sqldriver.CreateDBConnections(cfg, func(cfg *config.SQL) (*sqlx.DB, error) {
+		return d.createSingleDBConn(cfg)
+	})
+	if err != nil {
+		return nil, err
+	}
+	replicas, err := sqldriver.CreateReplicaConnections(cfg, func(cfg *config.SQL) (*sqlx.DB, error) {
+		return d.createSingleDBConn(cfg)
+	}, nil)
+	if err != nil {
+		for _, conn := range conns {
+			conn.Close()
+		}
+		return nil, err
+	}
+	return newDB(conns, replicas, nil, sqlplugin.DbShardUndefined, cfg.NumShards, 0)
+}
+
+// CreateAdminDB initialize the adminDB object
+func (d *plugin) CreateAdminDB(cfg *config.SQL) (sqlplugin.AdminDB, error) {
+	if isMemoryDatabase(cfg.DatabaseName) {
+		return d.createMemoryDB(cfg)
+	}
+	conns, err := sqldriver.CreateDBConnections(cfg, func(cfg *config.SQL) (*sqlx.DB, error) {
+		return d.createSingleDBConn(cfg)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return newDB(conns, nil, nil, sqlplugin.DbShardUndefined, cfg.NumShards, 0)
+}
+
+// CreateDBConnection creates a returns a reference to a logical connection to the
+// underlying SQL database. The returned object is to tied to a single
+// SQL database and the object can be used to perform CRUD operations on
+// the tables in the database
+// For SQLite the DatabaseName is the path of the database file, ConnectAddr,
+// User and Password are ignored
+// NOTE: the sqlite driver requires cgo, a binary built with CGO_ENABLED=0 fails to connect
+func (d *plugin) createSingleDBConn(cfg *config.SQL) (*sqlx.DB, error) {
+	if cfg.DatabaseName == "" {
+		return nil, fmt.Errorf("sqlite database name can't be empty, use the path of a file or %v<name> for an in-memory database", memoryDatabasePrefix)
+	}
+	db, err := sqlx.Connect(driverName, buildDSN(cfg))
+	if err != nil {
+		return nil, err
+	}
+	if cfg.MaxConns > 0 {
+		db.SetMaxOpenConns(cfg.MaxConns)
+	}
+	if cfg.MaxIdleConns > 0 {
+		db.SetMaxIdleConns(cfg.MaxIdleConns)
+	}
+	if cfg.MaxConnLifetime > 0 {
+		db.SetConnMaxLifetime(cfg.MaxConnLifetime)
+	}
+
+	// Maps struct names in CamelCase to snake without need for db struct tags.
+	db.MapperFunc(strcase.ToSnake)
+	return db, nil
+}
+
+func buildDSN(cfg *config.SQL) string {
+	if isMemoryDatabase(cfg.DatabaseName) {
+		// a named shared-cache database is shared by all the connections of the process
+		return fmt.Sprintf(memoryDSNFmt, url.PathEscape(strings.TrimPrefix(cfg.DatabaseName, memoryDatabasePrefix)))
+	}
+
+	attrs := url.Values{}
+	// wait for locks held by other connections instead of failing right away,
+	// take the write lock when a transaction begins to avoid deadlocks on lock upgrade
+	// and use write-ahead logging so readers don't block the writer
+	attrs.Set("_busy_timeout", defaultBusyTimeout)
+	attrs.Set("_txlock", "immediate")
+	attrs.Set("_journal_mode", "WAL")
+	attrs.Set("_foreign_keys", "false")
+	for k, v := range cfg.ConnectAttributes {
+		if k != schemaDirAttribute {
+			attrs.Set(k, v)
+		}
+	}
+	return fmt.Sprintf(dsnFmt, cfg.DatabaseName) + "?" + attrs.Encode()
+}
+
+func isMemoryDatabase(name string) bool {
+	return strings.HasPrefix(name, memoryDatabasePrefix)
+}
+
+const (
+	testSchemaDir = "schema/sqlite"
+)
+
+// GetTestClusterOption return test options
+func GetTestClusterOption() *pt.TestBaseOptions {
+	dir, err := ioutil.TempDir("", "cadence_sqlite_")
+	if err != nil {
+		panic(fmt.Sprintf("unable to create temp dir for sqlite database: %v", err))
+	}
+
+	return &pt.TestBaseOptions{
+		DBPluginName: PluginName,
+		DBName:       filepath.Join(dir, "cadence.db"),
+		SchemaDir:    testSchemaDir,
+	}
+}
@@ -0,0 +1,104 @@
+// The MIT License (MIT)
+
+// Copyright (c) 2017-2020 Uber Technologies Inc.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package sqlite
+
+import (
+	"testing"
+
+	"github.com/uber/cadence/common/config"
+)
+
+var testCases = []struct {
+	name string
+	cfg  *config.SQL
+	want string
+}{
+	{
+		name: "memory database",
+		cfg:  &config.SQL{DatabaseName: ":memory:cadence"},
+		want: "file:cadence?mode=memory&cache=shared",
+	},
+	{
+		name: "file database",
+		cfg:  &config.SQL{DatabaseName: "/tmp/cadence.db"},
+		want: "file:/tmp/cadence.db?_busy_timeout=10000&_foreign_keys=false&_journal_mode=WAL&_txlock=immediate",
+	},
+	{
+		name: "connect attributes override defaults",
+		cfg: &config.SQL{
+			DatabaseName:      "cadence.db",
+			ConnectAttributes: map[string]string{"_busy_timeout": "500", "_sync": "NORMAL", schemaDirAttribute: "schema"},
+		},
+		want: "file:cadence.db?_busy_timeout=500&_foreign_keys=false&_journal_mode=WAL&_sync=NORMAL&_txlock=immediate",
+	},
+}
+
+func TestBuildDSN(t *testing.T) {
+	for _, tc := range testCases {
+		if dsn := buildDSN(tc.cfg); dsn != tc.want {
+			t.Errorf("%v: got %v, want %v", tc.name, dsn, tc.want)
+		}
+	}
+}
+
+func TestEmptyDatabaseName(t *testing.T) {
+	if _, err := (&plugin{}).CreateDB(&config.SQL{}); err == nil {
+		t.Errorf("expect an error for an empty database name")
+	}
+}
+
+func TestMemoryDatabase(t *testing.T) {
+	cfg := &config.SQL{
+		DatabaseName:      ":memory:test_memory_database",
+		ConnectAttributes: map[string]string{schemaDirAttribute: "../../../../../schema/sqlite"},
+	}
+	db1, err := (&plugin{}).CreateDB(cfg)
+	if err != nil {
+		t.Fatalf("failed to create the first db: %v", err)
+	}
+	db2, err := (&plugin{}).CreateAdminDB(cfg)
+	if err != nil {
+		t.Fatalf("failed to create the second db: %v", err)
+	}
+	conn := db1.(*db).originalDBs[0]
+	if conn != db2.(*db).originalDBs[0] {
+		t.Errorf("expect the stores of an in-memory database to share its connection")
+	}
+
+	if _, err := conn.Exec("INSERT INTO shards (shard_id, range_id, data, data_encoding) VALUES (1, 1, x'00', 'thriftrw')"); err != nil {
+		t.Fatalf("expect the schema to be applied: %v", err)
+	}
+	if err := db1.Close(); err != nil {
+		t.Fatalf("failed to close the first db: %v", err)
+	}
+	var count int
+	if err := conn.Get(&count, "SELECT count(*) FROM shards"); err != nil || count != 1 {
+		t.Errorf("expect the database to be kept until its last store is closed, count: %v, err: %v", count, err)
+	}
+	if err := db2.Close(); err != nil {
+		t.Fatalf("failed to close the second db: %v", err)
+	}
+	if _, ok := memoryDatabases.dbs[cfg.DatabaseName]; ok {
+		t.Errorf("expect the database to be released with its last store")
+	}
+}
@@ -293,6 +293,22 @@ func mergeMutableStateUpdateSessionStats(stats ...*MutableStateUpdateSessionStat
 	return result
 }
 
+func (sc *statsComputer) computeExecutionRecordSize(entity *InternalListConcreteExecutionsEntity) int {
+	size := computeExecutionInfoSize(entity.ExecutionInfo)
+	size += len(entity.ExecutionInfo.ExecutionContext)
+	if entity.ExecutionInfo.CompletionEvent != nil {
+		size += len(entity.ExecutionInfo.CompletionEvent.Data)
+	}
+	if entity.ExecutionInfo.AutoResetPoints != nil {
+		size += len(entity.ExecutionInfo.AutoResetPoints.Data)
+	}
+	if entity.VersionHistories != nil {
+		size += len(entity.VersionHistories.Data)
+	}
+
+	return size
+}
+
 func computeExecutionInfoSize(executionInfo *InternalWorkflowExecutionInfo) int {
 	size := len(executionInfo.WorkflowID)
 	size += len(executionInfo.TaskList)
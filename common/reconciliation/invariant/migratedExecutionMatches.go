@@ -0,0 +1,205 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2017-2020 Uber Technologies Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package invariant
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	c "github.com/uber/cadence/common"
+	"github.com/uber/cadence/common/persistence"
+	"github.com/uber/cadence/common/reconciliation/entity"
+	"github.com/uber/cadence/common/types"
+)
+
+type (
+	migratedExecutionMatches struct {
+		source persistence.Retryer
+		target persistence.Retryer
+	}
+)
+
+// NewMigratedExecutionMatches returns a new invariant which compares executions of the source store
+// with their copies in the migration target store
+func NewMigratedExecutionMatches(
+	source persistence.Retryer,
+	target persistence.Retryer,
+) Invariant {
+	return &migratedExecutionMatches{
+		source: source,
+		target: target,
+	}
+}
+
+func (m *migratedExecutionMatches) Check(
+	ctx context.Context,
+	execution interface{},
+) CheckResult {
+	if checkResult := validateCheckContext(ctx, m.Name()); checkResult != nil {
+		return *checkResult
+	}
+
+	concreteExecution, ok := execution.(*entity.ConcreteExecution)
+	if !ok {
+		return CheckResult{
+			CheckResultType: CheckResultTypeFailed,
+			InvariantName:   m.Name(),
+			Info:            "failed to check: expected concrete execution",
+		}
+	}
+	req := &persistence.GetWorkflowExecutionRequest{
+		DomainID: concreteExecution.DomainID,
+		Execution: types.WorkflowExecution{
+			WorkflowID: concreteExecution.WorkflowID,
+			RunID:      concreteExecution.RunID,
+		},
+	}
+	// the target is read first, so that the writes which happen in between are already mirrored
+	// when the source is read and do not show up as a mismatch
+	targetResp, targetErr := m.target.GetWorkflowExecution(ctx, req)
+	sourceResp, sourceErr := m.source.GetWorkflowExecution(ctx, req)
+	if sourceErr != nil {
+		if _, ok := sourceErr.(*types.EntityNotExistsError); ok {
+			return CheckResult{
+				CheckResultType: CheckResultTypeHealthy,
+				InvariantName:   m.Name(),
+				Info:            "determined execution was healthy because concrete execution no longer exists",
+			}
+		}
+		return CheckResult{
+			CheckResultType: CheckResultTypeFailed,
+			InvariantName:   m.Name(),
+			Info:            "failed to get concrete execution from source store",
+			InfoDetails:     sourceErr.Error(),
+		}
+	}
+	if targetErr != nil {
+		if _, ok := targetErr.(*types.EntityNotExistsError); ok {
+			return CheckResult{
+				CheckResultType: CheckResultTypeCorrupted,
+				InvariantName:   m.Name(),
+				Info:            "concrete execution does not exist in migration target store",
+				InfoDetails:     targetErr.Error(),
+			}
+		}
+		return CheckResult{
+			CheckResultType: CheckResultTypeFailed,
+			InvariantName:   m.Name(),
+			Info:            "failed to get concrete execution from migration target store",
+			InfoDetails:     targetErr.Error(),
+		}
+	}
+	if mismatches := compareMutableStates(sourceResp.State, targetResp.State); len(mismatches) > 0 {
+		return CheckResult{
+			CheckResultType: CheckResultTypeCorrupted,
+			InvariantName:   m.Name(),
+			Info:            "concrete execution in migration target store does not match source store",
+			InfoDetails:     strings.Join(mismatches, ", "),
+		}
+	}
+
+	historyResp, err := m.target.ReadHistoryBranch(ctx, &persistence.ReadHistoryBranchRequest{
+		BranchToken: concreteExecution.BranchToken,
+		MinEventID:  c.FirstEventID,
+		MaxEventID:  c.FirstEventID + 1,
+		PageSize:    historyPageSize,
+		ShardID:     c.IntPtr(concreteExecution.ShardID),
+	})
+	if err != nil {
+		if _, ok := err.(*types.EntityNotExistsError); ok {
+			return CheckResult{
+				CheckResultType: CheckResultTypeCorrupted,
+				InvariantName:   m.Name(),
+				Info:            "history does not exist in migration target store",
+				InfoDetails:     err.Error(),
+			}
+		}
+		return CheckResult{
+			CheckResultType: CheckResultTypeFailed,
+			InvariantName:   m.Name(),
+			Info:            "failed to read history from migration target store",
+			InfoDetails:     err.Error(),
+		}
+	}
+	if historyResp == nil || len(historyResp.HistoryEvents) == 0 {
+		return CheckResult{
+			CheckResultType: CheckResultTypeCorrupted,
+			InvariantName:   m.Name(),
+			Info:            "got empty history from migration target store",
+		}
+	}
+	return CheckResult{
+		CheckResultType: CheckResultTypeHealthy,
+		InvariantName:   m.Name(),
+	}
+}
+
+func (m *migratedExecutionMatches) Fix(
+	ctx context.Context,
+	execution interface{},
+) FixResult {
+	if fixResult := validateFixContext(ctx, m.Name()); fixResult != nil {
+		return *fixResult
+	}
+
+	fixResult, checkResult := checkBeforeFix(ctx, m, execution)
+	if fixResult != nil {
+		return *fixResult
+	}
+	// the target store must not be modified here, the executions are copied by the migration backfill
+	return FixResult{
+		FixResultType: FixResultTypeSkipped,
+		InvariantName: m.Name(),
+		CheckResult:   *checkResult,
+		Info:          "skipped fix because execution has to be copied again by the migration backfill",
+	}
+}
+
+func (m *migratedExecutionMatches) Name() Name {
+	return MigratedExecutionMatches
+}
+
+func compareMutableStates(
+	source *persistence.WorkflowMutableState,
+	target *persistence.WorkflowMutableState,
+) []string {
+	var mismatches []string
+	compare := func(field string, sourceValue, targetValue interface{}) {
+		if sourceValue != targetValue {
+			mismatches = append(mismatches, fmt.Sprintf("%v: %v != %v", field, sourceValue, targetValue))
+		}
+	}
+	compare("NextEventID", source.ExecutionInfo.NextEventID, target.ExecutionInfo.NextEventID)
+	compare("State", source.ExecutionInfo.State, target.ExecutionInfo.State)
+	compare("CloseStatus", source.ExecutionInfo.CloseStatus, target.ExecutionInfo.CloseStatus)
+	compare("LastFirstEventID", source.ExecutionInfo.LastFirstEventID, target.ExecutionInfo.LastFirstEventID)
+	compare("DecisionScheduleID", source.ExecutionInfo.DecisionScheduleID, target.ExecutionInfo.DecisionScheduleID)
+	compare("ActivityInfos", len(source.ActivityInfos), len(target.ActivityInfos))
+	compare("TimerInfos", len(source.TimerInfos), len(target.TimerInfos))
+	compare("ChildExecutionInfos", len(source.ChildExecutionInfos), len(target.ChildExecutionInfos))
+	compare("RequestCancelInfos", len(source.RequestCancelInfos), len(target.RequestCancelInfos))
+	compare("SignalInfos", len(source.SignalInfos), len(target.SignalInfos))
+	compare("BufferedEvents", len(source.BufferedEvents), len(target.BufferedEvents))
+	return mismatches
+}
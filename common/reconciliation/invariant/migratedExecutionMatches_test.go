@@ -0,0 +1,182 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2017-2020 Uber Technologies Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package invariant
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+
+	c2 "github.com/uber/cadence/common"
+	"github.com/uber/cadence/common/mocks"
+	"github.com/uber/cadence/common/persistence"
+	"github.com/uber/cadence/common/types"
+)
+
+type MigratedExecutionMatchesSuite struct {
+	*require.Assertions
+	suite.Suite
+}
+
+func TestMigratedExecutionMatchesSuite(t *testing.T) {
+	suite.Run(t, new(MigratedExecutionMatchesSuite))
+}
+
+func (s *MigratedExecutionMatchesSuite) SetupTest() {
+	s.Assertions = require.New(s.T())
+}
+
+func (s *MigratedExecutionMatchesSuite) TestCheck() {
+	testCases := []struct {
+		sourceExecResp    *persistence.GetWorkflowExecutionResponse
+		sourceExecErr     error
+		targetExecResp    *persistence.GetWorkflowExecutionResponse
+		targetExecErr     error
+		targetHistoryResp *persistence.ReadHistoryBranchResponse
+		targetHistoryErr  error
+		expectedResult    CheckResult
+	}{
+		{
+			sourceExecErr: errors.New("got error getting workflow execution"),
+			targetExecErr: &types.EntityNotExistsError{},
+			expectedResult: CheckResult{
+				CheckResultType: CheckResultTypeFailed,
+				InvariantName:   MigratedExecutionMatches,
+				Info:            "failed to get concrete execution from source store",
+				InfoDetails:     "got error getting workflow execution",
+			},
+		},
+		{
+			sourceExecErr: &types.EntityNotExistsError{},
+			targetExecErr: &types.EntityNotExistsError{},
+			expectedResult: CheckResult{
+				CheckResultType: CheckResultTypeHealthy,
+				InvariantName:   MigratedExecutionMatches,
+				Info:            "determined execution was healthy because concrete execution no longer exists",
+			},
+		},
+		{
+			sourceExecResp: getMutableStateResponse(10, 0),
+			targetExecErr:  &types.EntityNotExistsError{Message: "got entity not exists error"},
+			expectedResult: CheckResult{
+				CheckResultType: CheckResultTypeCorrupted,
+				InvariantName:   MigratedExecutionMatches,
+				Info:            "concrete execution does not exist in migration target store",
+				InfoDetails:     "EntityNotExistsError{Message: got entity not exists error}",
+			},
+		},
+		{
+			sourceExecResp: getMutableStateResponse(10, 0),
+			targetExecErr:  errors.New("got error getting workflow execution"),
+			expectedResult: CheckResult{
+				CheckResultType: CheckResultTypeFailed,
+				InvariantName:   MigratedExecutionMatches,
+				Info:            "failed to get concrete execution from migration target store",
+				InfoDetails:     "got error getting workflow execution",
+			},
+		},
+		{
+			sourceExecResp: getMutableStateResponse(10, 2),
+			targetExecResp: getMutableStateResponse(8, 1),
+			expectedResult: CheckResult{
+				CheckResultType: CheckResultTypeCorrupted,
+				InvariantName:   MigratedExecutionMatches,
+				Info:            "concrete execution in migration target store does not match source store",
+				InfoDetails:     "NextEventID: 10 != 8, ActivityInfos: 2 != 1",
+			},
+		},
+		{
+			sourceExecResp:   getMutableStateResponse(10, 0),
+			targetExecResp:   getMutableStateResponse(10, 0),
+			targetHistoryErr: &types.EntityNotExistsError{Message: "got entity not exists error"},
+			expectedResult: CheckResult{
+				CheckResultType: CheckResultTypeCorrupted,
+				InvariantName:   MigratedExecutionMatches,
+				Info:            "history does not exist in migration target store",
+				InfoDetails:     "EntityNotExistsError{Message: got entity not exists error}",
+			},
+		},
+		{
+			sourceExecResp:    getMutableStateResponse(10, 0),
+			targetExecResp:    getMutableStateResponse(10, 0),
+			targetHistoryResp: &persistence.ReadHistoryBranchResponse{},
+			expectedResult: CheckResult{
+				CheckResultType: CheckResultTypeCorrupted,
+				InvariantName:   MigratedExecutionMatches,
+				Info:            "got empty history from migration target store",
+			},
+		},
+		{
+			sourceExecResp: getMutableStateResponse(10, 0),
+			targetExecResp: getMutableStateResponse(10, 0),
+			targetHistoryResp: &persistence.ReadHistoryBranchResponse{
+				HistoryEvents: []*types.HistoryEvent{
+					{},
+				},
+			},
+			expectedResult: CheckResult{
+				CheckResultType: CheckResultTypeHealthy,
+				InvariantName:   MigratedExecutionMatches,
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		sourceExecManager := &mocks.ExecutionManager{}
+		sourceHistoryManager := &mocks.HistoryV2Manager{}
+		targetExecManager := &mocks.ExecutionManager{}
+		targetHistoryManager := &mocks.HistoryV2Manager{}
+		sourceExecManager.On("GetWorkflowExecution", mock.Anything, mock.Anything).Return(tc.sourceExecResp, tc.sourceExecErr)
+		targetExecManager.On("GetWorkflowExecution", mock.Anything, mock.Anything).Return(tc.targetExecResp, tc.targetExecErr)
+		targetHistoryManager.On("ReadHistoryBranch", mock.Anything, mock.Anything).Return(tc.targetHistoryResp, tc.targetHistoryErr)
+		i := NewMigratedExecutionMatches(
+			persistence.NewPersistenceRetryer(sourceExecManager, sourceHistoryManager, c2.CreatePersistenceRetryPolicy()),
+			persistence.NewPersistenceRetryer(targetExecManager, targetHistoryManager, c2.CreatePersistenceRetryPolicy()),
+		)
+		result := i.Check(context.Background(), getOpenConcreteExecution())
+		s.Equal(tc.expectedResult, result)
+	}
+}
+
+func getMutableStateResponse(
+	nextEventID int64,
+	numActivities int,
+) *persistence.GetWorkflowExecutionResponse {
+	activityInfos := make(map[int64]*persistence.ActivityInfo)
+	for i := 0; i < numActivities; i++ {
+		activityInfos[int64(i)] = &persistence.ActivityInfo{}
+	}
+	return &persistence.GetWorkflowExecutionResponse{
+		State: &persistence.WorkflowMutableState{
+			ExecutionInfo: &persistence.WorkflowExecutionInfo{
+				NextEventID: nextEventID,
+				State:       openState,
+			},
+			ActivityInfos: activityInfos,
+		},
+	}
+}
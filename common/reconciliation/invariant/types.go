@@ -48,6 +48,8 @@ const (
 	OpenCurrentExecution Name = "open_current_execution"
 	// ConcreteExecutionExists asserts that an open current execution must have a valid concrete execution
 	ConcreteExecutionExists Name = "concrete_execution_exists"
+	// MigratedExecutionMatches asserts that a concrete execution has an identical copy in the migration target store
+	MigratedExecutionMatches Name = "migrated_execution_matches"
 
 	// CollectionMutableState is the collection of invariants relating to mutable state
 	CollectionMutableState Collection = 0
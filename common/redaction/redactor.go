@@ -0,0 +1,373 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package redaction
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/uber/cadence/common"
+	"github.com/uber/cadence/common/persistence"
+	"github.com/uber/cadence/common/types"
+)
+
+type (
+	// Redactor masks the sensitive payloads and search attributes of API responses, the responses are
+	// redacted in place
+	Redactor struct {
+		// paths are the JSON paths masked in the payloads, split into segments
+		paths [][]string
+		// redactAll masks the payloads entirely
+		redactAll        bool
+		searchAttributes map[string]bool
+	}
+)
+
+const (
+	// RedactedValue replaces the redacted JSON values and search attributes
+	RedactedValue = "[REDACTED]"
+
+	listSeparator = " "
+	pathRoot      = "$"
+	pathSeparator = "."
+	wildcard      = "*"
+)
+
+var redactedJSON, _ = json.Marshal(RedactedValue)
+
+// NewRedactor creates the redactor configured by the domain data, nil if the domain doesn't redact anything.
+// Paths are made of the field names separated by dots, optionally prefixed by $. The * wildcard matches any
+// field or array element, the other segments apply to every element of arrays, and the path $ masks the
+// whole payload.
+func NewRedactor(domainData map[string]string) *Redactor {
+	paths := splitList(domainData[common.DomainDataKeyForRedactedPaths])
+	searchAttributes := splitList(domainData[common.DomainDataKeyForRedactedSearchAttributes])
+	if len(paths) == 0 && len(searchAttributes) == 0 {
+		return nil
+	}
+
+	r := &Redactor{searchAttributes: make(map[string]bool, len(searchAttributes))}
+	for _, path := range paths {
+		segments := parsePath(path)
+		if len(segments) == 0 {
+			r.redactAll = true
+			continue
+		}
+		r.paths = append(r.paths, segments)
+	}
+	for _, key := range searchAttributes {
+		r.searchAttributes[key] = true
+	}
+	return r
+}
+
+// NewFullRedactor creates a redactor masking all the payloads and search attributes
+func NewFullRedactor() *Redactor {
+	return &Redactor{
+		redactAll:        true,
+		searchAttributes: map[string]bool{wildcard: true},
+	}
+}
+
+// IsRedacted returns whether the payload or search attribute value has been masked entirely
+func IsRedacted(value []byte) bool {
+	return bytes.Equal(bytes.TrimSpace(value), redactedJSON)
+}
+
+// RedactPayload returns the payload with the configured JSON paths masked. Payloads are sequences of
+// JSON values, one per argument, the payloads which are not JSON are masked entirely.
+func (r *Redactor) RedactPayload(payload []byte) []byte {
+	if len(payload) == 0 {
+		return payload
+	}
+	if r.redactAll {
+		return redacted()
+	}
+	if len(r.paths) == 0 {
+		return payload
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(payload))
+	decoder.UseNumber()
+	var values []interface{}
+	for {
+		var value interface{}
+		err := decoder.Decode(&value)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return redacted()
+		}
+		values = append(values, value)
+	}
+
+	isRedacted := false
+	for i := range values {
+		for _, path := range r.paths {
+			var ok bool
+			values[i], ok = redactPath(values[i], path)
+			isRedacted = isRedacted || ok
+		}
+	}
+	if !isRedacted {
+		return payload
+	}
+
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	for _, value := range values {
+		if err := encoder.Encode(value); err != nil {
+			return redacted()
+		}
+	}
+	return buf.Bytes()
+}
+
+// RedactSearchAttributes masks the configured search attributes
+func (r *Redactor) RedactSearchAttributes(searchAttributes *types.SearchAttributes) {
+	if searchAttributes == nil {
+		return
+	}
+	for key := range searchAttributes.IndexedFields {
+		if r.searchAttributes[wildcard] || r.searchAttributes[key] {
+			searchAttributes.IndexedFields[key] = redacted()
+		}
+	}
+}
+
+// RedactHistory masks the payloads and search attributes of the history events
+func (r *Redactor) RedactHistory(history *types.History) {
+	if history == nil {
+		return
+	}
+	for _, event := range history.Events {
+		r.RedactHistoryEvent(event)
+	}
+}
+
+// RedactHistoryEvent masks the payloads and search attributes of the history event
+func (r *Redactor) RedactHistoryEvent(event *types.HistoryEvent) {
+	if event == nil {
+		return
+	}
+	if attr := event.WorkflowExecutionStartedEventAttributes; attr != nil {
+		attr.Input = r.RedactPayload(attr.Input)
+		attr.ContinuedFailureDetails = r.RedactPayload(attr.ContinuedFailureDetails)
+		attr.LastCompletionResult = r.RedactPayload(attr.LastCompletionResult)
+		r.RedactSearchAttributes(attr.SearchAttributes)
+	}
+	if attr := event.WorkflowExecutionCompletedEventAttributes; attr != nil {
+		attr.Result = r.RedactPayload(attr.Result)
+	}
+	if attr := event.WorkflowExecutionFailedEventAttributes; attr != nil {
+		attr.Details = r.RedactPayload(attr.Details)
+	}
+	if attr := event.WorkflowExecutionContinuedAsNewEventAttributes; attr != nil {
+		attr.Input = r.RedactPayload(attr.Input)
+		attr.FailureDetails = r.RedactPayload(attr.FailureDetails)
+		attr.LastCompletionResult = r.RedactPayload(attr.LastCompletionResult)
+		r.RedactSearchAttributes(attr.SearchAttributes)
+	}
+	if attr := event.WorkflowExecutionSignaledEventAttributes; attr != nil {
+		attr.Input = r.RedactPayload(attr.Input)
+	}
+	if attr := event.WorkflowExecutionTerminatedEventAttributes; attr != nil {
+		attr.Details = r.RedactPayload(attr.Details)
+	}
+	if attr := event.WorkflowExecutionCanceledEventAttributes; attr != nil {
+		attr.Details = r.RedactPayload(attr.Details)
+	}
+	if attr := event.DecisionTaskFailedEventAttributes; attr != nil {
+		attr.Details = r.RedactPayload(attr.Details)
+	}
+	if attr := event.ActivityTaskScheduledEventAttributes; attr != nil {
+		attr.Input = r.RedactPayload(attr.Input)
+	}
+	if attr := event.ActivityTaskStartedEventAttributes; attr != nil {
+		attr.LastFailureDetails = r.RedactPayload(attr.LastFailureDetails)
+	}
+	if attr := event.ActivityTaskCompletedEventAttributes; attr != nil {
+		attr.Result = r.RedactPayload(attr.Result)
+	}
+	if attr := event.ActivityTaskFailedEventAttributes; attr != nil {
+		attr.Details = r.RedactPayload(attr.Details)
+	}
+	if attr := event.ActivityTaskTimedOutEventAttributes; attr != nil {
+		attr.Details = r.RedactPayload(attr.Details)
+		attr.LastFailureDetails = r.RedactPayload(attr.LastFailureDetails)
+	}
+	if attr := event.ActivityTaskCanceledEventAttributes; attr != nil {
+		attr.Details = r.RedactPayload(attr.Details)
+	}
+	if attr := event.MarkerRecordedEventAttributes; attr != nil {
+		attr.Details = r.RedactPayload(attr.Details)
+	}
+	if attr := event.StartChildWorkflowExecutionInitiatedEventAttributes; attr != nil {
+		attr.Input = r.RedactPayload(attr.Input)
+		r.RedactSearchAttributes(attr.SearchAttributes)
+	}
+	if attr := event.ChildWorkflowExecutionCompletedEventAttributes; attr != nil {
+		attr.Result = r.RedactPayload(attr.Result)
+	}
+	if attr := event.ChildWorkflowExecutionFailedEventAttributes; attr != nil {
+		attr.Details = r.RedactPayload(attr.Details)
+	}
+	if attr := event.ChildWorkflowExecutionCanceledEventAttributes; attr != nil {
+		attr.Details = r.RedactPayload(attr.Details)
+	}
+	if attr := event.SignalExternalWorkflowExecutionInitiatedEventAttributes; attr != nil {
+		attr.Input = r.RedactPayload(attr.Input)
+	}
+	if attr := event.UpsertWorkflowSearchAttributesEventAttributes; attr != nil {
+		r.RedactSearchAttributes(attr.SearchAttributes)
+	}
+}
+
+// RedactDescribeWorkflowExecutionResponse masks the search attributes of the workflow and the payloads
+// of its pending activities
+func (r *Redactor) RedactDescribeWorkflowExecutionResponse(resp *types.DescribeWorkflowExecutionResponse) {
+	if resp == nil {
+		return
+	}
+	r.RedactWorkflowExecutionInfos([]*types.WorkflowExecutionInfo{resp.WorkflowExecutionInfo})
+	for _, activity := range resp.PendingActivities {
+		if activity == nil {
+			continue
+		}
+		activity.HeartbeatDetails = r.RedactPayload(activity.HeartbeatDetails)
+		activity.LastFailureDetails = r.RedactPayload(activity.LastFailureDetails)
+	}
+}
+
+// RedactWorkflowExecutionInfos masks the search attributes of the workflows
+func (r *Redactor) RedactWorkflowExecutionInfos(executions []*types.WorkflowExecutionInfo) {
+	for _, execution := range executions {
+		if execution != nil {
+			r.RedactSearchAttributes(execution.SearchAttributes)
+		}
+	}
+}
+
+// RedactMutableState masks the payloads, memo and search attributes of the mutable state of the workflow
+// described by the admin API, along with the payloads of the events it keeps
+func (r *Redactor) RedactMutableState(state *persistence.WorkflowMutableState) {
+	if state == nil {
+		return
+	}
+	if info := state.ExecutionInfo; info != nil {
+		info.ExecutionContext = r.RedactPayload(info.ExecutionContext)
+		for key, value := range info.Memo {
+			info.Memo[key] = r.RedactPayload(value)
+		}
+		r.RedactSearchAttributes(&types.SearchAttributes{IndexedFields: info.SearchAttributes})
+		r.RedactHistoryEvent(info.CompletionEvent)
+	}
+	for _, activity := range state.ActivityInfos {
+		if activity == nil {
+			continue
+		}
+		r.RedactHistoryEvent(activity.ScheduledEvent)
+		r.RedactHistoryEvent(activity.StartedEvent)
+		activity.Details = r.RedactPayload(activity.Details)
+		activity.LastFailureDetails = r.RedactPayload(activity.LastFailureDetails)
+	}
+	for _, child := range state.ChildExecutionInfos {
+		if child == nil {
+			continue
+		}
+		r.RedactHistoryEvent(child.InitiatedEvent)
+		r.RedactHistoryEvent(child.StartedEvent)
+	}
+	for _, signal := range state.SignalInfos {
+		if signal != nil {
+			signal.Input = r.RedactPayload(signal.Input)
+		}
+	}
+	for _, event := range state.BufferedEvents {
+		r.RedactHistoryEvent(event)
+	}
+}
+
+// redactPath masks the values matching the path, returning the value and whether anything has been masked
+func redactPath(value interface{}, path []string) (interface{}, bool) {
+	if len(path) == 0 {
+		return RedactedValue, true
+	}
+	segment := path[0]
+	isRedacted := false
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, field := range v {
+			if segment == wildcard || segment == key {
+				var ok bool
+				v[key], ok = redactPath(field, path[1:])
+				isRedacted = isRedacted || ok
+			}
+		}
+	case []interface{}:
+		if index, err := strconv.Atoi(segment); err == nil {
+			if index < 0 || index >= len(v) {
+				return v, false
+			}
+			v[index], isRedacted = redactPath(v[index], path[1:])
+			return v, isRedacted
+		}
+		elementPath := path
+		if segment == wildcard {
+			elementPath = path[1:]
+		}
+		for i := range v {
+			var ok bool
+			v[i], ok = redactPath(v[i], elementPath)
+			isRedacted = isRedacted || ok
+		}
+	}
+	return value, isRedacted
+}
+
+func parsePath(path string) []string {
+	path = strings.TrimPrefix(path, pathRoot)
+	path = strings.TrimPrefix(path, pathSeparator)
+	if path == "" {
+		return nil
+	}
+	return strings.Split(path, pathSeparator)
+}
+
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, listSeparator) {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// redacted returns a copy of the redacted value, not to share the slice between responses
+func redacted() []byte {
+	return append([]byte(nil), redactedJSON...)
+}
@@ -0,0 +1,210 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package redaction
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/uber/cadence/common"
+	"github.com/uber/cadence/common/persistence"
+	"github.com/uber/cadence/common/types"
+)
+
+func TestNewRedactor(t *testing.T) {
+	assert.Nil(t, NewRedactor(nil))
+	assert.Nil(t, NewRedactor(map[string]string{common.DomainDataKeyForRedactedPaths: "  "}))
+
+	r := NewRedactor(map[string]string{
+		common.DomainDataKeyForRedactedPaths:            "$.card.number  ssn",
+		common.DomainDataKeyForRedactedSearchAttributes: "CustomerEmail",
+	})
+	assert.Equal(t, [][]string{{"card", "number"}, {"ssn"}}, r.paths)
+	assert.False(t, r.redactAll)
+	assert.Equal(t, map[string]bool{"CustomerEmail": true}, r.searchAttributes)
+
+	r = NewRedactor(map[string]string{common.DomainDataKeyForRedactedPaths: "$"})
+	assert.True(t, r.redactAll)
+}
+
+func TestRedactPayload(t *testing.T) {
+	testCases := []struct {
+		name     string
+		paths    string
+		payload  string
+		expected string
+	}{
+		{
+			name:     "empty payload",
+			paths:    "ssn",
+			payload:  "",
+			expected: "",
+		},
+		{
+			name:     "whole payload",
+			paths:    "$",
+			payload:  `{"ssn":"123"}`,
+			expected: `"[REDACTED]"`,
+		},
+		{
+			name:     "nested field",
+			paths:    "$.card.number",
+			payload:  `{"card":{"number":"4111","expiry":"12/30"},"amount":12.50}`,
+			expected: `{"amount":12.50,"card":{"expiry":"12/30","number":"[REDACTED]"}}` + "\n",
+		},
+		{
+			name:     "no match keeps payload unchanged",
+			paths:    "ssn",
+			payload:  `{"name": "x"}`,
+			expected: `{"name": "x"}`,
+		},
+		{
+			name:     "every argument",
+			paths:    "ssn",
+			payload:  "{\"ssn\":\"1\"}\n\"plain\"\n{\"ssn\":\"2\"}\n",
+			expected: "{\"ssn\":\"[REDACTED]\"}\n\"plain\"\n{\"ssn\":\"[REDACTED]\"}\n",
+		},
+		{
+			name:     "array elements",
+			paths:    "users.ssn",
+			payload:  `{"users":[{"ssn":"1"},{"ssn":"2","id":3}]}`,
+			expected: `{"users":[{"ssn":"[REDACTED]"},{"id":3,"ssn":"[REDACTED]"}]}` + "\n",
+		},
+		{
+			name:     "array index",
+			paths:    "users.1",
+			payload:  `{"users":["a","b"]}`,
+			expected: `{"users":["a","[REDACTED]"]}` + "\n",
+		},
+		{
+			name:     "wildcard",
+			paths:    "*.secret",
+			payload:  `{"a":{"secret":1},"b":{"secret":2}}`,
+			expected: `{"a":{"secret":"[REDACTED]"},"b":{"secret":"[REDACTED]"}}` + "\n",
+		},
+		{
+			name:     "not JSON",
+			paths:    "ssn",
+			payload:  "ssn=123",
+			expected: `"[REDACTED]"`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := NewRedactor(map[string]string{common.DomainDataKeyForRedactedPaths: tc.paths})
+			assert.Equal(t, tc.expected, string(r.RedactPayload([]byte(tc.payload))))
+		})
+	}
+}
+
+func TestRedactSearchAttributes(t *testing.T) {
+	r := NewRedactor(map[string]string{common.DomainDataKeyForRedactedSearchAttributes: "CustomerEmail"})
+	searchAttributes := &types.SearchAttributes{IndexedFields: map[string][]byte{
+		"CustomerEmail": []byte(`"a@b.c"`),
+		"CustomerID":    []byte(`12`),
+	}}
+	r.RedactSearchAttributes(searchAttributes)
+	assert.True(t, IsRedacted(searchAttributes.IndexedFields["CustomerEmail"]))
+	assert.Equal(t, `12`, string(searchAttributes.IndexedFields["CustomerID"]))
+
+	NewFullRedactor().RedactSearchAttributes(searchAttributes)
+	assert.True(t, IsRedacted(searchAttributes.IndexedFields["CustomerID"]))
+}
+
+func TestRedactHistory(t *testing.T) {
+	history := &types.History{Events: []*types.HistoryEvent{
+		{
+			WorkflowExecutionStartedEventAttributes: &types.WorkflowExecutionStartedEventAttributes{
+				Input: []byte(`{"ssn":"1"}`),
+				SearchAttributes: &types.SearchAttributes{IndexedFields: map[string][]byte{
+					"CustomerEmail": []byte(`"a@b.c"`),
+				}},
+			},
+		},
+		{
+			ActivityTaskCompletedEventAttributes: &types.ActivityTaskCompletedEventAttributes{
+				Result: []byte(`"done"`),
+			},
+		},
+		{
+			WorkflowExecutionSignaledEventAttributes: &types.WorkflowExecutionSignaledEventAttributes{
+				SignalName: "signal",
+				Input:      []byte(`{"ssn":"2"}`),
+			},
+		},
+	}}
+
+	NewFullRedactor().RedactHistory(history)
+	assert.True(t, IsRedacted(history.Events[0].WorkflowExecutionStartedEventAttributes.Input))
+	assert.True(t, IsRedacted(history.Events[0].WorkflowExecutionStartedEventAttributes.SearchAttributes.IndexedFields["CustomerEmail"]))
+	assert.True(t, IsRedacted(history.Events[1].ActivityTaskCompletedEventAttributes.Result))
+	assert.True(t, IsRedacted(history.Events[2].WorkflowExecutionSignaledEventAttributes.Input))
+	assert.Equal(t, "signal", history.Events[2].WorkflowExecutionSignaledEventAttributes.SignalName)
+}
+
+func TestRedactDescribeWorkflowExecutionResponse(t *testing.T) {
+	r := NewRedactor(map[string]string{common.DomainDataKeyForRedactedPaths: "ssn"})
+	resp := &types.DescribeWorkflowExecutionResponse{
+		WorkflowExecutionInfo: &types.WorkflowExecutionInfo{},
+		PendingActivities: []*types.PendingActivityInfo{
+			{HeartbeatDetails: []byte(`{"ssn":"1","progress":3}`)},
+		},
+	}
+	r.RedactDescribeWorkflowExecutionResponse(resp)
+	assert.Equal(t, `{"progress":3,"ssn":"[REDACTED]"}`+"\n", string(resp.PendingActivities[0].HeartbeatDetails))
+	assert.Nil(t, resp.PendingActivities[0].LastFailureDetails)
+}
+
+func TestRedactMutableState(t *testing.T) {
+	r := NewRedactor(map[string]string{
+		common.DomainDataKeyForRedactedPaths:            "ssn",
+		common.DomainDataKeyForRedactedSearchAttributes: "CustomerEmail",
+	})
+	state := &persistence.WorkflowMutableState{
+		ExecutionInfo: &persistence.WorkflowExecutionInfo{
+			Memo:             map[string][]byte{"customer": []byte(`{"ssn":"1"}`)},
+			SearchAttributes: map[string][]byte{"CustomerEmail": []byte(`"a@b.c"`)},
+		},
+		ActivityInfos: map[int64]*persistence.ActivityInfo{
+			5: {
+				ScheduledEvent: &types.HistoryEvent{
+					ActivityTaskScheduledEventAttributes: &types.ActivityTaskScheduledEventAttributes{
+						Input: []byte(`{"ssn":"2"}`),
+					},
+				},
+				Details: []byte(`{"ssn":"3"}`),
+			},
+		},
+		SignalInfos: map[int64]*persistence.SignalInfo{
+			6: {Input: []byte(`{"ssn":"4"}`)},
+		},
+	}
+
+	r.RedactMutableState(state)
+	redacted := `{"ssn":"[REDACTED]"}` + "\n"
+	assert.Equal(t, redacted, string(state.ExecutionInfo.Memo["customer"]))
+	assert.True(t, IsRedacted(state.ExecutionInfo.SearchAttributes["CustomerEmail"]))
+	assert.Equal(t, redacted, string(state.ActivityInfos[5].ScheduledEvent.ActivityTaskScheduledEventAttributes.Input))
+	assert.Equal(t, redacted, string(state.ActivityInfos[5].Details))
+	assert.Equal(t, redacted, string(state.SignalInfos[6].Input))
+}
@@ -67,6 +67,7 @@ type (
 		ArchiverProvider         provider.ArchiverProvider
 		Authorizer               authorization.Authorizer // NOTE: this can be nil. If nil, AccessControlledHandlerImpl will initiate one with config.Authorization
 		AuthorizationConfig      config.Authorization     // NOTE: empty(default) struct will get a authorization.NoopAuthorizer
+		AuditConfig              config.Audit             // NOTE: empty(default) struct disables the audit log
 	}
 
 	// MembershipMonitorFactory provides a bootstrapped membership monitor
@@ -49,6 +49,8 @@ const (
 	ClientImplHeaderName = "cadence-client-name"
 	// AuthorizationTokenHeaderName refers to the jwt token in the request
 	AuthorizationTokenHeaderName = "cadence-authorization"
+	// APIKeyHeaderName refers to the domain API key in the request
+	APIKeyHeaderName = "cadence-api-key"
 )
 
 type (
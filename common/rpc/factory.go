@@ -21,7 +21,6 @@
 package rpc
 
 import (
-	"crypto/tls"
 	"net"
 
 	"go.uber.org/yarpc"
@@ -30,8 +29,8 @@ import (
 	"go.uber.org/yarpc/peer/hostport"
 	"go.uber.org/yarpc/transport/grpc"
 	"go.uber.org/yarpc/transport/tchannel"
-	"google.golang.org/grpc/credentials"
 
+	"github.com/uber/cadence/common/certificate"
 	"github.com/uber/cadence/common/log"
 	"github.com/uber/cadence/common/log/tag"
 )
@@ -41,7 +40,7 @@ const defaultGRPCSizeLimit = 4 * 1024 * 1024
 // Factory is an implementation of common.RPCFactory interface
 type Factory struct {
 	maxMessageSize int
-	outboundTLS    map[string]*tls.Config
+	outboundTLS    map[string]*certificate.Reloader
 
 	logger            log.Logger
 	hostAddressMapper HostAddressMapper
@@ -87,7 +86,7 @@ func NewFactory(logger log.Logger, p Params) *Factory {
 
 		var inboundOptions []grpc.InboundOption
 		if p.InboundTLS != nil {
-			inboundOptions = append(inboundOptions, grpc.InboundCredentials(credentials.NewTLS(p.InboundTLS)))
+			inboundOptions = append(inboundOptions, grpc.InboundCredentials(newReloadingCredentials(p.InboundTLS)))
 		}
 
 		inbounds = append(inbounds, grpcTransport.NewInbound(listener, inboundOptions...))
@@ -149,8 +148,8 @@ func (d *Factory) CreateGRPCDispatcherForOutbound(
 	hostName string,
 ) (*yarpc.Dispatcher, error) {
 	// Service without TLS will return nil, which is ok here. We will create insecure dialer then.
-	tlsConfig := d.outboundTLS[serviceName]
-	outbound := d.grpc.NewOutbound(peer.NewSingle(hostport.PeerIdentifier(hostName), createDialer(d.grpc, tlsConfig)))
+	tlsReloader := d.outboundTLS[serviceName]
+	outbound := d.grpc.NewOutbound(peer.NewSingle(hostport.PeerIdentifier(hostName), createDialer(d.grpc, tlsReloader)))
 	return d.createOutboundDispatcher(callerName, serviceName, hostName, outbound)
 }
 
@@ -191,10 +190,10 @@ func (d *Factory) createOutboundDispatcher(
 	return dispatcher, nil
 }
 
-func createDialer(transport *grpc.Transport, tlsConfig *tls.Config) *grpc.Dialer {
+func createDialer(transport *grpc.Transport, tlsReloader *certificate.Reloader) *grpc.Dialer {
 	var dialOptions []grpc.DialOption
-	if tlsConfig != nil {
-		dialOptions = append(dialOptions, grpc.DialerCredentials(credentials.NewTLS(tlsConfig)))
+	if tlsReloader != nil {
+		dialOptions = append(dialOptions, grpc.DialerCredentials(newReloadingCredentials(tlsReloader)))
 	}
 	return transport.NewDialer(dialOptions...)
 }
@@ -24,7 +24,9 @@ import (
 	"fmt"
 
 	"github.com/uber/cadence/common/authorization"
+	"github.com/uber/cadence/common/certificate"
 	"github.com/uber/cadence/common/config"
+	"github.com/uber/cadence/common/log"
 	"github.com/uber/cadence/common/service"
 
 	"go.uber.org/multierr"
@@ -113,10 +115,11 @@ func (b publicClientOutbound) Build(_ *grpc.Transport, tchannel *tchannel.Transp
 type crossDCOutbounds struct {
 	clusterGroup map[string]config.ClusterInformation
 	pcf          PeerChooserFactory
+	logger       log.Logger
 }
 
-func NewCrossDCOutbounds(clusterGroup map[string]config.ClusterInformation, pcf PeerChooserFactory) OutboundsBuilder {
-	return crossDCOutbounds{clusterGroup, pcf}
+func NewCrossDCOutbounds(clusterGroup map[string]config.ClusterInformation, pcf PeerChooserFactory, logger log.Logger) OutboundsBuilder {
+	return crossDCOutbounds{clusterGroup, pcf, logger}
 }
 
 func (b crossDCOutbounds) Build(grpcTransport *grpc.Transport, tchannelTransport *tchannel.Transport) (yarpc.Outbounds, error) {
@@ -135,11 +138,11 @@ func (b crossDCOutbounds) Build(grpcTransport *grpc.Transport, tchannelTransport
 			}
 			outbound = tchannelTransport.NewOutbound(peerChooser)
 		case grpc.TransportName:
-			tlsConfig, err := clusterInfo.TLS.ToTLSConfig()
+			tlsReloader, err := certificate.NewReloader(clusterInfo.TLS, b.logger)
 			if err != nil {
 				return nil, err
 			}
-			peerChooser, err := b.pcf.CreatePeerChooser(createDialer(grpcTransport, tlsConfig), clusterInfo.RPCAddress)
+			peerChooser, err := b.pcf.CreatePeerChooser(createDialer(grpcTransport, tlsReloader), clusterInfo.RPCAddress)
 			if err != nil {
 				return nil, err
 			}
@@ -26,6 +26,7 @@ import (
 	"testing"
 
 	"github.com/uber/cadence/common/config"
+	"github.com/uber/cadence/common/log"
 	"github.com/uber/cadence/common/service"
 
 	"github.com/stretchr/testify/assert"
@@ -128,13 +129,13 @@ func TestCrossDCOutbounds(t *testing.T) {
 	clusterGroup := map[string]config.ClusterInformation{
 		"cluster-A": {Enabled: true, RPCName: "cadence-frontend", RPCTransport: "invalid"},
 	}
-	_, err := NewCrossDCOutbounds(clusterGroup, &fakePeerChooserFactory{}).Build(grpc, tchannel)
+	_, err := NewCrossDCOutbounds(clusterGroup, &fakePeerChooserFactory{}, log.NewNoop()).Build(grpc, tchannel)
 	assert.EqualError(t, err, "unknown cross DC transport type: invalid")
 
 	clusterGroup = map[string]config.ClusterInformation{
 		"cluster-A": {Enabled: true, RPCName: "cadence-frontend", RPCTransport: "grpc", AuthorizationProvider: config.AuthorizationProvider{Enable: true, PrivateKey: "invalid path"}},
 	}
-	_, err = NewCrossDCOutbounds(clusterGroup, &fakePeerChooserFactory{}).Build(grpc, tchannel)
+	_, err = NewCrossDCOutbounds(clusterGroup, &fakePeerChooserFactory{}, log.NewNoop()).Build(grpc, tchannel)
 	assert.EqualError(t, err, "create AuthProvider: invalid private key path invalid path")
 
 	clusterGroup = map[string]config.ClusterInformation{
@@ -142,7 +143,7 @@ func TestCrossDCOutbounds(t *testing.T) {
 		"cluster-B": {Enabled: true, RPCName: "cadence-frontend", RPCAddress: "address-B", RPCTransport: "tchannel"},
 		"cluster-C": {Enabled: false},
 	}
-	outbounds, err := NewCrossDCOutbounds(clusterGroup, &fakePeerChooserFactory{}).Build(grpc, tchannel)
+	outbounds, err := NewCrossDCOutbounds(clusterGroup, &fakePeerChooserFactory{}, log.NewNoop()).Build(grpc, tchannel)
 	assert.NoError(t, err)
 	assert.Equal(t, 2, len(outbounds))
 	assert.Equal(t, "cadence-frontend", outbounds["cluster-A"].ServiceName)
@@ -21,13 +21,15 @@
 package rpc
 
 import (
-	"crypto/tls"
 	"fmt"
 	"net"
 
+	"github.com/uber/cadence/common/certificate"
 	"github.com/uber/cadence/common/config"
+	"github.com/uber/cadence/common/log"
 	"github.com/uber/cadence/common/service"
 
+	"github.com/uber-go/tally"
 	"go.uber.org/yarpc"
 )
 
@@ -39,8 +41,9 @@ type Params struct {
 	GRPCMaxMsgSize    int
 	HostAddressMapper HostAddressMapper
 
-	InboundTLS  *tls.Config
-	OutboundTLS map[string]*tls.Config
+	// InboundTLS and OutboundTLS reload the certificates when the files change, nil means no TLS
+	InboundTLS  *certificate.Reloader
+	OutboundTLS map[string]*certificate.Reloader
 
 	InboundMiddleware  yarpc.InboundMiddleware
 	OutboundMiddleware yarpc.OutboundMiddleware
@@ -49,7 +52,7 @@ type Params struct {
 }
 
 // NewParams creates parameters for rpc.Factory from the given config
-func NewParams(serviceName string, config *config.Config) (Params, error) {
+func NewParams(serviceName string, config *config.Config, logger log.Logger) (Params, error) {
 	serviceConfig, err := config.GetServiceConfig(serviceName)
 	if err != nil {
 		return Params{}, err
@@ -60,17 +63,17 @@ func NewParams(serviceName string, config *config.Config) (Params, error) {
 		return Params{}, fmt.Errorf("get listen IP: %v", err)
 	}
 
-	inboundTLS, err := serviceConfig.RPC.TLS.ToTLSConfig()
+	inboundTLS, err := certificate.NewReloader(serviceConfig.RPC.TLS, logger)
 	if err != nil {
 		return Params{}, fmt.Errorf("inbound TLS config: %v", err)
 	}
-	outboundTLS := map[string]*tls.Config{}
+	outboundTLS := map[string]*certificate.Reloader{}
 	for _, outboundServiceName := range service.List {
 		outboundServiceConfig, err := config.GetServiceConfig(outboundServiceName)
 		if err != nil {
 			continue
 		}
-		outboundTLS[outboundServiceName], err = outboundServiceConfig.RPC.TLS.ToTLSConfig()
+		outboundTLS[outboundServiceName], err = certificate.NewReloader(outboundServiceConfig.RPC.TLS, logger)
 		if err != nil {
 			return Params{}, fmt.Errorf("outbound %s TLS config: %v", outboundServiceName, err)
 		}
@@ -114,3 +117,16 @@ func getListenIP(config config.RPC) (net.IP, error) {
 	}
 	return ListenIP()
 }
+
+// WatchCertificates checks the TLS files of the inbound and the outbounds for changes until doneCh is closed,
+// and reports how long the certificates are still valid
+func (p Params) WatchCertificates(scope tally.Scope, doneCh chan struct{}) {
+	if p.InboundTLS != nil {
+		p.InboundTLS.Watch(scope, doneCh)
+	}
+	for _, reloader := range p.OutboundTLS {
+		if reloader != nil {
+			reloader.Watch(scope, doneCh)
+		}
+	}
+}
@@ -27,6 +27,7 @@ import (
 	"github.com/stretchr/testify/assert"
 
 	"github.com/uber/cadence/common/config"
+	"github.com/uber/cadence/common/log"
 	"github.com/uber/cadence/common/service"
 )
 
@@ -38,28 +39,28 @@ func TestNewParams(t *testing.T) {
 			Services:     map[string]config.Service{"frontend": svc}}
 	}
 
-	_, err := NewParams(serviceName, &config.Config{})
+	_, err := NewParams(serviceName, &config.Config{}, log.NewNoop())
 	assert.EqualError(t, err, "no config section for service: frontend")
 
-	_, err = NewParams(serviceName, makeConfig(config.Service{RPC: config.RPC{BindOnLocalHost: true, BindOnIP: "1.2.3.4"}}))
+	_, err = NewParams(serviceName, makeConfig(config.Service{RPC: config.RPC{BindOnLocalHost: true, BindOnIP: "1.2.3.4"}}), log.NewNoop())
 	assert.EqualError(t, err, "get listen IP: bindOnLocalHost and bindOnIP are mutually exclusive")
 
-	_, err = NewParams(serviceName, makeConfig(config.Service{RPC: config.RPC{BindOnIP: "invalidIP"}}))
+	_, err = NewParams(serviceName, makeConfig(config.Service{RPC: config.RPC{BindOnIP: "invalidIP"}}), log.NewNoop())
 	assert.EqualError(t, err, "get listen IP: unable to parse bindOnIP value or it is not an IPv4 address: invalidIP")
 
-	_, err = NewParams(serviceName, &config.Config{Services: map[string]config.Service{"frontend": {}}})
+	_, err = NewParams(serviceName, &config.Config{Services: map[string]config.Service{"frontend": {}}}, log.NewNoop())
 	assert.EqualError(t, err, "public client outbound: need to provide an endpoint config for PublicClient")
 
-	_, err = NewParams(serviceName, makeConfig(config.Service{RPC: config.RPC{BindOnLocalHost: true, TLS: config.TLS{Enabled: true, CertFile: "invalid", KeyFile: "invalid"}}}))
+	_, err = NewParams(serviceName, makeConfig(config.Service{RPC: config.RPC{BindOnLocalHost: true, TLS: config.TLS{Enabled: true, CertFile: "invalid", KeyFile: "invalid"}}}), log.NewNoop())
 	assert.EqualError(t, err, "inbound TLS config: open invalid: no such file or directory")
 
 	_, err = NewParams(serviceName, &config.Config{Services: map[string]config.Service{
 		"frontend": {RPC: config.RPC{BindOnLocalHost: true}},
 		"history":  {RPC: config.RPC{TLS: config.TLS{Enabled: true, CaFile: "invalid"}}},
-	}})
+	}}, log.NewNoop())
 	assert.EqualError(t, err, "outbound cadence-history TLS config: open invalid: no such file or directory")
 
-	params, err := NewParams(serviceName, makeConfig(config.Service{RPC: config.RPC{BindOnLocalHost: true, Port: 1111, GRPCPort: 2222, GRPCMaxMsgSize: 3333}}))
+	params, err := NewParams(serviceName, makeConfig(config.Service{RPC: config.RPC{BindOnLocalHost: true, Port: 1111, GRPCPort: 2222, GRPCMaxMsgSize: 3333}}), log.NewNoop())
 	assert.NoError(t, err)
 	assert.Equal(t, "127.0.0.1:1111", params.TChannelAddress)
 	assert.Equal(t, "127.0.0.1:2222", params.GRPCAddress)
@@ -67,11 +68,11 @@ func TestNewParams(t *testing.T) {
 	assert.Nil(t, params.InboundTLS)
 	assert.IsType(t, GRPCPorts{}, params.HostAddressMapper)
 
-	params, err = NewParams(serviceName, makeConfig(config.Service{RPC: config.RPC{BindOnIP: "1.2.3.4", GRPCPort: 2222}}))
+	params, err = NewParams(serviceName, makeConfig(config.Service{RPC: config.RPC{BindOnIP: "1.2.3.4", GRPCPort: 2222}}), log.NewNoop())
 	assert.NoError(t, err)
 	assert.Equal(t, "1.2.3.4:2222", params.GRPCAddress)
 
-	params, err = NewParams(serviceName, makeConfig(config.Service{RPC: config.RPC{GRPCPort: 2222, TLS: config.TLS{Enabled: true}}}))
+	params, err = NewParams(serviceName, makeConfig(config.Service{RPC: config.RPC{GRPCPort: 2222, TLS: config.TLS{Enabled: true}}}), log.NewNoop())
 	assert.NoError(t, err)
 	ip, port, err := net.SplitHostPort(params.GRPCAddress)
 	assert.NoError(t, err)
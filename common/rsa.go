@@ -21,6 +21,7 @@
 package common
 
 import (
+	"crypto"
 	"crypto/rsa"
 	"crypto/x509"
 	"encoding/pem"
@@ -80,3 +81,8 @@ func LoadRSAPrivateKey(path string) (*rsa.PrivateKey, error) {
 	}
 	return key.(*rsa.PrivateKey), err
 }
+
+// LoadPublicKey loads a PEM encoded RSA, ECDSA or Ed25519 public key
+func LoadPublicKey(path string) (crypto.PublicKey, error) {
+	return loadRSAKey(path, KeyTypePublic)
+}
@@ -2733,10 +2733,11 @@ func FromResetWorkflowExecutionRequest(t *types.ResetWorkflowExecutionRequest) *
 	if t == nil {
 		return nil
 	}
+	// the IDL has no field for PreservePendingActivityResults, it's carried by the reason
 	return &apiv1.ResetWorkflowExecutionRequest{
 		Domain:                t.Domain,
 		WorkflowExecution:     FromWorkflowExecution(t.WorkflowExecution),
-		Reason:                t.Reason,
+		Reason:                common.EncodeResetReason(t.Reason, t.PreservePendingActivityResults),
 		DecisionFinishEventId: t.DecisionFinishEventID,
 		RequestId:             t.RequestID,
 		SkipSignalReapply:     t.SkipSignalReapply,
@@ -2747,13 +2748,15 @@ func ToResetWorkflowExecutionRequest(t *apiv1.ResetWorkflowExecutionRequest) *ty
 	if t == nil {
 		return nil
 	}
+	reason, preservePendingActivityResults := common.DecodeResetReason(t.Reason)
 	return &types.ResetWorkflowExecutionRequest{
-		Domain:                t.Domain,
-		WorkflowExecution:     ToWorkflowExecution(t.WorkflowExecution),
-		Reason:                t.Reason,
-		DecisionFinishEventID: t.DecisionFinishEventId,
-		RequestID:             t.RequestId,
-		SkipSignalReapply:     t.SkipSignalReapply,
+		Domain:                         t.Domain,
+		WorkflowExecution:              ToWorkflowExecution(t.WorkflowExecution),
+		Reason:                         reason,
+		DecisionFinishEventID:          t.DecisionFinishEventId,
+		RequestID:                      t.RequestId,
+		SkipSignalReapply:              t.SkipSignalReapply,
+		PreservePendingActivityResults: preservePendingActivityResults,
 	}
 }
 
@@ -3298,6 +3301,8 @@ func FromSignalWithStartWorkflowExecutionRequest(t *types.SignalWithStartWorkflo
 	if t == nil {
 		return nil
 	}
+	// the IDL has no field for WorkflowIDConflictPolicy, it's carried by the header
+	header := common.EncodeWorkflowIDConflictPolicy(t.Header, t.WorkflowIDConflictPolicy)
 	return &apiv1.SignalWithStartWorkflowExecutionRequest{
 		StartRequest: &apiv1.StartWorkflowExecutionRequest{
 			Domain:                       t.Domain,
@@ -3314,7 +3319,7 @@ func FromSignalWithStartWorkflowExecutionRequest(t *types.SignalWithStartWorkflo
 			CronSchedule:                 t.CronSchedule,
 			Memo:                         FromMemo(t.Memo),
 			SearchAttributes:             FromSearchAttributes(t.SearchAttributes),
-			Header:                       FromHeader(t.Header),
+			Header:                       FromHeader(header),
 			DelayStart:                   secondsToDuration(t.DelayStartSeconds),
 		},
 		SignalName:  t.SignalName,
@@ -3327,6 +3332,7 @@ func ToSignalWithStartWorkflowExecutionRequest(t *apiv1.SignalWithStartWorkflowE
 	if t == nil {
 		return nil
 	}
+	header, workflowIDConflictPolicy := common.DecodeWorkflowIDConflictPolicy(ToHeader(t.StartRequest.Header))
 	return &types.SignalWithStartWorkflowExecutionRequest{
 		Domain:                              t.StartRequest.Domain,
 		WorkflowID:                          t.StartRequest.WorkflowId,
@@ -3345,7 +3351,8 @@ func ToSignalWithStartWorkflowExecutionRequest(t *apiv1.SignalWithStartWorkflowE
 		CronSchedule:                        t.StartRequest.CronSchedule,
 		Memo:                                ToMemo(t.StartRequest.Memo),
 		SearchAttributes:                    ToSearchAttributes(t.StartRequest.SearchAttributes),
-		Header:                              ToHeader(t.StartRequest.Header),
+		Header:                              header,
+		WorkflowIDConflictPolicy:            workflowIDConflictPolicy,
 		DelayStartSeconds:                   durationToSeconds(t.StartRequest.DelayStart),
 	}
 }
@@ -3568,6 +3575,8 @@ func FromStartWorkflowExecutionRequest(t *types.StartWorkflowExecutionRequest) *
 	if t == nil {
 		return nil
 	}
+	// the IDL has no field for WorkflowIDConflictPolicy, it's carried by the header
+	header := common.EncodeWorkflowIDConflictPolicy(t.Header, t.WorkflowIDConflictPolicy)
 	return &apiv1.StartWorkflowExecutionRequest{
 		Domain:                       t.Domain,
 		WorkflowId:                   t.WorkflowID,
@@ -3583,7 +3592,7 @@ func FromStartWorkflowExecutionRequest(t *types.StartWorkflowExecutionRequest) *
 		CronSchedule:                 t.CronSchedule,
 		Memo:                         FromMemo(t.Memo),
 		SearchAttributes:             FromSearchAttributes(t.SearchAttributes),
-		Header:                       FromHeader(t.Header),
+		Header:                       FromHeader(header),
 		DelayStart:                   secondsToDuration(t.DelayStartSeconds),
 	}
 }
@@ -3592,6 +3601,7 @@ func ToStartWorkflowExecutionRequest(t *apiv1.StartWorkflowExecutionRequest) *ty
 	if t == nil {
 		return nil
 	}
+	header, workflowIDConflictPolicy := common.DecodeWorkflowIDConflictPolicy(ToHeader(t.Header))
 	return &types.StartWorkflowExecutionRequest{
 		Domain:                              t.Domain,
 		WorkflowID:                          t.WorkflowId,
@@ -3607,7 +3617,8 @@ func ToStartWorkflowExecutionRequest(t *apiv1.StartWorkflowExecutionRequest) *ty
 		CronSchedule:                        t.CronSchedule,
 		Memo:                                ToMemo(t.Memo),
 		SearchAttributes:                    ToSearchAttributes(t.SearchAttributes),
-		Header:                              ToHeader(t.Header),
+		Header:                              header,
+		WorkflowIDConflictPolicy:            workflowIDConflictPolicy,
 		DelayStartSeconds:                   durationToSeconds(t.DelayStart),
 	}
 }
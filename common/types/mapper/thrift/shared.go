@@ -21,6 +21,7 @@
 package thrift
 
 import (
+	"github.com/uber/cadence/common"
 	"github.com/uber/cadence/common/types"
 
 	"github.com/uber/cadence/.gen/go/shared"
@@ -4355,10 +4356,12 @@ func FromResetWorkflowExecutionRequest(t *types.ResetWorkflowExecutionRequest) *
 	if t == nil {
 		return nil
 	}
+	// the IDL has no field for PreservePendingActivityResults, it's carried by the reason
+	reason := common.EncodeResetReason(t.Reason, t.PreservePendingActivityResults)
 	return &shared.ResetWorkflowExecutionRequest{
 		Domain:                &t.Domain,
 		WorkflowExecution:     FromWorkflowExecution(t.WorkflowExecution),
-		Reason:                &t.Reason,
+		Reason:                &reason,
 		DecisionFinishEventId: &t.DecisionFinishEventID,
 		RequestId:             &t.RequestID,
 		SkipSignalReapply:     &t.SkipSignalReapply,
@@ -4370,13 +4373,15 @@ func ToResetWorkflowExecutionRequest(t *shared.ResetWorkflowExecutionRequest) *t
 	if t == nil {
 		return nil
 	}
+	reason, preservePendingActivityResults := common.DecodeResetReason(t.GetReason())
 	return &types.ResetWorkflowExecutionRequest{
-		Domain:                t.GetDomain(),
-		WorkflowExecution:     ToWorkflowExecution(t.WorkflowExecution),
-		Reason:                t.GetReason(),
-		DecisionFinishEventID: t.GetDecisionFinishEventId(),
-		RequestID:             t.GetRequestId(),
-		SkipSignalReapply:     t.GetSkipSignalReapply(),
+		Domain:                         t.GetDomain(),
+		WorkflowExecution:              ToWorkflowExecution(t.WorkflowExecution),
+		Reason:                         reason,
+		DecisionFinishEventID:          t.GetDecisionFinishEventId(),
+		RequestID:                      t.GetRequestId(),
+		SkipSignalReapply:              t.GetSkipSignalReapply(),
+		PreservePendingActivityResults: preservePendingActivityResults,
 	}
 }
 
@@ -4949,6 +4954,8 @@ func FromSignalWithStartWorkflowExecutionRequest(t *types.SignalWithStartWorkflo
 	if t == nil {
 		return nil
 	}
+	// the IDL has no field for WorkflowIDConflictPolicy, it's carried by the header
+	header := common.EncodeWorkflowIDConflictPolicy(t.Header, t.WorkflowIDConflictPolicy)
 	return &shared.SignalWithStartWorkflowExecutionRequest{
 		Domain:                              &t.Domain,
 		WorkflowId:                          &t.WorkflowID,
@@ -4967,7 +4974,7 @@ func FromSignalWithStartWorkflowExecutionRequest(t *types.SignalWithStartWorkflo
 		CronSchedule:                        &t.CronSchedule,
 		Memo:                                FromMemo(t.Memo),
 		SearchAttributes:                    FromSearchAttributes(t.SearchAttributes),
-		Header:                              FromHeader(t.Header),
+		Header:                              FromHeader(header),
 	}
 }
 
@@ -4976,6 +4983,7 @@ func ToSignalWithStartWorkflowExecutionRequest(t *shared.SignalWithStartWorkflow
 	if t == nil {
 		return nil
 	}
+	header, workflowIDConflictPolicy := common.DecodeWorkflowIDConflictPolicy(ToHeader(t.Header))
 	return &types.SignalWithStartWorkflowExecutionRequest{
 		Domain:                              t.GetDomain(),
 		WorkflowID:                          t.GetWorkflowId(),
@@ -4994,7 +5002,8 @@ func ToSignalWithStartWorkflowExecutionRequest(t *shared.SignalWithStartWorkflow
 		CronSchedule:                        t.GetCronSchedule(),
 		Memo:                                ToMemo(t.Memo),
 		SearchAttributes:                    ToSearchAttributes(t.SearchAttributes),
-		Header:                              ToHeader(t.Header),
+		Header:                              header,
+		WorkflowIDConflictPolicy:            workflowIDConflictPolicy,
 	}
 }
 
@@ -5209,6 +5218,8 @@ func FromStartWorkflowExecutionRequest(t *types.StartWorkflowExecutionRequest) *
 	if t == nil {
 		return nil
 	}
+	// the IDL has no field for WorkflowIDConflictPolicy, it's carried by the header
+	header := common.EncodeWorkflowIDConflictPolicy(t.Header, t.WorkflowIDConflictPolicy)
 	return &shared.StartWorkflowExecutionRequest{
 		Domain:                              &t.Domain,
 		WorkflowId:                          &t.WorkflowID,
@@ -5224,7 +5235,7 @@ func FromStartWorkflowExecutionRequest(t *types.StartWorkflowExecutionRequest) *
 		CronSchedule:                        &t.CronSchedule,
 		Memo:                                FromMemo(t.Memo),
 		SearchAttributes:                    FromSearchAttributes(t.SearchAttributes),
-		Header:                              FromHeader(t.Header),
+		Header:                              FromHeader(header),
 		DelayStartSeconds:                   t.DelayStartSeconds,
 	}
 }
@@ -5234,6 +5245,7 @@ func ToStartWorkflowExecutionRequest(t *shared.StartWorkflowExecutionRequest) *t
 	if t == nil {
 		return nil
 	}
+	header, workflowIDConflictPolicy := common.DecodeWorkflowIDConflictPolicy(ToHeader(t.Header))
 	return &types.StartWorkflowExecutionRequest{
 		Domain:                              t.GetDomain(),
 		WorkflowID:                          t.GetWorkflowId(),
@@ -5249,7 +5261,8 @@ func ToStartWorkflowExecutionRequest(t *shared.StartWorkflowExecutionRequest) *t
 		CronSchedule:                        t.GetCronSchedule(),
 		Memo:                                ToMemo(t.Memo),
 		SearchAttributes:                    ToSearchAttributes(t.SearchAttributes),
-		Header:                              ToHeader(t.Header),
+		Header:                              header,
+		WorkflowIDConflictPolicy:            workflowIDConflictPolicy,
 		DelayStartSeconds:                   t.DelayStartSeconds,
 	}
 }
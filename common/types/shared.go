@@ -6605,12 +6605,13 @@ type ResetStickyTaskListResponse struct {
 
 // ResetWorkflowExecutionRequest is an internal type (TBD...)
 type ResetWorkflowExecutionRequest struct {
-	Domain                string             `json:"domain,omitempty"`
-	WorkflowExecution     *WorkflowExecution `json:"workflowExecution,omitempty"`
-	Reason                string             `json:"reason,omitempty"`
-	DecisionFinishEventID int64              `json:"decisionFinishEventId,omitempty"`
-	RequestID             string             `json:"requestId,omitempty"`
-	SkipSignalReapply     bool               `json:"skipSignalReapply,omitempty"`
+	Domain                         string             `json:"domain,omitempty"`
+	WorkflowExecution              *WorkflowExecution `json:"workflowExecution,omitempty"`
+	Reason                         string             `json:"reason,omitempty"`
+	DecisionFinishEventID          int64              `json:"decisionFinishEventId,omitempty"`
+	RequestID                      string             `json:"requestId,omitempty"`
+	SkipSignalReapply              bool               `json:"skipSignalReapply,omitempty"`
+	PreservePendingActivityResults bool               `json:"preservePendingActivityResults,omitempty"`
 }
 
 // GetDomain is an internal getter (TBD...)
@@ -6661,6 +6662,14 @@ func (v *ResetWorkflowExecutionRequest) GetSkipSignalReapply() (o bool) {
 	return
 }
 
+// GetPreservePendingActivityResults is an internal getter (TBD...)
+func (v *ResetWorkflowExecutionRequest) GetPreservePendingActivityResults() (o bool) {
+	if v != nil {
+		return v.PreservePendingActivityResults
+	}
+	return
+}
+
 // ResetWorkflowExecutionResponse is an internal type (TBD...)
 type ResetWorkflowExecutionResponse struct {
 	RunID string `json:"runId,omitempty"`
@@ -7665,25 +7674,26 @@ func (v *SignalExternalWorkflowExecutionInitiatedEventAttributes) GetChildWorkfl
 
 // SignalWithStartWorkflowExecutionRequest is an internal type (TBD...)
 type SignalWithStartWorkflowExecutionRequest struct {
-	Domain                              string                 `json:"domain,omitempty"`
-	WorkflowID                          string                 `json:"workflowId,omitempty"`
-	WorkflowType                        *WorkflowType          `json:"workflowType,omitempty"`
-	TaskList                            *TaskList              `json:"taskList,omitempty"`
-	Input                               []byte                 `json:"input,omitempty"`
-	ExecutionStartToCloseTimeoutSeconds *int32                 `json:"executionStartToCloseTimeoutSeconds,omitempty"`
-	TaskStartToCloseTimeoutSeconds      *int32                 `json:"taskStartToCloseTimeoutSeconds,omitempty"`
-	Identity                            string                 `json:"identity,omitempty"`
-	RequestID                           string                 `json:"requestId,omitempty"`
-	WorkflowIDReusePolicy               *WorkflowIDReusePolicy `json:"workflowIdReusePolicy,omitempty"`
-	SignalName                          string                 `json:"signalName,omitempty"`
-	SignalInput                         []byte                 `json:"signalInput,omitempty"`
-	Control                             []byte                 `json:"control,omitempty"`
-	RetryPolicy                         *RetryPolicy           `json:"retryPolicy,omitempty"`
-	CronSchedule                        string                 `json:"cronSchedule,omitempty"`
-	Memo                                *Memo                  `json:"memo,omitempty"`
-	SearchAttributes                    *SearchAttributes      `json:"searchAttributes,omitempty"`
-	Header                              *Header                `json:"header,omitempty"`
-	DelayStartSeconds                   *int32                 `json:"delayStartSeconds,omitempty"`
+	Domain                              string                    `json:"domain,omitempty"`
+	WorkflowID                          string                    `json:"workflowId,omitempty"`
+	WorkflowType                        *WorkflowType             `json:"workflowType,omitempty"`
+	TaskList                            *TaskList                 `json:"taskList,omitempty"`
+	Input                               []byte                    `json:"input,omitempty"`
+	ExecutionStartToCloseTimeoutSeconds *int32                    `json:"executionStartToCloseTimeoutSeconds,omitempty"`
+	TaskStartToCloseTimeoutSeconds      *int32                    `json:"taskStartToCloseTimeoutSeconds,omitempty"`
+	Identity                            string                    `json:"identity,omitempty"`
+	RequestID                           string                    `json:"requestId,omitempty"`
+	WorkflowIDReusePolicy               *WorkflowIDReusePolicy    `json:"workflowIdReusePolicy,omitempty"`
+	SignalName                          string                    `json:"signalName,omitempty"`
+	SignalInput                         []byte                    `json:"signalInput,omitempty"`
+	Control                             []byte                    `json:"control,omitempty"`
+	RetryPolicy                         *RetryPolicy              `json:"retryPolicy,omitempty"`
+	CronSchedule                        string                    `json:"cronSchedule,omitempty"`
+	Memo                                *Memo                     `json:"memo,omitempty"`
+	SearchAttributes                    *SearchAttributes         `json:"searchAttributes,omitempty"`
+	Header                              *Header                   `json:"header,omitempty"`
+	DelayStartSeconds                   *int32                    `json:"delayStartSeconds,omitempty"`
+	WorkflowIDConflictPolicy            *WorkflowIDConflictPolicy `json:"workflowIdConflictPolicy,omitempty"`
 }
 
 // GetDomain is an internal getter (TBD...)
@@ -7774,6 +7784,14 @@ func (v *SignalWithStartWorkflowExecutionRequest) GetWorkflowIDReusePolicy() (o
 	return
 }
 
+// GetWorkflowIDConflictPolicy is an internal getter (TBD...)
+func (v *SignalWithStartWorkflowExecutionRequest) GetWorkflowIDConflictPolicy() (o WorkflowIDConflictPolicy) {
+	if v != nil && v.WorkflowIDConflictPolicy != nil {
+		return *v.WorkflowIDConflictPolicy
+	}
+	return
+}
+
 // GetSignalName is an internal getter (TBD...)
 func (v *SignalWithStartWorkflowExecutionRequest) GetSignalName() (o string) {
 	if v != nil {
@@ -8314,22 +8332,23 @@ func (v *StartTimerDecisionAttributes) GetStartToFireTimeoutSeconds() (o int64)
 
 // StartWorkflowExecutionRequest is an internal type (TBD...)
 type StartWorkflowExecutionRequest struct {
-	Domain                              string                 `json:"domain,omitempty"`
-	WorkflowID                          string                 `json:"workflowId,omitempty"`
-	WorkflowType                        *WorkflowType          `json:"workflowType,omitempty"`
-	TaskList                            *TaskList              `json:"taskList,omitempty"`
-	Input                               []byte                 `json:"input,omitempty"`
-	ExecutionStartToCloseTimeoutSeconds *int32                 `json:"executionStartToCloseTimeoutSeconds,omitempty"`
-	TaskStartToCloseTimeoutSeconds      *int32                 `json:"taskStartToCloseTimeoutSeconds,omitempty"`
-	Identity                            string                 `json:"identity,omitempty"`
-	RequestID                           string                 `json:"requestId,omitempty"`
-	WorkflowIDReusePolicy               *WorkflowIDReusePolicy `json:"workflowIdReusePolicy,omitempty"`
-	RetryPolicy                         *RetryPolicy           `json:"retryPolicy,omitempty"`
-	CronSchedule                        string                 `json:"cronSchedule,omitempty"`
-	Memo                                *Memo                  `json:"memo,omitempty"`
-	SearchAttributes                    *SearchAttributes      `json:"searchAttributes,omitempty"`
-	Header                              *Header                `json:"header,omitempty"`
-	DelayStartSeconds                   *int32                 `json:"delayStartSeconds,omitempty"`
+	Domain                              string                    `json:"domain,omitempty"`
+	WorkflowID                          string                    `json:"workflowId,omitempty"`
+	WorkflowType                        *WorkflowType             `json:"workflowType,omitempty"`
+	TaskList                            *TaskList                 `json:"taskList,omitempty"`
+	Input                               []byte                    `json:"input,omitempty"`
+	ExecutionStartToCloseTimeoutSeconds *int32                    `json:"executionStartToCloseTimeoutSeconds,omitempty"`
+	TaskStartToCloseTimeoutSeconds      *int32                    `json:"taskStartToCloseTimeoutSeconds,omitempty"`
+	Identity                            string                    `json:"identity,omitempty"`
+	RequestID                           string                    `json:"requestId,omitempty"`
+	WorkflowIDReusePolicy               *WorkflowIDReusePolicy    `json:"workflowIdReusePolicy,omitempty"`
+	RetryPolicy                         *RetryPolicy              `json:"retryPolicy,omitempty"`
+	CronSchedule                        string                    `json:"cronSchedule,omitempty"`
+	Memo                                *Memo                     `json:"memo,omitempty"`
+	SearchAttributes                    *SearchAttributes         `json:"searchAttributes,omitempty"`
+	Header                              *Header                   `json:"header,omitempty"`
+	DelayStartSeconds                   *int32                    `json:"delayStartSeconds,omitempty"`
+	WorkflowIDConflictPolicy            *WorkflowIDConflictPolicy `json:"workflowIdConflictPolicy,omitempty"`
 }
 
 // GetDomain is an internal getter (TBD...)
@@ -8420,6 +8439,14 @@ func (v *StartWorkflowExecutionRequest) GetWorkflowIDReusePolicy() (o WorkflowID
 	return
 }
 
+// GetWorkflowIDConflictPolicy is an internal getter (TBD...)
+func (v *StartWorkflowExecutionRequest) GetWorkflowIDConflictPolicy() (o WorkflowIDConflictPolicy) {
+	if v != nil && v.WorkflowIDConflictPolicy != nil {
+		return *v.WorkflowIDConflictPolicy
+	}
+	return
+}
+
 // GetRetryPolicy is an internal getter (TBD...)
 func (v *StartWorkflowExecutionRequest) GetRetryPolicy() (o *RetryPolicy) {
 	if v != nil && v.RetryPolicy != nil {
@@ -10158,6 +10185,65 @@ const (
 	WorkflowIDReusePolicyTerminateIfRunning
 )
 
+// WorkflowIDConflictPolicy decides how a start request is handled when a run of the workflow ID is still running,
+// unlike WorkflowIDReusePolicy which decides whether a closed run of the workflow ID can be reused
+type WorkflowIDConflictPolicy int32
+
+// Ptr is a helper function for getting pointer value
+func (e WorkflowIDConflictPolicy) Ptr() *WorkflowIDConflictPolicy {
+	return &e
+}
+
+// String returns a readable string representation of WorkflowIDConflictPolicy.
+func (e WorkflowIDConflictPolicy) String() string {
+	w := int32(e)
+	switch w {
+	case 0:
+		return "Fail"
+	case 1:
+		return "UseExisting"
+	case 2:
+		return "TerminateExisting"
+	}
+	return fmt.Sprintf("WorkflowIDConflictPolicy(%d)", w)
+}
+
+// UnmarshalText parses enum value from string representation
+func (e *WorkflowIDConflictPolicy) UnmarshalText(value []byte) error {
+	switch s := strings.ToUpper(string(value)); s {
+	case "FAIL":
+		*e = WorkflowIDConflictPolicyFail
+		return nil
+	case "USEEXISTING":
+		*e = WorkflowIDConflictPolicyUseExisting
+		return nil
+	case "TERMINATEEXISTING":
+		*e = WorkflowIDConflictPolicyTerminateExisting
+		return nil
+	default:
+		val, err := strconv.ParseInt(s, 10, 32)
+		if err != nil {
+			return fmt.Errorf("unknown enum value %q for %q: %v", s, "WorkflowIDConflictPolicy", err)
+		}
+		*e = WorkflowIDConflictPolicy(val)
+		return nil
+	}
+}
+
+// MarshalText encodes WorkflowIDConflictPolicy to text.
+func (e WorkflowIDConflictPolicy) MarshalText() ([]byte, error) {
+	return []byte(e.String()), nil
+}
+
+const (
+	// WorkflowIDConflictPolicyFail is an option for WorkflowIDConflictPolicy
+	WorkflowIDConflictPolicyFail WorkflowIDConflictPolicy = iota
+	// WorkflowIDConflictPolicyUseExisting is an option for WorkflowIDConflictPolicy
+	WorkflowIDConflictPolicyUseExisting
+	// WorkflowIDConflictPolicyTerminateExisting is an option for WorkflowIDConflictPolicy
+	WorkflowIDConflictPolicyTerminateExisting
+)
+
 // WorkflowQuery is an internal type (TBD...)
 type WorkflowQuery struct {
 	QueryType string `json:"queryType,omitempty"`
@@ -319,6 +319,7 @@ var (
 		Memo:                                &Memo,
 		SearchAttributes:                    &SearchAttributes,
 		Header:                              &Header,
+		WorkflowIDConflictPolicy:            &WorkflowIDConflictPolicy,
 	}
 	StartWorkflowExecutionResponse = types.StartWorkflowExecutionResponse{
 		RunID: RunID,
@@ -351,14 +352,16 @@ var (
 		Memo:                                &Memo,
 		SearchAttributes:                    &SearchAttributes,
 		Header:                              &Header,
+		WorkflowIDConflictPolicy:            &WorkflowIDConflictPolicy,
 	}
 	ResetWorkflowExecutionRequest = types.ResetWorkflowExecutionRequest{
-		Domain:                DomainName,
-		WorkflowExecution:     &WorkflowExecution,
-		Reason:                Reason,
-		DecisionFinishEventID: EventID1,
-		RequestID:             RequestID,
-		SkipSignalReapply:     true,
+		Domain:                         DomainName,
+		WorkflowExecution:              &WorkflowExecution,
+		Reason:                         Reason,
+		DecisionFinishEventID:          EventID1,
+		RequestID:                      RequestID,
+		SkipSignalReapply:              true,
+		PreservePendingActivityResults: true,
 	}
 	ResetWorkflowExecutionResponse = types.ResetWorkflowExecutionResponse{
 		RunID: RunID,
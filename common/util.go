@@ -998,3 +998,64 @@ func ConvertGetTaskFailedCauseToErr(failedCause types.GetTaskFailedCause) error
 		return &types.InternalServiceError{Message: "uncategorized error"}
 	}
 }
+
+// IsPauseSignalName returns whether the signal name is reserved to pause or unpause workflows
+func IsPauseSignalName(signalName string) bool {
+	return signalName == PauseWorkflowSignalName || signalName == UnpauseWorkflowSignalName
+}
+
+// EncodeResetReason returns the reason of a reset request carrying whether the reset preserves the results
+// of the activities pending at the reset point
+func EncodeResetReason(reason string, preservePendingActivityResults bool) string {
+	if !preservePendingActivityResults {
+		return reason
+	}
+	return ResetReasonPreservePendingActivityResultsPrefix + reason
+}
+
+// DecodeResetReason returns the reason of a reset request and whether the reset preserves the results
+// of the activities pending at the reset point
+func DecodeResetReason(reason string) (string, bool) {
+	if !strings.HasPrefix(reason, ResetReasonPreservePendingActivityResultsPrefix) {
+		return reason, false
+	}
+	return strings.TrimPrefix(reason, ResetReasonPreservePendingActivityResultsPrefix), true
+}
+
+// EncodeWorkflowIDConflictPolicy returns the header of a start request carrying the workflow ID conflict policy
+func EncodeWorkflowIDConflictPolicy(header *types.Header, policy *types.WorkflowIDConflictPolicy) *types.Header {
+	if policy == nil {
+		return header
+	}
+	fields := make(map[string][]byte, len(header.GetFields())+1)
+	for key, value := range header.GetFields() {
+		fields[key] = value
+	}
+	fields[WorkflowIDConflictPolicyHeaderKey] = []byte(policy.String())
+	return &types.Header{Fields: fields}
+}
+
+// DecodeWorkflowIDConflictPolicy returns the header of a start request without the workflow ID conflict policy,
+// and the policy if it's carried by the header
+func DecodeWorkflowIDConflictPolicy(header *types.Header) (*types.Header, *types.WorkflowIDConflictPolicy) {
+	value, ok := header.GetFields()[WorkflowIDConflictPolicyHeaderKey]
+	if !ok {
+		return header, nil
+	}
+	var policy *types.WorkflowIDConflictPolicy
+	var p types.WorkflowIDConflictPolicy
+	if err := p.UnmarshalText(value); err == nil {
+		policy = &p
+	}
+
+	fields := make(map[string][]byte, len(header.GetFields())-1)
+	for key, value := range header.GetFields() {
+		if key != WorkflowIDConflictPolicyHeaderKey {
+			fields[key] = value
+		}
+	}
+	if len(fields) == 0 {
+		return nil, policy
+	}
+	return &types.Header{Fields: fields}, policy
+}
@@ -245,3 +245,32 @@ func TestConvertErrToGetTaskFailedCause(t *testing.T) {
 		require.Equal(t, tc.expectedFailedCause, ConvertErrToGetTaskFailedCause(tc.err))
 	}
 }
+
+func TestEncodeDecodeResetReason(t *testing.T) {
+	reason, preservePendingActivityResults := DecodeResetReason(EncodeResetReason("some random reason", true))
+	require.True(t, preservePendingActivityResults)
+	require.Equal(t, "some random reason", reason)
+
+	reason, preservePendingActivityResults = DecodeResetReason(EncodeResetReason("some random reason", false))
+	require.False(t, preservePendingActivityResults)
+	require.Equal(t, "some random reason", reason)
+}
+
+func TestEncodeDecodeWorkflowIDConflictPolicy(t *testing.T) {
+	header := &types.Header{Fields: map[string][]byte{"some random key": []byte("some random value")}}
+
+	encoded := EncodeWorkflowIDConflictPolicy(header, types.WorkflowIDConflictPolicyUseExisting.Ptr())
+	require.Len(t, encoded.Fields, 2)
+	require.Len(t, header.Fields, 1)
+	decoded, policy := DecodeWorkflowIDConflictPolicy(encoded)
+	require.Equal(t, header, decoded)
+	require.Equal(t, types.WorkflowIDConflictPolicyUseExisting.Ptr(), policy)
+
+	decoded, policy = DecodeWorkflowIDConflictPolicy(EncodeWorkflowIDConflictPolicy(nil, types.WorkflowIDConflictPolicyTerminateExisting.Ptr()))
+	require.Nil(t, decoded)
+	require.Equal(t, types.WorkflowIDConflictPolicyTerminateExisting.Ptr(), policy)
+
+	decoded, policy = DecodeWorkflowIDConflictPolicy(EncodeWorkflowIDConflictPolicy(header, nil))
+	require.Equal(t, header, decoded)
+	require.Nil(t, policy)
+}
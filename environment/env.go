@@ -82,6 +82,17 @@ const (
 	PostgresPort = "POSTGRES_PORT"
 	// PostgresDefaultPort Postgres default port
 	PostgresDefaultPort = "5432"
+
+	// DynamoDBSeeds env
+	DynamoDBSeeds = "DYNAMODB_SEEDS"
+	// DynamoDBPort env
+	DynamoDBPort = "DYNAMODB_PORT"
+	// DynamoDBDefaultPort DynamoDB Local default port
+	DynamoDBDefaultPort = "8000"
+	// DynamoDBRegion env
+	DynamoDBRegion = "DYNAMODB_REGION"
+	// DynamoDBDefaultRegion is the region used when talking to DynamoDB Local
+	DynamoDBDefaultRegion = "us-east-1"
 )
 
 // SetupEnv setup the necessary env
@@ -135,6 +146,20 @@ func SetupEnv() {
 		}
 	}
 
+	if os.Getenv(DynamoDBSeeds) == "" {
+		err := os.Setenv(DynamoDBSeeds, Localhost)
+		if err != nil {
+			panic(fmt.Sprintf("error setting env %v", DynamoDBSeeds))
+		}
+	}
+
+	if os.Getenv(DynamoDBPort) == "" {
+		err := os.Setenv(DynamoDBPort, DynamoDBDefaultPort)
+		if err != nil {
+			panic(fmt.Sprintf("error setting env %v", DynamoDBPort))
+		}
+	}
+
 	if os.Getenv(KafkaSeeds) == "" {
 		err := os.Setenv(KafkaSeeds, Localhost)
 		if err != nil {
@@ -269,3 +294,34 @@ func GetESVersion() string {
 	}
 	return version
 }
+
+// GetDynamoDBAddress return the DynamoDB address
+func GetDynamoDBAddress() string {
+	addr := os.Getenv(DynamoDBSeeds)
+	if addr == "" {
+		addr = Localhost
+	}
+	return addr
+}
+
+// GetDynamoDBPort return the DynamoDB port
+func GetDynamoDBPort() int {
+	port := os.Getenv(DynamoDBPort)
+	if port == "" {
+		port = DynamoDBDefaultPort
+	}
+	p, err := strconv.Atoi(port)
+	if err != nil {
+		panic(fmt.Sprintf("error getting env %v", DynamoDBPort))
+	}
+	return p
+}
+
+// GetDynamoDBRegion return the DynamoDB region
+func GetDynamoDBRegion() string {
+	region := os.Getenv(DynamoDBRegion)
+	if region == "" {
+		region = DynamoDBDefaultRegion
+	}
+	return region
+}
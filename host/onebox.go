@@ -796,7 +796,7 @@ func (c *cadenceImpl) newRPCFactory(serviceName string, tchannelHostPort string)
 		OutboundsBuilder: rpc.CombineOutbounds(
 			&singleTChannelOutbound{serviceName, serviceName, tchannelHostPort},
 			&singleTChannelOutbound{rpc.OutboundPublicClient, service.Frontend, c.FrontendAddress()},
-			rpc.NewCrossDCOutbounds(c.clusterMetadata.GetAllClusterInfo(), rpc.NewDNSPeerChooserFactory(0, c.logger))),
+			rpc.NewCrossDCOutbounds(c.clusterMetadata.GetAllClusterInfo(), rpc.NewDNSPeerChooserFactory(0, c.logger), c.logger)),
 	})
 }
 
@@ -50,10 +50,12 @@ import (
 
 	// the import is a test dependency
 	_ "github.com/uber/cadence/common/persistence/nosql/nosqlplugin/cassandra/gocql/public"
+	_ "github.com/uber/cadence/common/persistence/nosql/nosqlplugin/memory"
 	persistencetests "github.com/uber/cadence/common/persistence/persistence-tests"
 	"github.com/uber/cadence/common/persistence/sql"
 	"github.com/uber/cadence/common/persistence/sql/sqlplugin/mysql"
 	"github.com/uber/cadence/common/persistence/sql/sqlplugin/postgres"
+	"github.com/uber/cadence/common/persistence/sql/sqlplugin/sqlite"
 )
 
 type (
@@ -200,9 +202,8 @@ func NewPersistenceTestCluster(clusterConfig *TestClusterConfig) testcluster.Per
 
 	var testCluster testcluster.PersistenceTestCluster
 	if TestFlags.PersistenceType == config.StoreTypeCassandra {
-		// TODO refactor to support other NoSQL
 		ops := clusterConfig.Persistence
-		ops.DBPluginName = "cassandra"
+		ops.DBPluginName = TestFlags.NoSQLPluginName
 		testCluster = nosql.NewTestCluster(ops.DBPluginName, ops.DBName, ops.DBUsername, ops.DBPassword, ops.DBHost, ops.DBPort, ops.ProtoVersion, "")
 	} else if TestFlags.PersistenceType == config.StoreTypeSQL {
 		var ops *persistencetests.TestBaseOptions
@@ -210,6 +211,10 @@ func NewPersistenceTestCluster(clusterConfig *TestClusterConfig) testcluster.Per
 			ops = mysql.GetTestClusterOption()
 		} else if TestFlags.SQLPluginName == postgres.PluginName {
 			ops = postgres.GetTestClusterOption()
+		} else if TestFlags.SQLPluginName == sqlite.PluginName {
+			ops = sqlite.GetTestClusterOption()
+			// the sqlite database name is the path of a per-run database file
+			clusterConfig.Persistence.DBName = ops.DBName
 		} else {
 			panic("not supported plugin " + TestFlags.SQLPluginName)
 		}
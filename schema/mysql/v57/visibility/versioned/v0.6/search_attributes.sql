@@ -0,0 +1,18 @@
+CREATE TABLE executions_visibility_search_attributes (
+  domain_id            CHAR(64) NOT NULL,
+  run_id               CHAR(64) NOT NULL,
+  name                 VARCHAR(255) NOT NULL,
+  value_index          INT NOT NULL, -- position of the value within an array attribute, 0 for single values
+  string_value         TEXT NULL,
+  int_value            BIGINT NULL,
+  double_value         DOUBLE NULL,
+  bool_value           BOOLEAN NULL,
+  datetime_value       DATETIME(6) NULL,
+
+  PRIMARY KEY  (domain_id, run_id, name, value_index)
+);
+
+CREATE INDEX by_string_value ON executions_visibility_search_attributes (domain_id, name, string_value(255));
+CREATE INDEX by_int_value ON executions_visibility_search_attributes (domain_id, name, int_value);
+CREATE INDEX by_double_value ON executions_visibility_search_attributes (domain_id, name, double_value);
+CREATE INDEX by_datetime_value ON executions_visibility_search_attributes (domain_id, name, datetime_value);
@@ -0,0 +1,46 @@
+-- Optional partitioned layout of the history_node and executions tables, requires PostgreSQL 11+.
+-- Apply on a fresh database after the versioned schema, it drops both tables:
+--   cadence-sql-tool --pl postgres --db cadence setup-schema -d -f ./schema/postgres/cadence/partitioned/schema.sql
+-- and enable it with the partitioning section of the sql persistence config.
+--
+-- Rows are placed by the bucket column, which is the history branch creation time (history_node)
+-- or the workflow start time (executions) divided by the configured bucket size.
+-- The partitions of the buckets, named <table>_b<bucket>, are created ahead of time and dropped once
+-- expired and unused by the partition retention job of the worker service.
+-- Rows of the legacy bucket 0 and of buckets without a partition go to the default partition,
+-- which is never dropped and is cleaned up row by row.
+
+DROP TABLE history_node;
+
+CREATE TABLE history_node (
+  shard_id       INTEGER NOT NULL,
+  tree_id        BYTEA NOT NULL,
+  branch_id      BYTEA NOT NULL,
+  node_id        BIGINT NOT NULL,
+  txn_id         BIGINT NOT NULL,
+  bucket         BIGINT NOT NULL DEFAULT 0,
+  --
+  data           BYTEA NOT NULL,
+  data_encoding  VARCHAR(16) NOT NULL,
+  PRIMARY KEY (shard_id, tree_id, branch_id, node_id, txn_id, bucket)
+) PARTITION BY RANGE (bucket);
+
+CREATE TABLE history_node_default PARTITION OF history_node DEFAULT;
+
+DROP TABLE executions;
+
+CREATE TABLE executions(
+  shard_id INTEGER NOT NULL,
+  domain_id BYTEA NOT NULL,
+  workflow_id VARCHAR(255) NOT NULL,
+  run_id BYTEA NOT NULL,
+  bucket BIGINT NOT NULL DEFAULT 0,
+  --
+  next_event_id BIGINT NOT NULL,
+  last_write_version BIGINT NOT NULL,
+  data BYTEA NOT NULL,
+  data_encoding VARCHAR(16) NOT NULL,
+  PRIMARY KEY (shard_id, domain_id, workflow_id, run_id, bucket)
+) PARTITION BY RANGE (bucket);
+
+CREATE TABLE executions_default PARTITION OF executions DEFAULT;
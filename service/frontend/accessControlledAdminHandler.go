@@ -22,10 +22,14 @@ package frontend
 
 import (
 	"context"
+	"encoding/json"
 
+	"github.com/uber/cadence/common/audit"
 	"github.com/uber/cadence/common/authorization"
 	"github.com/uber/cadence/common/config"
 	"github.com/uber/cadence/common/log/tag"
+	"github.com/uber/cadence/common/persistence"
+	"github.com/uber/cadence/common/redaction"
 	"github.com/uber/cadence/common/resource"
 	"github.com/uber/cadence/common/types"
 )
@@ -34,13 +38,17 @@ import (
 type AccessControlledWorkflowAdminHandler struct {
 	AdminHandler
 
+	resource   resource.Resource
 	authorizer authorization.Authorizer
+	auditor    *auditor
+	shadow     *authorizationShadow
 }
 
 var _ AdminHandler = (*AccessControlledWorkflowAdminHandler)(nil)
 
-// NewAccessControlledAdminHandlerImpl creates frontend handler with authentication support
-func NewAccessControlledAdminHandlerImpl(adminHandler AdminHandler, resource resource.Resource, authorizer authorization.Authorizer, cfg config.Authorization) *AccessControlledWorkflowAdminHandler {
+// NewAccessControlledAdminHandlerImpl creates frontend handler with authentication support,
+// recording the mutating API calls to the audit sink if not nil
+func NewAccessControlledAdminHandlerImpl(adminHandler AdminHandler, resource resource.Resource, authorizer authorization.Authorizer, cfg config.Authorization, auditSink audit.Sink) *AccessControlledWorkflowAdminHandler {
 	if authorizer == nil {
 		var err error
 		authorizer, err = authorization.NewAuthorizer(cfg, resource.GetLogger(), resource.GetDomainCache())
@@ -50,7 +58,10 @@ func NewAccessControlledAdminHandlerImpl(adminHandler AdminHandler, resource res
 	}
 	return &AccessControlledWorkflowAdminHandler{
 		AdminHandler: adminHandler,
+		resource:     resource,
 		authorizer:   authorizer,
+		auditor:      newAuditor(auditSink, resource),
+		shadow:       newAuthorizationShadow(cfg, resource),
 	}
 }
 
@@ -64,10 +75,13 @@ func (a *AccessControlledWorkflowAdminHandler) AddSearchAttribute(ctx context.Co
 		return err
 	}
 	if !isAuthorized {
-		return errUnauthorized
+		a.auditor.record(attr, "", nil, "", errUnauthorized)
+		return errAdminPermissionDenied(attr)
 	}
 
-	return a.AdminHandler.AddSearchAttribute(ctx, request)
+	err = a.AdminHandler.AddSearchAttribute(ctx, request)
+	a.auditor.record(attr, "", nil, "", err)
+	return err
 }
 
 func (a *AccessControlledWorkflowAdminHandler) CloseShard(ctx context.Context, request *types.CloseShardRequest) error {
@@ -80,10 +94,13 @@ func (a *AccessControlledWorkflowAdminHandler) CloseShard(ctx context.Context, r
 		return err
 	}
 	if !isAuthorized {
-		return errUnauthorized
+		a.auditor.record(attr, "", nil, "", errUnauthorized)
+		return errAdminPermissionDenied(attr)
 	}
 
-	return a.AdminHandler.CloseShard(ctx, request)
+	err = a.AdminHandler.CloseShard(ctx, request)
+	a.auditor.record(attr, "", nil, "", err)
+	return err
 }
 
 func (a *AccessControlledWorkflowAdminHandler) DescribeCluster(ctx context.Context) (*types.DescribeClusterResponse, error) {
@@ -96,7 +113,7 @@ func (a *AccessControlledWorkflowAdminHandler) DescribeCluster(ctx context.Conte
 		return nil, err
 	}
 	if !isAuthorized {
-		return nil, errUnauthorized
+		return nil, errAdminPermissionDenied(attr)
 	}
 
 	return a.AdminHandler.DescribeCluster(ctx)
@@ -112,7 +129,7 @@ func (a *AccessControlledWorkflowAdminHandler) DescribeShardDistribution(ctx con
 		return nil, err
 	}
 	if !isAuthorized {
-		return nil, errUnauthorized
+		return nil, errAdminPermissionDenied(attr)
 	}
 
 	return a.AdminHandler.DescribeShardDistribution(ctx, request)
@@ -128,7 +145,7 @@ func (a *AccessControlledWorkflowAdminHandler) DescribeHistoryHost(ctx context.C
 		return nil, err
 	}
 	if !isAuthorized {
-		return nil, errUnauthorized
+		return nil, errAdminPermissionDenied(attr)
 	}
 
 	return a.AdminHandler.DescribeHistoryHost(ctx, request)
@@ -144,7 +161,7 @@ func (a *AccessControlledWorkflowAdminHandler) DescribeQueue(ctx context.Context
 		return nil, err
 	}
 	if !isAuthorized {
-		return nil, errUnauthorized
+		return nil, errAdminPermissionDenied(attr)
 	}
 
 	return a.AdminHandler.DescribeQueue(ctx, request)
@@ -153,6 +170,7 @@ func (a *AccessControlledWorkflowAdminHandler) DescribeQueue(ctx context.Context
 func (a *AccessControlledWorkflowAdminHandler) DescribeWorkflowExecution(ctx context.Context, request *types.AdminDescribeWorkflowExecutionRequest) (*types.AdminDescribeWorkflowExecutionResponse, error) {
 	attr := &authorization.Attributes{
 		APIName:    "DescribeWorkflowExecution",
+		DomainName: request.GetDomain(),
 		Permission: authorization.PermissionAdmin,
 	}
 	isAuthorized, err := a.isAuthorized(ctx, attr)
@@ -160,10 +178,26 @@ func (a *AccessControlledWorkflowAdminHandler) DescribeWorkflowExecution(ctx con
 		return nil, err
 	}
 	if !isAuthorized {
-		return nil, errUnauthorized
+		return nil, errAdminPermissionDenied(attr)
 	}
 
-	return a.AdminHandler.DescribeWorkflowExecution(ctx, request)
+	resp, err := a.AdminHandler.DescribeWorkflowExecution(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	redactor, err := a.getRedactor(ctx, attr)
+	if err != nil {
+		return nil, err
+	}
+	if redactor != nil {
+		if resp.MutableStateInCache, err = redactMutableState(redactor, resp.MutableStateInCache); err != nil {
+			return nil, err
+		}
+		if resp.MutableStateInDatabase, err = redactMutableState(redactor, resp.MutableStateInDatabase); err != nil {
+			return nil, err
+		}
+	}
+	return resp, nil
 }
 
 func (a *AccessControlledWorkflowAdminHandler) GetDLQReplicationMessages(ctx context.Context, request *types.GetDLQReplicationMessagesRequest) (*types.GetDLQReplicationMessagesResponse, error) {
@@ -176,7 +210,7 @@ func (a *AccessControlledWorkflowAdminHandler) GetDLQReplicationMessages(ctx con
 		return nil, err
 	}
 	if !isAuthorized {
-		return nil, errUnauthorized
+		return nil, errAdminPermissionDenied(attr)
 	}
 
 	return a.AdminHandler.GetDLQReplicationMessages(ctx, request)
@@ -192,7 +226,7 @@ func (a *AccessControlledWorkflowAdminHandler) GetDomainReplicationMessages(ctx
 		return nil, err
 	}
 	if !isAuthorized {
-		return nil, errUnauthorized
+		return nil, errAdminPermissionDenied(attr)
 	}
 
 	return a.AdminHandler.GetDomainReplicationMessages(ctx, request)
@@ -208,7 +242,7 @@ func (a *AccessControlledWorkflowAdminHandler) GetReplicationMessages(ctx contex
 		return nil, err
 	}
 	if !isAuthorized {
-		return nil, errUnauthorized
+		return nil, errAdminPermissionDenied(attr)
 	}
 
 	return a.AdminHandler.GetReplicationMessages(ctx, request)
@@ -217,6 +251,7 @@ func (a *AccessControlledWorkflowAdminHandler) GetReplicationMessages(ctx contex
 func (a *AccessControlledWorkflowAdminHandler) GetWorkflowExecutionRawHistoryV2(ctx context.Context, request *types.GetWorkflowExecutionRawHistoryV2Request) (*types.GetWorkflowExecutionRawHistoryV2Response, error) {
 	attr := &authorization.Attributes{
 		APIName:    "GetWorkflowExecutionRawHistoryV2",
+		DomainName: request.GetDomain(),
 		Permission: authorization.PermissionAdmin,
 	}
 	isAuthorized, err := a.isAuthorized(ctx, attr)
@@ -224,10 +259,23 @@ func (a *AccessControlledWorkflowAdminHandler) GetWorkflowExecutionRawHistoryV2(
 		return nil, err
 	}
 	if !isAuthorized {
-		return nil, errUnauthorized
+		return nil, errAdminPermissionDenied(attr)
 	}
 
-	return a.AdminHandler.GetWorkflowExecutionRawHistoryV2(ctx, request)
+	resp, err := a.AdminHandler.GetWorkflowExecutionRawHistoryV2(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	redactor, err := a.getRedactor(ctx, attr)
+	if err != nil {
+		return nil, err
+	}
+	if redactor != nil {
+		if resp.HistoryBatches, err = redactRawHistory(a.resource.GetPayloadSerializer(), redactor, resp.HistoryBatches); err != nil {
+			return nil, err
+		}
+	}
+	return resp, nil
 }
 
 func (a *AccessControlledWorkflowAdminHandler) MergeDLQMessages(ctx context.Context, request *types.MergeDLQMessagesRequest) (*types.MergeDLQMessagesResponse, error) {
@@ -240,10 +288,13 @@ func (a *AccessControlledWorkflowAdminHandler) MergeDLQMessages(ctx context.Cont
 		return nil, err
 	}
 	if !isAuthorized {
-		return nil, errUnauthorized
+		a.auditor.record(attr, "", nil, "", errUnauthorized)
+		return nil, errAdminPermissionDenied(attr)
 	}
 
-	return a.AdminHandler.MergeDLQMessages(ctx, request)
+	resp, err := a.AdminHandler.MergeDLQMessages(ctx, request)
+	a.auditor.record(attr, "", nil, "", err)
+	return resp, err
 }
 
 func (a *AccessControlledWorkflowAdminHandler) PurgeDLQMessages(ctx context.Context, request *types.PurgeDLQMessagesRequest) error {
@@ -256,10 +307,13 @@ func (a *AccessControlledWorkflowAdminHandler) PurgeDLQMessages(ctx context.Cont
 		return err
 	}
 	if !isAuthorized {
-		return errUnauthorized
+		a.auditor.record(attr, "", nil, "", errUnauthorized)
+		return errAdminPermissionDenied(attr)
 	}
 
-	return a.AdminHandler.PurgeDLQMessages(ctx, request)
+	err = a.AdminHandler.PurgeDLQMessages(ctx, request)
+	a.auditor.record(attr, "", nil, "", err)
+	return err
 }
 
 func (a *AccessControlledWorkflowAdminHandler) ReadDLQMessages(ctx context.Context, request *types.ReadDLQMessagesRequest) (*types.ReadDLQMessagesResponse, error) {
@@ -272,7 +326,7 @@ func (a *AccessControlledWorkflowAdminHandler) ReadDLQMessages(ctx context.Conte
 		return nil, err
 	}
 	if !isAuthorized {
-		return nil, errUnauthorized
+		return nil, errAdminPermissionDenied(attr)
 	}
 
 	return a.AdminHandler.ReadDLQMessages(ctx, request)
@@ -288,10 +342,13 @@ func (a *AccessControlledWorkflowAdminHandler) ReapplyEvents(ctx context.Context
 		return err
 	}
 	if !isAuthorized {
-		return errUnauthorized
+		a.auditor.record(attr, request.GetDomainName(), request.GetWorkflowExecution(), "", errUnauthorized)
+		return errAdminPermissionDenied(attr)
 	}
 
-	return a.AdminHandler.ReapplyEvents(ctx, request)
+	err = a.AdminHandler.ReapplyEvents(ctx, request)
+	a.auditor.record(attr, request.GetDomainName(), request.GetWorkflowExecution(), "", err)
+	return err
 }
 
 func (a *AccessControlledWorkflowAdminHandler) RefreshWorkflowTasks(ctx context.Context, request *types.RefreshWorkflowTasksRequest) error {
@@ -304,10 +361,13 @@ func (a *AccessControlledWorkflowAdminHandler) RefreshWorkflowTasks(ctx context.
 		return err
 	}
 	if !isAuthorized {
-		return errUnauthorized
+		a.auditor.record(attr, request.GetDomain(), request.GetExecution(), "", errUnauthorized)
+		return errAdminPermissionDenied(attr)
 	}
 
-	return a.AdminHandler.RefreshWorkflowTasks(ctx, request)
+	err = a.AdminHandler.RefreshWorkflowTasks(ctx, request)
+	a.auditor.record(attr, request.GetDomain(), request.GetExecution(), "", err)
+	return err
 }
 
 func (a *AccessControlledWorkflowAdminHandler) RemoveTask(ctx context.Context, request *types.RemoveTaskRequest) error {
@@ -320,10 +380,13 @@ func (a *AccessControlledWorkflowAdminHandler) RemoveTask(ctx context.Context, r
 		return err
 	}
 	if !isAuthorized {
-		return errUnauthorized
+		a.auditor.record(attr, "", nil, "", errUnauthorized)
+		return errAdminPermissionDenied(attr)
 	}
 
-	return a.AdminHandler.RemoveTask(ctx, request)
+	err = a.AdminHandler.RemoveTask(ctx, request)
+	a.auditor.record(attr, "", nil, "", err)
+	return err
 }
 
 func (a *AccessControlledWorkflowAdminHandler) ResendReplicationTasks(ctx context.Context, request *types.ResendReplicationTasksRequest) error {
@@ -336,10 +399,13 @@ func (a *AccessControlledWorkflowAdminHandler) ResendReplicationTasks(ctx contex
 		return err
 	}
 	if !isAuthorized {
-		return errUnauthorized
+		a.auditor.record(attr, "", &types.WorkflowExecution{WorkflowID: request.GetWorkflowID(), RunID: request.GetRunID()}, "", errUnauthorized)
+		return errAdminPermissionDenied(attr)
 	}
 
-	return a.AdminHandler.ResendReplicationTasks(ctx, request)
+	err = a.AdminHandler.ResendReplicationTasks(ctx, request)
+	a.auditor.record(attr, "", &types.WorkflowExecution{WorkflowID: request.GetWorkflowID(), RunID: request.GetRunID()}, "", err)
+	return err
 }
 
 func (a *AccessControlledWorkflowAdminHandler) ResetQueue(ctx context.Context, request *types.ResetQueueRequest) error {
@@ -352,10 +418,13 @@ func (a *AccessControlledWorkflowAdminHandler) ResetQueue(ctx context.Context, r
 		return err
 	}
 	if !isAuthorized {
-		return errUnauthorized
+		a.auditor.record(attr, "", nil, "", errUnauthorized)
+		return errAdminPermissionDenied(attr)
 	}
 
-	return a.AdminHandler.ResetQueue(ctx, request)
+	err = a.AdminHandler.ResetQueue(ctx, request)
+	a.auditor.record(attr, "", nil, "", err)
+	return err
 }
 
 func (a *AccessControlledWorkflowAdminHandler) GetCrossClusterTasks(ctx context.Context, request *types.GetCrossClusterTasksRequest) (*types.GetCrossClusterTasksResponse, error) {
@@ -368,7 +437,7 @@ func (a *AccessControlledWorkflowAdminHandler) GetCrossClusterTasks(ctx context.
 		return nil, err
 	}
 	if !isAuthorized {
-		return nil, errUnauthorized
+		return nil, errAdminPermissionDenied(attr)
 	}
 
 	return a.AdminHandler.GetCrossClusterTasks(ctx, request)
@@ -384,7 +453,7 @@ func (a *AccessControlledWorkflowAdminHandler) GetDynamicConfig(ctx context.Cont
 		return nil, err
 	}
 	if !isAuthorized {
-		return nil, errUnauthorized
+		return nil, errAdminPermissionDenied(attr)
 	}
 
 	return a.AdminHandler.GetDynamicConfig(ctx, request)
@@ -400,10 +469,13 @@ func (a *AccessControlledWorkflowAdminHandler) UpdateDynamicConfig(ctx context.C
 		return err
 	}
 	if !isAuthorized {
-		return errUnauthorized
+		a.auditor.record(attr, "", nil, "", errUnauthorized)
+		return errAdminPermissionDenied(attr)
 	}
 
-	return a.AdminHandler.UpdateDynamicConfig(ctx, request)
+	err = a.AdminHandler.UpdateDynamicConfig(ctx, request)
+	a.auditor.record(attr, "", nil, "", err)
+	return err
 }
 
 func (a *AccessControlledWorkflowAdminHandler) RestoreDynamicConfig(ctx context.Context, request *types.RestoreDynamicConfigRequest) error {
@@ -416,10 +488,13 @@ func (a *AccessControlledWorkflowAdminHandler) RestoreDynamicConfig(ctx context.
 		return err
 	}
 	if !isAuthorized {
-		return errUnauthorized
+		a.auditor.record(attr, "", nil, "", errUnauthorized)
+		return errAdminPermissionDenied(attr)
 	}
 
-	return a.AdminHandler.RestoreDynamicConfig(ctx, request)
+	err = a.AdminHandler.RestoreDynamicConfig(ctx, request)
+	a.auditor.record(attr, "", nil, "", err)
+	return err
 }
 
 func (a *AccessControlledWorkflowAdminHandler) ListDynamicConfig(ctx context.Context, request *types.ListDynamicConfigRequest) (*types.ListDynamicConfigResponse, error) {
@@ -432,7 +507,7 @@ func (a *AccessControlledWorkflowAdminHandler) ListDynamicConfig(ctx context.Con
 		return nil, err
 	}
 	if !isAuthorized {
-		return nil, errUnauthorized
+		return nil, errAdminPermissionDenied(attr)
 	}
 
 	return a.AdminHandler.ListDynamicConfig(ctx, request)
@@ -443,9 +518,45 @@ func (a *AccessControlledWorkflowAdminHandler) isAuthorized(
 	attr *authorization.Attributes,
 ) (bool, error) {
 	result, err := a.authorizer.Authorize(ctx, attr)
+	if result.Actor != "" {
+		// recorded by the audit log
+		attr.Actor = result.Actor
+	}
 	if err != nil {
+		if a.shadow.allow(attr, err) {
+			return true, nil
+		}
 		return false, err
 	}
 	isAuth := result.Decision == authorization.DecisionAllow
+	if !isAuth && a.shadow.allow(attr, nil) {
+		return true, nil
+	}
 	return isAuth, nil
 }
+
+// getRedactor returns the redactor of the domain when the caller doesn't have the sensitive read permission
+// of the domain, nil otherwise. Being allowed to call the admin API doesn't grant the sensitive read permission.
+func (a *AccessControlledWorkflowAdminHandler) getRedactor(
+	ctx context.Context,
+	attr *authorization.Attributes,
+) (*redaction.Redactor, error) {
+	return getRedactor(ctx, a.resource.GetDomainCache(), a.authorizer, a.shadow, attr)
+}
+
+// redactMutableState masks the payloads of the mutable state described as JSON
+func redactMutableState(redactor *redaction.Redactor, mutableState string) (string, error) {
+	if mutableState == "" {
+		return mutableState, nil
+	}
+	state := &persistence.WorkflowMutableState{}
+	if err := json.Unmarshal([]byte(mutableState), state); err != nil {
+		return "", err
+	}
+	redactor.RedactMutableState(state)
+	data, err := json.Marshal(state)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
@@ -22,29 +22,53 @@ package frontend
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
 
+	"github.com/uber/cadence/common"
+	"github.com/uber/cadence/common/audit"
 	"github.com/uber/cadence/common/authorization"
+	"github.com/uber/cadence/common/cache"
 	"github.com/uber/cadence/common/config"
 	"github.com/uber/cadence/common/log/tag"
 	"github.com/uber/cadence/common/metrics"
+	"github.com/uber/cadence/common/persistence"
+	"github.com/uber/cadence/common/redaction"
 	"github.com/uber/cadence/common/resource"
 	"github.com/uber/cadence/common/types"
+	"github.com/uber/cadence/service/worker/scheduler"
 )
 
 var errUnauthorized = &types.BadRequestError{Message: "Request unauthorized."}
 
+// errAdminPermissionDenied returns the error of a denied admin API, naming the fine-grained admin permission
+// which would have allowed the call
+func errAdminPermissionDenied(attr *authorization.Attributes) error {
+	permission, ok := authorization.AdminPermissionOf(attr.APIName)
+	if !ok {
+		return &types.AccessDeniedError{Message: fmt.Sprintf("Request unauthorized, %v requires the admin permission.", attr.APIName)}
+	}
+	return &types.AccessDeniedError{Message: fmt.Sprintf(
+		"Request unauthorized, %v requires the admin permission or the %v admin permission.", attr.APIName, permission)}
+}
+
 // AccessControlledWorkflowHandler frontend handler wrapper for authentication and authorization
 type AccessControlledWorkflowHandler struct {
 	resource.Resource
 
 	frontendHandler Handler
 	authorizer      authorization.Authorizer
+	auditor         *auditor
+	shadow          *authorizationShadow
 }
 
 var _ Handler = (*AccessControlledWorkflowHandler)(nil)
 
-// NewAccessControlledHandlerImpl creates frontend handler with authentication support
-func NewAccessControlledHandlerImpl(wfHandler Handler, resource resource.Resource, authorizer authorization.Authorizer, cfg config.Authorization) *AccessControlledWorkflowHandler {
+// NewAccessControlledHandlerImpl creates frontend handler with authentication support,
+// recording the mutating API calls to the audit sink if not nil
+func NewAccessControlledHandlerImpl(wfHandler Handler, resource resource.Resource, authorizer authorization.Authorizer, cfg config.Authorization, auditSink audit.Sink) *AccessControlledWorkflowHandler {
 	if authorizer == nil {
 		var err error
 		authorizer, err = authorization.NewAuthorizer(cfg, resource.GetLogger(), resource.GetDomainCache())
@@ -56,6 +80,8 @@ func NewAccessControlledHandlerImpl(wfHandler Handler, resource resource.Resourc
 		Resource:        resource,
 		frontendHandler: wfHandler,
 		authorizer:      authorizer,
+		auditor:         newAuditor(auditSink, resource),
+		shadow:          newAuthorizationShadow(cfg, resource),
 	}
 }
 
@@ -106,10 +132,13 @@ func (a *AccessControlledWorkflowHandler) DeprecateDomain(
 		return err
 	}
 	if !isAuthorized {
-		return errUnauthorized
+		a.auditor.record(attr, request.GetName(), nil, "", errUnauthorized)
+		return errAdminPermissionDenied(attr)
 	}
 
-	return a.frontendHandler.DeprecateDomain(ctx, request)
+	err = a.frontendHandler.DeprecateDomain(ctx, request)
+	a.auditor.record(attr, request.GetName(), nil, "", err)
+	return err
 }
 
 // DescribeDomain API call
@@ -172,7 +201,7 @@ func (a *AccessControlledWorkflowHandler) DescribeWorkflowExecution(
 		DomainName: request.GetDomain(),
 		Permission: authorization.PermissionRead,
 	}
-	isAuthorized, err := a.isAuthorized(ctx, attr, scope)
+	isAuthorized, err := a.isAuthorizedForWorkflow(ctx, attr, request.Execution, scope)
 	if err != nil {
 		return nil, err
 	}
@@ -180,7 +209,18 @@ func (a *AccessControlledWorkflowHandler) DescribeWorkflowExecution(
 		return nil, errUnauthorized
 	}
 
-	return a.frontendHandler.DescribeWorkflowExecution(ctx, request)
+	resp, err := a.frontendHandler.DescribeWorkflowExecution(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	redactor, err := a.getRedactor(ctx, attr)
+	if err != nil {
+		return nil, err
+	}
+	if redactor != nil {
+		redactor.RedactDescribeWorkflowExecutionResponse(resp)
+	}
+	return resp, nil
 }
 
 // GetSearchAttributes API call
@@ -203,7 +243,7 @@ func (a *AccessControlledWorkflowHandler) GetWorkflowExecutionHistory(
 		DomainName: request.GetDomain(),
 		Permission: authorization.PermissionRead,
 	}
-	isAuthorized, err := a.isAuthorized(ctx, attr, scope)
+	isAuthorized, err := a.isAuthorizedForWorkflow(ctx, attr, request.Execution, scope)
 	if err != nil {
 		return nil, err
 	}
@@ -211,7 +251,21 @@ func (a *AccessControlledWorkflowHandler) GetWorkflowExecutionHistory(
 		return nil, errUnauthorized
 	}
 
-	return a.frontendHandler.GetWorkflowExecutionHistory(ctx, request)
+	resp, err := a.frontendHandler.GetWorkflowExecutionHistory(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	redactor, err := a.getRedactor(ctx, attr)
+	if err != nil {
+		return nil, err
+	}
+	if redactor != nil {
+		redactor.RedactHistory(resp.History)
+		if resp.RawHistory, err = a.redactRawHistory(redactor, resp.RawHistory); err != nil {
+			return nil, err
+		}
+	}
+	return resp, nil
 }
 
 // ListArchivedWorkflowExecutions API call
@@ -235,7 +289,18 @@ func (a *AccessControlledWorkflowHandler) ListArchivedWorkflowExecutions(
 		return nil, errUnauthorized
 	}
 
-	return a.frontendHandler.ListArchivedWorkflowExecutions(ctx, request)
+	resp, err := a.frontendHandler.ListArchivedWorkflowExecutions(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	redactor, err := a.getRedactor(ctx, attr)
+	if err != nil {
+		return nil, err
+	}
+	if redactor != nil {
+		redactor.RedactWorkflowExecutionInfos(resp.Executions)
+	}
+	return resp, nil
 }
 
 // ListClosedWorkflowExecutions API call
@@ -259,7 +324,18 @@ func (a *AccessControlledWorkflowHandler) ListClosedWorkflowExecutions(
 		return nil, errUnauthorized
 	}
 
-	return a.frontendHandler.ListClosedWorkflowExecutions(ctx, request)
+	resp, err := a.frontendHandler.ListClosedWorkflowExecutions(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	redactor, err := a.getRedactor(ctx, attr)
+	if err != nil {
+		return nil, err
+	}
+	if redactor != nil {
+		redactor.RedactWorkflowExecutionInfos(resp.Executions)
+	}
+	return resp, nil
 }
 
 // ListDomains API call
@@ -279,7 +355,7 @@ func (a *AccessControlledWorkflowHandler) ListDomains(
 		return nil, err
 	}
 	if !isAuthorized {
-		return nil, errUnauthorized
+		return nil, errAdminPermissionDenied(attr)
 	}
 
 	return a.frontendHandler.ListDomains(ctx, request)
@@ -306,7 +382,18 @@ func (a *AccessControlledWorkflowHandler) ListOpenWorkflowExecutions(
 		return nil, errUnauthorized
 	}
 
-	return a.frontendHandler.ListOpenWorkflowExecutions(ctx, request)
+	resp, err := a.frontendHandler.ListOpenWorkflowExecutions(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	redactor, err := a.getRedactor(ctx, attr)
+	if err != nil {
+		return nil, err
+	}
+	if redactor != nil {
+		redactor.RedactWorkflowExecutionInfos(resp.Executions)
+	}
+	return resp, nil
 }
 
 // ListWorkflowExecutions API call
@@ -330,7 +417,18 @@ func (a *AccessControlledWorkflowHandler) ListWorkflowExecutions(
 		return nil, errUnauthorized
 	}
 
-	return a.frontendHandler.ListWorkflowExecutions(ctx, request)
+	resp, err := a.frontendHandler.ListWorkflowExecutions(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	redactor, err := a.getRedactor(ctx, attr)
+	if err != nil {
+		return nil, err
+	}
+	if redactor != nil {
+		redactor.RedactWorkflowExecutionInfos(resp.Executions)
+	}
+	return resp, nil
 }
 
 // PollForActivityTask API call
@@ -396,7 +494,10 @@ func (a *AccessControlledWorkflowHandler) QueryWorkflow(
 		DomainName: request.GetDomain(),
 		Permission: authorization.PermissionRead,
 	}
-	isAuthorized, err := a.isAuthorized(ctx, attr, scope)
+	isAuthorized, err := a.isAuthorizedForWorkflow(ctx, attr, request.Execution, scope)
+	if err == nil && isAuthorized && isScheduleDomain(request.GetDomain()) {
+		isAuthorized, err = a.isAuthorizedForScheduleWorkflow(ctx, attr, request.GetExecution().GetWorkflowID(), scope)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -404,7 +505,18 @@ func (a *AccessControlledWorkflowHandler) QueryWorkflow(
 		return nil, errUnauthorized
 	}
 
-	return a.frontendHandler.QueryWorkflow(ctx, request)
+	resp, err := a.frontendHandler.QueryWorkflow(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	redactor, err := a.getRedactor(ctx, attr)
+	if err != nil {
+		return nil, err
+	}
+	if redactor != nil {
+		resp.QueryResult = redactor.RedactPayload(resp.QueryResult)
+	}
+	return resp, nil
 }
 
 // GetClusterInfo API call
@@ -449,10 +561,13 @@ func (a *AccessControlledWorkflowHandler) RegisterDomain(
 		return err
 	}
 	if !isAuthorized {
-		return errUnauthorized
+		a.auditor.record(attr, request.GetName(), nil, "", errUnauthorized)
+		return errAdminPermissionDenied(attr)
 	}
 
-	return a.frontendHandler.RegisterDomain(ctx, request)
+	err = a.frontendHandler.RegisterDomain(ctx, request)
+	a.auditor.record(attr, request.GetName(), nil, "", err)
+	return err
 }
 
 // RequestCancelWorkflowExecution API call
@@ -468,15 +583,21 @@ func (a *AccessControlledWorkflowHandler) RequestCancelWorkflowExecution(
 		DomainName: request.GetDomain(),
 		Permission: authorization.PermissionWrite,
 	}
-	isAuthorized, err := a.isAuthorized(ctx, attr, scope)
+	isAuthorized, err := a.isAuthorizedForWorkflow(ctx, attr, request.WorkflowExecution, scope)
+	if err == nil && isAuthorized && isScheduleDomain(request.GetDomain()) {
+		isAuthorized, err = a.isAuthorizedForScheduleWorkflow(ctx, attr, request.GetWorkflowExecution().GetWorkflowID(), scope)
+	}
 	if err != nil {
 		return err
 	}
 	if !isAuthorized {
+		a.auditor.record(attr, request.GetDomain(), request.WorkflowExecution, "", errUnauthorized)
 		return errUnauthorized
 	}
 
-	return a.frontendHandler.RequestCancelWorkflowExecution(ctx, request)
+	err = a.frontendHandler.RequestCancelWorkflowExecution(ctx, request)
+	a.auditor.record(attr, request.GetDomain(), request.WorkflowExecution, "", err)
+	return err
 }
 
 // ResetStickyTaskList API call
@@ -492,7 +613,7 @@ func (a *AccessControlledWorkflowHandler) ResetStickyTaskList(
 		DomainName: request.GetDomain(),
 		Permission: authorization.PermissionWrite,
 	}
-	isAuthorized, err := a.isAuthorized(ctx, attr, scope)
+	isAuthorized, err := a.isAuthorizedForWorkflow(ctx, attr, request.Execution, scope)
 	if err != nil {
 		return nil, err
 	}
@@ -516,15 +637,18 @@ func (a *AccessControlledWorkflowHandler) ResetWorkflowExecution(
 		DomainName: request.GetDomain(),
 		Permission: authorization.PermissionWrite,
 	}
-	isAuthorized, err := a.isAuthorized(ctx, attr, scope)
+	isAuthorized, err := a.isAuthorizedForWorkflow(ctx, attr, request.WorkflowExecution, scope)
 	if err != nil {
 		return nil, err
 	}
 	if !isAuthorized {
+		a.auditor.record(attr, request.GetDomain(), request.WorkflowExecution, request.GetReason(), errUnauthorized)
 		return nil, errUnauthorized
 	}
 
-	return a.frontendHandler.ResetWorkflowExecution(ctx, request)
+	resp, err := a.frontendHandler.ResetWorkflowExecution(ctx, request)
+	a.auditor.record(attr, request.GetDomain(), request.WorkflowExecution, request.GetReason(), err)
+	return resp, err
 }
 
 // RespondActivityTaskCanceled API call
@@ -620,7 +744,18 @@ func (a *AccessControlledWorkflowHandler) ScanWorkflowExecutions(
 		return nil, errUnauthorized
 	}
 
-	return a.frontendHandler.ScanWorkflowExecutions(ctx, request)
+	resp, err := a.frontendHandler.ScanWorkflowExecutions(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	redactor, err := a.getRedactor(ctx, attr)
+	if err != nil {
+		return nil, err
+	}
+	if redactor != nil {
+		redactor.RedactWorkflowExecutionInfos(resp.Executions)
+	}
+	return resp, nil
 }
 
 // SignalWithStartWorkflowExecution API call
@@ -638,14 +773,29 @@ func (a *AccessControlledWorkflowHandler) SignalWithStartWorkflowExecution(
 		WorkflowType: request.WorkflowType,
 	}
 	isAuthorized, err := a.isAuthorized(ctx, attr, scope)
+	if err == nil && isAuthorized {
+		isAuthorized, err = a.isAuthorizedToSignalRunningWorkflow(ctx, attr, request.GetWorkflowID(), scope)
+	}
+	if err == nil && isAuthorized && isScheduleWorkflow(request.GetDomain(), request.WorkflowType) {
+		isAuthorized, err = a.isAuthorizedForSchedule(ctx, attr, request.GetWorkflowID(), getScheduleParamsActionDomain(request.Input), scope)
+	}
+	if err == nil && isAuthorized && isScheduleDomain(request.GetDomain()) {
+		isAuthorized, err = a.isAuthorizedForScheduleWorkflow(ctx, attr, request.GetWorkflowID(), scope)
+	}
+	if err == nil && isAuthorized && isScheduleUpdate(request.GetDomain(), request.GetSignalName()) {
+		isAuthorized, err = a.isAuthorizedForSchedule(ctx, attr, request.GetWorkflowID(), getScheduleActionDomain(request.SignalInput), scope)
+	}
 	if err != nil {
 		return nil, err
 	}
 	if !isAuthorized {
+		a.auditor.record(attr, request.GetDomain(), &types.WorkflowExecution{WorkflowID: request.GetWorkflowID()}, "", errUnauthorized)
 		return nil, errUnauthorized
 	}
 
-	return a.frontendHandler.SignalWithStartWorkflowExecution(ctx, request)
+	resp, err := a.frontendHandler.SignalWithStartWorkflowExecution(ctx, request)
+	a.auditor.record(attr, request.GetDomain(), &types.WorkflowExecution{WorkflowID: request.GetWorkflowID(), RunID: resp.GetRunID()}, "", err)
+	return resp, err
 }
 
 // SignalWorkflowExecution API call
@@ -661,15 +811,24 @@ func (a *AccessControlledWorkflowHandler) SignalWorkflowExecution(
 		DomainName: request.GetDomain(),
 		Permission: authorization.PermissionWrite,
 	}
-	isAuthorized, err := a.isAuthorized(ctx, attr, scope)
+	isAuthorized, err := a.isAuthorizedForWorkflow(ctx, attr, request.WorkflowExecution, scope)
+	if err == nil && isAuthorized && isScheduleDomain(request.GetDomain()) {
+		isAuthorized, err = a.isAuthorizedForScheduleWorkflow(ctx, attr, request.GetWorkflowExecution().GetWorkflowID(), scope)
+	}
+	if err == nil && isAuthorized && isScheduleUpdate(request.GetDomain(), request.GetSignalName()) {
+		isAuthorized, err = a.isAuthorizedForSchedule(ctx, attr, request.GetWorkflowExecution().GetWorkflowID(), getScheduleActionDomain(request.Input), scope)
+	}
 	if err != nil {
 		return err
 	}
 	if !isAuthorized {
+		a.auditor.record(attr, request.GetDomain(), request.WorkflowExecution, "", errUnauthorized)
 		return errUnauthorized
 	}
 
-	return a.frontendHandler.SignalWorkflowExecution(ctx, request)
+	err = a.frontendHandler.SignalWorkflowExecution(ctx, request)
+	a.auditor.record(attr, request.GetDomain(), request.WorkflowExecution, "", err)
+	return err
 }
 
 // StartWorkflowExecution API call
@@ -687,14 +846,20 @@ func (a *AccessControlledWorkflowHandler) StartWorkflowExecution(
 		WorkflowType: request.WorkflowType,
 	}
 	isAuthorized, err := a.isAuthorized(ctx, attr, scope)
+	if err == nil && isAuthorized && isScheduleWorkflow(request.GetDomain(), request.WorkflowType) {
+		isAuthorized, err = a.isAuthorizedForSchedule(ctx, attr, request.GetWorkflowID(), getScheduleParamsActionDomain(request.Input), scope)
+	}
 	if err != nil {
 		return nil, err
 	}
 	if !isAuthorized {
+		a.auditor.record(attr, request.GetDomain(), &types.WorkflowExecution{WorkflowID: request.GetWorkflowID()}, "", errUnauthorized)
 		return nil, errUnauthorized
 	}
 
-	return a.frontendHandler.StartWorkflowExecution(ctx, request)
+	resp, err := a.frontendHandler.StartWorkflowExecution(ctx, request)
+	a.auditor.record(attr, request.GetDomain(), &types.WorkflowExecution{WorkflowID: request.GetWorkflowID(), RunID: resp.GetRunID()}, "", err)
+	return resp, err
 }
 
 // TerminateWorkflowExecution API call
@@ -710,15 +875,21 @@ func (a *AccessControlledWorkflowHandler) TerminateWorkflowExecution(
 		DomainName: request.GetDomain(),
 		Permission: authorization.PermissionWrite,
 	}
-	isAuthorized, err := a.isAuthorized(ctx, attr, scope)
+	isAuthorized, err := a.isAuthorizedForWorkflow(ctx, attr, request.WorkflowExecution, scope)
+	if err == nil && isAuthorized && isScheduleDomain(request.GetDomain()) {
+		isAuthorized, err = a.isAuthorizedForScheduleWorkflow(ctx, attr, request.GetWorkflowExecution().GetWorkflowID(), scope)
+	}
 	if err != nil {
 		return err
 	}
 	if !isAuthorized {
+		a.auditor.record(attr, request.GetDomain(), request.WorkflowExecution, request.GetReason(), errUnauthorized)
 		return errUnauthorized
 	}
 
-	return a.frontendHandler.TerminateWorkflowExecution(ctx, request)
+	err = a.frontendHandler.TerminateWorkflowExecution(ctx, request)
+	a.auditor.record(attr, request.GetDomain(), request.WorkflowExecution, request.GetReason(), err)
+	return err
 }
 
 // ListTaskListPartitions API call
@@ -782,37 +953,295 @@ func (a *AccessControlledWorkflowHandler) UpdateDomain(
 		DomainName: request.GetName(),
 		Permission: authorization.PermissionAdmin,
 	}
+	if isFailoverOnlyRequest(request) {
+		// failover is granted separately from the other domain updates
+		attr.APIName = authorization.FailoverDomainAPIName
+	}
 	isAuthorized, err := a.isAuthorized(ctx, attr, scope)
 	if err != nil {
 		return nil, err
 	}
 	if !isAuthorized {
-		return nil, errUnauthorized
+		a.auditor.record(attr, request.GetName(), nil, "", errUnauthorized)
+		return nil, errAdminPermissionDenied(attr)
+	}
+
+	resp, err := a.frontendHandler.UpdateDomain(ctx, request)
+	a.auditor.record(attr, request.GetName(), nil, "", err)
+	return resp, err
+}
+
+// isFailoverOnlyRequest returns whether the request fails over the domain without updating anything else
+func isFailoverOnlyRequest(request *types.UpdateDomainRequest) bool {
+	if !isFailoverRequest(request) {
+		return false
+	}
+	failoverRequest := types.UpdateDomainRequest{
+		Name:                     request.Name,
+		SecurityToken:            request.SecurityToken,
+		ActiveClusterName:        request.ActiveClusterName,
+		FailoverTimeoutInSeconds: request.FailoverTimeoutInSeconds,
 	}
+	return reflect.DeepEqual(*request, failoverRequest)
+}
+
+// isAuthorizedForSchedule authorizes the operations on a schedule against its action domain, as the workflows
+// of the schedule are started by the scheduler on behalf of the caller. The schedules of a domain are
+// identified by the prefix of their workflow IDs, so the workflow ID must match the action domain.
+func (a *AccessControlledWorkflowHandler) isAuthorizedForSchedule(
+	ctx context.Context,
+	attr *authorization.Attributes,
+	workflowID string,
+	actionDomain string,
+	scope metrics.Scope,
+) (bool, error) {
+	if actionDomain == "" || !strings.HasPrefix(workflowID, scheduler.GetScheduleWorkflowIDPrefix(actionDomain)) {
+		return false, nil
+	}
+	return a.isAuthorized(ctx, &authorization.Attributes{
+		APIName:    attr.APIName,
+		DomainName: actionDomain,
+		Permission: attr.Permission,
+	}, scope)
+}
+
+// isAuthorizedForScheduleWorkflow authorizes the operations on the workflow of a schedule against the domain
+// of the schedule, which is the prefix of the workflow ID. The workflows of the scheduler domain that aren't
+// the workflow of a schedule are denied.
+func (a *AccessControlledWorkflowHandler) isAuthorizedForScheduleWorkflow(
+	ctx context.Context,
+	attr *authorization.Attributes,
+	workflowID string,
+	scope metrics.Scope,
+) (bool, error) {
+	return a.isAuthorizedForSchedule(ctx, attr, workflowID, scheduler.GetScheduleDomain(workflowID), scope)
+}
+
+// isScheduleDomain returns whether the request operates on the workflows of the schedules
+func isScheduleDomain(domain string) bool {
+	return domain == common.SchedulerLocalDomainName
+}
 
-	return a.frontendHandler.UpdateDomain(ctx, request)
+// isScheduleWorkflow returns whether the request starts the workflow of a schedule
+func isScheduleWorkflow(domain string, workflowType *types.WorkflowType) bool {
+	return isScheduleDomain(domain) && workflowType.GetName() == scheduler.ScheduleWFTypeName
+}
+
+// isScheduleUpdate returns whether the request signals the workflow of a schedule to update it
+func isScheduleUpdate(domain string, signalName string) bool {
+	return isScheduleDomain(domain) && signalName == scheduler.UpdateSignalName
+}
+
+// getScheduleParamsActionDomain returns the action domain of the schedule started by the input, or an empty
+// string when the input can't be decoded
+func getScheduleParamsActionDomain(input []byte) string {
+	var params scheduler.ScheduleParams
+	if err := json.Unmarshal(input, &params); err != nil {
+		return ""
+	}
+	return params.Schedule.Action.Domain
+}
+
+// getScheduleActionDomain returns the action domain of the schedule in the update signal input, or an empty
+// string when the input can't be decoded
+func getScheduleActionDomain(input []byte) string {
+	var schedule scheduler.Schedule
+	if err := json.Unmarshal(input, &schedule); err != nil {
+		return ""
+	}
+	return schedule.Action.Domain
 }
 
 func (a *AccessControlledWorkflowHandler) isAuthorized(
 	ctx context.Context,
 	attr *authorization.Attributes,
 	scope metrics.Scope,
+) (bool, error) {
+	return a.isAuthorizedForWorkflow(ctx, attr, nil, scope)
+}
+
+// isAuthorizedForWorkflow authorizes APIs operating on an existing workflow. When the request is denied
+// and the domain has authorization rules scoped to workflow types, the type of the workflow is resolved
+// and the request is authorized again against it. The run resolved is pinned in the execution forwarded,
+// so that the request can't reach a new run of the workflow started after the authorization.
+func (a *AccessControlledWorkflowHandler) isAuthorizedForWorkflow(
+	ctx context.Context,
+	attr *authorization.Attributes,
+	execution *types.WorkflowExecution,
+	scope metrics.Scope,
 ) (bool, error) {
 	sw := scope.StartTimer(metrics.CadenceAuthorizationLatency)
 	defer sw.Stop()
 
 	result, err := a.authorizer.Authorize(ctx, attr)
+	if err == nil && result.Decision != authorization.DecisionAllow && attr.WorkflowType == nil && execution != nil {
+		info, describeErr := a.describeWorkflowForAuthorization(ctx, attr.DomainName, execution)
+		if describeErr != nil {
+			// the request is denied when the workflow can't be described, not to leak whether it exists
+			a.GetLogger().Debug("failed to resolve workflow type for authorization",
+				tag.WorkflowDomainName(attr.DomainName), tag.Error(describeErr))
+		} else if info.GetType() != nil {
+			attr.WorkflowType = info.GetType()
+			if execution.GetRunID() == "" {
+				execution.RunID = info.GetExecution().GetRunID()
+			}
+			result, err = a.authorizer.Authorize(ctx, attr)
+		}
+	}
+	if result.Actor != "" {
+		// recorded by the audit log
+		attr.Actor = result.Actor
+	}
 	if err != nil {
 		scope.IncCounter(metrics.CadenceErrAuthorizeFailedCounter)
+		if a.shadow.allow(attr, err) {
+			return true, nil
+		}
 		return false, err
 	}
 	isAuth := result.Decision == authorization.DecisionAllow
 	if !isAuth {
+		if a.shadow.allow(attr, nil) {
+			return true, nil
+		}
 		scope.IncCounter(metrics.CadenceErrUnauthorizedCounter)
 	}
 	return isAuth, nil
 }
 
+// getRedactor returns the redactor of the domain when the caller doesn't have the sensitive read permission,
+// nil otherwise. Nothing is redacted in shadow mode.
+func (a *AccessControlledWorkflowHandler) getRedactor(
+	ctx context.Context,
+	attr *authorization.Attributes,
+) (*redaction.Redactor, error) {
+	return getRedactor(ctx, a.GetDomainCache(), a.authorizer, a.shadow, attr)
+}
+
+// redactRawHistory masks the payloads of the history batches returned without being deserialized
+func (a *AccessControlledWorkflowHandler) redactRawHistory(
+	redactor *redaction.Redactor,
+	rawHistory []*types.DataBlob,
+) ([]*types.DataBlob, error) {
+	return redactRawHistory(a.GetPayloadSerializer(), redactor, rawHistory)
+}
+
+// getRedactor returns the redactor of the domain when the caller doesn't have the sensitive read permission
+// of the domain, nil otherwise. Nothing is redacted in shadow mode.
+func getRedactor(
+	ctx context.Context,
+	domainCache cache.DomainCache,
+	authorizer authorization.Authorizer,
+	shadow *authorizationShadow,
+	attr *authorization.Attributes,
+) (*redaction.Redactor, error) {
+	domainEntry, err := domainCache.GetDomain(attr.DomainName)
+	if err != nil {
+		return nil, err
+	}
+	redactor := redaction.NewRedactor(domainEntry.GetInfo().Data)
+	if redactor == nil {
+		return nil, nil
+	}
+	sensitiveAttr := *attr
+	sensitiveAttr.Permission = authorization.PermissionSensitiveRead
+	result, err := authorizer.Authorize(ctx, &sensitiveAttr)
+	if err != nil {
+		if shadow.allow(&sensitiveAttr, err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if result.Decision == authorization.DecisionAllow || shadow.allow(&sensitiveAttr, nil) {
+		return nil, nil
+	}
+	return redactor, nil
+}
+
+// redactRawHistory masks the payloads of the history batches returned without being deserialized
+func redactRawHistory(
+	serializer persistence.PayloadSerializer,
+	redactor *redaction.Redactor,
+	rawHistory []*types.DataBlob,
+) ([]*types.DataBlob, error) {
+	if len(rawHistory) == 0 {
+		return rawHistory, nil
+	}
+	redacted := make([]*types.DataBlob, 0, len(rawHistory))
+	for _, blob := range rawHistory {
+		dataBlob := persistence.NewDataBlobFromInternal(blob)
+		events, err := serializer.DeserializeBatchEvents(dataBlob)
+		if err != nil {
+			return nil, err
+		}
+		for _, event := range events {
+			redactor.RedactHistoryEvent(event)
+		}
+		redactedBlob, err := serializer.SerializeBatchEvents(events, dataBlob.Encoding)
+		if err != nil {
+			return nil, err
+		}
+		redacted = append(redacted, redactedBlob.ToInternal())
+	}
+	return redacted, nil
+}
+
+// isAuthorizedToSignalRunningWorkflow authorizes SignalWithStartWorkflowExecution against the type of the
+// running workflow, which is signaled instead of starting the workflow type of the request
+func (a *AccessControlledWorkflowHandler) isAuthorizedToSignalRunningWorkflow(
+	ctx context.Context,
+	attr *authorization.Attributes,
+	workflowID string,
+	scope metrics.Scope,
+) (bool, error) {
+	info, err := a.describeWorkflowForAuthorization(ctx, attr.DomainName, &types.WorkflowExecution{WorkflowID: workflowID})
+	if _, ok := err.(*types.EntityNotExistsError); ok {
+		return true, nil
+	}
+	if err != nil {
+		a.GetLogger().Debug("failed to resolve running workflow type for authorization",
+			tag.WorkflowDomainName(attr.DomainName), tag.WorkflowID(workflowID), tag.Error(err))
+		if a.shadow.allow(attr, err) {
+			return true, nil
+		}
+		scope.IncCounter(metrics.CadenceErrUnauthorizedCounter)
+		return false, nil
+	}
+	if info == nil || info.CloseStatus != nil || info.GetType().GetName() == attr.WorkflowType.GetName() {
+		return true, nil
+	}
+	signalAttr := *attr
+	signalAttr.WorkflowType = info.GetType()
+	return a.isAuthorizedForWorkflow(ctx, &signalAttr, nil, scope)
+}
+
+// describeWorkflowForAuthorization returns the info of the workflow if the domain has authorization rules
+// scoped to workflow types, nil otherwise
+func (a *AccessControlledWorkflowHandler) describeWorkflowForAuthorization(
+	ctx context.Context,
+	domainName string,
+	execution *types.WorkflowExecution,
+) (*types.WorkflowExecutionInfo, error) {
+	domainEntry, err := a.GetDomainCache().GetDomain(domainName)
+	if err != nil {
+		return nil, err
+	}
+	if !authorization.HasWorkflowTypeRules(domainEntry.GetInfo().Data) {
+		return nil, nil
+	}
+	resp, err := a.frontendHandler.DescribeWorkflowExecution(ctx, &types.DescribeWorkflowExecutionRequest{
+		Domain: domainName,
+		Execution: &types.WorkflowExecution{
+			WorkflowID: execution.GetWorkflowID(),
+			RunID:      execution.GetRunID(),
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp.GetWorkflowExecutionInfo(), nil
+}
+
 // getMetricsScopeWithDomain return metrics scope with domain tag
 func (a *AccessControlledWorkflowHandler) getMetricsScopeWithDomain(
 	scope int,
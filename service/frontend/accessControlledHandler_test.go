@@ -22,6 +22,7 @@ package frontend
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"testing"
 
@@ -29,11 +30,16 @@ import (
 	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
 
+	"github.com/uber/cadence/common"
 	"github.com/uber/cadence/common/authorization"
+	"github.com/uber/cadence/common/cache"
 	"github.com/uber/cadence/common/config"
 	"github.com/uber/cadence/common/metrics"
 	"github.com/uber/cadence/common/metrics/mocks"
+	"github.com/uber/cadence/common/persistence"
 	"github.com/uber/cadence/common/resource"
+	"github.com/uber/cadence/common/types"
+	"github.com/uber/cadence/service/worker/scheduler"
 )
 
 type (
@@ -63,7 +69,7 @@ func (s *accessControlledHandlerSuite) SetupTest() {
 	s.mockFrontendHandler = NewMockHandler(s.controller)
 	s.mockAuthorizer = authorization.NewMockAuthorizer(s.controller)
 	s.mockMetricsScope = &mocks.Scope{}
-	s.handler = NewAccessControlledHandlerImpl(s.mockFrontendHandler, s.mockResource, s.mockAuthorizer, config.Authorization{})
+	s.handler = NewAccessControlledHandlerImpl(s.mockFrontendHandler, s.mockResource, s.mockAuthorizer, config.Authorization{}, nil)
 }
 
 func (s *accessControlledHandlerSuite) TearDownTest() {
@@ -116,3 +122,438 @@ func (s *accessControlledHandlerSuite) TestIsAuthorized_Unauthorized() {
 	s.False(res)
 	s.NoError(err)
 }
+
+func (s *accessControlledHandlerSuite) TestIsAuthorized_ShadowMode() {
+	ctx := context.Background()
+	attr := &authorization.Attributes{APIName: "StartWorkflowExecution", DomainName: "test-domain"}
+	shadowScope := &mocks.Scope{}
+	s.handler.shadow = &authorizationShadow{logger: s.mockResource.GetLogger(), metricsScope: shadowScope}
+
+	s.mockMetricsScope.On("StartTimer", metrics.CadenceAuthorizationLatency).
+		Return(metrics.Stopwatch{}).Once()
+	s.mockAuthorizer.EXPECT().Authorize(ctx, attr).
+		Return(authorization.Result{Decision: authorization.DecisionDeny}, nil).
+		Times(1)
+	shadowScope.On("Tagged", metrics.DomainTag("test-domain"), metrics.APINameTag("StartWorkflowExecution")).
+		Return(shadowScope).Once()
+	shadowScope.On("IncCounter", metrics.CadenceAuthorizationShadowDeniedCounter).Once()
+
+	res, err := s.handler.isAuthorized(ctx, attr, s.mockMetricsScope)
+	s.True(res)
+	s.NoError(err)
+	shadowScope.AssertExpectations(s.T())
+}
+
+func (s *accessControlledHandlerSuite) TestIsAuthorizedForWorkflow_ResolveWorkflowType() {
+	ctx := context.Background()
+	attr := &authorization.Attributes{
+		APIName:    "SignalWorkflowExecution",
+		DomainName: "test-domain",
+		Permission: authorization.PermissionWrite,
+	}
+	execution := &types.WorkflowExecution{WorkflowID: "wid", RunID: "rid"}
+
+	s.mockMetricsScope.On("StartTimer", metrics.CadenceAuthorizationLatency).
+		Return(metrics.Stopwatch{}).Once()
+	gomock.InOrder(
+		s.mockAuthorizer.EXPECT().Authorize(ctx, attr).
+			Return(authorization.Result{Decision: authorization.DecisionDeny}, nil).Times(1),
+		s.mockAuthorizer.EXPECT().Authorize(ctx, attr).
+			Return(authorization.Result{Decision: authorization.DecisionAllow}, nil).Times(1),
+	)
+	s.mockResource.DomainCache.EXPECT().GetDomain("test-domain").Return(s.workflowTypeRulesDomainEntry(), nil).Times(1)
+	s.mockFrontendHandler.EXPECT().DescribeWorkflowExecution(ctx, &types.DescribeWorkflowExecutionRequest{
+		Domain:    "test-domain",
+		Execution: execution,
+	}).Return(&types.DescribeWorkflowExecutionResponse{
+		WorkflowExecutionInfo: &types.WorkflowExecutionInfo{Type: &types.WorkflowType{Name: "PaymentWorkflow"}},
+	}, nil).Times(1)
+
+	res, err := s.handler.isAuthorizedForWorkflow(ctx, attr, execution, s.mockMetricsScope)
+	s.True(res)
+	s.NoError(err)
+	s.Equal("PaymentWorkflow", attr.WorkflowType.GetName())
+}
+
+func (s *accessControlledHandlerSuite) TestIsAuthorizedForWorkflow_NoWorkflowTypeRules() {
+	ctx := context.Background()
+	attr := &authorization.Attributes{
+		APIName:    "SignalWorkflowExecution",
+		DomainName: "test-domain",
+		Permission: authorization.PermissionWrite,
+	}
+	execution := &types.WorkflowExecution{WorkflowID: "wid", RunID: "rid"}
+	domainEntry := cache.NewLocalDomainCacheEntryForTest(
+		&persistence.DomainInfo{Name: "test-domain"},
+		&persistence.DomainConfig{},
+		"",
+		nil,
+	)
+
+	s.mockMetricsScope.On("StartTimer", metrics.CadenceAuthorizationLatency).
+		Return(metrics.Stopwatch{}).Once()
+	s.mockAuthorizer.EXPECT().Authorize(ctx, attr).
+		Return(authorization.Result{Decision: authorization.DecisionDeny}, nil).Times(1)
+	s.mockResource.DomainCache.EXPECT().GetDomain("test-domain").Return(domainEntry, nil).Times(1)
+	s.mockMetricsScope.On("IncCounter", metrics.CadenceErrUnauthorizedCounter).Once()
+
+	res, err := s.handler.isAuthorizedForWorkflow(ctx, attr, execution, s.mockMetricsScope)
+	s.False(res)
+	s.NoError(err)
+}
+
+func (s *accessControlledHandlerSuite) TestIsAuthorizedForWorkflow_PinResolvedRun() {
+	ctx := context.Background()
+	attr := &authorization.Attributes{
+		APIName:    "TerminateWorkflowExecution",
+		DomainName: "test-domain",
+		Permission: authorization.PermissionWrite,
+	}
+	execution := &types.WorkflowExecution{WorkflowID: "wid"}
+
+	s.mockMetricsScope.On("StartTimer", metrics.CadenceAuthorizationLatency).
+		Return(metrics.Stopwatch{}).Once()
+	gomock.InOrder(
+		s.mockAuthorizer.EXPECT().Authorize(ctx, attr).
+			Return(authorization.Result{Decision: authorization.DecisionDeny}, nil).Times(1),
+		s.mockAuthorizer.EXPECT().Authorize(ctx, attr).
+			Return(authorization.Result{Decision: authorization.DecisionAllow}, nil).Times(1),
+	)
+	s.mockResource.DomainCache.EXPECT().GetDomain("test-domain").Return(s.workflowTypeRulesDomainEntry(), nil).Times(1)
+	s.mockFrontendHandler.EXPECT().DescribeWorkflowExecution(ctx, &types.DescribeWorkflowExecutionRequest{
+		Domain:    "test-domain",
+		Execution: &types.WorkflowExecution{WorkflowID: "wid"},
+	}).Return(&types.DescribeWorkflowExecutionResponse{
+		WorkflowExecutionInfo: &types.WorkflowExecutionInfo{
+			Execution: &types.WorkflowExecution{WorkflowID: "wid", RunID: "rid"},
+			Type:      &types.WorkflowType{Name: "PaymentWorkflow"},
+		},
+	}, nil).Times(1)
+
+	res, err := s.handler.isAuthorizedForWorkflow(ctx, attr, execution, s.mockMetricsScope)
+	s.True(res)
+	s.NoError(err)
+	s.Equal("rid", execution.GetRunID())
+}
+
+func (s *accessControlledHandlerSuite) TestSignalWithStartWorkflowExecution_RunningWorkflowTypeDenied() {
+	ctx := context.Background()
+	request := &types.SignalWithStartWorkflowExecutionRequest{
+		Domain:       "test-domain",
+		WorkflowID:   "wid",
+		WorkflowType: &types.WorkflowType{Name: "ReportWorkflow"},
+	}
+
+	s.mockAuthorizer.EXPECT().Authorize(ctx, gomock.Any()).DoAndReturn(
+		func(_ context.Context, attr *authorization.Attributes) (authorization.Result, error) {
+			if attr.WorkflowType.GetName() == "ReportWorkflow" {
+				return authorization.Result{Decision: authorization.DecisionAllow}, nil
+			}
+			return authorization.Result{Decision: authorization.DecisionDeny}, nil
+		}).Times(2)
+	s.mockResource.DomainCache.EXPECT().GetDomain("test-domain").Return(s.workflowTypeRulesDomainEntry(), nil).Times(1)
+	s.mockFrontendHandler.EXPECT().DescribeWorkflowExecution(ctx, &types.DescribeWorkflowExecutionRequest{
+		Domain:    "test-domain",
+		Execution: &types.WorkflowExecution{WorkflowID: "wid"},
+	}).Return(&types.DescribeWorkflowExecutionResponse{
+		WorkflowExecutionInfo: &types.WorkflowExecutionInfo{
+			Execution: &types.WorkflowExecution{WorkflowID: "wid", RunID: "rid"},
+			Type:      &types.WorkflowType{Name: "PaymentWorkflow"},
+		},
+	}, nil).Times(1)
+
+	_, err := s.handler.SignalWithStartWorkflowExecution(ctx, request)
+	s.Equal(errUnauthorized, err)
+}
+
+func (s *accessControlledHandlerSuite) TestStartWorkflowExecution_ScheduleActionDomainDenied() {
+	ctx := context.Background()
+	input, err := json.Marshal(scheduler.ScheduleParams{
+		ScheduleID: "test-schedule",
+		Schedule:   scheduler.Schedule{Action: scheduler.ScheduleAction{Domain: "test-domain"}},
+	})
+	s.NoError(err)
+	request := &types.StartWorkflowExecutionRequest{
+		Domain:       common.SchedulerLocalDomainName,
+		WorkflowID:   scheduler.GetScheduleWorkflowID("test-domain", "test-schedule"),
+		WorkflowType: &types.WorkflowType{Name: scheduler.ScheduleWFTypeName},
+		Input:        input,
+	}
+
+	s.mockAuthorizer.EXPECT().Authorize(ctx, gomock.Any()).DoAndReturn(
+		func(_ context.Context, attr *authorization.Attributes) (authorization.Result, error) {
+			if attr.DomainName == common.SchedulerLocalDomainName {
+				return authorization.Result{Decision: authorization.DecisionAllow}, nil
+			}
+			s.Equal("test-domain", attr.DomainName)
+			return authorization.Result{Decision: authorization.DecisionDeny}, nil
+		}).Times(2)
+
+	_, err = s.handler.StartWorkflowExecution(ctx, request)
+	s.Equal(errUnauthorized, err)
+}
+
+func (s *accessControlledHandlerSuite) TestSignalWorkflowExecution_ScheduleDomainMismatch() {
+	ctx := context.Background()
+	input, err := json.Marshal(scheduler.Schedule{Action: scheduler.ScheduleAction{Domain: "other-domain"}})
+	s.NoError(err)
+	request := &types.SignalWorkflowExecutionRequest{
+		Domain: common.SchedulerLocalDomainName,
+		WorkflowExecution: &types.WorkflowExecution{
+			WorkflowID: scheduler.GetScheduleWorkflowID("test-domain", "test-schedule"),
+		},
+		SignalName: scheduler.UpdateSignalName,
+		Input:      input,
+	}
+
+	s.mockAuthorizer.EXPECT().Authorize(ctx, gomock.Any()).
+		Return(authorization.Result{Decision: authorization.DecisionAllow}, nil).Times(2)
+
+	err = s.handler.SignalWorkflowExecution(ctx, request)
+	s.Equal(errUnauthorized, err)
+}
+
+func (s *accessControlledHandlerSuite) TestSignalWorkflowExecution_ScheduleDomainDenied() {
+	for _, signalName := range []string{
+		scheduler.UpdateSignalName,
+		scheduler.PauseSignalName,
+		scheduler.UnpauseSignalName,
+		scheduler.TriggerSignalName,
+		scheduler.BackfillSignalName,
+		scheduler.DeleteSignalName,
+	} {
+		ctx := context.Background()
+		request := &types.SignalWorkflowExecutionRequest{
+			Domain: common.SchedulerLocalDomainName,
+			WorkflowExecution: &types.WorkflowExecution{
+				WorkflowID: scheduler.GetScheduleWorkflowID("test-domain", "test-schedule"),
+			},
+			SignalName: signalName,
+		}
+
+		s.expectScheduleDomainDenied(ctx, authorization.PermissionWrite)
+
+		err := s.handler.SignalWorkflowExecution(ctx, request)
+		s.Equal(errUnauthorized, err, signalName)
+	}
+}
+
+func (s *accessControlledHandlerSuite) TestSignalWorkflowExecution_ScheduleDomainAllowed() {
+	ctx := context.Background()
+	request := &types.SignalWorkflowExecutionRequest{
+		Domain: common.SchedulerLocalDomainName,
+		WorkflowExecution: &types.WorkflowExecution{
+			WorkflowID: scheduler.GetScheduleWorkflowID("test-domain", "test-schedule"),
+		},
+		SignalName: scheduler.TriggerSignalName,
+	}
+
+	s.mockAuthorizer.EXPECT().Authorize(ctx, gomock.Any()).
+		Return(authorization.Result{Decision: authorization.DecisionAllow}, nil).Times(2)
+	s.mockFrontendHandler.EXPECT().SignalWorkflowExecution(ctx, request).Return(nil).Times(1)
+
+	err := s.handler.SignalWorkflowExecution(ctx, request)
+	s.NoError(err)
+}
+
+func (s *accessControlledHandlerSuite) TestSignalWorkflowExecution_NotScheduleWorkflowDenied() {
+	ctx := context.Background()
+	request := &types.SignalWorkflowExecutionRequest{
+		Domain:            common.SchedulerLocalDomainName,
+		WorkflowExecution: &types.WorkflowExecution{WorkflowID: "wid"},
+		SignalName:        scheduler.TriggerSignalName,
+	}
+
+	s.mockAuthorizer.EXPECT().Authorize(ctx, gomock.Any()).
+		Return(authorization.Result{Decision: authorization.DecisionAllow}, nil).Times(1)
+
+	err := s.handler.SignalWorkflowExecution(ctx, request)
+	s.Equal(errUnauthorized, err)
+}
+
+func (s *accessControlledHandlerSuite) TestSignalWithStartWorkflowExecution_ScheduleDomainDenied() {
+	ctx := context.Background()
+	request := &types.SignalWithStartWorkflowExecutionRequest{
+		Domain:     common.SchedulerLocalDomainName,
+		WorkflowID: scheduler.GetScheduleWorkflowID("test-domain", "test-schedule"),
+		SignalName: scheduler.TriggerSignalName,
+	}
+	domainEntry := cache.NewLocalDomainCacheEntryForTest(
+		&persistence.DomainInfo{Name: common.SchedulerLocalDomainName},
+		&persistence.DomainConfig{},
+		"",
+		nil,
+	)
+
+	s.expectScheduleDomainDenied(ctx, authorization.PermissionWrite)
+	s.mockResource.DomainCache.EXPECT().GetDomain(common.SchedulerLocalDomainName).Return(domainEntry, nil).Times(1)
+
+	_, err := s.handler.SignalWithStartWorkflowExecution(ctx, request)
+	s.Equal(errUnauthorized, err)
+}
+
+func (s *accessControlledHandlerSuite) TestTerminateWorkflowExecution_ScheduleDomainDenied() {
+	ctx := context.Background()
+	request := &types.TerminateWorkflowExecutionRequest{
+		Domain: common.SchedulerLocalDomainName,
+		WorkflowExecution: &types.WorkflowExecution{
+			WorkflowID: scheduler.GetScheduleWorkflowID("test-domain", "test-schedule"),
+		},
+	}
+
+	s.expectScheduleDomainDenied(ctx, authorization.PermissionWrite)
+
+	err := s.handler.TerminateWorkflowExecution(ctx, request)
+	s.Equal(errUnauthorized, err)
+}
+
+func (s *accessControlledHandlerSuite) TestRequestCancelWorkflowExecution_ScheduleDomainDenied() {
+	ctx := context.Background()
+	request := &types.RequestCancelWorkflowExecutionRequest{
+		Domain: common.SchedulerLocalDomainName,
+		WorkflowExecution: &types.WorkflowExecution{
+			WorkflowID: scheduler.GetScheduleWorkflowID("test-domain", "test-schedule"),
+		},
+	}
+
+	s.expectScheduleDomainDenied(ctx, authorization.PermissionWrite)
+
+	err := s.handler.RequestCancelWorkflowExecution(ctx, request)
+	s.Equal(errUnauthorized, err)
+}
+
+func (s *accessControlledHandlerSuite) TestQueryWorkflow_ScheduleDomainDenied() {
+	ctx := context.Background()
+	request := &types.QueryWorkflowRequest{
+		Domain: common.SchedulerLocalDomainName,
+		Execution: &types.WorkflowExecution{
+			WorkflowID: scheduler.GetScheduleWorkflowID("test-domain", "test-schedule"),
+		},
+		Query: &types.WorkflowQuery{QueryType: scheduler.DescribeQueryType},
+	}
+
+	s.expectScheduleDomainDenied(ctx, authorization.PermissionRead)
+
+	_, err := s.handler.QueryWorkflow(ctx, request)
+	s.Equal(errUnauthorized, err)
+}
+
+// expectScheduleDomainDenied allows the requests on the scheduler domain and denies the requests on the domain
+// of the schedule
+func (s *accessControlledHandlerSuite) expectScheduleDomainDenied(ctx context.Context, permission authorization.Permission) {
+	s.mockAuthorizer.EXPECT().Authorize(ctx, gomock.Any()).DoAndReturn(
+		func(_ context.Context, attr *authorization.Attributes) (authorization.Result, error) {
+			s.Equal(permission, attr.Permission)
+			if attr.DomainName == common.SchedulerLocalDomainName {
+				return authorization.Result{Decision: authorization.DecisionAllow}, nil
+			}
+			s.Equal("test-domain", attr.DomainName)
+			return authorization.Result{Decision: authorization.DecisionDeny}, nil
+		}).Times(2)
+}
+
+func (s *accessControlledHandlerSuite) workflowTypeRulesDomainEntry() *cache.DomainCacheEntry {
+	return cache.NewLocalDomainCacheEntryForTest(
+		&persistence.DomainInfo{
+			Name: "test-domain",
+			Data: map[string]string{
+				common.DomainDataKeyForAuthorizationRules: `[{"groups": ["oncall"], "workflowTypes": ["PaymentWorkflow"]}]`,
+			},
+		},
+		&persistence.DomainConfig{},
+		"",
+		nil,
+	)
+}
+
+func (s *accessControlledHandlerSuite) TestGetRedactor_NoRedaction() {
+	ctx := context.Background()
+	attr := &authorization.Attributes{DomainName: "test-domain", Permission: authorization.PermissionRead}
+	domainEntry := cache.NewLocalDomainCacheEntryForTest(
+		&persistence.DomainInfo{Name: "test-domain"},
+		&persistence.DomainConfig{},
+		"",
+		nil,
+	)
+
+	s.mockResource.DomainCache.EXPECT().GetDomain("test-domain").Return(domainEntry, nil).Times(1)
+
+	redactor, err := s.handler.getRedactor(ctx, attr)
+	s.NoError(err)
+	s.Nil(redactor)
+}
+
+func (s *accessControlledHandlerSuite) TestGetRedactor_SensitiveRead() {
+	ctx := context.Background()
+	attr := &authorization.Attributes{DomainName: "test-domain", Permission: authorization.PermissionRead}
+	domainEntry := cache.NewLocalDomainCacheEntryForTest(
+		&persistence.DomainInfo{
+			Name: "test-domain",
+			Data: map[string]string{common.DomainDataKeyForRedactedPaths: "ssn"},
+		},
+		&persistence.DomainConfig{},
+		"",
+		nil,
+	)
+
+	s.mockResource.DomainCache.EXPECT().GetDomain("test-domain").Return(domainEntry, nil).Times(1)
+	s.mockAuthorizer.EXPECT().Authorize(ctx, &authorization.Attributes{
+		DomainName: "test-domain",
+		Permission: authorization.PermissionSensitiveRead,
+	}).Return(authorization.Result{Decision: authorization.DecisionAllow}, nil).Times(1)
+
+	redactor, err := s.handler.getRedactor(ctx, attr)
+	s.NoError(err)
+	s.Nil(redactor)
+	s.Equal(authorization.PermissionRead, attr.Permission)
+}
+
+func (s *accessControlledHandlerSuite) TestQueryWorkflow_Redacted() {
+	ctx := context.Background()
+	request := &types.QueryWorkflowRequest{
+		Domain:    "test-domain",
+		Execution: &types.WorkflowExecution{WorkflowID: "wid", RunID: "rid"},
+	}
+	domainEntry := cache.NewLocalDomainCacheEntryForTest(
+		&persistence.DomainInfo{
+			Name: "test-domain",
+			Data: map[string]string{common.DomainDataKeyForRedactedPaths: "ssn"},
+		},
+		&persistence.DomainConfig{},
+		"",
+		nil,
+	)
+
+	gomock.InOrder(
+		s.mockAuthorizer.EXPECT().Authorize(ctx, gomock.Any()).
+			Return(authorization.Result{Decision: authorization.DecisionAllow}, nil).Times(1),
+		s.mockAuthorizer.EXPECT().Authorize(ctx, gomock.Any()).
+			Return(authorization.Result{Decision: authorization.DecisionDeny}, nil).Times(1),
+	)
+	s.mockFrontendHandler.EXPECT().QueryWorkflow(ctx, request).Return(&types.QueryWorkflowResponse{
+		QueryResult: []byte(`{"ssn":"123","name":"x"}`),
+	}, nil).Times(1)
+	s.mockResource.DomainCache.EXPECT().GetDomain("test-domain").Return(domainEntry, nil).Times(1)
+
+	resp, err := s.handler.QueryWorkflow(ctx, request)
+	s.NoError(err)
+	s.Equal(`{"name":"x","ssn":"[REDACTED]"}`+"\n", string(resp.QueryResult))
+}
+
+func (s *accessControlledHandlerSuite) TestErrAdminPermissionDenied() {
+	err := errAdminPermissionDenied(&authorization.Attributes{APIName: "PurgeDLQMessages", Permission: authorization.PermissionAdmin})
+	s.Equal(&types.AccessDeniedError{
+		Message: "Request unauthorized, PurgeDLQMessages requires the admin permission or the dlq admin permission.",
+	}, err)
+
+	err = errAdminPermissionDenied(&authorization.Attributes{APIName: "UnknownAPI", Permission: authorization.PermissionAdmin})
+	s.Equal(&types.AccessDeniedError{Message: "Request unauthorized, UnknownAPI requires the admin permission."}, err)
+}
+
+func (s *accessControlledHandlerSuite) TestIsFailoverOnlyRequest() {
+	activeCluster := "cluster1"
+	description := "description"
+	s.True(isFailoverOnlyRequest(&types.UpdateDomainRequest{Name: "domain", ActiveClusterName: &activeCluster}))
+	s.False(isFailoverOnlyRequest(&types.UpdateDomainRequest{Name: "domain", Description: &description}))
+	s.False(isFailoverOnlyRequest(&types.UpdateDomainRequest{Name: "domain", ActiveClusterName: &activeCluster, Description: &description}))
+}
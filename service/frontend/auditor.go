@@ -0,0 +1,97 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package frontend
+
+import (
+	"context"
+	"time"
+
+	"github.com/uber/cadence/common/audit"
+	"github.com/uber/cadence/common/authorization"
+	"github.com/uber/cadence/common/clock"
+	"github.com/uber/cadence/common/log"
+	"github.com/uber/cadence/common/log/tag"
+	"github.com/uber/cadence/common/metrics"
+	"github.com/uber/cadence/common/resource"
+	"github.com/uber/cadence/common/types"
+)
+
+const (
+	auditBufferSize  = 10000
+	auditEmitTimeout = 5 * time.Second
+)
+
+// auditor records the mutating API calls seen by the access controlled handlers
+type auditor struct {
+	sink         audit.Sink
+	logger       log.Logger
+	metricsScope metrics.Scope
+	timeSource   clock.TimeSource
+}
+
+func newAuditor(sink audit.Sink, resource resource.Resource) *auditor {
+	if sink == nil {
+		sink = audit.NewNopSink()
+	}
+	return &auditor{
+		sink:         sink,
+		logger:       resource.GetLogger(),
+		metricsScope: resource.GetMetricsClient().Scope(metrics.FrontendAuditScope),
+		timeSource:   resource.GetTimeSource(),
+	}
+}
+
+// record emits the audit event of the API call with its result, failing to emit it doesn't fail the call.
+// The event is emitted even when the call context is done, not to lose the record of a call timing out.
+// The sink of the frontend service is buffered, so the call is not delayed by emitting the event.
+func (a *auditor) record(
+	attr *authorization.Attributes,
+	domain string,
+	execution *types.WorkflowExecution,
+	reason string,
+	err error,
+) {
+	event := &audit.Event{
+		Timestamp:  a.timeSource.Now(),
+		Actor:      attr.Actor,
+		API:        attr.APIName,
+		Domain:     domain,
+		WorkflowID: execution.GetWorkflowID(),
+		RunID:      execution.GetRunID(),
+		Reason:     reason,
+		Result:     audit.ResultSuccess,
+	}
+	if err == errUnauthorized {
+		event.Result = audit.ResultUnauthorized
+	} else if err != nil {
+		event.Result = audit.ResultFailure
+		event.Error = err.Error()
+	}
+
+	if emitErr := a.sink.Emit(context.Background(), event); emitErr != nil {
+		a.metricsScope.IncCounter(metrics.CadenceErrAuditFailedCounter)
+		a.logger.Error("failed to emit audit event",
+			tag.WorkflowDomainName(event.Domain),
+			tag.WorkflowID(event.WorkflowID),
+			tag.WorkflowRunID(event.RunID),
+			tag.Error(emitErr))
+	}
+}
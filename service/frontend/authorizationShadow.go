@@ -0,0 +1,74 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package frontend
+
+import (
+	"github.com/uber/cadence/common/authorization"
+	"github.com/uber/cadence/common/config"
+	"github.com/uber/cadence/common/dynamicconfig"
+	"github.com/uber/cadence/common/log"
+	"github.com/uber/cadence/common/log/loggerimpl"
+	"github.com/uber/cadence/common/log/tag"
+	"github.com/uber/cadence/common/metrics"
+	"github.com/uber/cadence/common/resource"
+)
+
+// authorizationShadowLogRPS is the rate of the logs of the requests allowed in shadow mode, the denied
+// requests are counted by the metric and only a sample of them is logged
+const authorizationShadowLogRPS = 1
+
+// authorizationShadow allows the requests denied by the authorizer when authorization is in shadow mode,
+// reporting them so that authorization can be rolled out without rejecting calls
+type authorizationShadow struct {
+	logger       log.Logger
+	metricsScope metrics.Scope
+}
+
+// newAuthorizationShadow returns nil when authorization is not in shadow mode
+func newAuthorizationShadow(cfg config.Authorization, resource resource.Resource) *authorizationShadow {
+	if !cfg.ShadowMode {
+		return nil
+	}
+	return &authorizationShadow{
+		logger:       loggerimpl.NewThrottledLogger(resource.GetLogger(), dynamicconfig.GetIntPropertyFn(authorizationShadowLogRPS)),
+		metricsScope: resource.GetMetricsClient().Scope(metrics.FrontendAuthorizationShadowScope),
+	}
+}
+
+// allow counts the request denied by the authorizer, or failing to be authorized, logs it if the log rate allows,
+// and returns whether it's allowed anyway
+func (s *authorizationShadow) allow(attr *authorization.Attributes, err error) bool {
+	if s == nil {
+		return false
+	}
+	s.metricsScope.Tagged(metrics.DomainTag(attr.DomainName), metrics.APINameTag(attr.APIName)).
+		IncCounter(metrics.CadenceAuthorizationShadowDeniedCounter)
+	tags := []tag.Tag{
+		tag.WorkflowHandlerName(attr.APIName),
+		tag.WorkflowDomainName(attr.DomainName),
+		tag.Actor(attr.Actor),
+	}
+	if err != nil {
+		tags = append(tags, tag.Error(err))
+	}
+	s.logger.Warn("request would be denied by authorization, allowed in shadow mode", tags...)
+	return true
+}
@@ -27,11 +27,17 @@ import (
 	"time"
 
 	"github.com/uber/cadence/common"
+	"github.com/uber/cadence/common/audit"
 	"github.com/uber/cadence/common/client"
+	"github.com/uber/cadence/common/config"
 	"github.com/uber/cadence/common/definition"
 	"github.com/uber/cadence/common/domain"
 	"github.com/uber/cadence/common/dynamicconfig"
+	"github.com/uber/cadence/common/log/tag"
 	"github.com/uber/cadence/common/messaging"
+	"github.com/uber/cadence/common/metrics"
+	"github.com/uber/cadence/common/persistence"
+	persistenceClient "github.com/uber/cadence/common/persistence/client"
 	"github.com/uber/cadence/common/resource"
 	"github.com/uber/cadence/common/service"
 )
@@ -185,6 +191,7 @@ type Service struct {
 	stopC        chan struct{}
 	config       *Config
 	params       *resource.Params
+	auditSink    audit.Sink
 }
 
 // NewService builds a new cadence-frontend service
@@ -231,15 +238,49 @@ func NewService(
 		return nil, err
 	}
 
+	auditSink, err := newAuditSink(params, serviceConfig)
+	if err != nil {
+		return nil, err
+	}
+
 	return &Service{
-		Resource: serviceResource,
-		status:   common.DaemonStatusInitialized,
-		config:   serviceConfig,
-		stopC:    make(chan struct{}),
-		params:   params,
+		Resource:  serviceResource,
+		status:    common.DaemonStatusInitialized,
+		config:    serviceConfig,
+		stopC:     make(chan struct{}),
+		params:    params,
+		auditSink: auditSink,
 	}, nil
 }
 
+func newAuditSink(params *resource.Params, serviceConfig *Config) (audit.Sink, error) {
+	var queue persistence.QueueManager
+	if params.AuditConfig.HasSink(config.AuditSinkPersistence) {
+		var err error
+		queue, err = persistenceClient.NewFactory(
+			&params.PersistenceConfig,
+			func(...dynamicconfig.FilterOption) int { return serviceConfig.PersistenceMaxQPS() },
+			params.ClusterMetadata.GetCurrentClusterName(),
+			params.MetricsClient,
+			params.Logger,
+		).NewAuditQueueManager()
+		if err != nil {
+			return nil, err
+		}
+	}
+	sink, err := audit.NewSink(params.AuditConfig, params.MessagingClient, queue, params.Logger)
+	if err != nil || len(params.AuditConfig.Sinks) == 0 {
+		return sink, err
+	}
+	return audit.NewBufferedSink(
+		sink,
+		auditBufferSize,
+		auditEmitTimeout,
+		params.Logger,
+		params.MetricsClient.Scope(metrics.FrontendAuditScope),
+	), nil
+}
+
 // Start starts the service
 func (s *Service) Start() {
 	if !atomic.CompareAndSwapInt32(&s.status, common.DaemonStatusInitialized, common.DaemonStatusStarted) {
@@ -266,7 +307,7 @@ func (s *Service) Start() {
 		handler = NewClusterRedirectionHandler(handler, s, s.config, *s.params.ClusterRedirectionPolicy)
 	}
 
-	handler = NewAccessControlledHandlerImpl(handler, s, s.params.Authorizer, s.params.AuthorizationConfig)
+	handler = NewAccessControlledHandlerImpl(handler, s, s.params.Authorizer, s.params.AuthorizationConfig, s.auditSink)
 
 	// Register the latest (most decorated) handler
 	thriftHandler := NewThriftHandler(handler)
@@ -276,7 +317,7 @@ func (s *Service) Start() {
 	grpcHandler.register(s.GetDispatcher())
 
 	s.adminHandler = NewAdminHandler(s, s.params, s.config)
-	s.adminHandler = NewAccessControlledAdminHandlerImpl(s.adminHandler, s, s.params.Authorizer, s.params.AuthorizationConfig)
+	s.adminHandler = NewAccessControlledAdminHandlerImpl(s.adminHandler, s, s.params.Authorizer, s.params.AuthorizationConfig, s.auditSink)
 
 	adminThriftHandler := NewAdminThriftHandler(s.adminHandler)
 	adminThriftHandler.register(s.GetDispatcher())
@@ -324,6 +365,10 @@ func (s *Service) Stop() {
 	s.GetLogger().Info("ShutdownHandler: Draining traffic")
 	time.Sleep(requestDrainTime)
 
+	if err := s.auditSink.Close(); err != nil {
+		s.GetLogger().Error("failed to close audit sink", tag.Error(err))
+	}
+
 	close(s.stopC)
 	s.Resource.Stop()
 	s.params.Logger.Info("frontend stopped")
@@ -36,6 +36,7 @@ import (
 	"github.com/uber/cadence/client/frontend"
 	"github.com/uber/cadence/common"
 	"github.com/uber/cadence/common/archiver"
+	"github.com/uber/cadence/common/authorization"
 	"github.com/uber/cadence/common/backoff"
 	"github.com/uber/cadence/common/cache"
 	"github.com/uber/cadence/common/client"
@@ -133,6 +134,7 @@ var (
 	errEmptyReplicationInfo                       = &types.BadRequestError{Message: "Replication task info is not set."}
 	errEmptyQueueType                             = &types.BadRequestError{Message: "Queue type is not set."}
 	errShuttingDown                               = &types.InternalServiceError{Message: "Shutting down"}
+	errPauseSignalWithStart                       = &types.BadRequestError{Message: "Pause and unpause signals cannot be used with SignalWithStart."}
 
 	// err for archival
 	errHistoryNotFound = &types.BadRequestError{Message: "Requested workflow history not found, may have passed retention period."}
@@ -284,6 +286,10 @@ func (wh *WorkflowHandler) RegisterDomain(ctx context.Context, registerRequest *
 		return err
 	}
 
+	if err := checkAuthorizationRules(registerRequest.GetData()); err != nil {
+		return err
+	}
+
 	if registerRequest.GetName() == "" {
 		return errDomainNotSet
 	}
@@ -415,6 +421,10 @@ func (wh *WorkflowHandler) UpdateDomain(
 	if updateRequest.GetName() == "" {
 		return nil, errDomainNotSet
 	}
+
+	if err := checkAuthorizationRules(updateRequest.Data); err != nil {
+		return nil, err
+	}
 	// TODO: call remote clusters to verify domain data
 	resp, err := wh.domainHandler.UpdateDomain(ctx, updateRequest)
 	if err != nil {
@@ -2487,6 +2497,10 @@ func (wh *WorkflowHandler) SignalWithStartWorkflowExecution(
 		return nil, wh.error(errSignalNameTooLong, scope, tags...)
 	}
 
+	if common.IsPauseSignalName(signalWithStartRequest.GetSignalName()) {
+		return nil, wh.error(errPauseSignalWithStart, scope, tags...)
+	}
+
 	if signalWithStartRequest.WorkflowType == nil || signalWithStartRequest.WorkflowType.GetName() == "" {
 		return nil, wh.error(errWorkflowTypeNotSet, scope, tags...)
 	}
@@ -4304,6 +4318,17 @@ func checkRequiredDomainDataKVs(requiredDomainDataKeys map[string]interface{}, d
 	return nil
 }
 
+// checkAuthorizationRules rejects the domain data whose authorization rules or API keys are malformed
+func checkAuthorizationRules(domainData map[string]string) error {
+	if _, err := authorization.ParseRules(domainData); err != nil {
+		return &types.BadRequestError{Message: err.Error()}
+	}
+	if _, err := authorization.ParseAPIKeys(domainData); err != nil {
+		return &types.BadRequestError{Message: err.Error()}
+	}
+	return nil
+}
+
 // Some error types are introduced later that some clients might not support
 // To make them backward compatible, we continue returning the legacy error types
 // for older clients
@@ -299,6 +299,13 @@ type Config struct {
 	NotifyFailoverMarkerTimerJitterCoefficient dynamicconfig.FloatPropertyFn
 	EnableGracefulFailover                     dynamicconfig.BoolPropertyFn
 
+	// Domain storage quota
+	EnableDomainStorageTracking dynamicconfig.BoolPropertyFn
+	DomainStorageScanInterval   dynamicconfig.DurationPropertyFn
+	DomainStorageScanPageSize   dynamicconfig.IntPropertyFn
+	DomainStorageScanRPS        dynamicconfig.IntPropertyFn
+	DomainStorageQuota          dynamicconfig.IntPropertyFnWithDomainFilter
+
 	// Allows worker to dispatch activity tasks through local tunnel after decisions are made. This is an performance optimization to skip activity scheduling efforts.
 	EnableActivityLocalDispatchByDomain dynamicconfig.BoolPropertyFnWithDomainFilter
 
@@ -557,6 +564,12 @@ func New(dc *dynamicconfig.Collection, numberOfShards int, storeType string, isA
 		NotifyFailoverMarkerTimerJitterCoefficient: dc.GetFloat64Property(dynamicconfig.NotifyFailoverMarkerTimerJitterCoefficient, 0.15),
 		EnableGracefulFailover:                     dc.GetBoolProperty(dynamicconfig.EnableGracefulFailover, true),
 
+		EnableDomainStorageTracking: dc.GetBoolProperty(dynamicconfig.EnableDomainStorageTracking, false),
+		DomainStorageScanInterval:   dc.GetDurationProperty(dynamicconfig.DomainStorageScanInterval, 6*time.Hour),
+		DomainStorageScanPageSize:   dc.GetIntProperty(dynamicconfig.DomainStorageScanPageSize, 500),
+		DomainStorageScanRPS:        dc.GetIntProperty(dynamicconfig.DomainStorageScanRPS, 1),
+		DomainStorageQuota:          dc.GetIntPropertyFilteredByDomain(dynamicconfig.DomainStorageQuota, 0),
+
 		EnableActivityLocalDispatchByDomain: dc.GetBoolPropertyFilteredByDomain(dynamicconfig.EnableActivityLocalDispatchByDomain, false),
 
 		ActivityMaxScheduleToStartTimeoutForRetry: dc.GetDurationPropertyFilteredByDomain(dynamicconfig.ActivityMaxScheduleToStartTimeoutForRetry, 30*time.Minute),
@@ -29,6 +29,7 @@ import (
 	"github.com/uber/cadence/common"
 	"github.com/uber/cadence/common/backoff"
 	"github.com/uber/cadence/common/cache"
+	"github.com/uber/cadence/common/definition"
 	"github.com/uber/cadence/common/elasticsearch/validator"
 	"github.com/uber/cadence/common/log"
 	"github.com/uber/cadence/common/log/tag"
@@ -711,6 +712,12 @@ func (v *attrValidator) validateStartChildExecutionAttributes(
 		return err
 	}
 
+	for key := range attributes.GetSearchAttributes().GetIndexedFields() {
+		if definition.IsSystemIndexedKey(key) {
+			return &types.BadRequestError{Message: fmt.Sprintf("%s is read-only Cadence reservered attribute", key)}
+		}
+	}
+
 	// Inherit tasklist from parent workflow execution if not provided on decision
 	taskList, err := v.validatedTaskList(attributes.TaskList, parentInfo.TaskList, metricsScope, attributes.GetDomain())
 	if err != nil {
@@ -174,6 +174,10 @@ func (s *attrValidatorSuite) TestValidateUpsertWorkflowSearchAttributes() {
 	attributes.SearchAttributes.IndexedFields = map[string][]byte{"CustomKeywordField": []byte(`"bytes"`)}
 	err = s.validator.validateUpsertWorkflowSearchAttributes(domainName, attributes)
 	s.Nil(err)
+
+	attributes.SearchAttributes.IndexedFields = map[string][]byte{definition.IsPaused: []byte(`true`)}
+	err = s.validator.validateUpsertWorkflowSearchAttributes(domainName, attributes)
+	s.EqualError(err, "BadRequestError{Message: IsPaused is read-only Cadence reservered attribute}")
 }
 
 func (s *attrValidatorSuite) TestValidateCrossDomainCall_LocalToLocal() {
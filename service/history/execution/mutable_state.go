@@ -219,6 +219,7 @@ type (
 		UpdateUserTimer(*persistence.TimerInfo) error
 		UpdateCurrentVersion(version int64, forceUpdate bool) error
 		UpdateWorkflowStateCloseStatus(state int, closeStatus int) error
+		UpdateWorkflowPauseState(paused bool) error
 
 		AddTransferTasks(transferTasks ...persistence.Task)
 		AddCrossClusterTasks(crossClusterTasks ...persistence.Task)
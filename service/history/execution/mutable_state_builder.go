@@ -3269,6 +3269,24 @@ func (e *mutableStateBuilder) ReplicateWorkflowExecutionSignaled(
 	return nil
 }
 
+// UpdateWorkflowPauseState updates whether the workflow is paused, the pause state is kept in
+// the IsPaused search attribute so that it shows in describe and visibility. It's not recorded
+// in the history, so it's only kept by the mutable state of the active cluster.
+func (e *mutableStateBuilder) UpdateWorkflowPauseState(paused bool) error {
+	bytes, err := json.Marshal(paused)
+	if err != nil {
+		return err
+	}
+	if e.executionInfo.SearchAttributes == nil {
+		e.executionInfo.SearchAttributes = make(map[string][]byte)
+	}
+	e.executionInfo.SearchAttributes[definition.IsPaused] = bytes
+	if e.shard.GetConfig().AdvancedVisibilityWritingMode() != common.AdvancedVisibilityWritingModeOff {
+		return e.taskGenerator.GenerateWorkflowSearchAttrTasks()
+	}
+	return nil
+}
+
 func (e *mutableStateBuilder) AddContinueAsNewEvent(
 	ctx context.Context,
 	firstEventID int64,
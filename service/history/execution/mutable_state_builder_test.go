@@ -532,6 +532,18 @@ func (s *mutableStateSuite) TestEventReapplied() {
 	s.True(isReapplied)
 }
 
+func (s *mutableStateSuite) TestUpdateWorkflowPauseState() {
+	s.mockShard.GetConfig().AdvancedVisibilityWritingMode = dynamicconfig.GetStringPropertyFn(common.AdvancedVisibilityWritingModeOff)
+	s.False(IsWorkflowPaused(s.msBuilder))
+
+	s.NoError(s.msBuilder.UpdateWorkflowPauseState(true))
+	s.True(IsWorkflowPaused(s.msBuilder))
+	s.Equal(int64(0), s.msBuilder.GetExecutionInfo().SignalCount)
+
+	s.NoError(s.msBuilder.UpdateWorkflowPauseState(false))
+	s.False(IsWorkflowPaused(s.msBuilder))
+}
+
 func (s *mutableStateSuite) TestTransientDecisionTaskSchedule_CurrentVersionChanged() {
 	version := int64(2000)
 	runID := uuid.New()
@@ -2391,7 +2391,20 @@ func (mr *MockMutableStateMockRecorder) UpdateWorkflowStateCloseStatus(state, cl
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateWorkflowStateCloseStatus", reflect.TypeOf((*MockMutableState)(nil).UpdateWorkflowStateCloseStatus), state, closeStatus)
 }
 
-// AddTransferTasks mocks base method
+// UpdateWorkflowPauseState mocks base method
+func (m *MockMutableState) UpdateWorkflowPauseState(paused bool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateWorkflowPauseState", paused)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateWorkflowPauseState indicates an expected call of UpdateWorkflowPauseState
+func (mr *MockMutableStateMockRecorder) UpdateWorkflowPauseState(paused interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateWorkflowPauseState", reflect.TypeOf((*MockMutableState)(nil).UpdateWorkflowPauseState), paused)
+}
+
 func (m *MockMutableState) AddTransferTasks(transferTasks ...persistence.Task) {
 	m.ctrl.T.Helper()
 	varargs := []interface{}{}
@@ -26,6 +26,7 @@ import (
 
 	"github.com/uber/cadence/common/cache"
 	"github.com/uber/cadence/common/clock"
+	"github.com/uber/cadence/common/definition"
 	"github.com/uber/cadence/common/persistence"
 	"github.com/uber/cadence/common/types"
 )
@@ -242,6 +243,17 @@ func ScheduleDecision(
 	return nil
 }
 
+// IsWorkflowPaused returns whether the workflow is paused, i.e. its decision and activity tasks are
+// not dispatched and its timers are deferred until it's unpaused
+func IsWorkflowPaused(mutableState MutableState) bool {
+	value, ok := mutableState.GetExecutionInfo().SearchAttributes[definition.IsPaused]
+	if !ok {
+		return false
+	}
+	var paused bool
+	return json.Unmarshal(value, &paused) == nil && paused
+}
+
 // FindAutoResetPoint returns the auto reset point
 func FindAutoResetPoint(
 	timeSource clock.TimeSource,
@@ -24,10 +24,13 @@ import (
 	"testing"
 	"time"
 
+	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
 
 	"github.com/uber/cadence/common"
 	"github.com/uber/cadence/common/clock"
+	"github.com/uber/cadence/common/definition"
+	"github.com/uber/cadence/common/persistence"
 	"github.com/uber/cadence/common/types"
 )
 
@@ -148,3 +151,26 @@ func TestFindAutoResetPoint(t *testing.T) {
 	})
 	assert.Equal(t, pt, pt5)
 }
+
+func TestIsWorkflowPaused(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	testCases := []struct {
+		searchAttributes map[string][]byte
+		expected         bool
+	}{
+		{searchAttributes: nil, expected: false},
+		{searchAttributes: map[string][]byte{definition.IsPaused: []byte("true")}, expected: true},
+		{searchAttributes: map[string][]byte{definition.IsPaused: []byte("false")}, expected: false},
+		{searchAttributes: map[string][]byte{definition.IsPaused: []byte("invalid")}, expected: false},
+	}
+
+	for _, tc := range testCases {
+		mutableState := NewMockMutableState(controller)
+		mutableState.EXPECT().GetExecutionInfo().Return(&persistence.WorkflowExecutionInfo{
+			SearchAttributes: tc.searchAttributes,
+		}).Times(1)
+		assert.Equal(t, tc.expected, IsWorkflowPaused(mutableState))
+	}
+}
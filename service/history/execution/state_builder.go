@@ -106,6 +106,12 @@ func (b *stateBuilderImpl) ApplyEvents(
 
 	// need to clear the stickiness since workflow turned to passive
 	b.mutableState.ClearStickyness()
+	// the pause state is kept by the active cluster only, so it's cleared as well
+	if IsWorkflowPaused(b.mutableState) {
+		if err := b.mutableState.UpdateWorkflowPauseState(false); err != nil {
+			return nil, err
+		}
+	}
 
 	for _, event := range history {
 		// NOTE: stateBuilder is also being used in the active side
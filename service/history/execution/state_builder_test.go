@@ -33,6 +33,7 @@ import (
 	"github.com/uber/cadence/common/backoff"
 	"github.com/uber/cadence/common/cache"
 	"github.com/uber/cadence/common/cluster"
+	"github.com/uber/cadence/common/definition"
 	"github.com/uber/cadence/common/log"
 	"github.com/uber/cadence/common/persistence"
 	"github.com/uber/cadence/common/types"
@@ -568,6 +569,36 @@ func (s *stateBuilderSuite) TestApplyEvents_EventTypeWorkflowExecutionSignaled()
 	s.Nil(err)
 }
 
+func (s *stateBuilderSuite) TestApplyEvents_ClearPauseState() {
+	version := int64(1)
+	requestID := uuid.New()
+
+	workflowExecution := types.WorkflowExecution{
+		WorkflowID: "some random workflow ID",
+		RunID:      constants.TestRunID,
+	}
+
+	now := time.Now()
+	evenType := types.EventTypeWorkflowExecutionSignaled
+	event := &types.HistoryEvent{
+		Version:                                  version,
+		EventID:                                  130,
+		Timestamp:                                common.Int64Ptr(now.UnixNano()),
+		EventType:                                &evenType,
+		WorkflowExecutionSignaledEventAttributes: &types.WorkflowExecutionSignaledEventAttributes{},
+	}
+	s.mockUpdateVersion(event)
+	s.mockMutableState.EXPECT().GetExecutionInfo().Return(&persistence.WorkflowExecutionInfo{
+		SearchAttributes: map[string][]byte{definition.IsPaused: []byte("true")},
+	}).AnyTimes()
+	s.mockMutableState.EXPECT().ReplicateWorkflowExecutionSignaled(event).Return(nil).Times(1)
+	s.mockMutableState.EXPECT().ClearStickyness().Times(1)
+	s.mockMutableState.EXPECT().UpdateWorkflowPauseState(false).Return(nil).Times(1)
+
+	_, err := s.stateBuilder.ApplyEvents(constants.TestDomainID, requestID, workflowExecution, s.toHistory(event), nil)
+	s.Nil(err)
+}
+
 func (s *stateBuilderSuite) TestApplyEvents_EventTypeWorkflowExecutionCancelRequested() {
 	version := int64(1)
 	requestID := uuid.New()
@@ -1097,7 +1097,29 @@ func (h *handlerImpl) SignalWorkflowExecution(
 		return h.error(err1, scope, domainID, workflowID)
 	}
 
-	err2 := engine.SignalWorkflowExecution(ctx, wrappedRequest)
+	// pausing and unpausing workflows is requested with the reserved signal names
+	var err2 error
+	signalRequest := wrappedRequest.SignalRequest
+	switch signalRequest.GetSignalName() {
+	case common.PauseWorkflowSignalName:
+		err2 = engine.PauseWorkflowExecution(ctx, &types.HistoryPauseWorkflowExecutionRequest{
+			DomainUUID:        domainID,
+			WorkflowExecution: workflowExecution,
+			Reason:            string(signalRequest.GetInput()),
+			Identity:          signalRequest.GetIdentity(),
+			RequestID:         signalRequest.GetRequestID(),
+		})
+	case common.UnpauseWorkflowSignalName:
+		err2 = engine.UnpauseWorkflowExecution(ctx, &types.HistoryUnpauseWorkflowExecutionRequest{
+			DomainUUID:        domainID,
+			WorkflowExecution: workflowExecution,
+			Reason:            string(signalRequest.GetInput()),
+			Identity:          signalRequest.GetIdentity(),
+			RequestID:         signalRequest.GetRequestID(),
+		})
+	default:
+		err2 = engine.SignalWorkflowExecution(ctx, wrappedRequest)
+	}
 	if err2 != nil {
 		return h.error(err2, scope, domainID, workflowID)
 	}
@@ -31,6 +31,7 @@ import (
 	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
 
+	"github.com/uber/cadence/common"
 	"github.com/uber/cadence/common/cluster"
 	"github.com/uber/cadence/common/log/loggerimpl"
 	"github.com/uber/cadence/common/metrics"
@@ -114,6 +115,34 @@ func (s *handlerSuite) TestGetCrossClusterTasks() {
 	}
 }
 
+func (s *handlerSuite) TestSignalWorkflowExecution_PauseSignal() {
+	domainID := "some random domain ID"
+	workflowExecution := &types.WorkflowExecution{
+		WorkflowID: "some random workflow ID",
+		RunID:      "some random run ID",
+	}
+	s.mockShardController.EXPECT().GetEngine(workflowExecution.WorkflowID).Return(s.mockEngine, nil).Times(1)
+	s.mockEngine.EXPECT().PauseWorkflowExecution(gomock.Any(), &types.HistoryPauseWorkflowExecutionRequest{
+		DomainUUID:        domainID,
+		WorkflowExecution: workflowExecution,
+		Reason:            "some random reason",
+		Identity:          "some random identity",
+		RequestID:         "some random request ID",
+	}).Return(nil).Times(1)
+
+	err := s.handler.SignalWorkflowExecution(context.Background(), &types.HistorySignalWorkflowExecutionRequest{
+		DomainUUID: domainID,
+		SignalRequest: &types.SignalWorkflowExecutionRequest{
+			WorkflowExecution: workflowExecution,
+			SignalName:        common.PauseWorkflowSignalName,
+			Input:             []byte("some random reason"),
+			Identity:          "some random identity",
+			RequestID:         "some random request ID",
+		},
+	})
+	s.NoError(err)
+}
+
 func (s *handlerSuite) TestRespondCrossClusterTaskCompleted_FetchNewTask() {
 	s.testRespondCrossClusterTaskCompleted(true)
 }
@@ -61,6 +61,7 @@ import (
 	"github.com/uber/cadence/service/history/replication"
 	"github.com/uber/cadence/service/history/reset"
 	"github.com/uber/cadence/service/history/shard"
+	"github.com/uber/cadence/service/history/storage"
 	"github.com/uber/cadence/service/history/task"
 	"github.com/uber/cadence/service/history/workflow"
 	warchiver "github.com/uber/cadence/service/worker/archiver"
@@ -79,7 +80,8 @@ const (
 )
 
 var (
-	errDomainDeprecated = &types.BadRequestError{Message: "Domain is deprecated."}
+	errDomainDeprecated           = &types.BadRequestError{Message: "Domain is deprecated."}
+	errDomainStorageQuotaExceeded = &types.LimitExceededError{Message: "Domain storage quota exceeded."}
 )
 
 type (
@@ -117,6 +119,7 @@ type (
 		clientChecker              client.VersionChecker
 		replicationDLQHandler      replication.DLQHandler
 		failoverMarkerNotifier     failover.MarkerNotifier
+		domainStorageScanner       storage.Scanner
 	}
 )
 
@@ -189,6 +192,14 @@ func NewEngineWithShardContext(
 		queueTaskProcessor:     queueTaskProcessor,
 		clientChecker:          client.NewVersionChecker(),
 		failoverMarkerNotifier: failoverMarkerNotifier,
+		domainStorageScanner: storage.NewScanner(
+			shard.GetShardID(),
+			executionManager,
+			shard.GetService().GetDomainStorageTracker(),
+			config,
+			shard.GetMetricsClient(),
+			logger,
+		),
 		replicationAckManager: replication.NewTaskAckManager(
 			shard,
 			executionCache,
@@ -347,6 +358,7 @@ func (e *historyEngineImpl) Start() {
 	if e.config.EnableGracefulFailover() {
 		e.failoverMarkerNotifier.Start()
 	}
+	e.domainStorageScanner.Start()
 }
 
 // Stop the service.
@@ -369,6 +381,7 @@ func (e *historyEngineImpl) Stop() {
 	}
 
 	e.failoverMarkerNotifier.Stop()
+	e.domainStorageScanner.Stop()
 
 	// unset the failover callback
 	e.shard.GetDomainCache().UnregisterDomainChangeCallback(e.shard.GetShardID())
@@ -554,6 +567,34 @@ type signalWithStartArg struct {
 	prevMutableState       execution.MutableState
 }
 
+// checkDomainStorageQuota rejects new workflow executions of domains using more storage than their quota.
+// Workflows are evenly distributed across shards, so each shard may use its share of the quota. The decision
+// only depends on the shard of the workflow, and not on the other shards owned by the host.
+func (e *historyEngineImpl) checkDomainStorageQuota(
+	domainEntry *cache.DomainCacheEntry,
+	metricsScope int,
+) error {
+	if !e.config.EnableDomainStorageTracking() {
+		return nil
+	}
+	domainName := domainEntry.GetInfo().Name
+	quota := e.config.DomainStorageQuota(domainName)
+	if quota <= 0 {
+		return nil
+	}
+	shardQuota := int64(quota) / int64(e.config.NumberOfShards)
+	usage, ok := e.shard.GetService().GetDomainStorageTracker().GetDomainShardUsage(e.shard.GetShardID(), domainEntry.GetInfo().ID)
+	if !ok || usage < shardQuota {
+		return nil
+	}
+	e.metricsClient.Scope(metricsScope, metrics.DomainTag(domainName)).IncCounter(metrics.DomainStorageQuotaExceededCounter)
+	e.throttledLogger.Warn("Domain storage quota exceeded, rejecting new workflow execution.",
+		tag.WorkflowDomainName(domainName),
+		tag.Number(usage),
+	)
+	return errDomainStorageQuotaExceeded
+}
+
 func (e *historyEngineImpl) newDomainNotActiveError(
 	domainName string,
 	failoverVersion int64,
@@ -578,6 +619,10 @@ func (e *historyEngineImpl) startWorkflowHelper(
 		return nil, errDomainDeprecated
 	}
 
+	if err := e.checkDomainStorageQuota(domainEntry, metricsScope); err != nil {
+		return nil, err
+	}
+
 	request := startRequest.StartRequest
 	err := e.validateStartWorkflowExecutionRequest(request, metricsScope)
 	if err != nil {
@@ -644,6 +689,31 @@ func (e *historyEngineImpl) startWorkflowHelper(
 		}
 	}
 
+	// the running run is looked up before any history is persisted, so using it leaves no history branch behind
+	if !isSignalWithStart && request.GetWorkflowIDConflictPolicy() == types.WorkflowIDConflictPolicyUseExisting {
+		currentExecution, err := e.executionManager.GetCurrentExecution(ctx, &persistence.GetCurrentExecutionRequest{
+			DomainID:   domainID,
+			WorkflowID: workflowID,
+		})
+		switch err.(type) {
+		case nil:
+			if shouldUseExisting(startRequest, currentExecution.State) {
+				if curMutableState.GetCurrentVersion() < currentExecution.LastWriteVersion {
+					return nil, e.newDomainNotActiveError(
+						domainEntry.GetInfo().Name,
+						currentExecution.LastWriteVersion,
+					)
+				}
+				return &types.StartWorkflowExecutionResponse{
+					RunID: currentExecution.RunID,
+				}, nil
+			}
+		case *types.EntityNotExistsError:
+		default:
+			return nil, err
+		}
+	}
+
 	err = e.addStartEventsAndTasks(
 		curMutableState,
 		workflowExecution,
@@ -709,6 +779,13 @@ func (e *historyEngineImpl) startWorkflowHelper(
 			)
 		}
 
+		// a run started after the running run was looked up above
+		if shouldUseExisting(startRequest, t.State) {
+			return &types.StartWorkflowExecutionResponse{
+				RunID: t.RunID,
+			}, nil
+		}
+
 		prevRunID = t.RunID
 		if shouldTerminateAndStart(startRequest, t.State) {
 			runningWFCtx, err := workflow.LoadOnce(ctx, e.executionCache, domainID, workflowID, prevRunID)
@@ -769,7 +846,22 @@ func shouldTerminateAndStart(
 	startRequest *types.HistoryStartWorkflowExecutionRequest,
 	state int,
 ) bool {
-	return startRequest.StartRequest.GetWorkflowIDReusePolicy() == types.WorkflowIDReusePolicyTerminateIfRunning &&
+	if state != persistence.WorkflowStateRunning && state != persistence.WorkflowStateCreated {
+		return false
+	}
+	// the conflict policy wins over the reuse policy when it's set
+	request := startRequest.StartRequest
+	if request.WorkflowIDConflictPolicy != nil {
+		return request.GetWorkflowIDConflictPolicy() == types.WorkflowIDConflictPolicyTerminateExisting
+	}
+	return request.GetWorkflowIDReusePolicy() == types.WorkflowIDReusePolicyTerminateIfRunning
+}
+
+func shouldUseExisting(
+	startRequest *types.HistoryStartWorkflowExecutionRequest,
+	state int,
+) bool {
+	return startRequest.StartRequest.GetWorkflowIDConflictPolicy() == types.WorkflowIDConflictPolicyUseExisting &&
 		(state == persistence.WorkflowStateRunning || state == persistence.WorkflowStateCreated)
 }
 
@@ -2254,6 +2346,137 @@ func (e *historyEngineImpl) SignalWorkflowExecution(
 		})
 }
 
+// PauseWorkflowExecution pauses the workflow, its decision and activity tasks are not dispatched
+// and its timers are deferred until it's unpaused
+func (e *historyEngineImpl) PauseWorkflowExecution(
+	ctx context.Context,
+	request *types.HistoryPauseWorkflowExecutionRequest,
+) error {
+
+	return e.updateWorkflowPauseState(
+		ctx,
+		request.GetDomainUUID(),
+		request.GetWorkflowExecution(),
+		true,
+		request.GetReason(),
+		request.GetIdentity(),
+		request.GetRequestID(),
+	)
+}
+
+// UnpauseWorkflowExecution resumes the paused workflow, and generates again the tasks dropped
+// while the workflow was paused
+func (e *historyEngineImpl) UnpauseWorkflowExecution(
+	ctx context.Context,
+	request *types.HistoryUnpauseWorkflowExecutionRequest,
+) error {
+
+	return e.updateWorkflowPauseState(
+		ctx,
+		request.GetDomainUUID(),
+		request.GetWorkflowExecution(),
+		false,
+		request.GetReason(),
+		request.GetIdentity(),
+		request.GetRequestID(),
+	)
+}
+
+func (e *historyEngineImpl) updateWorkflowPauseState(
+	ctx context.Context,
+	domainUUID string,
+	workflowExecution *types.WorkflowExecution,
+	pause bool,
+	reason string,
+	identity string,
+	requestID string,
+) error {
+
+	domainEntry, err := e.shard.GetDomainCache().GetActiveDomainByID(domainUUID)
+	if err != nil {
+		return err
+	}
+	if domainEntry.GetInfo().Status != persistence.DomainStatusRegistered {
+		return errDomainDeprecated
+	}
+	domainID := domainEntry.GetInfo().ID
+
+	return workflow.UpdateCurrentWithActionFunc(
+		ctx,
+		e.executionCache,
+		e.executionManager,
+		domainID,
+		types.WorkflowExecution{
+			WorkflowID: workflowExecution.GetWorkflowID(),
+			RunID:      workflowExecution.GetRunID(),
+		},
+		e.timeSource.Now(),
+		func(wfContext execution.Context, mutableState execution.MutableState) (*workflow.UpdateAction, error) {
+			if requestID != "" && mutableState.IsSignalRequested(requestID) {
+				return &workflow.UpdateAction{
+					Noop:           true,
+					CreateDecision: false,
+				}, nil
+			}
+
+			if !mutableState.IsWorkflowExecutionRunning() {
+				return nil, workflow.ErrAlreadyCompleted
+			}
+
+			if execution.IsWorkflowPaused(mutableState) == pause {
+				// the workflow is already paused, or not paused
+				return &workflow.UpdateAction{
+					Noop:           true,
+					CreateDecision: false,
+				}, nil
+			}
+
+			if requestID != "" {
+				mutableState.AddSignalRequested(requestID)
+			}
+
+			// the pause state is not recorded in the history, so that it's not delivered to
+			// the workflow as a signal, the request is logged instead
+			if err := mutableState.UpdateWorkflowPauseState(pause); err != nil {
+				return nil, &types.InternalServiceError{Message: "Unable to update workflow pause state."}
+			}
+			msg := "Workflow unpaused."
+			if pause {
+				msg = "Workflow paused."
+			}
+			e.logger.Info(msg,
+				tag.WorkflowDomainName(domainEntry.GetInfo().Name),
+				tag.WorkflowID(workflowExecution.GetWorkflowID()),
+				tag.WorkflowRunID(mutableState.GetExecutionInfo().RunID),
+				tag.WorkflowPauseReason(reason),
+				tag.Actor(identity),
+			)
+
+			if !pause {
+				mutableStateTaskRefresher := execution.NewMutableStateTaskRefresher(
+					e.shard.GetConfig(),
+					e.shard.GetClusterMetadata(),
+					e.shard.GetDomainCache(),
+					e.shard.GetEventsCache(),
+					e.shard.GetLogger(),
+					e.shard.GetShardID(),
+				)
+				if err := mutableStateTaskRefresher.RefreshTasks(
+					ctx,
+					mutableState.GetExecutionInfo().StartTimestamp,
+					mutableState,
+				); err != nil {
+					return nil, err
+				}
+			}
+
+			return &workflow.UpdateAction{
+				Noop:           false,
+				CreateDecision: false,
+			}, nil
+		})
+}
+
 func (e *historyEngineImpl) SignalWithStartWorkflowExecution(
 	ctx context.Context,
 	signalWithStartRequest *types.HistorySignalWithStartWorkflowExecutionRequest,
@@ -2295,8 +2518,22 @@ func (e *historyEngineImpl) SignalWithStartWorkflowExecution(
 				prevMutableState = mutableState
 				break
 			}
-			// workflow is running, if policy is TerminateIfRunning, terminate current run then signalWithStart
-			if sRequest.GetWorkflowIDReusePolicy() == types.WorkflowIDReusePolicyTerminateIfRunning {
+			// workflow is running, if conflict policy is Fail, reject the request
+			executionInfo := mutableState.GetExecutionInfo()
+			if sRequest.WorkflowIDConflictPolicy != nil && sRequest.GetWorkflowIDConflictPolicy() == types.WorkflowIDConflictPolicyFail {
+				if executionInfo.CreateRequestID == sRequest.GetRequestID() {
+					return &types.StartWorkflowExecutionResponse{RunID: executionInfo.RunID}, nil
+				}
+				msg := "Workflow execution is already running. WorkflowId: %v, RunId: %v."
+				return nil, getWorkflowAlreadyStartedError(msg, executionInfo.CreateRequestID, executionInfo.WorkflowID, executionInfo.RunID)
+			}
+			// workflow is running, if policy is TerminateIfRunning or TerminateExisting, terminate current run then signalWithStart
+			// the conflict policy wins over the reuse policy when it's set
+			shouldTerminate := sRequest.GetWorkflowIDReusePolicy() == types.WorkflowIDReusePolicyTerminateIfRunning
+			if sRequest.WorkflowIDConflictPolicy != nil {
+				shouldTerminate = sRequest.GetWorkflowIDConflictPolicy() == types.WorkflowIDConflictPolicyTerminateExisting
+			}
+			if shouldTerminate {
 				workflowExecution.RunID = uuid.New()
 				runningWFCtx := workflow.NewContext(wfContext, release, mutableState)
 				resp, errTerm := e.terminateAndStartWorkflow(
@@ -2315,7 +2552,6 @@ func (e *historyEngineImpl) SignalWithStartWorkflowExecution(
 				}
 			}
 
-			executionInfo := mutableState.GetExecutionInfo()
 			maxAllowedSignals := e.config.MaximumSignalsPerExecution(domainEntry.GetInfo().Name)
 			if maxAllowedSignals > 0 && int(executionInfo.SignalCount) >= maxAllowedSignals {
 				e.logger.Info("Execution limit reached for maximum signals", tag.WorkflowSignalCount(executionInfo.SignalCount),
@@ -2672,6 +2908,7 @@ func (e *historyEngineImpl) ResetWorkflowExecution(
 		request.GetReason(),
 		nil,
 		request.GetSkipSignalReapply(),
+		request.GetPreservePendingActivityResults(),
 	); err != nil {
 		return nil, err
 	}
@@ -2929,6 +3166,7 @@ func getStartRequest(
 		SearchAttributes:                    request.SearchAttributes,
 		Header:                              request.Header,
 		DelayStartSeconds:                   request.DelayStartSeconds,
+		WorkflowIDConflictPolicy:            request.WorkflowIDConflictPolicy,
 	}
 
 	startRequest := common.CreateHistoryStartWorkflowRequest(domainID, req, time.Now())
@@ -3168,6 +3406,7 @@ func (e *historyEngineImpl) ReapplyEvents(
 					ndc.EventsReapplicationResetWorkflowReason,
 					toReapplyEvents,
 					false,
+					false,
 				); err != nil {
 					return nil, err
 				}
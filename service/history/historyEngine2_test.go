@@ -38,6 +38,7 @@ import (
 	"github.com/uber/cadence/common/cache"
 	"github.com/uber/cadence/common/clock"
 	"github.com/uber/cadence/common/cluster"
+	"github.com/uber/cadence/common/dynamicconfig"
 	"github.com/uber/cadence/common/log"
 	"github.com/uber/cadence/common/log/loggerimpl"
 	"github.com/uber/cadence/common/log/tag"
@@ -54,6 +55,7 @@ import (
 	"github.com/uber/cadence/service/history/query"
 	"github.com/uber/cadence/service/history/queue"
 	"github.com/uber/cadence/service/history/shard"
+	"github.com/uber/cadence/service/history/storage"
 	test "github.com/uber/cadence/service/history/testing"
 	"github.com/uber/cadence/service/history/workflow"
 )
@@ -942,6 +944,40 @@ func (s *engine2Suite) TestStartWorkflowExecution_BrandNew() {
 	s.NotNil(resp.RunID)
 }
 
+func (s *engine2Suite) TestStartWorkflowExecution_DomainStorageQuotaExceeded() {
+	domainID := constants.TestDomainID
+
+	enableDomainStorageTracking := s.config.EnableDomainStorageTracking
+	domainStorageQuota := s.config.DomainStorageQuota
+	defer func() {
+		s.config.EnableDomainStorageTracking = enableDomainStorageTracking
+		s.config.DomainStorageQuota = domainStorageQuota
+	}()
+	s.config.EnableDomainStorageTracking = dynamicconfig.GetBoolPropertyFn(true)
+	s.config.DomainStorageQuota = dynamicconfig.GetIntPropertyFilteredByDomain(1000 * s.config.NumberOfShards)
+
+	tracker := storage.NewMockTracker(s.controller)
+	// each shard may use its share of the domain quota
+	tracker.EXPECT().GetDomainShardUsage(s.mockShard.GetShardID(), domainID).Return(int64(1000), true).Times(1)
+	s.mockShard.Resource.DomainStorageTracker = tracker
+
+	resp, err := s.historyEngine.StartWorkflowExecution(context.Background(), &types.HistoryStartWorkflowExecutionRequest{
+		DomainUUID: domainID,
+		StartRequest: &types.StartWorkflowExecutionRequest{
+			Domain:                              domainID,
+			WorkflowID:                          "workflowID",
+			WorkflowType:                        &types.WorkflowType{Name: "workflowType"},
+			TaskList:                            &types.TaskList{Name: "testTaskList"},
+			ExecutionStartToCloseTimeoutSeconds: common.Int32Ptr(1),
+			TaskStartToCloseTimeoutSeconds:      common.Int32Ptr(2),
+			Identity:                            "testIdentity",
+			RequestID:                           uuid.New(),
+		},
+	})
+	s.Nil(resp)
+	s.IsType(&types.LimitExceededError{}, err)
+}
+
 func (s *engine2Suite) TestStartWorkflowExecution_StillRunning_Dedup() {
 	domainID := constants.TestDomainID
 	workflowID := "workflowID"
@@ -1017,6 +1053,127 @@ func (s *engine2Suite) TestStartWorkflowExecution_StillRunning_NonDeDup() {
 	s.Nil(resp)
 }
 
+func (s *engine2Suite) TestStartWorkflowExecution_StillRunning_UseExisting() {
+	domainID := constants.TestDomainID
+	workflowID := "workflowID"
+	runID := "runID"
+	workflowType := "workflowType"
+	taskList := "testTaskList"
+	identity := "testIdentity"
+	lastWriteVersion := common.EmptyVersion
+
+	// the running run is used before any history is appended or any execution is created
+	s.mockExecutionMgr.On("GetCurrentExecution", mock.Anything, mock.Anything).Return(&p.GetCurrentExecutionResponse{
+		StartRequestID:   "oldRequestID",
+		RunID:            runID,
+		State:            p.WorkflowStateRunning,
+		CloseStatus:      p.WorkflowCloseStatusNone,
+		LastWriteVersion: lastWriteVersion,
+	}, nil).Once()
+
+	resp, err := s.historyEngine.StartWorkflowExecution(context.Background(), &types.HistoryStartWorkflowExecutionRequest{
+		DomainUUID: domainID,
+		StartRequest: &types.StartWorkflowExecutionRequest{
+			Domain:                              domainID,
+			WorkflowID:                          workflowID,
+			WorkflowType:                        &types.WorkflowType{Name: workflowType},
+			TaskList:                            &types.TaskList{Name: taskList},
+			ExecutionStartToCloseTimeoutSeconds: common.Int32Ptr(1),
+			TaskStartToCloseTimeoutSeconds:      common.Int32Ptr(2),
+			Identity:                            identity,
+			RequestID:                           "newRequestID",
+			WorkflowIDConflictPolicy:            types.WorkflowIDConflictPolicyUseExisting.Ptr(),
+		},
+	})
+	s.Nil(err)
+	s.Equal(runID, resp.GetRunID())
+	s.mockHistoryV2Mgr.AssertNotCalled(s.T(), "AppendHistoryNodes", mock.Anything, mock.Anything)
+	s.mockExecutionMgr.AssertNotCalled(s.T(), "CreateWorkflowExecution", mock.Anything, mock.Anything)
+}
+
+func (s *engine2Suite) TestStartWorkflowExecution_StartedConcurrently_UseExisting() {
+	domainID := constants.TestDomainID
+	workflowID := "workflowID"
+	runID := "runID"
+	workflowType := "workflowType"
+	taskList := "testTaskList"
+	identity := "testIdentity"
+	lastWriteVersion := common.EmptyVersion
+
+	// the run is started after the running run is looked up, so the creation conflicts with it
+	s.mockExecutionMgr.On("GetCurrentExecution", mock.Anything, mock.Anything).Return(nil, &types.EntityNotExistsError{}).Once()
+	s.mockHistoryV2Mgr.On("AppendHistoryNodes", mock.Anything, mock.Anything).Return(&p.AppendHistoryNodesResponse{Size: 0}, nil).Once()
+	s.mockExecutionMgr.On("CreateWorkflowExecution", mock.Anything, mock.Anything).Return(nil, &p.WorkflowExecutionAlreadyStartedError{
+		Msg:              "random message",
+		StartRequestID:   "oldRequestID",
+		RunID:            runID,
+		State:            p.WorkflowStateRunning,
+		CloseStatus:      p.WorkflowCloseStatusNone,
+		LastWriteVersion: lastWriteVersion,
+	}).Once()
+
+	resp, err := s.historyEngine.StartWorkflowExecution(context.Background(), &types.HistoryStartWorkflowExecutionRequest{
+		DomainUUID: domainID,
+		StartRequest: &types.StartWorkflowExecutionRequest{
+			Domain:                              domainID,
+			WorkflowID:                          workflowID,
+			WorkflowType:                        &types.WorkflowType{Name: workflowType},
+			TaskList:                            &types.TaskList{Name: taskList},
+			ExecutionStartToCloseTimeoutSeconds: common.Int32Ptr(1),
+			TaskStartToCloseTimeoutSeconds:      common.Int32Ptr(2),
+			Identity:                            identity,
+			RequestID:                           "newRequestID",
+			WorkflowIDReusePolicy:               types.WorkflowIDReusePolicyAllowDuplicate.Ptr(),
+			WorkflowIDConflictPolicy:            types.WorkflowIDConflictPolicyUseExisting.Ptr(),
+		},
+	})
+	s.Nil(err)
+	s.Equal(runID, resp.GetRunID())
+	s.mockExecutionMgr.AssertNumberOfCalls(s.T(), "CreateWorkflowExecution", 1)
+	s.mockExecutionMgr.AssertNotCalled(s.T(), "GetWorkflowExecution", mock.Anything, mock.Anything)
+}
+
+func (s *engine2Suite) TestStartWorkflowExecution_StillRunning_ConflictPolicyFailOverridesReusePolicy() {
+	domainID := constants.TestDomainID
+	workflowID := "workflowID"
+	runID := "runID"
+	workflowType := "workflowType"
+	taskList := "testTaskList"
+	identity := "testIdentity"
+	lastWriteVersion := common.EmptyVersion
+
+	s.mockHistoryV2Mgr.On("AppendHistoryNodes", mock.Anything, mock.Anything).Return(&p.AppendHistoryNodesResponse{Size: 0}, nil).Once()
+	s.mockExecutionMgr.On("CreateWorkflowExecution", mock.Anything, mock.Anything).Return(nil, &p.WorkflowExecutionAlreadyStartedError{
+		Msg:              "random message",
+		StartRequestID:   "oldRequestID",
+		RunID:            runID,
+		State:            p.WorkflowStateRunning,
+		CloseStatus:      p.WorkflowCloseStatusNone,
+		LastWriteVersion: lastWriteVersion,
+	}).Once()
+
+	resp, err := s.historyEngine.StartWorkflowExecution(context.Background(), &types.HistoryStartWorkflowExecutionRequest{
+		DomainUUID: domainID,
+		StartRequest: &types.StartWorkflowExecutionRequest{
+			Domain:                              domainID,
+			WorkflowID:                          workflowID,
+			WorkflowType:                        &types.WorkflowType{Name: workflowType},
+			TaskList:                            &types.TaskList{Name: taskList},
+			ExecutionStartToCloseTimeoutSeconds: common.Int32Ptr(1),
+			TaskStartToCloseTimeoutSeconds:      common.Int32Ptr(2),
+			Identity:                            identity,
+			RequestID:                           "newRequestID",
+			WorkflowIDReusePolicy:               types.WorkflowIDReusePolicyTerminateIfRunning.Ptr(),
+			WorkflowIDConflictPolicy:            types.WorkflowIDConflictPolicyFail.Ptr(),
+		},
+	})
+	if _, ok := err.(*types.WorkflowExecutionAlreadyStartedError); !ok {
+		s.Fail("return err is not *types.WorkflowExecutionAlreadyStartedError")
+	}
+	s.Nil(resp)
+	s.mockExecutionMgr.AssertNotCalled(s.T(), "GetWorkflowExecution", mock.Anything, mock.Anything)
+}
+
 func (s *engine2Suite) TestStartWorkflowExecution_NotRunning_PrevSuccess() {
 	domainID := constants.TestDomainID
 	workflowID := "workflowID"
@@ -1221,6 +1378,46 @@ func (s *engine2Suite) TestSignalWithStartWorkflowExecution_JustSignal() {
 	s.Equal(runID, resp.GetRunID())
 }
 
+func (s *engine2Suite) TestSignalWithStartWorkflowExecution_StillRunning_ConflictPolicyFail() {
+	domainID := constants.TestDomainID
+	workflowID := "wId"
+	runID := constants.TestRunID
+	identity := "testIdentity"
+	signalName := "my signal name"
+	input := []byte("test input")
+	sRequest := &types.HistorySignalWithStartWorkflowExecutionRequest{
+		DomainUUID: domainID,
+		SignalWithStartRequest: &types.SignalWithStartWorkflowExecutionRequest{
+			Domain:                   domainID,
+			WorkflowID:               workflowID,
+			Identity:                 identity,
+			SignalName:               signalName,
+			Input:                    input,
+			RequestID:                "newRequestID",
+			WorkflowIDConflictPolicy: types.WorkflowIDConflictPolicyFail.Ptr(),
+		},
+	}
+
+	msBuilder := execution.NewMutableStateBuilderWithEventV2(
+		s.historyEngine.shard,
+		loggerimpl.NewLoggerForTest(s.Suite),
+		runID,
+		constants.TestLocalDomainEntry,
+	)
+	ms := execution.CreatePersistenceMutableState(msBuilder)
+	gwmsResponse := &p.GetWorkflowExecutionResponse{State: ms}
+	gceResponse := &p.GetCurrentExecutionResponse{RunID: runID}
+
+	s.mockExecutionMgr.On("GetCurrentExecution", mock.Anything, mock.Anything).Return(gceResponse, nil).Once()
+	s.mockExecutionMgr.On("GetWorkflowExecution", mock.Anything, mock.Anything).Return(gwmsResponse, nil).Once()
+
+	resp, err := s.historyEngine.SignalWithStartWorkflowExecution(context.Background(), sRequest)
+	if _, ok := err.(*types.WorkflowExecutionAlreadyStartedError); !ok {
+		s.Fail("return err is not *types.WorkflowExecutionAlreadyStartedError")
+	}
+	s.Nil(resp)
+}
+
 func (s *engine2Suite) TestSignalWithStartWorkflowExecution_WorkflowNotExist() {
 	sRequest := &types.HistorySignalWithStartWorkflowExecutionRequest{}
 	_, err := s.historyEngine.SignalWithStartWorkflowExecution(context.Background(), sRequest)
@@ -357,6 +357,7 @@ func (r *transactionManagerImpl) backfillWorkflowEventsReapply(
 			EventsReapplicationResetWorkflowReason,
 			targetWorkflowEvents.Events,
 			false,
+			false,
 		); err != nil {
 			return 0, execution.TransactionPolicyActive, err
 		}
@@ -35,6 +35,7 @@ import (
 	"github.com/uber/cadence/common/log/tag"
 	"github.com/uber/cadence/common/metrics"
 	"github.com/uber/cadence/common/persistence"
+	ctask "github.com/uber/cadence/common/task"
 	"github.com/uber/cadence/common/types"
 	"github.com/uber/cadence/service/history/config"
 	"github.com/uber/cadence/service/history/execution"
@@ -390,9 +391,15 @@ func (c *crossClusterQueueProcessorBase) pollTasks(
 		} else if request != nil {
 			result = append(result, request)
 		} else {
-			// if request is nil, nothing need to be done for the task,
-			// task already acked in GetCrossClusterRequest()
+			// if request is nil, nothing need to be done for the task at the target cluster,
+			// task is either already acked in GetCrossClusterRequest() or its response is recorded
+			// and it needs to be processed
 			c.readyForPollTasks.Remove(task.GetTaskID())
+			if task.State() != ctask.TaskStateAcked {
+				if _, err := c.submitTask(task); err != nil {
+					break
+				}
+			}
 		}
 	}
 	return result
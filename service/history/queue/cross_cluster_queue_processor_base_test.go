@@ -37,6 +37,7 @@ import (
 	"github.com/uber/cadence/common/log/loggerimpl"
 	"github.com/uber/cadence/common/metrics"
 	"github.com/uber/cadence/common/persistence"
+	ctask "github.com/uber/cadence/common/task"
 	"github.com/uber/cadence/common/types"
 	"github.com/uber/cadence/service/history/config"
 	"github.com/uber/cadence/service/history/constants"
@@ -203,6 +204,7 @@ func (s *crossClusterQueueProcessorBaseSuite) TestPollTasks() {
 	task2.EXPECT().GetDomainID().Return(uuid.New()).AnyTimes()
 	task2.EXPECT().GetTaskID().Return(taskID2).AnyTimes()
 	task2.EXPECT().GetCrossClusterRequest().Return(nil, nil).Times(1)
+	task2.EXPECT().State().Return(ctask.TaskStateAcked).Times(1)
 	// case 3: failed to get request, should retry on next poll
 	taskID3 := int64(4)
 	task3 := task.NewMockCrossClusterTask(s.controller)
@@ -217,12 +219,20 @@ func (s *crossClusterQueueProcessorBaseSuite) TestPollTasks() {
 	task4.EXPECT().GetTaskID().Return(taskID4).AnyTimes()
 	task4.EXPECT().GetCrossClusterRequest().Return(nil, errors.New("task is invalid")).Times(1)
 	task4.EXPECT().IsValid().Return(false).Times(1)
-	s.mockTaskProcessor.EXPECT().TrySubmit(gomock.Any()).Return(true, nil).Times(1)
+	// case 5: task response is recorded without the target cluster, should be submitted for processing
+	taskID5 := int64(6)
+	task5 := task.NewMockCrossClusterTask(s.controller)
+	task5.EXPECT().GetDomainID().Return(uuid.New()).AnyTimes()
+	task5.EXPECT().GetTaskID().Return(taskID5).AnyTimes()
+	task5.EXPECT().GetCrossClusterRequest().Return(nil, nil).Times(1)
+	task5.EXPECT().State().Return(ctask.TaskStatePending).Times(1)
+	s.mockTaskProcessor.EXPECT().TrySubmit(gomock.Any()).Return(true, nil).Times(2)
 	newTaskMap := map[task.Key]task.Task{
 		testKey{ID: int(taskID1)}: task1,
 		testKey{ID: int(taskID2)}: task2,
 		testKey{ID: int(taskID3)}: task3,
 		testKey{ID: int(taskID4)}: task4,
+		testKey{ID: int(taskID5)}: task5,
 	}
 	processorBase.processingQueueCollections[0].AddTasks(newTaskMap, testKey{ID: 10})
 	for _, task := range newTaskMap {
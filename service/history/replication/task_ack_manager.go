@@ -180,6 +180,19 @@ TaskInfoLoop:
 			readLevel = taskInfo.GetTaskID()
 			continue
 		}
+		if !domainEntity.IsClusterAllowed(pollingCluster) {
+			replicationScope.Tagged(
+				metrics.DomainTag(domainEntity.GetInfo().Name),
+				metrics.TargetClusterTag(pollingCluster),
+			).IncCounter(metrics.DataResidencyViolationCounter)
+			t.logger.Error("Skip replication task not allowed by the domain data residency.",
+				tag.WorkflowDomainName(domainEntity.GetInfo().Name),
+				tag.ClusterName(pollingCluster),
+				tag.TaskID(taskInfo.GetTaskID()),
+			)
+			readLevel = taskInfo.GetTaskID()
+			continue
+		}
 
 		// construct replication task from DB
 		_ = t.rateLimiter.Wait(ctx)
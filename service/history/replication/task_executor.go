@@ -296,6 +296,10 @@ func (e *taskExecutorImpl) filterTask(
 		return false, err
 	}
 
+	if !domainEntry.IsClusterAllowed(e.currentCluster) {
+		return false, ErrDataResidencyViolation
+	}
+
 	shouldProcessTask := false
 FilterLoop:
 	for _, targetCluster := range domainEntry.GetReplicationConfig().Clusters {
@@ -33,6 +33,7 @@ import (
 	"github.com/uber/cadence/client"
 	"github.com/uber/cadence/client/admin"
 	historyClient "github.com/uber/cadence/client/history"
+	"github.com/uber/cadence/common"
 	"github.com/uber/cadence/common/cache"
 	"github.com/uber/cadence/common/cluster"
 	"github.com/uber/cadence/common/metrics"
@@ -163,6 +164,30 @@ func (s *taskExecutorSuite) TestFilterTask_Error() {
 	s.False(ok)
 }
 
+func (s *taskExecutorSuite) TestFilterTask_DataResidencyViolation() {
+	domainID := uuid.New()
+	s.mockDomainCache.EXPECT().
+		GetDomainByID(domainID).
+		Return(cache.NewGlobalDomainCacheEntryForTest(
+			&persistence.DomainInfo{
+				ID:   domainID,
+				Data: map[string]string{common.DomainDataKeyForAllowedClusters: "standby"},
+			},
+			nil,
+			&persistence.DomainReplicationConfig{
+				Clusters: []*persistence.ClusterReplicationConfig{
+					{
+						ClusterName: "active",
+					},
+				}},
+			0,
+			s.clusterMetadata,
+		), nil)
+	ok, err := s.taskHandler.filterTask(domainID, false)
+	s.Equal(ErrDataResidencyViolation, err)
+	s.False(ok)
+}
+
 func (s *taskExecutorSuite) TestFilterTask_EnforceApply() {
 	domainID := uuid.New()
 	ok, err := s.taskHandler.filterTask(domainID, true)
@@ -62,6 +62,8 @@ const (
 var (
 	// ErrUnknownReplicationTask is the error to indicate unknown replication task type
 	ErrUnknownReplicationTask = &types.BadRequestError{Message: "unknown replication task"}
+	// ErrDataResidencyViolation is the error to indicate the domain data residency doesn't allow the replication task
+	ErrDataResidencyViolation = &types.BadRequestError{Message: "replication task not allowed by the domain data residency"}
 )
 
 type (
@@ -418,6 +420,22 @@ func (p *taskProcessorImpl) processSingleTask(replicationTask *types.Replication
 		// skip the workflow without version histories
 		p.logger.Warn("Encounter workflow withour version histories")
 		return nil
+	case err == ErrDataResidencyViolation:
+		// the data of the task must not be kept in this cluster, the DLQ entry only records
+		// the domain, workflow, run and event range of the task, not its events.
+		// The source cluster skips these tasks, recording the violation, so this only happens
+		// when the data residency of the domain changes while the task is replicated.
+		p.metricsClient.Scope(
+			metrics.ReplicationTaskFetcherScope,
+			metrics.TargetClusterTag(p.sourceCluster),
+			metrics.InstanceTag(strconv.Itoa(p.shard.GetShardID())),
+		).IncCounter(metrics.DataResidencyViolationCounter)
+		p.logger.Error("Replication task not allowed by the domain data residency. Putting task metadata into DLQ.",
+			tag.TaskID(replicationTask.GetSourceTaskID()),
+			tag.SourceCluster(p.sourceCluster),
+			tag.ClusterName(p.shard.GetClusterMetadata().GetCurrentClusterName()),
+		)
+		return p.putReplicationTaskToDLQ(replicationTask)
 	default:
 		//handle error
 	}
@@ -241,6 +241,37 @@ func (s *taskProcessorSuite) TestPutReplicationTaskToDLQ_HistoryV2ReplicationTas
 	s.NoError(err)
 }
 
+func (s *taskProcessorSuite) TestProcessSingleTask_DataResidencyViolation() {
+	domainID := uuid.New()
+	workflowID := uuid.New()
+	runID := uuid.New()
+	task := &types.ReplicationTask{
+		TaskType:     types.ReplicationTaskTypeSyncActivity.Ptr(),
+		SourceTaskID: 100,
+		SyncActivityTaskAttributes: &types.SyncActivityTaskAttributes{
+			DomainID:    domainID,
+			WorkflowID:  workflowID,
+			RunID:       runID,
+			ScheduledID: 5,
+		},
+	}
+	request := &persistence.PutReplicationTaskToDLQRequest{
+		SourceClusterName: "standby",
+		TaskInfo: &persistence.ReplicationTaskInfo{
+			DomainID:    domainID,
+			WorkflowID:  workflowID,
+			RunID:       runID,
+			TaskID:      100,
+			TaskType:    persistence.ReplicationTaskTypeSyncActivity,
+			ScheduledID: 5,
+		},
+	}
+	s.taskExecutor.EXPECT().execute(task, false).Return(metrics.SyncActivityTaskScope, ErrDataResidencyViolation).Times(1)
+	s.executionManager.On("PutReplicationTaskToDLQ", mock.Anything, request).Return(nil).Once()
+	err := s.taskProcessor.processSingleTask(task)
+	s.NoError(err)
+}
+
 func (s *taskProcessorSuite) TestGenerateDLQRequest_ReplicationTaskTypeHistoryV2() {
 	domainID := uuid.New()
 	workflowID := uuid.New()
@@ -25,6 +25,9 @@ package reset
 import (
 	"context"
 	"fmt"
+	"sort"
+
+	"github.com/pborman/uuid"
 
 	"github.com/uber/cadence/common"
 	"github.com/uber/cadence/common/cache"
@@ -57,6 +60,7 @@ type (
 			resetReason string,
 			additionalReapplyEvents []*types.HistoryEvent,
 			skipSignalReapply bool,
+			preservePendingActivityResults bool,
 		) error
 	}
 
@@ -109,6 +113,7 @@ func (r *workflowResetterImpl) ResetWorkflow(
 	resetReason string,
 	additionalReapplyEvents []*types.HistoryEvent,
 	skipSignalReapply bool,
+	preservePendingActivityResults bool,
 ) (retError error) {
 
 	domainEntry, err := r.domainCache.GetDomainByID(domainID)
@@ -145,6 +150,7 @@ func (r *workflowResetterImpl) ResetWorkflow(
 		resetReason,
 		additionalReapplyEvents,
 		skipSignalReapply,
+		preservePendingActivityResults,
 	)
 	if err != nil {
 		return err
@@ -174,6 +180,7 @@ func (r *workflowResetterImpl) prepareResetWorkflow(
 	resetReason string,
 	additionalReapplyEvents []*types.HistoryEvent,
 	skipSignalReapply bool,
+	preservePendingActivityResults bool,
 ) (execution.Workflow, error) {
 
 	resetWorkflow, err := r.replayResetWorkflow(
@@ -217,6 +224,18 @@ func (r *workflowResetterImpl) prepareResetWorkflow(
 		return nil, err
 	}
 
+	if preservePendingActivityResults {
+		if err := r.completePendingActivitiesWithPreservedResults(
+			ctx,
+			resetMutableState,
+			baseBranchToken,
+			baseRebuildLastEventID+1,
+			baseNextEventID,
+		); err != nil {
+			return nil, err
+		}
+	}
+
 	if err := r.failInflightActivity(resetMutableState, resetReason); err != nil {
 		return nil, err
 	}
@@ -224,6 +243,8 @@ func (r *workflowResetterImpl) prepareResetWorkflow(
 	// TODO right now only signals are eligible for reapply, so we can directly skip the whole reapply process
 	// for the sake of performance. In the future, if there are other events that need to be reapplied, remove this check
 	// For example, we may want to re-apply activity/timer results for https://github.com/uber/cadence/issues/2934
+	// NOTE: only the results of the activities pending at the reset point are preserved above, the activities
+	// scheduled after the reset point are scheduled again by the reset workflow
 	if !skipSignalReapply {
 		if err := r.reapplyResetAndContinueAsNewWorkflowEvents(
 			ctx,
@@ -407,6 +428,91 @@ func (r *workflowResetterImpl) failInflightActivity(
 	return nil
 }
 
+// completePendingActivitiesWithPreservedResults completes the activities pending in the reset workflow
+// with the results recorded in the base workflow after the reset point, so they are not run again.
+// The activities scheduled after the reset point are not matched with the base workflow, they run again.
+func (r *workflowResetterImpl) completePendingActivitiesWithPreservedResults(
+	ctx context.Context,
+	mutableState execution.MutableState,
+	baseBranchToken []byte,
+	baseRebuildNextEventID int64,
+	baseNextEventID int64,
+) error {
+
+	if baseRebuildNextEventID == baseNextEventID || len(mutableState.GetPendingActivityInfos()) == 0 {
+		return nil
+	}
+
+	startedEvents := make(map[int64]*types.ActivityTaskStartedEventAttributes)
+	completedEvents := make(map[int64]*types.ActivityTaskCompletedEventAttributes)
+	iter := collection.NewPagingIterator(r.getPaginationFn(
+		ctx,
+		baseRebuildNextEventID,
+		baseNextEventID,
+		baseBranchToken,
+	))
+	for iter.HasNext() {
+		batch, err := iter.Next()
+		if err != nil {
+			return err
+		}
+		for _, event := range batch.(*types.History).Events {
+			switch event.GetEventType() {
+			case types.EventTypeActivityTaskStarted:
+				attr := event.GetActivityTaskStartedEventAttributes()
+				startedEvents[attr.GetScheduledEventID()] = attr
+			case types.EventTypeActivityTaskCompleted:
+				attr := event.GetActivityTaskCompletedEventAttributes()
+				completedEvents[attr.GetScheduledEventID()] = attr
+			default:
+				// only the results of the completed activities are preserved
+			}
+		}
+	}
+
+	var preservedActivities []*persistence.ActivityInfo
+	for scheduleID, ai := range mutableState.GetPendingActivityInfos() {
+		if _, ok := completedEvents[scheduleID]; ok {
+			preservedActivities = append(preservedActivities, ai)
+		}
+	}
+	// complete the activities in the order they are scheduled
+	sort.Slice(preservedActivities, func(i, j int) bool {
+		return preservedActivities[i].ScheduleID < preservedActivities[j].ScheduleID
+	})
+
+	for _, ai := range preservedActivities {
+		completedAttr := completedEvents[ai.ScheduleID]
+		if ai.StartedID == common.EmptyEventID {
+			requestID := uuid.New()
+			identity := completedAttr.GetIdentity()
+			if startedAttr, ok := startedEvents[ai.ScheduleID]; ok {
+				requestID = startedAttr.GetRequestID()
+				identity = startedAttr.GetIdentity()
+			}
+			if _, err := mutableState.AddActivityTaskStartedEvent(
+				ai,
+				ai.ScheduleID,
+				requestID,
+				identity,
+			); err != nil {
+				return err
+			}
+		}
+		if _, err := mutableState.AddActivityTaskCompletedEvent(
+			ai.ScheduleID,
+			ai.StartedID,
+			&types.RespondActivityTaskCompletedRequest{
+				Result:   completedAttr.Result,
+				Identity: completedAttr.GetIdentity(),
+			},
+		); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (r *workflowResetterImpl) forkAndGenerateBranchToken(
 	ctx context.Context,
 	domainID string,
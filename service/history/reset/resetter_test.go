@@ -301,6 +301,130 @@ func (s *workflowResetterSuite) TestFailInflightActivity() {
 	s.NoError(err)
 }
 
+func (s *workflowResetterSuite) TestCompletePendingActivitiesWithPreservedResults() {
+	firstEventID := int64(10)
+	nextEventID := int64(15)
+	branchToken := []byte("some random branch token")
+
+	activity1 := &persistence.ActivityInfo{
+		Version:    12,
+		ScheduleID: 5,
+		StartedID:  6,
+	}
+	activity2 := &persistence.ActivityInfo{
+		Version:    12,
+		ScheduleID: 7,
+		StartedID:  common.EmptyEventID,
+	}
+	activity3 := &persistence.ActivityInfo{
+		Version:    12,
+		ScheduleID: 8,
+		StartedID:  common.EmptyEventID,
+	}
+	mutableState := execution.NewMockMutableState(s.controller)
+	mutableState.EXPECT().GetPendingActivityInfos().Return(map[int64]*persistence.ActivityInfo{
+		activity1.ScheduleID: activity1,
+		activity2.ScheduleID: activity2,
+		activity3.ScheduleID: activity3,
+	}).AnyTimes()
+
+	events := []*types.HistoryEvent{
+		{
+			EventID:   10,
+			EventType: types.EventTypeActivityTaskStarted.Ptr(),
+			ActivityTaskStartedEventAttributes: &types.ActivityTaskStartedEventAttributes{
+				ScheduledEventID: activity2.ScheduleID,
+				Identity:         "some random activity 2 identity",
+				RequestID:        "some random activity 2 request ID",
+			},
+		},
+		{
+			EventID:   11,
+			EventType: types.EventTypeActivityTaskCompleted.Ptr(),
+			ActivityTaskCompletedEventAttributes: &types.ActivityTaskCompletedEventAttributes{
+				ScheduledEventID: activity1.ScheduleID,
+				StartedEventID:   activity1.StartedID,
+				Result:           []byte("some random activity 1 result"),
+				Identity:         "some random activity 1 identity",
+			},
+		},
+		{
+			EventID:   12,
+			EventType: types.EventTypeActivityTaskCompleted.Ptr(),
+			ActivityTaskCompletedEventAttributes: &types.ActivityTaskCompletedEventAttributes{
+				ScheduledEventID: activity2.ScheduleID,
+				StartedEventID:   10,
+				Result:           []byte("some random activity 2 result"),
+				Identity:         "some random activity 2 identity",
+			},
+		},
+		{
+			EventID:   13,
+			EventType: types.EventTypeActivityTaskFailed.Ptr(),
+			ActivityTaskFailedEventAttributes: &types.ActivityTaskFailedEventAttributes{
+				ScheduledEventID: activity3.ScheduleID,
+			},
+		},
+		{
+			EventID:   14,
+			EventType: types.EventTypeActivityTaskCompleted.Ptr(),
+			ActivityTaskCompletedEventAttributes: &types.ActivityTaskCompletedEventAttributes{
+				ScheduledEventID: 9,
+				Result:           []byte("some random activity result scheduled after reset point"),
+			},
+		},
+	}
+	s.mockHistoryV2Mgr.On("ReadHistoryBranchByBatch", mock.Anything, &persistence.ReadHistoryBranchRequest{
+		BranchToken:   branchToken,
+		MinEventID:    firstEventID,
+		MaxEventID:    nextEventID,
+		PageSize:      execution.NDCDefaultPageSize,
+		NextPageToken: nil,
+		ShardID:       common.IntPtr(s.mockShard.GetShardID()),
+	}).Return(&persistence.ReadHistoryBranchByBatchResponse{
+		History:       []*types.History{{Events: events}},
+		NextPageToken: nil,
+	}, nil).Once()
+
+	activity2StartedID := int64(16)
+	gomock.InOrder(
+		mutableState.EXPECT().AddActivityTaskCompletedEvent(
+			activity1.ScheduleID,
+			activity1.StartedID,
+			&types.RespondActivityTaskCompletedRequest{
+				Result:   []byte("some random activity 1 result"),
+				Identity: "some random activity 1 identity",
+			},
+		).Return(&types.HistoryEvent{}, nil),
+		mutableState.EXPECT().AddActivityTaskStartedEvent(
+			activity2,
+			activity2.ScheduleID,
+			"some random activity 2 request ID",
+			"some random activity 2 identity",
+		).DoAndReturn(func(ai *persistence.ActivityInfo, scheduleID int64, requestID string, identity string) (*types.HistoryEvent, error) {
+			ai.StartedID = activity2StartedID
+			return &types.HistoryEvent{}, nil
+		}),
+		mutableState.EXPECT().AddActivityTaskCompletedEvent(
+			activity2.ScheduleID,
+			activity2StartedID,
+			&types.RespondActivityTaskCompletedRequest{
+				Result:   []byte("some random activity 2 result"),
+				Identity: "some random activity 2 identity",
+			},
+		).Return(&types.HistoryEvent{}, nil),
+	)
+
+	err := s.workflowResetter.completePendingActivitiesWithPreservedResults(
+		context.Background(),
+		mutableState,
+		branchToken,
+		firstEventID,
+		nextEventID,
+	)
+	s.NoError(err)
+}
+
 func (s *workflowResetterSuite) TestGenerateBranchToken() {
 	baseBranchToken := []byte("some random base branch token")
 	baseNodeID := int64(1234)
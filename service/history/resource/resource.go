@@ -29,19 +29,22 @@ import (
 	"github.com/uber/cadence/common/service"
 	"github.com/uber/cadence/service/history/config"
 	"github.com/uber/cadence/service/history/events"
+	"github.com/uber/cadence/service/history/storage"
 )
 
 // Resource is the interface which expose common history resources
 type Resource interface {
 	resource.Resource
 	GetEventCache() events.Cache
+	GetDomainStorageTracker() storage.Tracker
 }
 
 type resourceImpl struct {
 	status int32
 
 	resource.Resource
-	eventCache events.Cache
+	eventCache           events.Cache
+	domainStorageTracker storage.Tracker
 }
 
 // Start starts all resources
@@ -56,6 +59,7 @@ func (h *resourceImpl) Start() {
 	}
 
 	h.Resource.Start()
+	h.domainStorageTracker.Start()
 	h.GetLogger().Info("history resource started", tag.LifeCycleStarted)
 }
 
@@ -70,6 +74,7 @@ func (h *resourceImpl) Stop() {
 		return
 	}
 
+	h.domainStorageTracker.Stop()
 	h.Resource.Stop()
 	h.GetLogger().Info("history resource stopped", tag.LifeCycleStopped)
 }
@@ -79,6 +84,11 @@ func (h *resourceImpl) GetEventCache() events.Cache {
 	return h.eventCache
 }
 
+// GetDomainStorageTracker return domain storage tracker
+func (h *resourceImpl) GetDomainStorageTracker() storage.Tracker {
+	return h.domainStorageTracker
+}
+
 // New create a new resource containing common history dependencies
 func New(
 	params *resource.Params,
@@ -121,9 +131,16 @@ func New(
 		uint64(config.EventsCacheMaxSize()),
 	)
 
+	domainStorageTracker := storage.NewTracker(
+		serviceResource.GetDomainCache(),
+		params.MetricsClient,
+		params.Logger,
+	)
+
 	historyResource = &resourceImpl{
-		Resource:   serviceResource,
-		eventCache: eventCache,
+		Resource:             serviceResource,
+		eventCache:           eventCache,
+		domainStorageTracker: domainStorageTracker,
 	}
 	return
 }
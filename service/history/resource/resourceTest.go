@@ -26,13 +26,15 @@ import (
 	"github.com/uber/cadence/common/metrics"
 	"github.com/uber/cadence/common/resource"
 	"github.com/uber/cadence/service/history/events"
+	"github.com/uber/cadence/service/history/storage"
 )
 
 type (
 	// Test is the test implementation used for testing
 	Test struct {
 		*resource.Test
-		EventCache *events.MockCache
+		EventCache           *events.MockCache
+		DomainStorageTracker *storage.MockTracker
 	}
 )
 
@@ -43,9 +45,15 @@ func NewTest(
 	controller *gomock.Controller,
 	serviceMetricsIndex metrics.ServiceIdx,
 ) *Test {
+	domainStorageTracker := storage.NewMockTracker(controller)
+	domainStorageTracker.EXPECT().AddHistorySize(gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
+	domainStorageTracker.EXPECT().RemoveHistorySize(gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
+	domainStorageTracker.EXPECT().GetDomainShardUsage(gomock.Any(), gomock.Any()).Return(int64(0), false).AnyTimes()
+
 	return &Test{
-		Test:       resource.NewTest(controller, serviceMetricsIndex),
-		EventCache: events.NewMockCache(controller),
+		Test:                 resource.NewTest(controller, serviceMetricsIndex),
+		EventCache:           events.NewMockCache(controller),
+		DomainStorageTracker: domainStorageTracker,
 	}
 }
 
@@ -53,3 +61,8 @@ func NewTest(
 func (s *Test) GetEventCache() events.Cache {
 	return s.EventCache
 }
+
+// GetDomainStorageTracker for testing
+func (s *Test) GetDomainStorageTracker() storage.Tracker {
+	return s.DomainStorageTracker
+}
@@ -690,7 +690,16 @@ Create_Loop:
 }
 
 func (s *contextImpl) getDefaultEncoding(domainName string) common.EncodingType {
-	return common.EncodingType(s.config.EventEncodingType(domainName))
+	encoding := common.EncodingType(s.config.EventEncodingType(domainName))
+	if !persistence.IsEncodingTypeAvailable(encoding) {
+		// refuse the configured encoding instead of failing every write of the domain
+		s.throttledLogger.Error("Configured event encoding is not available in this build, using thriftrw instead.",
+			tag.WorkflowDomainName(domainName),
+			tag.Value(encoding),
+		)
+		return common.EncodingTypeThriftRW
+	}
+	return encoding
 }
 
 func (s *contextImpl) UpdateWorkflowExecution(
@@ -980,6 +989,7 @@ func (s *contextImpl) AppendHistoryV2Events(
 	request.Encoding = s.getDefaultEncoding(domainName)
 	request.ShardID = common.IntPtr(s.shardID)
 	request.TransactionID = transactionID
+	request.DomainID = domainID
 
 	size := 0
 	defer func() {
@@ -997,6 +1007,9 @@ func (s *contextImpl) AppendHistoryV2Events(
 	if resp != nil {
 		size = resp.Size
 	}
+	if err0 == nil {
+		s.GetDomainStorageTracker().AddHistorySize(s.shardID, domainID, int64(size))
+	}
 	return size, err0
 }
 
@@ -0,0 +1,181 @@
+// Copyright (c) 2017-2020 Uber Technologies Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package storage
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/uber/cadence/common"
+	"github.com/uber/cadence/common/backoff"
+	"github.com/uber/cadence/common/log"
+	"github.com/uber/cadence/common/log/tag"
+	"github.com/uber/cadence/common/metrics"
+	"github.com/uber/cadence/common/persistence"
+	"github.com/uber/cadence/common/quotas"
+	"github.com/uber/cadence/service/history/config"
+)
+
+const (
+	scanJitterCoefficient = 0.15
+	maxInitialScanDelay   = 5 * time.Minute
+)
+
+type (
+	// Scanner periodically scans the workflow executions of a shard to compute the storage used by each domain
+	Scanner interface {
+		common.Daemon
+	}
+
+	scannerImpl struct {
+		status           int32
+		shutdownCh       chan struct{}
+		shardID          int
+		executionManager persistence.ExecutionManager
+		tracker          Tracker
+		config           *config.Config
+		rateLimiter      quotas.Limiter
+		metricsClient    metrics.Client
+		logger           log.Logger
+	}
+)
+
+var _ Scanner = (*scannerImpl)(nil)
+
+// NewScanner creates a new domain storage scanner for a shard
+func NewScanner(
+	shardID int,
+	executionManager persistence.ExecutionManager,
+	tracker Tracker,
+	config *config.Config,
+	metricsClient metrics.Client,
+	logger log.Logger,
+) Scanner {
+	return &scannerImpl{
+		status:           common.DaemonStatusInitialized,
+		shutdownCh:       make(chan struct{}),
+		shardID:          shardID,
+		executionManager: executionManager,
+		tracker:          tracker,
+		config:           config,
+		rateLimiter: quotas.NewDynamicRateLimiter(func() float64 {
+			return float64(config.DomainStorageScanRPS())
+		}),
+		metricsClient: metricsClient,
+		logger:        logger.WithTags(tag.ComponentDomainStorageTracker),
+	}
+}
+
+func (s *scannerImpl) Start() {
+	if !atomic.CompareAndSwapInt32(
+		&s.status,
+		common.DaemonStatusInitialized,
+		common.DaemonStatusStarted,
+	) {
+		return
+	}
+
+	go s.scanLoop()
+	s.logger.Info("Domain storage scanner state changed", tag.LifeCycleStarted)
+}
+
+func (s *scannerImpl) Stop() {
+	if !atomic.CompareAndSwapInt32(
+		&s.status,
+		common.DaemonStatusStarted,
+		common.DaemonStatusStopped,
+	) {
+		return
+	}
+
+	close(s.shutdownCh)
+	s.tracker.RemoveShard(s.shardID)
+	s.logger.Info("Domain storage scanner state changed", tag.LifeCycleStopped)
+}
+
+func (s *scannerImpl) scanLoop() {
+	// spread the first scan of the shards loaded at the same time
+	initialScanDelay := s.config.DomainStorageScanInterval()
+	if initialScanDelay > maxInitialScanDelay {
+		initialScanDelay = maxInitialScanDelay
+	}
+	timer := time.NewTimer(backoff.JitDuration(initialScanDelay, 1))
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-s.shutdownCh:
+			return
+		case <-timer.C:
+			if s.config.EnableDomainStorageTracking() {
+				s.scan()
+			} else {
+				s.tracker.RemoveShard(s.shardID)
+			}
+			timer.Reset(backoff.JitDuration(s.config.DomainStorageScanInterval(), scanJitterCoefficient))
+		}
+	}
+}
+
+func (s *scannerImpl) scan() {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		select {
+		case <-s.shutdownCh:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	usage := make(map[string]int64)
+	var pageToken []byte
+	for {
+		if err := s.rateLimiter.Wait(ctx); err != nil {
+			return
+		}
+		response, err := s.executionManager.ListConcreteExecutions(ctx, &persistence.ListConcreteExecutionsRequest{
+			PageSize:  s.config.DomainStorageScanPageSize(),
+			PageToken: pageToken,
+		})
+		if err != nil {
+			if ctx.Err() == nil {
+				s.metricsClient.IncCounter(metrics.DomainStorageScope, metrics.DomainStorageScanFailure)
+				s.logger.Warn("Failed to scan the domain storage of the shard.", tag.ShardID(s.shardID), tag.Error(err))
+			}
+			return
+		}
+		for _, execution := range response.Executions {
+			size := int64(execution.RecordSize)
+			if execution.ExecutionStats != nil {
+				size += execution.ExecutionStats.HistorySize
+			}
+			usage[execution.ExecutionInfo.DomainID] += size
+		}
+		if len(response.PageToken) == 0 {
+			break
+		}
+		pageToken = response.PageToken
+	}
+
+	s.tracker.SetShardUsage(s.shardID, usage)
+}
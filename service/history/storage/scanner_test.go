@@ -0,0 +1,117 @@
+// Copyright (c) 2017-2020 Uber Technologies Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package storage
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/uber/cadence/common/log/loggerimpl"
+	"github.com/uber/cadence/common/metrics"
+	"github.com/uber/cadence/common/mocks"
+	"github.com/uber/cadence/common/persistence"
+	"github.com/uber/cadence/service/history/config"
+)
+
+type (
+	scannerSuite struct {
+		suite.Suite
+		*require.Assertions
+
+		controller       *gomock.Controller
+		executionManager *mocks.ExecutionManager
+		tracker          *MockTracker
+		scanner          *scannerImpl
+	}
+)
+
+func TestScannerSuite(t *testing.T) {
+	s := new(scannerSuite)
+	suite.Run(t, s)
+}
+
+func (s *scannerSuite) SetupTest() {
+	s.Assertions = require.New(s.T())
+	s.controller = gomock.NewController(s.T())
+	s.executionManager = &mocks.ExecutionManager{}
+	s.tracker = NewMockTracker(s.controller)
+
+	s.scanner = NewScanner(
+		10,
+		s.executionManager,
+		s.tracker,
+		config.NewForTest(),
+		metrics.NewNoopMetricsClient(),
+		loggerimpl.NewNopLogger(),
+	).(*scannerImpl)
+}
+
+func (s *scannerSuite) TearDownTest() {
+	s.controller.Finish()
+	s.executionManager.AssertExpectations(s.T())
+}
+
+func (s *scannerSuite) TestScan() {
+	s.executionManager.On("ListConcreteExecutions", mock.Anything, &persistence.ListConcreteExecutionsRequest{
+		PageSize: 500,
+	}).Return(&persistence.ListConcreteExecutionsResponse{
+		Executions: []*persistence.ListConcreteExecutionsEntity{
+			s.newExecution("domain-1", 100, 10),
+			s.newExecution("domain-2", 200, 20),
+		},
+		PageToken: []byte("token"),
+	}, nil).Once()
+	s.executionManager.On("ListConcreteExecutions", mock.Anything, &persistence.ListConcreteExecutionsRequest{
+		PageSize:  500,
+		PageToken: []byte("token"),
+	}).Return(&persistence.ListConcreteExecutionsResponse{
+		Executions: []*persistence.ListConcreteExecutionsEntity{
+			s.newExecution("domain-1", 300, 30),
+		},
+	}, nil).Once()
+	s.tracker.EXPECT().SetShardUsage(10, map[string]int64{"domain-1": 440, "domain-2": 220}).Times(1)
+
+	s.scanner.scan()
+}
+
+func (s *scannerSuite) TestScan_Failure() {
+	s.executionManager.On("ListConcreteExecutions", mock.Anything, mock.Anything).
+		Return(nil, errors.New("some random error")).Once()
+
+	s.scanner.scan()
+}
+
+func (s *scannerSuite) newExecution(
+	domainID string,
+	historySize int64,
+	recordSize int,
+) *persistence.ListConcreteExecutionsEntity {
+	return &persistence.ListConcreteExecutionsEntity{
+		ExecutionInfo:  &persistence.WorkflowExecutionInfo{DomainID: domainID},
+		ExecutionStats: &persistence.ExecutionStats{HistorySize: historySize},
+		RecordSize:     recordSize,
+	}
+}
@@ -0,0 +1,237 @@
+// Copyright (c) 2017-2020 Uber Technologies Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+//go:generate mockgen -package $GOPACKAGE -source $GOFILE -destination tracker_mock.go -self_package github.com/uber/cadence/service/history/storage
+
+package storage
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/uber/cadence/common"
+	"github.com/uber/cadence/common/cache"
+	"github.com/uber/cadence/common/log"
+	"github.com/uber/cadence/common/log/tag"
+	"github.com/uber/cadence/common/metrics"
+)
+
+const (
+	emitMetricsInterval = time.Minute
+)
+
+type (
+	// Tracker tracks the storage used by the history and mutable state of each domain
+	// in the shards owned by the host
+	Tracker interface {
+		common.Daemon
+
+		// SetShardUsage replaces the storage used by the domains in the shard with the result of a scan
+		SetShardUsage(shardID int, usage map[string]int64)
+		// AddHistorySize adds the size of the events appended to the history of a workflow of the domain in the shard
+		AddHistorySize(shardID int, domainID string, size int64)
+		// RemoveHistorySize subtracts the size of the history of a workflow of the domain deleted from the shard
+		RemoveHistorySize(shardID int, domainID string, size int64)
+		// RemoveShard removes the storage used in a shard which is no longer owned by the host
+		RemoveShard(shardID int)
+		// GetDomainShardUsage returns the storage used by the domain in the shard,
+		// it returns false if the shard has not been scanned yet
+		GetDomainShardUsage(shardID int, domainID string) (int64, bool)
+		// GetShardUsage returns the storage used by each domain in the shard,
+		// it returns false if the shard has not been scanned yet
+		GetShardUsage(shardID int) (map[string]int64, bool)
+	}
+
+	trackerImpl struct {
+		status        int32
+		shutdownCh    chan struct{}
+		domainCache   cache.DomainCache
+		metricsClient metrics.Client
+		logger        log.Logger
+
+		sync.RWMutex
+		// shardID -> domainID -> bytes, only contains the shards which have been scanned
+		shards map[int]map[string]int64
+		// names of the domains whose gauge was last reported with a non zero usage, only used by the emit loop
+		emittedDomains map[string]struct{}
+	}
+)
+
+var _ Tracker = (*trackerImpl)(nil)
+
+// NewTracker creates a new domain storage tracker
+func NewTracker(
+	domainCache cache.DomainCache,
+	metricsClient metrics.Client,
+	logger log.Logger,
+) Tracker {
+	return &trackerImpl{
+		status:        common.DaemonStatusInitialized,
+		shutdownCh:    make(chan struct{}),
+		domainCache:   domainCache,
+		metricsClient: metricsClient,
+		logger:        logger.WithTags(tag.ComponentDomainStorageTracker),
+		shards:        make(map[int]map[string]int64),
+
+		emittedDomains: make(map[string]struct{}),
+	}
+}
+
+func (t *trackerImpl) Start() {
+	if !atomic.CompareAndSwapInt32(
+		&t.status,
+		common.DaemonStatusInitialized,
+		common.DaemonStatusStarted,
+	) {
+		return
+	}
+
+	go t.emitMetricsLoop()
+	t.logger.Info("Domain storage tracker state changed", tag.LifeCycleStarted)
+}
+
+func (t *trackerImpl) Stop() {
+	if !atomic.CompareAndSwapInt32(
+		&t.status,
+		common.DaemonStatusStarted,
+		common.DaemonStatusStopped,
+	) {
+		return
+	}
+
+	close(t.shutdownCh)
+	t.logger.Info("Domain storage tracker state changed", tag.LifeCycleStopped)
+}
+
+func (t *trackerImpl) SetShardUsage(shardID int, usage map[string]int64) {
+	t.Lock()
+	defer t.Unlock()
+
+	t.shards[shardID] = usage
+}
+
+func (t *trackerImpl) AddHistorySize(shardID int, domainID string, size int64) {
+	t.Lock()
+	defer t.Unlock()
+
+	// the usage of a shard which has not been scanned yet is only known once the scan completes
+	if usage, ok := t.shards[shardID]; ok {
+		usage[domainID] += size
+	}
+}
+
+func (t *trackerImpl) RemoveHistorySize(shardID int, domainID string, size int64) {
+	t.Lock()
+	defer t.Unlock()
+
+	// the history archived and deleted by the archiver is only subtracted by the next scan
+	if usage, ok := t.shards[shardID]; ok {
+		usage[domainID] -= size
+		if usage[domainID] <= 0 {
+			delete(usage, domainID)
+		}
+	}
+}
+
+func (t *trackerImpl) RemoveShard(shardID int) {
+	t.Lock()
+	defer t.Unlock()
+
+	delete(t.shards, shardID)
+}
+
+func (t *trackerImpl) GetDomainShardUsage(shardID int, domainID string) (int64, bool) {
+	t.RLock()
+	defer t.RUnlock()
+
+	usage, ok := t.shards[shardID]
+	if !ok {
+		return 0, false
+	}
+	return usage[domainID], true
+}
+
+func (t *trackerImpl) GetShardUsage(shardID int) (map[string]int64, bool) {
+	t.RLock()
+	defer t.RUnlock()
+
+	usage, ok := t.shards[shardID]
+	if !ok {
+		return nil, false
+	}
+	result := make(map[string]int64, len(usage))
+	for domainID, size := range usage {
+		result[domainID] = size
+	}
+	return result, true
+}
+
+// getHostUsage returns the storage used by each domain in the scanned shards owned by the host
+func (t *trackerImpl) getHostUsage() map[string]int64 {
+	t.RLock()
+	defer t.RUnlock()
+
+	result := make(map[string]int64)
+	for _, usage := range t.shards {
+		for domainID, size := range usage {
+			result[domainID] += size
+		}
+	}
+	return result
+}
+
+func (t *trackerImpl) emitMetricsLoop() {
+	ticker := time.NewTicker(emitMetricsInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-t.shutdownCh:
+			return
+		case <-ticker.C:
+			t.emitMetrics()
+		}
+	}
+}
+
+func (t *trackerImpl) emitMetrics() {
+	// the gauges report the usage of the shards owned by the host,
+	// the sum of the gauges of all hosts is the usage of the domain in the cluster
+	emitted := make(map[string]struct{})
+	for domainID, size := range t.getHostUsage() {
+		domainName, err := t.domainCache.GetDomainName(domainID)
+		if err != nil {
+			continue
+		}
+		t.metricsClient.Scope(metrics.DomainStorageScope, metrics.DomainTag(domainName)).
+			UpdateGauge(metrics.DomainStorageUsageGauge, float64(size))
+		emitted[domainName] = struct{}{}
+	}
+	// a gauge keeps its last value, reset the domains whose shards have moved to other hosts
+	// so that they are not counted twice in the sum
+	for domainName := range t.emittedDomains {
+		if _, ok := emitted[domainName]; !ok {
+			t.metricsClient.Scope(metrics.DomainStorageScope, metrics.DomainTag(domainName)).
+				UpdateGauge(metrics.DomainStorageUsageGauge, 0)
+		}
+	}
+	t.emittedDomains = emitted
+}
@@ -0,0 +1,151 @@
+// Copyright (c) 2017-2020 Uber Technologies Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package storage
+
+import (
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+	"github.com/uber-go/tally"
+
+	"github.com/uber/cadence/common/cache"
+	"github.com/uber/cadence/common/log/loggerimpl"
+	"github.com/uber/cadence/common/metrics"
+)
+
+type (
+	trackerSuite struct {
+		suite.Suite
+		*require.Assertions
+
+		controller *gomock.Controller
+		tracker    *trackerImpl
+	}
+)
+
+func TestTrackerSuite(t *testing.T) {
+	s := new(trackerSuite)
+	suite.Run(t, s)
+}
+
+func (s *trackerSuite) SetupTest() {
+	s.Assertions = require.New(s.T())
+	s.controller = gomock.NewController(s.T())
+
+	s.tracker = NewTracker(
+		cache.NewMockDomainCache(s.controller),
+		metrics.NewNoopMetricsClient(),
+		loggerimpl.NewNopLogger(),
+	).(*trackerImpl)
+}
+
+func (s *trackerSuite) TearDownTest() {
+	s.controller.Finish()
+}
+
+func (s *trackerSuite) TestGetDomainShardUsage_NotScanned() {
+	s.tracker.AddHistorySize(1, "domain-1", 100)
+
+	_, ok := s.tracker.GetDomainShardUsage(1, "domain-1")
+	s.False(ok)
+	_, ok = s.tracker.GetShardUsage(1)
+	s.False(ok)
+}
+
+func (s *trackerSuite) TestGetDomainShardUsage() {
+	s.tracker.SetShardUsage(1, map[string]int64{"domain-1": 100, "domain-2": 10})
+	s.tracker.SetShardUsage(2, map[string]int64{"domain-1": 300})
+
+	usage, ok := s.tracker.GetDomainShardUsage(1, "domain-1")
+	s.True(ok)
+	s.Equal(int64(100), usage)
+
+	usage, ok = s.tracker.GetDomainShardUsage(2, "domain-2")
+	s.True(ok)
+	s.Equal(int64(0), usage)
+
+	shardUsage, ok := s.tracker.GetShardUsage(1)
+	s.True(ok)
+	s.Equal(map[string]int64{"domain-1": 100, "domain-2": 10}, shardUsage)
+	// the returned usage is a copy
+	shardUsage["domain-1"] = 0
+	usage, _ = s.tracker.GetDomainShardUsage(1, "domain-1")
+	s.Equal(int64(100), usage)
+}
+
+func (s *trackerSuite) TestAddHistorySize() {
+	s.tracker.SetShardUsage(1, map[string]int64{"domain-1": 100})
+	s.tracker.AddHistorySize(1, "domain-1", 50)
+	s.tracker.AddHistorySize(1, "domain-2", 20)
+	// shard 2 has not been scanned, its usage will be known after its scan
+	s.tracker.AddHistorySize(2, "domain-1", 1000)
+
+	s.Equal(map[string]int64{"domain-1": 150, "domain-2": 20}, s.tracker.getHostUsage())
+}
+
+func (s *trackerSuite) TestRemoveHistorySize() {
+	s.tracker.SetShardUsage(1, map[string]int64{"domain-1": 100, "domain-2": 20})
+	s.tracker.RemoveHistorySize(1, "domain-1", 30)
+	s.tracker.RemoveHistorySize(1, "domain-2", 50)
+	s.tracker.RemoveHistorySize(2, "domain-1", 10)
+
+	s.Equal(map[string]int64{"domain-1": 70}, s.tracker.getHostUsage())
+}
+
+func (s *trackerSuite) TestRemoveShard() {
+	s.tracker.SetShardUsage(1, map[string]int64{"domain-1": 100})
+	s.tracker.SetShardUsage(2, map[string]int64{"domain-1": 200})
+	s.tracker.RemoveShard(2)
+
+	s.Equal(map[string]int64{"domain-1": 100}, s.tracker.getHostUsage())
+	_, ok := s.tracker.GetDomainShardUsage(2, "domain-1")
+	s.False(ok)
+}
+
+func (s *trackerSuite) TestEmitMetrics_ResetsMovedDomains() {
+	domainCache := cache.NewMockDomainCache(s.controller)
+	domainCache.EXPECT().GetDomainName("domain-1").Return("domain-name-1", nil).AnyTimes()
+	domainCache.EXPECT().GetDomainName("domain-2").Return("domain-name-2", nil).AnyTimes()
+	scope := tally.NewTestScope("", nil)
+	tracker := NewTracker(domainCache, metrics.NewClient(scope, metrics.History), loggerimpl.NewNopLogger()).(*trackerImpl)
+
+	tracker.SetShardUsage(1, map[string]int64{"domain-1": 100})
+	tracker.SetShardUsage(2, map[string]int64{"domain-2": 200})
+	tracker.emitMetrics()
+	s.Equal(map[string]float64{"domain-name-1": 100, "domain-name-2": 200}, domainStorageGauges(scope))
+
+	// shard 2 moved to another host
+	tracker.RemoveShard(2)
+	tracker.emitMetrics()
+	s.Equal(map[string]float64{"domain-name-1": 100, "domain-name-2": 0}, domainStorageGauges(scope))
+}
+
+func domainStorageGauges(scope tally.TestScope) map[string]float64 {
+	result := make(map[string]float64)
+	for _, gauge := range scope.Snapshot().Gauges() {
+		if gauge.Name() == "domain_storage_bytes" {
+			result[gauge.Tags()["domain"]] = gauge.Value()
+		}
+	}
+	return result
+}
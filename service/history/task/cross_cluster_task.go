@@ -420,12 +420,15 @@ func (t *crossClusterSourceTask) IsReadyForPoll() bool {
 // If both returned error and request are nil:
 // - there's nothing need to be done for the task, task already acked and is not available
 //   for polling again
+// - or the task failed without sending it to the target cluster, e.g. the data residency of
+//   the domain doesn't allow the target cluster, in which case the task is not acked and
+//   caller should submit the task for processing
 // If the returned request is not nil
 // - the request can be returned to the target cluster
 func (t *crossClusterSourceTask) GetCrossClusterRequest() (request *types.CrossClusterTaskRequest, retError error) {
 	t.Lock()
 	defer func() {
-		if retError == nil && request == nil {
+		if retError == nil && request == nil && t.processingState != processingStateResponseReported {
 			t.state = ctask.TaskStateAcked
 		}
 		t.Unlock()
@@ -435,6 +438,32 @@ func (t *crossClusterSourceTask) GetCrossClusterRequest() (request *types.CrossC
 		return nil, errors.New("task invalidated")
 	}
 
+	// the task is not sent when the data residency of the domain doesn't allow the target cluster,
+	// instead the operation is failed in the source workflow so that it doesn't wait for it forever
+	sourceEntry, err := t.shard.GetDomainCache().GetDomainByID(t.GetDomainID())
+	if err != nil {
+		return nil, err
+	}
+	if !sourceEntry.IsClusterAllowed(t.targetCluster) {
+		t.shard.GetMetricsClient().Scope(
+			metrics.CrossClusterQueueProcessorScope,
+			metrics.DomainTag(sourceEntry.GetInfo().Name),
+			metrics.TargetClusterTag(t.targetCluster),
+		).IncCounter(metrics.DataResidencyViolationCounter)
+		t.logger.Error("Fail cross cluster task not allowed by the domain data residency.",
+			tag.WorkflowDomainName(sourceEntry.GetInfo().Name),
+			tag.WorkflowID(t.GetWorkflowID()),
+			tag.WorkflowRunID(t.GetRunID()),
+			tag.ClusterName(t.targetCluster),
+			tag.TaskID(t.GetTaskID()),
+		)
+		if response := t.getResponseForDataResidencyViolation(); response != nil {
+			t.processingState = processingStateResponseReported
+			t.response = response
+		}
+		return nil, nil
+	}
+
 	ctx, cancel := context.WithTimeout(context.Background(), taskDefaultTimeout)
 	defer cancel()
 
@@ -505,6 +534,35 @@ func (t *crossClusterSourceTask) GetCrossClusterRequest() (request *types.CrossC
 	return request, nil
 }
 
+// getResponseForDataResidencyViolation returns the failed response recorded for the task when its
+// target cluster is not allowed, so that the operation fails in the source workflow with the same
+// events as when the target workflow or domain can't be found. Nil is returned when there's nothing
+// to fail in the source workflow.
+func (t *crossClusterSourceTask) getResponseForDataResidencyViolation() *types.CrossClusterTaskResponse {
+	if t.processingState != processingStateInitialized {
+		return nil
+	}
+
+	response := &types.CrossClusterTaskResponse{
+		TaskID:    t.GetTaskID(),
+		TaskState: int16(processingStateInitialized),
+	}
+	switch t.GetTaskType() {
+	case persistence.CrossClusterTaskTypeStartChildExecution:
+		response.TaskType = types.CrossClusterTaskTypeStartChildExecution.Ptr()
+		response.FailedCause = types.CrossClusterTaskFailedCauseDomainNotExists.Ptr()
+	case persistence.CrossClusterTaskTypeCancelExecution:
+		response.TaskType = types.CrossClusterTaskTypeCancelExecution.Ptr()
+		response.FailedCause = types.CrossClusterTaskFailedCauseWorkflowNotExists.Ptr()
+	case persistence.CrossClusterTaskTypeSignalExecution:
+		response.TaskType = types.CrossClusterTaskTypeSignalExecution.Ptr()
+		response.FailedCause = types.CrossClusterTaskFailedCauseWorkflowNotExists.Ptr()
+	default:
+		return nil
+	}
+	return response
+}
+
 func (t *crossClusterSourceTask) VerifyLastWriteVersion(
 	mutableState execution.MutableState,
 	taskInfo *persistence.CrossClusterTaskInfo,
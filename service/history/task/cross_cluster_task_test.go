@@ -998,6 +998,89 @@ func (s *crossClusterTaskSuite) testGetSignalExecutionRequest(
 	validationFn(request, err, sourceTask, signalInfo)
 }
 
+func (s *crossClusterTaskSuite) TestSourceTask_GetRequest_DataResidencyViolation() {
+	domainID := uuid.New()
+	domainEntry := cache.NewGlobalDomainCacheEntryForTest(
+		&p.DomainInfo{
+			ID:   domainID,
+			Name: "some random domain name",
+			Data: map[string]string{common.DomainDataKeyForAllowedClusters: cluster.TestCurrentClusterName},
+		},
+		&p.DomainConfig{Retention: 1},
+		&p.DomainReplicationConfig{
+			ActiveClusterName: cluster.TestCurrentClusterName,
+			Clusters: []*p.ClusterReplicationConfig{
+				{ClusterName: cluster.TestCurrentClusterName},
+				{ClusterName: cluster.TestAlternativeClusterName},
+			},
+		},
+		constants.TestVersion,
+		nil,
+	)
+	s.mockDomainCache.EXPECT().GetDomainByID(domainID).Return(domainEntry, nil).AnyTimes()
+
+	testCases := []struct {
+		taskType            int
+		expectedTaskType    types.CrossClusterTaskType
+		expectedFailedCause types.CrossClusterTaskFailedCause
+	}{
+		{
+			taskType:            p.CrossClusterTaskTypeStartChildExecution,
+			expectedTaskType:    types.CrossClusterTaskTypeStartChildExecution,
+			expectedFailedCause: types.CrossClusterTaskFailedCauseDomainNotExists,
+		},
+		{
+			taskType:            p.CrossClusterTaskTypeCancelExecution,
+			expectedTaskType:    types.CrossClusterTaskTypeCancelExecution,
+			expectedFailedCause: types.CrossClusterTaskFailedCauseWorkflowNotExists,
+		},
+		{
+			taskType:            p.CrossClusterTaskTypeSignalExecution,
+			expectedTaskType:    types.CrossClusterTaskTypeSignalExecution,
+			expectedFailedCause: types.CrossClusterTaskFailedCauseWorkflowNotExists,
+		},
+	}
+
+	for _, tc := range testCases {
+		sourceTask := s.newTestSourceTask(
+			cluster.TestAlternativeClusterName,
+			&p.CrossClusterTaskInfo{
+				DomainID:       domainID,
+				WorkflowID:     "some random workflow ID",
+				RunID:          uuid.New(),
+				TargetDomainID: constants.TestRemoteTargetDomainID,
+				TaskID:         int64(59),
+				TaskType:       tc.taskType,
+			},
+		)
+
+		request, err := sourceTask.GetCrossClusterRequest()
+		s.NoError(err)
+		s.Nil(request)
+		s.Equal(ctask.TaskStatePending, sourceTask.State())
+		s.Equal(processingStateResponseReported, sourceTask.ProcessingState())
+		s.False(sourceTask.IsReadyForPoll())
+		s.Equal(tc.expectedTaskType, sourceTask.response.GetTaskType())
+		s.Equal(tc.expectedFailedCause, sourceTask.response.GetFailedCause())
+		s.Equal(int16(processingStateInitialized), sourceTask.response.TaskState)
+	}
+
+	// nothing to fail in the source workflow for the remaining task types
+	sourceTask := s.newTestSourceTask(
+		cluster.TestAlternativeClusterName,
+		&p.CrossClusterTaskInfo{
+			DomainID:       domainID,
+			TargetDomainID: constants.TestRemoteTargetDomainID,
+			TaskID:         int64(59),
+			TaskType:       p.CrossClusterTaskTypeRecordChildExeuctionCompleted,
+		},
+	)
+	request, err := sourceTask.GetCrossClusterRequest()
+	s.NoError(err)
+	s.Nil(request)
+	s.Equal(ctask.TaskStateAcked, sourceTask.State())
+}
+
 func (s *crossClusterTaskSuite) newTestSourceTask(
 	targetCluster string,
 	taskInfo *p.CrossClusterTaskInfo,
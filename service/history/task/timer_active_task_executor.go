@@ -137,6 +137,10 @@ func (t *timerActiveTaskExecutor) executeUserTimerTimeoutTask(
 	if mutableState == nil || !mutableState.IsWorkflowExecutionRunning() {
 		return nil
 	}
+	if execution.IsWorkflowPaused(mutableState) {
+		// the timer is created again when the workflow is unpaused
+		return nil
+	}
 
 	timerSequence := execution.NewTimerSequence(mutableState)
 	referenceTime := t.shard.GetTimeSource().Now()
@@ -239,6 +243,10 @@ func (t *timerActiveTaskExecutor) executeActivityTimeoutTask(
 	if mutableState == nil || !mutableState.IsWorkflowExecutionRunning() {
 		return nil
 	}
+	if execution.IsWorkflowPaused(mutableState) {
+		// the timer is created again when the workflow is unpaused
+		return nil
+	}
 
 	timerSequence := execution.NewTimerSequence(mutableState)
 	referenceTime := t.shard.GetTimeSource().Now()
@@ -401,6 +409,10 @@ func (t *timerActiveTaskExecutor) executeDecisionTimeoutTask(
 	if mutableState == nil || !mutableState.IsWorkflowExecutionRunning() {
 		return nil
 	}
+	if execution.IsWorkflowPaused(mutableState) {
+		// the timer is created again when the workflow is unpaused
+		return nil
+	}
 
 	scheduleID := task.EventID
 	decision, ok := mutableState.GetDecisionInfo(scheduleID)
@@ -497,6 +509,10 @@ func (t *timerActiveTaskExecutor) executeWorkflowBackoffTimerTask(
 	if mutableState == nil || !mutableState.IsWorkflowExecutionRunning() {
 		return nil
 	}
+	if execution.IsWorkflowPaused(mutableState) {
+		// the timer is created again when the workflow is unpaused
+		return nil
+	}
 
 	if task.TimeoutType == persistence.WorkflowBackoffTimeoutTypeRetry {
 		t.metricsClient.IncCounter(metrics.TimerActiveTaskWorkflowBackoffTimerScope, metrics.WorkflowRetryBackoffTimerCount)
@@ -538,6 +554,10 @@ func (t *timerActiveTaskExecutor) executeActivityRetryTimerTask(
 	if mutableState == nil || !mutableState.IsWorkflowExecutionRunning() {
 		return nil
 	}
+	if execution.IsWorkflowPaused(mutableState) {
+		// the timer is created again when the workflow is unpaused
+		return nil
+	}
 
 	// generate activity task
 	scheduledID := task.EventID
@@ -140,7 +140,7 @@ func (t *timerTaskExecutorBase) deleteWorkflow(
 		return err
 	}
 
-	if err := t.deleteWorkflowHistory(ctx, task, msBuilder); err != nil {
+	if err := t.deleteWorkflowHistory(ctx, task, context, msBuilder); err != nil {
 		return err
 	}
 
@@ -206,7 +206,7 @@ func (t *timerTaskExecutorBase) archiveWorkflow(
 	// delete workflow history if history archival is not needed or history as been archived inline
 	if resp.HistoryArchivedInline {
 		t.metricsClient.IncCounter(metrics.HistoryProcessDeleteHistoryEventScope, metrics.WorkflowCleanupDeleteHistoryInlineCount)
-		if err := t.deleteWorkflowHistory(ctx, task, msBuilder); err != nil {
+		if err := t.deleteWorkflowHistory(ctx, task, workflowContext, msBuilder); err != nil {
 			return err
 		}
 	}
@@ -254,6 +254,7 @@ func (t *timerTaskExecutorBase) deleteCurrentWorkflowExecution(
 func (t *timerTaskExecutorBase) deleteWorkflowHistory(
 	ctx context.Context,
 	task *persistence.TimerTaskInfo,
+	workflowContext execution.Context,
 	msBuilder execution.MutableState,
 ) error {
 
@@ -268,7 +269,15 @@ func (t *timerTaskExecutorBase) deleteWorkflowHistory(
 		})
 
 	}
-	return t.throttleRetry.Do(ctx, op)
+	if err := t.throttleRetry.Do(ctx, op); err != nil {
+		return err
+	}
+	t.shard.GetService().GetDomainStorageTracker().RemoveHistorySize(
+		t.shard.GetShardID(),
+		task.DomainID,
+		workflowContext.GetHistorySize(),
+	)
+	return nil
 }
 
 func (t *timerTaskExecutorBase) deleteWorkflowVisibility(
@@ -187,6 +187,10 @@ func (t *transferActiveTaskExecutor) processActivityTask(
 	if err != nil || !ok {
 		return err
 	}
+	if execution.IsWorkflowPaused(mutableState) {
+		// the activity task is generated again when the workflow is unpaused
+		return nil
+	}
 
 	timeout := common.MinInt32(ai.ScheduleToStartTimeout, common.MaxTaskTimeout)
 	// release the context lock since we no longer need mutable state builder and
@@ -230,6 +234,10 @@ func (t *transferActiveTaskExecutor) processDecisionTask(
 	if err != nil || !ok {
 		return err
 	}
+	if execution.IsWorkflowPaused(mutableState) {
+		// the decision task is generated again when the workflow is unpaused
+		return nil
+	}
 
 	executionInfo := mutableState.GetExecutionInfo()
 	workflowTimeout := executionInfo.WorkflowTimeout
@@ -1778,6 +1786,7 @@ func (t *transferActiveTaskExecutor) resetWorkflow(
 		reason,
 		nil,
 		false,
+		false,
 	)
 
 	switch err.(type) {
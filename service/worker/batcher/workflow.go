@@ -71,10 +71,14 @@ const (
 	BatchTypeCancel = "cancel"
 	// BatchTypeSignal is batch type for signaling workflows
 	BatchTypeSignal = "signal"
+	// BatchTypePause is batch type for pausing workflows
+	BatchTypePause = "pause"
+	// BatchTypeUnpause is batch type for unpausing workflows
+	BatchTypeUnpause = "unpause"
 )
 
 // AllBatchTypes is the batch types we supported
-var AllBatchTypes = []string{BatchTypeTerminate, BatchTypeCancel, BatchTypeSignal}
+var AllBatchTypes = []string{BatchTypeTerminate, BatchTypeCancel, BatchTypeSignal, BatchTypePause, BatchTypeUnpause}
 
 type (
 	// TerminateParams is the parameters for terminating workflow
@@ -199,9 +203,7 @@ func validateParams(params BatchParams) error {
 			return fmt.Errorf("must provide signal name")
 		}
 		return nil
-	case BatchTypeCancel:
-		fallthrough
-	case BatchTypeTerminate:
+	case BatchTypeCancel, BatchTypeTerminate, BatchTypePause, BatchTypeUnpause:
 		return nil
 	default:
 		return fmt.Errorf("not supported batch type: %v", params.BatchType)
@@ -392,6 +394,25 @@ func startTaskProcessor(
 							Input:      []byte(batchParams.SignalParams.Input),
 						})
 					})
+			case BatchTypePause, BatchTypeUnpause:
+				signalName := common.PauseWorkflowSignalName
+				if batchParams.BatchType == BatchTypeUnpause {
+					signalName = common.UnpauseWorkflowSignalName
+				}
+				err = processTask(ctx, limiter, task, batchParams, client, common.BoolPtr(false),
+					func(workflowID, runID string) error {
+						return client.SignalWorkflowExecution(ctx, &types.SignalWorkflowExecutionRequest{
+							Domain: batchParams.DomainName,
+							WorkflowExecution: &types.WorkflowExecution{
+								WorkflowID: workflowID,
+								RunID:      runID,
+							},
+							Identity:   BatchWFTypeName,
+							RequestID:  requestID,
+							SignalName: signalName,
+							Input:      []byte(batchParams.Reason),
+						})
+					})
 			}
 			if err != nil {
 				batcher.metricsClient.IncCounter(metrics.BatcherScope, metrics.BatcherProcessorFailures)
@@ -0,0 +1,221 @@
+// Copyright (c) 2017-2020 Uber Technologies Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package migration
+
+import (
+	"context"
+	"errors"
+
+	"go.uber.org/cadence"
+	"go.uber.org/cadence/activity"
+
+	"github.com/uber/cadence/common"
+	"github.com/uber/cadence/common/log/tag"
+	"github.com/uber/cadence/common/persistence"
+	"github.com/uber/cadence/common/reconciliation/fetcher"
+	"github.com/uber/cadence/common/reconciliation/invariant"
+	"github.com/uber/cadence/common/types"
+)
+
+const (
+	errShadowWritesDisabledReason = "cadence-sys-migration-shadow-writes-disabled"
+)
+
+type (
+	// CopyExecutionsActivityParams is the input of CopyExecutionsActivity and VerifyExecutionsActivity
+	CopyExecutionsActivityParams struct {
+		ShardID   int
+		PageSize  int
+		PageToken []byte
+	}
+
+	// CopyExecutionsActivityResult is the result of CopyExecutionsActivity
+	CopyExecutionsActivityResult struct {
+		NextPageToken []byte
+		Created       int64
+		Updated       int64
+		Skipped       int64
+		Failed        int64
+	}
+
+	// VerifyExecutionsActivityResult is the result of VerifyExecutionsActivity
+	VerifyExecutionsActivityResult struct {
+		NextPageToken []byte
+		Healthy       int64
+		Corrupted     int64
+		Failed        int64
+	}
+)
+
+// CopyDomainsActivity copies all domains into the migration target store
+func CopyDomainsActivity(ctx context.Context) (int, error) {
+	m, err := getMigrator(ctx)
+	if err != nil {
+		return 0, err
+	}
+	// executions changed during the backfill are only mirrored with shadow writes enabled,
+	// without them the backfill would never converge
+	migrationMode := m.persistenceConfig.MigrationMode
+	if migrationMode == nil || migrationMode() != common.PersistenceMigrationModeShadow {
+		return 0, cadence.NewCustomError(errShadowWritesDisabledReason)
+	}
+	return newCopier(m.resource.GetPersistenceBean(), m.target, m.resource.GetHistoryClient(), m.logger).copyDomains(ctx)
+}
+
+// GetShardCountActivity returns the number of history shards
+func GetShardCountActivity(ctx context.Context) (int, error) {
+	m, err := getMigrator(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return m.persistenceConfig.NumHistoryShards, nil
+}
+
+// CopyShardActivity copies the shard info into the migration target store
+func CopyShardActivity(ctx context.Context, shardID int) error {
+	m, err := getMigrator(ctx)
+	if err != nil {
+		return err
+	}
+	_, err = newCopier(m.resource.GetPersistenceBean(), m.target, m.resource.GetHistoryClient(), m.logger).copyShard(ctx, shardID)
+	return err
+}
+
+// CopyExecutionsActivity copies a page of workflow executions of a shard into the migration target store.
+// Executions which fail to be copied are counted and logged, they are picked up by the next backfill.
+func CopyExecutionsActivity(ctx context.Context, params CopyExecutionsActivityParams) (*CopyExecutionsActivityResult, error) {
+	m, err := getMigrator(ctx)
+	if err != nil {
+		return nil, err
+	}
+	c := newCopier(m.resource.GetPersistenceBean(), m.target, m.resource.GetHistoryClient(), m.logger)
+	sourceExecutions, err := c.source.GetExecutionManager(params.ShardID)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := sourceExecutions.ListConcreteExecutions(ctx, &persistence.ListConcreteExecutionsRequest{
+		PageSize:  params.PageSize,
+		PageToken: params.PageToken,
+	})
+	if err != nil {
+		return nil, err
+	}
+	shard, err := c.target.GetShardManager().GetShard(ctx, &persistence.GetShardRequest{ShardID: params.ShardID})
+	if err != nil {
+		return nil, err
+	}
+
+	result := &CopyExecutionsActivityResult{NextPageToken: resp.PageToken}
+	for _, entity := range resp.Executions {
+		info := entity.ExecutionInfo
+		execution := types.WorkflowExecution{WorkflowID: info.WorkflowID, RunID: info.RunID}
+		copyResult, err := c.copyExecution(ctx, params.ShardID, shard.ShardInfo.RangeID, info.DomainID, execution)
+		if err != nil {
+			c.logExecutionError(err, params.ShardID, info.DomainID, execution)
+			result.Failed++
+			continue
+		}
+		switch copyResult {
+		case executionCopyResultCreated:
+			result.Created++
+		case executionCopyResultUpdated:
+			result.Updated++
+		default:
+			result.Skipped++
+		}
+		activity.RecordHeartbeat(ctx)
+	}
+	return result, nil
+}
+
+// VerifyExecutionsActivity checks that a page of workflow executions of a shard matches their copies in the
+// migration target store
+func VerifyExecutionsActivity(ctx context.Context, params CopyExecutionsActivityParams) (*VerifyExecutionsActivityResult, error) {
+	m, err := getMigrator(ctx)
+	if err != nil {
+		return nil, err
+	}
+	sourceExecutions, err := m.resource.GetPersistenceBean().GetExecutionManager(params.ShardID)
+	if err != nil {
+		return nil, err
+	}
+	targetExecutions, err := m.target.GetExecutionManager(params.ShardID)
+	if err != nil {
+		return nil, err
+	}
+	source := persistence.NewPersistenceRetryer(
+		sourceExecutions, m.resource.GetHistoryManager(), common.CreatePersistenceRetryPolicy(),
+	)
+	target := persistence.NewPersistenceRetryer(
+		targetExecutions, m.target.GetHistoryManager(), common.CreatePersistenceRetryPolicy(),
+	)
+	inv := invariant.NewMigratedExecutionMatches(source, target)
+
+	resp, err := source.ListConcreteExecutions(ctx, &persistence.ListConcreteExecutionsRequest{
+		PageSize:  params.PageSize,
+		PageToken: params.PageToken,
+	})
+	if err != nil {
+		return nil, err
+	}
+	result := &VerifyExecutionsActivityResult{NextPageToken: resp.PageToken}
+	for _, e := range resp.Executions {
+		execution, err := fetcher.ConcreteExecution(ctx, source, fetcher.ExecutionRequest{
+			DomainID:   e.ExecutionInfo.DomainID,
+			WorkflowID: e.ExecutionInfo.WorkflowID,
+			RunID:      e.ExecutionInfo.RunID,
+		})
+		if err != nil {
+			if _, ok := err.(*types.EntityNotExistsError); ok {
+				result.Healthy++
+				continue
+			}
+			result.Failed++
+			continue
+		}
+		checkResult := inv.Check(ctx, execution)
+		switch checkResult.CheckResultType {
+		case invariant.CheckResultTypeHealthy:
+			result.Healthy++
+		case invariant.CheckResultTypeCorrupted:
+			m.logger.Warn("migrated workflow execution does not match",
+				tag.ShardID(params.ShardID),
+				tag.WorkflowDomainID(e.ExecutionInfo.DomainID),
+				tag.WorkflowID(e.ExecutionInfo.WorkflowID),
+				tag.WorkflowRunID(e.ExecutionInfo.RunID),
+				tag.Value(checkResult),
+			)
+			result.Corrupted++
+		default:
+			result.Failed++
+		}
+		activity.RecordHeartbeat(ctx)
+	}
+	return result, nil
+}
+
+func getMigrator(ctx context.Context) (*Migrator, error) {
+	m, ok := ctx.Value(migratorContextKey).(*Migrator)
+	if !ok {
+		return nil, errors.New("could not retrieve migrator from context")
+	}
+	return m, nil
+}
@@ -0,0 +1,576 @@
+// Copyright (c) 2017-2020 Uber Technologies Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package migration
+
+import (
+	"context"
+
+	"github.com/uber/cadence/.gen/go/shared"
+	"github.com/uber/cadence/client/history"
+	"github.com/uber/cadence/common"
+	"github.com/uber/cadence/common/codec"
+	"github.com/uber/cadence/common/log"
+	"github.com/uber/cadence/common/log/tag"
+	"github.com/uber/cadence/common/persistence"
+	persistenceClient "github.com/uber/cadence/common/persistence/client"
+	"github.com/uber/cadence/common/types"
+)
+
+const (
+	domainPageSize  = 100
+	historyPageSize = 100
+)
+
+type (
+	// copier copies the persisted state from the source datastore into the migration target datastore.
+	// All the copy operations are idempotent, so a failed copy can simply be retried.
+	copier struct {
+		source        persistenceClient.Bean
+		target        persistenceClient.Bean
+		historyClient history.Client
+		thriftEncoder codec.BinaryEncoder
+		logger        log.Logger
+	}
+
+	// executionCopyResult is the outcome of copying a single workflow execution
+	executionCopyResult int
+)
+
+const (
+	executionCopyResultSkipped executionCopyResult = iota
+	executionCopyResultCreated
+	executionCopyResultUpdated
+)
+
+func newCopier(
+	source persistenceClient.Bean,
+	target persistenceClient.Bean,
+	historyClient history.Client,
+	logger log.Logger,
+) *copier {
+	return &copier{
+		source:        source,
+		target:        target,
+		historyClient: historyClient,
+		thriftEncoder: codec.NewThriftRWEncoder(),
+		logger:        logger,
+	}
+}
+
+// copyDomains copies all domains into the target store and returns the number of domains copied
+func (c *copier) copyDomains(ctx context.Context) (int, error) {
+	var pageToken []byte
+	count := 0
+	for {
+		resp, err := c.source.GetDomainManager().ListDomains(ctx, &persistence.ListDomainsRequest{
+			PageSize:      domainPageSize,
+			NextPageToken: pageToken,
+		})
+		if err != nil {
+			return count, err
+		}
+		for _, domain := range resp.Domains {
+			copied, err := c.copyDomain(ctx, domain)
+			if err != nil {
+				return count, err
+			}
+			if copied {
+				count++
+			}
+		}
+		pageToken = resp.NextPageToken
+		if len(pageToken) == 0 {
+			return count, nil
+		}
+	}
+}
+
+func (c *copier) copyDomain(ctx context.Context, domain *persistence.GetDomainResponse) (bool, error) {
+	targetDomains := c.target.GetDomainManager()
+	existing, err := targetDomains.GetDomain(ctx, &persistence.GetDomainRequest{ID: domain.Info.ID})
+	switch err.(type) {
+	case nil:
+		if isDomainUpToDate(domain, existing) {
+			return false, nil
+		}
+	case *types.EntityNotExistsError:
+		if _, err := targetDomains.CreateDomain(ctx, &persistence.CreateDomainRequest{
+			Info:              domain.Info,
+			Config:            domain.Config,
+			ReplicationConfig: domain.ReplicationConfig,
+			IsGlobalDomain:    domain.IsGlobalDomain,
+			ConfigVersion:     domain.ConfigVersion,
+			FailoverVersion:   domain.FailoverVersion,
+			LastUpdatedTime:   domain.LastUpdatedTime,
+		}); err != nil {
+			return false, err
+		}
+	default:
+		return false, err
+	}
+
+	// the create request does not carry all the fields, so a domain is always updated after being created
+	metadata, err := targetDomains.GetMetadata(ctx)
+	if err != nil {
+		return false, err
+	}
+	if err := targetDomains.UpdateDomain(ctx, &persistence.UpdateDomainRequest{
+		Info:                        domain.Info,
+		Config:                      domain.Config,
+		ReplicationConfig:           domain.ReplicationConfig,
+		ConfigVersion:               domain.ConfigVersion,
+		FailoverVersion:             domain.FailoverVersion,
+		FailoverNotificationVersion: domain.FailoverNotificationVersion,
+		PreviousFailoverVersion:     domain.PreviousFailoverVersion,
+		FailoverEndTime:             domain.FailoverEndTime,
+		LastUpdatedTime:             domain.LastUpdatedTime,
+		NotificationVersion:         metadata.NotificationVersion,
+	}); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func isDomainUpToDate(source, target *persistence.GetDomainResponse) bool {
+	return source.ConfigVersion == target.ConfigVersion &&
+		source.FailoverVersion == target.FailoverVersion &&
+		source.FailoverNotificationVersion == target.FailoverNotificationVersion &&
+		source.PreviousFailoverVersion == target.PreviousFailoverVersion &&
+		source.LastUpdatedTime == target.LastUpdatedTime
+}
+
+// copyShard copies the shard info into the target store and returns the range ID of the shard in the target store
+func (c *copier) copyShard(ctx context.Context, shardID int) (int64, error) {
+	source, err := c.source.GetShardManager().GetShard(ctx, &persistence.GetShardRequest{ShardID: shardID})
+	if err != nil {
+		return 0, err
+	}
+	targetShards := c.target.GetShardManager()
+	target, err := targetShards.GetShard(ctx, &persistence.GetShardRequest{ShardID: shardID})
+	switch err.(type) {
+	case nil:
+		if target.ShardInfo.RangeID >= source.ShardInfo.RangeID {
+			// the shard has already been mirrored by the shadow writes
+			return target.ShardInfo.RangeID, nil
+		}
+		if err := targetShards.UpdateShard(ctx, &persistence.UpdateShardRequest{
+			ShardInfo:       source.ShardInfo,
+			PreviousRangeID: target.ShardInfo.RangeID,
+		}); err != nil {
+			return 0, err
+		}
+	case *types.EntityNotExistsError:
+		if err := targetShards.CreateShard(ctx, &persistence.CreateShardRequest{ShardInfo: source.ShardInfo}); err != nil {
+			return 0, err
+		}
+	default:
+		return 0, err
+	}
+	return source.ShardInfo.RangeID, nil
+}
+
+// copyExecution copies a single workflow execution, including its history, into the target store.
+// The history is copied first so that the copied mutable state never references missing history.
+func (c *copier) copyExecution(
+	ctx context.Context,
+	shardID int,
+	rangeID int64,
+	domainID string,
+	execution types.WorkflowExecution,
+) (executionCopyResult, error) {
+	sourceExecutions, err := c.source.GetExecutionManager(shardID)
+	if err != nil {
+		return executionCopyResultSkipped, err
+	}
+	targetExecutions, err := c.target.GetExecutionManager(shardID)
+	if err != nil {
+		return executionCopyResultSkipped, err
+	}
+
+	getRequest := &persistence.GetWorkflowExecutionRequest{
+		DomainID:  domainID,
+		Execution: execution,
+	}
+	sourceResp, err := sourceExecutions.GetWorkflowExecution(ctx, getRequest)
+	if err != nil {
+		if _, ok := err.(*types.EntityNotExistsError); ok {
+			// the execution has been deleted after being listed
+			return executionCopyResultSkipped, nil
+		}
+		return executionCopyResultSkipped, err
+	}
+	state := sourceResp.State
+
+	var targetState *persistence.WorkflowMutableState
+	targetResp, err := targetExecutions.GetWorkflowExecution(ctx, getRequest)
+	switch err.(type) {
+	case nil:
+		targetState = targetResp.State
+		if isExecutionUpToDate(state.ExecutionInfo, targetState.ExecutionInfo) {
+			return executionCopyResultSkipped, nil
+		}
+	case *types.EntityNotExistsError:
+	default:
+		return executionCopyResultSkipped, err
+	}
+
+	if err := c.copyHistory(ctx, shardID, state); err != nil {
+		return executionCopyResultSkipped, err
+	}
+
+	isCurrent, err := c.isCurrentExecution(ctx, sourceExecutions, state.ExecutionInfo)
+	if err != nil {
+		return executionCopyResultSkipped, err
+	}
+	result := executionCopyResultCreated
+	if targetState != nil {
+		mutation := newOverwriteMutation(state, targetState)
+		if err := c.updateExecution(ctx, targetExecutions, rangeID, isCurrent, mutation); err != nil {
+			return executionCopyResultSkipped, err
+		}
+		result = executionCopyResultUpdated
+	} else if err := c.createExecution(ctx, targetExecutions, rangeID, isCurrent, state); err != nil {
+		return executionCopyResultSkipped, err
+	}
+
+	if err := c.refreshTasks(ctx, state.ExecutionInfo); err != nil {
+		return executionCopyResultSkipped, err
+	}
+	return result, nil
+}
+
+// refreshTasks regenerates the tasks of an open execution. Tasks are not copied by the backfill, the
+// regenerated tasks are written to the default store and mirrored into the target store by the shadow writes.
+func (c *copier) refreshTasks(ctx context.Context, info *persistence.WorkflowExecutionInfo) error {
+	if c.historyClient == nil || info.State == persistence.WorkflowStateCompleted || info.State == persistence.WorkflowStateZombie {
+		return nil
+	}
+	return c.historyClient.RefreshWorkflowTasks(ctx, &types.HistoryRefreshWorkflowTasksRequest{
+		DomainUIID: info.DomainID,
+		Request: &types.RefreshWorkflowTasksRequest{
+			Execution: &types.WorkflowExecution{
+				WorkflowID: info.WorkflowID,
+				RunID:      info.RunID,
+			},
+		},
+	})
+}
+
+func isExecutionUpToDate(source, target *persistence.WorkflowExecutionInfo) bool {
+	return source.NextEventID == target.NextEventID &&
+		source.State == target.State &&
+		source.CloseStatus == target.CloseStatus &&
+		source.DecisionScheduleID == target.DecisionScheduleID
+}
+
+func (c *copier) isCurrentExecution(
+	ctx context.Context,
+	executions persistence.ExecutionManager,
+	info *persistence.WorkflowExecutionInfo,
+) (bool, error) {
+	current, err := executions.GetCurrentExecution(ctx, &persistence.GetCurrentExecutionRequest{
+		DomainID:   info.DomainID,
+		WorkflowID: info.WorkflowID,
+	})
+	switch err.(type) {
+	case nil:
+		return current.RunID == info.RunID, nil
+	case *types.EntityNotExistsError:
+		return false, nil
+	default:
+		return false, err
+	}
+}
+
+// createExecution creates the execution in the target store. Operation modes only allow a limited set of
+// workflow states on creation, so closed executions are created first and closed by a follow up update,
+// which also carries the buffered events.
+func (c *copier) createExecution(
+	ctx context.Context,
+	executions persistence.ExecutionManager,
+	rangeID int64,
+	isCurrent bool,
+	state *persistence.WorkflowMutableState,
+) error {
+	info := state.ExecutionInfo
+	snapshot := newWorkflowSnapshot(state)
+	createRequest := &persistence.CreateWorkflowExecutionRequest{
+		RangeID:             rangeID,
+		NewWorkflowSnapshot: *snapshot,
+	}
+	if isCurrent {
+		current, err := executions.GetCurrentExecution(ctx, &persistence.GetCurrentExecutionRequest{
+			DomainID:   info.DomainID,
+			WorkflowID: info.WorkflowID,
+		})
+		switch err.(type) {
+		case nil:
+			createRequest.Mode = persistence.CreateWorkflowModeWorkflowIDReuse
+			createRequest.PreviousRunID = current.RunID
+			createRequest.PreviousLastWriteVersion = current.LastWriteVersion
+		case *types.EntityNotExistsError:
+			createRequest.Mode = persistence.CreateWorkflowModeBrandNew
+		default:
+			return err
+		}
+		if info.State == persistence.WorkflowStateCompleted {
+			snapshot.ExecutionInfo.State = persistence.WorkflowStateRunning
+			snapshot.ExecutionInfo.CloseStatus = persistence.WorkflowCloseStatusNone
+		}
+	} else {
+		createRequest.Mode = persistence.CreateWorkflowModeZombie
+		snapshot.ExecutionInfo.State = persistence.WorkflowStateZombie
+		snapshot.ExecutionInfo.CloseStatus = persistence.WorkflowCloseStatusNone
+	}
+	createRequest.NewWorkflowSnapshot = *snapshot
+	if _, err := executions.CreateWorkflowExecution(ctx, createRequest); err != nil {
+		return err
+	}
+
+	if snapshot.ExecutionInfo.State == info.State && len(state.BufferedEvents) == 0 {
+		return nil
+	}
+	return c.updateExecution(ctx, executions, rangeID, isCurrent, &persistence.WorkflowMutation{
+		ExecutionInfo:     info,
+		ExecutionStats:    state.ExecutionStats,
+		VersionHistories:  state.VersionHistories,
+		NewBufferedEvents: state.BufferedEvents,
+		Condition:         info.NextEventID,
+		Checksum:          state.Checksum,
+	})
+}
+
+func (c *copier) updateExecution(
+	ctx context.Context,
+	executions persistence.ExecutionManager,
+	rangeID int64,
+	isCurrent bool,
+	mutation *persistence.WorkflowMutation,
+) error {
+	mode := persistence.UpdateWorkflowModeBypassCurrent
+	if isCurrent {
+		mode = persistence.UpdateWorkflowModeUpdateCurrent
+	}
+	_, err := executions.UpdateWorkflowExecution(ctx, &persistence.UpdateWorkflowExecutionRequest{
+		RangeID:                rangeID,
+		Mode:                   mode,
+		UpdateWorkflowMutation: *mutation,
+		Encoding:               common.EncodingTypeThriftRW,
+	})
+	return err
+}
+
+// newWorkflowSnapshot builds a snapshot of the source mutable state without any tasks
+func newWorkflowSnapshot(state *persistence.WorkflowMutableState) *persistence.WorkflowSnapshot {
+	info := *state.ExecutionInfo
+	snapshot := &persistence.WorkflowSnapshot{
+		ExecutionInfo:    &info,
+		ExecutionStats:   state.ExecutionStats,
+		VersionHistories: state.VersionHistories,
+		Condition:        info.NextEventID,
+		Checksum:         state.Checksum,
+	}
+	for _, activityInfo := range state.ActivityInfos {
+		snapshot.ActivityInfos = append(snapshot.ActivityInfos, activityInfo)
+	}
+	for _, timerInfo := range state.TimerInfos {
+		snapshot.TimerInfos = append(snapshot.TimerInfos, timerInfo)
+	}
+	for _, childInfo := range state.ChildExecutionInfos {
+		snapshot.ChildExecutionInfos = append(snapshot.ChildExecutionInfos, childInfo)
+	}
+	for _, cancelInfo := range state.RequestCancelInfos {
+		snapshot.RequestCancelInfos = append(snapshot.RequestCancelInfos, cancelInfo)
+	}
+	for _, signalInfo := range state.SignalInfos {
+		snapshot.SignalInfos = append(snapshot.SignalInfos, signalInfo)
+	}
+	for signalRequestedID := range state.SignalRequestedIDs {
+		snapshot.SignalRequestedIDs = append(snapshot.SignalRequestedIDs, signalRequestedID)
+	}
+	return snapshot
+}
+
+// newOverwriteMutation builds a mutation which replaces the target mutable state with the source one
+func newOverwriteMutation(source, target *persistence.WorkflowMutableState) *persistence.WorkflowMutation {
+	snapshot := newWorkflowSnapshot(source)
+	mutation := &persistence.WorkflowMutation{
+		ExecutionInfo:             source.ExecutionInfo,
+		ExecutionStats:            source.ExecutionStats,
+		VersionHistories:          source.VersionHistories,
+		UpsertActivityInfos:       snapshot.ActivityInfos,
+		UpsertTimerInfos:          snapshot.TimerInfos,
+		UpsertChildExecutionInfos: snapshot.ChildExecutionInfos,
+		UpsertRequestCancelInfos:  snapshot.RequestCancelInfos,
+		UpsertSignalInfos:         snapshot.SignalInfos,
+		UpsertSignalRequestedIDs:  snapshot.SignalRequestedIDs,
+		NewBufferedEvents:         source.BufferedEvents,
+		ClearBufferedEvents:       true,
+		Condition:                 target.ExecutionInfo.NextEventID,
+		Checksum:                  source.Checksum,
+	}
+	for id := range target.ActivityInfos {
+		if _, ok := source.ActivityInfos[id]; !ok {
+			mutation.DeleteActivityInfos = append(mutation.DeleteActivityInfos, id)
+		}
+	}
+	for id := range target.TimerInfos {
+		if _, ok := source.TimerInfos[id]; !ok {
+			mutation.DeleteTimerInfos = append(mutation.DeleteTimerInfos, id)
+		}
+	}
+	for id := range target.ChildExecutionInfos {
+		if _, ok := source.ChildExecutionInfos[id]; !ok {
+			mutation.DeleteChildExecutionInfos = append(mutation.DeleteChildExecutionInfos, id)
+		}
+	}
+	for id := range target.RequestCancelInfos {
+		if _, ok := source.RequestCancelInfos[id]; !ok {
+			mutation.DeleteRequestCancelInfos = append(mutation.DeleteRequestCancelInfos, id)
+		}
+	}
+	for id := range target.SignalInfos {
+		if _, ok := source.SignalInfos[id]; !ok {
+			mutation.DeleteSignalInfos = append(mutation.DeleteSignalInfos, id)
+		}
+	}
+	for id := range target.SignalRequestedIDs {
+		if _, ok := source.SignalRequestedIDs[id]; !ok {
+			mutation.DeleteSignalRequestedIDs = append(mutation.DeleteSignalRequestedIDs, id)
+		}
+	}
+	return mutation
+}
+
+// copyHistory copies all the history branches referenced by the mutable state
+func (c *copier) copyHistory(ctx context.Context, shardID int, state *persistence.WorkflowMutableState) error {
+	var branchTokens [][]byte
+	if state.VersionHistories != nil {
+		for _, versionHistory := range state.VersionHistories.Histories {
+			branchTokens = append(branchTokens, versionHistory.BranchToken)
+		}
+	} else {
+		branchTokens = append(branchTokens, state.ExecutionInfo.BranchToken)
+	}
+	info := persistence.BuildHistoryGarbageCleanupInfo(
+		state.ExecutionInfo.DomainID,
+		state.ExecutionInfo.WorkflowID,
+		state.ExecutionInfo.RunID,
+	)
+	for _, branchToken := range branchTokens {
+		if err := c.copyHistoryBranch(ctx, shardID, info, branchToken); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// copyHistoryBranch copies a history branch. The events before the fork point of a branch are stored
+// under the branch IDs of its ancestors, so they are copied using tokens of the ancestor branches.
+func (c *copier) copyHistoryBranch(ctx context.Context, shardID int, info string, branchToken []byte) error {
+	var branch shared.HistoryBranch
+	if err := c.thriftEncoder.Decode(branchToken, &branch); err != nil {
+		return err
+	}
+
+	beginNodeID := common.FirstEventID
+	for i, ancestor := range branch.Ancestors {
+		ancestorToken, err := c.thriftEncoder.Encode(&shared.HistoryBranch{
+			TreeID:    branch.TreeID,
+			BranchID:  ancestor.BranchID,
+			Ancestors: branch.Ancestors[:i],
+		})
+		if err != nil {
+			return err
+		}
+		if err := c.copyHistoryRange(
+			ctx, shardID, info, ancestorToken, ancestor.GetBeginNodeID(), ancestor.GetEndNodeID(), false,
+		); err != nil {
+			return err
+		}
+		beginNodeID = ancestor.GetEndNodeID()
+	}
+	return c.copyHistoryRange(ctx, shardID, info, branchToken, beginNodeID, common.EndEventID, true)
+}
+
+func (c *copier) copyHistoryRange(
+	ctx context.Context,
+	shardID int,
+	info string,
+	branchToken []byte,
+	minEventID int64,
+	maxEventID int64,
+	isNewBranch bool,
+) error {
+	var pageToken []byte
+	for {
+		resp, err := c.source.GetHistoryManager().ReadHistoryBranchByBatch(ctx, &persistence.ReadHistoryBranchRequest{
+			BranchToken:   branchToken,
+			MinEventID:    minEventID,
+			MaxEventID:    maxEventID,
+			PageSize:      historyPageSize,
+			NextPageToken: pageToken,
+			ShardID:       common.IntPtr(shardID),
+		})
+		if err != nil {
+			if _, ok := err.(*types.EntityNotExistsError); ok {
+				// the range has no events, e.g. a branch forked without new events
+				return nil
+			}
+			return err
+		}
+		for _, batch := range resp.History {
+			if len(batch.Events) == 0 {
+				continue
+			}
+			transactionID := batch.Events[len(batch.Events)-1].TaskID
+			if transactionID <= 0 {
+				transactionID = 1
+			}
+			if _, err := c.target.GetHistoryManager().AppendHistoryNodes(ctx, &persistence.AppendHistoryNodesRequest{
+				IsNewBranch:   isNewBranch,
+				Info:          info,
+				BranchToken:   branchToken,
+				Events:        batch.Events,
+				TransactionID: transactionID,
+				Encoding:      common.EncodingTypeThriftRW,
+				ShardID:       common.IntPtr(shardID),
+			}); err != nil {
+				return err
+			}
+			isNewBranch = false
+		}
+		pageToken = resp.NextPageToken
+		if len(pageToken) == 0 {
+			return nil
+		}
+	}
+}
+
+func (c *copier) logExecutionError(err error, shardID int, domainID string, execution types.WorkflowExecution) {
+	c.logger.Warn("failed to copy workflow execution",
+		tag.ShardID(shardID),
+		tag.WorkflowDomainID(domainID),
+		tag.WorkflowID(execution.WorkflowID),
+		tag.WorkflowRunID(execution.RunID),
+		tag.Error(err),
+	)
+}
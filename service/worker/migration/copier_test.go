@@ -0,0 +1,266 @@
+// Copyright (c) 2017-2020 Uber Technologies Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package migration
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/pborman/uuid"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/uber/cadence/common"
+	"github.com/uber/cadence/common/config"
+	"github.com/uber/cadence/common/dynamicconfig"
+	"github.com/uber/cadence/common/log/loggerimpl"
+	"github.com/uber/cadence/common/persistence"
+	persistenceClient "github.com/uber/cadence/common/persistence/client"
+	_ "github.com/uber/cadence/common/persistence/nosql/nosqlplugin/memory" // needed to load memory plugin
+	"github.com/uber/cadence/common/service"
+	"github.com/uber/cadence/common/types"
+)
+
+const (
+	testShardID     = 1
+	testClusterName = "active"
+)
+
+type copierSuite struct {
+	suite.Suite
+	source persistenceClient.Bean
+	target persistenceClient.Bean
+	copier *copier
+}
+
+func TestCopierSuite(t *testing.T) {
+	suite.Run(t, new(copierSuite))
+}
+
+func (s *copierSuite) SetupTest() {
+	s.source = s.newBean("source_" + uuid.New()[:8])
+	s.target = s.newBean("target_" + uuid.New()[:8])
+	s.copier = newCopier(s.source, s.target, nil, loggerimpl.NewNopLogger())
+	s.NoError(s.source.GetShardManager().CreateShard(context.Background(), &persistence.CreateShardRequest{
+		ShardInfo: &persistence.ShardInfo{
+			ShardID:                 testShardID,
+			RangeID:                 10,
+			ClusterTimerAckLevel:    map[string]time.Time{testClusterName: {}},
+			ClusterTransferAckLevel: map[string]int64{testClusterName: 0},
+		},
+	}))
+}
+
+func (s *copierSuite) TearDownTest() {
+	s.source.Close()
+	s.target.Close()
+}
+
+func (s *copierSuite) newBean(keyspace string) persistenceClient.Bean {
+	cfg := config.Persistence{
+		DefaultStore:         "default",
+		NumHistoryShards:     4,
+		TransactionSizeLimit: dynamicconfig.GetIntPropertyFn(common.DefaultTransactionSizeLimit),
+		ErrorInjectionRate:   dynamicconfig.GetFloatPropertyFn(0),
+		DataStores: map[string]config.DataStore{
+			"default": {
+				NoSQL: &config.NoSQL{
+					PluginName: "memory",
+					Keyspace:   keyspace,
+				},
+			},
+		},
+	}
+	factory := persistenceClient.NewFactory(&cfg, nil, testClusterName, nil, loggerimpl.NewNopLogger())
+	bean, err := persistenceClient.NewBeanFromFactory(factory, &persistenceClient.Params{PersistenceConfig: cfg}, &service.Config{})
+	s.Require().NoError(err)
+	return bean
+}
+
+func (s *copierSuite) TestCopyDomains() {
+	ctx := context.Background()
+	resp, err := s.source.GetDomainManager().CreateDomain(ctx, &persistence.CreateDomainRequest{
+		Info:   &persistence.DomainInfo{ID: uuid.New(), Name: "test-domain", Status: persistence.DomainStatusRegistered},
+		Config: &persistence.DomainConfig{Retention: 1},
+		ReplicationConfig: &persistence.DomainReplicationConfig{
+			ActiveClusterName: testClusterName,
+			Clusters:          []*persistence.ClusterReplicationConfig{{ClusterName: testClusterName}},
+		},
+		ConfigVersion:   2,
+		FailoverVersion: common.EmptyVersion,
+		LastUpdatedTime: time.Now().UnixNano(),
+	})
+	s.NoError(err)
+
+	copied, err := s.copier.copyDomains(ctx)
+	s.NoError(err)
+	s.Equal(1, copied)
+	domain, err := s.target.GetDomainManager().GetDomain(ctx, &persistence.GetDomainRequest{ID: resp.ID})
+	s.NoError(err)
+	s.Equal("test-domain", domain.Info.Name)
+	s.Equal(int64(2), domain.ConfigVersion)
+
+	// copying again is a no-op
+	copied, err = s.copier.copyDomains(ctx)
+	s.NoError(err)
+	s.Equal(0, copied)
+}
+
+func (s *copierSuite) TestCopyShard() {
+	ctx := context.Background()
+	rangeID, err := s.copier.copyShard(ctx, testShardID)
+	s.NoError(err)
+	s.Equal(int64(10), rangeID)
+
+	s.NoError(s.source.GetShardManager().UpdateShard(ctx, &persistence.UpdateShardRequest{
+		ShardInfo:       &persistence.ShardInfo{ShardID: testShardID, RangeID: 11},
+		PreviousRangeID: 10,
+	}))
+	rangeID, err = s.copier.copyShard(ctx, testShardID)
+	s.NoError(err)
+	s.Equal(int64(11), rangeID)
+	shard, err := s.target.GetShardManager().GetShard(ctx, &persistence.GetShardRequest{ShardID: testShardID})
+	s.NoError(err)
+	s.Equal(int64(11), shard.ShardInfo.RangeID)
+}
+
+func (s *copierSuite) TestCopyExecution() {
+	ctx := context.Background()
+	domainID := uuid.New()
+	execution := types.WorkflowExecution{WorkflowID: "test-workflow", RunID: uuid.New()}
+	s.createExecution(ctx, domainID, execution)
+	rangeID, err := s.copier.copyShard(ctx, testShardID)
+	s.NoError(err)
+
+	result, err := s.copier.copyExecution(ctx, testShardID, rangeID, domainID, execution)
+	s.NoError(err)
+	s.Equal(executionCopyResultCreated, result)
+
+	targetExecutions, err := s.target.GetExecutionManager(testShardID)
+	s.NoError(err)
+	resp, err := targetExecutions.GetWorkflowExecution(ctx, &persistence.GetWorkflowExecutionRequest{
+		DomainID:  domainID,
+		Execution: execution,
+	})
+	s.NoError(err)
+	s.Equal(int64(3), resp.State.ExecutionInfo.NextEventID)
+	s.Equal(persistence.WorkflowStateRunning, resp.State.ExecutionInfo.State)
+	current, err := targetExecutions.GetCurrentExecution(ctx, &persistence.GetCurrentExecutionRequest{
+		DomainID:   domainID,
+		WorkflowID: execution.WorkflowID,
+	})
+	s.NoError(err)
+	s.Equal(execution.RunID, current.RunID)
+
+	history, err := s.target.GetHistoryManager().ReadHistoryBranch(ctx, &persistence.ReadHistoryBranchRequest{
+		BranchToken: resp.State.ExecutionInfo.BranchToken,
+		MinEventID:  common.FirstEventID,
+		MaxEventID:  common.EndEventID,
+		PageSize:    historyPageSize,
+		ShardID:     common.IntPtr(testShardID),
+	})
+	s.NoError(err)
+	s.Len(history.HistoryEvents, 2)
+
+	// the execution is up to date in the target store
+	result, err = s.copier.copyExecution(ctx, testShardID, rangeID, domainID, execution)
+	s.NoError(err)
+	s.Equal(executionCopyResultSkipped, result)
+}
+
+func (s *copierSuite) TestCopyExecution_NotExists() {
+	result, err := s.copier.copyExecution(
+		context.Background(),
+		testShardID,
+		10,
+		uuid.New(),
+		types.WorkflowExecution{WorkflowID: "test-workflow", RunID: uuid.New()},
+	)
+	s.NoError(err)
+	s.Equal(executionCopyResultSkipped, result)
+}
+
+func (s *copierSuite) createExecution(ctx context.Context, domainID string, execution types.WorkflowExecution) {
+	branchToken, err := persistence.NewHistoryBranchToken(execution.RunID)
+	s.Require().NoError(err)
+	_, err = s.source.GetHistoryManager().AppendHistoryNodes(ctx, &persistence.AppendHistoryNodesRequest{
+		IsNewBranch: true,
+		Info:        persistence.BuildHistoryGarbageCleanupInfo(domainID, execution.WorkflowID, execution.RunID),
+		BranchToken: branchToken,
+		Events: []*types.HistoryEvent{
+			{
+				EventID:                                 1,
+				Version:                                 common.EmptyVersion,
+				TaskID:                                  1,
+				EventType:                               types.EventTypeWorkflowExecutionStarted.Ptr(),
+				WorkflowExecutionStartedEventAttributes: &types.WorkflowExecutionStartedEventAttributes{},
+			},
+			{
+				EventID:                              2,
+				Version:                              common.EmptyVersion,
+				TaskID:                               1,
+				EventType:                            types.EventTypeDecisionTaskScheduled.Ptr(),
+				DecisionTaskScheduledEventAttributes: &types.DecisionTaskScheduledEventAttributes{},
+			},
+		},
+		TransactionID: 1,
+		Encoding:      common.EncodingTypeThriftRW,
+		ShardID:       common.IntPtr(testShardID),
+	})
+	s.Require().NoError(err)
+
+	sourceExecutions, err := s.source.GetExecutionManager(testShardID)
+	s.Require().NoError(err)
+	now := time.Now()
+	_, err = sourceExecutions.CreateWorkflowExecution(ctx, &persistence.CreateWorkflowExecutionRequest{
+		RangeID: 10,
+		Mode:    persistence.CreateWorkflowModeBrandNew,
+		NewWorkflowSnapshot: persistence.WorkflowSnapshot{
+			ExecutionInfo: &persistence.WorkflowExecutionInfo{
+				CreateRequestID:             uuid.New(),
+				DomainID:                    domainID,
+				WorkflowID:                  execution.WorkflowID,
+				RunID:                       execution.RunID,
+				TaskList:                    "test-tasklist",
+				WorkflowTypeName:            "test-workflow-type",
+				WorkflowTimeout:             10,
+				DecisionStartToCloseTimeout: 5,
+				State:                       persistence.WorkflowStateRunning,
+				CloseStatus:                 persistence.WorkflowCloseStatusNone,
+				LastFirstEventID:            common.FirstEventID,
+				NextEventID:                 3,
+				LastProcessedEvent:          common.EmptyEventID,
+				LastUpdatedTimestamp:        now,
+				StartTimestamp:              now,
+				DecisionScheduleID:          2,
+				DecisionStartedID:           common.EmptyEventID,
+				DecisionTimeout:             1,
+				BranchToken:                 branchToken,
+			},
+			ExecutionStats: &persistence.ExecutionStats{},
+			VersionHistories: persistence.NewVersionHistories(persistence.NewVersionHistory(
+				branchToken,
+				[]*persistence.VersionHistoryItem{persistence.NewVersionHistoryItem(2, common.EmptyVersion)},
+			)),
+		},
+	})
+	s.Require().NoError(err)
+}
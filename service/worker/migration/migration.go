@@ -0,0 +1,148 @@
+// Copyright (c) 2017-2020 Uber Technologies Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package migration
+
+import (
+	"context"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/uber-go/tally"
+	"go.uber.org/cadence/activity"
+	"go.uber.org/cadence/worker"
+	"go.uber.org/cadence/workflow"
+
+	"github.com/uber/cadence/common"
+	"github.com/uber/cadence/common/config"
+	"github.com/uber/cadence/common/log"
+	"github.com/uber/cadence/common/log/tag"
+	persistenceClient "github.com/uber/cadence/common/persistence/client"
+	"github.com/uber/cadence/common/resource"
+	"github.com/uber/cadence/common/service"
+)
+
+const (
+	// TaskListName is the task list used by the persistence migration workflows
+	TaskListName = "cadence-sys-migration-tasklist"
+
+	// BackfillWorkflowTypeName is the workflow type name of the backfill workflow
+	BackfillWorkflowTypeName = "cadence-sys-migration-backfill-workflow"
+	// ShardBackfillWorkflowTypeName is the workflow type name of the per shard backfill workflow
+	ShardBackfillWorkflowTypeName = "cadence-sys-migration-shard-backfill-workflow"
+
+	copyDomainsActivityName      = "cadence-sys-migration-copy-domains-activity"
+	getShardCountActivityName    = "cadence-sys-migration-get-shard-count-activity"
+	copyShardActivityName        = "cadence-sys-migration-copy-shard-activity"
+	copyExecutionsActivityName   = "cadence-sys-migration-copy-executions-activity"
+	verifyExecutionsActivityName = "cadence-sys-migration-verify-executions-activity"
+)
+
+type (
+	contextKey string
+
+	// BootstrapParams contains the set of params needed to bootstrap
+	// the persistence migrator
+	BootstrapParams struct {
+		// Resource is the resource of the worker service, its persistence managers are used as the migration source
+		Resource resource.Resource
+		// PersistenceConfig is the persistence config containing the migration target store
+		PersistenceConfig config.Persistence
+		// TallyScope is an instance of tally metrics scope
+		TallyScope tally.Scope
+	}
+
+	// Migrator hosts the workflows copying the data of the default store into the migration target store
+	Migrator struct {
+		resource          resource.Resource
+		persistenceConfig config.Persistence
+		tallyScope        tally.Scope
+		logger            log.Logger
+		target            persistenceClient.Bean
+		worker            worker.Worker
+	}
+)
+
+const migratorContextKey contextKey = "migratorContext"
+
+// New returns a new instance of Migrator
+func New(params *BootstrapParams) *Migrator {
+	return &Migrator{
+		resource:          params.Resource,
+		persistenceConfig: params.PersistenceConfig,
+		tallyScope:        params.TallyScope,
+		logger:            params.Resource.GetLogger().WithTags(tag.ComponentPersistenceMigrator),
+	}
+}
+
+// Start starts the worker
+func (m *Migrator) Start() error {
+	target, err := m.newTargetBean()
+	if err != nil {
+		return err
+	}
+	m.target = target
+
+	ctx := context.WithValue(context.Background(), migratorContextKey, m)
+	workerOpts := worker.Options{
+		MetricsScope:              m.tallyScope,
+		BackgroundActivityContext: ctx,
+		Tracer:                    opentracing.GlobalTracer(),
+	}
+	migrationWorker := worker.New(m.resource.GetSDKClient(), common.SystemLocalDomainName, TaskListName, workerOpts)
+	migrationWorker.RegisterWorkflowWithOptions(BackfillWorkflow, workflow.RegisterOptions{Name: BackfillWorkflowTypeName})
+	migrationWorker.RegisterWorkflowWithOptions(ShardBackfillWorkflow, workflow.RegisterOptions{Name: ShardBackfillWorkflowTypeName})
+	migrationWorker.RegisterActivityWithOptions(CopyDomainsActivity, activity.RegisterOptions{Name: copyDomainsActivityName})
+	migrationWorker.RegisterActivityWithOptions(GetShardCountActivity, activity.RegisterOptions{Name: getShardCountActivityName})
+	migrationWorker.RegisterActivityWithOptions(CopyShardActivity, activity.RegisterOptions{Name: copyShardActivityName})
+	migrationWorker.RegisterActivityWithOptions(CopyExecutionsActivity, activity.RegisterOptions{Name: copyExecutionsActivityName})
+	migrationWorker.RegisterActivityWithOptions(VerifyExecutionsActivity, activity.RegisterOptions{Name: verifyExecutionsActivityName})
+	m.worker = migrationWorker
+	return migrationWorker.Start()
+}
+
+// Stop stops the worker
+func (m *Migrator) Stop() {
+	if m.worker != nil {
+		m.worker.Stop()
+	}
+	if m.target != nil {
+		m.target.Close()
+	}
+}
+
+// newTargetBean creates persistence managers which access the migration target store directly
+func (m *Migrator) newTargetBean() (persistenceClient.Bean, error) {
+	targetConfig := m.persistenceConfig
+	targetConfig.DefaultStore = m.persistenceConfig.MigrationTargetStore
+	targetConfig.MigrationTargetStore = ""
+	targetConfig.VisibilityStore = ""
+	targetConfig.AdvancedVisibilityStore = ""
+	factory := persistenceClient.NewFactory(
+		&targetConfig,
+		nil,
+		m.resource.GetClusterMetadata().GetCurrentClusterName(),
+		m.resource.GetMetricsClient(),
+		m.logger,
+	)
+	return persistenceClient.NewBeanFromFactory(factory, &persistenceClient.Params{
+		PersistenceConfig: targetConfig,
+		MetricsClient:     m.resource.GetMetricsClient(),
+	}, &service.Config{})
+}
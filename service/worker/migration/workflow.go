@@ -0,0 +1,224 @@
+// Copyright (c) 2017-2020 Uber Technologies Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package migration
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"go.uber.org/cadence"
+	"go.uber.org/cadence/workflow"
+)
+
+const (
+	defaultConcurrency = 8
+	defaultPageSize    = 100
+	// maxPagesPerRun is the number of pages a shard backfill workflow processes before continuing as new
+	maxPagesPerRun = 100
+
+	infiniteDuration = 20 * 365 * 24 * time.Hour
+)
+
+var (
+	activityOptions = workflow.ActivityOptions{
+		ScheduleToStartTimeout: time.Minute,
+		StartToCloseTimeout:    10 * time.Minute,
+		HeartbeatTimeout:       time.Minute,
+		RetryPolicy: &cadence.RetryPolicy{
+			InitialInterval:          time.Second,
+			BackoffCoefficient:       2,
+			MaximumInterval:          time.Minute,
+			ExpirationInterval:       time.Hour,
+			NonRetriableErrorReasons: []string{errShadowWritesDisabledReason},
+		},
+	}
+
+	errInvalidShardID = errors.New("shard ID must not be negative")
+)
+
+type (
+	// BackfillParams is the input of BackfillWorkflow
+	BackfillParams struct {
+		// NumShards is the number of history shards to backfill, defaults to all the shards of the cluster
+		NumShards int
+		// Concurrency is the number of shards backfilled in parallel
+		Concurrency int
+		// PageSize is the number of executions copied by a single activity
+		PageSize int
+		// Verify checks the copied executions after a shard has been backfilled
+		Verify bool
+	}
+
+	// BackfillReport is the result of BackfillWorkflow
+	BackfillReport struct {
+		DomainsCopied int
+		Shards        []ShardBackfillReport
+		FailedShards  []int
+	}
+
+	// ShardBackfillParams is the input of ShardBackfillWorkflow
+	ShardBackfillParams struct {
+		ShardID  int
+		PageSize int
+		Verify   bool
+		// the fields below are set when continuing as new
+		Verifying bool
+		PageToken []byte
+		Report    ShardBackfillReport
+	}
+
+	// ShardBackfillReport is the result of ShardBackfillWorkflow
+	ShardBackfillReport struct {
+		ShardID             int
+		ExecutionsCreated   int64
+		ExecutionsUpdated   int64
+		ExecutionsSkipped   int64
+		ExecutionsFailed    int64
+		ExecutionsHealthy   int64
+		ExecutionsCorrupted int64
+		VerificationsFailed int64
+	}
+
+	shardBackfillResult struct {
+		shardID int
+		report  ShardBackfillReport
+		err     error
+	}
+)
+
+// BackfillWorkflow copies the domains and then backfills every shard using a child workflow per shard
+func BackfillWorkflow(ctx workflow.Context, params BackfillParams) (*BackfillReport, error) {
+	ctx = workflow.WithActivityOptions(ctx, activityOptions)
+	report := &BackfillReport{}
+	if err := workflow.ExecuteActivity(ctx, copyDomainsActivityName).Get(ctx, &report.DomainsCopied); err != nil {
+		return nil, err
+	}
+	numShards := params.NumShards
+	if numShards <= 0 {
+		if err := workflow.ExecuteActivity(ctx, getShardCountActivityName).Get(ctx, &numShards); err != nil {
+			return nil, err
+		}
+	}
+	concurrency := params.Concurrency
+	if concurrency <= 0 {
+		concurrency = defaultConcurrency
+	}
+	if concurrency > numShards {
+		concurrency = numShards
+	}
+	pageSize := params.PageSize
+	if pageSize <= 0 {
+		pageSize = defaultPageSize
+	}
+
+	parentID := workflow.GetInfo(ctx).WorkflowExecution.ID
+	resultCh := workflow.NewChannel(ctx)
+	for i := 0; i < concurrency; i++ {
+		idx := i
+		workflow.Go(ctx, func(ctx workflow.Context) {
+			for shardID := idx; shardID < numShards; shardID += concurrency {
+				childCtx := workflow.WithChildOptions(ctx, workflow.ChildWorkflowOptions{
+					WorkflowID:                   fmt.Sprintf("%v-shard-%v", parentID, shardID),
+					TaskList:                     TaskListName,
+					ExecutionStartToCloseTimeout: infiniteDuration,
+				})
+				result := shardBackfillResult{shardID: shardID}
+				result.err = workflow.ExecuteChildWorkflow(childCtx, ShardBackfillWorkflowTypeName, ShardBackfillParams{
+					ShardID:  shardID,
+					PageSize: pageSize,
+					Verify:   params.Verify,
+				}).Get(ctx, &result.report)
+				resultCh.Send(ctx, result)
+			}
+		})
+	}
+
+	for i := 0; i < numShards; i++ {
+		var result shardBackfillResult
+		resultCh.Receive(ctx, &result)
+		if result.err != nil {
+			workflow.GetLogger(ctx).Error(fmt.Sprintf("failed to backfill shard %v: %v", result.shardID, result.err))
+			report.FailedShards = append(report.FailedShards, result.shardID)
+			continue
+		}
+		report.Shards = append(report.Shards, result.report)
+	}
+	return report, nil
+}
+
+// ShardBackfillWorkflow copies the shard info and all the executions of a shard page by page,
+// optionally verifying them afterwards. It continues as new periodically to bound its history size.
+func ShardBackfillWorkflow(ctx workflow.Context, params ShardBackfillParams) (*ShardBackfillReport, error) {
+	if params.ShardID < 0 {
+		return nil, errInvalidShardID
+	}
+	ctx = workflow.WithActivityOptions(ctx, activityOptions)
+	report := params.Report
+	report.ShardID = params.ShardID
+	if !params.Verifying && params.PageToken == nil {
+		if err := workflow.ExecuteActivity(ctx, copyShardActivityName, params.ShardID).Get(ctx, nil); err != nil {
+			return nil, err
+		}
+	}
+
+	pageToken := params.PageToken
+	verifying := params.Verifying
+	for page := 0; page < maxPagesPerRun; page++ {
+		activityParams := CopyExecutionsActivityParams{
+			ShardID:   params.ShardID,
+			PageSize:  params.PageSize,
+			PageToken: pageToken,
+		}
+		if verifying {
+			var result VerifyExecutionsActivityResult
+			if err := workflow.ExecuteActivity(ctx, verifyExecutionsActivityName, activityParams).Get(ctx, &result); err != nil {
+				return nil, err
+			}
+			report.ExecutionsHealthy += result.Healthy
+			report.ExecutionsCorrupted += result.Corrupted
+			report.VerificationsFailed += result.Failed
+			pageToken = result.NextPageToken
+		} else {
+			var result CopyExecutionsActivityResult
+			if err := workflow.ExecuteActivity(ctx, copyExecutionsActivityName, activityParams).Get(ctx, &result); err != nil {
+				return nil, err
+			}
+			report.ExecutionsCreated += result.Created
+			report.ExecutionsUpdated += result.Updated
+			report.ExecutionsSkipped += result.Skipped
+			report.ExecutionsFailed += result.Failed
+			pageToken = result.NextPageToken
+		}
+
+		if len(pageToken) == 0 {
+			if verifying || !params.Verify {
+				return &report, nil
+			}
+			verifying = true
+		}
+	}
+
+	params.Verifying = verifying
+	params.PageToken = pageToken
+	params.Report = report
+	return nil, workflow.NewContinueAsNewError(ctx, ShardBackfillWorkflowTypeName, params)
+}
@@ -0,0 +1,132 @@
+// Copyright (c) 2017-2020 Uber Technologies Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package migration
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/cadence"
+	"go.uber.org/cadence/activity"
+	"go.uber.org/cadence/testsuite"
+	"go.uber.org/cadence/workflow"
+)
+
+type workflowTestSuite struct {
+	suite.Suite
+	testsuite.WorkflowTestSuite
+	workflowEnv *testsuite.TestWorkflowEnvironment
+}
+
+func TestWorkflowTestSuite(t *testing.T) {
+	suite.Run(t, new(workflowTestSuite))
+}
+
+func (s *workflowTestSuite) SetupTest() {
+	s.workflowEnv = s.NewTestWorkflowEnvironment()
+	s.workflowEnv.RegisterWorkflowWithOptions(BackfillWorkflow, workflow.RegisterOptions{Name: BackfillWorkflowTypeName})
+	s.workflowEnv.RegisterWorkflowWithOptions(ShardBackfillWorkflow, workflow.RegisterOptions{Name: ShardBackfillWorkflowTypeName})
+	s.workflowEnv.RegisterActivityWithOptions(CopyDomainsActivity, activity.RegisterOptions{Name: copyDomainsActivityName})
+	s.workflowEnv.RegisterActivityWithOptions(GetShardCountActivity, activity.RegisterOptions{Name: getShardCountActivityName})
+	s.workflowEnv.RegisterActivityWithOptions(CopyShardActivity, activity.RegisterOptions{Name: copyShardActivityName})
+	s.workflowEnv.RegisterActivityWithOptions(CopyExecutionsActivity, activity.RegisterOptions{Name: copyExecutionsActivityName})
+	s.workflowEnv.RegisterActivityWithOptions(VerifyExecutionsActivity, activity.RegisterOptions{Name: verifyExecutionsActivityName})
+}
+
+func (s *workflowTestSuite) TearDownTest() {
+	s.workflowEnv.AssertExpectations(s.T())
+}
+
+func (s *workflowTestSuite) TestBackfillWorkflow() {
+	firstPage := mock.MatchedBy(func(params CopyExecutionsActivityParams) bool { return params.PageToken == nil })
+	secondPage := mock.MatchedBy(func(params CopyExecutionsActivityParams) bool { return params.PageToken != nil })
+	s.workflowEnv.OnActivity(copyDomainsActivityName, mock.Anything).Return(2, nil).Once()
+	s.workflowEnv.OnActivity(getShardCountActivityName, mock.Anything).Return(3, nil).Once()
+	s.workflowEnv.OnActivity(copyShardActivityName, mock.Anything, mock.Anything).Return(nil).Times(3)
+	s.workflowEnv.OnActivity(copyExecutionsActivityName, mock.Anything, firstPage).
+		Return(&CopyExecutionsActivityResult{NextPageToken: []byte("token"), Created: 2, Skipped: 1}, nil).Times(3)
+	s.workflowEnv.OnActivity(copyExecutionsActivityName, mock.Anything, secondPage).
+		Return(&CopyExecutionsActivityResult{Updated: 1, Failed: 1}, nil).Times(3)
+	s.workflowEnv.OnActivity(verifyExecutionsActivityName, mock.Anything, firstPage).
+		Return(&VerifyExecutionsActivityResult{Healthy: 4, Corrupted: 1}, nil).Times(3)
+
+	s.workflowEnv.ExecuteWorkflow(BackfillWorkflowTypeName, BackfillParams{Concurrency: 2, Verify: true})
+	s.True(s.workflowEnv.IsWorkflowCompleted())
+	s.NoError(s.workflowEnv.GetWorkflowError())
+	var report BackfillReport
+	s.NoError(s.workflowEnv.GetWorkflowResult(&report))
+	s.Equal(2, report.DomainsCopied)
+	s.Empty(report.FailedShards)
+	s.Len(report.Shards, 3)
+	for _, shardReport := range report.Shards {
+		s.Equal(int64(2), shardReport.ExecutionsCreated)
+		s.Equal(int64(1), shardReport.ExecutionsUpdated)
+		s.Equal(int64(1), shardReport.ExecutionsSkipped)
+		s.Equal(int64(1), shardReport.ExecutionsFailed)
+		s.Equal(int64(4), shardReport.ExecutionsHealthy)
+		s.Equal(int64(1), shardReport.ExecutionsCorrupted)
+	}
+}
+
+func (s *workflowTestSuite) TestBackfillWorkflow_ShadowWritesDisabled() {
+	s.workflowEnv.OnActivity(copyDomainsActivityName, mock.Anything).
+		Return(0, cadence.NewCustomError(errShadowWritesDisabledReason)).Once()
+
+	s.workflowEnv.ExecuteWorkflow(BackfillWorkflowTypeName, BackfillParams{NumShards: 1})
+	s.True(s.workflowEnv.IsWorkflowCompleted())
+	s.Error(s.workflowEnv.GetWorkflowError())
+}
+
+func (s *workflowTestSuite) TestBackfillWorkflow_ShardFailed() {
+	s.workflowEnv.OnActivity(copyDomainsActivityName, mock.Anything).Return(0, nil).Once()
+	s.workflowEnv.OnActivity(copyShardActivityName, mock.Anything, 0).Return(nil).Once()
+	s.workflowEnv.OnActivity(copyShardActivityName, mock.Anything, 1).
+		Return(cadence.NewCustomError("test-error"))
+	s.workflowEnv.OnActivity(copyExecutionsActivityName, mock.Anything, mock.Anything).
+		Return(&CopyExecutionsActivityResult{Created: 1}, nil).Once()
+
+	s.workflowEnv.ExecuteWorkflow(BackfillWorkflowTypeName, BackfillParams{NumShards: 2})
+	s.True(s.workflowEnv.IsWorkflowCompleted())
+	s.NoError(s.workflowEnv.GetWorkflowError())
+	var report BackfillReport
+	s.NoError(s.workflowEnv.GetWorkflowResult(&report))
+	s.Equal([]int{1}, report.FailedShards)
+	s.Len(report.Shards, 1)
+	s.Equal(0, report.Shards[0].ShardID)
+}
+
+func (s *workflowTestSuite) TestShardBackfillWorkflow_ContinueAsNew() {
+	s.workflowEnv.OnActivity(copyShardActivityName, mock.Anything, mock.Anything).Return(nil).Once()
+	s.workflowEnv.OnActivity(copyExecutionsActivityName, mock.Anything, mock.Anything).
+		Return(&CopyExecutionsActivityResult{NextPageToken: []byte("token"), Created: 1}, nil).Times(maxPagesPerRun)
+
+	s.workflowEnv.ExecuteWorkflow(ShardBackfillWorkflowTypeName, ShardBackfillParams{ShardID: 1, PageSize: 10})
+	s.True(s.workflowEnv.IsWorkflowCompleted())
+	_, ok := s.workflowEnv.GetWorkflowError().(*workflow.ContinueAsNewError)
+	s.True(ok)
+}
+
+func (s *workflowTestSuite) TestShardBackfillWorkflow_InvalidShardID() {
+	s.workflowEnv.ExecuteWorkflow(ShardBackfillWorkflowTypeName, ShardBackfillParams{ShardID: -1})
+	s.True(s.workflowEnv.IsWorkflowCompleted())
+	s.Error(s.workflowEnv.GetWorkflowError())
+}
@@ -0,0 +1,144 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package partition
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/multierr"
+
+	"github.com/uber/cadence/common/clock"
+	"github.com/uber/cadence/common/log"
+	"github.com/uber/cadence/common/log/tag"
+	"github.com/uber/cadence/common/metrics"
+	"github.com/uber/cadence/common/persistence/sql/sqlplugin"
+)
+
+const (
+	// createAheadBuckets is the number of buckets after the current one whose partitions are created ahead of time,
+	// so that new rows never fall through to the default partition while the job isn't running
+	createAheadBuckets = 3
+)
+
+type (
+	// Manager maintains the partitions of the tables created by the SQL partitioned schema.
+	// On every run, for each DB shard and partitioned table it
+	//  - creates the partitions of the current bucket and the next createAheadBuckets buckets
+	//  - drops the partitions of the expired buckets which are not in use anymore
+	// A partition of a past bucket is not in use once all of its executions are deleted and all of
+	// the history trees referred by its history nodes are deleted, i.e. once every workflow
+	// that wrote into it has passed its retention. The legacy partition is never dropped.
+	Manager struct {
+		admin       sqlplugin.PartitionAdmin
+		numDBShards int
+		bucketSize  time.Duration
+		timeSource  clock.TimeSource
+		metrics     metrics.Scope
+		logger      log.Logger
+	}
+)
+
+// NewManager returns a new instance of partition manager
+func NewManager(
+	admin sqlplugin.PartitionAdmin,
+	numDBShards int,
+	bucketSize time.Duration,
+	timeSource clock.TimeSource,
+	metricsClient metrics.Client,
+	logger log.Logger,
+) *Manager {
+	if numDBShards < 1 {
+		numDBShards = 1
+	}
+	return &Manager{
+		admin:       admin,
+		numDBShards: numDBShards,
+		bucketSize:  bucketSize,
+		timeSource:  timeSource,
+		metrics:     metricsClient.Scope(metrics.PartitionRetentionScope),
+		logger:      logger.WithTags(tag.ComponentPartitionRetention),
+	}
+}
+
+// Run runs one iteration over all the partitioned tables of all DB shards,
+// failures of a table don't stop the others from being processed
+func (m *Manager) Run(ctx context.Context) error {
+	currentBucket := sqlplugin.GetPartitionBucket(m.timeSource.Now(), m.bucketSize)
+	var errs error
+	for dbShardID := 0; dbShardID < m.numDBShards; dbShardID++ {
+		for _, table := range sqlplugin.PartitionedTables {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			if err := m.maintainTable(ctx, dbShardID, table, currentBucket); err != nil {
+				m.metrics.IncCounter(metrics.PartitionRetentionErrorCount)
+				m.logger.Error("failed to maintain partitions",
+					tag.ShardID(dbShardID), tag.StoreTable(table), tag.Error(err))
+				errs = multierr.Append(errs, err)
+			}
+		}
+	}
+	return errs
+}
+
+func (m *Manager) maintainTable(ctx context.Context, dbShardID int, table string, currentBucket int64) error {
+	buckets, err := m.admin.SelectPartitionBuckets(ctx, dbShardID, table)
+	if err != nil {
+		return err
+	}
+
+	// partitions can only be appended after the existing ones
+	nextBucket := currentBucket
+	if len(buckets) > 0 && buckets[len(buckets)-1] >= nextBucket {
+		nextBucket = buckets[len(buckets)-1] + 1
+	}
+	for bucket := nextBucket; bucket <= currentBucket+createAheadBuckets; bucket++ {
+		if err := m.admin.CreatePartition(ctx, dbShardID, table, bucket); err != nil {
+			return err
+		}
+		m.metrics.IncCounter(metrics.PartitionRetentionCreatedCount)
+		m.logger.Info("created partition",
+			tag.ShardID(dbShardID), tag.StoreTable(table), tag.StorePartitionBucket(bucket))
+	}
+
+	for _, bucket := range buckets {
+		if bucket >= currentBucket || bucket == sqlplugin.LegacyPartitionBucket {
+			continue
+		}
+		inUse, err := m.admin.IsPartitionInUse(ctx, dbShardID, table, bucket)
+		if err != nil {
+			return err
+		}
+		if inUse {
+			m.metrics.IncCounter(metrics.PartitionRetentionSkipCount)
+			continue
+		}
+		if err := m.admin.DropPartition(ctx, dbShardID, table, bucket); err != nil {
+			return err
+		}
+		m.metrics.IncCounter(metrics.PartitionRetentionDroppedCount)
+		m.logger.Info("dropped partition",
+			tag.ShardID(dbShardID), tag.StoreTable(table), tag.StorePartitionBucket(bucket),
+			tag.Timestamp(sqlplugin.GetPartitionBucketStartTime(bucket, m.bucketSize)))
+	}
+	return nil
+}
@@ -0,0 +1,140 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package partition
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/uber/cadence/common/clock"
+	"github.com/uber/cadence/common/log"
+	"github.com/uber/cadence/common/metrics"
+	"github.com/uber/cadence/common/persistence/sql/sqlplugin"
+)
+
+type (
+	managerSuite struct {
+		suite.Suite
+		admin      *fakePartitionAdmin
+		timeSource *clock.EventTimeSource
+		manager    *Manager
+	}
+
+	fakePartitionAdmin struct {
+		// table -> buckets of its partitions
+		partitions map[string][]int64
+		// table -> buckets of the partitions in use
+		inUse     map[string]map[int64]bool
+		createErr error
+	}
+)
+
+const testBucketSize = 24 * time.Hour
+
+func TestManagerSuite(t *testing.T) {
+	suite.Run(t, new(managerSuite))
+}
+
+func (s *managerSuite) SetupTest() {
+	s.admin = &fakePartitionAdmin{
+		partitions: map[string][]int64{},
+		inUse:      map[string]map[int64]bool{},
+	}
+	s.timeSource = clock.NewEventTimeSource().Update(time.Unix(100*int64(testBucketSize/time.Second)+3600, 0))
+	s.manager = NewManager(s.admin, 1, testBucketSize, s.timeSource, metrics.NewNoopMetricsClient(), log.NewNoop())
+}
+
+func (s *managerSuite) TestRun_CreatesUpcomingPartitions() {
+	s.NoError(s.manager.Run(context.Background()))
+	for _, table := range sqlplugin.PartitionedTables {
+		s.Equal([]int64{100, 101, 102, 103}, s.admin.partitions[table])
+	}
+
+	// nothing changes within the same bucket
+	s.NoError(s.manager.Run(context.Background()))
+	s.Equal([]int64{100, 101, 102, 103}, s.admin.partitions[sqlplugin.PartitionedTableHistoryNode])
+
+	// the partition of the previous bucket is dropped once it's not in use
+	s.admin.inUse[sqlplugin.PartitionedTableHistoryNode] = map[int64]bool{100: true}
+	s.timeSource.Update(s.timeSource.Now().Add(testBucketSize))
+	s.NoError(s.manager.Run(context.Background()))
+	s.Equal([]int64{100, 101, 102, 103, 104}, s.admin.partitions[sqlplugin.PartitionedTableHistoryNode])
+	s.Equal([]int64{101, 102, 103, 104}, s.admin.partitions[sqlplugin.PartitionedTableExecutions])
+}
+
+func (s *managerSuite) TestRun_DropsExpiredUnusedPartitions() {
+	for _, table := range sqlplugin.PartitionedTables {
+		s.admin.partitions[table] = []int64{sqlplugin.LegacyPartitionBucket, 97, 98, 99, 100, 101, 102, 103}
+	}
+	s.admin.inUse[sqlplugin.PartitionedTableHistoryNode] = map[int64]bool{98: true}
+	s.admin.inUse[sqlplugin.PartitionedTableExecutions] = map[int64]bool{97: true}
+
+	s.NoError(s.manager.Run(context.Background()))
+	s.Equal([]int64{sqlplugin.LegacyPartitionBucket, 98, 100, 101, 102, 103}, s.admin.partitions[sqlplugin.PartitionedTableHistoryNode])
+	s.Equal([]int64{sqlplugin.LegacyPartitionBucket, 97, 100, 101, 102, 103}, s.admin.partitions[sqlplugin.PartitionedTableExecutions])
+}
+
+func (s *managerSuite) TestRun_ContinuesOnError() {
+	s.admin.createErr = errors.New("some random error")
+	s.admin.partitions[sqlplugin.PartitionedTableExecutions] = []int64{99, 100, 101, 102, 103}
+
+	s.Error(s.manager.Run(context.Background()))
+	s.Empty(s.admin.partitions[sqlplugin.PartitionedTableHistoryNode])
+	s.Equal([]int64{100, 101, 102, 103}, s.admin.partitions[sqlplugin.PartitionedTableExecutions])
+}
+
+func (a *fakePartitionAdmin) SelectPartitionBuckets(_ context.Context, _ int, table string) ([]int64, error) {
+	return append([]int64(nil), a.partitions[table]...), nil
+}
+
+func (a *fakePartitionAdmin) CreatePartition(_ context.Context, _ int, table string, bucket int64) error {
+	if a.createErr != nil {
+		return a.createErr
+	}
+	buckets := a.partitions[table]
+	if len(buckets) > 0 && buckets[len(buckets)-1] >= bucket {
+		return errors.New("partition is not after the existing ones")
+	}
+	a.partitions[table] = append(buckets, bucket)
+	return nil
+}
+
+func (a *fakePartitionAdmin) IsPartitionInUse(_ context.Context, _ int, table string, bucket int64) (bool, error) {
+	return a.inUse[table][bucket], nil
+}
+
+func (a *fakePartitionAdmin) DropPartition(_ context.Context, _ int, table string, bucket int64) error {
+	if bucket == sqlplugin.LegacyPartitionBucket {
+		return errors.New("legacy partition can't be dropped")
+	}
+	var buckets []int64
+	for _, b := range a.partitions[table] {
+		if b != bucket {
+			buckets = append(buckets, b)
+		}
+	}
+	a.partitions[table] = buckets
+	return nil
+}
@@ -67,6 +67,8 @@ type (
 		ClusterMetadata cluster.Metadata
 		// HistoryScannerEnabled indicates if history scanner should be started as part of scanner
 		HistoryScannerEnabled dynamicconfig.BoolPropertyFn
+		// PartitionRetentionEnabled indicates if partition retention job should be started as part of scanner
+		PartitionRetentionEnabled dynamicconfig.BoolPropertyFn
 		// ShardScanners is a list of shard scanner configs
 		ShardScanners              []*shardscanner.ScannerConfig
 		MaxWorkflowRetentionInDays dynamicconfig.IntPropertyFn
@@ -142,6 +144,13 @@ func (s *Scanner) Start() error {
 				tlScannerWFTypeName)
 			workerTaskListNames = append(workerTaskListNames, tlScannerTaskListName)
 		}
+		if s.isPartitionedSchema() && s.context.cfg.PartitionRetentionEnabled() {
+			ctx = s.startScanner(
+				ctx,
+				partitionRetentionWFStartOptions,
+				partitionRetentionWFTypeName)
+			workerTaskListNames = append(workerTaskListNames, partitionRetentionTaskListName)
+		}
 	}
 	if s.context.cfg.HistoryScannerEnabled() {
 		ctx = s.startScanner(
@@ -167,6 +176,12 @@ func (s *Scanner) Start() error {
 	return nil
 }
 
+func (s *Scanner) isPartitionedSchema() bool {
+	persistence := s.context.cfg.Persistence
+	sqlCfg := persistence.DataStores[persistence.DefaultStore].SQL
+	return sqlCfg != nil && sqlCfg.Partitioning != nil
+}
+
 func (s *Scanner) startScanner(ctx context.Context, options client.StartWorkflowOptions, workflowName string) context.Context {
 	go workercommon.StartWorkflowWithRetry(workflowName, scannerStartUpDelay, s.context.resource, func(client client.Client) error {
 		return s.startWorkflow(client, options, workflowName, nil)
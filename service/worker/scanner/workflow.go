@@ -22,6 +22,7 @@ package scanner
 
 import (
 	"context"
+	"fmt"
 	"time"
 
 	"go.uber.org/cadence"
@@ -30,8 +31,11 @@ import (
 	"go.uber.org/cadence/workflow"
 
 	"github.com/uber/cadence/common/log/tag"
+	"github.com/uber/cadence/common/persistence/sql"
+	"github.com/uber/cadence/common/persistence/sql/sqlplugin"
 	"github.com/uber/cadence/service/worker/scanner/executions"
 	"github.com/uber/cadence/service/worker/scanner/history"
+	"github.com/uber/cadence/service/worker/scanner/partition"
 	"github.com/uber/cadence/service/worker/scanner/tasklist"
 	"github.com/uber/cadence/service/worker/scanner/timers"
 )
@@ -50,10 +54,16 @@ const (
 	historyScannerWFTypeName     = "cadence-sys-history-scanner-workflow"
 	historyScannerTaskListName   = "cadence-sys-history-scanner-tasklist-0"
 	historyScavengerActivityName = "cadence-sys-history-scanner-scvg-activity"
+
+	partitionRetentionWFID         = "cadence-sys-partition-retention"
+	partitionRetentionWFTypeName   = "cadence-sys-partition-retention-workflow"
+	partitionRetentionTaskListName = "cadence-sys-partition-retention-tasklist-0"
+	partitionRetentionActivityName = "cadence-sys-partition-retention-activity"
 )
 
 var (
-	tlScavengerHBInterval = 10 * time.Second
+	tlScavengerHBInterval        = 10 * time.Second
+	partitionRetentionHBInterval = 10 * time.Second
 
 	activityRetryPolicy = cadence.RetryPolicy{
 		InitialInterval:    10 * time.Second,
@@ -81,6 +91,13 @@ var (
 		WorkflowIDReusePolicy:        cclient.WorkflowIDReusePolicyAllowDuplicate,
 		CronSchedule:                 "0 */12 * * *",
 	}
+	partitionRetentionWFStartOptions = cclient.StartWorkflowOptions{
+		ID:                           partitionRetentionWFID,
+		TaskList:                     partitionRetentionTaskListName,
+		ExecutionStartToCloseTimeout: infiniteDuration,
+		WorkflowIDReusePolicy:        cclient.WorkflowIDReusePolicyAllowDuplicate,
+		CronSchedule:                 "0 * * * *",
+	}
 )
 
 func init() {
@@ -90,6 +107,9 @@ func init() {
 	workflow.RegisterWithOptions(HistoryScannerWorkflow, workflow.RegisterOptions{Name: historyScannerWFTypeName})
 	activity.RegisterWithOptions(HistoryScavengerActivity, activity.RegisterOptions{Name: historyScavengerActivityName})
 
+	workflow.RegisterWithOptions(PartitionRetentionWorkflow, workflow.RegisterOptions{Name: partitionRetentionWFTypeName})
+	activity.RegisterWithOptions(PartitionRetentionActivity, activity.RegisterOptions{Name: partitionRetentionActivityName})
+
 	workflow.RegisterWithOptions(executions.ConcreteScannerWorkflow, workflow.RegisterOptions{Name: executions.ConcreteExecutionsScannerWFTypeName})
 	workflow.RegisterWithOptions(executions.CurrentScannerWorkflow, workflow.RegisterOptions{Name: executions.CurrentExecutionsScannerWFTypeName})
 	workflow.RegisterWithOptions(executions.ConcreteFixerWorkflow, workflow.RegisterOptions{Name: executions.ConcreteExecutionsFixerWFTypeName})
@@ -119,6 +139,18 @@ func HistoryScannerWorkflow(
 	return future.Get(ctx, nil)
 }
 
+// PartitionRetentionWorkflow is the workflow that runs the partition retention background daemon
+func PartitionRetentionWorkflow(
+	ctx workflow.Context,
+) error {
+
+	future := workflow.ExecuteActivity(
+		workflow.WithActivityOptions(ctx, activityOptions),
+		partitionRetentionActivityName,
+	)
+	return future.Get(ctx, nil)
+}
+
 // HistoryScavengerActivity is the activity that runs history scavenger
 func HistoryScavengerActivity(
 	activityCtx context.Context,
@@ -181,3 +213,52 @@ func TaskListScavengerActivity(
 	}
 	return nil
 }
+
+// PartitionRetentionActivity is the activity that maintains the partitions of the SQL partitioned schema
+func PartitionRetentionActivity(
+	activityCtx context.Context,
+) error {
+	ctx, err := getScannerContext(activityCtx)
+	if err != nil {
+		return err
+	}
+	res := ctx.resource
+	sqlCfg := ctx.cfg.Persistence.DataStores[ctx.cfg.Persistence.DefaultStore].SQL
+	if sqlCfg == nil || sqlCfg.Partitioning == nil {
+		return cadence.NewCustomError("partitioned schema is not configured for the default store")
+	}
+
+	db, err := sql.NewSQLDB(sqlCfg)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	admin, ok := db.(sqlplugin.PartitionAdmin)
+	if !ok {
+		return cadence.NewCustomError(fmt.Sprintf("sql plugin %v doesn't support the partitioned schema", sqlCfg.PluginName))
+	}
+
+	manager := partition.NewManager(
+		admin,
+		sqlCfg.NumShards,
+		sqlCfg.Partitioning.BucketSize,
+		res.GetTimeSource(),
+		res.GetMetricsClient(),
+		res.GetLogger(),
+	)
+	doneCh := make(chan error, 1)
+	go func() {
+		doneCh <- manager.Run(activityCtx)
+	}()
+
+	ticker := time.NewTicker(partitionRetentionHBInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case err := <-doneCh:
+			return err
+		case <-ticker.C:
+			activity.RecordHeartbeat(activityCtx)
+		}
+	}
+}
@@ -0,0 +1,205 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package scheduler
+
+import (
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"time"
+
+	"github.com/robfig/cron"
+)
+
+// OverlapPolicy decides what happens to a run of the schedule while the workflow started by a previous run is still running
+type OverlapPolicy string
+
+const (
+	// OverlapPolicySkip skips the run
+	OverlapPolicySkip OverlapPolicy = "skip"
+	// OverlapPolicyBuffer buffers the run and starts it once the running workflow is closed
+	OverlapPolicyBuffer OverlapPolicy = "buffer"
+	// OverlapPolicyCancelOther cancels the running workflow and starts the run
+	OverlapPolicyCancelOther OverlapPolicy = "cancel-other"
+	// OverlapPolicyAllowAll starts the run regardless of the running workflow
+	OverlapPolicyAllowAll OverlapPolicy = "allow-all"
+)
+
+const (
+	// DefaultCatchupWindow is the default value for CatchupWindow
+	DefaultCatchupWindow = time.Hour
+	// DefaultExecutionStartToCloseTimeout is the default value for ExecutionStartToCloseTimeout
+	DefaultExecutionStartToCloseTimeout = 24 * time.Hour
+	// DefaultTaskStartToCloseTimeout is the default value for TaskStartToCloseTimeout
+	DefaultTaskStartToCloseTimeout = 10 * time.Second
+)
+
+// AllOverlapPolicies is the overlap policies we supported
+var AllOverlapPolicies = []OverlapPolicy{OverlapPolicySkip, OverlapPolicyBuffer, OverlapPolicyCancelOther, OverlapPolicyAllowAll}
+
+type (
+	// ScheduleSpec defines when the runs of a schedule happen
+	ScheduleSpec struct {
+		// CronExpression is a standard cron expression
+		CronExpression string
+		// Timezone is the IANA name of the timezone the cron expression is evaluated in. Default to UTC
+		Timezone string
+		// Jitter is the maximum random delay added to each run
+		Jitter time.Duration
+		// CatchupWindow is how late a run can be started, e.g. after the scheduler is down,
+		// runs later than this are missed. Default to DefaultCatchupWindow
+		CatchupWindow time.Duration
+		// OverlapPolicy decides what happens to a run while the previous one is still running. Default to OverlapPolicySkip
+		OverlapPolicy OverlapPolicy
+	}
+
+	// ScheduleAction defines the workflow started by each run of a schedule
+	ScheduleAction struct {
+		// Domain of the started workflow
+		Domain string
+		// WorkflowIDPrefix prefixes the ID of the started workflow, which is suffixed by the time of the run.
+		// Default to the schedule ID
+		WorkflowIDPrefix string
+		WorkflowType     string
+		TaskList         string
+		Input            []byte
+		// Default to DefaultExecutionStartToCloseTimeout
+		ExecutionStartToCloseTimeout time.Duration
+		// Default to DefaultTaskStartToCloseTimeout
+		TaskStartToCloseTimeout time.Duration
+	}
+
+	// Schedule is the entity starting a workflow at the times of its spec
+	Schedule struct {
+		Spec   ScheduleSpec
+		Action ScheduleAction
+		// Paused schedule doesn't run until it's unpaused, runs missed while paused are not caught up
+		Paused bool
+		// Notes is a free form description of the schedule or of its last pause
+		Notes string
+	}
+)
+
+// ValidateSchedule validates the spec and the action of a schedule
+func ValidateSchedule(schedule *Schedule) error {
+	if schedule == nil {
+		return errors.New("schedule is not set")
+	}
+	if _, err := cron.ParseStandard(schedule.Spec.CronExpression); err != nil {
+		return fmt.Errorf("invalid cron expression: %v", err)
+	}
+	if _, err := time.LoadLocation(schedule.Spec.Timezone); err != nil {
+		return fmt.Errorf("invalid timezone: %v", err)
+	}
+	if schedule.Spec.Jitter < 0 || schedule.Spec.CatchupWindow < 0 {
+		return errors.New("jitter and catch-up window must not be negative")
+	}
+	if schedule.Spec.OverlapPolicy != "" && !isValidOverlapPolicy(schedule.Spec.OverlapPolicy) {
+		return fmt.Errorf("not supported overlap policy: %v", schedule.Spec.OverlapPolicy)
+	}
+	if schedule.Action.Domain == "" ||
+		schedule.Action.WorkflowType == "" ||
+		schedule.Action.TaskList == "" {
+		return errors.New("must provide required parameters: Domain/WorkflowType/TaskList")
+	}
+	return nil
+}
+
+func isValidOverlapPolicy(policy OverlapPolicy) bool {
+	for _, p := range AllOverlapPolicies {
+		if p == policy {
+			return true
+		}
+	}
+	return false
+}
+
+func setDefaultSchedule(schedule Schedule, scheduleID string) Schedule {
+	if schedule.Spec.CatchupWindow == 0 {
+		schedule.Spec.CatchupWindow = DefaultCatchupWindow
+	}
+	if schedule.Spec.OverlapPolicy == "" {
+		schedule.Spec.OverlapPolicy = OverlapPolicySkip
+	}
+	if schedule.Action.WorkflowIDPrefix == "" {
+		schedule.Action.WorkflowIDPrefix = scheduleID
+	}
+	if schedule.Action.ExecutionStartToCloseTimeout <= 0 {
+		schedule.Action.ExecutionStartToCloseTimeout = DefaultExecutionStartToCloseTimeout
+	}
+	if schedule.Action.TaskStartToCloseTimeout <= 0 {
+		schedule.Action.TaskStartToCloseTimeout = DefaultTaskStartToCloseTimeout
+	}
+	return schedule
+}
+
+// getNextRunTime returns the first time of the spec strictly after the given time, in UTC
+func getNextRunTime(spec ScheduleSpec, after time.Time) (time.Time, error) {
+	schedule, err := cron.ParseStandard(spec.CronExpression)
+	if err != nil {
+		return time.Time{}, err
+	}
+	location, err := time.LoadLocation(spec.Timezone)
+	if err != nil {
+		return time.Time{}, err
+	}
+	// the cron expression is evaluated in the location of the time it's given
+	return schedule.Next(after.In(location)).UTC(), nil
+}
+
+// getRunTimes returns the times of the spec in (after, until], up to limit of them
+func getRunTimes(spec ScheduleSpec, after time.Time, until time.Time, limit int) ([]time.Time, error) {
+	var runTimes []time.Time
+	for len(runTimes) < limit {
+		next, err := getNextRunTime(spec, after)
+		if err != nil {
+			return nil, err
+		}
+		if next.IsZero() || next.After(until) {
+			break
+		}
+		runTimes = append(runTimes, next)
+		after = next
+	}
+	return runTimes, nil
+}
+
+// getJitter returns the delay added to the run of the given time, it's derived from the schedule ID
+// and the time so that it's deterministic upon workflow replay
+func getJitter(spec ScheduleSpec, scheduleID string, runTime time.Time) time.Duration {
+	if spec.Jitter <= 0 {
+		return 0
+	}
+	hash := fnv.New64a()
+	hash.Write([]byte(fmt.Sprintf("%v/%v", scheduleID, runTime.UnixNano()))) //nolint:errcheck
+	return time.Duration(hash.Sum64() % uint64(spec.Jitter))
+}
+
+// getRunWorkflowID returns the ID of the workflow started by the run of the given time,
+// the ID is deterministic so that a run is not started twice. Triggered runs are told apart
+// by their trigger ID, as several of them can be triggered at the same time
+func getRunWorkflowID(action ScheduleAction, runTime time.Time, triggerID int64) string {
+	workflowID := fmt.Sprintf("%v-%v", action.WorkflowIDPrefix, runTime.UTC().Format(time.RFC3339))
+	if triggerID != 0 {
+		workflowID = fmt.Sprintf("%v-trigger-%v", workflowID, triggerID)
+	}
+	return workflowID
+}
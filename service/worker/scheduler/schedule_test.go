@@ -0,0 +1,120 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateSchedule(t *testing.T) {
+	assert.Error(t, ValidateSchedule(nil))
+
+	schedule := &Schedule{}
+	assert.Error(t, ValidateSchedule(schedule))
+	schedule.Spec.CronExpression = "*/5 * * * *"
+	assert.Error(t, ValidateSchedule(schedule))
+	schedule.Action = ScheduleAction{
+		Domain:       "test-domain",
+		WorkflowType: "test-workflow-type",
+		TaskList:     "test-tasklist",
+	}
+	assert.NoError(t, ValidateSchedule(schedule))
+
+	schedule.Spec.Timezone = "Invalid/Timezone"
+	assert.Error(t, ValidateSchedule(schedule))
+	schedule.Spec.Timezone = "America/New_York"
+	assert.NoError(t, ValidateSchedule(schedule))
+
+	schedule.Spec.OverlapPolicy = "invalid"
+	assert.Error(t, ValidateSchedule(schedule))
+	schedule.Spec.OverlapPolicy = OverlapPolicyBuffer
+	assert.NoError(t, ValidateSchedule(schedule))
+
+	schedule.Spec.Jitter = -time.Second
+	assert.Error(t, ValidateSchedule(schedule))
+}
+
+func TestSetDefaultSchedule(t *testing.T) {
+	schedule := setDefaultSchedule(Schedule{}, "test-schedule")
+	assert.Equal(t, DefaultCatchupWindow, schedule.Spec.CatchupWindow)
+	assert.Equal(t, OverlapPolicySkip, schedule.Spec.OverlapPolicy)
+	assert.Equal(t, "test-schedule", schedule.Action.WorkflowIDPrefix)
+	assert.Equal(t, DefaultExecutionStartToCloseTimeout, schedule.Action.ExecutionStartToCloseTimeout)
+	assert.Equal(t, DefaultTaskStartToCloseTimeout, schedule.Action.TaskStartToCloseTimeout)
+}
+
+func TestGetNextRunTime(t *testing.T) {
+	after := time.Date(2021, 3, 1, 12, 30, 0, 0, time.UTC)
+
+	next, err := getNextRunTime(ScheduleSpec{CronExpression: "0 9 * * *"}, after)
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2021, 3, 2, 9, 0, 0, 0, time.UTC), next)
+
+	// 9:00 in New York is 14:00 in UTC before the daylight saving time
+	next, err = getNextRunTime(ScheduleSpec{CronExpression: "0 9 * * *", Timezone: "America/New_York"}, after)
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2021, 3, 1, 14, 0, 0, 0, time.UTC), next)
+
+	_, err = getNextRunTime(ScheduleSpec{CronExpression: "invalid"}, after)
+	assert.Error(t, err)
+}
+
+func TestGetRunTimes(t *testing.T) {
+	spec := ScheduleSpec{CronExpression: "0 * * * *"}
+	after := time.Date(2021, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	runTimes, err := getRunTimes(spec, after, after.Add(3*time.Hour), 10)
+	assert.NoError(t, err)
+	assert.Equal(t, []time.Time{
+		after.Add(time.Hour),
+		after.Add(2 * time.Hour),
+		after.Add(3 * time.Hour),
+	}, runTimes)
+
+	runTimes, err = getRunTimes(spec, after, after.Add(3*time.Hour), 2)
+	assert.NoError(t, err)
+	assert.Len(t, runTimes, 2)
+
+	runTimes, err = getRunTimes(spec, after, after.Add(time.Minute), 10)
+	assert.NoError(t, err)
+	assert.Empty(t, runTimes)
+}
+
+func TestGetJitter(t *testing.T) {
+	runTime := time.Date(2021, 3, 1, 0, 0, 0, 0, time.UTC)
+	assert.Equal(t, time.Duration(0), getJitter(ScheduleSpec{}, "test-schedule", runTime))
+
+	spec := ScheduleSpec{Jitter: time.Minute}
+	for i := 0; i < 100; i++ {
+		jitter := getJitter(spec, "test-schedule", runTime.Add(time.Duration(i)*time.Hour))
+		assert.True(t, jitter >= 0 && jitter < time.Minute)
+	}
+	assert.Equal(t, getJitter(spec, "test-schedule", runTime), getJitter(spec, "test-schedule", runTime))
+}
+
+func TestGetRunWorkflowID(t *testing.T) {
+	runTime := time.Date(2021, 3, 1, 9, 0, 0, 0, time.UTC)
+	assert.Equal(t, "prefix-2021-03-01T09:00:00Z", getRunWorkflowID(ScheduleAction{WorkflowIDPrefix: "prefix"}, runTime, 0))
+	assert.Equal(t, "prefix-2021-03-01T09:00:00Z-trigger-2", getRunWorkflowID(ScheduleAction{WorkflowIDPrefix: "prefix"}, runTime, 2))
+}
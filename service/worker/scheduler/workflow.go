@@ -0,0 +1,642 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package scheduler
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/cadence"
+	"go.uber.org/cadence/activity"
+	"go.uber.org/cadence/workflow"
+	"go.uber.org/zap"
+
+	"github.com/uber/cadence/common"
+	"github.com/uber/cadence/common/log"
+	"github.com/uber/cadence/common/log/tag"
+	"github.com/uber/cadence/common/types"
+)
+
+type (
+	contextKey string
+)
+
+const (
+	schedulerContextKey contextKey = "schedulerContext"
+	// SchedulerTaskListName is the tasklist name
+	SchedulerTaskListName = "cadence-sys-scheduler-tasklist"
+	// ScheduleWFTypeName is the workflow type
+	ScheduleWFTypeName           = "cadence-sys-schedule-workflow"
+	startWorkflowActivityName    = "cadence-sys-schedule-start-workflow-activity"
+	describeWorkflowActivityName = "cadence-sys-schedule-describe-workflow-activity"
+	cancelWorkflowActivityName   = "cadence-sys-schedule-cancel-workflow-activity"
+	// InfiniteDuration is a long duration(20 yrs) we used for infinite workflow running
+	InfiniteDuration = 20 * 365 * 24 * time.Hour
+
+	// UpdateSignalName is the signal name replacing the schedule, the signal input is the new Schedule
+	UpdateSignalName = "update"
+	// PauseSignalName is the signal name pausing the schedule, the signal input is the notes of the pause
+	PauseSignalName = "pause"
+	// UnpauseSignalName is the signal name unpausing the schedule, the signal input is the notes of the unpause
+	UnpauseSignalName = "unpause"
+	// TriggerSignalName is the signal name running the schedule immediately, the signal input is a TriggerRequest
+	TriggerSignalName = "trigger"
+	// BackfillSignalName is the signal name running the schedule for the times of a past window, the signal input is a BackfillRequest
+	BackfillSignalName = "backfill"
+	// DeleteSignalName is the signal name deleting the schedule
+	DeleteSignalName = "delete"
+	// DescribeQueryType is the query type describing the schedule, the query result is a ScheduleDescription
+	DescribeQueryType = "describe"
+
+	// errReasonRunAlreadyStarted is the reason of the error starting a triggered run whose workflow is already started
+	errReasonRunAlreadyStarted = "cadence-sys-schedule-run-already-started"
+	// errReasonNotScheduleWorkflow is the reason of the error of an activity not scheduled by the workflow of a schedule
+	// of the domain it operates on
+	errReasonNotScheduleWorkflow = "cadence-sys-schedule-not-schedule-workflow"
+
+	// maxPendingRuns bounds the runs waiting to be started, including the ones of a backfill
+	maxPendingRuns = 1000
+	// maxRecentRuns bounds the recent runs kept in the state of the schedule
+	maxRecentRuns = 10
+	// pendingRunsCheckInterval is the interval of checking whether the pending runs can be started
+	pendingRunsCheckInterval = time.Minute
+	// maxIterationsBeforeContinueAsNew bounds the history size of the schedule workflow
+	maxIterationsBeforeContinueAsNew = 500
+)
+
+type (
+	// ScheduleParams is the parameters for schedule workflow
+	ScheduleParams struct {
+		ScheduleID string
+		Schedule   Schedule
+		// State is carried over upon continue as new. Default to empty
+		State ScheduleState
+	}
+
+	// ScheduleState is the state of the runs of a schedule
+	ScheduleState struct {
+		// LastRunTime is the time of the last run taken from the spec
+		LastRunTime time.Time
+		// PendingRuns are the runs waiting to be started
+		PendingRuns []PendingRun
+		// LastWorkflow is the workflow started by the last run, the overlap policy applies to it
+		LastWorkflow *WorkflowRun
+		// RecentRuns are the most recent runs started
+		RecentRuns  []WorkflowRun
+		TotalRuns   int64
+		MissedRuns  int64
+		SkippedRuns int64
+		// TriggeredRuns is the number of the runs triggered, it identifies the workflows of the triggered runs
+		TriggeredRuns int64
+	}
+
+	// PendingRun is a run waiting to be started
+	PendingRun struct {
+		RunTime time.Time
+		// OverlapPolicy overrides the overlap policy of the schedule for this run. Default to empty
+		OverlapPolicy OverlapPolicy
+		// TriggerID identifies the run when it's triggered. Default to zero
+		TriggerID int64
+	}
+
+	// WorkflowRun is a workflow started by a run of the schedule
+	WorkflowRun struct {
+		RunTime    time.Time
+		StartTime  time.Time
+		WorkflowID string
+		RunID      string
+	}
+
+	// TriggerRequest is the input of the trigger signal
+	TriggerRequest struct {
+		// OverlapPolicy overrides the overlap policy of the schedule for this run. Default to empty
+		OverlapPolicy OverlapPolicy
+	}
+
+	// BackfillRequest is the input of the backfill signal
+	BackfillRequest struct {
+		StartTime time.Time
+		EndTime   time.Time
+		// OverlapPolicy overrides the overlap policy of the schedule for the backfilled runs. Default to empty
+		OverlapPolicy OverlapPolicy
+	}
+
+	// ScheduleDescription is the result of the describe query
+	ScheduleDescription struct {
+		ScheduleID  string
+		Schedule    Schedule
+		State       ScheduleState
+		NextRunTime time.Time
+	}
+
+	startWorkflowRequest struct {
+		Action    ScheduleAction
+		RunTime   time.Time
+		TriggerID int64
+	}
+
+	scheduleWorkflow struct {
+		scheduleID string
+		schedule   Schedule
+		state      ScheduleState
+		deleted    bool
+		logger     *zap.Logger
+	}
+
+	// signalHandler handles a signal received by the given function, it returns whether a signal was received
+	signalHandler struct {
+		signalName string
+		handle     func(ctx workflow.Context, receive func(valuePtr interface{}) bool) bool
+	}
+)
+
+var (
+	scheduleActivityRetryPolicy = cadence.RetryPolicy{
+		InitialInterval:    time.Second,
+		BackoffCoefficient: 2,
+		MaximumInterval:    time.Minute,
+		ExpirationInterval: 10 * time.Minute,
+		NonRetriableErrorReasons: []string{
+			errReasonRunAlreadyStarted,
+			errReasonNotScheduleWorkflow,
+		},
+	}
+
+	scheduleActivityOptions = workflow.ActivityOptions{
+		ScheduleToStartTimeout: 5 * time.Minute,
+		StartToCloseTimeout:    time.Minute,
+		RetryPolicy:            &scheduleActivityRetryPolicy,
+	}
+)
+
+func init() {
+	workflow.RegisterWithOptions(ScheduleWorkflow, workflow.RegisterOptions{Name: ScheduleWFTypeName})
+	activity.RegisterWithOptions(StartWorkflowActivity, activity.RegisterOptions{Name: startWorkflowActivityName})
+	activity.RegisterWithOptions(DescribeWorkflowActivity, activity.RegisterOptions{Name: describeWorkflowActivityName})
+	activity.RegisterWithOptions(CancelWorkflowActivity, activity.RegisterOptions{Name: cancelWorkflowActivityName})
+}
+
+// GetScheduleWorkflowID returns the ID of the workflow of a schedule, schedule IDs are unique within a domain
+func GetScheduleWorkflowID(domain string, scheduleID string) string {
+	return GetScheduleWorkflowIDPrefix(domain) + scheduleID
+}
+
+// GetScheduleWorkflowIDPrefix returns the prefix of the IDs of the workflows of the schedules of a domain
+func GetScheduleWorkflowIDPrefix(domain string) string {
+	return domain + "/"
+}
+
+// GetScheduleDomain returns the domain of the schedule of a workflow ID, or an empty string when the workflow ID
+// isn't the ID of the workflow of a schedule
+func GetScheduleDomain(workflowID string) string {
+	i := strings.Index(workflowID, "/")
+	if i <= 0 {
+		return ""
+	}
+	return workflowID[:i]
+}
+
+// ScheduleWorkflow is the workflow that runs a schedule until it's deleted
+func ScheduleWorkflow(ctx workflow.Context, params ScheduleParams) error {
+	if err := ValidateSchedule(&params.Schedule); err != nil {
+		return err
+	}
+	s := &scheduleWorkflow{
+		scheduleID: params.ScheduleID,
+		schedule:   setDefaultSchedule(params.Schedule, params.ScheduleID),
+		state:      params.State,
+		logger:     workflow.GetLogger(ctx),
+	}
+	if s.state.LastRunTime.IsZero() {
+		s.state.LastRunTime = workflow.Now(ctx)
+	}
+	if err := workflow.SetQueryHandler(ctx, DescribeQueryType, s.describe); err != nil {
+		return err
+	}
+
+	ctx = workflow.WithActivityOptions(ctx, scheduleActivityOptions)
+	for i := 0; i < maxIterationsBeforeContinueAsNew; i++ {
+		s.takeDueRuns(workflow.Now(ctx))
+		s.startPendingRuns(ctx)
+		if deleted := s.wait(ctx); deleted {
+			return nil
+		}
+	}
+	s.drainSignals(ctx)
+	if s.deleted {
+		return nil
+	}
+	return workflow.NewContinueAsNewError(ctx, ScheduleWFTypeName, ScheduleParams{
+		ScheduleID: s.scheduleID,
+		Schedule:   s.schedule,
+		State:      s.state,
+	})
+}
+
+func (s *scheduleWorkflow) describe() (ScheduleDescription, error) {
+	description := ScheduleDescription{
+		ScheduleID: s.scheduleID,
+		Schedule:   s.schedule,
+		State:      s.state,
+	}
+	if !s.schedule.Paused {
+		nextRunTime, err := getNextRunTime(s.schedule.Spec, s.state.LastRunTime)
+		if err != nil {
+			return ScheduleDescription{}, err
+		}
+		description.NextRunTime = nextRunTime
+	}
+	return description, nil
+}
+
+// takeDueRuns takes the runs of the spec which are due, the runs later than the catch-up window are missed
+func (s *scheduleWorkflow) takeDueRuns(now time.Time) {
+	if s.schedule.Paused {
+		return
+	}
+	runTimes, err := getRunTimes(s.schedule.Spec, s.state.LastRunTime, now, maxPendingRuns)
+	if err != nil {
+		s.logger.Error("Failed to get the run times of the schedule", zap.Error(err))
+		return
+	}
+	for _, runTime := range runTimes {
+		if now.Before(runTime.Add(getJitter(s.schedule.Spec, s.scheduleID, runTime))) {
+			break
+		}
+		s.state.LastRunTime = runTime
+		if now.Sub(runTime) > s.schedule.Spec.CatchupWindow {
+			s.state.MissedRuns++
+			continue
+		}
+		s.addPendingRun(PendingRun{RunTime: runTime})
+	}
+}
+
+func (s *scheduleWorkflow) addPendingRun(run PendingRun) {
+	if len(s.state.PendingRuns) >= maxPendingRuns {
+		s.state.SkippedRuns++
+		return
+	}
+	s.state.PendingRuns = append(s.state.PendingRuns, run)
+}
+
+// startPendingRuns starts the pending runs in order, applying the overlap policy of each of them
+func (s *scheduleWorkflow) startPendingRuns(ctx workflow.Context) {
+	for len(s.state.PendingRuns) > 0 {
+		run := s.state.PendingRuns[0]
+		overlapPolicy := run.OverlapPolicy
+		if overlapPolicy == "" {
+			overlapPolicy = s.schedule.Spec.OverlapPolicy
+		}
+
+		if overlapPolicy != OverlapPolicyAllowAll && s.state.LastWorkflow != nil {
+			var running bool
+			if err := workflow.ExecuteActivity(
+				ctx,
+				describeWorkflowActivityName,
+				s.schedule.Action.Domain,
+				*s.state.LastWorkflow,
+			).Get(ctx, &running); err != nil {
+				// the pending runs are started upon next check
+				s.logger.Error("Failed to describe the last workflow of the schedule", zap.Error(err))
+				return
+			}
+			if !running {
+				s.state.LastWorkflow = nil
+			}
+		}
+
+		if s.state.LastWorkflow != nil {
+			switch overlapPolicy {
+			case OverlapPolicySkip:
+				s.state.SkippedRuns++
+				s.state.PendingRuns = s.state.PendingRuns[1:]
+				continue
+			case OverlapPolicyBuffer:
+				// the pending runs are started upon next check after the last workflow is closed
+				return
+			case OverlapPolicyCancelOther:
+				if err := workflow.ExecuteActivity(
+					ctx,
+					cancelWorkflowActivityName,
+					s.schedule.Action.Domain,
+					*s.state.LastWorkflow,
+				).Get(ctx, nil); err != nil {
+					s.logger.Error("Failed to cancel the last workflow of the schedule", zap.Error(err))
+					return
+				}
+			}
+		}
+
+		var workflowRun WorkflowRun
+		if err := workflow.ExecuteActivity(
+			ctx,
+			startWorkflowActivityName,
+			startWorkflowRequest{
+				Action:    s.schedule.Action,
+				RunTime:   run.RunTime,
+				TriggerID: run.TriggerID,
+			},
+		).Get(ctx, &workflowRun); err != nil {
+			if customErr, ok := err.(*cadence.CustomError); ok && customErr.Reason() == errReasonRunAlreadyStarted {
+				s.logger.Warn("Skipped the run of the schedule as its workflow is already started", zap.Error(err))
+				s.state.SkippedRuns++
+				s.state.PendingRuns = s.state.PendingRuns[1:]
+				continue
+			}
+			s.logger.Error("Failed to start the workflow of the schedule", zap.Error(err))
+			return
+		}
+		s.state.PendingRuns = s.state.PendingRuns[1:]
+		s.state.LastWorkflow = &workflowRun
+		s.state.TotalRuns++
+		s.state.RecentRuns = append(s.state.RecentRuns, workflowRun)
+		if len(s.state.RecentRuns) > maxRecentRuns {
+			s.state.RecentRuns = s.state.RecentRuns[len(s.state.RecentRuns)-maxRecentRuns:]
+		}
+	}
+}
+
+// wait blocks until the next run is due, the pending runs are to be checked, or a signal is received.
+// It returns whether the schedule is deleted.
+func (s *scheduleWorkflow) wait(ctx workflow.Context) bool {
+	timerCtx, cancelTimer := workflow.WithCancel(ctx)
+	defer cancelTimer()
+
+	now := workflow.Now(ctx)
+	waitTime := InfiniteDuration
+	if !s.schedule.Paused {
+		nextRunTime, err := getNextRunTime(s.schedule.Spec, s.state.LastRunTime)
+		if err == nil && !nextRunTime.IsZero() {
+			waitTime = nextRunTime.Add(getJitter(s.schedule.Spec, s.scheduleID, nextRunTime)).Sub(now)
+		}
+	}
+	if len(s.state.PendingRuns) > 0 && waitTime > pendingRunsCheckInterval {
+		waitTime = pendingRunsCheckInterval
+	}
+	if waitTime <= 0 {
+		return false
+	}
+
+	selector := workflow.NewSelector(ctx)
+	selector.AddFuture(workflow.NewTimer(timerCtx, waitTime), func(f workflow.Future) {})
+	for _, handler := range s.getSignalHandlers() {
+		handle := handler.handle
+		selector.AddReceive(workflow.GetSignalChannel(ctx, handler.signalName), func(c workflow.Channel, more bool) {
+			handle(ctx, func(valuePtr interface{}) bool {
+				return c.Receive(ctx, valuePtr)
+			})
+		})
+	}
+	selector.Select(ctx)
+	return s.deleted
+}
+
+// drainSignals handles the signals received since the last wait, they would be lost upon continue as new
+func (s *scheduleWorkflow) drainSignals(ctx workflow.Context) {
+	for _, handler := range s.getSignalHandlers() {
+		c := workflow.GetSignalChannel(ctx, handler.signalName)
+		for received := true; received; {
+			received = handler.handle(ctx, c.ReceiveAsync)
+		}
+	}
+}
+
+// getSignalHandlers returns the handlers of the signals of the schedule, in the order they are handled
+func (s *scheduleWorkflow) getSignalHandlers() []signalHandler {
+	return []signalHandler{
+		{signalName: UpdateSignalName, handle: s.handleUpdate},
+		{signalName: PauseSignalName, handle: s.handlePause},
+		{signalName: UnpauseSignalName, handle: s.handleUnpause},
+		{signalName: TriggerSignalName, handle: s.handleTrigger},
+		{signalName: BackfillSignalName, handle: s.handleBackfill},
+		{signalName: DeleteSignalName, handle: s.handleDelete},
+	}
+}
+
+func (s *scheduleWorkflow) handleUpdate(ctx workflow.Context, receive func(valuePtr interface{}) bool) bool {
+	var schedule Schedule
+	if !receive(&schedule) {
+		return false
+	}
+	if err := ValidateSchedule(&schedule); err != nil {
+		s.logger.Warn("Ignored invalid update of the schedule", zap.Error(err))
+		return true
+	}
+	if schedule.Action.Domain != s.schedule.Action.Domain {
+		// the caller is authorized against the domain of the schedule, which can't be changed
+		s.logger.Warn("Ignored update of the domain of the schedule", zap.String("domain", schedule.Action.Domain))
+		return true
+	}
+	if s.schedule.Paused && !schedule.Paused {
+		s.state.LastRunTime = workflow.Now(ctx)
+	}
+	s.schedule = setDefaultSchedule(schedule, s.scheduleID)
+	return true
+}
+
+func (s *scheduleWorkflow) handlePause(ctx workflow.Context, receive func(valuePtr interface{}) bool) bool {
+	var notes string
+	if !receive(&notes) {
+		return false
+	}
+	s.schedule.Paused = true
+	s.schedule.Notes = notes
+	return true
+}
+
+func (s *scheduleWorkflow) handleUnpause(ctx workflow.Context, receive func(valuePtr interface{}) bool) bool {
+	var notes string
+	if !receive(&notes) {
+		return false
+	}
+	if s.schedule.Paused {
+		// the runs missed while paused are not caught up
+		s.state.LastRunTime = workflow.Now(ctx)
+	}
+	s.schedule.Paused = false
+	s.schedule.Notes = notes
+	return true
+}
+
+func (s *scheduleWorkflow) handleTrigger(ctx workflow.Context, receive func(valuePtr interface{}) bool) bool {
+	var request TriggerRequest
+	if !receive(&request) {
+		return false
+	}
+	// each triggered run starts its own workflow, even when triggered at the same time
+	s.state.TriggeredRuns++
+	s.addPendingRun(PendingRun{
+		RunTime:       workflow.Now(ctx),
+		OverlapPolicy: request.OverlapPolicy,
+		TriggerID:     s.state.TriggeredRuns,
+	})
+	return true
+}
+
+func (s *scheduleWorkflow) handleBackfill(ctx workflow.Context, receive func(valuePtr interface{}) bool) bool {
+	var request BackfillRequest
+	if !receive(&request) {
+		return false
+	}
+	// the start time is inclusive
+	runTimes, err := getRunTimes(s.schedule.Spec, request.StartTime.Add(-time.Nanosecond), request.EndTime, maxPendingRuns)
+	if err != nil {
+		s.logger.Warn("Ignored invalid backfill of the schedule", zap.Error(err))
+		return true
+	}
+	for _, runTime := range runTimes {
+		s.addPendingRun(PendingRun{
+			RunTime:       runTime,
+			OverlapPolicy: request.OverlapPolicy,
+		})
+	}
+	return true
+}
+
+func (s *scheduleWorkflow) handleDelete(ctx workflow.Context, receive func(valuePtr interface{}) bool) bool {
+	if !receive(nil) {
+		return false
+	}
+	s.deleted = true
+	return true
+}
+
+// StartWorkflowActivity starts the workflow of a run of the schedule
+func StartWorkflowActivity(ctx context.Context, request startWorkflowRequest) (WorkflowRun, error) {
+	action := request.Action
+	if err := validateActivityWorkflow(ctx, action.Domain); err != nil {
+		return WorkflowRun{}, err
+	}
+	scheduler := ctx.Value(schedulerContextKey).(*Scheduler)
+	client := scheduler.clientBean.GetFrontendClient()
+
+	workflowID := getRunWorkflowID(action, request.RunTime, request.TriggerID)
+	workflowRun := WorkflowRun{
+		RunTime:    request.RunTime,
+		StartTime:  time.Now(),
+		WorkflowID: workflowID,
+	}
+	resp, err := client.StartWorkflowExecution(ctx, &types.StartWorkflowExecutionRequest{
+		Domain:                              action.Domain,
+		WorkflowID:                          workflowID,
+		WorkflowType:                        &types.WorkflowType{Name: action.WorkflowType},
+		TaskList:                            &types.TaskList{Name: action.TaskList},
+		Input:                               action.Input,
+		ExecutionStartToCloseTimeoutSeconds: common.Int32Ptr(int32(action.ExecutionStartToCloseTimeout.Seconds())),
+		TaskStartToCloseTimeoutSeconds:      common.Int32Ptr(int32(action.TaskStartToCloseTimeout.Seconds())),
+		Identity:                            ScheduleWFTypeName,
+		// the request ID is derived from the workflow ID so that retries of the activity are deduplicated
+		RequestID:             uuid.NewSHA1(uuid.NameSpaceOID, []byte(workflowID)).String(),
+		WorkflowIDReusePolicy: types.WorkflowIDReusePolicyRejectDuplicate.Ptr(),
+	})
+	if err != nil {
+		if alreadyStarted, ok := err.(*types.WorkflowExecutionAlreadyStartedError); ok {
+			if request.TriggerID != 0 {
+				// the workflow ID of a triggered run is taken by another workflow
+				return WorkflowRun{}, cadence.NewCustomError(errReasonRunAlreadyStarted, alreadyStarted.Message)
+			}
+			// the run of the spec is already started
+			workflowRun.RunID = alreadyStarted.RunID
+			return workflowRun, nil
+		}
+		getActivityLogger(ctx).Error("Failed to start the workflow of the schedule",
+			tag.WorkflowDomainName(action.Domain),
+			tag.Error(err))
+		return WorkflowRun{}, err
+	}
+	workflowRun.RunID = resp.GetRunID()
+	return workflowRun, nil
+}
+
+// DescribeWorkflowActivity returns whether the workflow of a run of the schedule is still running
+func DescribeWorkflowActivity(ctx context.Context, domain string, workflowRun WorkflowRun) (bool, error) {
+	if err := validateActivityWorkflow(ctx, domain); err != nil {
+		return false, err
+	}
+	scheduler := ctx.Value(schedulerContextKey).(*Scheduler)
+	client := scheduler.clientBean.GetFrontendClient()
+
+	resp, err := client.DescribeWorkflowExecution(ctx, &types.DescribeWorkflowExecutionRequest{
+		Domain: domain,
+		Execution: &types.WorkflowExecution{
+			WorkflowID: workflowRun.WorkflowID,
+			RunID:      workflowRun.RunID,
+		},
+	})
+	if err != nil {
+		if _, ok := err.(*types.EntityNotExistsError); ok {
+			return false, nil
+		}
+		return false, err
+	}
+	return resp.GetWorkflowExecutionInfo().CloseStatus == nil, nil
+}
+
+// CancelWorkflowActivity requests the cancellation of the workflow of a run of the schedule
+func CancelWorkflowActivity(ctx context.Context, domain string, workflowRun WorkflowRun) error {
+	if err := validateActivityWorkflow(ctx, domain); err != nil {
+		return err
+	}
+	scheduler := ctx.Value(schedulerContextKey).(*Scheduler)
+	client := scheduler.clientBean.GetFrontendClient()
+
+	err := client.RequestCancelWorkflowExecution(ctx, &types.RequestCancelWorkflowExecutionRequest{
+		Domain: domain,
+		WorkflowExecution: &types.WorkflowExecution{
+			WorkflowID: workflowRun.WorkflowID,
+			RunID:      workflowRun.RunID,
+		},
+		Identity:  ScheduleWFTypeName,
+		RequestID: uuid.New().String(),
+	})
+	switch err.(type) {
+	case nil, *types.EntityNotExistsError, *types.WorkflowExecutionAlreadyCompletedError, *types.CancellationAlreadyRequestedError:
+		return nil
+	default:
+		return err
+	}
+}
+
+// validateActivityWorkflow validates that the activity is scheduled by the workflow of a schedule of the domain,
+// as the activities operate on the domain with the internal frontend client
+func validateActivityWorkflow(ctx context.Context, domain string) error {
+	info := activity.GetInfo(ctx)
+	if info.WorkflowDomain != common.SchedulerLocalDomainName ||
+		info.WorkflowType == nil ||
+		info.WorkflowType.Name != ScheduleWFTypeName ||
+		!strings.HasPrefix(info.WorkflowExecution.ID, GetScheduleWorkflowIDPrefix(domain)) {
+		return cadence.NewCustomError(errReasonNotScheduleWorkflow)
+	}
+	return nil
+}
+
+func getActivityLogger(ctx context.Context) log.Logger {
+	scheduler := ctx.Value(schedulerContextKey).(*Scheduler)
+	wfInfo := activity.GetInfo(ctx)
+	return scheduler.logger.WithTags(
+		tag.WorkflowID(wfInfo.WorkflowExecution.ID),
+		tag.WorkflowRunID(wfInfo.WorkflowExecution.RunID),
+		tag.WorkflowDomainName(wfInfo.WorkflowDomain),
+	)
+}
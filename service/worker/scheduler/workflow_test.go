@@ -0,0 +1,255 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package scheduler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/cadence"
+	"go.uber.org/cadence/activity"
+	"go.uber.org/cadence/testsuite"
+	"go.uber.org/cadence/workflow"
+)
+
+type scheduleWorkflowTestSuite struct {
+	suite.Suite
+	testsuite.WorkflowTestSuite
+	workflowEnv *testsuite.TestWorkflowEnvironment
+}
+
+func TestScheduleWorkflowTestSuite(t *testing.T) {
+	suite.Run(t, new(scheduleWorkflowTestSuite))
+}
+
+func (s *scheduleWorkflowTestSuite) SetupTest() {
+	s.workflowEnv = s.NewTestWorkflowEnvironment()
+	s.workflowEnv.SetStartTime(time.Date(2021, 3, 1, 0, 0, 30, 0, time.UTC))
+	s.workflowEnv.RegisterWorkflowWithOptions(ScheduleWorkflow, workflow.RegisterOptions{Name: ScheduleWFTypeName})
+	s.workflowEnv.RegisterActivityWithOptions(StartWorkflowActivity, activity.RegisterOptions{Name: startWorkflowActivityName})
+	s.workflowEnv.RegisterActivityWithOptions(DescribeWorkflowActivity, activity.RegisterOptions{Name: describeWorkflowActivityName})
+	s.workflowEnv.RegisterActivityWithOptions(CancelWorkflowActivity, activity.RegisterOptions{Name: cancelWorkflowActivityName})
+}
+
+func (s *scheduleWorkflowTestSuite) TearDownTest() {
+	s.workflowEnv.AssertExpectations(s.T())
+}
+
+func (s *scheduleWorkflowTestSuite) getParams(overlapPolicy OverlapPolicy) ScheduleParams {
+	return ScheduleParams{
+		ScheduleID: "test-schedule",
+		Schedule: Schedule{
+			Spec: ScheduleSpec{
+				CronExpression: "0 * * * *",
+				OverlapPolicy:  overlapPolicy,
+			},
+			Action: ScheduleAction{
+				Domain:       "test-domain",
+				WorkflowType: "test-workflow-type",
+				TaskList:     "test-tasklist",
+			},
+		},
+	}
+}
+
+func (s *scheduleWorkflowTestSuite) mockStartWorkflowActivity() *[]WorkflowRun {
+	var started []WorkflowRun
+	s.workflowEnv.OnActivity(startWorkflowActivityName, mock.Anything, mock.Anything).Return(
+		func(ctx context.Context, request startWorkflowRequest) (WorkflowRun, error) {
+			workflowRun := WorkflowRun{
+				RunTime:    request.RunTime,
+				WorkflowID: getRunWorkflowID(request.Action, request.RunTime, request.TriggerID),
+				RunID:      "test-run-id",
+			}
+			started = append(started, workflowRun)
+			return workflowRun, nil
+		})
+	return &started
+}
+
+func (s *scheduleWorkflowTestSuite) deleteAfter(delay time.Duration) {
+	s.workflowEnv.RegisterDelayedCallback(func() {
+		s.workflowEnv.SignalWorkflow(DeleteSignalName, nil)
+	}, delay)
+}
+
+func (s *scheduleWorkflowTestSuite) TestWorkflow_InvalidParams() {
+	s.workflowEnv.ExecuteWorkflow(ScheduleWFTypeName, ScheduleParams{})
+	s.True(s.workflowEnv.IsWorkflowCompleted())
+	s.Error(s.workflowEnv.GetWorkflowError())
+}
+
+func (s *scheduleWorkflowTestSuite) TestWorkflow_AllowAll() {
+	started := s.mockStartWorkflowActivity()
+	s.deleteAfter(3 * time.Hour)
+
+	s.workflowEnv.ExecuteWorkflow(ScheduleWFTypeName, s.getParams(OverlapPolicyAllowAll))
+	s.True(s.workflowEnv.IsWorkflowCompleted())
+	s.NoError(s.workflowEnv.GetWorkflowError())
+	s.Len(*started, 3)
+	s.Equal("test-schedule-2021-03-01T01:00:00Z", (*started)[0].WorkflowID)
+}
+
+func (s *scheduleWorkflowTestSuite) TestWorkflow_Skip() {
+	started := s.mockStartWorkflowActivity()
+	s.workflowEnv.OnActivity(describeWorkflowActivityName, mock.Anything, mock.Anything, mock.Anything).Return(true, nil)
+	s.deleteAfter(3 * time.Hour)
+
+	s.workflowEnv.ExecuteWorkflow(ScheduleWFTypeName, s.getParams(OverlapPolicySkip))
+	s.True(s.workflowEnv.IsWorkflowCompleted())
+	s.NoError(s.workflowEnv.GetWorkflowError())
+	s.Len(*started, 1)
+}
+
+func (s *scheduleWorkflowTestSuite) TestWorkflow_CancelOther() {
+	started := s.mockStartWorkflowActivity()
+	s.workflowEnv.OnActivity(describeWorkflowActivityName, mock.Anything, mock.Anything, mock.Anything).Return(true, nil)
+	s.workflowEnv.OnActivity(cancelWorkflowActivityName, mock.Anything, mock.Anything, mock.Anything).Return(nil).Times(2)
+	s.deleteAfter(3 * time.Hour)
+
+	s.workflowEnv.ExecuteWorkflow(ScheduleWFTypeName, s.getParams(OverlapPolicyCancelOther))
+	s.True(s.workflowEnv.IsWorkflowCompleted())
+	s.NoError(s.workflowEnv.GetWorkflowError())
+	s.Len(*started, 3)
+}
+
+func (s *scheduleWorkflowTestSuite) TestWorkflow_Paused() {
+	started := s.mockStartWorkflowActivity()
+	s.workflowEnv.RegisterDelayedCallback(func() {
+		s.workflowEnv.SignalWorkflow(PauseSignalName, "test-notes")
+	}, 90*time.Minute)
+	s.workflowEnv.RegisterDelayedCallback(func() {
+		result, err := s.workflowEnv.QueryWorkflow(DescribeQueryType)
+		s.NoError(err)
+		var description ScheduleDescription
+		s.NoError(result.Get(&description))
+		s.True(description.Schedule.Paused)
+		s.Equal("test-notes", description.Schedule.Notes)
+		s.Equal(int64(1), description.State.TotalRuns)
+		s.True(description.NextRunTime.IsZero())
+	}, 150*time.Minute)
+	s.deleteAfter(3 * time.Hour)
+
+	s.workflowEnv.ExecuteWorkflow(ScheduleWFTypeName, s.getParams(OverlapPolicyAllowAll))
+	s.True(s.workflowEnv.IsWorkflowCompleted())
+	s.NoError(s.workflowEnv.GetWorkflowError())
+	s.Len(*started, 1)
+}
+
+func (s *scheduleWorkflowTestSuite) TestWorkflow_TriggerAndBackfill() {
+	started := s.mockStartWorkflowActivity()
+	s.workflowEnv.RegisterDelayedCallback(func() {
+		s.workflowEnv.SignalWorkflow(TriggerSignalName, TriggerRequest{})
+	}, 10*time.Minute)
+	s.workflowEnv.RegisterDelayedCallback(func() {
+		s.workflowEnv.SignalWorkflow(BackfillSignalName, BackfillRequest{
+			StartTime: time.Date(2021, 2, 28, 21, 0, 0, 0, time.UTC),
+			EndTime:   time.Date(2021, 2, 28, 23, 0, 0, 0, time.UTC),
+		})
+	}, 20*time.Minute)
+	s.deleteAfter(30 * time.Minute)
+
+	s.workflowEnv.ExecuteWorkflow(ScheduleWFTypeName, s.getParams(OverlapPolicyAllowAll))
+	s.True(s.workflowEnv.IsWorkflowCompleted())
+	s.NoError(s.workflowEnv.GetWorkflowError())
+	// one triggered run and the backfilled runs of 21:00, 22:00 and 23:00 of the previous day
+	s.Len(*started, 4)
+	s.Equal("test-schedule-2021-02-28T21:00:00Z", (*started)[1].WorkflowID)
+}
+
+func (s *scheduleWorkflowTestSuite) TestWorkflow_TriggeredAtSameTime() {
+	started := s.mockStartWorkflowActivity()
+	s.workflowEnv.RegisterDelayedCallback(func() {
+		s.workflowEnv.SignalWorkflow(TriggerSignalName, TriggerRequest{})
+		s.workflowEnv.SignalWorkflow(TriggerSignalName, TriggerRequest{})
+	}, 10*time.Minute)
+	s.deleteAfter(20 * time.Minute)
+
+	s.workflowEnv.ExecuteWorkflow(ScheduleWFTypeName, s.getParams(OverlapPolicyAllowAll))
+	s.True(s.workflowEnv.IsWorkflowCompleted())
+	s.NoError(s.workflowEnv.GetWorkflowError())
+	s.Len(*started, 2)
+	s.Equal("test-schedule-2021-03-01T00:10:30Z-trigger-1", (*started)[0].WorkflowID)
+	s.Equal("test-schedule-2021-03-01T00:10:30Z-trigger-2", (*started)[1].WorkflowID)
+}
+
+func (s *scheduleWorkflowTestSuite) TestWorkflow_TriggeredRunAlreadyStarted() {
+	s.workflowEnv.OnActivity(startWorkflowActivityName, mock.Anything, mock.Anything).
+		Return(WorkflowRun{}, cadence.NewCustomError(errReasonRunAlreadyStarted)).Once()
+	s.workflowEnv.RegisterDelayedCallback(func() {
+		s.workflowEnv.SignalWorkflow(TriggerSignalName, TriggerRequest{})
+	}, 10*time.Minute)
+	s.workflowEnv.RegisterDelayedCallback(func() {
+		result, err := s.workflowEnv.QueryWorkflow(DescribeQueryType)
+		s.NoError(err)
+		var description ScheduleDescription
+		s.NoError(result.Get(&description))
+		s.Empty(description.State.PendingRuns)
+		s.Equal(int64(1), description.State.SkippedRuns)
+		s.Equal(int64(0), description.State.TotalRuns)
+	}, 20*time.Minute)
+	s.deleteAfter(30 * time.Minute)
+
+	s.workflowEnv.ExecuteWorkflow(ScheduleWFTypeName, s.getParams(OverlapPolicyAllowAll))
+	s.True(s.workflowEnv.IsWorkflowCompleted())
+	s.NoError(s.workflowEnv.GetWorkflowError())
+}
+
+func (s *scheduleWorkflowTestSuite) TestWorkflow_UpdateDomainIgnored() {
+	s.mockStartWorkflowActivity()
+	s.workflowEnv.RegisterDelayedCallback(func() {
+		schedule := s.getParams(OverlapPolicyAllowAll).Schedule
+		schedule.Action.Domain = "other-domain"
+		schedule.Notes = "test-notes"
+		s.workflowEnv.SignalWorkflow(UpdateSignalName, schedule)
+	}, 10*time.Minute)
+	s.workflowEnv.RegisterDelayedCallback(func() {
+		result, err := s.workflowEnv.QueryWorkflow(DescribeQueryType)
+		s.NoError(err)
+		var description ScheduleDescription
+		s.NoError(result.Get(&description))
+		s.Equal("test-domain", description.Schedule.Action.Domain)
+		s.Empty(description.Schedule.Notes)
+	}, 20*time.Minute)
+	s.deleteAfter(30 * time.Minute)
+
+	s.workflowEnv.ExecuteWorkflow(ScheduleWFTypeName, s.getParams(OverlapPolicyAllowAll))
+	s.True(s.workflowEnv.IsWorkflowCompleted())
+	s.NoError(s.workflowEnv.GetWorkflowError())
+}
+
+func (s *scheduleWorkflowTestSuite) TestActivity_NotScheduleWorkflow() {
+	activityEnv := s.NewTestActivityEnvironment()
+	activityEnv.RegisterActivityWithOptions(StartWorkflowActivity, activity.RegisterOptions{Name: startWorkflowActivityName})
+	activityEnv.RegisterActivityWithOptions(CancelWorkflowActivity, activity.RegisterOptions{Name: cancelWorkflowActivityName})
+
+	_, err := activityEnv.ExecuteActivity(startWorkflowActivityName, startWorkflowRequest{
+		Action: s.getParams(OverlapPolicyAllowAll).Schedule.Action,
+	})
+	s.IsType(&cadence.CustomError{}, err)
+	s.Equal(errReasonNotScheduleWorkflow, err.(*cadence.CustomError).Reason())
+
+	_, err = activityEnv.ExecuteActivity(cancelWorkflowActivityName, "test-domain", WorkflowRun{WorkflowID: "test-workflow-id"})
+	s.IsType(&cadence.CustomError{}, err)
+}
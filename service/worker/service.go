@@ -43,6 +43,7 @@ import (
 	"github.com/uber/cadence/service/worker/esanalyzer"
 	"github.com/uber/cadence/service/worker/failovermanager"
 	"github.com/uber/cadence/service/worker/indexer"
+	"github.com/uber/cadence/service/worker/migration"
 	"github.com/uber/cadence/service/worker/parentclosepolicy"
 	"github.com/uber/cadence/service/worker/replicator"
 	"github.com/uber/cadence/service/worker/scanner"
@@ -50,6 +51,7 @@ import (
 	"github.com/uber/cadence/service/worker/scanner/shardscanner"
 	"github.com/uber/cadence/service/worker/scanner/tasklist"
 	"github.com/uber/cadence/service/worker/scanner/timers"
+	"github.com/uber/cadence/service/worker/scheduler"
 	"github.com/uber/cadence/service/worker/shadower"
 )
 
@@ -73,6 +75,7 @@ type (
 		IndexerCfg                        *indexer.Config
 		ScannerCfg                        *scanner.Config
 		BatcherCfg                        *batcher.Config
+		SchedulerCfg                      *scheduler.Config
 		ESAnalyzerCfg                     *esanalyzer.Config
 		failoverManagerCfg                *failovermanager.Config
 		ThrottledLogRPS                   dynamicconfig.IntPropertyFn
@@ -82,6 +85,7 @@ type (
 		EnableParentClosePolicyWorker     dynamicconfig.BoolPropertyFn
 		EnableFailoverManager             dynamicconfig.BoolPropertyFn
 		EnableWorkflowShadower            dynamicconfig.BoolPropertyFn
+		EnableScheduler                   dynamicconfig.BoolPropertyFn
 		DomainReplicationMaxRetryDuration dynamicconfig.DurationPropertyFn
 		EnableESAnalyzer                  dynamicconfig.BoolPropertyFn
 	}
@@ -139,10 +143,11 @@ func NewConfig(params *resource.Params) *Config {
 				EnableCleaning:           dc.GetBoolProperty(dynamicconfig.EnableCleaningOrphanTaskInTasklistScavenger, false),
 				MaxTasksPerJobFn:         dc.GetIntProperty(dynamicconfig.ScannerMaxTasksProcessedPerTasklistJob, tasklist.DefaultScannerMaxTasksProcessedPerTasklistJob),
 			},
-			Persistence:            &params.PersistenceConfig,
-			ClusterMetadata:        params.ClusterMetadata,
-			TaskListScannerEnabled: dc.GetBoolProperty(dynamicconfig.TaskListScannerEnabled, true),
-			HistoryScannerEnabled:  dc.GetBoolProperty(dynamicconfig.HistoryScannerEnabled, false),
+			Persistence:               &params.PersistenceConfig,
+			ClusterMetadata:           params.ClusterMetadata,
+			TaskListScannerEnabled:    dc.GetBoolProperty(dynamicconfig.TaskListScannerEnabled, true),
+			HistoryScannerEnabled:     dc.GetBoolProperty(dynamicconfig.HistoryScannerEnabled, false),
+			PartitionRetentionEnabled: dc.GetBoolProperty(dynamicconfig.PartitionRetentionEnabled, true),
 			ShardScanners: []*shardscanner.ScannerConfig{
 				executions.ConcreteExecutionScannerConfig(dc),
 				executions.CurrentExecutionScannerConfig(dc),
@@ -154,6 +159,9 @@ func NewConfig(params *resource.Params) *Config {
 			AdminOperationToken: dc.GetStringProperty(dynamicconfig.AdminOperationToken, common.DefaultAdminOperationToken),
 			ClusterMetadata:     params.ClusterMetadata,
 		},
+		SchedulerCfg: &scheduler.Config{
+			ClusterMetadata: params.ClusterMetadata,
+		},
 		failoverManagerCfg: &failovermanager.Config{
 			AdminOperationToken: dc.GetStringProperty(dynamicconfig.AdminOperationToken, common.DefaultAdminOperationToken),
 			ClusterMetadata:     params.ClusterMetadata,
@@ -174,6 +182,7 @@ func NewConfig(params *resource.Params) *Config {
 		EnableESAnalyzer:                  dc.GetBoolProperty(dynamicconfig.EnableESAnalyzer, false),
 		EnableFailoverManager:             dc.GetBoolProperty(dynamicconfig.EnableFailoverManager, true),
 		EnableWorkflowShadower:            dc.GetBoolProperty(dynamicconfig.EnableWorkflowShadower, true),
+		EnableScheduler:                   dc.GetBoolProperty(dynamicconfig.EnableScheduler, false),
 		ThrottledLogRPS:                   dc.GetIntProperty(dynamicconfig.WorkerThrottledLogRPS, 20),
 		PersistenceGlobalMaxQPS:           dc.GetIntProperty(dynamicconfig.WorkerPersistenceGlobalMaxQPS, 0),
 		PersistenceMaxQPS:                 dc.GetIntProperty(dynamicconfig.WorkerPersistenceMaxQPS, 500),
@@ -237,6 +246,13 @@ func (s *Service) Start() {
 		s.ensureDomainExists(common.ShadowerLocalDomainName)
 		s.startWorkflowShadower()
 	}
+	if s.config.EnableScheduler() {
+		s.ensureDomainExists(common.SchedulerLocalDomainName)
+		s.startScheduler()
+	}
+	if s.params.PersistenceConfig.IsMigrationConfigExist() {
+		s.startMigrator()
+	}
 
 	logger.Info("worker started", tag.ComponentWorker)
 	<-s.stopC
@@ -304,6 +320,20 @@ func (s *Service) startBatcher() {
 	}
 }
 
+func (s *Service) startScheduler() {
+	params := &scheduler.BootstrapParams{
+		Config:        *s.config.SchedulerCfg,
+		ServiceClient: s.params.PublicClient,
+		MetricsClient: s.GetMetricsClient(),
+		Logger:        s.GetLogger(),
+		TallyScope:    s.params.MetricScope,
+		ClientBean:    s.GetClientBean(),
+	}
+	if err := scheduler.New(params).Start(); err != nil {
+		s.GetLogger().Fatal("error starting scheduler", tag.Error(err))
+	}
+}
+
 func (s *Service) startScanner() {
 	params := &scanner.BootstrapParams{
 		Config:     *s.config.ScannerCfg,
@@ -406,6 +436,18 @@ func (s *Service) startWorkflowShadower() {
 	}
 }
 
+func (s *Service) startMigrator() {
+	params := &migration.BootstrapParams{
+		Resource:          s.Resource,
+		PersistenceConfig: s.params.PersistenceConfig,
+		TallyScope:        s.params.MetricScope,
+	}
+	if err := migration.New(params).Start(); err != nil {
+		s.Stop()
+		s.GetLogger().Fatal("error starting persistence migrator", tag.Error(err))
+	}
+}
+
 func (s *Service) ensureDomainExists(domain string) {
 	_, err := s.GetDomainManager().GetDomain(context.Background(), &persistence.GetDomainRequest{Name: domain})
 	switch err.(type) {
@@ -456,6 +498,8 @@ func getDomainID(domain string) string {
 		domainID = common.BatcherDomainID
 	case common.ShadowerLocalDomainName:
 		domainID = common.ShadowerDomainID
+	case common.SchedulerLocalDomainName:
+		domainID = common.SchedulerDomainID
 	}
 	return domainID
 }
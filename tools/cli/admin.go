@@ -58,6 +58,10 @@ func newAdminWorkflowCommands() []cli.Command {
 				cli.IntFlag{
 					Name:  FlagShardIDWithAlias,
 					Usage: "ShardID",
+				},
+				cli.BoolFlag{
+					Name:  FlagRedact,
+					Usage: "Mask all the payloads and search attributes, for sharing the history",
 				}),
 			Action: func(c *cli.Context) {
 				AdminShowWorkflow(c)
@@ -384,6 +388,24 @@ func newAdminDomainCommands() []cli.Command {
 				newDomainCLI(c, true).DescribeDomain(c)
 			},
 		},
+		{
+			Name:  "storage",
+			Usage: "Describe the storage used by the history and mutable state of each domain in the cluster",
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  FlagURL,
+					Usage: "URL of the Prometheus server scraping the metrics of the history hosts",
+				},
+				cli.StringFlag{
+					Name:  FlagMetricName,
+					Value: "domain_storage_bytes",
+					Usage: "Name of the domain storage gauge in Prometheus, including the prefix of the metrics if any",
+				},
+			},
+			Action: func(c *cli.Context) {
+				AdminDescribeDomainStorage(c)
+			},
+		},
 		{
 			Name:    "getdomainidorname",
 			Aliases: []string{"getdn"},
@@ -1357,3 +1379,51 @@ func newAdminConfigStoreCommands() []cli.Command {
 		},
 	}
 }
+
+func newAdminAuditCommands() []cli.Command {
+	return []cli.Command{
+		{
+			Name:    "list",
+			Aliases: []string{"l"},
+			Usage:   "List the audit events of mutating API calls from the audit queue in database, or from a file written by the file sink",
+			Flags: append(getDBFlags(),
+				cli.StringFlag{
+					Name:  FlagWorkflowIDWithAlias,
+					Usage: "WorkflowID",
+				},
+				cli.StringFlag{
+					Name:  FlagRunIDWithAlias,
+					Usage: "RunID",
+				},
+				cli.StringFlag{
+					Name:  FlagActor,
+					Usage: "Identity of the caller",
+				},
+				cli.StringFlag{
+					Name:  FlagAPIName,
+					Usage: "Name of the API, e.g. TerminateWorkflowExecution",
+				},
+				cli.StringFlag{
+					Name:  FlagEarliestTimeWithAlias,
+					Usage: "EarliestTime of the events. Supported formats are '2006-01-02T15:04:05+07:00', raw UnixNano and time range (N<duration>)",
+				},
+				cli.StringFlag{
+					Name:  FlagLatestTimeWithAlias,
+					Usage: "LatestTime of the events. Supported formats are '2006-01-02T15:04:05+07:00', raw UnixNano and time range (N<duration>)",
+				},
+				cli.IntFlag{
+					Name:  FlagMaxMessageCountWithAlias,
+					Usage: "Maximum number of the latest events to list, all the events are listed if not positive",
+					Value: 100,
+				},
+				cli.StringFlag{
+					Name:  FlagInputFileWithAlias,
+					Usage: "Read the events from the file written by the file sink instead of the database",
+				},
+			),
+			Action: func(c *cli.Context) {
+				AdminListAuditEvents(c)
+			},
+		},
+	}
+}
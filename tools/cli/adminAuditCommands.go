@@ -0,0 +1,152 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cli
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/urfave/cli"
+
+	"github.com/uber/cadence/common/audit"
+	"github.com/uber/cadence/common/log/loggerimpl"
+	"github.com/uber/cadence/common/persistence"
+	"github.com/uber/cadence/common/persistence/nosql"
+	"github.com/uber/cadence/common/persistence/sql"
+)
+
+// AdminListAuditEvents lists the audit events of mutating API calls
+func AdminListAuditEvents(c *cli.Context) {
+	filter := &audit.Filter{
+		Domain:     c.GlobalString(FlagDomain),
+		WorkflowID: c.String(FlagWorkflowID),
+		RunID:      c.String(FlagRunID),
+		Actor:      c.String(FlagActor),
+		API:        c.String(FlagAPIName),
+	}
+	if c.IsSet(FlagEarliestTime) {
+		filter.StartTime = time.Unix(0, parseTime(c.String(FlagEarliestTime), 0))
+	}
+	if c.IsSet(FlagLatestTime) {
+		filter.EndTime = time.Unix(0, parseTime(c.String(FlagLatestTime), 0))
+	}
+	maxCount := c.Int(FlagMaxMessageCount)
+
+	var events []*audit.Event
+	if c.IsSet(FlagInputFile) {
+		var err error
+		events, err = audit.ReadFile(c.String(FlagInputFile), filter)
+		if err != nil {
+			ErrorAndExit("Failed to read audit file", err)
+		}
+	} else {
+		events = readAuditQueue(c, filter, maxCount)
+	}
+	// show the latest events
+	if maxCount > 0 && len(events) > maxCount {
+		events = events[len(events)-maxCount:]
+	}
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetBorder(false)
+	table.SetColumnSeparator("|")
+	header := []string{"Time", "Actor", "API", "Domain", "Workflow ID", "Run ID", "Result", "Reason"}
+	headerColor := make([]tablewriter.Colors, len(header))
+	for i := range headerColor {
+		headerColor[i] = tableHeaderBlue
+	}
+	table.SetHeader(header)
+	table.SetHeaderColor(headerColor...)
+	for _, event := range events {
+		result := string(event.Result)
+		if event.Error != "" {
+			result = fmt.Sprintf("%v: %v", result, event.Error)
+		}
+		table.Append([]string{
+			convertTime(event.Timestamp.UnixNano(), false),
+			event.Actor,
+			event.API,
+			event.Domain,
+			event.WorkflowID,
+			event.RunID,
+			result,
+			event.Reason,
+		})
+	}
+	table.Render()
+}
+
+// readAuditQueue reads the latest maxCount events matching the filter, or all of them if maxCount is not positive
+func readAuditQueue(c *cli.Context, filter *audit.Filter, maxCount int) []*audit.Event {
+	queue := initializeAuditQueueManager(c)
+	defer queue.Close()
+
+	if maxCount > 0 {
+		ctx, cancel := newContext(c)
+		defer cancel()
+		events, err := audit.ReadQueueTail(ctx, queue, maxCount, defaultPageSize, filter)
+		if err != nil {
+			ErrorAndExit("Failed to read audit queue", err)
+		}
+		return events
+	}
+
+	var events []*audit.Event
+	lastMessageID := int64(-1)
+	for {
+		ctx, cancel := newContext(c)
+		page, nextMessageID, err := audit.ReadQueue(ctx, queue, lastMessageID, defaultPageSize, filter)
+		cancel()
+		if err != nil {
+			ErrorAndExit("Failed to read audit queue", err)
+		}
+		events = append(events, page...)
+		if nextMessageID == lastMessageID {
+			break
+		}
+		lastMessageID = nextMessageID
+	}
+	return events
+}
+
+func initializeAuditQueueManager(c *cli.Context) persistence.QueueManager {
+	dbType := c.String(FlagDBType)
+	if !isDBTypeSupported(dbType) {
+		supportedDBs := append(sql.GetRegisteredPluginNames(), "cassandra")
+		ErrorAndExit(fmt.Sprintf("The DB type is not supported. Options are: %s.", supportedDBs), nil)
+	}
+	logger := loggerimpl.NewNopLogger()
+	var store persistence.Queue
+	var err error
+	switch dbType {
+	case "cassandra":
+		db, _ := connectToCassandra(c)
+		store, err = nosql.NewNoSQLQueueStoreFromSession(db, logger, persistence.AuditQueueType)
+	default:
+		store, err = sql.NewSQLQueueStore(connectToSQL(c), logger, persistence.AuditQueueType)
+	}
+	if err != nil {
+		ErrorAndExit("Failed to get audit queue", err)
+	}
+	return persistence.NewQueueManager(store)
+}
@@ -23,11 +23,15 @@ package cli
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"net"
+	"net/http"
+	"net/url"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/olekukonko/tablewriter"
@@ -45,6 +49,7 @@ import (
 	cassandra_db "github.com/uber/cadence/common/persistence/nosql/nosqlplugin/cassandra"
 	"github.com/uber/cadence/common/persistence/sql"
 	"github.com/uber/cadence/common/persistence/sql/sqlplugin"
+	"github.com/uber/cadence/common/redaction"
 	"github.com/uber/cadence/common/types"
 	"github.com/uber/cadence/common/types/mapper/thrift"
 	"github.com/uber/cadence/tools/common/flag"
@@ -97,6 +102,10 @@ func AdminShowWorkflow(c *cli.Context) {
 	}
 	allEvents := &shared.History{}
 	totalSize := 0
+	var redactor *redaction.Redactor
+	if c.Bool(FlagRedact) {
+		redactor = redaction.NewFullRedactor()
+	}
 	for idx, b := range history {
 		totalSize += len(b.Data)
 		fmt.Printf("======== batch %v, blob len: %v ======\n", idx+1, len(b.Data))
@@ -104,6 +113,9 @@ func AdminShowWorkflow(c *cli.Context) {
 		if err != nil {
 			ErrorAndExit("DeserializeBatchEvents err", err)
 		}
+		if redactor != nil {
+			redactor.RedactHistory(&types.History{Events: internalHistoryBatch})
+		}
 		historyBatch := thrift.FromHistoryEventArray(internalHistoryBatch)
 		allEvents.Events = append(allEvents.Events, historyBatch...)
 		for _, e := range historyBatch {
@@ -665,3 +677,55 @@ func AdminDescribeQueue(c *cli.Context) {
 		fmt.Println(state)
 	}
 }
+
+// AdminDescribeDomainStorage describes the storage used by each domain in the cluster. The history hosts report the
+// storage of the domains in the shards they own as a gauge, the totals are read from the Prometheus server scraping them
+func AdminDescribeDomainStorage(c *cli.Context) {
+	prometheusURL := getRequiredOption(c, FlagURL)
+	query := fmt.Sprintf("sum by (domain) (%v)", c.String(FlagMetricName))
+
+	ctx, cancel := newContext(c)
+	defer cancel()
+	req, err := http.NewRequest(http.MethodGet, strings.TrimSuffix(prometheusURL, "/")+"/api/v1/query", nil)
+	if err != nil {
+		ErrorAndExit("Failed to create the Prometheus query", err)
+	}
+	req.URL.RawQuery = url.Values{"query": []string{query}}.Encode()
+	resp, err := http.DefaultClient.Do(req.WithContext(ctx))
+	if err != nil {
+		ErrorAndExit("Failed to query Prometheus", err)
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		Status string `json:"status"`
+		Error  string `json:"error"`
+		Data   struct {
+			Result []struct {
+				Metric map[string]string `json:"metric"`
+				// the time of the sample and its value as a string
+				Value []interface{} `json:"value"`
+			} `json:"result"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		ErrorAndExit("Failed to decode the Prometheus response", err)
+	}
+	if result.Status != "success" {
+		ErrorAndExit(fmt.Sprintf("Prometheus query %v failed", query), errors.New(result.Error))
+	}
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetBorder(false)
+	table.SetColumnSeparator("|")
+	table.SetHeader([]string{"Domain", "Bytes"})
+	table.SetHeaderColor(tableHeaderBlue, tableHeaderBlue)
+	table.SetHeaderLine(false)
+	for _, sample := range result.Data.Result {
+		if len(sample.Value) != 2 {
+			continue
+		}
+		table.Append([]string{sample.Metric["domain"], fmt.Sprintf("%v", sample.Value[1])})
+	}
+	table.Render()
+}
@@ -74,6 +74,11 @@ func NewCliApp() *cli.App {
 			Usage:  "optional private key path to create JWT. Either this or --jwt is needed for jwt authorization. --jwt flag has priority over this one if both provided",
 			EnvVar: "CADENCE_CLI_JWT_PRIVATE_KEY",
 		},
+		cli.StringFlag{
+			Name:   FlagAPIKey,
+			Usage:  "optional domain API key for authorization",
+			EnvVar: "CADENCE_CLI_API_KEY",
+		},
 	}
 	app.Commands = []cli.Command{
 		{
@@ -171,6 +176,12 @@ func NewCliApp() *cli.App {
 					Usage:       "Run admin operation on config store",
 					Subcommands: newAdminConfigStoreCommands(),
 				},
+				{
+					Name:        "audit",
+					Aliases:     []string{"au"},
+					Usage:       "Run admin operation on audit log",
+					Subcommands: newAdminAuditCommands(),
+				},
 			},
 		},
 		{
@@ -179,6 +190,12 @@ func NewCliApp() *cli.App {
 			Usage:       "Operate cadence cluster",
 			Subcommands: newClusterCommands(),
 		},
+		{
+			Name:        "schedule",
+			Aliases:     []string{"sch"},
+			Usage:       "Operate cadence schedule",
+			Subcommands: newScheduleCommands(),
+		},
 	}
 
 	// set builder if not customized
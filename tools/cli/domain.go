@@ -83,5 +83,42 @@ func newDomainCommands() []cli.Command {
 				newDomainCLI(c, false).DescribeDomain(c)
 			},
 		},
+		{
+			Name:        "apikey",
+			Aliases:     []string{"ak"},
+			Usage:       "Manage the API keys of workflow domain",
+			Subcommands: newDomainAPIKeyCommands(),
+		},
+	}
+}
+
+func newDomainAPIKeyCommands() []cli.Command {
+	return []cli.Command{
+		{
+			Name:    "create",
+			Aliases: []string{"c"},
+			Usage:   "Create an API key of workflow domain, the key is only printed once",
+			Flags:   createDomainAPIKeyFlags,
+			Action: func(c *cli.Context) {
+				newDomainCLI(c, false).CreateAPIKey(c)
+			},
+		},
+		{
+			Name:    "list",
+			Aliases: []string{"l"},
+			Usage:   "List the API keys of workflow domain",
+			Action: func(c *cli.Context) {
+				newDomainCLI(c, false).ListAPIKeys(c)
+			},
+		},
+		{
+			Name:    "revoke",
+			Aliases: []string{"r"},
+			Usage:   "Revoke an API key of workflow domain",
+			Flags:   revokeDomainAPIKeyFlags,
+			Action: func(c *cli.Context) {
+				newDomainCLI(c, false).RevokeAPIKey(c)
+			},
+		},
 	}
 }
@@ -0,0 +1,119 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/urfave/cli"
+
+	"github.com/uber/cadence/common"
+	"github.com/uber/cadence/common/authorization"
+	"github.com/uber/cadence/common/types"
+)
+
+// CreateAPIKey creates an API key of a domain and prints it
+func (d *domainCLIImpl) CreateAPIKey(c *cli.Context) {
+	domainName := getRequiredGlobalOption(c, FlagDomain)
+
+	key, apiKey, err := authorization.NewAPIKey(c.String(FlagPermission), c.String(FlagDescription), time.Now())
+	if err != nil {
+		ErrorAndExit("Failed to create API key.", err)
+	}
+	data, err := json.Marshal(apiKey)
+	if err != nil {
+		ErrorAndExit("Failed to encode API key.", err)
+	}
+	// the key is added to the API keys of the domain by the server, within the domain update
+	d.updateAPIKeys(c, domainName, common.DomainDataKeyForCreateAPIKey, string(data))
+
+	fmt.Printf("API key %s created for domain %s, it will not be shown again:\n%s\n", apiKey.ID, domainName, key)
+}
+
+// ListAPIKeys lists the API keys of a domain
+func (d *domainCLIImpl) ListAPIKeys(c *cli.Context) {
+	domainName := getRequiredGlobalOption(c, FlagDomain)
+	apiKeys := d.getAPIKeys(c, domainName)
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetBorder(false)
+	table.SetColumnSeparator("|")
+	table.SetHeader([]string{"ID", "Permission", "Description", "Created Time"})
+	table.SetHeaderColor(tableHeaderBlue, tableHeaderBlue, tableHeaderBlue, tableHeaderBlue)
+	for _, apiKey := range apiKeys {
+		table.Append([]string{apiKey.ID, apiKey.Permission, apiKey.Description, convertTime(apiKey.CreatedTime.UnixNano(), false)})
+	}
+	table.Render()
+}
+
+// RevokeAPIKey removes an API key from a domain
+func (d *domainCLIImpl) RevokeAPIKey(c *cli.Context) {
+	domainName := getRequiredGlobalOption(c, FlagDomain)
+	keyID := getRequiredOption(c, FlagKeyID)
+
+	// the key is removed from the API keys of the domain by the server, within the domain update
+	d.updateAPIKeys(c, domainName, common.DomainDataKeyForRevokeAPIKey, keyID)
+
+	fmt.Printf("API key %s of domain %s successfully revoked.\n", keyID, domainName)
+}
+
+func (d *domainCLIImpl) getAPIKeys(c *cli.Context, domainName string) []authorization.APIKey {
+	ctx, cancel := newContext(c)
+	defer cancel()
+
+	resp, err := d.describeDomain(ctx, &types.DescribeDomainRequest{
+		Name: common.StringPtr(domainName),
+	})
+	if err != nil {
+		if _, ok := err.(*types.EntityNotExistsError); !ok {
+			ErrorAndExit("Operation DescribeDomain failed.", err)
+		}
+		ErrorAndExit(fmt.Sprintf("Domain %s does not exist.", domainName), err)
+	}
+
+	apiKeys, err := authorization.ParseAPIKeys(resp.DomainInfo.GetData())
+	if err != nil {
+		ErrorAndExit(fmt.Sprintf("Domain %s has invalid API keys.", domainName), err)
+	}
+	return apiKeys
+}
+
+// updateAPIKeys sends the API key operation, one of the reserved domain data keys, in an UpdateDomain request
+func (d *domainCLIImpl) updateAPIKeys(c *cli.Context, domainName string, operation string, value string) {
+	ctx, cancel := newContext(c)
+	defer cancel()
+
+	_, err := d.updateDomain(ctx, &types.UpdateDomainRequest{
+		Name:          domainName,
+		SecurityToken: c.String(FlagSecurityToken),
+		Data:          map[string]string{operation: value},
+	})
+	if err != nil {
+		if _, ok := err.(*types.EntityNotExistsError); ok && operation == common.DomainDataKeyForRevokeAPIKey {
+			ErrorAndExit(fmt.Sprintf("API key %s does not exist in domain %s.", value, domainName), err)
+		}
+		ErrorAndExit("Operation UpdateDomain failed.", err)
+	}
+}
@@ -197,6 +197,33 @@ var (
 		},
 	}
 
+	createDomainAPIKeyFlags = []cli.Flag{
+		cli.StringFlag{
+			Name:  FlagPermission,
+			Value: "read",
+			Usage: "Permission granted to the key: read, write or admin",
+		},
+		cli.StringFlag{
+			Name:  FlagDescription,
+			Usage: "Description of the key",
+		},
+		cli.StringFlag{
+			Name:  FlagSecurityTokenWithAlias,
+			Usage: "Optional token for security check",
+		},
+	}
+
+	revokeDomainAPIKeyFlags = []cli.Flag{
+		cli.StringFlag{
+			Name:  FlagKeyID,
+			Usage: "ID of the key to revoke",
+		},
+		cli.StringFlag{
+			Name:  FlagSecurityTokenWithAlias,
+			Usage: "Optional token for security check",
+		},
+	}
+
 	adminDomainCommonFlags = []cli.Flag{
 		cli.StringFlag{
 			Name:  FlagServiceConfigDirWithAlias,
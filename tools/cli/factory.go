@@ -50,6 +50,8 @@ type ContextKey string
 const (
 	// CtxKeyJWT is the name of the context key for the JWT
 	CtxKeyJWT = ContextKey("ctxKeyJWT")
+	// CtxKeyAPIKey is the name of the context key for the domain API key
+	CtxKeyAPIKey = ContextKey("ctxKeyAPIKey")
 )
 
 // ClientFactory is used to construct rpc clients
@@ -136,6 +138,9 @@ func (vm *versionMiddleware) Call(ctx context.Context, request *transport.Reques
 	if jwtKey, ok := ctx.Value(CtxKeyJWT).(string); ok {
 		request.Headers = request.Headers.With(common.AuthorizationTokenHeaderName, jwtKey)
 	}
+	if apiKey, ok := ctx.Value(CtxKeyAPIKey).(string); ok && apiKey != "" {
+		request.Headers = request.Headers.With(common.APIKeyHeaderName, apiKey)
+	}
 	return out.Call(ctx, request)
 }
 
@@ -66,6 +66,8 @@ const (
 	FlagTaskListTypeWithAlias             = FlagTaskListType + ", tlt"
 	FlagWorkflowIDReusePolicy             = "workflowidreusepolicy"
 	FlagWorkflowIDReusePolicyAlias        = FlagWorkflowIDReusePolicy + ", wrp"
+	FlagWorkflowIDConflictPolicy          = "workflowidconflictpolicy"
+	FlagWorkflowIDConflictPolicyAlias     = FlagWorkflowIDConflictPolicy + ", wcp"
 	FlagCronSchedule                      = "cron"
 	FlagWorkflowType                      = "workflow_type"
 	FlagWorkflowTypeWithAlias             = FlagWorkflowType + ", wt"
@@ -191,6 +193,7 @@ const (
 	FlagMessageType                       = "message_type"
 	FlagMessageTypeWithAlias              = FlagMessageType + ", mt"
 	FlagURL                               = "url"
+	FlagMetricName                        = "metric_name"
 	FlagMuttleyDestination                = "muttely_destination"
 	FlagMuttleyDestinationWithAlias       = FlagMuttleyDestination + ", muttley"
 	FlagIndex                             = "index"
@@ -206,8 +209,10 @@ const (
 	FlagRemoveBadBinary                   = "remove_bad_binary"
 	FlagResetType                         = "reset_type"
 	FlagResetPointsOnly                   = "reset_points_only"
+	FlagRedact                            = "redact"
 	FlagResetBadBinaryChecksum            = "reset_bad_binary_checksum"
 	FlagSkipSignalReapply                 = "skip_signal_reapply"
+	FlagPreservePendingActivityResults    = "preserve_pending_activity_results"
 	FlagListQuery                         = "query"
 	FlagListQueryWithAlias                = FlagListQuery + ", q"
 	FlagBatchType                         = "batch_type"
@@ -279,9 +284,24 @@ const (
 	FlagJWT                               = "jwt"
 	FlagJWTPrivateKey                     = "jwt-private-key"
 	FlagJWTPrivateKeyWithAlias            = FlagJWTPrivateKey + ", jwt-pk"
+	FlagAPIKey                            = "api_key"
+	FlagPermission                        = "permission"
+	FlagKeyID                             = "key_id"
 	FlagDynamicConfigName                 = "dynamic_config_name"
 	FlagDynamicConfigFilter               = "dynamic_config_filter"
 	FlagDynamicConfigValue                = "dynamic_config_value"
+	FlagActor                             = "actor"
+	FlagAPIName                           = "api"
+	FlagScheduleID                        = "schedule_id"
+	FlagScheduleIDWithAlias               = FlagScheduleID + ", sid"
+	FlagTimezone                          = "timezone"
+	FlagTimezoneWithAlias                 = FlagTimezone + ", tz"
+	FlagJitter                            = "jitter"
+	FlagCatchupWindow                     = "catchup_window"
+	FlagOverlapPolicy                     = "overlap_policy"
+	FlagOverlapPolicyWithAlias            = FlagOverlapPolicy + ", op"
+	FlagWorkflowIDPrefix                  = "workflow_id_prefix"
+	FlagNotes                             = "notes"
 )
 
 var flagsForExecution = []cli.Flag{
@@ -345,6 +365,10 @@ func getFlagsForShowID() []cli.Flag {
 			Name:  FlagResetPointsOnly,
 			Usage: "Only show events that are eligible for reset",
 		},
+		cli.BoolFlag{
+			Name:  FlagRedact,
+			Usage: "Mask all the payloads and search attributes, for sharing the history",
+		},
 	}
 }
 
@@ -387,6 +411,11 @@ func getFlagsForStart() []cli.Flag {
 			Usage: "Optional input to configure if the same workflow ID is allow to use for new workflow execution. " +
 				"Available options: 0: AllowDuplicateFailedOnly, 1: AllowDuplicate, 2: RejectDuplicate, 3:TerminateIfRunning",
 		},
+		cli.IntFlag{
+			Name: FlagWorkflowIDConflictPolicyAlias,
+			Usage: "Optional input to configure what to do if a workflow with the same workflow ID is still running. " +
+				"Available options: 0: Fail, 1: UseExisting, 2: TerminateExisting",
+		},
 		cli.StringFlag{
 			Name:  FlagInputWithAlias,
 			Usage: "Optional input for the workflow, in JSON format. If there are multiple parameters, concatenate them and separate by space.",
@@ -527,6 +556,79 @@ func getFlagsForTerminate() []cli.Flag {
 	})
 }
 
+func getFlagsForPause() []cli.Flag {
+	return append(flagsForExecution, cli.StringFlag{
+		Name:  FlagReasonWithAlias,
+		Usage: "The reason you want to pause or unpause the workflow",
+	})
+}
+
+func getFlagsForSchedule() []cli.Flag {
+	return []cli.Flag{
+		cli.StringFlag{
+			Name:  FlagScheduleIDWithAlias,
+			Usage: "ScheduleID, unique within the domain",
+		},
+	}
+}
+
+func getFlagsForScheduleSpec() []cli.Flag {
+	return append(getFlagsForSchedule(),
+		cli.StringFlag{
+			Name:  FlagCronSchedule,
+			Usage: "Cron expression of the times the schedule runs at, in standard cron format",
+		},
+		cli.StringFlag{
+			Name:  FlagTimezoneWithAlias,
+			Usage: "Optional IANA timezone the cron expression is evaluated in, e.g. America/New_York. Default to UTC",
+		},
+		cli.StringFlag{
+			Name:  FlagJitter,
+			Usage: "Optional maximum random delay added to each run, e.g. 30s",
+		},
+		cli.StringFlag{
+			Name:  FlagCatchupWindow,
+			Usage: "Optional duration within which a late run is still started, e.g. 1h. Default to 1h",
+		},
+		cli.StringFlag{
+			Name:  FlagOverlapPolicyWithAlias,
+			Usage: "Optional policy for a run while the workflow of the previous run is still running. Available options: skip(default), buffer, cancel-other, allow-all",
+		},
+		cli.StringFlag{
+			Name:  FlagWorkflowIDPrefix,
+			Usage: "Optional prefix of the IDs of the started workflows, which are suffixed by the time of the run. Default to the schedule ID",
+		},
+		cli.StringFlag{
+			Name:  FlagWorkflowTypeWithAlias,
+			Usage: "WorkflowTypeName of the started workflows",
+		},
+		cli.StringFlag{
+			Name:  FlagTaskListWithAlias,
+			Usage: "TaskList of the started workflows",
+		},
+		cli.IntFlag{
+			Name:  FlagExecutionTimeoutWithAlias,
+			Usage: "Optional execution start to close timeout in seconds of the started workflows",
+		},
+		cli.IntFlag{
+			Name:  FlagDecisionTimeoutWithAlias,
+			Usage: "Optional decision task start to close timeout in seconds of the started workflows",
+		},
+		cli.StringFlag{
+			Name:  FlagInputWithAlias,
+			Usage: "Optional input for the started workflows, in JSON format. If there are multiple parameters, concatenate them and separate by space.",
+		},
+		cli.StringFlag{
+			Name:  FlagInputFileWithAlias,
+			Usage: "Optional input for the started workflows from JSON file. If there are multiple JSON, concatenate them and separate by space or newline.",
+		},
+		cli.StringFlag{
+			Name:  FlagNotes,
+			Usage: "Optional notes of the schedule",
+		},
+	)
+}
+
 func getCommonFlagsForVisibility() []cli.Flag {
 	return []cli.Flag{
 		cli.BoolFlag{
@@ -716,6 +818,10 @@ func getFlagsForDescribeID() []cli.Flag {
 			Name:  FlagResetPointsOnly,
 			Usage: "Only show auto-reset points",
 		},
+		cli.BoolFlag{
+			Name:  FlagRedact,
+			Usage: "Mask all the payloads and search attributes, for sharing the workflow details",
+		},
 	}
 }
 
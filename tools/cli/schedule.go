@@ -0,0 +1,127 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cli
+
+import "github.com/urfave/cli"
+
+func newScheduleCommands() []cli.Command {
+	return []cli.Command{
+		{
+			Name:    "create",
+			Aliases: []string{"c"},
+			Usage:   "Create a schedule starting a workflow at the times of a cron expression",
+			Flags:   getFlagsForScheduleSpec(),
+			Action: func(c *cli.Context) {
+				CreateSchedule(c)
+			},
+		},
+		{
+			Name:    "describe",
+			Aliases: []string{"desc"},
+			Usage:   "Describe the spec, the action and the recent runs of a schedule",
+			Flags:   getFlagsForSchedule(),
+			Action: func(c *cli.Context) {
+				DescribeSchedule(c)
+			},
+		},
+		{
+			Name:    "update",
+			Aliases: []string{"u"},
+			Usage:   "Update a schedule, only the provided flags are changed",
+			Flags:   getFlagsForScheduleSpec(),
+			Action: func(c *cli.Context) {
+				UpdateSchedule(c)
+			},
+		},
+		{
+			Name:  "pause",
+			Usage: "Pause a schedule, the runs missed while paused are not caught up",
+			Flags: append(getFlagsForSchedule(), cli.StringFlag{
+				Name:  FlagNotes,
+				Usage: "Optional notes of the pause",
+			}),
+			Action: func(c *cli.Context) {
+				PauseSchedule(c)
+			},
+		},
+		{
+			Name:  "unpause",
+			Usage: "Unpause a schedule",
+			Flags: append(getFlagsForSchedule(), cli.StringFlag{
+				Name:  FlagNotes,
+				Usage: "Optional notes of the unpause",
+			}),
+			Action: func(c *cli.Context) {
+				UnpauseSchedule(c)
+			},
+		},
+		{
+			Name:    "trigger",
+			Aliases: []string{"t"},
+			Usage:   "Run a schedule immediately",
+			Flags: append(getFlagsForSchedule(), cli.StringFlag{
+				Name:  FlagOverlapPolicyWithAlias,
+				Usage: "Optional overlap policy of the run. Default to the overlap policy of the schedule",
+			}),
+			Action: func(c *cli.Context) {
+				TriggerSchedule(c)
+			},
+		},
+		{
+			Name:    "backfill",
+			Aliases: []string{"b"},
+			Usage:   "Run a schedule for each of its times in a past window",
+			Flags: append(getFlagsForSchedule(),
+				cli.StringFlag{
+					Name:  FlagStartDate,
+					Usage: "Start of the window (inclusive), in RFC3339 format",
+				},
+				cli.StringFlag{
+					Name:  FlagEndDate,
+					Usage: "End of the window (inclusive), in RFC3339 format",
+				},
+				cli.StringFlag{
+					Name:  FlagOverlapPolicyWithAlias,
+					Usage: "Optional overlap policy of the runs. Default to the overlap policy of the schedule",
+				},
+			),
+			Action: func(c *cli.Context) {
+				BackfillSchedule(c)
+			},
+		},
+		{
+			Name:  "delete",
+			Usage: "Delete a schedule, the started workflows are not affected",
+			Flags: getFlagsForSchedule(),
+			Action: func(c *cli.Context) {
+				DeleteSchedule(c)
+			},
+		},
+		{
+			Name:    "list",
+			Aliases: []string{"l"},
+			Usage:   "List the schedules of the domain",
+			Action: func(c *cli.Context) {
+				ListSchedules(c)
+			},
+		},
+	}
+}
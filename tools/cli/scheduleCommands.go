@@ -0,0 +1,281 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cli
+
+import (
+	"strings"
+	"time"
+
+	"github.com/urfave/cli"
+	"go.uber.org/cadence/.gen/go/shared"
+	cclient "go.uber.org/cadence/client"
+
+	"github.com/uber/cadence/common"
+	"github.com/uber/cadence/service/worker/scheduler"
+)
+
+// CreateSchedule creates a schedule
+func CreateSchedule(c *cli.Context) {
+	domain := getRequiredGlobalOption(c, FlagDomain)
+	scheduleID := getRequiredOption(c, FlagScheduleID)
+	schedule := scheduler.Schedule{
+		Spec: scheduler.ScheduleSpec{
+			CronExpression: getRequiredOption(c, FlagCronSchedule),
+		},
+		Action: scheduler.ScheduleAction{
+			Domain:       domain,
+			WorkflowType: getRequiredOption(c, FlagWorkflowType),
+			TaskList:     getRequiredOption(c, FlagTaskList),
+		},
+	}
+	applyScheduleFlags(c, &schedule)
+	if err := scheduler.ValidateSchedule(&schedule); err != nil {
+		ErrorAndExit("Invalid schedule", err)
+	}
+
+	client := getSchedulerClient(c)
+	tcCtx, cancel := newContext(c)
+	defer cancel()
+	options := cclient.StartWorkflowOptions{
+		ID:                           scheduler.GetScheduleWorkflowID(domain, scheduleID),
+		TaskList:                     scheduler.SchedulerTaskListName,
+		ExecutionStartToCloseTimeout: scheduler.InfiniteDuration,
+		// a deleted schedule can be created again
+		WorkflowIDReusePolicy: cclient.WorkflowIDReusePolicyAllowDuplicate,
+	}
+	params := scheduler.ScheduleParams{
+		ScheduleID: scheduleID,
+		Schedule:   schedule,
+	}
+	if _, err := client.StartWorkflow(tcCtx, options, scheduler.ScheduleWFTypeName, params); err != nil {
+		ErrorAndExit("Failed to create schedule", err)
+	}
+	output := map[string]interface{}{
+		"msg":        "schedule is created",
+		"scheduleID": scheduleID,
+	}
+	prettyPrintJSONObject(output)
+}
+
+// DescribeSchedule describes a schedule
+func DescribeSchedule(c *cli.Context) {
+	prettyPrintJSONObject(describeSchedule(c))
+}
+
+// UpdateSchedule updates the provided fields of a schedule
+func UpdateSchedule(c *cli.Context) {
+	description := describeSchedule(c)
+	schedule := description.Schedule
+	if c.IsSet(FlagCronSchedule) {
+		schedule.Spec.CronExpression = c.String(FlagCronSchedule)
+	}
+	if c.IsSet(FlagWorkflowType) {
+		schedule.Action.WorkflowType = c.String(FlagWorkflowType)
+	}
+	if c.IsSet(FlagTaskList) {
+		schedule.Action.TaskList = c.String(FlagTaskList)
+	}
+	applyScheduleFlags(c, &schedule)
+	if err := scheduler.ValidateSchedule(&schedule); err != nil {
+		ErrorAndExit("Invalid schedule", err)
+	}
+	signalSchedule(c, scheduler.UpdateSignalName, schedule)
+	prettyPrintJSONObject(map[string]interface{}{"msg": "schedule is updated"})
+}
+
+// PauseSchedule pauses a schedule
+func PauseSchedule(c *cli.Context) {
+	signalSchedule(c, scheduler.PauseSignalName, c.String(FlagNotes))
+	prettyPrintJSONObject(map[string]interface{}{"msg": "schedule is paused"})
+}
+
+// UnpauseSchedule unpauses a schedule
+func UnpauseSchedule(c *cli.Context) {
+	signalSchedule(c, scheduler.UnpauseSignalName, c.String(FlagNotes))
+	prettyPrintJSONObject(map[string]interface{}{"msg": "schedule is unpaused"})
+}
+
+// TriggerSchedule runs a schedule immediately
+func TriggerSchedule(c *cli.Context) {
+	request := scheduler.TriggerRequest{
+		OverlapPolicy: getOverlapPolicy(c),
+	}
+	signalSchedule(c, scheduler.TriggerSignalName, request)
+	prettyPrintJSONObject(map[string]interface{}{"msg": "schedule is triggered"})
+}
+
+// BackfillSchedule runs a schedule for each of its times in a past window
+func BackfillSchedule(c *cli.Context) {
+	startTime, err := time.Parse(time.RFC3339, getRequiredOption(c, FlagStartDate))
+	if err != nil {
+		ErrorAndExit("wrong date format for "+FlagStartDate, err)
+	}
+	endTime, err := time.Parse(time.RFC3339, getRequiredOption(c, FlagEndDate))
+	if err != nil {
+		ErrorAndExit("wrong date format for "+FlagEndDate, err)
+	}
+	if endTime.Before(startTime) {
+		ErrorAndExit(FlagEndDate+" is before "+FlagStartDate, nil)
+	}
+	request := scheduler.BackfillRequest{
+		StartTime:     startTime,
+		EndTime:       endTime,
+		OverlapPolicy: getOverlapPolicy(c),
+	}
+	signalSchedule(c, scheduler.BackfillSignalName, request)
+	prettyPrintJSONObject(map[string]interface{}{"msg": "schedule backfill is requested"})
+}
+
+// DeleteSchedule deletes a schedule
+func DeleteSchedule(c *cli.Context) {
+	signalSchedule(c, scheduler.DeleteSignalName, nil)
+	prettyPrintJSONObject(map[string]interface{}{"msg": "schedule is deleted"})
+}
+
+// ListSchedules lists the schedules of a domain
+func ListSchedules(c *cli.Context) {
+	domain := getRequiredGlobalOption(c, FlagDomain)
+	prefix := scheduler.GetScheduleWorkflowIDPrefix(domain)
+
+	client := getSchedulerClient(c)
+	output := make([]interface{}, 0)
+	var nextPageToken []byte
+	for {
+		tcCtx, cancel := newContext(c)
+		resp, err := client.ListOpenWorkflow(tcCtx, &shared.ListOpenWorkflowExecutionsRequest{
+			Domain: common.StringPtr(common.SchedulerLocalDomainName),
+			StartTimeFilter: &shared.StartTimeFilter{
+				EarliestTime: common.Int64Ptr(0),
+				LatestTime:   common.Int64Ptr(time.Now().UnixNano()),
+			},
+			TypeFilter: &shared.WorkflowTypeFilter{
+				Name: common.StringPtr(scheduler.ScheduleWFTypeName),
+			},
+			NextPageToken: nextPageToken,
+		})
+		cancel()
+		if err != nil {
+			ErrorAndExit("Failed to list schedules", err)
+		}
+		for _, wf := range resp.Executions {
+			workflowID := wf.Execution.GetWorkflowId()
+			if !strings.HasPrefix(workflowID, prefix) {
+				continue
+			}
+			output = append(output, map[string]string{
+				"scheduleID": strings.TrimPrefix(workflowID, prefix),
+				"createTime": convertTime(wf.GetStartTime(), false),
+			})
+		}
+		nextPageToken = resp.NextPageToken
+		if len(nextPageToken) == 0 {
+			break
+		}
+	}
+	prettyPrintJSONObject(output)
+}
+
+func applyScheduleFlags(c *cli.Context, schedule *scheduler.Schedule) {
+	if c.IsSet(FlagTimezone) {
+		schedule.Spec.Timezone = c.String(FlagTimezone)
+	}
+	if c.IsSet(FlagJitter) {
+		schedule.Spec.Jitter = getDurationOption(c, FlagJitter)
+	}
+	if c.IsSet(FlagCatchupWindow) {
+		schedule.Spec.CatchupWindow = getDurationOption(c, FlagCatchupWindow)
+	}
+	if c.IsSet(FlagOverlapPolicy) {
+		schedule.Spec.OverlapPolicy = getOverlapPolicy(c)
+	}
+	if c.IsSet(FlagWorkflowIDPrefix) {
+		schedule.Action.WorkflowIDPrefix = c.String(FlagWorkflowIDPrefix)
+	}
+	if c.IsSet(FlagExecutionTimeout) {
+		schedule.Action.ExecutionStartToCloseTimeout = time.Duration(c.Int(FlagExecutionTimeout)) * time.Second
+	}
+	if c.IsSet(FlagDecisionTimeout) {
+		schedule.Action.TaskStartToCloseTimeout = time.Duration(c.Int(FlagDecisionTimeout)) * time.Second
+	}
+	if c.IsSet(FlagInput) || c.IsSet(FlagInputFile) {
+		schedule.Action.Input = []byte(processJSONInput(c))
+	}
+	if c.IsSet(FlagNotes) {
+		schedule.Notes = c.String(FlagNotes)
+	}
+}
+
+func getDurationOption(c *cli.Context, optionName string) time.Duration {
+	duration, err := time.ParseDuration(c.String(optionName))
+	if err != nil {
+		ErrorAndExit("Invalid duration for "+optionName, err)
+	}
+	return duration
+}
+
+func getOverlapPolicy(c *cli.Context) scheduler.OverlapPolicy {
+	policy := scheduler.OverlapPolicy(c.String(FlagOverlapPolicy))
+	if policy == "" {
+		return policy
+	}
+	for _, p := range scheduler.AllOverlapPolicies {
+		if p == policy {
+			return policy
+		}
+	}
+	ErrorAndExit("Invalid overlap policy, supported: skip, buffer, cancel-other, allow-all", nil)
+	return ""
+}
+
+func describeSchedule(c *cli.Context) *scheduler.ScheduleDescription {
+	domain := getRequiredGlobalOption(c, FlagDomain)
+	scheduleID := getRequiredOption(c, FlagScheduleID)
+
+	client := getSchedulerClient(c)
+	tcCtx, cancel := newContext(c)
+	defer cancel()
+	queryResp, err := client.QueryWorkflow(tcCtx, scheduler.GetScheduleWorkflowID(domain, scheduleID), "", scheduler.DescribeQueryType)
+	if err != nil {
+		ErrorAndExit("Failed to describe schedule", err)
+	}
+	var description scheduler.ScheduleDescription
+	if err := queryResp.Get(&description); err != nil {
+		ErrorAndExit("Failed to decode schedule description", err)
+	}
+	return &description
+}
+
+func signalSchedule(c *cli.Context, signalName string, arg interface{}) {
+	domain := getRequiredGlobalOption(c, FlagDomain)
+	scheduleID := getRequiredOption(c, FlagScheduleID)
+
+	client := getSchedulerClient(c)
+	tcCtx, cancel := newContext(c)
+	defer cancel()
+	if err := client.SignalWorkflow(tcCtx, scheduler.GetScheduleWorkflowID(domain, scheduleID), "", signalName, arg); err != nil {
+		ErrorAndExit("Failed to signal schedule", err)
+	}
+}
+
+func getSchedulerClient(c *cli.Context) cclient.Client {
+	svcClient := cFactory.ClientFrontendClient(c)
+	return cclient.NewClient(svcClient, common.SchedulerLocalDomainName, &DefaultClientOptions)
+}
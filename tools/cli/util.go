@@ -786,6 +786,7 @@ func processJWTFlags(ctx context.Context, cliCtx *cli.Context) context.Context {
 
 func populateContextFromCLIContext(ctx context.Context, cliCtx *cli.Context) context.Context {
 	ctx = processJWTFlags(ctx, cliCtx)
+	ctx = context.WithValue(ctx, CtxKeyAPIKey, cliCtx.GlobalString(FlagAPIKey))
 	return ctx
 }
 
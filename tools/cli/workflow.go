@@ -104,6 +104,22 @@ func newWorkflowCommands() []cli.Command {
 				TerminateWorkflow(c)
 			},
 		},
+		{
+			Name:  "pause",
+			Usage: "pause a workflow execution, no decision or activity task is dispatched until it is unpaused",
+			Flags: getFlagsForPause(),
+			Action: func(c *cli.Context) {
+				PauseWorkflow(c)
+			},
+		},
+		{
+			Name:  "unpause",
+			Usage: "unpause a paused workflow execution",
+			Flags: getFlagsForPause(),
+			Action: func(c *cli.Context) {
+				UnpauseWorkflow(c)
+			},
+		},
 		{
 			Name:        "list",
 			Aliases:     []string{"l"},
@@ -246,6 +262,12 @@ func newWorkflowCommands() []cli.Command {
 					Name:  FlagSkipSignalReapply,
 					Usage: "whether or not skipping signals reapply after the reset point",
 				},
+				cli.BoolFlag{
+					Name: FlagPreservePendingActivityResults,
+					Usage: "whether or not completing the activities pending at the reset point with their results " +
+						"recorded after the reset point, instead of running them again. The activities scheduled after " +
+						"the reset point run again",
+				},
 			},
 			Action: func(c *cli.Context) {
 				ResetWorkflow(c)
@@ -314,6 +336,12 @@ func newWorkflowCommands() []cli.Command {
 					Name:  FlagSkipSignalReapply,
 					Usage: "whether or not skipping signals reapply after the reset point",
 				},
+				cli.BoolFlag{
+					Name: FlagPreservePendingActivityResults,
+					Usage: "whether or not completing the activities pending at the reset point with their results " +
+						"recorded after the reset point, instead of running them again. The activities scheduled after " +
+						"the reset point run again",
+				},
 				cli.StringFlag{
 					Name: FlagEarliestTimeWithAlias,
 					Usage: "EarliestTime of decision start time, required for resetType of DecisionCompletedTime." +
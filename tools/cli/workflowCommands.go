@@ -43,10 +43,13 @@ import (
 	s "go.uber.org/cadence/.gen/go/shared"
 	"go.uber.org/cadence/client"
 
+	"github.com/uber/cadence/.gen/go/shared"
 	"github.com/uber/cadence/client/frontend"
 	"github.com/uber/cadence/common"
 	cc "github.com/uber/cadence/common/client"
 	"github.com/uber/cadence/common/clock"
+	"github.com/uber/cadence/common/codec"
+	"github.com/uber/cadence/common/redaction"
 	"github.com/uber/cadence/common/types"
 	"github.com/uber/cadence/common/types/mapper/thrift"
 	"github.com/uber/cadence/service/history/execution"
@@ -92,6 +95,11 @@ func showHistoryHelper(c *cli.Context, wid, rid string) {
 	if err != nil {
 		ErrorAndExit(fmt.Sprintf("Failed to get history on workflow id: %s, run id: %s.", wid, rid), err)
 	}
+	if c.Bool(FlagRedact) {
+		if history, err = redactHistory(history); err != nil {
+			ErrorAndExit("Failed to redact history.", err)
+		}
+	}
 
 	prevEvent := s.HistoryEvent{}
 	if printFully { // dump everything
@@ -310,6 +318,11 @@ func constructStartWorkflowRequest(c *cli.Context) *s.StartWorkflowExecutionRequ
 	}
 
 	headerFields := processHeader(c)
+	if c.IsSet(FlagWorkflowIDConflictPolicy) {
+		// the conflict policy is carried by a reserved header entry
+		header := common.EncodeWorkflowIDConflictPolicy(&types.Header{Fields: headerFields}, getWorkflowIDConflictPolicy(c.Int(FlagWorkflowIDConflictPolicy)))
+		headerFields = header.Fields
+	}
 	if len(headerFields) != 0 {
 		startRequest.Header = &s.Header{Fields: headerFields}
 	}
@@ -496,6 +509,48 @@ func CancelWorkflow(c *cli.Context) {
 	}
 }
 
+// PauseWorkflow pauses a workflow execution
+func PauseWorkflow(c *cli.Context) {
+	updateWorkflowPauseState(c, common.PauseWorkflowSignalName)
+	fmt.Println("Pause workflow succeeded.")
+}
+
+// UnpauseWorkflow unpauses a paused workflow execution
+func UnpauseWorkflow(c *cli.Context) {
+	updateWorkflowPauseState(c, common.UnpauseWorkflowSignalName)
+	fmt.Println("Unpause workflow succeeded.")
+}
+
+func updateWorkflowPauseState(c *cli.Context, signalName string) {
+	serviceClient := cFactory.ClientFrontendClient(c)
+
+	domain := getRequiredGlobalOption(c, FlagDomain)
+	wid := getRequiredOption(c, FlagWorkflowID)
+	rid := c.String(FlagRunID)
+	reason := c.String(FlagReason)
+
+	tcCtx, cancel := newContext(c)
+	defer cancel()
+	err := serviceClient.SignalWorkflowExecution(
+		tcCtx,
+		&s.SignalWorkflowExecutionRequest{
+			Domain: common.StringPtr(domain),
+			WorkflowExecution: &s.WorkflowExecution{
+				WorkflowId: common.StringPtr(wid),
+				RunId:      getPtrOrNilIfEmpty(rid),
+			},
+			SignalName: common.StringPtr(signalName),
+			Input:      []byte(reason),
+			Identity:   common.StringPtr(getCliIdentity()),
+			RequestId:  common.StringPtr(uuid.New()),
+		},
+		cc.GetDefaultCLIYarpcCallOptions()...,
+	)
+	if err != nil {
+		ErrorAndExit("Update workflow pause state failed.", err)
+	}
+}
+
 // SignalWorkflow signals a workflow execution
 func SignalWorkflow(c *cli.Context) {
 	serviceClient := cFactory.ClientFrontendClient(c)
@@ -934,6 +989,9 @@ func describeWorkflowHelper(c *cli.Context, wid, rid string) {
 	if err != nil {
 		ErrorAndExit("Describe workflow execution failed", err)
 	}
+	if c.Bool(FlagRedact) {
+		redaction.NewFullRedactor().RedactDescribeWorkflowExecutionResponse(resp)
+	}
 
 	if printResetPointsOnly {
 		printAutoResetPoints(resp)
@@ -950,6 +1008,32 @@ func describeWorkflowHelper(c *cli.Context, wid, rid string) {
 	prettyPrintJSONObject(o)
 }
 
+// redactHistory masks all the payloads and search attributes of the history
+func redactHistory(history *s.History) (*s.History, error) {
+	// the history of the client is converted through the thrift wire format, shared by the server types
+	encoder := codec.NewThriftRWEncoder()
+	data, err := encoder.Encode(history)
+	if err != nil {
+		return nil, err
+	}
+	var serverHistory shared.History
+	if err := encoder.Decode(data, &serverHistory); err != nil {
+		return nil, err
+	}
+	internalHistory := thrift.ToHistory(&serverHistory)
+	redaction.NewFullRedactor().RedactHistory(internalHistory)
+
+	data, err = encoder.Encode(thrift.FromHistory(internalHistory))
+	if err != nil {
+		return nil, err
+	}
+	redacted := &s.History{}
+	if err := encoder.Decode(data, redacted); err != nil {
+		return nil, err
+	}
+	return redacted, nil
+}
+
 func printAutoResetPoints(resp *types.DescribeWorkflowExecutionResponse) {
 	fmt.Println("Auto Reset Points:")
 	table := tablewriter.NewWriter(os.Stdout)
@@ -1111,6 +1195,10 @@ func convertSearchAttributesToMapOfInterface(searchAttributes *types.SearchAttri
 
 	indexedFields := searchAttributes.GetIndexedFields()
 	for k, v := range indexedFields {
+		if redaction.IsRedacted(v) {
+			result[k] = redaction.RedactedValue
+			continue
+		}
 		valueType := validKeys[k]
 		deserializedValue, err := common.DeserializeSearchAttributeValue(v, thrift.FromIndexedValueType(valueType))
 		if err != nil {
@@ -1486,6 +1574,15 @@ func getWorkflowIDReusePolicy(value int) *s.WorkflowIdReusePolicy {
 	return nil
 }
 
+func getWorkflowIDConflictPolicy(value int) *types.WorkflowIDConflictPolicy {
+	if value >= int(types.WorkflowIDConflictPolicyFail) && value <= int(types.WorkflowIDConflictPolicyTerminateExisting) {
+		return types.WorkflowIDConflictPolicy(value).Ptr()
+	}
+	// At this point, the policy should return if the value is valid
+	ErrorAndExit(fmt.Sprintf("Option %v value is not in supported range.", FlagWorkflowIDConflictPolicy), nil)
+	return nil
+}
+
 // default will print decoded raw
 func printListResults(executions []*s.WorkflowExecutionInfo, inJSON bool, more bool) {
 	for i, execution := range executions {
@@ -1559,10 +1656,11 @@ func ResetWorkflow(c *cli.Context) {
 			WorkflowID: wid,
 			RunID:      resetBaseRunID,
 		},
-		Reason:                fmt.Sprintf("%v:%v", getCurrentUserFromEnv(), reason),
-		DecisionFinishEventID: decisionFinishID,
-		RequestID:             uuid.New(),
-		SkipSignalReapply:     c.Bool(FlagSkipSignalReapply),
+		Reason:                         fmt.Sprintf("%v:%v", getCurrentUserFromEnv(), reason),
+		DecisionFinishEventID:          decisionFinishID,
+		RequestID:                      uuid.New(),
+		SkipSignalReapply:              c.Bool(FlagSkipSignalReapply),
+		PreservePendingActivityResults: c.Bool(FlagPreservePendingActivityResults),
 	})
 	if err != nil {
 		ErrorAndExit("reset failed", err)
@@ -1601,13 +1699,14 @@ func processResets(c *cli.Context, domain string, wes chan types.WorkflowExecuti
 }
 
 type batchResetParamsType struct {
-	reason               string
-	skipOpen             bool
-	nonDeterministicOnly bool
-	skipBaseNotCurrent   bool
-	dryRun               bool
-	resetType            string
-	skipSignalReapply    bool
+	reason                         string
+	skipOpen                       bool
+	nonDeterministicOnly           bool
+	skipBaseNotCurrent             bool
+	dryRun                         bool
+	resetType                      string
+	skipSignalReapply              bool
+	preservePendingActivityResults bool
 }
 
 // ResetInBatch resets workflow in batch
@@ -1629,13 +1728,14 @@ func ResetInBatch(c *cli.Context) {
 	}
 
 	batchResetParams := batchResetParamsType{
-		reason:               getRequiredOption(c, FlagReason),
-		skipOpen:             c.Bool(FlagSkipCurrentOpen),
-		nonDeterministicOnly: c.Bool(FlagNonDeterministicOnly),
-		skipBaseNotCurrent:   c.Bool(FlagSkipBaseIsNotCurrent),
-		dryRun:               c.Bool(FlagDryRun),
-		resetType:            resetType,
-		skipSignalReapply:    c.Bool(FlagSkipSignalReapply),
+		reason:                         getRequiredOption(c, FlagReason),
+		skipOpen:                       c.Bool(FlagSkipCurrentOpen),
+		nonDeterministicOnly:           c.Bool(FlagNonDeterministicOnly),
+		skipBaseNotCurrent:             c.Bool(FlagSkipBaseIsNotCurrent),
+		dryRun:                         c.Bool(FlagDryRun),
+		resetType:                      resetType,
+		skipSignalReapply:              c.Bool(FlagSkipSignalReapply),
+		preservePendingActivityResults: c.Bool(FlagPreservePendingActivityResults),
 	}
 
 	if inFileName == "" && query == "" {
@@ -1827,10 +1927,11 @@ func doReset(c *cli.Context, domain, wid, rid string, params batchResetParamsTyp
 				WorkflowID: wid,
 				RunID:      resetBaseRunID,
 			},
-			DecisionFinishEventID: decisionFinishID,
-			RequestID:             uuid.New(),
-			Reason:                fmt.Sprintf("%v:%v", getCurrentUserFromEnv(), params.reason),
-			SkipSignalReapply:     params.skipSignalReapply,
+			DecisionFinishEventID:          decisionFinishID,
+			RequestID:                      uuid.New(),
+			Reason:                         fmt.Sprintf("%v:%v", getCurrentUserFromEnv(), params.reason),
+			SkipSignalReapply:              params.skipSignalReapply,
+			PreservePendingActivityResults: params.preservePendingActivityResults,
 		})
 
 		if err != nil {